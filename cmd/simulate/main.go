@@ -2,10 +2,13 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/adapters/chain"
 	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/adapters/price"
@@ -14,36 +17,136 @@ import (
 	"github.com/joho/godotenv"
 )
 
+// options are the command line settings of a simulated analysis
+type options struct {
+	input domain.AgentInput
+	out   string // File to write the result to; empty writes to stdout
+}
+
+// parseFlags parses the command line, writing usage to stderr when it is
+// invalid. flag.ErrHelp is returned when -h was given.
+func parseFlags(args []string, stderr io.Writer) (options, error) {
+	var opts options
+	fs := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: simulate [-chain chain] [-token address[,address...]] [-limit n] [-format format] [-out file]")
+		fs.PrintDefaults()
+	}
+
+	chainName := fs.String("chain", "ethereum", "chain the token trades on: ethereum or solana")
+	tokens := fs.String("token", "0xbC56a8efee5871B397Fb06254D12a04546B62924", "token address to analyze, or a comma-separated list")
+	limit := fs.Int("limit", 5, "number of top wallets to return")
+	format := fs.String("format", domain.FormatJSON, "output format: json, text, markdown or csv")
+	fs.StringVar(&opts.out, "out", "", "write the result to this file instead of stdout")
+
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+
+	usageError := func(format string, a ...interface{}) (options, error) {
+		err := fmt.Errorf(format, a...)
+		fmt.Fprintln(fs.Output(), err)
+		fs.Usage()
+		return opts, err
+	}
+
+	if fs.NArg() > 0 {
+		return usageError("unexpected argument %q", fs.Arg(0))
+	}
+	if strings.TrimSpace(*chainName) == "" {
+		return usageError("-chain must not be empty")
+	}
+	if *limit <= 0 {
+		return usageError("-limit must be a positive integer, got %d", *limit)
+	}
+	parsedFormat, err := service.ParseOutputFormat(*format)
+	if err != nil {
+		return usageError("-format: %v", err)
+	}
+
+	opts.input = domain.AgentInput{
+		Chain:  strings.TrimSpace(*chainName),
+		Limit:  *limit,
+		Format: parsedFormat,
+	}
+	if strings.Contains(*tokens, ",") {
+		for _, token := range strings.Split(*tokens, ",") {
+			if token = strings.TrimSpace(token); token != "" {
+				opts.input.TokenAddresses = append(opts.input.TokenAddresses, token)
+			}
+		}
+	} else {
+		opts.input.TokenAddress = strings.TrimSpace(*tokens)
+	}
+	if len(opts.input.Tokens()) == 0 {
+		return usageError("-token must name at least one token address")
+	}
+	return opts, nil
+}
+
 func main() {
 	_ = godotenv.Load()
 
+	opts, err := parseFlags(os.Args[1:], os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		os.Exit(2)
+	}
+
 	// 1. Initialize same services as main.go
 	ethURL := os.Getenv("ALCHEMY_ETHEREUM_URL")
 	solURL := os.Getenv("ALCHEMY_SOLANA_URL")
 
-	ethService := chain.NewEthereumService(ethURL)
-	solService := chain.NewSolanaService(solURL)
+	ethService := chain.NewEthereumService(ethURL, nil)
+	solService := chain.NewSolanaService(solURL, nil)
 	chains := []domain.ChainService{ethService, solService}
-	priceService := price.NewDexScreenerService()
+	priceService := price.NewCompositeService(
+		price.NewDexScreenerService(),
+		price.NewCoinGeckoService(os.Getenv("COINGECKO_API_KEY")),
+	)
 	pnlCalc := service.NewPnLCalculator()
 
 	agentService := service.NewAgentService(chains, priceService, pnlCalc)
 
-	// 2. Prepare the input
-	input := domain.AgentInput{
-		Chain:        "ethereum",
-		TokenAddress: "0xbC56a8efee5871B397Fb06254D12a04546B62924",
-		Limit:        5,
+	// 2. Execute logic; progress goes to stderr so stdout holds only the result
+	input := opts.input
+	progress := func(p domain.Progress) {
+		log.Printf("... %s", p.Message)
 	}
 
-	// 3. Execute logic
-	log.Printf("Simulating analysis for Token: %s on %s...", input.TokenAddress, input.Chain)
-	result, err := agentService.AnalyzeToken(context.Background(), input)
-	if err != nil {
-		log.Fatalf("Analysis failed: %v", err)
+	var output string
+	if input.IsBatch() {
+		log.Printf("Simulating analysis for %d tokens on %s...", len(input.Tokens()), input.Chain)
+		batch, err := agentService.AnalyzeTokens(context.Background(), input, progress)
+		if err != nil {
+			log.Fatalf("Analysis failed: %v", err)
+		}
+		output, err = service.FormatBatchOutput(batch, input.Format)
+		if err != nil {
+			log.Fatalf("Formatting failed: %v", err)
+		}
+	} else {
+		log.Printf("Simulating analysis for Token: %s on %s...", input.TokenAddress, input.Chain)
+		result, err := agentService.AnalyzeToken(context.Background(), input, progress)
+		if err != nil {
+			log.Fatalf("Analysis failed: %v", err)
+		}
+		output, err = service.FormatOutput(result, input.Format)
+		if err != nil {
+			log.Fatalf("Formatting failed: %v", err)
+		}
 	}
 
-	// 4. Print Output
-	output, _ := json.MarshalIndent(result, "", "  ")
-	fmt.Println(string(output))
+	// 3. Write Output
+	if opts.out == "" {
+		fmt.Println(output)
+		return
+	}
+	if err := os.WriteFile(opts.out, []byte(output+"\n"), 0644); err != nil {
+		log.Fatalf("Failed to write %s: %v", opts.out, err)
+	}
+	log.Printf("Wrote result to %s", opts.out)
 }
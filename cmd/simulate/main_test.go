@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/core/domain"
+)
+
+func TestParseFlags(t *testing.T) {
+	const token = "0x1f9840a85d5aF5bf1D1762F925BDADdC4201F984"
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    domain.AgentInput
+		wantOut string
+	}{
+		{
+			name: "defaults",
+			want: domain.AgentInput{Chain: "ethereum", TokenAddress: "0xbC56a8efee5871B397Fb06254D12a04546B62924", Limit: 5, Format: domain.FormatJSON},
+		},
+		{
+			name:    "all flags",
+			args:    []string{"-chain", "solana", "-token", "So11111111111111111111111111111111111111112", "-limit", "20", "-format", "csv", "-out=wallets.csv"},
+			want:    domain.AgentInput{Chain: "solana", TokenAddress: "So11111111111111111111111111111111111111112", Limit: 20, Format: domain.FormatCSV},
+			wantOut: "wallets.csv",
+		},
+		{
+			name: "format alias",
+			args: []string{"-token=" + token, "-format=MD"},
+			want: domain.AgentInput{Chain: "ethereum", TokenAddress: token, Limit: 5, Format: domain.FormatMarkdown},
+		},
+		{
+			name: "token list",
+			args: []string{"-token", token + ", 0xbC56a8efee5871B397Fb06254D12a04546B62924 ,"},
+			want: domain.AgentInput{Chain: "ethereum", TokenAddresses: []string{token, "0xbC56a8efee5871B397Fb06254D12a04546B62924"}, Limit: 5, Format: domain.FormatJSON},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			opts, err := parseFlags(tt.args, &stderr)
+			if err != nil {
+				t.Fatalf("parseFlags() error = %v, output:\n%s", err, stderr.String())
+			}
+			got := opts.input
+			if got.Chain != tt.want.Chain || got.TokenAddress != tt.want.TokenAddress || got.Limit != tt.want.Limit || got.Format != tt.want.Format ||
+				strings.Join(got.TokenAddresses, "|") != strings.Join(tt.want.TokenAddresses, "|") {
+				t.Errorf("parseFlags() input = %+v, want %+v", got, tt.want)
+			}
+			if opts.out != tt.wantOut {
+				t.Errorf("parseFlags() out = %q, want %q", opts.out, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestParseFlags_InvalidPrintsUsage(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"unknown flag", []string{"-network", "ethereum"}},
+		{"non-numeric limit", []string{"-limit", "ten"}},
+		{"zero limit", []string{"-limit", "0"}},
+		{"unknown format", []string{"-format", "xml"}},
+		{"empty chain", []string{"-chain", " "}},
+		{"empty token list", []string{"-token", ",,"}},
+		{"positional argument", []string{"-limit", "3", "extra"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			if _, err := parseFlags(tt.args, &stderr); err == nil {
+				t.Fatal("parseFlags() succeeded, want an error")
+			}
+			if !strings.Contains(stderr.String(), "Usage: simulate") {
+				t.Errorf("usage not printed, output:\n%s", stderr.String())
+			}
+		})
+	}
+}
+
+func TestParseFlags_Help(t *testing.T) {
+	var stderr bytes.Buffer
+	if _, err := parseFlags([]string{"-h"}, &stderr); !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("parseFlags(-h) error = %v, want flag.ErrHelp", err)
+	}
+	if !strings.Contains(stderr.String(), "-out") {
+		t.Errorf("help does not describe -out:\n%s", stderr.String())
+	}
+}
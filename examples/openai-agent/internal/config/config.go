@@ -3,16 +3,30 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 )
 
 // Config holds application-level configuration loaded from environment variables.
 type Config struct {
 	HeliusAPIKey  string
 	HeliusBaseURL string
+	// HeliusMaxTransactions caps the swaps fetched per analysis (0 = client default).
+	HeliusMaxTransactions int
+	// HeliusMaxAge stops pagination at older transactions (0 = no cutoff).
+	HeliusMaxAge time.Duration
+	// WalletBlocklist holds extra comma-separated network:address entries
+	// excluded from rankings.
+	WalletBlocklist string
+	// UseDefaultBlocklist keeps the built-in router and exchange addresses.
+	UseDefaultBlocklist bool
 }
 
 // Load reads configuration from the environment.
 // HELIUS_API_KEY is required; HeliusBaseURL has a sensible default.
+// HELIUS_MAX_TRANSACTIONS and HELIUS_MAX_AGE (e.g. "72h") optionally bound pagination.
+// WALLET_BLOCKLIST extends the ranking blocklist; WALLET_BLOCKLIST_DEFAULTS=false
+// drops the built-in entries.
 func Load() (*Config, error) {
 	apiKey := os.Getenv("HELIUS_API_KEY")
 	if apiKey == "" {
@@ -24,8 +38,28 @@ func Load() (*Config, error) {
 		baseURL = "https://api-mainnet.helius-rpc.com"
 	}
 
-	return &Config{
-		HeliusAPIKey:  apiKey,
-		HeliusBaseURL: baseURL,
-	}, nil
+	cfg := &Config{
+		HeliusAPIKey:        apiKey,
+		HeliusBaseURL:       baseURL,
+		WalletBlocklist:     os.Getenv("WALLET_BLOCKLIST"),
+		UseDefaultBlocklist: os.Getenv("WALLET_BLOCKLIST_DEFAULTS") != "false",
+	}
+
+	if v := os.Getenv("HELIUS_MAX_TRANSACTIONS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("HELIUS_MAX_TRANSACTIONS must be a positive integer, got %q", v)
+		}
+		cfg.HeliusMaxTransactions = n
+	}
+
+	if v := os.Getenv("HELIUS_MAX_AGE"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("HELIUS_MAX_AGE must be a positive duration such as 72h, got %q", v)
+		}
+		cfg.HeliusMaxAge = d
+	}
+
+	return cfg, nil
 }
@@ -15,6 +15,9 @@ type WalletPnL struct {
 	TotalSells      int
 	WinningTrades   int     // sells with positive PnL
 	WinRate         float64 // WinningTrades / TotalSells * 100
+	CostBasis       float64 // SOL cost of the tokens sold
+	ROI             float64 // RealizedPnL / CostBasis * 100
+	VolumeSOL       float64 // SOL spent on buys plus SOL received from sells
 }
 
 // buyLot represents a single buy that has not been fully consumed by sells.
@@ -56,11 +59,13 @@ func ComputePnL(swaps []parser.NormalizedSwap) []WalletPnL {
 // computeWalletPnL runs the FIFO cost basis algorithm for a single wallet.
 func computeWalletPnL(wallet string, swaps []parser.NormalizedSwap) WalletPnL {
 	var lots []buyLot
-	var realizedPnL float64
+	var realizedPnL, costBasisSold float64
 	var completedTrades int
 	var totalBuys, totalSells, winningTrades int
+	var volume float64
 
 	for _, s := range swaps {
+		volume += s.SolAmount
 		switch s.Type {
 		case "buy":
 			totalBuys++
@@ -94,6 +99,7 @@ func computeWalletPnL(wallet string, swaps []parser.NormalizedSwap) WalletPnL {
 				costBasis := consumed * lot.costPerToken
 				revenue := consumed * sellPricePerToken
 				sellPnL += revenue - costBasis
+				costBasisSold += costBasis
 				traded = true
 
 				lot.tokenRemaining -= consumed
@@ -120,6 +126,11 @@ func computeWalletPnL(wallet string, swaps []parser.NormalizedSwap) WalletPnL {
 		winRate = float64(winningTrades) / float64(totalSells) * 100
 	}
 
+	roi := 0.0
+	if costBasisSold > 0 {
+		roi = realizedPnL / costBasisSold * 100
+	}
+
 	return WalletPnL{
 		Wallet:          wallet,
 		RealizedPnL:     realizedPnL,
@@ -128,5 +139,8 @@ func computeWalletPnL(wallet string, swaps []parser.NormalizedSwap) WalletPnL {
 		TotalSells:      totalSells,
 		WinningTrades:   winningTrades,
 		WinRate:         winRate,
+		CostBasis:       costBasisSold,
+		ROI:             roi,
+		VolumeSOL:       volume,
 	}
 }
@@ -12,8 +12,9 @@ import (
 )
 
 const (
-	// maxPages caps pagination to keep latency within the Teneo task timeout.
-	maxPages = 10
+	// DefaultMaxTransactions caps how many swaps are collected so latency stays
+	// within the Teneo task timeout.
+	DefaultMaxTransactions = 1000
 	// pageSize is the maximum number of transactions per Helius API call.
 	pageSize = 100
 )
@@ -23,6 +24,9 @@ type Client struct {
 	apiKey     string
 	baseURL    string
 	httpClient *http.Client
+
+	maxTransactions int
+	maxAge          time.Duration
 }
 
 // NewClient creates a new Helius API client.
@@ -33,16 +37,64 @@ func NewClient(apiKey, baseURL string) *Client {
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		maxTransactions: DefaultMaxTransactions,
+	}
+}
+
+// SetMaxTransactions sets how many swap transactions FetchSwapTransactions
+// collects before it stops paginating. Values <= 0 restore the default.
+func (c *Client) SetMaxTransactions(n int) {
+	if n <= 0 {
+		n = DefaultMaxTransactions
 	}
+	c.maxTransactions = n
 }
 
-// FetchSwapTransactions retrieves all SWAP-type transactions for a given token
-// mint address, paginating up to maxPages (1,000 transactions).
-func (c *Client) FetchSwapTransactions(ctx context.Context, tokenMint string) ([]EnhancedTransaction, error) {
+// SetMaxAge stops pagination at transactions older than maxAge.
+// Zero disables the time cutoff.
+func (c *Client) SetMaxAge(maxAge time.Duration) {
+	c.maxAge = maxAge
+}
+
+// FetchOptions scopes a single FetchSwapTransactions call. Zero values fall
+// back to the client's limits, which the options can narrow but not widen.
+type FetchOptions struct {
+	From            time.Time // Stop at transactions older than this
+	To              time.Time // Skip transactions newer than this
+	MaxTransactions int       // Stop after this many swaps
+}
+
+// FetchSwapTransactions retrieves SWAP-type transactions for a given token
+// mint address, newest first. It follows the Helius "before" cursor page by
+// page until the history is exhausted, the max transaction count is reached,
+// or a transaction older than the max age or opts.From is seen.
+func (c *Client) FetchSwapTransactions(ctx context.Context, tokenMint string, opts FetchOptions) ([]EnhancedTransaction, error) {
 	var allTxns []EnhancedTransaction
 	var beforeSig string
+	seen := make(map[string]bool)
+
+	var cutoff int64
+	if c.maxAge > 0 {
+		cutoff = time.Now().Add(-c.maxAge).Unix()
+	}
+	if !opts.From.IsZero() && opts.From.Unix() > cutoff {
+		cutoff = opts.From.Unix()
+	}
+	var until int64
+	if !opts.To.IsZero() {
+		until = opts.To.Unix()
+	}
+	maxTransactions := c.maxTransactions
+	if opts.MaxTransactions > 0 && opts.MaxTransactions < maxTransactions {
+		maxTransactions = opts.MaxTransactions
+	}
+
+	for page := 0; ; page++ {
+		// Stop promptly if the task was cancelled between pages
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 
-	for page := 0; page < maxPages; page++ {
 		txns, err := c.fetchPage(ctx, tokenMint, beforeSig)
 		if err != nil {
 			return nil, fmt.Errorf("page %d: %w", page, err)
@@ -52,8 +104,22 @@ func (c *Client) FetchSwapTransactions(ctx context.Context, tokenMint string) ([
 			break
 		}
 
-		// Filter: keep only transactions with a swap event and no error
+		done := false
 		for i := range txns {
+			if cutoff > 0 && txns[i].Timestamp > 0 && txns[i].Timestamp < cutoff {
+				done = true
+				break
+			}
+			// Pages can overlap when new transactions land between requests
+			if seen[txns[i].Signature] {
+				continue
+			}
+			seen[txns[i].Signature] = true
+			if until > 0 && txns[i].Timestamp > until {
+				continue
+			}
+
+			// Filter: keep only transactions with a swap event and no error
 			if txns[i].TransactionError != nil {
 				continue
 			}
@@ -61,17 +127,25 @@ func (c *Client) FetchSwapTransactions(ctx context.Context, tokenMint string) ([
 				continue
 			}
 			allTxns = append(allTxns, txns[i])
+			if len(allTxns) >= maxTransactions {
+				done = true
+				break
+			}
 		}
 
-		// Set cursor for next page
-		beforeSig = txns[len(txns)-1].Signature
-
 		log.Printf("📡 Fetched page %d: %d transactions (%d swaps total)", page+1, len(txns), len(allTxns))
 
 		// If we got fewer than a full page, there are no more transactions
-		if len(txns) < pageSize {
+		if done || len(txns) < pageSize {
+			break
+		}
+
+		// Set cursor for next page; bail out if it would not advance
+		nextSig := txns[len(txns)-1].Signature
+		if nextSig == "" || nextSig == beforeSig {
 			break
 		}
+		beforeSig = nextSig
 	}
 
 	log.Printf("✅ Total swap transactions fetched: %d", len(allTxns))
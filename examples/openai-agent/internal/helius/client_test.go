@@ -0,0 +1,210 @@
+package helius
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// swapTxn builds a successful swap transaction with the given signature
+func swapTxn(sig string, timestamp int64) EnhancedTransaction {
+	return EnhancedTransaction{
+		Type:      "SWAP",
+		Signature: sig,
+		Timestamp: timestamp,
+		Events:    Events{Swap: &SwapEvent{}},
+	}
+}
+
+// newPagedServer serves history (newest first) in pages of pageSize,
+// honouring the "before" cursor. overlap repeats that many transactions
+// from the previous page at the start of each following page.
+func newPagedServer(t *testing.T, history []EnhancedTransaction, overlap int) (*httptest.Server, *[]string) {
+	t.Helper()
+	var mu sync.Mutex
+	var cursors []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		before := r.URL.Query().Get("before")
+		mu.Lock()
+		cursors = append(cursors, before)
+		mu.Unlock()
+
+		start := 0
+		if before != "" {
+			for i := range history {
+				if history[i].Signature == before {
+					start = i + 1 - overlap
+					break
+				}
+			}
+		}
+		end := start + pageSize
+		if end > len(history) {
+			end = len(history)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(history[start:end])
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &cursors
+}
+
+func newHistory(n int, newest time.Time) []EnhancedTransaction {
+	history := make([]EnhancedTransaction, n)
+	for i := range history {
+		history[i] = swapTxn(fmt.Sprintf("sig-%03d", i), newest.Add(-time.Duration(i)*time.Minute).Unix())
+	}
+	return history
+}
+
+func TestFetchSwapTransactions_Paginates(t *testing.T) {
+	history := newHistory(250, time.Now())
+	history[5].TransactionError = &TxError{Error: "failed"}
+	history[6].Events.Swap = nil
+	srv, cursors := newPagedServer(t, history, 0)
+
+	client := NewClient("key", srv.URL)
+	txns, err := client.FetchSwapTransactions(context.Background(), "mint", FetchOptions{})
+	if err != nil {
+		t.Fatalf("FetchSwapTransactions() error = %v", err)
+	}
+
+	if len(txns) != 248 {
+		t.Errorf("got %d transactions, want 248", len(txns))
+	}
+	wantCursors := []string{"", "sig-099", "sig-199"}
+	if fmt.Sprint(*cursors) != fmt.Sprint(wantCursors) {
+		t.Errorf("before cursors = %v, want %v", *cursors, wantCursors)
+	}
+}
+
+func TestFetchSwapTransactions_DedupesAcrossPages(t *testing.T) {
+	history := newHistory(250, time.Now())
+	srv, _ := newPagedServer(t, history, 3)
+
+	client := NewClient("key", srv.URL)
+	txns, err := client.FetchSwapTransactions(context.Background(), "mint", FetchOptions{})
+	if err != nil {
+		t.Fatalf("FetchSwapTransactions() error = %v", err)
+	}
+
+	seen := map[string]bool{}
+	for _, txn := range txns {
+		if seen[txn.Signature] {
+			t.Fatalf("duplicate transaction %s", txn.Signature)
+		}
+		seen[txn.Signature] = true
+	}
+	if len(txns) != 250 {
+		t.Errorf("got %d transactions, want 250", len(txns))
+	}
+}
+
+func TestFetchSwapTransactions_MaxTransactions(t *testing.T) {
+	history := newHistory(500, time.Now())
+	srv, cursors := newPagedServer(t, history, 0)
+
+	client := NewClient("key", srv.URL)
+	client.SetMaxTransactions(150)
+	txns, err := client.FetchSwapTransactions(context.Background(), "mint", FetchOptions{})
+	if err != nil {
+		t.Fatalf("FetchSwapTransactions() error = %v", err)
+	}
+
+	if len(txns) != 150 {
+		t.Errorf("got %d transactions, want 150", len(txns))
+	}
+	if len(*cursors) != 2 {
+		t.Errorf("fetched %d pages, want 2", len(*cursors))
+	}
+}
+
+func TestFetchSwapTransactions_MaxAge(t *testing.T) {
+	// One transaction per minute, so a 150 minute cutoff lands on page two
+	history := newHistory(500, time.Now())
+	srv, cursors := newPagedServer(t, history, 0)
+
+	client := NewClient("key", srv.URL)
+	client.SetMaxAge(150*time.Minute - 30*time.Second)
+	txns, err := client.FetchSwapTransactions(context.Background(), "mint", FetchOptions{})
+	if err != nil {
+		t.Fatalf("FetchSwapTransactions() error = %v", err)
+	}
+
+	if len(txns) != 150 {
+		t.Errorf("got %d transactions, want 150", len(txns))
+	}
+	if len(*cursors) != 2 {
+		t.Errorf("fetched %d pages, want 2", len(*cursors))
+	}
+}
+
+func TestFetchSwapTransactions_Window(t *testing.T) {
+	now := time.Now()
+	history := newHistory(500, now)
+	srv, cursors := newPagedServer(t, history, 0)
+
+	client := NewClient("key", srv.URL)
+	txns, err := client.FetchSwapTransactions(context.Background(), "mint", FetchOptions{
+		From: now.Add(-149*time.Minute - 30*time.Second),
+		To:   now.Add(-49*time.Minute - 30*time.Second),
+	})
+	if err != nil {
+		t.Fatalf("FetchSwapTransactions() error = %v", err)
+	}
+
+	// sig-050 through sig-149 fall inside the window
+	if len(txns) != 100 || txns[0].Signature != "sig-050" || txns[len(txns)-1].Signature != "sig-149" {
+		t.Errorf("got %d transactions, want sig-050..sig-149", len(txns))
+	}
+	if len(*cursors) != 2 {
+		t.Errorf("fetched %d pages, want 2", len(*cursors))
+	}
+
+	// A per-call max cannot exceed the client's own limit
+	client.SetMaxTransactions(20)
+	txns, err = client.FetchSwapTransactions(context.Background(), "mint", FetchOptions{MaxTransactions: 50})
+	if err != nil {
+		t.Fatalf("FetchSwapTransactions() error = %v", err)
+	}
+	if len(txns) != 20 {
+		t.Errorf("got %d transactions, want 20", len(txns))
+	}
+}
+
+// roundTripFunc serves requests in-process so a test can act between pages
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestFetchSwapTransactions_CancelledBetweenPages(t *testing.T) {
+	history := newHistory(500, time.Now())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pages := 0
+	client := NewClient("key", "http://helius.test")
+	client.httpClient.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		pages++
+		rec := httptest.NewRecorder()
+		json.NewEncoder(rec).Encode(history[:pageSize])
+		// Cancel once the first page has been delivered in full
+		cancel()
+		return rec.Result(), nil
+	})
+
+	_, err := client.FetchSwapTransactions(ctx, "mint", FetchOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("FetchSwapTransactions() error = %v, want context.Canceled", err)
+	}
+	if pages != 1 {
+		t.Errorf("fetched %d pages after cancellation, want 1", pages)
+	}
+}
@@ -1,8 +1,7 @@
 package parser
 
 import (
-	"math"
-	"strconv"
+	"math/big"
 	"strings"
 
 	"github.com/TeneoProtocolAI/teneo-agent-sdk/examples/openai-agent/internal/helius"
@@ -20,6 +19,9 @@ type NormalizedSwap struct {
 
 const lamportsPerSOL = 1_000_000_000.0
 
+// amountPrecision is the big.Float mantissa size used to scale raw amounts
+const amountPrecision = 256
+
 // NormalizeSwaps extracts buy/sell records from enhanced transactions for a
 // specific token mint.
 //
@@ -76,11 +78,12 @@ func parseLamports(na *helius.NativeAmount) float64 {
 	if na == nil {
 		return 0
 	}
-	val, err := strconv.ParseFloat(na.Amount, 64)
-	if err != nil {
+	val, ok := new(big.Int).SetString(na.Amount, 10)
+	if !ok {
 		return 0
 	}
-	return val
+	f, _ := new(big.Float).SetInt(val).Float64()
+	return f
 }
 
 // findTokenAmount checks whether any SwapToken matches the target mint,
@@ -97,15 +100,19 @@ func findTokenAmount(tokens []helius.SwapToken, mintLower string) (bool, float64
 	return false, 0
 }
 
-// parseRawTokenAmount converts a raw token amount string and its decimals into
-// a float64 in human-readable units.
+// parseRawTokenAmount converts a raw token amount string and the mint's
+// decimals into human-readable units. The raw integer is scaled as a
+// big.Float so large amounts are not rounded before the division.
 func parseRawTokenAmount(raw helius.RawTokenAmount) float64 {
-	val, err := strconv.ParseFloat(raw.TokenAmount, 64)
-	if err != nil {
+	val, ok := new(big.Int).SetString(raw.TokenAmount, 10)
+	if !ok {
 		return 0
 	}
+	amount := new(big.Float).SetPrec(amountPrecision).SetInt(val)
 	if raw.Decimals > 0 {
-		val = val / math.Pow(10, float64(raw.Decimals))
+		unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(raw.Decimals)), nil)
+		amount.Quo(amount, new(big.Float).SetPrec(amountPrecision).SetInt(unit))
 	}
-	return val
+	f, _ := amount.Float64()
+	return f
 }
@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/examples/openai-agent/internal/helius"
+)
+
+func TestParseRawTokenAmount(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  helius.RawTokenAmount
+		want float64
+	}{
+		{"6 decimals", helius.RawTokenAmount{TokenAmount: "1500250000", Decimals: 6}, 1500.25},
+		{"18 decimals", helius.RawTokenAmount{TokenAmount: "1500250000000000000000", Decimals: 18}, 1500.25},
+		{"beyond uint64", helius.RawTokenAmount{TokenAmount: "123456789012345678901234567890", Decimals: 18}, 123456789012.34567890123456789},
+		{"no decimals", helius.RawTokenAmount{TokenAmount: "42"}, 42},
+		{"malformed", helius.RawTokenAmount{TokenAmount: "1.5", Decimals: 6}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRawTokenAmount(tt.raw); got != tt.want {
+				t.Errorf("parseRawTokenAmount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeSwaps_ScalesByMintDecimals(t *testing.T) {
+	const mint = "Mint1111111111111111111111111111111111111"
+	txns := []helius.EnhancedTransaction{{
+		FeePayer:  "wallet",
+		Signature: "sig",
+		Events: helius.Events{Swap: &helius.SwapEvent{
+			NativeInput: &helius.NativeAmount{Amount: "2500000000"},
+			TokenOutputs: []helius.SwapToken{{
+				Mint:           mint,
+				RawTokenAmount: helius.RawTokenAmount{TokenAmount: "1000000", Decimals: 6},
+			}},
+		}},
+	}}
+
+	swaps := NormalizeSwaps(txns, mint)
+	if len(swaps) != 1 {
+		t.Fatalf("expected 1 swap, got %d", len(swaps))
+	}
+	if swaps[0].Type != "buy" || swaps[0].TokenAmount != 1 || swaps[0].SolAmount != 2.5 {
+		t.Errorf("unexpected swap: %+v", swaps[0])
+	}
+}
@@ -8,26 +8,79 @@ import (
 	"github.com/TeneoProtocolAI/teneo-agent-sdk/examples/openai-agent/internal/engine"
 )
 
-// RankWallets filters to profitable wallets, sorts by PnL descending
-// (with tie-breakers on win rate then trade count), and truncates to limit.
+// Mode selects the primary metric wallets are ranked by.
+type Mode string
+
+const (
+	// ByPnL ranks by absolute realized PnL in SOL.
+	ByPnL Mode = "pnl"
+	// ByROI ranks by realized PnL as a percentage of the SOL cost basis.
+	ByROI Mode = "roi"
+)
+
+// ParseMode returns the ranking mode named by s ("pnl" or "roi").
+func ParseMode(s string) (Mode, error) {
+	switch Mode(strings.ToLower(s)) {
+	case ByPnL:
+		return ByPnL, nil
+	case ByROI:
+		return ByROI, nil
+	}
+	return "", fmt.Errorf("unknown ranking mode %q, expected pnl or roi", s)
+}
+
+// Options controls how wallets are ranked.
+type Options struct {
+	Mode      Mode       // Primary metric (defaults to ByPnL)
+	Network   string     // Network of the wallets, used to look up the blocklist
+	Blocklist *Blocklist // Addresses to exclude; nil ranks every wallet
+	MinTrades int        // Minimum buys plus sells (0 = no minimum)
+	MinVolume float64    // Minimum SOL volume (0 = no minimum)
+}
+
+// HasThresholds reports whether the options exclude low-activity wallets.
+func (o Options) HasThresholds() bool {
+	return o.MinTrades > 0 || o.MinVolume > 0
+}
+
+// meetsThresholds reports whether w traded enough to be ranked
+func (o Options) meetsThresholds(w engine.WalletPnL) bool {
+	return w.TotalBuys+w.TotalSells >= o.MinTrades && w.VolumeSOL >= o.MinVolume
+}
+
+// RankWallets ranks Solana wallets by PnL, excluding DefaultBlocklist
+// addresses. See Rank for the ordering.
 func RankWallets(wallets []engine.WalletPnL, limit int) []engine.WalletPnL {
-	// Filter: only wallets with positive realized PnL
+	return Rank(wallets, limit, Options{Mode: ByPnL, Network: "sol", Blocklist: DefaultBlocklist()})
+}
+
+// Rank filters to profitable wallets that are not blocklisted and meet the
+// trade and volume thresholds, sorts them by
+// the mode's metric descending, and truncates to limit. Ties are broken by
+// realized PnL descending (in ROI mode), then completed trade count
+// descending, then wallet address ascending, so the order is the same on
+// every run.
+func Rank(wallets []engine.WalletPnL, limit int, opts Options) []engine.WalletPnL {
+	// Filter: only wallets with positive realized PnL that are actual traders
 	var profitable []engine.WalletPnL
 	for _, w := range wallets {
-		if w.RealizedPnL > 0 {
+		if w.RealizedPnL > 0 && !opts.Blocklist.Contains(opts.Network, w.Wallet) && opts.meetsThresholds(w) {
 			profitable = append(profitable, w)
 		}
 	}
 
-	// Sort: PnL desc → WinRate desc → CompletedTrades desc
-	sort.Slice(profitable, func(i, j int) bool {
-		if profitable[i].RealizedPnL != profitable[j].RealizedPnL {
-			return profitable[i].RealizedPnL > profitable[j].RealizedPnL
+	sort.SliceStable(profitable, func(i, j int) bool {
+		a, b := profitable[i], profitable[j]
+		if opts.Mode == ByROI && a.ROI != b.ROI {
+			return a.ROI > b.ROI
+		}
+		if a.RealizedPnL != b.RealizedPnL {
+			return a.RealizedPnL > b.RealizedPnL
 		}
-		if profitable[i].WinRate != profitable[j].WinRate {
-			return profitable[i].WinRate > profitable[j].WinRate
+		if a.CompletedTrades != b.CompletedTrades {
+			return a.CompletedTrades > b.CompletedTrades
 		}
-		return profitable[i].CompletedTrades > profitable[j].CompletedTrades
+		return a.Wallet < b.Wallet
 	})
 
 	if len(profitable) > limit {
@@ -37,6 +90,20 @@ func RankWallets(wallets []engine.WalletPnL, limit int) []engine.WalletPnL {
 	return profitable
 }
 
+// FormatNoMatches explains that the ranking thresholds excluded every wallet,
+// so users can tell it apart from a token without profitable traders.
+func FormatNoMatches(contractAddress string, opts Options) string {
+	var criteria []string
+	if opts.MinTrades > 0 {
+		criteria = append(criteria, fmt.Sprintf("at least %d trades", opts.MinTrades))
+	}
+	if opts.MinVolume > 0 {
+		criteria = append(criteria, fmt.Sprintf("at least %.4f SOL volume", opts.MinVolume))
+	}
+	return fmt.Sprintf("No wallets met criteria for %s (%s). Try lowering min-trades or min-volume.",
+		contractAddress, strings.Join(criteria, " and "))
+}
+
 // FormatOutput builds the human-readable output string per the spec.
 func FormatOutput(wallets []engine.WalletPnL, contractAddress string) string {
 	if len(wallets) == 0 {
@@ -51,11 +118,12 @@ func FormatOutput(wallets []engine.WalletPnL, contractAddress string) string {
 		if w.RealizedPnL < 0 {
 			pnlSign = ""
 		}
-		sb.WriteString(fmt.Sprintf("%d. %s (Realized PnL: %s%.4f SOL, Trades: %d, WinRate: %.0f%%)\n",
+		sb.WriteString(fmt.Sprintf("%d. %s (Realized PnL: %s%.4f SOL, ROI: %.1f%%, Trades: %d, WinRate: %.0f%%)\n",
 			i+1,
 			w.Wallet,
 			pnlSign,
 			w.RealizedPnL,
+			w.ROI,
 			w.CompletedTrades,
 			w.WinRate,
 		))
@@ -0,0 +1,147 @@
+package ranking
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/examples/openai-agent/internal/engine"
+)
+
+func walletNames(wallets []engine.WalletPnL) []string {
+	names := make([]string, len(wallets))
+	for i, w := range wallets {
+		names[i] = w.Wallet
+	}
+	return names
+}
+
+func TestRankWallets_DeterministicTieBreaking(t *testing.T) {
+	wallets := []engine.WalletPnL{
+		{Wallet: "carol", RealizedPnL: 5, CompletedTrades: 2},
+		{Wallet: "bob", RealizedPnL: 5, CompletedTrades: 3},
+		{Wallet: "alice", RealizedPnL: 5, CompletedTrades: 2},
+		{Wallet: "dave", RealizedPnL: 9, CompletedTrades: 1},
+		{Wallet: "erin", RealizedPnL: 5, CompletedTrades: 2},
+		{Wallet: "loser", RealizedPnL: -1, CompletedTrades: 4},
+	}
+	want := "[dave bob alice carol erin]"
+
+	// Shuffled inputs must always produce the same leaderboard
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		shuffled := append([]engine.WalletPnL(nil), wallets...)
+		rng.Shuffle(len(shuffled), func(a, b int) { shuffled[a], shuffled[b] = shuffled[b], shuffled[a] })
+
+		if got := fmt.Sprint(walletNames(RankWallets(shuffled, 10))); got != want {
+			t.Fatalf("RankWallets() = %s, want %s", got, want)
+		}
+	}
+
+	if got := fmt.Sprint(walletNames(RankWallets(wallets, 2))); got != "[dave bob]" {
+		t.Errorf("RankWallets() with limit 2 = %s, want [dave bob]", got)
+	}
+}
+
+func TestRank_ROI(t *testing.T) {
+	wallets := []engine.WalletPnL{
+		{Wallet: "whale", RealizedPnL: 100, ROI: 10, CompletedTrades: 1},
+		{Wallet: "sniper", RealizedPnL: 2, ROI: 400, CompletedTrades: 1},
+		{Wallet: "steady", RealizedPnL: 20, ROI: 50, CompletedTrades: 5},
+		{Wallet: "twin-b", RealizedPnL: 20, ROI: 50, CompletedTrades: 5},
+		{Wallet: "smaller", RealizedPnL: 10, ROI: 50, CompletedTrades: 9},
+	}
+
+	got := fmt.Sprint(walletNames(Rank(wallets, 10, Options{Mode: ByROI})))
+	want := "[sniper steady twin-b smaller whale]"
+	if got != want {
+		t.Errorf("Rank(ByROI) = %s, want %s", got, want)
+	}
+
+	got = fmt.Sprint(walletNames(Rank(wallets, 10, Options{Mode: ByPnL})))
+	want = "[whale steady twin-b smaller sniper]"
+	if got != want {
+		t.Errorf("Rank(ByPnL) = %s, want %s", got, want)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for input, want := range map[string]Mode{"pnl": ByPnL, "ROI": ByROI} {
+		if got, err := ParseMode(input); err != nil || got != want {
+			t.Errorf("ParseMode(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseMode("winrate"); err == nil {
+		t.Error("ParseMode(\"winrate\") should fail")
+	}
+}
+
+func TestRank_ExcludesBlocklistedWallets(t *testing.T) {
+	const jupiter = "JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4"
+	wallets := []engine.WalletPnL{
+		{Wallet: jupiter, RealizedPnL: 1000, CompletedTrades: 50},
+		{Wallet: "MarketMaker1111111111111111111111111111111", RealizedPnL: 500, CompletedTrades: 20},
+		{Wallet: "Trader11111111111111111111111111111111111", RealizedPnL: 10, CompletedTrades: 2},
+	}
+
+	if got := fmt.Sprint(walletNames(RankWallets(wallets, 10))); got != "[MarketMaker1111111111111111111111111111111 Trader11111111111111111111111111111111111]" {
+		t.Errorf("RankWallets() = %s, want default blocklist applied", got)
+	}
+
+	extended := DefaultBlocklist()
+	if err := extended.AddEntries("sol:MarketMaker1111111111111111111111111111111"); err != nil {
+		t.Fatalf("AddEntries() error = %v", err)
+	}
+	got := fmt.Sprint(walletNames(Rank(wallets, 10, Options{Network: "sol", Blocklist: extended})))
+	if got != "[Trader11111111111111111111111111111111111]" {
+		t.Errorf("Rank() with extended blocklist = %s", got)
+	}
+
+	// Entries only apply to their own network
+	other := NewBlocklist()
+	other.Add("eth", jupiter)
+	if got := Rank(wallets, 10, Options{Network: "sol", Blocklist: other}); len(got) != 3 {
+		t.Errorf("Rank() with another network's blocklist = %v, want all wallets", walletNames(got))
+	}
+
+	if err := NewBlocklist().AddEntries("no-network"); err == nil {
+		t.Error("AddEntries() should reject entries without a network")
+	}
+}
+
+func TestRank_MinTradesAndMinVolume(t *testing.T) {
+	wallets := []engine.WalletPnL{
+		// One lucky 10x on a tiny position
+		{Wallet: "lucky", RealizedPnL: 0.9, ROI: 900, TotalBuys: 1, TotalSells: 1, CompletedTrades: 1, VolumeSOL: 1.1},
+		{Wallet: "steady", RealizedPnL: 0.5, ROI: 25, TotalBuys: 3, TotalSells: 3, CompletedTrades: 3, VolumeSOL: 4.5},
+		{Wallet: "whale", RealizedPnL: 0.7, ROI: 7, TotalBuys: 1, TotalSells: 1, CompletedTrades: 1, VolumeSOL: 20},
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"no thresholds", Options{}, "[lucky whale steady]"},
+		{"min trades", Options{MinTrades: 4}, "[steady]"},
+		{"min volume", Options{MinVolume: 4}, "[whale steady]"},
+		{"both", Options{MinTrades: 2, MinVolume: 4}, "[whale steady]"},
+		{"excludes everyone", Options{MinTrades: 10, MinVolume: 100}, "[]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fmt.Sprint(walletNames(Rank(wallets, 10, tt.opts))); got != tt.want {
+				t.Errorf("Rank() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatNoMatches(t *testing.T) {
+	got := FormatNoMatches("Mint111", Options{MinTrades: 10, MinVolume: 2.5})
+	want := "No wallets met criteria for Mint111 (at least 10 trades and at least 2.5000 SOL volume). Try lowering min-trades or min-volume."
+	if got != want {
+		t.Errorf("FormatNoMatches() = %q, want %q", got, want)
+	}
+}
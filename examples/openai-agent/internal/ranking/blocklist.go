@@ -0,0 +1,81 @@
+package ranking
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultBlocked lists well-known program, router and exchange wallets per
+// network. They show up as swap counterparties but are not alpha traders.
+var defaultBlocked = map[string][]string{
+	"sol": {
+		"JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4",  // Jupiter v6
+		"JUP4Fb2cqiRUcaTHdrPC8h2gNsA2ETXiPDD33WcGuJB",  // Jupiter v4
+		"675kPX9MHTjS2zt1qfr1NYHuzeLXfQM9H24wFSUt1Mp8", // Raydium AMM v4
+		"5Q544fKrFoe6tsEbD7S8EmxGTJYAKtTVhAW5Q5pge4j1", // Raydium AMM authority
+		"CAMMCzo5YL8w4VFF8KVHrK22GGUsp5VTaW7grrKgrWqK", // Raydium CLMM
+		"whirLbMiicVdio4qvUfM5KAg6Ct8VwpYzGff3uctyCc",  // Orca Whirlpool
+		"6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P",  // Pump.fun
+		"5tzFkiKscXHK5ZXCGbXZxdw7gTjjD1mBwuoFbhUvuAi9", // Binance 2
+		"H8sMJSCQxfKiFTCfDR3DUMLPwcRbM61LGFJ8N4dK3WjS", // Coinbase 1
+	},
+}
+
+// Blocklist is a per-network set of wallet addresses excluded from rankings.
+// Solana addresses are base58 and matched exactly.
+type Blocklist struct {
+	addresses map[string]map[string]bool
+}
+
+// NewBlocklist returns an empty blocklist.
+func NewBlocklist() *Blocklist {
+	return &Blocklist{addresses: make(map[string]map[string]bool)}
+}
+
+// DefaultBlocklist returns a blocklist seeded with common DEX programs and
+// exchange wallets. Callers may Add to it.
+func DefaultBlocklist() *Blocklist {
+	b := NewBlocklist()
+	for network, addresses := range defaultBlocked {
+		b.Add(network, addresses...)
+	}
+	return b
+}
+
+// Add blocks addresses on network.
+func (b *Blocklist) Add(network string, addresses ...string) {
+	network = strings.ToLower(network)
+	if b.addresses[network] == nil {
+		b.addresses[network] = make(map[string]bool)
+	}
+	for _, addr := range addresses {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			b.addresses[network][addr] = true
+		}
+	}
+}
+
+// Contains reports whether address is blocked on network.
+func (b *Blocklist) Contains(network, address string) bool {
+	if b == nil {
+		return false
+	}
+	return b.addresses[strings.ToLower(network)][address]
+}
+
+// AddEntries adds comma-separated "network:address" entries, as read from
+// configuration, to b.
+func (b *Blocklist) AddEntries(entries string) error {
+	for _, entry := range strings.Split(entries, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		network, addr, ok := strings.Cut(entry, ":")
+		if !ok || network == "" || addr == "" {
+			return fmt.Errorf("invalid blocklist entry %q, expected network:address", entry)
+		}
+		b.Add(network, addr)
+	}
+	return nil
+}
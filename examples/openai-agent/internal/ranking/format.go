@@ -0,0 +1,156 @@
+package ranking
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/examples/openai-agent/internal/engine"
+)
+
+// Output formats accepted by Format.
+const (
+	FormatText     = "text"
+	FormatMarkdown = "markdown"
+	FormatCSV      = "csv"
+	FormatJSON     = "json"
+)
+
+// csvHeader names the columns written by the CSV format
+var csvHeader = []string{
+	"rank", "wallet", "realized_pnl_sol", "roi_percentage", "completed_trades",
+	"total_buys", "total_sells", "win_rate_percentage", "volume_sol",
+}
+
+// jsonWallet is the JSON form of a ranked wallet
+type jsonWallet struct {
+	Rank            int     `json:"rank"`
+	Wallet          string  `json:"wallet"`
+	RealizedPnL     float64 `json:"realized_pnl_sol"`
+	ROI             float64 `json:"roi_percentage"`
+	CompletedTrades int     `json:"completed_trades"`
+	TotalBuys       int     `json:"total_buys"`
+	TotalSells      int     `json:"total_sells"`
+	WinRate         float64 `json:"win_rate_percentage"`
+	VolumeSOL       float64 `json:"volume_sol"`
+}
+
+// jsonResult is the JSON document produced by the JSON format
+type jsonResult struct {
+	Contract string       `json:"contract"`
+	Wallets  []jsonWallet `json:"wallets"`
+}
+
+// ParseFormat normalizes an output format name, defaulting to text.
+func ParseFormat(format string) (string, error) {
+	switch f := strings.ToLower(strings.TrimSpace(format)); f {
+	case "":
+		return FormatText, nil
+	case FormatText, FormatMarkdown, FormatCSV, FormatJSON:
+		return f, nil
+	case "md":
+		return FormatMarkdown, nil
+	}
+	return "", fmt.Errorf("unknown format %q, expected text, markdown, csv or json", format)
+}
+
+// Format renders ranked wallets in the given format. Text is the format
+// produced by FormatOutput.
+func Format(wallets []engine.WalletPnL, contractAddress, format string) (string, error) {
+	format, err := ParseFormat(format)
+	if err != nil {
+		return "", err
+	}
+
+	switch format {
+	case FormatMarkdown:
+		return formatMarkdown(wallets, contractAddress), nil
+	case FormatCSV:
+		return formatCSV(wallets)
+	case FormatJSON:
+		result := jsonResult{Contract: contractAddress, Wallets: make([]jsonWallet, len(wallets))}
+		for i, w := range wallets {
+			result.Wallets[i] = jsonWallet{
+				Rank:            i + 1,
+				Wallet:          w.Wallet,
+				RealizedPnL:     w.RealizedPnL,
+				ROI:             w.ROI,
+				CompletedTrades: w.CompletedTrades,
+				TotalBuys:       w.TotalBuys,
+				TotalSells:      w.TotalSells,
+				WinRate:         w.WinRate,
+				VolumeSOL:       w.VolumeSOL,
+			}
+		}
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("encoding json: %w", err)
+		}
+		return string(data), nil
+	default:
+		return FormatOutput(wallets, contractAddress), nil
+	}
+}
+
+func formatMarkdown(wallets []engine.WalletPnL, contractAddress string) string {
+	if len(wallets) == 0 {
+		return fmt.Sprintf("No profitable Alpha Wallets found for `%s`", markdownCell(contractAddress))
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### Alpha Wallets for `%s`\n\n", markdownCell(contractAddress))
+	sb.WriteString("| # | Wallet | Realized PnL (SOL) | ROI (%) | Trades | Win Rate (%) | Volume (SOL) |\n")
+	sb.WriteString("|---:|---|---:|---:|---:|---:|---:|\n")
+	for i, w := range wallets {
+		fmt.Fprintf(&sb, "| %d | `%s` | %s | %s | %d | %s | %s |\n",
+			i+1, markdownCell(w.Wallet),
+			formatNumber(w.RealizedPnL), formatNumber(w.ROI), w.CompletedTrades,
+			formatNumber(w.WinRate), formatNumber(w.VolumeSOL))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func formatCSV(wallets []engine.WalletPnL) (string, error) {
+	var buf bytes.Buffer
+	cw := csv.NewWriter(&buf)
+	if err := cw.Write(csvHeader); err != nil {
+		return "", err
+	}
+	for i, w := range wallets {
+		record := []string{
+			strconv.Itoa(i + 1),
+			w.Wallet,
+			formatNumber(w.RealizedPnL),
+			formatNumber(w.ROI),
+			strconv.Itoa(w.CompletedTrades),
+			strconv.Itoa(w.TotalBuys),
+			strconv.Itoa(w.TotalSells),
+			formatNumber(w.WinRate),
+			formatNumber(w.VolumeSOL),
+		}
+		if err := cw.Write(record); err != nil {
+			return "", err
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return "", fmt.Errorf("writing csv: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// formatNumber renders f without exponent notation so it parses back exactly
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// markdownCell escapes text placed inside a Markdown table cell
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "\r", " ")
+	s = strings.ReplaceAll(s, "\n", " ")
+	s = strings.ReplaceAll(s, "`", "'")
+	return strings.ReplaceAll(s, "|", "\\|")
+}
@@ -0,0 +1,127 @@
+package ranking
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/examples/openai-agent/internal/engine"
+)
+
+// formatWallets includes values that need quoting or would otherwise use
+// exponent notation
+var formatWallets = []engine.WalletPnL{
+	{Wallet: "Hx1,\"quoted\"|pipe", RealizedPnL: 1234567.891, ROI: 0.000001, CompletedTrades: 3, TotalBuys: 2, TotalSells: 3, WinRate: 66.66666666666667, VolumeSOL: 1e21},
+	{Wallet: "9xQeWvG816bUx9EPjHmaT23yvVM2ZWbrrpZb9PusVFin", RealizedPnL: -0.5, ROI: -12.5, CompletedTrades: 1, TotalBuys: 1, TotalSells: 1, VolumeSOL: 2.25},
+}
+
+func TestFormat_CSVRoundTrip(t *testing.T) {
+	out, err := Format(formatWallets, "MintAddr", FormatCSV)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatalf("CSV does not parse back: %v\n%s", err, out)
+	}
+	if len(records) != len(formatWallets)+1 {
+		t.Fatalf("got %d records, want %d", len(records), len(formatWallets)+1)
+	}
+	if strings.Join(records[0], ",") != strings.Join(csvHeader, ",") {
+		t.Errorf("header = %v, want %v", records[0], csvHeader)
+	}
+
+	for i, w := range formatWallets {
+		row := records[i+1]
+		if row[0] != strconv.Itoa(i+1) || row[1] != w.Wallet {
+			t.Errorf("row %d = %v, want rank %d wallet %q", i, row, i+1, w.Wallet)
+		}
+		for col, want := range map[int]float64{2: w.RealizedPnL, 3: w.ROI, 7: w.WinRate, 8: w.VolumeSOL} {
+			got, err := strconv.ParseFloat(row[col], 64)
+			if err != nil || got != want {
+				t.Errorf("row %d %s = %q, want %v", i, csvHeader[col], row[col], want)
+			}
+			if strings.ContainsAny(row[col], "eE") {
+				t.Errorf("row %d %s = %q uses exponent notation", i, csvHeader[col], row[col])
+			}
+		}
+	}
+
+	empty, err := Format(nil, "MintAddr", FormatCSV)
+	if err != nil || strings.TrimSpace(empty) != strings.Join(csvHeader, ",") {
+		t.Errorf("empty CSV = %q, %v, want header only", empty, err)
+	}
+}
+
+func TestFormat_JSONRoundTrip(t *testing.T) {
+	out, err := Format(formatWallets, "MintAddr", FormatJSON)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	var got jsonResult
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("JSON does not parse back: %v", err)
+	}
+	if got.Contract != "MintAddr" || len(got.Wallets) != len(formatWallets) {
+		t.Fatalf("got %+v", got)
+	}
+	for i, w := range formatWallets {
+		g := got.Wallets[i]
+		if g.Rank != i+1 || g.Wallet != w.Wallet || g.RealizedPnL != w.RealizedPnL || g.VolumeSOL != w.VolumeSOL {
+			t.Errorf("wallet %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+func TestFormat_MarkdownRoundTrip(t *testing.T) {
+	out, err := Format(formatWallets, "MintAddr", "md")
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	var rows [][]string
+	for _, line := range strings.Split(out, "\n") {
+		if !strings.HasPrefix(line, "| ") {
+			continue
+		}
+		// Split on unescaped pipes only
+		cells := strings.Split(strings.ReplaceAll(line, "\\|", "\x00"), "|")
+		cells = cells[1 : len(cells)-1]
+		for i, c := range cells {
+			cells[i] = strings.Trim(strings.TrimSpace(strings.ReplaceAll(c, "\x00", "|")), "`")
+		}
+		rows = append(rows, cells)
+	}
+	if len(rows) != len(formatWallets)+1 {
+		t.Fatalf("got %d table rows, want %d:\n%s", len(rows), len(formatWallets)+1, out)
+	}
+	for i, w := range formatWallets {
+		row := rows[i+1]
+		if len(row) != len(rows[0]) {
+			t.Fatalf("row %d has %d cells, want %d", i, len(row), len(rows[0]))
+		}
+		if row[1] != w.Wallet {
+			t.Errorf("row %d wallet = %q, want %q", i, row[1], w.Wallet)
+		}
+		if got, err := strconv.ParseFloat(row[2], 64); err != nil || got != w.RealizedPnL {
+			t.Errorf("row %d pnl = %q, want %v", i, row[2], w.RealizedPnL)
+		}
+	}
+}
+
+func TestFormat_TextIsDefault(t *testing.T) {
+	want := FormatOutput(formatWallets, "MintAddr")
+	for _, format := range []string{"", "text", " TEXT "} {
+		got, err := Format(formatWallets, "MintAddr", format)
+		if err != nil || got != want {
+			t.Errorf("Format(%q) = %q, %v, want the text output", format, got, err)
+		}
+	}
+	if _, err := Format(formatWallets, "MintAddr", "xml"); err == nil {
+		t.Error("Format(xml) should fail")
+	}
+}
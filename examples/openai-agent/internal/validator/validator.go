@@ -4,6 +4,10 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/examples/openai-agent/internal/ranking"
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/command"
 )
 
 // AnalyzeRequest represents a validated user command.
@@ -11,26 +15,87 @@ type AnalyzeRequest struct {
 	ContractAddress string
 	Network         string
 	Limit           int
+
+	// Optional analysis scope; zero values leave that bound open
+	FromTime time.Time
+	ToTime   time.Time
+	MaxSwaps int
+
+	// RankBy selects the ranking metric (defaults to realized PnL)
+	RankBy ranking.Mode
+
+	// Optional thresholds a wallet must meet to be ranked
+	MinTrades int
+	MinVolume float64
+
+	// Format selects the output format (defaults to text)
+	Format string
 }
 
-// ParseCommand parses the CLI-style input: analyze <contract_address> <network> [limit]
+// optionNames are the options accepted as --name=value flags or, as before
+// flags existed, as name=value words
+var optionNames = []string{
+	"address", "network", "limit", "from", "to", "max", "rank", "min-trades", "min-volume", "format",
+}
+
+// commandParser splits commands into positional arguments and options
+var commandParser = &command.Parser{Flags: optionNames, KeyValue: true}
+
+const usage = "usage: analyze <contract_address> <network> [limit] [from=<time>] [to=<time>] [max=<swaps>] [rank=pnl|roi] [min-trades=<n>] [min-volume=<sol>] [format=text|markdown|csv|json]"
+
+// ParseCommand parses the CLI-style input:
+//
+//	analyze <contract_address> <network> [limit] [from=<time>] [to=<time>] [max=<swaps>] [rank=pnl|roi]
+//	        [min-trades=<n>] [min-volume=<sol>] [format=text|markdown|csv|json]
+//
+// Arguments may be quoted, and every option may also be written as a
+// --name=value or --name value flag, including --address, --network and
+// --limit in place of the positional arguments; flags take precedence.
+// Times are RFC 3339, a YYYY-MM-DD date, or an age such as 7d or 12h.
 func ParseCommand(task string) (*AnalyzeRequest, error) {
-	parts := strings.Fields(strings.TrimSpace(task))
-	if len(parts) == 0 {
+	return parseCommand(task, time.Now())
+}
+
+func parseCommand(task string, now time.Time) (*AnalyzeRequest, error) {
+	cmd, err := commandParser.Parse(task)
+	if err != nil {
+		return nil, err
+	}
+	if len(cmd.Args) == 0 {
 		return nil, fmt.Errorf("empty command")
 	}
 
 	// The command word must be "analyze"
-	if strings.ToLower(parts[0]) != "analyze" {
-		return nil, fmt.Errorf("unknown command %q, expected \"analyze\"", parts[0])
+	if strings.ToLower(cmd.Arg(0)) != "analyze" {
+		return nil, fmt.Errorf("unknown command %q, expected \"analyze\"", cmd.Arg(0))
 	}
 
-	if len(parts) < 3 {
-		return nil, fmt.Errorf("usage: analyze <contract_address> <network> [limit]")
+	// Options override the positional arguments they stand in for
+	positional := cmd.Args[1:]
+	value := func(name string, position int) string {
+		if v, ok := cmd.Flag(name); ok {
+			return v
+		}
+		if position < len(positional) {
+			return positional[position]
+		}
+		return ""
 	}
 
-	address := parts[1]
-	network := strings.ToLower(parts[2])
+	for _, arg := range positional {
+		if key, _, ok := strings.Cut(arg, "="); ok {
+			return nil, fmt.Errorf("unknown option %q, expected from, to, max, rank, min-trades, min-volume or format", key)
+		}
+	}
+
+	address := value("address", 0)
+	network := strings.ToLower(value("network", 1))
+	if address == "" || network == "" {
+		return nil, fmt.Errorf(usage)
+	}
+	if len(positional) > 3 {
+		return nil, fmt.Errorf("invalid option %q, expected key=value", positional[3])
+	}
 
 	if network != "sol" {
 		return nil, fmt.Errorf("unsupported network %q, only \"sol\" is supported", network)
@@ -40,20 +105,100 @@ func ParseCommand(task string) (*AnalyzeRequest, error) {
 		return nil, fmt.Errorf("invalid contract address: %w", err)
 	}
 
-	limit := 5 // default
-	if len(parts) >= 4 {
-		parsed, err := strconv.Atoi(parts[3])
+	req := &AnalyzeRequest{
+		ContractAddress: address,
+		Network:         network,
+		Limit:           5, // default
+		RankBy:          ranking.ByPnL,
+		Format:          ranking.FormatText,
+	}
+
+	if limit := value("limit", 2); limit != "" {
+		parsed, err := strconv.Atoi(limit)
 		if err != nil || parsed <= 0 {
-			return nil, fmt.Errorf("limit must be a positive integer, got %q", parts[3])
+			return nil, fmt.Errorf("limit must be a positive integer, got %q", limit)
 		}
-		limit = parsed
+		req.Limit = parsed
 	}
 
-	return &AnalyzeRequest{
-		ContractAddress: address,
-		Network:         network,
-		Limit:           limit,
-	}, nil
+	for _, key := range optionNames {
+		value, ok := cmd.Flag(key)
+		if !ok {
+			continue
+		}
+		switch key {
+		case "address", "network", "limit":
+			// Handled with the positional arguments
+		case "from":
+			t, err := parseTime(value, now)
+			if err != nil {
+				return nil, fmt.Errorf("invalid from: %w", err)
+			}
+			req.FromTime = t
+		case "to":
+			t, err := parseTime(value, now)
+			if err != nil {
+				return nil, fmt.Errorf("invalid to: %w", err)
+			}
+			req.ToTime = t
+		case "max":
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("max must be a positive integer, got %q", value)
+			}
+			req.MaxSwaps = n
+		case "min-trades":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("min-trades must be a non-negative integer, got %q", value)
+			}
+			req.MinTrades = n
+		case "min-volume":
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil || v < 0 {
+				return nil, fmt.Errorf("min-volume must be a non-negative number of SOL, got %q", value)
+			}
+			req.MinVolume = v
+		case "format":
+			format, err := ranking.ParseFormat(value)
+			if err != nil {
+				return nil, err
+			}
+			req.Format = format
+		case "rank":
+			mode, err := ranking.ParseMode(value)
+			if err != nil {
+				return nil, err
+			}
+			req.RankBy = mode
+		}
+	}
+
+	if !req.FromTime.IsZero() && !req.ToTime.IsZero() && req.ToTime.Before(req.FromTime) {
+		return nil, fmt.Errorf("to must not be before from")
+	}
+
+	return req, nil
+}
+
+// parseTime accepts RFC 3339, a YYYY-MM-DD date (UTC), or an age relative
+// to now such as 7d, 12h or 30m.
+func parseTime(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date, RFC 3339 time or age like 7d", value)
 }
 
 // validateBase58 checks that s is a plausible Solana base58 address.
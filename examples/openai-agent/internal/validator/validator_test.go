@@ -0,0 +1,62 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/examples/openai-agent/internal/ranking"
+)
+
+const testMint = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+
+func TestParseCommand(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		task   string
+		check  func(*AnalyzeRequest) bool
+		errMsg string // empty = valid
+	}{
+		{"positional", "analyze " + testMint + " sol 7", func(r *AnalyzeRequest) bool {
+			return r.ContractAddress == testMint && r.Limit == 7 && r.Format == ranking.FormatText
+		}, ""},
+		{"key=value options", "analyze " + testMint + " sol max=100 rank=roi format=csv", func(r *AnalyzeRequest) bool {
+			return r.MaxSwaps == 100 && r.RankBy == ranking.ByROI && r.Format == ranking.FormatCSV && r.Limit == 5
+		}, ""},
+		{"named flags", "analyze --network=SOL --address " + testMint + " --limit=3 --from=7d", func(r *AnalyzeRequest) bool {
+			return r.Network == "sol" && r.Limit == 3 && r.FromTime.Equal(now.AddDate(0, 0, -7))
+		}, ""},
+		{"quoted arguments", `analyze "` + testMint + `" 'sol' --format "markdown"`, func(r *AnalyzeRequest) bool {
+			return r.ContractAddress == testMint && r.Format == ranking.FormatMarkdown
+		}, ""},
+		{"flag overrides positional", "analyze " + testMint + " sol 2 --limit=9", func(r *AnalyzeRequest) bool {
+			return r.Limit == 9
+		}, ""},
+		{"unknown option", "analyze " + testMint + " sol color=red", nil, `unknown option "color"`},
+		{"unknown flag", "analyze " + testMint + " sol --formt=csv", nil, "did you mean --format?"},
+		{"unterminated quote", `analyze "` + testMint + " sol", nil, "unterminated"},
+		{"extra argument", "analyze " + testMint + " sol 5 6", nil, `invalid option "6"`},
+		{"missing network", "analyze " + testMint, nil, "usage:"},
+		{"wrong command", "rank " + testMint + " sol", nil, `unknown command "rank"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := parseCommand(tt.task, now)
+			if tt.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+					t.Fatalf("parseCommand() error = %v, want error containing %q", err, tt.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseCommand() error = %v", err)
+			}
+			if !tt.check(req) {
+				t.Errorf("parseCommand() = %+v", req)
+			}
+		})
+	}
+}
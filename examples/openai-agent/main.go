@@ -21,19 +21,24 @@ import (
 // returns the top profitable wallets by realized PnL from swap activity.
 type AlphaHandler struct {
 	heliusClient *helius.Client
+	blocklist    *ranking.Blocklist
 }
 
 func (h *AlphaHandler) ProcessTask(ctx context.Context, task string) (string, error) {
 	// 1. Parse and validate the input command
 	req, err := validator.ParseCommand(task)
 	if err != nil {
-		return fmt.Sprintf("Invalid command: %v\n\nUsage: analyze <contract_address> sol [limit]", err), nil
+		return fmt.Sprintf("Invalid command: %v\n\nUsage: analyze <contract_address> sol [limit] [from=7d] [to=2024-05-08] [max=1000] [rank=roi] [min-trades=3] [min-volume=1] [format=csv]", err), nil
 	}
 
 	log.Printf("🔍 Analyzing token %s on %s (limit: %d)", req.ContractAddress, req.Network, req.Limit)
 
 	// 2. Fetch swap transactions from Helius
-	txns, err := h.heliusClient.FetchSwapTransactions(ctx, req.ContractAddress)
+	txns, err := h.heliusClient.FetchSwapTransactions(ctx, req.ContractAddress, helius.FetchOptions{
+		From:            req.FromTime,
+		To:              req.ToTime,
+		MaxTransactions: req.MaxSwaps,
+	})
 	if err != nil {
 		return fmt.Sprintf("Error fetching swap data: %v", err), nil
 	}
@@ -56,8 +61,29 @@ func (h *AlphaHandler) ProcessTask(ctx context.Context, task string) (string, er
 	walletPnLs := engine.ComputePnL(swaps)
 
 	// 5. Rank wallets and format output
-	ranked := ranking.RankWallets(walletPnLs, req.Limit)
-	return ranking.FormatOutput(ranked, req.ContractAddress), nil
+	opts := ranking.Options{
+		Mode:      req.RankBy,
+		Network:   req.Network,
+		Blocklist: h.blocklist,
+		MinTrades: req.MinTrades,
+		MinVolume: req.MinVolume,
+	}
+	ranked := ranking.Rank(walletPnLs, req.Limit, opts)
+	if len(ranked) == 0 && opts.HasThresholds() {
+		// Only blame the thresholds if some wallet would rank without them
+		unfiltered := opts
+		unfiltered.MinTrades, unfiltered.MinVolume = 0, 0
+		// CSV and JSON stay machine-readable and simply have no rows
+		humanReadable := req.Format == ranking.FormatText || req.Format == ranking.FormatMarkdown
+		if humanReadable && len(ranking.Rank(walletPnLs, 1, unfiltered)) > 0 {
+			return ranking.FormatNoMatches(req.ContractAddress, opts), nil
+		}
+	}
+	output, err := ranking.Format(ranked, req.ContractAddress, req.Format)
+	if err != nil {
+		return fmt.Sprintf("Error formatting output: %v", err), nil
+	}
+	return output, nil
 }
 
 func main() {
@@ -76,18 +102,28 @@ func main() {
 	}
 
 	heliusClient := helius.NewClient(cfg.HeliusAPIKey, cfg.HeliusBaseURL)
+	heliusClient.SetMaxTransactions(cfg.HeliusMaxTransactions)
+	heliusClient.SetMaxAge(cfg.HeliusMaxAge)
+
+	blocklist := ranking.DefaultBlocklist()
+	if !cfg.UseDefaultBlocklist {
+		blocklist = ranking.NewBlocklist()
+	}
+	if err := blocklist.AddEntries(cfg.WalletBlocklist); err != nil {
+		log.Fatalf("Invalid WALLET_BLOCKLIST: %v", err)
+	}
 
 	// Agent Configuration
 	agentConfig := agent.DefaultConfig()
 	agentConfig.Name = "Alpha Wallet Finder"
-	agentConfig.Description = "Analyzes a Solana token contract and returns the top profitable wallets by realized PnL from swap activity. Usage: analyze <contract_address> sol [limit]"
+	agentConfig.Description = "Analyzes a Solana token contract and returns the top profitable wallets by realized PnL from swap activity. Usage: analyze <contract_address> sol [limit] [from=7d] [to=<date>] [max=<swaps>] [rank=pnl|roi] [min-trades=<n>] [min-volume=<sol>] [format=text|markdown|csv|json]"
 	agentConfig.Capabilities = []string{"analyze_address"}
 	agentConfig.PrivateKey = privateKey
 
 	// Enhanced Agent Config
 	enhancedConfig := &agent.EnhancedAgentConfig{
 		Config:       agentConfig,
-		AgentHandler: &AlphaHandler{heliusClient: heliusClient},
+		AgentHandler: &AlphaHandler{heliusClient: heliusClient, blocklist: blocklist},
 	}
 
 	// NFT Configuration Logic
@@ -0,0 +1,214 @@
+// Package breaker wraps chain and price services in circuit breakers, so an
+// upstream outage fails fast instead of every analysis waiting on it.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/core/domain"
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/network"
+)
+
+// Config sets when a wrapped service's circuit opens and for how long
+type Config struct {
+	// MaxFailures is the number of consecutive failed calls that opens the
+	// circuit
+	MaxFailures int
+
+	// Cooldown is how long an open circuit fails calls without trying the
+	// service; the first call after it tests whether the service recovered
+	Cooldown time.Duration
+}
+
+// DefaultConfig returns the thresholds used by the agent
+func DefaultConfig() Config {
+	return Config{
+		MaxFailures: 5,
+		Cooldown:    30 * time.Second,
+	}
+}
+
+// newCircuitBreaker creates a breaker for the named service that logs its
+// state changes
+func newCircuitBreaker(name string, config Config) *network.CircuitBreaker {
+	defaults := DefaultConfig()
+	if config.MaxFailures <= 0 {
+		config.MaxFailures = defaults.MaxFailures
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = defaults.Cooldown
+	}
+
+	cb := network.NewCircuitBreaker(config.MaxFailures, config.Cooldown)
+	cb.SetStateChangeHandler(func(from, to network.CircuitState) {
+		log.Printf("⚡ %s circuit breaker: %s -> %s", name, from, to)
+	})
+	return cb
+}
+
+// call runs fn through the breaker. Answers that mean the service is up,
+// such as a token without historical prices, and calls abandoned by the
+// caller do not count as failures.
+func call(ctx context.Context, cb *network.CircuitBreaker, name string, fn func() error) error {
+	if !cb.CanAttempt() {
+		return fmt.Errorf("%s: %w", name, network.ErrCircuitOpen)
+	}
+
+	err := fn()
+	if err != nil && !isServiceFailure(ctx, err) {
+		cb.RecordResult(nil)
+		return err
+	}
+	cb.RecordResult(err)
+	return err
+}
+
+// isServiceFailure reports whether err says the service is unavailable
+func isServiceFailure(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	return !errors.Is(err, domain.ErrHistoricalPriceUnavailable) && !errors.Is(err, domain.ErrUnsupportedChain)
+}
+
+// sourceName returns the provider name of a wrapped service
+func sourceName(service interface{}) string {
+	if ds, ok := service.(domain.DataSource); ok {
+		return ds.SourceName()
+	}
+	return "unknown"
+}
+
+// ChainService is a domain.ChainService whose calls pass through a circuit
+// breaker. It forwards the optional interfaces of the service it wraps.
+type ChainService struct {
+	inner   domain.ChainService
+	name    string
+	breaker *network.CircuitBreaker
+}
+
+// NewChainService wraps a chain service in a circuit breaker
+func NewChainService(inner domain.ChainService, config Config) *ChainService {
+	name := sourceName(inner)
+	return &ChainService{inner: inner, name: name, breaker: newCircuitBreaker(name, config)}
+}
+
+// State returns the state of the service's circuit
+func (s *ChainService) State() network.CircuitState {
+	return s.breaker.GetState()
+}
+
+func (s *ChainService) IsSupported(chain string) bool {
+	return s.inner.IsSupported(chain)
+}
+
+func (s *ChainService) GetTokenMetadata(ctx context.Context, tokenAddress string) (*domain.TokenMetadata, error) {
+	var meta *domain.TokenMetadata
+	err := call(ctx, s.breaker, s.name, func() (err error) {
+		meta, err = s.inner.GetTokenMetadata(ctx, tokenAddress)
+		return err
+	})
+	return meta, err
+}
+
+func (s *ChainService) GetTrades(ctx context.Context, tokenAddress string) ([]domain.Trade, error) {
+	var trades []domain.Trade
+	err := call(ctx, s.breaker, s.name, func() (err error) {
+		trades, err = s.inner.GetTrades(ctx, tokenAddress)
+		return err
+	})
+	return trades, err
+}
+
+func (s *ChainService) GetHoldersWithTrades(ctx context.Context, tokenAddress string) (map[string][]domain.Trade, error) {
+	var holders map[string][]domain.Trade
+	err := call(ctx, s.breaker, s.name, func() (err error) {
+		holders, err = s.inner.GetHoldersWithTrades(ctx, tokenAddress)
+		return err
+	})
+	return holders, err
+}
+
+// GetHoldersWithTradesInWindow narrows the fetch when the wrapped service
+// supports windows and otherwise fetches every trade; AgentService filters
+// the result to the window either way.
+func (s *ChainService) GetHoldersWithTradesInWindow(ctx context.Context, tokenAddress string, window domain.TradeWindow) (map[string][]domain.Trade, error) {
+	windowed, ok := s.inner.(domain.WindowedChainService)
+	if !ok {
+		return s.GetHoldersWithTrades(ctx, tokenAddress)
+	}
+
+	var holders map[string][]domain.Trade
+	err := call(ctx, s.breaker, s.name, func() (err error) {
+		holders, err = windowed.GetHoldersWithTradesInWindow(ctx, tokenAddress, window)
+		return err
+	})
+	return holders, err
+}
+
+// SourceName reports the wrapped service's provider
+func (s *ChainService) SourceName() string {
+	return s.name
+}
+
+// TransactionCap reports the wrapped service's cap, or 0 when it has none
+func (s *ChainService) TransactionCap() int {
+	if capped, ok := s.inner.(domain.CappedSource); ok {
+		return capped.TransactionCap()
+	}
+	return 0
+}
+
+// PriceService is a domain.PriceService whose calls pass through a circuit
+// breaker. It forwards the optional interfaces of the service it wraps.
+type PriceService struct {
+	inner   domain.PriceService
+	name    string
+	breaker *network.CircuitBreaker
+}
+
+// NewPriceService wraps a price service in a circuit breaker
+func NewPriceService(inner domain.PriceService, config Config) *PriceService {
+	name := sourceName(inner)
+	return &PriceService{inner: inner, name: name, breaker: newCircuitBreaker(name, config)}
+}
+
+// State returns the state of the service's circuit
+func (s *PriceService) State() network.CircuitState {
+	return s.breaker.GetState()
+}
+
+func (s *PriceService) GetCurrentPrice(ctx context.Context, chain, tokenAddress string) (float64, error) {
+	var price float64
+	err := call(ctx, s.breaker, s.name, func() (err error) {
+		price, err = s.inner.GetCurrentPrice(ctx, chain, tokenAddress)
+		return err
+	})
+	return price, err
+}
+
+func (s *PriceService) PriceHistory(ctx context.Context, chain, tokenAddress string, from, to time.Time) ([]domain.PricePoint, error) {
+	var points []domain.PricePoint
+	err := call(ctx, s.breaker, s.name, func() (err error) {
+		points, err = s.inner.PriceHistory(ctx, chain, tokenAddress, from, to)
+		return err
+	})
+	return points, err
+}
+
+// SourceName reports the wrapped service's provider
+func (s *PriceService) SourceName() string {
+	return s.name
+}
+
+// HistoricalSourceName reports the wrapped service's historical provider
+func (s *PriceService) HistoricalSourceName() string {
+	if hs, ok := s.inner.(domain.HistoricalDataSource); ok {
+		return hs.HistoricalSourceName()
+	}
+	return s.name
+}
@@ -0,0 +1,170 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/core/domain"
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/network"
+)
+
+// flakyPrice fails while down is set and counts the calls that reach it
+type flakyPrice struct {
+	down  bool
+	err   error
+	calls int
+}
+
+func (f *flakyPrice) GetCurrentPrice(ctx context.Context, chain, tokenAddress string) (float64, error) {
+	f.calls++
+	if f.down {
+		return 0, errors.New("503 service unavailable")
+	}
+	return 2, nil
+}
+
+func (f *flakyPrice) PriceHistory(ctx context.Context, chain, tokenAddress string, from, to time.Time) ([]domain.PricePoint, error) {
+	f.calls++
+	return nil, f.err
+}
+
+func (f *flakyPrice) SourceName() string { return "dexscreener" }
+
+// flakyChain fails every call while down is set
+type flakyChain struct {
+	down  bool
+	calls int
+}
+
+func (f *flakyChain) IsSupported(chain string) bool { return chain == "ethereum" }
+
+func (f *flakyChain) GetTokenMetadata(ctx context.Context, tokenAddress string) (*domain.TokenMetadata, error) {
+	return f.respond(&domain.TokenMetadata{Symbol: "TEST", Decimals: 18})
+}
+
+func (f *flakyChain) GetTrades(ctx context.Context, tokenAddress string) ([]domain.Trade, error) {
+	return nil, nil
+}
+
+func (f *flakyChain) GetHoldersWithTrades(ctx context.Context, tokenAddress string) (map[string][]domain.Trade, error) {
+	_, err := f.respond(nil)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]domain.Trade{"0xwallet": {{Type: "buy", Amount: 1}}}, nil
+}
+
+func (f *flakyChain) respond(meta *domain.TokenMetadata) (*domain.TokenMetadata, error) {
+	f.calls++
+	if f.down {
+		return nil, errors.New("rpc timeout")
+	}
+	return meta, nil
+}
+
+func (f *flakyChain) SourceName() string { return "alchemy" }
+
+func (f *flakyChain) TransactionCap() int { return 1000 }
+
+func TestPriceService_Transitions(t *testing.T) {
+	inner := &flakyPrice{down: true}
+	svc := NewPriceService(inner, Config{MaxFailures: 3, Cooldown: 50 * time.Millisecond})
+	ctx := context.Background()
+
+	// Closed: failures reach the service until the threshold
+	for i := 0; i < 3; i++ {
+		if svc.State() != network.CircuitClosed {
+			t.Fatalf("state after %d failures = %s, want closed", i, svc.State())
+		}
+		if _, err := svc.GetCurrentPrice(ctx, "ethereum", "0xtoken"); err == nil {
+			t.Fatal("GetCurrentPrice() succeeded while the service is down")
+		}
+	}
+
+	// Open: calls fail fast without reaching the service
+	if svc.State() != network.CircuitOpen {
+		t.Fatalf("state = %s, want open", svc.State())
+	}
+	if _, err := svc.GetCurrentPrice(ctx, "ethereum", "0xtoken"); !errors.Is(err, network.ErrCircuitOpen) {
+		t.Errorf("GetCurrentPrice() error = %v, want ErrCircuitOpen", err)
+	}
+	if inner.calls != 3 {
+		t.Errorf("service called %d times, want 3", inner.calls)
+	}
+
+	// Half-open: after the cooldown a failed trial reopens the circuit
+	time.Sleep(60 * time.Millisecond)
+	if _, err := svc.GetCurrentPrice(ctx, "ethereum", "0xtoken"); err == nil || errors.Is(err, network.ErrCircuitOpen) {
+		t.Errorf("trial GetCurrentPrice() error = %v, want the service's error", err)
+	}
+	if svc.State() != network.CircuitOpen || inner.calls != 4 {
+		t.Fatalf("state = %s after %d calls, want open after one trial", svc.State(), inner.calls)
+	}
+
+	// Half-open: a successful trial closes it again
+	inner.down = false
+	time.Sleep(60 * time.Millisecond)
+	if price, err := svc.GetCurrentPrice(ctx, "ethereum", "0xtoken"); err != nil || price != 2 {
+		t.Fatalf("trial GetCurrentPrice() = %v, %v, want 2", price, err)
+	}
+	if svc.State() != network.CircuitClosed {
+		t.Errorf("state = %s, want closed after a successful trial", svc.State())
+	}
+}
+
+func TestHalfOpenAllowsOneTrial(t *testing.T) {
+	svc := NewPriceService(&flakyPrice{down: true}, Config{MaxFailures: 1, Cooldown: 20 * time.Millisecond})
+	ctx := context.Background()
+	svc.GetCurrentPrice(ctx, "ethereum", "0xtoken")
+	time.Sleep(30 * time.Millisecond)
+
+	if !svc.breaker.CanAttempt() {
+		t.Fatal("no trial allowed after the cooldown")
+	}
+	if svc.State() != network.CircuitHalfOpen {
+		t.Fatalf("state = %s, want half-open", svc.State())
+	}
+	if svc.breaker.CanAttempt() {
+		t.Error("a second trial was allowed while the first is in flight")
+	}
+}
+
+func TestCall_IgnoresHealthyAnswersAndCancellation(t *testing.T) {
+	inner := &flakyPrice{err: domain.ErrHistoricalPriceUnavailable}
+	svc := NewPriceService(inner, Config{MaxFailures: 1, Cooldown: time.Minute})
+
+	if _, err := svc.PriceHistory(context.Background(), "ethereum", "0xtoken", time.Now().Add(-time.Hour), time.Now()); !errors.Is(err, domain.ErrHistoricalPriceUnavailable) {
+		t.Errorf("PriceHistory() error = %v, want ErrHistoricalPriceUnavailable", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	inner.err = context.Canceled
+	svc.PriceHistory(ctx, "ethereum", "0xtoken", time.Now().Add(-time.Hour), time.Now())
+
+	if svc.State() != network.CircuitClosed {
+		t.Errorf("state = %s, want closed: the service never failed", svc.State())
+	}
+}
+
+func TestChainService_OpensAndForwardsOptionalInterfaces(t *testing.T) {
+	inner := &flakyChain{down: true}
+	svc := NewChainService(inner, Config{MaxFailures: 2, Cooldown: time.Minute})
+	ctx := context.Background()
+
+	if svc.SourceName() != "alchemy" || svc.TransactionCap() != 1000 || !svc.IsSupported("ethereum") {
+		t.Errorf("wrapper reports %q, cap %d; want the wrapped service's", svc.SourceName(), svc.TransactionCap())
+	}
+
+	// Failures of any method count towards the same circuit
+	svc.GetTokenMetadata(ctx, "0xtoken")
+	svc.GetHoldersWithTradesInWindow(ctx, "0xtoken", domain.TradeWindow{MaxSwaps: 10})
+	if _, err := svc.GetHoldersWithTrades(ctx, "0xtoken"); !errors.Is(err, network.ErrCircuitOpen) {
+		t.Errorf("GetHoldersWithTrades() error = %v, want ErrCircuitOpen", err)
+	}
+	if inner.calls != 2 {
+		t.Errorf("service called %d times, want 2", inner.calls)
+	}
+}
@@ -3,27 +3,42 @@ package chain
 import (
 	"bytes"
 	"context"
-	"encoding/json"
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
-	"net/http"
+	"math/big"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/core/domain"
 )
 
+// ERC-20 metadata getter selectors
+const (
+	selectorName     = "0x06fdde03"
+	selectorSymbol   = "0x95d89b41"
+	selectorDecimals = "0x313ce567"
+)
+
+// maxAssetTransfers is the number of transfers requested from alchemy_getAssetTransfers
+const maxAssetTransfers = 1000
+
 // EthereumService implements ChainService for EVM chains.
 // Uses a generic structure potentially compatible with Covalent or custom Indexers.
 // For this implementation, we will assume a generic "Covalent-like" API structure for simplicity,
 // or a mockable interface if keys are missing.
 type EthereumService struct {
 	rpcURL string
-	client *http.Client
+	rpc    *rpcClient
 }
 
-func NewEthereumService(rpcURL string) *EthereumService {
+// NewEthereumService creates an EVM chain service. Read-only RPC calls are
+// retried on transient errors according to retry; nil uses DefaultRetryConfig.
+func NewEthereumService(rpcURL string, retry *RetryConfig) *EthereumService {
 	return &EthereumService{
 		rpcURL: rpcURL,
-		client: &http.Client{Timeout: 30 * time.Second},
+		rpc:    newRPCClient(rpcURL, retry),
 	}
 }
 
@@ -31,11 +46,21 @@ func (s *EthereumService) IsSupported(chain string) bool {
 	return chain == "ethereum" || chain == "eth"
 }
 
+// SourceName reports the RPC provider used for trades
+func (s *EthereumService) SourceName() string {
+	return providerName(s.rpcURL)
+}
+
+// TransactionCap is the single-page transfer limit of GetHoldersWithTrades.
+// Several transfers can share a transaction hash, so it counts transfers.
+func (s *EthereumService) TransactionCap() int {
+	return maxAssetTransfers
+}
+
+// GetTokenMetadata reads the token's ERC-20 decimals(), symbol() and name().
+// decimals() is required to interpret amounts; symbol and name are optional
+// in ERC-20, so tokens that do not implement them report empty values.
 func (s *EthereumService) GetTokenMetadata(ctx context.Context, tokenAddress string) (*domain.TokenMetadata, error) {
-	// In a real app, call an API (e.g. Alchemy getTokenMetadata).
-	// For this task, we'll return a stub or attempt a basic RPC call if we had an RPC URL.
-	// We will assume the user has set up the environment and we might rely on a third party API.
-	
 	// Mock/Stub for demonstration if URL is missing
 	if s.rpcURL == "" {
 		return &domain.TokenMetadata{
@@ -45,13 +70,44 @@ func (s *EthereumService) GetTokenMetadata(ctx context.Context, tokenAddress str
 		}, nil
 	}
 
-	// Example: Call a hypothetical metadata endpoint using s.rpcURL
-	// ... implementation omitted for brevity, returning placeholder
-	return &domain.TokenMetadata{
-		Symbol:   "TOKEN",
-		Decimals: 18,
-		Name:     "Real Token",
-	}, nil
+	data, err := s.callERC20(ctx, tokenAddress, selectorDecimals)
+	if err != nil {
+		return nil, fmt.Errorf("decimals() call failed: %w", err)
+	}
+	decimals, err := decodeUint8(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid decimals() result: %w", err)
+	}
+
+	meta := &domain.TokenMetadata{Decimals: decimals}
+	if data, err := s.callERC20(ctx, tokenAddress, selectorSymbol); err == nil {
+		meta.Symbol = decodeABIString(data)
+	}
+	if data, err := s.callERC20(ctx, tokenAddress, selectorName); err == nil {
+		meta.Name = decodeABIString(data)
+	}
+	return meta, nil
+}
+
+// callERC20 performs an eth_call of a zero-argument ERC-20 getter
+func (s *EthereumService) callERC20(ctx context.Context, tokenAddress, selector string) ([]byte, error) {
+	var result string
+	params := []interface{}{
+		map[string]string{"to": tokenAddress, "data": selector},
+		"latest",
+	}
+	if err := s.rpc.Call(ctx, "eth_call", params, &result); err != nil {
+		return nil, err
+	}
+
+	data, err := hex.DecodeString(strings.TrimPrefix(result, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid eth_call result: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty eth_call result, %s may not be a contract", tokenAddress)
+	}
+	return data, nil
 }
 
 // GetHoldersWithTrades fetches trades. 
@@ -59,89 +115,75 @@ func (s *EthereumService) GetTokenMetadata(ctx context.Context, tokenAddress str
 // We will simulate the behavior of fetching from an Indexer API.
 // GetHoldersWithTrades fetches trades using Alchemy's Asset Transfers API.
 func (s *EthereumService) GetHoldersWithTrades(ctx context.Context, tokenAddress string) (map[string][]domain.Trade, error) {
+	return s.fetchTransfers(ctx, tokenAddress, "asc", maxAssetTransfers)
+}
+
+// GetHoldersWithTradesInWindow fetches the newest transfers first so a window
+// over recent activity is not crowded out by old transfers, and requests only
+// window.MaxSwaps transfers when that is below the usual cap. Alchemy filters
+// by block rather than time, so AgentService applies the time bounds.
+func (s *EthereumService) GetHoldersWithTradesInWindow(ctx context.Context, tokenAddress string, window domain.TradeWindow) (map[string][]domain.Trade, error) {
+	maxCount := maxAssetTransfers
+	if window.MaxSwaps > 0 && window.MaxSwaps < maxCount {
+		maxCount = window.MaxSwaps
+	}
+	return s.fetchTransfers(ctx, tokenAddress, "desc", maxCount)
+}
+
+// fetchTransfers converts up to maxCount ERC-20 transfers of the token,
+// in the given block order, into buy and sell trades per wallet.
+func (s *EthereumService) fetchTransfers(ctx context.Context, tokenAddress, order string, maxCount int) (map[string][]domain.Trade, error) {
 	if s.rpcURL == "" {
 		return s.mockTrades()
 	}
 
 	// Payload for alchemy_getAssetTransfers
 	// Docs: https://docs.alchemy.com/reference/alchemy-getassettransfers
-	payload := map[string]interface{}{
-		"jsonrpc": "2.0",
-		"id":      1,
-		"method":  "alchemy_getAssetTransfers",
-		"params": []interface{}{
-			map[string]interface{}{
-				"fromBlock":         "0x0",
-				"toBlock":           "latest",
-				"contractAddresses": []string{tokenAddress},
-				"category":          []string{"erc20"},
-				"withMetadata":      true,
-				"maxCount":          "0x3e8", // 1000 transfers limit for this demo
-			},
+	params := []interface{}{
+		map[string]interface{}{
+			"fromBlock":         "0x0",
+			"toBlock":           "latest",
+			"contractAddresses": []string{tokenAddress},
+			"category":          []string{"erc20"},
+			"withMetadata":      true,
+			"order":             order,
+			"maxCount":          fmt.Sprintf("0x%x", maxCount),
 		},
 	}
 
-	reqBody, err := json.Marshal(payload) // Need to import "encoding/json"
-	if err != nil {
-		return nil, err
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", s.rpcURL, bytes.NewBuffer(reqBody)) // Need "bytes"
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := s.client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("alchemy api error: %d", resp.StatusCode)
-	}
-
 	// Response structure
-	type Transfer struct {
-		from   string
-		to     string
-		value  float64
-		hash   string
-		block  string
-		meta   struct {
-			blockTimestamp string
-		}
+	var result struct {
+		Transfers []struct {
+			From     string  `json:"from"`
+			To       string  `json:"to"`
+			Value    float64 `json:"value"`
+			Hash     string  `json:"hash"`
+			Metadata struct {
+				BlockTimestamp string `json:"blockTimestamp"`
+			} `json:"metadata"`
+			RawContract struct {
+				Value   string `json:"value"`   // Hex amount in base units
+				Decimal string `json:"decimal"` // Hex token decimals
+			} `json:"rawContract"`
+		} `json:"transfers"`
 	}
-	// Need a custom struct to decode
-	var rpcResp struct {
-		Result struct {
-			Transfers []struct {
-				From     string  `json:"from"`
-				To       string  `json:"to"`
-				Value    float64 `json:"value"`
-				Hash     string  `json:"hash"`
-				Metadata struct {
-					BlockTimestamp string `json:"blockTimestamp"`
-				} `json:"metadata"`
-			} `json:"transfers"`
-		} `json:"result"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
-		return nil, err
+
+	if err := s.rpc.Call(ctx, "alchemy_getAssetTransfers", params, &result); err != nil {
+		return nil, fmt.Errorf("alchemy api error: %w", err)
 	}
 
 	trades := make(map[string][]domain.Trade)
 
 	// Transform Transfers into Trades
 	// Note: We don't have historical prices here, so we set PriceUSD to 0.
-	// This means Realized PnL will be ignored or 100%, and Unrealized will appear as pure profit.
-	// This addresses the "negative PnL" verified by the user (mock data had high buy price).
-	for _, tx := range rpcResp.Result.Transfers {
+	// AgentService prices these trades from the PriceService at the transfer timestamp.
+	for _, tx := range result.Transfers {
 		// Timestamp parsing
 		ts, _ := time.Parse(time.RFC3339, tx.Metadata.BlockTimestamp)
 
+		// Keep the exact base-unit amount; Alchemy's value is a rounded float
+		raw, decimals, _ := parseRawAmount(tx.RawContract.Value, tx.RawContract.Decimal)
+
 		// "Buy" side (To)
 		trades[tx.To] = append(trades[tx.To], domain.Trade{
 			Type:      "buy",
@@ -149,6 +191,8 @@ func (s *EthereumService) GetHoldersWithTrades(ctx context.Context, tokenAddress
 			PriceUSD:  0, // Missing historical price
 			Timestamp: ts,
 			TxHash:    tx.Hash,
+			RawAmount: raw,
+			Decimals:  decimals,
 		})
 
 		// "Sell" side (From)
@@ -158,6 +202,8 @@ func (s *EthereumService) GetHoldersWithTrades(ctx context.Context, tokenAddress
 			PriceUSD:  0, // Missing historical price
 			Timestamp: ts,
 			TxHash:    tx.Hash,
+			RawAmount: raw,
+			Decimals:  decimals,
 		})
 	}
 
@@ -172,3 +218,72 @@ func (s *EthereumService) mockTrades() (map[string][]domain.Trade, error) {
 	// Fallback mock
 	return make(map[string][]domain.Trade), nil
 }
+
+// decodeUint8 decodes an ABI-encoded uint8 return value
+func decodeUint8(data []byte) (int, error) {
+	if len(data) < 32 {
+		return 0, fmt.Errorf("expected 32 bytes, got %d", len(data))
+	}
+	for _, b := range data[:31] {
+		if b != 0 {
+			return 0, fmt.Errorf("value does not fit in uint8")
+		}
+	}
+	return int(data[31]), nil
+}
+
+// decodeABIString decodes an ABI-encoded string return value. Some older
+// tokens (e.g. MKR) return bytes32 instead, which is decoded as a
+// NUL-padded string. Malformed data decodes to "".
+func decodeABIString(data []byte) string {
+	if len(data) == 32 {
+		return strings.ToValidUTF8(string(bytes.TrimRight(data, "\x00")), "")
+	}
+	if len(data) < 64 {
+		return ""
+	}
+
+	offset, ok := abiWord(data, 0)
+	if !ok {
+		return ""
+	}
+	length, ok := abiWord(data, offset)
+	if !ok || offset+32+length > uint64(len(data)) {
+		return ""
+	}
+	start := offset + 32
+	return strings.ToValidUTF8(string(data[start:start+length]), "")
+}
+
+// abiWord reads the 32-byte big-endian word at offset as a uint64, failing
+// when it is out of range or too large to be a valid offset or length
+func abiWord(data []byte, offset uint64) (uint64, bool) {
+	if offset+32 > uint64(len(data)) {
+		return 0, false
+	}
+	word := data[offset : offset+32]
+	for _, b := range word[:24] {
+		if b != 0 {
+			return 0, false
+		}
+	}
+	v := binary.BigEndian.Uint64(word[24:])
+	if v > uint64(len(data)) {
+		return 0, false
+	}
+	return v, true
+}
+
+// parseRawAmount parses Alchemy's hex rawContract value and decimal fields,
+// reporting false when either is missing or malformed
+func parseRawAmount(value, decimal string) (*big.Int, int, bool) {
+	raw, ok := new(big.Int).SetString(strings.TrimPrefix(value, "0x"), 16)
+	if !ok || raw.Sign() < 0 {
+		return nil, 0, false
+	}
+	decimals, err := strconv.ParseUint(strings.TrimPrefix(decimal, "0x"), 16, 8)
+	if err != nil {
+		return nil, 0, false
+	}
+	return raw, int(decimals), true
+}
@@ -0,0 +1,263 @@
+package chain
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// rpcHandler answers a single JSON-RPC call; a non-nil *RPCError is returned to the client
+type rpcHandler func(params []json.RawMessage) (interface{}, *RPCError)
+
+// fakeRPCMethods dispatches JSON-RPC requests to handlers by method name
+func fakeRPCMethods(t *testing.T, handlers map[string]rpcHandler) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("invalid rpc request: %v", err)
+			return
+		}
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": 1}
+		handler, ok := handlers[req.Method]
+		if !ok {
+			resp["error"] = &RPCError{Code: -32601, Message: "method not found"}
+		} else if result, rpcErr := handler(req.Params); rpcErr != nil {
+			resp["error"] = rpcErr
+		} else {
+			resp["result"] = result
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// abiUint encodes v as a 32-byte ABI word
+func abiUint(v uint64) []byte {
+	return new(big.Int).SetUint64(v).FillBytes(make([]byte, 32))
+}
+
+// abiString ABI-encodes s as a dynamic string return value
+func abiString(s string) []byte {
+	data := append(abiUint(32), abiUint(uint64(len(s)))...)
+	padded := make([]byte, (len(s)+31)/32*32)
+	copy(padded, s)
+	return append(data, padded...)
+}
+
+// erc20Calls answers eth_call by selector; missing selectors revert
+func erc20Calls(results map[string][]byte) rpcHandler {
+	return func(params []json.RawMessage) (interface{}, *RPCError) {
+		var call struct {
+			Data string `json:"data"`
+		}
+		json.Unmarshal(params[0], &call)
+		data, ok := results[call.Data]
+		if !ok {
+			return nil, &RPCError{Code: 3, Message: "execution reverted"}
+		}
+		return "0x" + hex.EncodeToString(data), nil
+	}
+}
+
+func TestEthereumService_GetTokenMetadata(t *testing.T) {
+	bytes32Symbol := make([]byte, 32)
+	copy(bytes32Symbol, "MKR")
+
+	tests := []struct {
+		name    string
+		results map[string][]byte
+		want    string // symbol/name/decimals
+		wantErr bool
+	}{
+		{
+			name: "standard token",
+			results: map[string][]byte{
+				selectorDecimals: abiUint(6),
+				selectorSymbol:   abiString("USDC"),
+				selectorName:     abiString("USD Coin"),
+			},
+			want: "USDC/USD Coin/6",
+		},
+		{
+			name: "bytes32 symbol and name",
+			results: map[string][]byte{
+				selectorDecimals: abiUint(18),
+				selectorSymbol:   bytes32Symbol,
+				selectorName:     bytes32Symbol,
+			},
+			want: "MKR/MKR/18",
+		},
+		{
+			name:    "missing symbol and name",
+			results: map[string][]byte{selectorDecimals: abiUint(0)},
+			want:    "//0",
+		},
+		{
+			name: "malformed symbol",
+			results: map[string][]byte{
+				selectorDecimals: abiUint(8),
+				selectorSymbol:   append(abiUint(32), abiUint(1000)...),
+			},
+			want: "//8",
+		},
+		{
+			name:    "missing decimals",
+			results: map[string][]byte{selectorSymbol: abiString("NODEC")},
+			wantErr: true,
+		},
+		{
+			name:    "decimals out of range",
+			results: map[string][]byte{selectorDecimals: abiUint(256)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := fakeRPCMethods(t, map[string]rpcHandler{"eth_call": erc20Calls(tt.results)})
+			svc := NewEthereumService(srv.URL, testRetryConfig(0))
+
+			meta, err := svc.GetTokenMetadata(context.Background(), "0xtoken")
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %+v", meta)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetTokenMetadata failed: %v", err)
+			}
+			got := meta.Symbol + "/" + meta.Name + "/" + strconv.Itoa(meta.Decimals)
+			if got != tt.want {
+				t.Errorf("metadata = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSolanaService_GetTokenMetadata(t *testing.T) {
+	mint := func(info map[string]interface{}) interface{} {
+		return map[string]interface{}{
+			"value": map[string]interface{}{
+				"data": map[string]interface{}{
+					"program": "spl-token-2022",
+					"parsed":  map[string]interface{}{"type": "mint", "info": info},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		result  interface{}
+		want    string // symbol/name/decimals
+		wantErr bool
+	}{
+		{
+			name: "token-2022 with metadata extension",
+			result: mint(map[string]interface{}{
+				"decimals": 6,
+				"extensions": []map[string]interface{}{
+					{"extension": "metadataPointer", "state": map[string]interface{}{}},
+					{"extension": "tokenMetadata", "state": map[string]interface{}{"name": "Paypal USD", "symbol": "PYUSD"}},
+				},
+			}),
+			want: "PYUSD/Paypal USD/6",
+		},
+		{
+			name:   "classic mint without metadata",
+			result: mint(map[string]interface{}{"decimals": 9}),
+			want:   "//9",
+		},
+		{
+			name:    "account not found",
+			result:  map[string]interface{}{"value": nil},
+			wantErr: true,
+		},
+		{
+			name:    "unparsed account",
+			result:  map[string]interface{}{"value": map[string]interface{}{"data": []string{"AAAA", "base64"}}},
+			wantErr: true,
+		},
+		{
+			name: "token account rather than mint",
+			result: map[string]interface{}{"value": map[string]interface{}{"data": map[string]interface{}{
+				"parsed": map[string]interface{}{"type": "account", "info": map[string]interface{}{}},
+			}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := fakeRPCMethods(t, map[string]rpcHandler{
+				"getAccountInfo": func(params []json.RawMessage) (interface{}, *RPCError) { return tt.result, nil },
+			})
+			svc := NewSolanaService(srv.URL, testRetryConfig(0))
+
+			meta, err := svc.GetTokenMetadata(context.Background(), "Mint1111111111111111111111111111111111111")
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %+v", meta)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetTokenMetadata failed: %v", err)
+			}
+			got := meta.Symbol + "/" + meta.Name + "/" + strconv.Itoa(meta.Decimals)
+			if got != tt.want {
+				t.Errorf("metadata = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEthereumService_KeepsRawTransferAmounts(t *testing.T) {
+	srv, _ := fakeRPC(t, 0, nil, map[string]interface{}{
+		"transfers": []map[string]interface{}{
+			{
+				"from":        "0xseller",
+				"to":          "0xbuyer",
+				"value":       1500.25,
+				"hash":        "0x1",
+				"rawContract": map[string]string{"value": "0x596bff90", "decimal": "0x6"},
+			},
+			{
+				"from":        "0xseller",
+				"to":          "0xbuyer",
+				"value":       nil,
+				"hash":        "0x2",
+				"rawContract": map[string]interface{}{"value": "0x1", "decimal": nil},
+			},
+		},
+	})
+	svc := NewEthereumService(srv.URL, testRetryConfig(0))
+
+	trades, err := svc.GetHoldersWithTrades(context.Background(), "0xusdc")
+	if err != nil {
+		t.Fatalf("GetHoldersWithTrades failed: %v", err)
+	}
+
+	buys := trades["0xbuyer"]
+	if len(buys) != 2 {
+		t.Fatalf("expected 2 buys, got %+v", buys)
+	}
+	if buys[0].RawAmount == nil || buys[0].RawAmount.String() != "1500250000" || buys[0].Decimals != 6 {
+		t.Errorf("expected raw amount 1500250000 with 6 decimals, got %v/%d", buys[0].RawAmount, buys[0].Decimals)
+	}
+	if buys[1].RawAmount != nil {
+		t.Errorf("expected no raw amount without decimals, got %v", buys[1].RawAmount)
+	}
+}
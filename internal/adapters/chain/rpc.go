@@ -0,0 +1,232 @@
+package chain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// RetryConfig controls how read-only RPC calls are retried.
+type RetryConfig struct {
+	MaxRetries    int           // Retries after the first attempt (0 disables retrying)
+	InitialDelay  time.Duration // Delay before the first retry
+	MaxDelay      time.Duration // Upper bound for the backoff delay
+	BackoffFactor float64       // Multiplier applied to the delay after each retry
+	CallTimeout   time.Duration // Timeout applied to each individual attempt
+}
+
+// DefaultRetryConfig returns the retry settings used when none are supplied.
+func DefaultRetryConfig() *RetryConfig {
+	return &RetryConfig{
+		MaxRetries:    3,
+		InitialDelay:  500 * time.Millisecond,
+		MaxDelay:      5 * time.Second,
+		BackoffFactor: 2.0,
+		CallTimeout:   30 * time.Second,
+	}
+}
+
+// RPCError is a JSON-RPC error object returned by the node.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// statusError is returned when the node answers with a non-200 HTTP status.
+type statusError struct {
+	StatusCode int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("rpc http status: %d", e.StatusCode)
+}
+
+// nonRetryableMessages are node error fragments that will never succeed on retry.
+var nonRetryableMessages = []string{
+	"execution reverted",
+	"invalid argument",
+	"method not found",
+	"invalid params",
+}
+
+// isRetryable reports whether err is a transient failure worth retrying.
+func isRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		msg := strings.ToLower(rpcErr.Message)
+		for _, fragment := range nonRetryableMessages {
+			if strings.Contains(msg, fragment) {
+				return false
+			}
+		}
+		// -32601 method not found, -32602 invalid params, -32600 invalid request
+		switch rpcErr.Code {
+		case -32600, -32601, -32602:
+			return false
+		}
+		return true
+	}
+
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+
+	// Network errors, per-call timeouts and malformed responses are treated as transient
+	return true
+}
+
+// rpcClient performs JSON-RPC calls with per-call timeouts and retry on transient errors.
+type rpcClient struct {
+	url    string
+	client *http.Client
+	retry  *RetryConfig
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+func newRPCClient(url string, retry *RetryConfig) *rpcClient {
+	if retry == nil {
+		retry = DefaultRetryConfig()
+	}
+	return &rpcClient{
+		url:    url,
+		client: &http.Client{},
+		retry:  retry,
+		sleep:  sleepContext,
+	}
+}
+
+// Call invokes method with params and decodes the JSON-RPC result into result.
+func (c *rpcClient) Call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal rpc request: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= c.retry.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if err := c.sleep(ctx, c.backoff(attempt)); err != nil {
+				return err
+			}
+		}
+
+		lastErr = c.do(ctx, payload, result)
+		if lastErr == nil {
+			return nil
+		}
+		if ctx.Err() != nil || !isRetryable(lastErr) {
+			return lastErr
+		}
+	}
+
+	return fmt.Errorf("%s failed after %d attempts: %w", method, c.retry.MaxRetries+1, lastErr)
+}
+
+// do performs a single attempt bounded by the configured call timeout.
+func (c *rpcClient) do(ctx context.Context, payload []byte, result interface{}) error {
+	if c.retry.CallTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.retry.CallTimeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return &statusError{StatusCode: resp.StatusCode}
+	}
+
+	var rpcResp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *RPCError       `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return fmt.Errorf("failed to decode rpc response: %w", err)
+	}
+	if rpcResp.Error != nil {
+		return rpcResp.Error
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(rpcResp.Result, result)
+}
+
+// backoff returns the delay before the given retry attempt (1-based).
+func (c *rpcClient) backoff(attempt int) time.Duration {
+	delay := float64(c.retry.InitialDelay)
+	for i := 1; i < attempt; i++ {
+		delay *= c.retry.BackoffFactor
+	}
+	if c.retry.MaxDelay > 0 && time.Duration(delay) > c.retry.MaxDelay {
+		return c.retry.MaxDelay
+	}
+	return time.Duration(delay)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// providerName derives a data source name from an RPC URL, recognising the
+// hosted providers the agent is usually configured with.
+func providerName(rpcURL string) string {
+	if rpcURL == "" {
+		return "mock"
+	}
+
+	host := rpcURL
+	if u, err := url.Parse(rpcURL); err == nil && u.Hostname() != "" {
+		host = u.Hostname()
+	}
+
+	lower := strings.ToLower(host)
+	for _, provider := range []string{"alchemy", "helius", "infura", "quicknode"} {
+		if strings.Contains(lower, provider) {
+			return provider
+		}
+	}
+	return host
+}
@@ -0,0 +1,168 @@
+package chain
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeRPC fails the first failures requests using fail, then answers with result.
+func fakeRPC(t *testing.T, failures int32, fail func(w http.ResponseWriter), result interface{}) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		if n <= failures {
+			fail(w)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"result":  result,
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func testRetryConfig(maxRetries int) *RetryConfig {
+	return &RetryConfig{
+		MaxRetries:    maxRetries,
+		InitialDelay:  time.Millisecond,
+		MaxDelay:      5 * time.Millisecond,
+		BackoffFactor: 2.0,
+		CallTimeout:   time.Second,
+	}
+}
+
+func TestRPCClient_RetriesTransientErrors(t *testing.T) {
+	srv, calls := fakeRPC(t, 2, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}, "0x1")
+
+	c := newRPCClient(srv.URL, testRetryConfig(3))
+
+	var result string
+	if err := c.Call(context.Background(), "eth_blockNumber", []interface{}{}, &result); err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if result != "0x1" {
+		t.Errorf("expected result 0x1, got %s", result)
+	}
+	if got := atomic.LoadInt32(calls); got != 3 {
+		t.Errorf("expected 3 calls, got %d", got)
+	}
+}
+
+func TestRPCClient_GivesUpAfterMaxRetries(t *testing.T) {
+	srv, calls := fakeRPC(t, 10, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusBadGateway)
+	}, "0x1")
+
+	c := newRPCClient(srv.URL, testRetryConfig(2))
+
+	if err := c.Call(context.Background(), "eth_blockNumber", []interface{}{}, nil); err == nil {
+		t.Fatal("expected error after exhausting retries")
+	}
+	if got := atomic.LoadInt32(calls); got != 3 {
+		t.Errorf("expected 3 calls (1 + 2 retries), got %d", got)
+	}
+}
+
+func TestRPCClient_NonRetryableFailsFast(t *testing.T) {
+	srv, calls := fakeRPC(t, 10, func(w http.ResponseWriter) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"error":   map[string]interface{}{"code": 3, "message": "execution reverted"},
+		})
+	}, "0x1")
+
+	c := newRPCClient(srv.URL, testRetryConfig(3))
+
+	err := c.Call(context.Background(), "eth_call", []interface{}{}, nil)
+	if err == nil {
+		t.Fatal("expected execution reverted error")
+	}
+	if _, ok := err.(*RPCError); !ok {
+		t.Errorf("expected *RPCError, got %T", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("expected 1 call for non-retryable error, got %d", got)
+	}
+}
+
+func TestRPCClient_PerCallTimeout(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(200 * time.Millisecond):
+			}
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"ok"}`))
+	}))
+	defer srv.Close()
+
+	cfg := testRetryConfig(1)
+	cfg.CallTimeout = 20 * time.Millisecond
+	c := newRPCClient(srv.URL, cfg)
+
+	var result string
+	if err := c.Call(context.Background(), "eth_chainId", []interface{}{}, &result); err != nil {
+		t.Fatalf("expected retry after timeout to succeed, got %v", err)
+	}
+	if result != "ok" {
+		t.Errorf("expected result ok, got %s", result)
+	}
+}
+
+func TestEthereumService_GetHoldersWithTradesRetries(t *testing.T) {
+	srv, calls := fakeRPC(t, 1, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}, map[string]interface{}{
+		"transfers": []map[string]interface{}{
+			{
+				"from":     "0xseller",
+				"to":       "0xbuyer",
+				"value":    10,
+				"hash":     "0xhash",
+				"metadata": map[string]string{"blockTimestamp": "2024-01-01T00:00:00Z"},
+			},
+		},
+	})
+
+	svc := NewEthereumService(srv.URL, testRetryConfig(2))
+
+	trades, err := svc.GetHoldersWithTrades(context.Background(), "0xtoken")
+	if err != nil {
+		t.Fatalf("GetHoldersWithTrades failed: %v", err)
+	}
+	if len(trades["0xbuyer"]) != 1 || len(trades["0xseller"]) != 1 {
+		t.Errorf("unexpected trades: %+v", trades)
+	}
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("expected 2 calls, got %d", got)
+	}
+}
+
+func TestProviderName(t *testing.T) {
+	tests := map[string]string{
+		"": "mock",
+		"https://eth-mainnet.g.alchemy.com/v2/key":  "alchemy",
+		"https://mainnet.helius-rpc.com/?api-key=x": "helius",
+		"http://localhost:8545":                     "localhost",
+	}
+	for url, want := range tests {
+		if got := providerName(url); got != want {
+			t.Errorf("providerName(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
@@ -2,8 +2,8 @@ package chain
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"net/http"
 	"time"
 
 	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/core/domain"
@@ -11,13 +11,15 @@ import (
 
 type SolanaService struct {
 	rpcURL string
-	client *http.Client
+	rpc    *rpcClient
 }
 
-func NewSolanaService(rpcURL string) *SolanaService {
+// NewSolanaService creates a Solana chain service. Read-only RPC calls are
+// retried on transient errors according to retry; nil uses DefaultRetryConfig.
+func NewSolanaService(rpcURL string, retry *RetryConfig) *SolanaService {
 	return &SolanaService{
 		rpcURL: rpcURL,
-		client: &http.Client{Timeout: 30 * time.Second},
+		rpc:    newRPCClient(rpcURL, retry),
 	}
 }
 
@@ -25,6 +27,14 @@ func (s *SolanaService) IsSupported(chain string) bool {
 	return chain == "solana" || chain == "sol"
 }
 
+// SourceName reports "mock" because trades are not fetched from the RPC yet
+func (s *SolanaService) SourceName() string {
+	return "mock"
+}
+
+// GetTokenMetadata reads the token mint account. Decimals come from the mint
+// itself; name and symbol come from the Token-2022 metadata extension, so
+// classic SPL tokens without it report empty values.
 func (s *SolanaService) GetTokenMetadata(ctx context.Context, tokenAddress string) (*domain.TokenMetadata, error) {
 	if s.rpcURL == "" {
 		return &domain.TokenMetadata{
@@ -33,9 +43,48 @@ func (s *SolanaService) GetTokenMetadata(ctx context.Context, tokenAddress strin
 			Name:     "Mock Sol Token",
 		}, nil
 	}
-	// Real Alchemy/Solana RPC call would go here
-	// e.g. "getTokenSupply" or "getAccountInfo"
-	return &domain.TokenMetadata{Symbol: "SOL-TOKEN", Decimals: 9}, nil
+
+	var result struct {
+		Value *struct {
+			Data json.RawMessage `json:"data"`
+		} `json:"value"`
+	}
+	params := []interface{}{tokenAddress, map[string]string{"encoding": "jsonParsed"}}
+	if err := s.rpc.Call(ctx, "getAccountInfo", params, &result); err != nil {
+		return nil, fmt.Errorf("getAccountInfo failed: %w", err)
+	}
+	if result.Value == nil {
+		return nil, fmt.Errorf("mint account %s not found", tokenAddress)
+	}
+
+	// Accounts the node cannot parse come back as [data, encoding] instead
+	var data struct {
+		Parsed struct {
+			Type string `json:"type"`
+			Info struct {
+				Decimals   int `json:"decimals"`
+				Extensions []struct {
+					Extension string `json:"extension"`
+					State     struct {
+						Name   string `json:"name"`
+						Symbol string `json:"symbol"`
+					} `json:"state"`
+				} `json:"extensions"`
+			} `json:"info"`
+		} `json:"parsed"`
+	}
+	if err := json.Unmarshal(result.Value.Data, &data); err != nil || data.Parsed.Type != "mint" {
+		return nil, fmt.Errorf("%s is not a token mint account", tokenAddress)
+	}
+
+	meta := &domain.TokenMetadata{Decimals: data.Parsed.Info.Decimals}
+	for _, ext := range data.Parsed.Info.Extensions {
+		if ext.Extension == "tokenMetadata" {
+			meta.Name = ext.State.Name
+			meta.Symbol = ext.State.Symbol
+		}
+	}
+	return meta, nil
 }
 
 func (s *SolanaService) GetHoldersWithTrades(ctx context.Context, tokenAddress string) (map[string][]domain.Trade, error) {
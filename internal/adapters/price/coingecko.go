@@ -0,0 +1,169 @@
+package price
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/core/domain"
+)
+
+// CoinGeckoService implements PriceService using CoinGecko's contract endpoints,
+// which support both live and historical prices.
+type CoinGeckoService struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewCoinGeckoService creates a CoinGecko price service. apiKey may be empty
+// to use the public rate-limited API.
+func NewCoinGeckoService(apiKey string) *CoinGeckoService {
+	return &CoinGeckoService{
+		baseURL: "https://api.coingecko.com/api/v3",
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// platformID maps the agent's chain names to CoinGecko asset platform IDs.
+func platformID(chain string) (string, error) {
+	switch strings.ToLower(chain) {
+	case "ethereum", "eth":
+		return "ethereum", nil
+	case "solana", "sol":
+		return "solana", nil
+	default:
+		return "", fmt.Errorf("chain %s not supported by coingecko", chain)
+	}
+}
+
+// SourceName identifies CoinGecko for result attribution
+func (s *CoinGeckoService) SourceName() string {
+	return "coingecko"
+}
+
+func (s *CoinGeckoService) GetCurrentPrice(ctx context.Context, chain, tokenAddress string) (float64, error) {
+	platform, err := platformID(chain)
+	if err != nil {
+		return 0, err
+	}
+
+	query := url.Values{}
+	query.Set("contract_addresses", tokenAddress)
+	query.Set("vs_currencies", "usd")
+
+	var result map[string]struct {
+		USD float64 `json:"usd"`
+	}
+	if err := s.get(ctx, "/simple/token_price/"+platform, query, &result); err != nil {
+		return 0, err
+	}
+
+	// Keys are returned lowercased for EVM addresses
+	for addr, price := range result {
+		if strings.EqualFold(addr, tokenAddress) {
+			return price.USD, nil
+		}
+	}
+	return 0, fmt.Errorf("coingecko returned no price for %s", tokenAddress)
+}
+
+// PriceHistory returns CoinGecko's market chart between from and to. Its
+// granularity depends on the range: 5 minutes up to a day, hourly up to 90
+// days and daily beyond.
+func (s *CoinGeckoService) PriceHistory(ctx context.Context, chain, tokenAddress string, from, to time.Time) ([]domain.PricePoint, error) {
+	platform, err := platformID(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("vs_currency", "usd")
+	query.Set("from", fmt.Sprintf("%d", from.Unix()))
+	query.Set("to", fmt.Sprintf("%d", to.Unix()))
+
+	var result struct {
+		Prices [][2]float64 `json:"prices"` // [unix ms, price]
+	}
+	path := fmt.Sprintf("/coins/%s/contract/%s/market_chart/range", platform, tokenAddress)
+	if err := s.get(ctx, path, query, &result); err != nil {
+		return nil, err
+	}
+
+	if len(result.Prices) == 0 {
+		return nil, domain.ErrHistoricalPriceUnavailable
+	}
+
+	points := make([]domain.PricePoint, len(result.Prices))
+	for i, p := range result.Prices {
+		points[i] = domain.PricePoint{Time: time.UnixMilli(int64(p[0])).UTC(), PriceUSD: p[1]}
+	}
+	return points, nil
+}
+
+func (s *CoinGeckoService) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", s.baseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	if s.apiKey != "" {
+		req.Header.Set("x-cg-demo-api-key", s.apiKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("coingecko api returned status: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode coingecko response: %w", err)
+	}
+	return nil
+}
+
+// CompositeService serves current prices from one provider and historical
+// prices from another, e.g. DexScreener for live pairs and CoinGecko for history.
+type CompositeService struct {
+	current    domain.PriceService
+	historical domain.PriceService
+}
+
+func NewCompositeService(current, historical domain.PriceService) *CompositeService {
+	return &CompositeService{current: current, historical: historical}
+}
+
+func (s *CompositeService) GetCurrentPrice(ctx context.Context, chain, tokenAddress string) (float64, error) {
+	return s.current.GetCurrentPrice(ctx, chain, tokenAddress)
+}
+
+func (s *CompositeService) PriceHistory(ctx context.Context, chain, tokenAddress string, from, to time.Time) ([]domain.PricePoint, error) {
+	return s.historical.PriceHistory(ctx, chain, tokenAddress, from, to)
+}
+
+// SourceName reports the provider serving current prices
+func (s *CompositeService) SourceName() string {
+	return sourceName(s.current)
+}
+
+// HistoricalSourceName reports the provider serving historical prices
+func (s *CompositeService) HistoricalSourceName() string {
+	return sourceName(s.historical)
+}
+
+// sourceName returns the provider name of a price service, if it has one
+func sourceName(service domain.PriceService) string {
+	if ds, ok := service.(domain.DataSource); ok {
+		return ds.SourceName()
+	}
+	return "unknown"
+}
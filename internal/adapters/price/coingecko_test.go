@@ -0,0 +1,76 @@
+package price
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/core/domain"
+)
+
+func TestCoinGeckoService_PriceHistoryRequestsRange(t *testing.T) {
+	ts := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	from, to := ts.Add(-time.Hour), ts.Add(time.Hour)
+
+	var gotFrom, gotTo int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/coins/ethereum/contract/0xtoken/market_chart/range" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		gotFrom, _ = strconv.ParseInt(r.URL.Query().Get("from"), 10, 64)
+		gotTo, _ = strconv.ParseInt(r.URL.Query().Get("to"), 10, 64)
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"prices": [][2]float64{
+				{float64(ts.Add(-30 * time.Minute).UnixMilli()), 1.0},
+				{float64(ts.Add(2 * time.Minute).UnixMilli()), 2.0},
+				{float64(ts.Add(40 * time.Minute).UnixMilli()), 3.0},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	svc := NewCoinGeckoService("")
+	svc.baseURL = srv.URL
+
+	points, err := svc.PriceHistory(context.Background(), "ethereum", "0xtoken", from, to)
+	if err != nil {
+		t.Fatalf("PriceHistory failed: %v", err)
+	}
+	if len(points) != 3 || !points[1].Time.Equal(ts.Add(2*time.Minute)) || points[1].PriceUSD != 2.0 {
+		t.Errorf("unexpected price points: %+v", points)
+	}
+	if gotFrom != from.Unix() || gotTo != to.Unix() {
+		t.Errorf("requested range [%d, %d], want [%d, %d]", gotFrom, gotTo, from.Unix(), to.Unix())
+	}
+}
+
+func TestCoinGeckoService_PriceHistoryNoData(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"prices":[]}`))
+	}))
+	defer srv.Close()
+
+	svc := NewCoinGeckoService("")
+	svc.baseURL = srv.URL
+
+	_, err := svc.PriceHistory(context.Background(), "solana", "mint", time.Now().Add(-time.Hour), time.Now())
+	if !errors.Is(err, domain.ErrHistoricalPriceUnavailable) {
+		t.Errorf("expected ErrHistoricalPriceUnavailable, got %v", err)
+	}
+}
+
+func TestCompositeService_SourceNames(t *testing.T) {
+	svc := NewCompositeService(NewDexScreenerService(), NewCoinGeckoService(""))
+	if got := svc.SourceName(); got != "dexscreener" {
+		t.Errorf("SourceName() = %q, want dexscreener", got)
+	}
+	if got := svc.HistoricalSourceName(); got != "coingecko" {
+		t.Errorf("HistoricalSourceName() = %q, want coingecko", got)
+	}
+}
@@ -0,0 +1,103 @@
+package price
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/core/domain"
+)
+
+// wrappedSOLMint is the mint Jupiter prices native SOL under
+const wrappedSOLMint = "So11111111111111111111111111111111111111112"
+
+// base58Alphabet lists the characters valid in Solana addresses
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// JupiterService implements PriceService for Solana tokens using Jupiter's
+// price API, which lists new tokens well before DexScreener does. It only
+// serves current prices.
+type JupiterService struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewJupiterService creates a Jupiter price service using the public API
+func NewJupiterService() *JupiterService {
+	return &JupiterService{
+		baseURL: "https://lite-api.jup.ag/price/v3",
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// SourceName identifies Jupiter for result attribution
+func (s *JupiterService) SourceName() string {
+	return "jupiter"
+}
+
+// jupiterMint maps a Solana token address to the mint Jupiter prices it
+// under. Native SOL may be given as "SOL".
+func jupiterMint(chain, tokenAddress string) (string, error) {
+	switch strings.ToLower(chain) {
+	case "solana", "sol":
+	default:
+		return "", fmt.Errorf("%w: jupiter only prices solana tokens, got %s", domain.ErrUnsupportedChain, chain)
+	}
+
+	if strings.EqualFold(tokenAddress, "sol") {
+		return wrappedSOLMint, nil
+	}
+	if len(tokenAddress) < 32 || len(tokenAddress) > 44 || strings.Trim(tokenAddress, base58Alphabet) != "" {
+		return "", fmt.Errorf("invalid solana mint address: %s", tokenAddress)
+	}
+	return tokenAddress, nil
+}
+
+func (s *JupiterService) GetCurrentPrice(ctx context.Context, chain, tokenAddress string) (float64, error) {
+	mint, err := jupiterMint(chain, tokenAddress)
+	if err != nil {
+		return 0, err
+	}
+
+	query := url.Values{}
+	query.Set("ids", mint)
+	req, err := http.NewRequestWithContext(ctx, "GET", s.baseURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("jupiter api returned status: %d", resp.StatusCode)
+	}
+
+	// Tokens without a price are left out of the response
+	var result map[string]*struct {
+		USDPrice float64 `json:"usdPrice"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("failed to decode jupiter response: %w", err)
+	}
+	price := result[mint]
+	if price == nil || price.USDPrice <= 0 {
+		return 0, fmt.Errorf("jupiter returned no price for %s", tokenAddress)
+	}
+	return price.USDPrice, nil
+}
+
+// PriceHistory is not supported by Jupiter, which only exposes current prices.
+func (s *JupiterService) PriceHistory(ctx context.Context, chain, tokenAddress string, from, to time.Time) ([]domain.PricePoint, error) {
+	if _, err := jupiterMint(chain, tokenAddress); err != nil {
+		return nil, err
+	}
+	return nil, domain.ErrHistoricalPriceUnavailable
+}
@@ -0,0 +1,84 @@
+package price
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/core/domain"
+)
+
+const bonkMint = "DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263"
+
+func newJupiterTestService(t *testing.T, body string) (*JupiterService, *string) {
+	t.Helper()
+	var gotIDs string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotIDs = r.URL.Query().Get("ids")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	svc := NewJupiterService()
+	svc.baseURL = srv.URL
+	return svc, &gotIDs
+}
+
+func TestJupiterService_GetCurrentPrice(t *testing.T) {
+	svc, gotIDs := newJupiterTestService(t, `{
+		"DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263": {"usdPrice": 0.0000231, "decimals": 5, "priceChange24h": 4.2},
+		"So11111111111111111111111111111111111111112": {"usdPrice": 147.5, "decimals": 9}
+	}`)
+
+	price, err := svc.GetCurrentPrice(context.Background(), "solana", bonkMint)
+	if err != nil {
+		t.Fatalf("GetCurrentPrice failed: %v", err)
+	}
+	if price != 0.0000231 || *gotIDs != bonkMint {
+		t.Errorf("got price %v for ids %q, want 0.0000231 for %s", price, *gotIDs, bonkMint)
+	}
+
+	// Native SOL is priced as wrapped SOL
+	price, err = svc.GetCurrentPrice(context.Background(), "sol", "SOL")
+	if err != nil {
+		t.Fatalf("GetCurrentPrice(SOL) failed: %v", err)
+	}
+	if price != 147.5 || *gotIDs != wrappedSOLMint {
+		t.Errorf("got price %v for ids %q, want 147.5 for wrapped SOL", price, *gotIDs)
+	}
+}
+
+func TestJupiterService_NoPrice(t *testing.T) {
+	svc, _ := newJupiterTestService(t, `{}`)
+
+	if _, err := svc.GetCurrentPrice(context.Background(), "solana", bonkMint); err == nil {
+		t.Error("expected an error for a token Jupiter does not price")
+	}
+}
+
+func TestJupiterService_ChainFiltering(t *testing.T) {
+	svc, gotIDs := newJupiterTestService(t, `{}`)
+
+	for _, chain := range []string{"ethereum", "base", ""} {
+		_, err := svc.GetCurrentPrice(context.Background(), chain, "0x6982508145454ce325ddbe47a25d4ec3d2311933")
+		if !errors.Is(err, domain.ErrUnsupportedChain) {
+			t.Errorf("GetCurrentPrice(%q) error = %v, want ErrUnsupportedChain", chain, err)
+		}
+		if _, err := svc.PriceHistory(context.Background(), chain, "0xtoken", time.Now().Add(-time.Hour), time.Now()); !errors.Is(err, domain.ErrUnsupportedChain) {
+			t.Errorf("PriceHistory(%q) error = %v, want ErrUnsupportedChain", chain, err)
+		}
+	}
+	if *gotIDs != "" {
+		t.Errorf("jupiter was queried for a non-solana chain with ids %q", *gotIDs)
+	}
+
+	if _, err := svc.GetCurrentPrice(context.Background(), "solana", "0x6982508145454ce325ddbe47a25d4ec3d2311933"); err == nil || errors.Is(err, domain.ErrUnsupportedChain) {
+		t.Errorf("expected an invalid address error for an EVM address on solana, got %v", err)
+	}
+	if _, err := svc.PriceHistory(context.Background(), "solana", bonkMint, time.Now().Add(-time.Hour), time.Now()); !errors.Is(err, domain.ErrHistoricalPriceUnavailable) {
+		t.Errorf("PriceHistory error = %v, want ErrHistoricalPriceUnavailable", err)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"net/http"
 	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/core/domain"
 )
 
 type DexScreenerService struct {
@@ -18,6 +20,11 @@ func NewDexScreenerService() *DexScreenerService {
 	}
 }
 
+// SourceName identifies DexScreener for result attribution
+func (s *DexScreenerService) SourceName() string {
+	return "dexscreener"
+}
+
 func (s *DexScreenerService) GetCurrentPrice(ctx context.Context, chain, tokenAddress string) (float64, error) {
 	// Valid DexScreener API call
 	// URL: https://api.dexscreener.com/latest/dex/tokens/{tokenAddresses}
@@ -65,3 +72,8 @@ func (s *DexScreenerService) GetCurrentPrice(ctx context.Context, chain, tokenAd
 
 	return price, nil
 }
+
+// PriceHistory is not supported by DexScreener, which only exposes live pair prices.
+func (s *DexScreenerService) PriceHistory(ctx context.Context, chain, tokenAddress string, from, to time.Time) ([]domain.PricePoint, error) {
+	return nil, domain.ErrHistoricalPriceUnavailable
+}
@@ -1,13 +1,18 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"errors"
+	"time"
+)
 
 // ChainService defines the operations required to interact with a blockchain.
 type ChainService interface {
 	// IsSupported checks if the chain is supported by this service.
 	IsSupported(chain string) bool
 	
-	// GetTokenMetadata fetches the decimals and symbol for a token.
+	// GetTokenMetadata fetches the decimals, symbol and name for a token.
+	// Symbol and name may be empty when the token does not publish them.
 	GetTokenMetadata(ctx context.Context, tokenAddress string) (*TokenMetadata, error)
 	
 	// GetTrades fetches all relevant trades for a token.
@@ -24,10 +29,48 @@ type ChainService interface {
 	GetHoldersWithTrades(ctx context.Context, tokenAddress string) (map[string][]Trade, error)
 }
 
+// WindowedChainService is implemented by chain services that can narrow
+// their fetch to a trade window, saving requests for heavily traded tokens.
+// AgentService still filters whatever is returned to the window.
+type WindowedChainService interface {
+	GetHoldersWithTradesInWindow(ctx context.Context, tokenAddress string, window TradeWindow) (map[string][]Trade, error)
+}
+
 // PriceService defines how to get token price data.
 type PriceService interface {
 	// GetCurrentPrice returns the current USD price of the token.
 	GetCurrentPrice(ctx context.Context, chain, tokenAddress string) (float64, error)
+
+	// PriceHistory returns the USD prices of the token between from and to,
+	// oldest first, with one request to the provider.
+	// Providers without historical data return ErrHistoricalPriceUnavailable.
+	PriceHistory(ctx context.Context, chain, tokenAddress string, from, to time.Time) ([]PricePoint, error)
+}
+
+// ErrHistoricalPriceUnavailable is returned by PriceHistory when no historical price can be found.
+var ErrHistoricalPriceUnavailable = errors.New("historical price unavailable")
+
+// ErrUnsupportedChain is returned by price services asked about a chain they
+// do not cover, so a caller trying several services can skip to the next.
+var ErrUnsupportedChain = errors.New("chain not supported")
+
+// DataSource is implemented by chain and price services that can name their
+// upstream provider for result attribution.
+type DataSource interface {
+	SourceName() string
+}
+
+// HistoricalDataSource is implemented by price services whose historical
+// prices come from a different provider than their current prices.
+type HistoricalDataSource interface {
+	HistoricalSourceName() string
+}
+
+// CappedSource is implemented by chain services that fetch at most a fixed
+// number of transfers per token, each reported as one buy trade. Reaching the
+// cap marks the source as capped.
+type CappedSource interface {
+	TransactionCap() int
 }
 
 // PnLCalculator defines the logic to compute PnL.
@@ -1,12 +1,115 @@
 package domain
 
-import "time"
+import (
+	"math/big"
+	"strings"
+	"time"
+)
 
 // AgentInput represents the input parameters for the agent.
 type AgentInput struct {
 	Chain        string `json:"chain"`        // "ethereum" or "solana"
 	TokenAddress string `json:"tokenAddress"` // Contract address of the token
 	Limit        int    `json:"limit"`        // Number of top wallets to return
+
+	// TokenAddresses analyzes several tokens in one request; TokenAddress,
+	// when also set, is analyzed first
+	TokenAddresses []string `json:"tokenAddresses,omitempty"`
+
+	// Chains analyzes the token's swaps on several chains at once, merged
+	// into one ranking; Chain, when also set, is fetched first
+	Chains []string `json:"chains,omitempty"`
+
+	// Optional analysis scope; zero values leave that bound open
+	FromTime time.Time `json:"fromTime,omitzero"` // Ignore swaps before this time
+	ToTime   time.Time `json:"toTime,omitzero"`   // Ignore swaps after this time
+	MaxSwaps int       `json:"maxSwaps,omitzero"` // Analyze only the most recent N swaps
+
+	// Optional ranking thresholds; wallets below either are left out
+	MinTrades int     `json:"minTrades,omitzero"` // Minimum buys plus sells
+	MinVolume float64 `json:"minVolume,omitzero"` // Minimum traded volume in USD
+
+	Format string `json:"format,omitempty"` // Output format: json (default), text, markdown or csv
+}
+
+// Output formats accepted in AgentInput.Format.
+const (
+	FormatJSON     = "json"
+	FormatText     = "text"
+	FormatMarkdown = "markdown"
+	FormatCSV      = "csv"
+)
+
+// IsBatch reports whether the input asks for a combined multi-token result.
+func (in AgentInput) IsBatch() bool {
+	return len(in.TokenAddresses) > 0
+}
+
+// Tokens returns the token addresses to analyze in order, trimmed and
+// without blanks or duplicates.
+func (in AgentInput) Tokens() []string {
+	var tokens []string
+	seen := make(map[string]bool)
+	for _, addr := range append([]string{in.TokenAddress}, in.TokenAddresses...) {
+		addr = strings.TrimSpace(addr)
+		if addr == "" || seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		tokens = append(tokens, addr)
+	}
+	return tokens
+}
+
+// AllChains returns the chains to fetch swaps from in order, Chain first,
+// lowercased and without blanks or duplicates.
+func (in AgentInput) AllChains() []string {
+	var chains []string
+	seen := make(map[string]bool)
+	for _, chain := range append([]string{in.Chain}, in.Chains...) {
+		chain = strings.ToLower(strings.TrimSpace(chain))
+		if chain == "" || seen[chain] {
+			continue
+		}
+		seen[chain] = true
+		chains = append(chains, chain)
+	}
+	return chains
+}
+
+// Window returns the trade window requested by the input.
+func (in AgentInput) Window() TradeWindow {
+	return TradeWindow{From: in.FromTime, To: in.ToTime, MaxSwaps: in.MaxSwaps}
+}
+
+// TradeWindow bounds which swaps are included in an analysis.
+type TradeWindow struct {
+	From     time.Time // Inclusive lower bound (zero = unbounded)
+	To       time.Time // Inclusive upper bound (zero = unbounded)
+	MaxSwaps int       // Keep only the most recent N swaps (0 = all)
+}
+
+// IsZero reports whether the window places no bounds on the analysis.
+func (w TradeWindow) IsZero() bool {
+	return w.From.IsZero() && w.To.IsZero() && w.MaxSwaps == 0
+}
+
+// Contains reports whether ts falls within the window's time bounds.
+// Trades without a timestamp are excluded once either bound is set.
+func (w TradeWindow) Contains(ts time.Time) bool {
+	if w.From.IsZero() && w.To.IsZero() {
+		return true
+	}
+	if ts.IsZero() {
+		return false
+	}
+	if !w.From.IsZero() && ts.Before(w.From) {
+		return false
+	}
+	if !w.To.IsZero() && ts.After(w.To) {
+		return false
+	}
+	return true
 }
 
 // AgentOutput represents the structured output of the agent.
@@ -14,8 +117,75 @@ type AgentOutput struct {
 	TokenSymbol  string      `json:"token_symbol"`
 	CurrentPrice float64     `json:"current_price_usd"`
 	TopWallets   []WalletPnL `json:"top_wallets"`
+
+	// Token describes the analyzed token; nil when its metadata could not be read
+	Token *TokenMetadata `json:"token,omitempty"`
+
+	// DataSources records which providers contributed to the result
+	DataSources []SourceInfo `json:"data_sources,omitempty"`
+
+	// Message explains an empty TopWallets, e.g. when no wallet met the thresholds
+	Message string `json:"message,omitempty"`
+
+	// Cached is set when the result was served from the result cache
+	Cached bool `json:"cached,omitempty"`
+}
+
+// BatchOutput is the combined result of analyzing several tokens.
+type BatchOutput struct {
+	Results []TokenResult `json:"results"`
+}
+
+// TokenResult labels the analysis of one token in a batch. Exactly one of
+// Result and Error is set.
+type TokenResult struct {
+	TokenAddress string       `json:"token_address"`
+	Result       *AgentOutput `json:"result,omitempty"`
+	Error        string       `json:"error,omitempty"`
+}
+
+// Data source roles reported in SourceInfo.
+const (
+	SourceRoleTrades          = "trades"
+	SourceRolePrice           = "price"
+	SourceRoleHistoricalPrice = "historical_price"
+)
+
+// Data source statuses reported in SourceInfo.
+const (
+	SourceStatusOK     = "ok"
+	SourceStatusCapped = "capped" // Provider hit its fetch limit; results may be incomplete
+	SourceStatusFailed = "failed" // Provider failed and a fallback was used
+)
+
+// SourceInfo attributes part of an analysis to a data provider.
+type SourceInfo struct {
+	Name         string `json:"name"`         // e.g. "alchemy", "helius", "dexscreener", "coingecko"
+	Role         string `json:"role"`         // What the provider was used for
+	Transactions int    `json:"transactions"` // Transactions (or price lookups) served by the provider
+	Status       string `json:"status"`
+	Error        string `json:"error,omitempty"`
+	Chain        string `json:"chain,omitempty"` // Set when swaps were fetched from several chains
+}
+
+// Analysis phases reported through a ProgressFunc, in the order they occur.
+const (
+	PhaseFetched      = "fetched"       // Trades were fetched from the chain
+	PhaseNormalized   = "normalized"    // Trades were windowed, scaled and priced
+	PhaseComputingPnL = "computing_pnl" // PnL is being calculated per wallet
+	PhaseRanking      = "ranking"       // Wallets are being ranked
+)
+
+// Progress reports that an analysis reached a phase.
+type Progress struct {
+	Phase   string
+	Count   int    // Swaps for the fetch and normalize phases, wallets after
+	Message string // Human-readable update, e.g. "fetched 120 swaps"
 }
 
+// ProgressFunc receives progress updates while a token is analyzed.
+type ProgressFunc func(Progress)
+
 // WalletPnL contains the Profit and Loss data for a specific wallet.
 type WalletPnL struct {
 	Address         string  `json:"wallet_address"`
@@ -29,20 +199,59 @@ type WalletPnL struct {
 	UnrealizedPnL float64 `json:"unrealized_pnl_usd"`
 	TotalPnL      float64 `json:"total_pnl_usd"`
 	ROI           float64 `json:"roi_percentage"`
+
+	TradeCount int     `json:"trade_count"` // Buys plus sells
+	VolumeUSD  float64 `json:"volume_usd"`  // Value of all buys and sells
+}
+
+// PricePoint is the USD price of a token at a point in time.
+type PricePoint struct {
+	Time     time.Time `json:"time"`
+	PriceUSD float64   `json:"price_usd"`
 }
 
 // Trade represents a single buy or sell event.
 type Trade struct {
-	Type      string    `json:"type"` // "buy" or "sell"
-	Amount    float64   `json:"amount"`
+	Type      string    `json:"type"`   // "buy" or "sell"
+	Amount    float64   `json:"amount"` // Token units, decimal-adjusted
 	PriceUSD  float64   `json:"price_usd"`
 	Timestamp time.Time `json:"timestamp"`
 	TxHash    string    `json:"tx_hash"`
+
+	// RawAmount is the on-chain amount in base units, when the provider
+	// reports it; Decimals is the token precision used to scale it.
+	RawAmount *big.Int `json:"raw_amount,omitempty"`
+	Decimals  int      `json:"decimals,omitempty"`
+}
+
+// TokenAmount returns the trade size in token units. Raw amounts are scaled
+// exactly; otherwise the provider's decimal-adjusted Amount is used.
+func (t Trade) TokenAmount() *big.Float {
+	if t.RawAmount != nil {
+		return ScaleAmount(t.RawAmount, t.Decimals)
+	}
+	return new(big.Float).SetPrec(AmountPrecision).SetFloat64(t.Amount)
+}
+
+// AmountPrecision is the big.Float mantissa size used for token amounts,
+// enough to hold any uint256 base-unit amount exactly.
+const AmountPrecision = 512
+
+// ScaleAmount converts a raw amount in base units into token units by
+// dividing by 10^decimals.
+func ScaleAmount(raw *big.Int, decimals int) *big.Float {
+	amount := new(big.Float).SetPrec(AmountPrecision).SetInt(raw)
+	if decimals <= 0 {
+		return amount
+	}
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	return amount.Quo(amount, new(big.Float).SetPrec(AmountPrecision).SetInt(unit))
 }
 
 // TokenMetadata holds basic information about a token.
 type TokenMetadata struct {
-	Symbol   string
-	Decimals int
-	Name     string
+	Address  string `json:"address"`
+	Symbol   string `json:"symbol,omitempty"`
+	Decimals int    `json:"decimals"`
+	Name     string `json:"name,omitempty"`
 }
@@ -4,14 +4,24 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/core/domain"
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/cache"
 )
 
 type AgentService struct {
 	chainServices []domain.ChainService
 	priceService  domain.PriceService
 	pnlCalculator domain.PnLCalculator
+	blocklist     *Blocklist
+
+	resultCache    cache.AgentCache
+	resultCacheTTL time.Duration
+
+	attributeSources bool
+	chainConcurrency int
 }
 
 func NewAgentService(
@@ -23,46 +33,116 @@ func NewAgentService(
 		chainServices: chains,
 		priceService:  price,
 		pnlCalculator: calc,
+		blocklist:     DefaultBlocklist(),
+
+		attributeSources: true,
 	}
 }
 
-func (s *AgentService) AnalyzeToken(ctx context.Context, input domain.AgentInput) (*domain.AgentOutput, error) {
-	// 1. Find correct chain service
-	var chainService domain.ChainService
-	for _, cs := range s.chainServices {
-		if cs.IsSupported(input.Chain) {
-			chainService = cs
-			break
+// SetBlocklist replaces the addresses excluded from rankings, which default
+// to DefaultBlocklist. Pass an extended DefaultBlocklist to add addresses, or
+// nil to rank every wallet.
+func (s *AgentService) SetBlocklist(b *Blocklist) {
+	s.blocklist = b
+}
+
+// SetResultCache makes AnalyzeToken reuse results for the same chain, token,
+// limit, window and thresholds for ttl, or DefaultResultCacheTTL when ttl is
+// not positive. Pass nil to always compute results live.
+func (s *AgentService) SetResultCache(c cache.AgentCache, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = DefaultResultCacheTTL
+	}
+	s.resultCache = c
+	s.resultCacheTTL = ttl
+}
+
+// SetDataSourceAttribution controls whether results list the data sources
+// used to produce them. Attribution is enabled by default.
+func (s *AgentService) SetDataSourceAttribution(enabled bool) {
+	s.attributeSources = enabled
+}
+
+// AnalyzeToken ranks the wallets trading a token by PnL. When progress is
+// non-nil it is called synchronously as the analysis enters each phase; a
+// result served from the result cache reports no phases.
+func (s *AgentService) AnalyzeToken(ctx context.Context, input domain.AgentInput, progress domain.ProgressFunc) (*domain.AgentOutput, error) {
+	report := func(phase string, count int, format string) {
+		if progress != nil {
+			progress(domain.Progress{Phase: phase, Count: count, Message: fmt.Sprintf(format, count)})
 		}
 	}
-	if chainService == nil {
-		return nil, fmt.Errorf("chain %s not supported", input.Chain)
+
+
+	// 1. Find correct chain service and check the rest of the input
+	chainService, err := s.validateInput(input)
+	if err != nil {
+		return nil, err
+	}
+	window := input.Window()
+
+	if cached, ok := s.cachedResult(ctx, input); ok {
+		return cached, nil
 	}
 
-	// 2. Fetch Token Metadata
+	// 2. Fetch Token Metadata; the analysis does not depend on it, so a token
+	// without readable metadata is reported without a symbol
 	meta, err := chainService.GetTokenMetadata(ctx, input.TokenAddress)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get token metadata: %w", err)
+	if err == nil && meta != nil {
+		meta.Address = input.TokenAddress
+	} else {
+		meta = nil
 	}
 
 	// 3. Fetch Price
-	price, err := s.priceService.GetCurrentPrice(ctx, input.Chain, input.TokenAddress)
+	prices := &countedPrices{PriceService: s.priceService}
+	price, err := prices.GetCurrentPrice(ctx, input.Chain, input.TokenAddress)
 	if err != nil {
 		// Proceed with 0 price or error? Agent usually needs price.
 		return nil, fmt.Errorf("failed to get price: %w", err)
 	}
 
-	// 4. Fetch Trades/Holders
-	holdersMap, err := chainService.GetHoldersWithTrades(ctx, input.TokenAddress)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get trades: %w", err)
+	// 4. Fetch Trades/Holders, scoped to the requested window; swaps on
+	// several chains are fetched concurrently and already scaled
+	var holdersMap map[string][]domain.Trade
+	var tradeSources []domain.SourceInfo
+	if chains := input.AllChains(); len(chains) > 1 {
+		swaps, err := s.FetchSwapsAcrossChains(ctx, input.TokenAddress, chains, window)
+		if err != nil {
+			return nil, err
+		}
+		holdersMap, tradeSources = swaps.Holders, swaps.Sources
+		report(domain.PhaseFetched, countTrades(holdersMap), "fetched %d swaps")
+		holdersMap = applyWindow(holdersMap, window)
+	} else {
+		if windowed, ok := chainService.(domain.WindowedChainService); ok && !window.IsZero() {
+			holdersMap, err = windowed.GetHoldersWithTradesInWindow(ctx, input.TokenAddress, window)
+		} else {
+			holdersMap, err = chainService.GetHoldersWithTrades(ctx, input.TokenAddress)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get trades: %w", err)
+		}
+		report(domain.PhaseFetched, countTrades(holdersMap), "fetched %d swaps")
+		// Attribute the trades as fetched, the window may hide a capped fetch
+		tradeSources = []domain.SourceInfo{tradeSource(chainService, holdersMap)}
+		holdersMap = applyWindow(holdersMap, window)
+		scaleTrades(holdersMap, meta)
 	}
 
-	// 5. Calculate PnL for each wallet
+	// 5. Value trades that carry no price at the swap time
+	historicalSource := priceTrades(ctx, prices, input, holdersMap, price)
+	report(domain.PhaseNormalized, countTrades(holdersMap), "normalized %d swaps")
+
+	// 6. Calculate PnL for each wallet
+	report(domain.PhaseComputingPnL, len(holdersMap), "computing PnL for %d wallets")
 	var results []domain.WalletPnL
+	belowThreshold := 0
 	for addr, trades := range holdersMap {
-		// Optional: Filter out logic here (contracts, deployer) if not done in ChainService
-		// For now we assume ChainService returns relevant user wallets or we filter here if we had metadata.
+		// Routers, aggregators and exchange wallets are counterparties, not traders
+		if s.blocklist.Contains(input.Chain, addr) {
+			continue
+		}
 		
 		stats := s.pnlCalculator.Calculate(trades, price)
 		stats.Address = addr
@@ -71,25 +151,390 @@ func (s *AgentService) AnalyzeToken(ctx context.Context, input domain.AgentInput
 		if stats.TotalBought == 0 && stats.TotalSold == 0 {
 			continue
 		}
+
+		// Leave out one-off lucky trades and dust positions
+		if stats.TradeCount < input.MinTrades || stats.VolumeUSD < input.MinVolume {
+			belowThreshold++
+			continue
+		}
 		
 		results = append(results, *stats)
 	}
 
-	// 6. Rank by Total PnL (descending)
+	// 7. Rank by Total PnL (descending)
+	report(domain.PhaseRanking, len(results), "ranking %d wallets")
 	sort.Slice(results, func(i, j int) bool {
 		return results[i].TotalPnL > results[j].TotalPnL
 	})
 
-	// 7. Limit output
+	// 8. Limit output
 	limit := input.Limit
 	if limit > len(results) {
 		limit = len(results)
 	}
 	topWallets := results[:limit]
 
-	return &domain.AgentOutput{
-		TokenSymbol:  meta.Symbol,
+	output := &domain.AgentOutput{
 		CurrentPrice: price,
 		TopWallets:   topWallets,
-	}, nil
+		Token:        meta,
+	}
+	if meta != nil {
+		output.TokenSymbol = meta.Symbol
+	}
+	if len(results) == 0 && belowThreshold > 0 {
+		output.Message = noWalletsMessage(belowThreshold, input)
+	}
+
+	// 9. Attribute the result to the providers that produced it
+	if s.attributeSources {
+		output.DataSources = append(tradeSources, domain.SourceInfo{
+			Name:         sourceName(s.priceService),
+			Role:         domain.SourceRolePrice,
+			Transactions: prices.current,
+			Status:       domain.SourceStatusOK,
+		})
+		if historicalSource != nil {
+			output.DataSources = append(output.DataSources, *historicalSource)
+		}
+	}
+
+	s.storeResult(ctx, input, output)
+	return output, nil
+}
+
+// AnalyzeTokens analyzes every token in input.Tokens() with the rest of the
+// input and labels each result. A token that fails, for example because its
+// address is malformed, gets an error entry without failing the batch; only
+// problems with the shared input fail the whole call.
+func (s *AgentService) AnalyzeTokens(ctx context.Context, input domain.AgentInput, progress domain.ProgressFunc) (*domain.BatchOutput, error) {
+	tokens := input.Tokens()
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("no token addresses given")
+	}
+	if _, err := s.validateInput(input); err != nil {
+		return nil, err
+	}
+
+	batch := &domain.BatchOutput{Results: make([]domain.TokenResult, 0, len(tokens))}
+	for _, token := range tokens {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		result := domain.TokenResult{TokenAddress: token}
+		single := input
+		single.TokenAddress, single.TokenAddresses = token, nil
+
+		var tokenProgress domain.ProgressFunc
+		if progress != nil {
+			tokenProgress = func(p domain.Progress) {
+				p.Message = token + ": " + p.Message
+				progress(p)
+			}
+		}
+
+		if err := validateTokenAddress(input.Chain, token); err != nil {
+			result.Error = err.Error()
+		} else if out, err := s.AnalyzeToken(ctx, single, tokenProgress); err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Result = out
+		}
+		batch.Results = append(batch.Results, result)
+	}
+	return batch, nil
+}
+
+// validateInput checks the parts of an input shared by every token and
+// returns the chain service that analyzes it
+func (s *AgentService) validateInput(input domain.AgentInput) (domain.ChainService, error) {
+	var chainService domain.ChainService
+	for _, cs := range s.chainServices {
+		if cs.IsSupported(input.Chain) {
+			chainService = cs
+			break
+		}
+	}
+	if chainService == nil {
+		return nil, fmt.Errorf("chain %s not supported", input.Chain)
+	}
+
+	if err := validateWindow(input.Window()); err != nil {
+		return nil, err
+	}
+	if input.MinTrades < 0 || input.MinVolume < 0 {
+		return nil, fmt.Errorf("minTrades and minVolume must not be negative")
+	}
+	return chainService, nil
+}
+
+// countTrades returns the number of trades across all wallets
+func countTrades(holdersMap map[string][]domain.Trade) int {
+	n := 0
+	for _, trades := range holdersMap {
+		n += len(trades)
+	}
+	return n
+}
+
+// scaleTrades converts raw on-chain amounts into token units, preferring the
+// decimals read from the token contract over those reported with the trade.
+func scaleTrades(holdersMap map[string][]domain.Trade, meta *domain.TokenMetadata) {
+	for _, trades := range holdersMap {
+		for i := range trades {
+			t := &trades[i]
+			if t.RawAmount == nil {
+				continue
+			}
+			if meta != nil {
+				t.Decimals = meta.Decimals
+			}
+			t.Amount, _ = t.TokenAmount().Float64()
+		}
+	}
+}
+
+// noWalletsMessage explains that the ranking thresholds excluded every wallet
+func noWalletsMessage(excluded int, input domain.AgentInput) string {
+	var criteria []string
+	if input.MinTrades > 0 {
+		criteria = append(criteria, fmt.Sprintf("at least %d trades", input.MinTrades))
+	}
+	if input.MinVolume > 0 {
+		criteria = append(criteria, fmt.Sprintf("at least $%.2f volume", input.MinVolume))
+	}
+	return fmt.Sprintf("no wallets met criteria (%s); %d wallets were below the thresholds",
+		strings.Join(criteria, " and "), excluded)
+}
+
+// validateWindow rejects windows that cannot contain any swap
+func validateWindow(window domain.TradeWindow) error {
+	if window.MaxSwaps < 0 {
+		return fmt.Errorf("maxSwaps must not be negative, got %d", window.MaxSwaps)
+	}
+	if !window.From.IsZero() && !window.To.IsZero() && window.To.Before(window.From) {
+		return fmt.Errorf("toTime %s is before fromTime %s", window.To.Format(time.RFC3339), window.From.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// applyWindow drops trades outside the window's time bounds and, when
+// MaxSwaps is set, keeps only the trades of the most recent MaxSwaps swaps.
+// Wallets left without trades are removed so they cannot be ranked.
+func applyWindow(holdersMap map[string][]domain.Trade, window domain.TradeWindow) map[string][]domain.Trade {
+	if window.IsZero() {
+		return holdersMap
+	}
+
+	// Both legs of a transfer share a hash, so count swaps not trades
+	swapKey := func(addr string, i int, t domain.Trade) string {
+		if t.TxHash != "" {
+			return t.TxHash
+		}
+		return fmt.Sprintf("%s#%d", addr, i)
+	}
+
+	swapTimes := make(map[string]time.Time)
+	for addr, trades := range holdersMap {
+		for i, t := range trades {
+			if window.Contains(t.Timestamp) {
+				swapTimes[swapKey(addr, i, t)] = t.Timestamp
+			}
+		}
+	}
+
+	if window.MaxSwaps > 0 && len(swapTimes) > window.MaxSwaps {
+		keys := make([]string, 0, len(swapTimes))
+		for key := range swapTimes {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if !swapTimes[keys[i]].Equal(swapTimes[keys[j]]) {
+				return swapTimes[keys[i]].After(swapTimes[keys[j]])
+			}
+			return keys[i] < keys[j]
+		})
+		for _, key := range keys[window.MaxSwaps:] {
+			delete(swapTimes, key)
+		}
+	}
+
+	filtered := make(map[string][]domain.Trade, len(holdersMap))
+	for addr, trades := range holdersMap {
+		var kept []domain.Trade
+		for i, t := range trades {
+			if _, ok := swapTimes[swapKey(addr, i, t)]; ok {
+				kept = append(kept, t)
+			}
+		}
+		if len(kept) > 0 {
+			filtered[addr] = kept
+		}
+	}
+	return filtered
+}
+
+// sourceName returns the provider name of a chain or price service
+func sourceName(service interface{}) string {
+	if ds, ok := service.(domain.DataSource); ok {
+		return ds.SourceName()
+	}
+	return "unknown"
+}
+
+// tradeSource attributes the fetched trades to the chain service, flagging it
+// as capped when it returned as many transfers as it is allowed to fetch.
+func tradeSource(chainService domain.ChainService, holdersMap map[string][]domain.Trade) domain.SourceInfo {
+	// Both legs of a transfer share a hash, so count transactions not trades.
+	// A transaction can hold several transfers, and the cap counts those.
+	txs := make(map[string]struct{})
+	unhashed, transfers := 0, 0
+	for _, trades := range holdersMap {
+		for _, t := range trades {
+			if t.Type == "buy" {
+				transfers++
+			}
+			if t.TxHash == "" {
+				unhashed++
+				continue
+			}
+			txs[t.TxHash] = struct{}{}
+		}
+	}
+
+	info := domain.SourceInfo{
+		Name:         sourceName(chainService),
+		Role:         domain.SourceRoleTrades,
+		Transactions: len(txs) + unhashed,
+		Status:       domain.SourceStatusOK,
+	}
+
+	if capped, ok := chainService.(domain.CappedSource); ok {
+		if limit := capped.TransactionCap(); limit > 0 && transfers >= limit {
+			info.Status = domain.SourceStatusCapped
+			info.Error = fmt.Sprintf("fetch limit of %d transfers reached, older trades may be missing", limit)
+		}
+	}
+
+	return info
+}
+
+// historicalPricePadding widens the price history request so the first and
+// last trades have points on both sides. A trade without a point within
+// maxHistoricalPriceGap, e.g. before the provider's history starts, is
+// priced at the current price; long ranges come back daily.
+const (
+	historicalPricePadding = time.Hour
+	maxHistoricalPriceGap  = 24 * time.Hour
+)
+
+// priceTrades fills in PriceUSD for trades the chain service could not price,
+// using the historical price nearest the swap timestamp and falling back to
+// the current price when no historical data is available. The history for
+// all trades is fetched with one request.
+// It returns attribution for the historical lookup, or nil if none was made.
+func priceTrades(ctx context.Context, prices *countedPrices, input domain.AgentInput, holdersMap map[string][]domain.Trade, currentPrice float64) *domain.SourceInfo {
+	var unpriced []*domain.Trade
+	var from, to time.Time
+	for _, trades := range holdersMap {
+		for i := range trades {
+			t := &trades[i]
+			if t.PriceUSD != 0 {
+				continue
+			}
+			if t.Timestamp.IsZero() {
+				t.PriceUSD = currentPrice
+				continue
+			}
+
+			if len(unpriced) == 0 || t.Timestamp.Before(from) {
+				from = t.Timestamp
+			}
+			if len(unpriced) == 0 || t.Timestamp.After(to) {
+				to = t.Timestamp
+			}
+			unpriced = append(unpriced, t)
+		}
+	}
+
+	if len(unpriced) == 0 {
+		return nil
+	}
+
+	points, err := prices.PriceHistory(ctx, input.Chain, input.TokenAddress, from.Add(-historicalPricePadding), to.Add(historicalPricePadding))
+	failed := 0
+	for _, t := range unpriced {
+		if p, ok := nearestPrice(points, t.Timestamp); err == nil && ok {
+			t.PriceUSD = p
+		} else {
+			t.PriceUSD = currentPrice
+			failed++
+		}
+	}
+
+	name := sourceName(prices.PriceService)
+	if hs, ok := prices.PriceService.(domain.HistoricalDataSource); ok {
+		name = hs.HistoricalSourceName()
+	}
+
+	info := &domain.SourceInfo{
+		Name:         name,
+		Role:         domain.SourceRoleHistoricalPrice,
+		Transactions: prices.history,
+		Status:       domain.SourceStatusOK,
+	}
+	switch {
+	case err != nil:
+		info.Status = domain.SourceStatusFailed
+		info.Error = fmt.Sprintf("lookup failed, used current price for %d trades: %v", failed, err)
+	case failed > 0:
+		info.Status = domain.SourceStatusFailed
+		info.Error = fmt.Sprintf("%d of %d trades had no price within %v, used current price", failed, len(unpriced), maxHistoricalPriceGap)
+	}
+	return info
+}
+
+// countedPrices counts the lookups one analysis makes through a price
+// service, for attribution. It is not safe for concurrent use.
+type countedPrices struct {
+	domain.PriceService
+	current, history int
+}
+
+func (p *countedPrices) GetCurrentPrice(ctx context.Context, chain, tokenAddress string) (float64, error) {
+	p.current++
+	return p.PriceService.GetCurrentPrice(ctx, chain, tokenAddress)
+}
+
+func (p *countedPrices) PriceHistory(ctx context.Context, chain, tokenAddress string, from, to time.Time) ([]domain.PricePoint, error) {
+	p.history++
+	return p.PriceService.PriceHistory(ctx, chain, tokenAddress, from, to)
+}
+
+// nearestPrice returns the price of the point closest to ts, if one lies
+// within maxHistoricalPriceGap. points must be sorted oldest first.
+func nearestPrice(points []domain.PricePoint, ts time.Time) (float64, bool) {
+	i := sort.Search(len(points), func(i int) bool { return !points[i].Time.Before(ts) })
+
+	best := -1
+	for _, j := range []int{i - 1, i} {
+		if j < 0 || j >= len(points) || points[j].PriceUSD <= 0 {
+			continue
+		}
+		if best < 0 || absDuration(points[j].Time.Sub(ts)) < absDuration(points[best].Time.Sub(ts)) {
+			best = j
+		}
+	}
+	if best < 0 || absDuration(points[best].Time.Sub(ts)) > maxHistoricalPriceGap {
+		return 0, false
+	}
+	return points[best].PriceUSD, true
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
 }
@@ -0,0 +1,779 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/core/domain"
+)
+
+type fakeChain struct {
+	trades  map[string][]domain.Trade
+	meta    *domain.TokenMetadata
+	metaErr error
+}
+
+func (f *fakeChain) IsSupported(chain string) bool { return chain == "ethereum" }
+
+func (f *fakeChain) GetTokenMetadata(ctx context.Context, tokenAddress string) (*domain.TokenMetadata, error) {
+	if f.metaErr != nil {
+		return nil, f.metaErr
+	}
+	if f.meta != nil {
+		meta := *f.meta
+		return &meta, nil
+	}
+	return &domain.TokenMetadata{Symbol: "TEST", Name: "Test Token", Decimals: 18}, nil
+}
+
+func (f *fakeChain) GetTrades(ctx context.Context, tokenAddress string) ([]domain.Trade, error) {
+	return nil, nil
+}
+
+func (f *fakeChain) GetHoldersWithTrades(ctx context.Context, tokenAddress string) (map[string][]domain.Trade, error) {
+	return f.trades, nil
+}
+
+type fakePrice struct {
+	current    float64
+	historical map[time.Time]float64
+
+	mu        sync.Mutex
+	requested []timeRange
+}
+
+// timeRange is a requested price history range
+type timeRange struct {
+	from, to time.Time
+}
+
+func (r timeRange) contains(ts time.Time) bool {
+	return !ts.Before(r.from) && !ts.After(r.to)
+}
+
+func (f *fakePrice) GetCurrentPrice(ctx context.Context, chain, tokenAddress string) (float64, error) {
+	return f.current, nil
+}
+
+func (f *fakePrice) PriceHistory(ctx context.Context, chain, tokenAddress string, from, to time.Time) ([]domain.PricePoint, error) {
+	f.mu.Lock()
+	f.requested = append(f.requested, timeRange{from, to})
+	f.mu.Unlock()
+
+	var points []domain.PricePoint
+	for ts, p := range f.historical {
+		if (timeRange{from, to}).contains(ts) {
+			points = append(points, domain.PricePoint{Time: ts, PriceUSD: p})
+		}
+	}
+	if len(points) == 0 {
+		return nil, domain.ErrHistoricalPriceUnavailable
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Time.Before(points[j].Time) })
+	return points, nil
+}
+
+func TestAnalyzeToken_UsesHistoricalPriceAtSwapTime(t *testing.T) {
+	buyTime := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	sellTime := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	chain := &fakeChain{trades: map[string][]domain.Trade{
+		"0xwallet": {
+			{Type: "buy", Amount: 100, Timestamp: buyTime},
+			{Type: "sell", Amount: 50, Timestamp: sellTime},
+		},
+	}}
+	prices := &fakePrice{
+		current: 10,
+		historical: map[time.Time]float64{
+			buyTime:  1,
+			sellTime: 4,
+		},
+	}
+
+	svc := NewAgentService([]domain.ChainService{chain}, prices, NewPnLCalculator())
+	out, err := svc.AnalyzeToken(context.Background(), domain.AgentInput{
+		Chain:        "ethereum",
+		TokenAddress: "0xtoken",
+		Limit:        10,
+	}, nil)
+	if err != nil {
+		t.Fatalf("AnalyzeToken failed: %v", err)
+	}
+
+	if len(prices.requested) != 1 || !prices.requested[0].contains(buyTime) || !prices.requested[0].contains(sellTime) {
+		t.Errorf("expected one historical request covering %v and %v, got %v", buyTime, sellTime, prices.requested)
+	}
+
+	if len(out.TopWallets) != 1 {
+		t.Fatalf("expected 1 wallet, got %d", len(out.TopWallets))
+	}
+	w := out.TopWallets[0]
+	if w.AverageBuyPrice != 1 {
+		t.Errorf("expected avg buy price 1 (historical), got %v", w.AverageBuyPrice)
+	}
+	// Sold 50 @ 4 with cost basis 1
+	if w.RealizedPnL != 150 {
+		t.Errorf("expected realized PnL 150, got %v", w.RealizedPnL)
+	}
+}
+
+func TestAnalyzeToken_FallsBackToCurrentPrice(t *testing.T) {
+	buyTime := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	chain := &fakeChain{trades: map[string][]domain.Trade{
+		"0xwallet": {{Type: "buy", Amount: 10, Timestamp: buyTime}},
+	}}
+	prices := &fakePrice{current: 3}
+
+	svc := NewAgentService([]domain.ChainService{chain}, prices, NewPnLCalculator())
+	out, err := svc.AnalyzeToken(context.Background(), domain.AgentInput{
+		Chain:        "ethereum",
+		TokenAddress: "0xtoken",
+		Limit:        10,
+	}, nil)
+	if err != nil {
+		t.Fatalf("AnalyzeToken failed: %v", err)
+	}
+
+	if len(prices.requested) != 1 || !prices.requested[0].contains(buyTime) {
+		t.Errorf("expected one historical lookup covering %v, got %v", buyTime, prices.requested)
+	}
+	if got := out.TopWallets[0].AverageBuyPrice; got != 3 {
+		t.Errorf("expected fallback to current price 3, got %v", got)
+	}
+}
+
+func TestAnalyzeToken_PricesTradesWithOneHistoryRequest(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	// Hourly price points and a trade every 7 minutes for two days
+	historical := map[time.Time]float64{}
+	for h := 0; h <= 48; h++ {
+		historical[start.Add(time.Duration(h)*time.Hour)] = float64(h + 1)
+	}
+	var trades []domain.Trade
+	for m := 0; m < 48*60; m += 7 {
+		trades = append(trades, domain.Trade{Type: "buy", Amount: 1, Timestamp: start.Add(time.Duration(m)*time.Minute + 20*time.Second)})
+	}
+	holders := map[string][]domain.Trade{"0xwallet": trades}
+	prices := &fakePrice{current: 1000, historical: historical}
+
+	source := priceTrades(context.Background(), &countedPrices{PriceService: prices}, domain.AgentInput{Chain: "ethereum", TokenAddress: "0xtoken"}, holders, prices.current)
+
+	if len(prices.requested) != 1 {
+		t.Fatalf("expected one historical request, got %d", len(prices.requested))
+	}
+	if source == nil || source.Transactions != 1 || source.Status != domain.SourceStatusOK {
+		t.Errorf("unexpected historical price source: %+v", source)
+	}
+	for _, trade := range holders["0xwallet"] {
+		nearest := trade.Timestamp.Round(time.Hour)
+		if want := historical[nearest]; trade.PriceUSD != want {
+			t.Errorf("trade at %v priced %v, want %v from the point at %v", trade.Timestamp, trade.PriceUSD, want, nearest)
+		}
+	}
+}
+
+func TestNearestPrice(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	points := []domain.PricePoint{
+		{Time: start, PriceUSD: 1},
+		{Time: start.Add(24 * time.Hour), PriceUSD: 2},
+		{Time: start.Add(48 * time.Hour), PriceUSD: 0}, // no price
+	}
+
+	tests := []struct {
+		name   string
+		ts     time.Time
+		want   float64
+		wantOK bool
+	}{
+		{"exact", start, 1, true},
+		{"closer to the later point", start.Add(13 * time.Hour), 2, true},
+		{"before the history", start.Add(-time.Hour), 1, true},
+		{"skips zero prices", start.Add(47 * time.Hour), 2, true},
+		{"too far from any point", start.Add(-25 * time.Hour), 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, ok := nearestPrice(points, tt.ts); got != tt.want || ok != tt.wantOK {
+				t.Errorf("nearestPrice() = %v, %v; want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+	if _, ok := nearestPrice(nil, start); ok {
+		t.Error("nearestPrice() found a price without points")
+	}
+}
+
+func TestAnalyzeToken_KeepsPricedTrades(t *testing.T) {
+	chain := &fakeChain{trades: map[string][]domain.Trade{
+		"0xwallet": {{Type: "buy", Amount: 10, PriceUSD: 2, Timestamp: time.Now()}},
+	}}
+	prices := &fakePrice{current: 5}
+
+	svc := NewAgentService([]domain.ChainService{chain}, prices, NewPnLCalculator())
+	if _, err := svc.AnalyzeToken(context.Background(), domain.AgentInput{
+		Chain:        "ethereum",
+		TokenAddress: "0xtoken",
+		Limit:        10,
+	}, nil); err != nil {
+		t.Fatalf("AnalyzeToken failed: %v", err)
+	}
+
+	if len(prices.requested) != 0 {
+		t.Errorf("expected no historical lookups for priced trades, got %d", len(prices.requested))
+	}
+}
+
+// attributedChain is a fakeChain that names its provider and caps its fetch size
+type attributedChain struct {
+	fakeChain
+	name  string
+	limit int
+}
+
+func (f *attributedChain) SourceName() string { return f.name }
+
+func (f *attributedChain) TransactionCap() int { return f.limit }
+
+type attributedPrice struct {
+	fakePrice
+	name string
+}
+
+func (f *attributedPrice) SourceName() string { return f.name }
+
+func findSource(sources []domain.SourceInfo, role string) *domain.SourceInfo {
+	for i := range sources {
+		if sources[i].Role == role {
+			return &sources[i]
+		}
+	}
+	return nil
+}
+
+func TestAnalyzeToken_AttributesDataSources(t *testing.T) {
+	ts := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	chain := &attributedChain{
+		name:  "alchemy",
+		limit: 1000,
+		fakeChain: fakeChain{trades: map[string][]domain.Trade{
+			// One transfer seen from both sides, plus a second transfer
+			"0xbuyer":  {{Type: "buy", Amount: 10, Timestamp: ts, TxHash: "0x1"}, {Type: "buy", Amount: 5, Timestamp: ts, TxHash: "0x2"}},
+			"0xseller": {{Type: "sell", Amount: 10, Timestamp: ts, TxHash: "0x1"}},
+		}},
+	}
+	prices := &attributedPrice{name: "coingecko", fakePrice: fakePrice{
+		current:    2,
+		historical: map[time.Time]float64{ts: 1},
+	}}
+
+	svc := NewAgentService([]domain.ChainService{chain}, prices, NewPnLCalculator())
+	out, err := svc.AnalyzeToken(context.Background(), domain.AgentInput{Chain: "ethereum", TokenAddress: "0xtoken", Limit: 10}, nil)
+	if err != nil {
+		t.Fatalf("AnalyzeToken failed: %v", err)
+	}
+
+	trades := findSource(out.DataSources, domain.SourceRoleTrades)
+	if trades == nil || trades.Name != "alchemy" || trades.Transactions != 2 || trades.Status != domain.SourceStatusOK {
+		t.Errorf("unexpected trades source: %+v", trades)
+	}
+	price := findSource(out.DataSources, domain.SourceRolePrice)
+	if price == nil || price.Name != "coingecko" || price.Status != domain.SourceStatusOK {
+		t.Errorf("unexpected price source: %+v", price)
+	}
+	historical := findSource(out.DataSources, domain.SourceRoleHistoricalPrice)
+	if historical == nil || historical.Name != "coingecko" || historical.Transactions != 1 || historical.Status != domain.SourceStatusOK {
+		t.Errorf("unexpected historical price source: %+v", historical)
+	}
+}
+
+func TestAnalyzeToken_FlagsCappedAndFailedSources(t *testing.T) {
+	ts := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	chain := &attributedChain{
+		name:  "alchemy",
+		limit: 2,
+		fakeChain: fakeChain{trades: map[string][]domain.Trade{
+			"0xa": {{Type: "buy", Amount: 1, Timestamp: ts, TxHash: "0x1"}},
+			"0xb": {{Type: "buy", Amount: 1, Timestamp: ts, TxHash: "0x2"}},
+		}},
+	}
+	// No historical data, so the lookup fails and current price is used
+	prices := &attributedPrice{name: "dexscreener", fakePrice: fakePrice{current: 2}}
+
+	svc := NewAgentService([]domain.ChainService{chain}, prices, NewPnLCalculator())
+	out, err := svc.AnalyzeToken(context.Background(), domain.AgentInput{Chain: "ethereum", TokenAddress: "0xtoken", Limit: 10}, nil)
+	if err != nil {
+		t.Fatalf("AnalyzeToken failed: %v", err)
+	}
+
+	trades := findSource(out.DataSources, domain.SourceRoleTrades)
+	if trades == nil || trades.Status != domain.SourceStatusCapped || trades.Error == "" {
+		t.Errorf("expected capped trades source, got %+v", trades)
+	}
+	historical := findSource(out.DataSources, domain.SourceRoleHistoricalPrice)
+	if historical == nil || historical.Name != "dexscreener" || historical.Status != domain.SourceStatusFailed || historical.Error == "" {
+		t.Errorf("expected failed historical price source, got %+v", historical)
+	}
+}
+
+func TestAnalyzeToken_CapCountsTransfersNotTransactions(t *testing.T) {
+	ts := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	// Three transfers routed through one transaction reach a cap of three
+	chain := &attributedChain{
+		name:  "alchemy",
+		limit: 3,
+		fakeChain: fakeChain{trades: map[string][]domain.Trade{
+			"0xa":      {{Type: "buy", Amount: 1, Timestamp: ts, TxHash: "0x1"}},
+			"0xb":      {{Type: "buy", Amount: 1, Timestamp: ts, TxHash: "0x1"}},
+			"0xc":      {{Type: "buy", Amount: 1, Timestamp: ts, TxHash: "0x1"}},
+			"0xrouter": {{Type: "sell", Amount: 3, Timestamp: ts, TxHash: "0x1"}},
+		}},
+	}
+	prices := &attributedPrice{name: "coingecko", fakePrice: fakePrice{current: 2}}
+
+	svc := NewAgentService([]domain.ChainService{chain}, prices, NewPnLCalculator())
+	out, err := svc.AnalyzeToken(context.Background(), domain.AgentInput{Chain: "ethereum", TokenAddress: "0xtoken", Limit: 10}, nil)
+	if err != nil {
+		t.Fatalf("AnalyzeToken failed: %v", err)
+	}
+
+	trades := findSource(out.DataSources, domain.SourceRoleTrades)
+	if trades == nil || trades.Transactions != 1 || trades.Status != domain.SourceStatusCapped {
+		t.Errorf("expected one capped transaction, got %+v", trades)
+	}
+	price := findSource(out.DataSources, domain.SourceRolePrice)
+	if price == nil || price.Transactions != 1 {
+		t.Errorf("expected one current price lookup, got %+v", price)
+	}
+	historical := findSource(out.DataSources, domain.SourceRoleHistoricalPrice)
+	if historical == nil || historical.Transactions != len(prices.requested) {
+		t.Errorf("historical price source reports %+v, want %d lookups", historical, len(prices.requested))
+	}
+}
+
+func TestAnalyzeToken_AttributionCanBeDisabled(t *testing.T) {
+	chain := &fakeChain{trades: map[string][]domain.Trade{
+		"0xwallet": {{Type: "buy", Amount: 10, PriceUSD: 2}},
+	}}
+
+	svc := NewAgentService([]domain.ChainService{chain}, &fakePrice{current: 5}, NewPnLCalculator())
+	svc.SetDataSourceAttribution(false)
+	out, err := svc.AnalyzeToken(context.Background(), domain.AgentInput{Chain: "ethereum", TokenAddress: "0xtoken", Limit: 10}, nil)
+	if err != nil {
+		t.Fatalf("AnalyzeToken failed: %v", err)
+	}
+	if len(out.DataSources) != 0 {
+		t.Errorf("expected no data sources when attribution is disabled, got %+v", out.DataSources)
+	}
+}
+
+// windowedChain is a fakeChain that records the window it was asked for
+type windowedChain struct {
+	fakeChain
+	window *domain.TradeWindow
+}
+
+func (f *windowedChain) GetHoldersWithTradesInWindow(ctx context.Context, tokenAddress string, window domain.TradeWindow) (map[string][]domain.Trade, error) {
+	f.window = &window
+	return f.trades, nil
+}
+
+func rankedAddresses(out *domain.AgentOutput) []string {
+	var addrs []string
+	for _, w := range out.TopWallets {
+		addrs = append(addrs, w.Address)
+	}
+	return addrs
+}
+
+func TestAnalyzeToken_ExcludesSwapsOutsideTimeWindow(t *testing.T) {
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 5, 8, 0, 0, 0, 0, time.UTC)
+
+	chain := &windowedChain{fakeChain: fakeChain{trades: map[string][]domain.Trade{
+		// Only trades inside the window
+		"0xinside": {
+			{Type: "buy", Amount: 10, PriceUSD: 1, Timestamp: from, TxHash: "0x1"},
+			{Type: "sell", Amount: 10, PriceUSD: 2, Timestamp: to, TxHash: "0x2"},
+		},
+		// A huge win, but entirely before the window
+		"0xbefore": {
+			{Type: "buy", Amount: 100, PriceUSD: 1, Timestamp: from.Add(-48 * time.Hour), TxHash: "0x3"},
+			{Type: "sell", Amount: 100, PriceUSD: 50, Timestamp: from.Add(-time.Second), TxHash: "0x4"},
+		},
+		// Bought inside the window, sold after it
+		"0xstraddle": {
+			{Type: "buy", Amount: 5, PriceUSD: 1, Timestamp: from.Add(time.Hour), TxHash: "0x5"},
+			{Type: "sell", Amount: 5, PriceUSD: 100, Timestamp: to.Add(time.Second), TxHash: "0x6"},
+		},
+	}}}
+
+	svc := NewAgentService([]domain.ChainService{chain}, &fakePrice{current: 1}, NewPnLCalculator())
+	out, err := svc.AnalyzeToken(context.Background(), domain.AgentInput{
+		Chain:        "ethereum",
+		TokenAddress: "0xtoken",
+		Limit:        10,
+		FromTime:     from,
+		ToTime:       to,
+	}, nil)
+	if err != nil {
+		t.Fatalf("AnalyzeToken failed: %v", err)
+	}
+
+	if chain.window == nil || !chain.window.From.Equal(from) || !chain.window.To.Equal(to) {
+		t.Errorf("expected chain service to receive the window, got %+v", chain.window)
+	}
+
+	got := map[string]domain.WalletPnL{}
+	for _, w := range out.TopWallets {
+		got[w.Address] = w
+	}
+	if _, ok := got["0xbefore"]; ok {
+		t.Errorf("wallet trading only before the window was ranked: %v", rankedAddresses(out))
+	}
+	if w, ok := got["0xstraddle"]; !ok || w.TotalSold != 0 {
+		t.Errorf("expected 0xstraddle ranked without its late sell, got %+v", w)
+	}
+	if w, ok := got["0xinside"]; !ok || w.RealizedPnL != 10 {
+		t.Errorf("expected 0xinside realized PnL 10, got %+v", w)
+	}
+}
+
+func TestAnalyzeToken_MaxSwapsKeepsMostRecent(t *testing.T) {
+	base := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	// Each transfer appears from both sides but counts as one swap
+	chain := &fakeChain{trades: map[string][]domain.Trade{
+		"0xold": {{Type: "buy", Amount: 1, PriceUSD: 1, Timestamp: base, TxHash: "0x1"}},
+		"0xmid": {
+			{Type: "buy", Amount: 1, PriceUSD: 1, Timestamp: base.Add(time.Hour), TxHash: "0x2"},
+		},
+		"0xnew": {
+			{Type: "buy", Amount: 1, PriceUSD: 1, Timestamp: base.Add(2 * time.Hour), TxHash: "0x3"},
+		},
+		"0xseller": {
+			{Type: "sell", Amount: 1, PriceUSD: 1, Timestamp: base, TxHash: "0x1"},
+			{Type: "sell", Amount: 1, PriceUSD: 1, Timestamp: base.Add(time.Hour), TxHash: "0x2"},
+			{Type: "sell", Amount: 1, PriceUSD: 1, Timestamp: base.Add(2 * time.Hour), TxHash: "0x3"},
+		},
+	}}
+
+	svc := NewAgentService([]domain.ChainService{chain}, &fakePrice{current: 3}, NewPnLCalculator())
+	out, err := svc.AnalyzeToken(context.Background(), domain.AgentInput{
+		Chain:        "ethereum",
+		TokenAddress: "0xtoken",
+		Limit:        10,
+		MaxSwaps:     2,
+	}, nil)
+	if err != nil {
+		t.Fatalf("AnalyzeToken failed: %v", err)
+	}
+
+	got := map[string]domain.WalletPnL{}
+	for _, w := range out.TopWallets {
+		got[w.Address] = w
+	}
+	if _, ok := got["0xold"]; ok {
+		t.Errorf("wallet from the oldest swap was ranked: %v", rankedAddresses(out))
+	}
+	if _, ok := got["0xmid"]; !ok {
+		t.Errorf("expected 0xmid ranked, got %v", rankedAddresses(out))
+	}
+	if _, ok := got["0xnew"]; !ok {
+		t.Errorf("expected 0xnew ranked, got %v", rankedAddresses(out))
+	}
+	if w := got["0xseller"]; w.TotalSold != 2 {
+		t.Errorf("expected 0xseller to keep 2 sells, got %v", w.TotalSold)
+	}
+}
+
+func TestAnalyzeToken_RejectsInvalidWindow(t *testing.T) {
+	from := time.Date(2024, 5, 8, 0, 0, 0, 0, time.UTC)
+	svc := NewAgentService([]domain.ChainService{&fakeChain{}}, &fakePrice{current: 1}, NewPnLCalculator())
+
+	tests := []struct {
+		name  string
+		input domain.AgentInput
+	}{
+		{"to before from", domain.AgentInput{Chain: "ethereum", TokenAddress: "0xtoken", FromTime: from, ToTime: from.Add(-time.Hour)}},
+		{"negative max swaps", domain.AgentInput{Chain: "ethereum", TokenAddress: "0xtoken", MaxSwaps: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.AnalyzeToken(context.Background(), tt.input, nil); err == nil {
+				t.Error("expected an error for an invalid window")
+			}
+		})
+	}
+}
+
+func TestAnalyzeToken_IncludesTokenMetadata(t *testing.T) {
+	chain := &fakeChain{trades: map[string][]domain.Trade{
+		"0xwallet": {{Type: "buy", Amount: 10, PriceUSD: 2}},
+	}}
+
+	svc := NewAgentService([]domain.ChainService{chain}, &fakePrice{current: 5}, NewPnLCalculator())
+	out, err := svc.AnalyzeToken(context.Background(), domain.AgentInput{Chain: "ethereum", TokenAddress: "0xtoken", Limit: 10}, nil)
+	if err != nil {
+		t.Fatalf("AnalyzeToken failed: %v", err)
+	}
+
+	want := domain.TokenMetadata{Address: "0xtoken", Symbol: "TEST", Name: "Test Token", Decimals: 18}
+	if out.Token == nil || *out.Token != want {
+		t.Errorf("expected token metadata %+v, got %+v", want, out.Token)
+	}
+	if out.TokenSymbol != "TEST" {
+		t.Errorf("expected token symbol TEST, got %q", out.TokenSymbol)
+	}
+
+	data, _ := json.Marshal(out)
+	var decoded map[string]interface{}
+	json.Unmarshal(data, &decoded)
+	token, _ := decoded["token"].(map[string]interface{})
+	if token["symbol"] != "TEST" || token["decimals"] != float64(18) || token["name"] != "Test Token" {
+		t.Errorf("unexpected token JSON: %s", data)
+	}
+}
+
+func TestAnalyzeToken_ToleratesMissingMetadata(t *testing.T) {
+	chain := &fakeChain{
+		trades:  map[string][]domain.Trade{"0xwallet": {{Type: "buy", Amount: 10, PriceUSD: 2}}},
+		metaErr: errors.New("execution reverted"),
+	}
+
+	svc := NewAgentService([]domain.ChainService{chain}, &fakePrice{current: 5}, NewPnLCalculator())
+	out, err := svc.AnalyzeToken(context.Background(), domain.AgentInput{Chain: "ethereum", TokenAddress: "0xtoken", Limit: 10}, nil)
+	if err != nil {
+		t.Fatalf("AnalyzeToken failed: %v", err)
+	}
+
+	if out.Token != nil || out.TokenSymbol != "" {
+		t.Errorf("expected no token metadata, got %+v", out.Token)
+	}
+	if len(out.TopWallets) != 1 {
+		t.Errorf("expected the analysis to continue, got %d wallets", len(out.TopWallets))
+	}
+}
+
+func TestAnalyzeToken_ScalesRawAmountsByTokenDecimals(t *testing.T) {
+	// 1,500.25 USDC bought at $1 and 1,000 sold at $1.10; the transfer
+	// reports 18 decimals but the contract's 6 decimals win
+	raw := func(s string) *big.Int {
+		v, _ := new(big.Int).SetString(s, 10)
+		return v
+	}
+	chain := &fakeChain{
+		meta: &domain.TokenMetadata{Symbol: "USDC", Decimals: 6},
+		trades: map[string][]domain.Trade{"0xwallet": {
+			{Type: "buy", Amount: 1, RawAmount: raw("1500250000"), Decimals: 18, PriceUSD: 1, TxHash: "0x1"},
+			{Type: "sell", Amount: 1, RawAmount: raw("1000000000"), Decimals: 18, PriceUSD: 1.1, TxHash: "0x2", Timestamp: time.Unix(1, 0)},
+		}},
+	}
+
+	svc := NewAgentService([]domain.ChainService{chain}, &fakePrice{current: 1}, NewPnLCalculator())
+	out, err := svc.AnalyzeToken(context.Background(), domain.AgentInput{Chain: "ethereum", TokenAddress: "0xusdc", Limit: 10}, nil)
+	if err != nil {
+		t.Fatalf("AnalyzeToken failed: %v", err)
+	}
+
+	w := out.TopWallets[0]
+	if w.TotalBought != 1500.25 || w.TotalSold != 1000 || w.CurrentBalance != 500.25 {
+		t.Errorf("expected 1500.25 bought, 1000 sold, 500.25 held, got %+v", w)
+	}
+	if math.Abs(w.RealizedPnL-100) > 1e-9 {
+		t.Errorf("expected realized PnL 100, got %v", w.RealizedPnL)
+	}
+}
+
+func TestAnalyzeToken_MinTradesAndMinVolume(t *testing.T) {
+	ts := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	newChain := func() *fakeChain {
+		return &fakeChain{trades: map[string][]domain.Trade{
+			// One lucky 10x on a $10 position
+			"0xlucky": {
+				{Type: "buy", Amount: 10, PriceUSD: 1, Timestamp: ts},
+				{Type: "sell", Amount: 10, PriceUSD: 10, Timestamp: ts.Add(time.Hour)},
+			},
+			// Consistent trader: four trades, $5,000 volume
+			"0xsteady": {
+				{Type: "buy", Amount: 1000, PriceUSD: 1, Timestamp: ts},
+				{Type: "sell", Amount: 1000, PriceUSD: 1.2, Timestamp: ts.Add(time.Hour)},
+				{Type: "buy", Amount: 1000, PriceUSD: 1, Timestamp: ts.Add(2 * time.Hour)},
+				{Type: "sell", Amount: 1000, PriceUSD: 1.6, Timestamp: ts.Add(3 * time.Hour)},
+			},
+		}}
+	}
+
+	tests := []struct {
+		name        string
+		minTrades   int
+		minVolume   float64
+		want        []string
+		wantMessage bool
+	}{
+		{"no thresholds", 0, 0, []string{"0xsteady", "0xlucky"}, false},
+		{"min trades", 3, 0, []string{"0xsteady"}, false},
+		{"min volume", 0, 1000, []string{"0xsteady"}, false},
+		{"excludes everyone", 10, 1e6, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewAgentService([]domain.ChainService{newChain()}, &fakePrice{current: 1}, NewPnLCalculator())
+			out, err := svc.AnalyzeToken(context.Background(), domain.AgentInput{
+				Chain:        "ethereum",
+				TokenAddress: "0xtoken",
+				Limit:        10,
+				MinTrades:    tt.minTrades,
+				MinVolume:    tt.minVolume,
+			}, nil)
+			if err != nil {
+				t.Fatalf("AnalyzeToken failed: %v", err)
+			}
+
+			got := rankedAddresses(out)
+			if len(got) != len(tt.want) {
+				t.Fatalf("ranked %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("ranked %v, want %v", got, tt.want)
+				}
+			}
+			if (out.Message != "") != tt.wantMessage {
+				t.Errorf("unexpected message %q", out.Message)
+			}
+			if tt.wantMessage && !strings.Contains(out.Message, "no wallets met criteria") {
+				t.Errorf("expected a no-wallets message, got %q", out.Message)
+			}
+		})
+	}
+}
+
+func TestAnalyzeToken_ReportsProgressInOrder(t *testing.T) {
+	chain := &fakeChain{trades: map[string][]domain.Trade{
+		"0xalpha": {
+			{Type: "buy", Amount: 100, PriceUSD: 1, TxHash: "0x1"},
+			{Type: "sell", Amount: 100, PriceUSD: 3, TxHash: "0x2"},
+		},
+		"0xbeta": {{Type: "buy", Amount: 10, PriceUSD: 2, TxHash: "0x3"}},
+	}}
+	svc := NewAgentService([]domain.ChainService{chain}, &fakePrice{current: 2}, NewPnLCalculator())
+
+	var got []domain.Progress
+	_, err := svc.AnalyzeToken(context.Background(), domain.AgentInput{Chain: "ethereum", TokenAddress: "0xtoken", Limit: 10}, func(p domain.Progress) {
+		got = append(got, p)
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeToken failed: %v", err)
+	}
+
+	want := []domain.Progress{
+		{Phase: domain.PhaseFetched, Count: 3, Message: "fetched 3 swaps"},
+		{Phase: domain.PhaseNormalized, Count: 3, Message: "normalized 3 swaps"},
+		{Phase: domain.PhaseComputingPnL, Count: 2, Message: "computing PnL for 2 wallets"},
+		{Phase: domain.PhaseRanking, Count: 2, Message: "ranking 2 wallets"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d progress updates %+v, want %d", len(got), got, len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("update %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestAnalyzeToken_NoProgressOnEarlyFailure(t *testing.T) {
+	svc := NewAgentService([]domain.ChainService{&fakeChain{}}, &fakePrice{current: 1}, NewPnLCalculator())
+
+	calls := 0
+	_, err := svc.AnalyzeToken(context.Background(), domain.AgentInput{Chain: "solana", TokenAddress: "0xtoken"}, func(domain.Progress) {
+		calls++
+	})
+	if err == nil {
+		t.Fatal("expected an error for an unsupported chain")
+	}
+	if calls != 0 {
+		t.Errorf("progress called %d times, want 0", calls)
+	}
+}
+
+func TestAnalyzeTokens_LabelsEachTokenAndIsolatesFailures(t *testing.T) {
+	chain := &fakeChain{trades: map[string][]domain.Trade{
+		"0xalpha": {
+			{Type: "buy", Amount: 100, PriceUSD: 1, TxHash: "0x1"},
+			{Type: "sell", Amount: 100, PriceUSD: 3, TxHash: "0x2"},
+		},
+	}}
+	svc := NewAgentService([]domain.ChainService{chain}, &fakePrice{current: 2}, NewPnLCalculator())
+
+	var messages []string
+	out, err := svc.AnalyzeTokens(context.Background(), domain.AgentInput{
+		Chain:          "ethereum",
+		TokenAddresses: []string{testTokenA, "0xnot-an-address", testTokenB},
+		Limit:          10,
+	}, func(p domain.Progress) {
+		messages = append(messages, p.Message)
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeTokens failed: %v", err)
+	}
+
+	if len(out.Results) != 3 {
+		t.Fatalf("got %d results, want 3", len(out.Results))
+	}
+	for i, want := range []string{testTokenA, "0xnot-an-address", testTokenB} {
+		if out.Results[i].TokenAddress != want {
+			t.Errorf("result %d token = %s, want %s", i, out.Results[i].TokenAddress, want)
+		}
+	}
+	for _, i := range []int{0, 2} {
+		r := out.Results[i]
+		if r.Error != "" || r.Result == nil || len(r.Result.TopWallets) != 1 {
+			t.Errorf("result %d = %+v, want one ranked wallet", i, r)
+		}
+		if r.Result != nil && r.Result.Token.Address != r.TokenAddress {
+			t.Errorf("result %d token metadata address = %s, want %s", i, r.Result.Token.Address, r.TokenAddress)
+		}
+	}
+	if bad := out.Results[1]; bad.Result != nil || !strings.Contains(bad.Error, "invalid ethereum token address") {
+		t.Errorf("invalid token result = %+v, want an address error", bad)
+	}
+
+	if len(messages) != 8 || !strings.HasPrefix(messages[0], testTokenA+": ") || !strings.HasPrefix(messages[4], testTokenB+": ") {
+		t.Errorf("progress messages = %v, want four per analyzed token labeled with its address", messages)
+	}
+}
+
+func TestAnalyzeTokens_SharedInputErrorsFailTheBatch(t *testing.T) {
+	svc := NewAgentService([]domain.ChainService{&fakeChain{}}, &fakePrice{current: 1}, NewPnLCalculator())
+
+	tests := []struct {
+		name  string
+		input domain.AgentInput
+	}{
+		{"unsupported chain", domain.AgentInput{Chain: "solana", TokenAddresses: []string{testTokenA}}},
+		{"negative threshold", domain.AgentInput{Chain: "ethereum", TokenAddresses: []string{testTokenA}, MinTrades: -1}},
+		{"no tokens", domain.AgentInput{Chain: "ethereum"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.AnalyzeTokens(context.Background(), tt.input, nil); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
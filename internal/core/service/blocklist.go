@@ -0,0 +1,125 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultBlockedAddresses are well-known routers, aggregators, exchange hot
+// wallets and burn addresses per chain. They appear as swap counterparties
+// but are not traders, so they are excluded from wallet rankings.
+var defaultBlockedAddresses = map[string][]string{
+	"ethereum": {
+		"0x0000000000000000000000000000000000000000", // Mint/burn
+		"0x000000000000000000000000000000000000dEaD", // Burn
+		"0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D", // Uniswap V2 Router
+		"0xE592427A0AEce92De3Edee1F18E0157C05861564", // Uniswap V3 SwapRouter
+		"0x68b3465833fb72A70ecDF485E0e4C7bD8665Fc45", // Uniswap SwapRouter02
+		"0xEf1c6E67703c7BD7107eed8303Fbe6EC2554BF6B", // Uniswap Universal Router (legacy)
+		"0x3fC91A3afd70395Cd496C647d5a6CC9D4B2b7FAD", // Uniswap Universal Router
+		"0xd9e1cE17f2641f24aE83637ab66a2cca9C378B9F", // SushiSwap Router
+		"0x1111111254EEB25477B68fb85Ed929f73A960582", // 1inch v5 Router
+		"0x111111125421cA6dc452d289314280a0f8842A65", // 1inch v6 Router
+		"0xDef1C0ded9bec7F1a1670819833240f027b25EfF", // 0x Exchange Proxy
+		"0x28C6c06298d514Db089934071355E5743bf21d60", // Binance 14
+		"0xA9D1e08C7793af67e9d92fe308d5697FB81d3E43", // Coinbase 10
+	},
+	"solana": {
+		"JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4",  // Jupiter v6
+		"JUP4Fb2cqiRUcaTHdrPC8h2gNsA2ETXiPDD33WcGuJB",  // Jupiter v4
+		"675kPX9MHTjS2zt1qfr1NYHuzeLXfQM9H24wFSUt1Mp8", // Raydium AMM v4
+		"5Q544fKrFoe6tsEbD7S8EmxGTJYAKtTVhAW5Q5pge4j1", // Raydium AMM authority
+		"CAMMCzo5YL8w4VFF8KVHrK22GGUsp5VTaW7grrKgrWqK", // Raydium CLMM
+		"whirLbMiicVdio4qvUfM5KAg6Ct8VwpYzGff3uctyCc",  // Orca Whirlpool
+		"6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P",  // Pump.fun
+		"5tzFkiKscXHK5ZXCGbXZxdw7gTjjD1mBwuoFbhUvuAi9", // Binance 2
+		"H8sMJSCQxfKiFTCfDR3DUMLPwcRbM61LGFJ8N4dK3WjS", // Coinbase 1
+	},
+}
+
+// Blocklist is a per-chain set of addresses excluded from wallet rankings.
+// EVM addresses match case-insensitively; Solana addresses are base58 and
+// match exactly. The zero value is empty and ready to use.
+type Blocklist struct {
+	addresses map[string]map[string]struct{}
+}
+
+// NewBlocklist returns an empty blocklist.
+func NewBlocklist() *Blocklist {
+	return &Blocklist{}
+}
+
+// DefaultBlocklist returns a blocklist seeded with common routers,
+// aggregators and exchange wallets. Callers may Add to it.
+func DefaultBlocklist() *Blocklist {
+	b := NewBlocklist()
+	for chain, addresses := range defaultBlockedAddresses {
+		b.Add(chain, addresses...)
+	}
+	return b
+}
+
+// Add blocks addresses on chain. Chain aliases such as "eth" and "sol" are
+// accepted.
+func (b *Blocklist) Add(chain string, addresses ...string) {
+	chain = canonicalChain(chain)
+	if b.addresses == nil {
+		b.addresses = make(map[string]map[string]struct{})
+	}
+	if b.addresses[chain] == nil {
+		b.addresses[chain] = make(map[string]struct{})
+	}
+	for _, addr := range addresses {
+		if addr = normalizeAddress(chain, addr); addr != "" {
+			b.addresses[chain][addr] = struct{}{}
+		}
+	}
+}
+
+// Contains reports whether address is blocked on chain.
+func (b *Blocklist) Contains(chain, address string) bool {
+	if b == nil {
+		return false
+	}
+	chain = canonicalChain(chain)
+	_, ok := b.addresses[chain][normalizeAddress(chain, address)]
+	return ok
+}
+
+// AddEntries adds comma-separated "chain:address" entries, as
+// read from configuration, to b.
+func (b *Blocklist) AddEntries(entries string) error {
+	for _, entry := range strings.Split(entries, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		chain, addr, ok := strings.Cut(entry, ":")
+		if !ok || strings.TrimSpace(chain) == "" || strings.TrimSpace(addr) == "" {
+			return fmt.Errorf("invalid blocklist entry %q, expected chain:address", entry)
+		}
+		b.Add(strings.TrimSpace(chain), strings.TrimSpace(addr))
+	}
+	return nil
+}
+
+// canonicalChain maps chain aliases to the name used as the blocklist key
+func canonicalChain(chain string) string {
+	switch chain = strings.ToLower(strings.TrimSpace(chain)); chain {
+	case "eth":
+		return "ethereum"
+	case "sol":
+		return "solana"
+	}
+	return chain
+}
+
+// normalizeAddress lowercases hex addresses so checksummed and plain forms
+// match; base58 Solana addresses are case-sensitive and kept as is
+func normalizeAddress(chain, address string) string {
+	address = strings.TrimSpace(address)
+	if chain == "solana" {
+		return address
+	}
+	return strings.ToLower(address)
+}
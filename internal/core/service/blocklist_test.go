@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/core/domain"
+)
+
+const uniswapV2Router = "0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D"
+
+func TestBlocklist_ChainAware(t *testing.T) {
+	b := DefaultBlocklist()
+
+	tests := []struct {
+		chain, address string
+		want           bool
+	}{
+		{"ethereum", uniswapV2Router, true},
+		{"eth", "0x7A250D5630B4CF539739DF2C5DACB4C659F2488D", true}, // alias and case-insensitive
+		{"solana", uniswapV2Router, false},                          // EVM router means nothing on Solana
+		{"solana", "JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4", true},
+		{"sol", "jup6lkbzbjs1jkkwapdhny74zcz3tluzoi5qnyvtav4", false}, // base58 is case-sensitive
+		{"ethereum", "0xtrader", false},
+	}
+	for _, tt := range tests {
+		if got := b.Contains(tt.chain, tt.address); got != tt.want {
+			t.Errorf("Contains(%q, %q) = %v, want %v", tt.chain, tt.address, got, tt.want)
+		}
+	}
+
+	var nilList *Blocklist
+	if nilList.Contains("ethereum", uniswapV2Router) {
+		t.Error("nil blocklist should block nothing")
+	}
+}
+
+func TestBlocklist_AddEntries(t *testing.T) {
+	b := NewBlocklist()
+	if err := b.AddEntries(" ethereum:0xMarketMaker, sol:SoLMarketMaker ,"); err != nil {
+		t.Fatalf("AddEntries failed: %v", err)
+	}
+	if !b.Contains("ethereum", "0xmarketmaker") || !b.Contains("solana", "SoLMarketMaker") {
+		t.Error("expected configured entries to be blocked")
+	}
+	if b.Contains("ethereum", uniswapV2Router) {
+		t.Error("an empty blocklist should not include the defaults")
+	}
+
+	for _, entries := range []string{"0xnochain", "ethereum:", ":0xaddr"} {
+		if err := b.AddEntries(entries); err == nil {
+			t.Errorf("AddEntries(%q) should fail", entries)
+		}
+	}
+}
+
+func TestAnalyzeToken_ExcludesBlocklistedAddresses(t *testing.T) {
+	ts := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	newChain := func() *fakeChain {
+		return &fakeChain{trades: map[string][]domain.Trade{
+			"0xtrader":      {{Type: "buy", Amount: 10, PriceUSD: 1, Timestamp: ts, TxHash: "0x1"}},
+			uniswapV2Router: {{Type: "buy", Amount: 1000, PriceUSD: 1, Timestamp: ts, TxHash: "0x2"}},
+			"0xmarketmaker": {{Type: "buy", Amount: 500, PriceUSD: 1, Timestamp: ts, TxHash: "0x3"}},
+		}}
+	}
+	input := domain.AgentInput{Chain: "ethereum", TokenAddress: "0xtoken", Limit: 10}
+
+	tests := []struct {
+		name      string
+		blocklist func() *Blocklist
+		want      []string
+	}{
+		{"default blocklist", nil, []string{"0xmarketmaker", "0xtrader"}},
+		{"extended blocklist", func() *Blocklist {
+			b := DefaultBlocklist()
+			b.Add("ethereum", "0xMarketMaker")
+			return b
+		}, []string{"0xtrader"}},
+		{"disabled", func() *Blocklist { return nil }, []string{uniswapV2Router, "0xmarketmaker", "0xtrader"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewAgentService([]domain.ChainService{newChain()}, &fakePrice{current: 2}, NewPnLCalculator())
+			if tt.blocklist != nil {
+				svc.SetBlocklist(tt.blocklist())
+			}
+
+			out, err := svc.AnalyzeToken(context.Background(), input, nil)
+			if err != nil {
+				t.Fatalf("AnalyzeToken failed: %v", err)
+			}
+			got := rankedAddresses(out)
+			if len(got) != len(tt.want) {
+				t.Fatalf("ranked %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("ranked %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
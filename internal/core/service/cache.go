@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/core/domain"
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/cache"
+)
+
+// DefaultResultCacheTTL is how long analysis results are reused when
+// SetResultCache is given no TTL
+const DefaultResultCacheTTL = 5 * time.Minute
+
+// resultCacheKey identifies an analysis by everything that shapes its
+// result: chains, token, limit, trade window and ranking thresholds.
+func resultCacheKey(input domain.AgentInput) string {
+	chain := canonicalChain(input.Chain)
+	window := input.Window()
+
+	// Zero bounds mean unbounded and must not collide with the Unix epoch
+	bound := func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return strconv.FormatInt(t.Unix(), 10)
+	}
+
+	return strings.Join([]string{
+		"analysis",
+		strings.Join(canonicalChains(input.AllChains()), ","),
+		normalizeAddress(chain, input.TokenAddress),
+		strconv.Itoa(input.Limit),
+		bound(window.From),
+		bound(window.To),
+		strconv.Itoa(window.MaxSwaps),
+		strconv.Itoa(input.MinTrades),
+		strconv.FormatFloat(input.MinVolume, 'f', -1, 64),
+	}, ":")
+}
+
+// cachedResult returns a stored analysis for input. Misses, cache errors and
+// entries that no longer decode all report false so the caller computes the
+// result live.
+func (s *AgentService) cachedResult(ctx context.Context, input domain.AgentInput) (*domain.AgentOutput, bool) {
+	if s.resultCache == nil {
+		return nil, false
+	}
+
+	var output domain.AgentOutput
+	if err := cache.GetJSON(ctx, s.resultCache, resultCacheKey(input), &output); err != nil {
+		return nil, false
+	}
+	output.Cached = true
+	return &output, true
+}
+
+// storeResult caches a fresh analysis. A result that cannot be cached is
+// still returned to the caller, so errors are ignored.
+func (s *AgentService) storeResult(ctx context.Context, input domain.AgentInput, output *domain.AgentOutput) {
+	if s.resultCache == nil {
+		return
+	}
+
+	_ = cache.SetJSON(ctx, s.resultCache, resultCacheKey(input), output, s.resultCacheTTL)
+}
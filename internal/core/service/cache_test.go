@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/core/domain"
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/cache"
+)
+
+// fakeCache stores values in memory and expires them against a fake clock
+type fakeCache struct {
+	cache.NoOpCache
+
+	now     time.Time
+	entries map[string][]byte
+	expiry  map[string]time.Time
+	getErr  error
+}
+
+func newFakeCache() *fakeCache {
+	return &fakeCache{
+		now:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		entries: map[string][]byte{},
+		expiry:  map[string]time.Time{},
+	}
+}
+
+func (c *fakeCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	c.entries[key] = value.([]byte)
+	c.expiry[key] = c.now.Add(ttl)
+	return nil
+}
+
+func (c *fakeCache) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	if c.getErr != nil {
+		return nil, c.getErr
+	}
+	data, ok := c.entries[key]
+	if !ok || !c.now.Before(c.expiry[key]) {
+		return nil, cache.ErrCacheKeyNotFound
+	}
+	return data, nil
+}
+
+// countingChain counts how often trades are fetched
+type countingChain struct {
+	fakeChain
+	fetches int
+}
+
+func (c *countingChain) GetHoldersWithTrades(ctx context.Context, tokenAddress string) (map[string][]domain.Trade, error) {
+	c.fetches++
+	return c.fakeChain.GetHoldersWithTrades(ctx, tokenAddress)
+}
+
+func newCachedService(t *testing.T, ttl time.Duration) (*AgentService, *countingChain, *fakeCache) {
+	t.Helper()
+	chain := &countingChain{fakeChain: fakeChain{trades: map[string][]domain.Trade{
+		"0xalpha": {
+			{Type: "buy", Amount: 100, PriceUSD: 1, TxHash: "0x1"},
+			{Type: "sell", Amount: 100, PriceUSD: 3, TxHash: "0x2"},
+		},
+	}}}
+	store := newFakeCache()
+	svc := NewAgentService([]domain.ChainService{chain}, &fakePrice{current: 2}, NewPnLCalculator())
+	svc.SetResultCache(store, ttl)
+	return svc, chain, store
+}
+
+func TestAnalyzeToken_CacheHitAndMiss(t *testing.T) {
+	svc, chain, _ := newCachedService(t, time.Minute)
+	input := domain.AgentInput{Chain: "ethereum", TokenAddress: "0xToken", Limit: 10}
+
+	first, err := svc.AnalyzeToken(context.Background(), input, nil)
+	if err != nil {
+		t.Fatalf("AnalyzeToken failed: %v", err)
+	}
+	if first.Cached {
+		t.Error("first result should be computed live")
+	}
+
+	// The same token in another case is the same analysis
+	progressCalls := 0
+	second, err := svc.AnalyzeToken(context.Background(), domain.AgentInput{Chain: "ethereum", TokenAddress: "0xtoken", Limit: 10}, func(domain.Progress) {
+		progressCalls++
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeToken failed: %v", err)
+	}
+	if chain.fetches != 1 || !second.Cached || progressCalls != 0 {
+		t.Errorf("fetches = %d, cached = %v, progress calls = %d, want a cache hit", chain.fetches, second.Cached, progressCalls)
+	}
+	if len(second.TopWallets) != 1 || second.TopWallets[0] != first.TopWallets[0] {
+		t.Errorf("cached wallets = %+v, want %+v", second.TopWallets, first.TopWallets)
+	}
+
+	// A different limit, window or threshold is a different analysis
+	for _, other := range []domain.AgentInput{
+		{Chain: "ethereum", TokenAddress: "0xtoken", Limit: 5},
+		{Chain: "ethereum", TokenAddress: "0xtoken", Limit: 10, FromTime: time.Unix(1, 0)},
+		{Chain: "ethereum", TokenAddress: "0xtoken", Limit: 10, MaxSwaps: 50},
+		{Chain: "ethereum", TokenAddress: "0xtoken", Limit: 10, MinTrades: 1},
+	} {
+		before := chain.fetches
+		if _, err := svc.AnalyzeToken(context.Background(), other, nil); err != nil {
+			t.Fatalf("AnalyzeToken failed: %v", err)
+		}
+		if chain.fetches != before+1 {
+			t.Errorf("input %+v was served from the cache", other)
+		}
+	}
+}
+
+func TestAnalyzeToken_CacheExpires(t *testing.T) {
+	svc, chain, store := newCachedService(t, time.Minute)
+	input := domain.AgentInput{Chain: "ethereum", TokenAddress: "0xtoken", Limit: 10}
+
+	for _, step := range []struct {
+		advance     time.Duration
+		wantFetches int
+	}{
+		{0, 1},
+		{30 * time.Second, 1},
+		{31 * time.Second, 2}, // Expired, recomputed and stored again
+		{59 * time.Second, 2},
+	} {
+		store.now = store.now.Add(step.advance)
+		if _, err := svc.AnalyzeToken(context.Background(), input, nil); err != nil {
+			t.Fatalf("AnalyzeToken failed: %v", err)
+		}
+		if chain.fetches != step.wantFetches {
+			t.Fatalf("after %s fetches = %d, want %d", step.advance, chain.fetches, step.wantFetches)
+		}
+	}
+}
+
+func TestAnalyzeToken_CacheFailuresFallThrough(t *testing.T) {
+	input := domain.AgentInput{Chain: "ethereum", TokenAddress: "0xtoken", Limit: 10}
+
+	t.Run("corrupt entry", func(t *testing.T) {
+		svc, chain, store := newCachedService(t, time.Minute)
+		store.entries[resultCacheKey(input)] = []byte("{not json")
+		store.expiry[resultCacheKey(input)] = store.now.Add(time.Hour)
+
+		out, err := svc.AnalyzeToken(context.Background(), input, nil)
+		if err != nil || out.Cached || chain.fetches != 1 {
+			t.Fatalf("got %+v, %v after %d fetches, want a live result", out, err, chain.fetches)
+		}
+		if _, ok := svc.cachedResult(context.Background(), input); !ok {
+			t.Error("corrupt entry should be replaced by the live result")
+		}
+	})
+
+	t.Run("cache unavailable", func(t *testing.T) {
+		svc, chain, store := newCachedService(t, time.Minute)
+		store.getErr = errors.New("connection refused")
+
+		for i := 0; i < 2; i++ {
+			out, err := svc.AnalyzeToken(context.Background(), input, nil)
+			if err != nil || out.Cached {
+				t.Fatalf("got %+v, %v, want a live result", out, err)
+			}
+		}
+		if chain.fetches != 2 {
+			t.Errorf("fetches = %d, want 2", chain.fetches)
+		}
+	})
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/core/domain"
+)
+
+// csvHeader names the columns written by FormatCSV
+var csvHeader = []string{
+	"rank", "wallet_address", "total_pnl_usd", "realized_pnl_usd", "unrealized_pnl_usd",
+	"roi_percentage", "total_bought_tokens", "total_sold_tokens", "current_balance_tokens",
+	"avg_buy_price_usd", "avg_sell_price_usd", "trade_count", "volume_usd",
+}
+
+// ParseOutputFormat normalizes an output format name, defaulting to JSON.
+func ParseOutputFormat(format string) (string, error) {
+	switch f := strings.ToLower(strings.TrimSpace(format)); f {
+	case "":
+		return domain.FormatJSON, nil
+	case domain.FormatJSON, domain.FormatText, domain.FormatMarkdown, domain.FormatCSV:
+		return f, nil
+	case "md":
+		return domain.FormatMarkdown, nil
+	}
+	return "", fmt.Errorf("unknown output format %q, expected json, text, markdown or csv", format)
+}
+
+// FormatOutput renders an analysis result in the given format.
+func FormatOutput(out *domain.AgentOutput, format string) (string, error) {
+	format, err := ParseOutputFormat(format)
+	if err != nil {
+		return "", err
+	}
+
+	switch format {
+	case domain.FormatText:
+		return formatText(out), nil
+	case domain.FormatMarkdown:
+		return formatMarkdown(out), nil
+	case domain.FormatCSV:
+		return formatCSV(out)
+	default:
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal result: %w", err)
+		}
+		return string(data), nil
+	}
+}
+
+// tokenLabel names the analyzed token for headings
+func tokenLabel(out *domain.AgentOutput) string {
+	if out.TokenSymbol != "" {
+		return out.TokenSymbol
+	}
+	if out.Token != nil && out.Token.Address != "" {
+		return out.Token.Address
+	}
+	return "token"
+}
+
+// emptyMessage explains a result without wallets
+func emptyMessage(out *domain.AgentOutput) string {
+	if out.Message != "" {
+		return out.Message
+	}
+	return "no profitable wallets found"
+}
+
+func formatText(out *domain.AgentOutput) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Top %d wallets for %s (price $%s)\n", len(out.TopWallets), tokenLabel(out), formatNumber(out.CurrentPrice))
+	if len(out.TopWallets) == 0 {
+		sb.WriteString(emptyMessage(out))
+		return sb.String()
+	}
+
+	for i, w := range out.TopWallets {
+		fmt.Fprintf(&sb, "\n%d. %s\n   Total PnL: $%s (realized $%s, unrealized $%s), ROI: %s%%, Trades: %d, Volume: $%s",
+			i+1, w.Address,
+			formatNumber(w.TotalPnL), formatNumber(w.RealizedPnL), formatNumber(w.UnrealizedPnL),
+			formatNumber(w.ROI), w.TradeCount, formatNumber(w.VolumeUSD))
+	}
+	return sb.String()
+}
+
+func formatMarkdown(out *domain.AgentOutput) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### Top wallets for %s\n\n", markdownCell(tokenLabel(out)))
+	fmt.Fprintf(&sb, "Current price: $%s\n\n", formatNumber(out.CurrentPrice))
+	if len(out.TopWallets) == 0 {
+		sb.WriteString(markdownCell(emptyMessage(out)))
+		return sb.String()
+	}
+
+	sb.WriteString("| # | Wallet | Total PnL (USD) | Realized PnL (USD) | Unrealized PnL (USD) | ROI (%) | Trades | Volume (USD) |\n")
+	sb.WriteString("|---:|---|---:|---:|---:|---:|---:|---:|\n")
+	for i, w := range out.TopWallets {
+		fmt.Fprintf(&sb, "| %d | `%s` | %s | %s | %s | %s | %d | %s |\n",
+			i+1, markdownCell(w.Address),
+			formatNumber(w.TotalPnL), formatNumber(w.RealizedPnL), formatNumber(w.UnrealizedPnL),
+			formatNumber(w.ROI), w.TradeCount, formatNumber(w.VolumeUSD))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func formatCSV(out *domain.AgentOutput) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(csvHeader); err != nil {
+		return "", err
+	}
+	for i, wallet := range out.TopWallets {
+		if err := w.Write(walletRecord(i+1, wallet)); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", fmt.Errorf("failed to write csv: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// walletRecord renders a ranked wallet as a row of csvHeader columns
+func walletRecord(rank int, wallet domain.WalletPnL) []string {
+	return []string{
+		strconv.Itoa(rank),
+		wallet.Address,
+		formatNumber(wallet.TotalPnL),
+		formatNumber(wallet.RealizedPnL),
+		formatNumber(wallet.UnrealizedPnL),
+		formatNumber(wallet.ROI),
+		formatNumber(wallet.TotalBought),
+		formatNumber(wallet.TotalSold),
+		formatNumber(wallet.CurrentBalance),
+		formatNumber(wallet.AverageBuyPrice),
+		formatNumber(wallet.AverageSellPrice),
+		strconv.Itoa(wallet.TradeCount),
+		formatNumber(wallet.VolumeUSD),
+	}
+}
+
+// FormatBatchOutput renders a multi-token result in the given format. Text
+// and Markdown render one section per token; CSV prefixes each row with the
+// token address and appends an error column for tokens that failed.
+func FormatBatchOutput(out *domain.BatchOutput, format string) (string, error) {
+	format, err := ParseOutputFormat(format)
+	if err != nil {
+		return "", err
+	}
+
+	switch format {
+	case domain.FormatText, domain.FormatMarkdown:
+		sections := make([]string, len(out.Results))
+		for i, r := range out.Results {
+			switch {
+			case r.Error != "" && format == domain.FormatMarkdown:
+				sections[i] = fmt.Sprintf("### %s\n\nError: %s", markdownCell(r.TokenAddress), markdownCell(r.Error))
+			case r.Error != "":
+				sections[i] = fmt.Sprintf("%s: error: %s", r.TokenAddress, r.Error)
+			case format == domain.FormatMarkdown:
+				sections[i] = formatMarkdown(labeled(r))
+			default:
+				sections[i] = formatText(labeled(r))
+			}
+		}
+		return strings.Join(sections, "\n\n"), nil
+	case domain.FormatCSV:
+		return formatBatchCSV(out)
+	default:
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal result: %w", err)
+		}
+		return string(data), nil
+	}
+}
+
+// labeled returns a token result whose heading also names its address, so
+// tokens sharing a symbol can be told apart
+func labeled(r domain.TokenResult) *domain.AgentOutput {
+	out := *r.Result
+	if out.TokenSymbol != "" {
+		out.TokenSymbol = fmt.Sprintf("%s (%s)", out.TokenSymbol, r.TokenAddress)
+	} else {
+		out.TokenSymbol = r.TokenAddress
+	}
+	return &out
+}
+
+func formatBatchCSV(out *domain.BatchOutput) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	header := append(append([]string{"token_address"}, csvHeader...), "error")
+	if err := w.Write(header); err != nil {
+		return "", err
+	}
+	for _, r := range out.Results {
+		if r.Error != "" {
+			record := make([]string, len(header))
+			record[0], record[len(record)-1] = r.TokenAddress, r.Error
+			if err := w.Write(record); err != nil {
+				return "", err
+			}
+			continue
+		}
+		for i, wallet := range r.Result.TopWallets {
+			record := append(append([]string{r.TokenAddress}, walletRecord(i+1, wallet)...), "")
+			if err := w.Write(record); err != nil {
+				return "", err
+			}
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", fmt.Errorf("failed to write csv: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// formatNumber renders f without exponent or locale separators so it parses
+// back exactly and spreadsheets treat it as a number
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// markdownCell escapes text placed inside a Markdown table or heading
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "\r", " ")
+	s = strings.ReplaceAll(s, "\n", " ")
+	s = strings.ReplaceAll(s, "`", "'")
+	return strings.ReplaceAll(s, "|", "\\|")
+}
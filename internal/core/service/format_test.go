@@ -0,0 +1,243 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/core/domain"
+)
+
+func sampleOutput() *domain.AgentOutput {
+	return &domain.AgentOutput{
+		TokenSymbol:  "PEPE",
+		CurrentPrice: 0.0000012345,
+		Token:        &domain.TokenMetadata{Address: "0xtoken", Symbol: "PEPE", Decimals: 18},
+		TopWallets: []domain.WalletPnL{
+			{
+				Address:         "0xAbC0000000000000000000000000000000000001",
+				TotalBought:     123456789.123,
+				TotalPnL:        1234567.891,
+				RealizedPnL:     1000000,
+				UnrealizedPnL:   234567.891,
+				ROI:             -12.5,
+				AverageBuyPrice: 0.000001,
+				TradeCount:      7,
+				VolumeUSD:       1e21, // would print in exponent form with %v
+			},
+			{
+				// Not a real address, but the formatters must not trust their input
+				Address:    "wallet,with \"quotes\" | pipes\nand newline",
+				TotalPnL:   42,
+				TradeCount: 1,
+			},
+		},
+	}
+}
+
+func TestFormatOutput_JSONRoundTrip(t *testing.T) {
+	out := sampleOutput()
+	for _, format := range []string{"", "json", "JSON"} {
+		s, err := FormatOutput(out, format)
+		if err != nil {
+			t.Fatalf("FormatOutput(%q) error = %v", format, err)
+		}
+		var decoded domain.AgentOutput
+		if err := json.Unmarshal([]byte(s), &decoded); err != nil {
+			t.Fatalf("FormatOutput(%q) is not valid JSON: %v", format, err)
+		}
+		if !reflect.DeepEqual(&decoded, out) {
+			t.Errorf("JSON round trip = %+v, want %+v", decoded, out)
+		}
+	}
+}
+
+func TestFormatOutput_CSVRoundTrip(t *testing.T) {
+	out := sampleOutput()
+	s, err := FormatOutput(out, "csv")
+	if err != nil {
+		t.Fatalf("FormatOutput(csv) error = %v", err)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(s)).ReadAll()
+	if err != nil {
+		t.Fatalf("CSV does not parse: %v", err)
+	}
+	if len(records) != len(out.TopWallets)+1 {
+		t.Fatalf("got %d records, want header plus %d rows", len(records), len(out.TopWallets))
+	}
+	if !reflect.DeepEqual(records[0], csvHeader) {
+		t.Errorf("header = %v, want %v", records[0], csvHeader)
+	}
+
+	for i, w := range out.TopWallets {
+		row := records[i+1]
+		if len(row) != len(csvHeader) {
+			t.Fatalf("row %d has %d fields, want %d", i+1, len(row), len(csvHeader))
+		}
+		if row[1] != w.Address {
+			t.Errorf("row %d address = %q, want %q", i+1, row[1], w.Address)
+		}
+		numbers := map[int]float64{2: w.TotalPnL, 3: w.RealizedPnL, 4: w.UnrealizedPnL, 5: w.ROI, 6: w.TotalBought, 9: w.AverageBuyPrice, 12: w.VolumeUSD}
+		for col, want := range numbers {
+			got, err := strconv.ParseFloat(row[col], 64)
+			if err != nil || got != want {
+				t.Errorf("row %d %s = %q, want %v", i+1, csvHeader[col], row[col], want)
+			}
+			if strings.ContainsAny(row[col], "eE") {
+				t.Errorf("row %d %s = %q uses exponent notation", i+1, csvHeader[col], row[col])
+			}
+		}
+		if row[11] != strconv.Itoa(w.TradeCount) {
+			t.Errorf("row %d trade_count = %q, want %d", i+1, row[11], w.TradeCount)
+		}
+	}
+}
+
+func TestFormatOutput_MarkdownRoundTrip(t *testing.T) {
+	out := sampleOutput()
+	s, err := FormatOutput(out, "markdown")
+	if err != nil {
+		t.Fatalf("FormatOutput(markdown) error = %v", err)
+	}
+
+	var rows [][]string
+	for _, line := range strings.Split(s, "\n") {
+		if !strings.HasPrefix(line, "| ") {
+			continue
+		}
+		// Split on pipes that are not escaped
+		var cells []string
+		var cell strings.Builder
+		for i := 1; i < len(line); i++ {
+			switch {
+			case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+				cell.WriteByte('|')
+				i++
+			case line[i] == '|':
+				cells = append(cells, strings.TrimSpace(cell.String()))
+				cell.Reset()
+			default:
+				cell.WriteByte(line[i])
+			}
+		}
+		rows = append(rows, cells)
+	}
+
+	if len(rows) != len(out.TopWallets)+1 {
+		t.Fatalf("got %d table rows, want header plus %d:\n%s", len(rows), len(out.TopWallets), s)
+	}
+	for i, w := range out.TopWallets {
+		row := rows[i+1]
+		if len(row) != len(rows[0]) {
+			t.Fatalf("row %d has %d cells, want %d:\n%s", i+1, len(row), len(rows[0]), s)
+		}
+		wantAddr := strings.ReplaceAll(w.Address, "\n", " ")
+		if strings.Trim(row[1], "`") != wantAddr {
+			t.Errorf("row %d wallet = %q, want %q", i+1, row[1], wantAddr)
+		}
+		if got, err := strconv.ParseFloat(row[2], 64); err != nil || got != w.TotalPnL {
+			t.Errorf("row %d total PnL = %q, want %v", i+1, row[2], w.TotalPnL)
+		}
+	}
+}
+
+func TestFormatOutput_Text(t *testing.T) {
+	out := sampleOutput()
+	s, err := FormatOutput(out, "text")
+	if err != nil {
+		t.Fatalf("FormatOutput(text) error = %v", err)
+	}
+	for _, want := range []string{"Top 2 wallets for PEPE", "1. " + out.TopWallets[0].Address, "Total PnL: $1234567.891", "ROI: -12.5%", "Trades: 7"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("text output missing %q:\n%s", want, s)
+		}
+	}
+
+	empty := &domain.AgentOutput{TokenSymbol: "PEPE", Message: "no wallets met criteria (at least 5 trades)"}
+	for _, format := range []string{"text", "markdown"} {
+		s, _ := FormatOutput(empty, format)
+		if !strings.Contains(s, "no wallets met criteria") {
+			t.Errorf("%s output for an empty result = %q, want the message", format, s)
+		}
+	}
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	for input, want := range map[string]string{"": "json", "Text": "text", "md": "markdown", " csv ": "csv"} {
+		if got, err := ParseOutputFormat(input); err != nil || got != want {
+			t.Errorf("ParseOutputFormat(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseOutputFormat("xml"); err == nil {
+		t.Error("ParseOutputFormat(\"xml\") should fail")
+	}
+}
+
+func sampleBatch() *domain.BatchOutput {
+	return &domain.BatchOutput{Results: []domain.TokenResult{
+		{TokenAddress: testTokenA, Result: sampleOutput()},
+		{TokenAddress: "0xbad", Error: `invalid ethereum token address "0xbad"`},
+	}}
+}
+
+func TestFormatBatchOutput_CSVRoundTrip(t *testing.T) {
+	out, err := FormatBatchOutput(sampleBatch(), domain.FormatCSV)
+	if err != nil {
+		t.Fatalf("FormatBatchOutput() error = %v", err)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatalf("CSV does not parse back: %v\n%s", err, out)
+	}
+	wallets := sampleOutput().TopWallets
+	if len(records) != 1+len(wallets)+1 {
+		t.Fatalf("got %d records, want %d", len(records), len(wallets)+2)
+	}
+	if records[0][0] != "token_address" || records[0][len(records[0])-1] != "error" {
+		t.Errorf("header = %v", records[0])
+	}
+	for i, w := range wallets {
+		row := records[i+1]
+		if row[0] != testTokenA || row[2] != w.Address || row[len(row)-1] != "" {
+			t.Errorf("row %d = %q, want token %s and wallet %q", i, row, testTokenA, w.Address)
+		}
+	}
+	failed := records[len(records)-1]
+	if failed[0] != "0xbad" || failed[1] != "" || !strings.Contains(failed[len(failed)-1], "invalid ethereum token address") {
+		t.Errorf("failed token row = %q", failed)
+	}
+}
+
+func TestFormatBatchOutput_Sections(t *testing.T) {
+	batch := sampleBatch()
+
+	data, err := FormatBatchOutput(batch, "")
+	if err != nil {
+		t.Fatalf("FormatBatchOutput() error = %v", err)
+	}
+	var decoded domain.BatchOutput
+	if err := json.Unmarshal([]byte(data), &decoded); err != nil || len(decoded.Results) != 2 {
+		t.Fatalf("JSON does not round-trip: %v\n%s", err, data)
+	}
+	if decoded.Results[1].Error != batch.Results[1].Error || decoded.Results[0].Result.TokenSymbol != "PEPE" {
+		t.Errorf("decoded = %+v", decoded)
+	}
+
+	for _, format := range []string{domain.FormatText, domain.FormatMarkdown} {
+		out, err := FormatBatchOutput(batch, format)
+		if err != nil {
+			t.Fatalf("FormatBatchOutput(%s) error = %v", format, err)
+		}
+		if !strings.Contains(out, "PEPE ("+testTokenA+")") || !strings.Contains(out, "0xbad") {
+			t.Errorf("%s output does not label each token:\n%s", format, out)
+		}
+	}
+	if batch.Results[0].Result.TokenSymbol != "PEPE" {
+		t.Error("formatting must not modify the result")
+	}
+}
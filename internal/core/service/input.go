@@ -0,0 +1,146 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/core/domain"
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/command"
+)
+
+// defaultCommandLimit is the number of wallets returned by the command form
+// when no limit is given
+const defaultCommandLimit = 10
+
+var (
+	evmAddressPattern    = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+	solanaAddressPattern = regexp.MustCompile(`^[1-9A-HJ-NP-Za-km-z]{32,44}$`)
+)
+
+// commandParser accepts the flags of the command form; each mirrors an
+// AgentInput field
+var commandParser = &command.Parser{Flags: []string{
+	"chain", "token", "limit", "format", "from", "to", "max-swaps", "min-trades", "min-volume",
+}}
+
+// commandUsage describes the command form in errors
+const commandUsage = "expected JSON or 'chain address[,address...] [limit] [format]' with optional " +
+	"--chain, --token, --limit, --format, --from, --to, --max-swaps, --min-trades and --min-volume flags"
+
+// ParseInput parses a task as a JSON AgentInput or, failing that, as the
+// command form "chain address[,address...] [limit] [format]". Arguments may
+// be quoted and given as --name=value flags instead, which take precedence
+// over positional ones. A comma-separated address list fills
+// TokenAddresses; a single address fills TokenAddress as before.
+func ParseInput(task string) (domain.AgentInput, error) {
+	task = strings.TrimPrefix(strings.TrimSpace(task), "/")
+
+	var input domain.AgentInput
+	if err := json.Unmarshal([]byte(task), &input); err != nil {
+		if input, err = parseCommand(task); err != nil {
+			return input, err
+		}
+	}
+
+	if input.Chain == "" || len(input.Tokens()) == 0 {
+		return input, fmt.Errorf("missing chain or token address")
+	}
+	return input, nil
+}
+
+// parseCommand parses the command form of an AgentInput
+func parseCommand(task string) (domain.AgentInput, error) {
+	var input domain.AgentInput
+	cmd, err := commandParser.Parse(task)
+	if err != nil {
+		return input, fmt.Errorf("invalid input format: %w", err)
+	}
+	if len(cmd.Args) > 4 {
+		return input, fmt.Errorf("invalid input format: unexpected argument %q, %s", cmd.Args[4], commandUsage)
+	}
+
+	value := func(flag string, position int) string {
+		if v, ok := cmd.Flag(flag); ok {
+			return v
+		}
+		return cmd.Arg(position)
+	}
+
+	input.Chain = value("chain", 0)
+	if tokens := value("token", 1); strings.Contains(tokens, ",") {
+		input.TokenAddresses = strings.Split(tokens, ",")
+	} else {
+		input.TokenAddress = tokens
+	}
+	if input.Chain == "" || input.TokenAddress == "" && len(input.TokenAddresses) == 0 {
+		return input, fmt.Errorf("invalid input format: %s", commandUsage)
+	}
+	input.Format = value("format", 3)
+
+	input.Limit = defaultCommandLimit
+	if limit := value("limit", 2); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			return input, fmt.Errorf("limit must be a positive integer, got %q", limit)
+		}
+		input.Limit = n
+	}
+
+	times := []struct {
+		flag   string
+		target *time.Time
+	}{{"from", &input.FromTime}, {"to", &input.ToTime}}
+	for _, tf := range times {
+		flag, target := tf.flag, tf.target
+		if v, ok := cmd.Flag(flag); ok {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return input, fmt.Errorf("--%s must be an RFC 3339 time, got %q", flag, v)
+			}
+			*target = t
+		}
+	}
+	counts := []struct {
+		flag   string
+		target *int
+	}{{"max-swaps", &input.MaxSwaps}, {"min-trades", &input.MinTrades}}
+	for _, cf := range counts {
+		flag, target := cf.flag, cf.target
+		if v, ok := cmd.Flag(flag); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return input, fmt.Errorf("--%s must be a non-negative integer, got %q", flag, v)
+			}
+			*target = n
+		}
+	}
+	if v, ok := cmd.Flag("min-volume"); ok {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 {
+			return input, fmt.Errorf("--min-volume must be a non-negative number of USD, got %q", v)
+		}
+		input.MinVolume = f
+	}
+	return input, nil
+}
+
+// validateTokenAddress checks that address is well formed for chain so a
+// malformed entry in a batch is reported without querying any provider.
+// Chains without a known address format are not checked.
+func validateTokenAddress(chain, address string) error {
+	switch canonicalChain(chain) {
+	case "ethereum":
+		if !evmAddressPattern.MatchString(address) {
+			return fmt.Errorf("invalid ethereum token address %q", address)
+		}
+	case "solana":
+		if !solanaAddressPattern.MatchString(address) {
+			return fmt.Errorf("invalid solana token address %q", address)
+		}
+	}
+	return nil
+}
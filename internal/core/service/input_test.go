@@ -0,0 +1,144 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/core/domain"
+)
+
+const (
+	testTokenA = "0x6B175474E89094C44Da98b954EedeAC495271d0F"
+	testTokenB = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+)
+
+func TestParseInput(t *testing.T) {
+	tests := []struct {
+		name       string
+		task       string
+		wantTokens []string
+		wantBatch  bool
+		wantLimit  int
+		wantErr    bool
+	}{
+		{"json single", `{"chain":"ethereum","tokenAddress":"` + testTokenA + `","limit":5}`, []string{testTokenA}, false, 5, false},
+		{"json list", `{"chain":"ethereum","tokenAddresses":["` + testTokenA + `","` + testTokenB + `"],"limit":5}`, []string{testTokenA, testTokenB}, true, 5, false},
+		{"json both", `{"chain":"ethereum","tokenAddress":"` + testTokenA + `","tokenAddresses":["` + testTokenB + `","` + testTokenA + `"]}`, []string{testTokenA, testTokenB}, true, 0, false},
+		{"command single", "/ethereum " + testTokenA + " 3", []string{testTokenA}, false, 3, false},
+		{"command list", "ethereum " + testTokenA + "," + testTokenB, []string{testTokenA, testTokenB}, true, 10, false},
+		{"command list with blanks", "ethereum " + testTokenA + ",," + testTokenB + ",", []string{testTokenA, testTokenB}, true, 10, false},
+		{"command list with invalid entry", "ethereum " + testTokenA + ",nope", []string{testTokenA, "nope"}, true, 10, false},
+		{"json without token", `{"chain":"ethereum"}`, nil, false, 0, true},
+		{"json with empty list", `{"chain":"ethereum","tokenAddresses":[" "]}`, nil, true, 0, true},
+		{"too few words", "ethereum", nil, false, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, err := ParseInput(tt.task)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseInput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := input.Tokens(); !reflect.DeepEqual(got, tt.wantTokens) {
+				t.Errorf("Tokens() = %v, want %v", got, tt.wantTokens)
+			}
+			if input.IsBatch() != tt.wantBatch {
+				t.Errorf("IsBatch() = %v, want %v", input.IsBatch(), tt.wantBatch)
+			}
+			if input.Limit != tt.wantLimit {
+				t.Errorf("Limit = %d, want %d", input.Limit, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestValidateTokenAddress(t *testing.T) {
+	tests := []struct {
+		chain, address string
+		valid          bool
+	}{
+		{"ethereum", testTokenA, true},
+		{"eth", "0x6b175474e89094c44da98b954eedeac495271d0f", true},
+		{"ethereum", "0x6B175474E89094C44Da98b954EedeAC495271d0", false},
+		{"ethereum", "6B175474E89094C44Da98b954EedeAC495271d0F00", false},
+		{"solana", "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", true},
+		{"solana", "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt10", false}, // 0 is not base58
+		{"solana", testTokenA, false},
+		{"base", "anything", true},
+	}
+	for _, tt := range tests {
+		if err := validateTokenAddress(tt.chain, tt.address); (err == nil) != tt.valid {
+			t.Errorf("validateTokenAddress(%q, %q) error = %v, want valid %v", tt.chain, tt.address, err, tt.valid)
+		}
+	}
+}
+
+func TestAgentInput_TokensKeepsSingleAddress(t *testing.T) {
+	input := domain.AgentInput{Chain: "ethereum", TokenAddress: " " + testTokenA + " "}
+	if got := input.Tokens(); !reflect.DeepEqual(got, []string{testTokenA}) || input.IsBatch() {
+		t.Errorf("Tokens() = %v, IsBatch() = %v, want a single token", got, input.IsBatch())
+	}
+}
+
+func TestParseInput_CommandFlags(t *testing.T) {
+	tests := []struct {
+		name   string
+		task   string
+		want   domain.AgentInput
+		errMsg string // empty = valid
+	}{
+		{
+			name: "flags only",
+			task: "--chain=ethereum --token=" + testTokenA + " --limit=3 --format=csv",
+			want: domain.AgentInput{Chain: "ethereum", TokenAddress: testTokenA, Limit: 3, Format: "csv"},
+		},
+		{
+			name: "flags override positional",
+			task: "solana " + testTokenA + " 5 --chain ethereum --limit=2",
+			want: domain.AgentInput{Chain: "ethereum", TokenAddress: testTokenA, Limit: 2},
+		},
+		{
+			name: "quoted token list with spaces",
+			task: `ethereum "` + testTokenA + `, ` + testTokenB + `" --format "md"`,
+			want: domain.AgentInput{Chain: "ethereum", TokenAddresses: []string{testTokenA, " " + testTokenB}, Limit: 10, Format: "md"},
+		},
+		{
+			name: "window and thresholds",
+			task: "ethereum " + testTokenA + " --from=2024-01-01T00:00:00Z --max-swaps=50 --min-trades=2 --min-volume=1000.5",
+			want: domain.AgentInput{
+				Chain: "ethereum", TokenAddress: testTokenA, Limit: 10,
+				FromTime: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), MaxSwaps: 50, MinTrades: 2, MinVolume: 1000.5,
+			},
+		},
+		{name: "unknown flag", task: "ethereum " + testTokenA + " --limt=5", errMsg: "did you mean --limit?"},
+		{name: "unterminated quote", task: `ethereum "` + testTokenA, errMsg: "unterminated"},
+		{name: "bad limit", task: "ethereum " + testTokenA + " five", errMsg: `limit must be a positive integer, got "five"`},
+		{name: "bad time", task: "ethereum " + testTokenA + " --to=yesterday", errMsg: "--to must be an RFC 3339 time"},
+		{name: "negative count", task: "ethereum " + testTokenA + " --min-trades=-1", errMsg: "--min-trades must be a non-negative integer"},
+		{name: "extra argument", task: "ethereum " + testTokenA + " 5 csv extra", errMsg: `unexpected argument "extra"`},
+		{name: "missing token", task: "--chain=ethereum", errMsg: "expected JSON or"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseInput(tt.task)
+			if tt.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+					t.Fatalf("ParseInput() error = %v, want error containing %q", err, tt.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseInput() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseInput() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
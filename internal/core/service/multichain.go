@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/core/domain"
+)
+
+// DefaultChainConcurrency is how many chains FetchSwapsAcrossChains queries
+// at once when SetChainConcurrency was not called
+const DefaultChainConcurrency = 4
+
+// CrossChainSwaps holds a token's swaps fetched from several chains.
+type CrossChainSwaps struct {
+	// Holders merges every chain that succeeded. Hex wallet addresses are
+	// lowercased so the same wallet on several EVM chains is ranked once.
+	Holders map[string][]domain.Trade
+
+	// Sources attributes the swaps to each chain's provider in the order the
+	// chains were requested; chains that failed have a failed status
+	Sources []domain.SourceInfo
+}
+
+// chainSwaps is the outcome of fetching one chain
+type chainSwaps struct {
+	holders map[string][]domain.Trade
+	source  domain.SourceInfo
+	err     error
+}
+
+// SetChainConcurrency bounds how many chains are fetched at once, or restores
+// DefaultChainConcurrency when n is not positive.
+func (s *AgentService) SetChainConcurrency(n int) {
+	s.chainConcurrency = n
+}
+
+// FetchSwapsAcrossChains fetches a token's swaps on every chain concurrently,
+// at most SetChainConcurrency chains at a time, and merges them. Raw amounts
+// are scaled by each chain's own token decimals. A chain that is unsupported
+// or fails is reported in Sources without affecting the others; an error is
+// returned only when ctx ends or no chain succeeds.
+func (s *AgentService) FetchSwapsAcrossChains(ctx context.Context, tokenAddress string, chains []string, window domain.TradeWindow) (*CrossChainSwaps, error) {
+	chains = canonicalChains(chains)
+	if len(chains) == 0 {
+		return nil, fmt.Errorf("no chains given")
+	}
+
+	workers := s.chainConcurrency
+	if workers <= 0 {
+		workers = DefaultChainConcurrency
+	}
+	if workers > len(chains) {
+		workers = len(chains)
+	}
+
+	results := make([]chainSwaps, len(chains))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = s.fetchChainSwaps(ctx, chains[i], tokenAddress, window)
+			}
+		}()
+	}
+
+feed:
+	for i := range chains {
+		select {
+		case next <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	merged := &CrossChainSwaps{
+		Holders: make(map[string][]domain.Trade),
+		Sources: make([]domain.SourceInfo, 0, len(chains)),
+	}
+	var errs []error
+	for i, result := range results {
+		merged.Sources = append(merged.Sources, result.source)
+		if result.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", chains[i], result.err))
+			continue
+		}
+		chain := chains[i]
+		for addr, trades := range result.holders {
+			// The blocklist is per chain, so apply it before wallets merge
+			if s.blocklist.Contains(chain, addr) {
+				continue
+			}
+			key := normalizeAddress(chain, addr)
+			merged.Holders[key] = append(merged.Holders[key], trades...)
+		}
+	}
+	if len(errs) == len(chains) {
+		return nil, fmt.Errorf("failed to get trades on any chain: %w", errors.Join(errs...))
+	}
+	return merged, nil
+}
+
+// fetchChainSwaps fetches and scales one chain's swaps for
+// FetchSwapsAcrossChains
+func (s *AgentService) fetchChainSwaps(ctx context.Context, chain, tokenAddress string, window domain.TradeWindow) chainSwaps {
+	failed := func(name string, err error) chainSwaps {
+		return chainSwaps{err: err, source: domain.SourceInfo{
+			Name:   name,
+			Role:   domain.SourceRoleTrades,
+			Status: domain.SourceStatusFailed,
+			Error:  err.Error(),
+			Chain:  chain,
+		}}
+	}
+
+	var chainService domain.ChainService
+	for _, cs := range s.chainServices {
+		if cs.IsSupported(chain) {
+			chainService = cs
+			break
+		}
+	}
+	if chainService == nil {
+		return failed("unknown", fmt.Errorf("chain %s not supported", chain))
+	}
+
+	var holdersMap map[string][]domain.Trade
+	var err error
+	if windowed, ok := chainService.(domain.WindowedChainService); ok && !window.IsZero() {
+		holdersMap, err = windowed.GetHoldersWithTradesInWindow(ctx, tokenAddress, window)
+	} else {
+		holdersMap, err = chainService.GetHoldersWithTrades(ctx, tokenAddress)
+	}
+	if err != nil {
+		return failed(sourceName(chainService), err)
+	}
+
+	// The same token can have different decimals on each chain
+	meta, err := chainService.GetTokenMetadata(ctx, tokenAddress)
+	if err != nil {
+		meta = nil
+	}
+	scaleTrades(holdersMap, meta)
+
+	source := tradeSource(chainService, holdersMap)
+	source.Chain = chain
+	return chainSwaps{holders: holdersMap, source: source}
+}
+
+// canonicalChains returns chains by their canonical names without blanks or
+// aliases of a chain already listed
+func canonicalChains(chains []string) []string {
+	var canonical []string
+	seen := make(map[string]bool)
+	for _, chain := range chains {
+		chain = canonicalChain(chain)
+		if chain == "" || seen[chain] {
+			continue
+		}
+		seen[chain] = true
+		canonical = append(canonical, chain)
+	}
+	return canonical
+}
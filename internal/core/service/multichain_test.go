@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/core/domain"
+)
+
+// networkChain is a fakeChain serving one named network. Every fetch is
+// recorded in the shared gate, which can hold fetches until released.
+type networkChain struct {
+	fakeChain
+	network string
+	err     error
+	gate    *chainGate
+}
+
+func (f *networkChain) IsSupported(chain string) bool { return chain == f.network }
+
+func (f *networkChain) SourceName() string { return f.network + "-rpc" }
+
+func (f *networkChain) GetHoldersWithTrades(ctx context.Context, tokenAddress string) (map[string][]domain.Trade, error) {
+	if f.gate != nil {
+		if err := f.gate.enter(ctx); err != nil {
+			return nil, err
+		}
+		defer f.gate.leave()
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.trades, nil
+}
+
+// chainGate tracks how many fetches run at once
+type chainGate struct {
+	release chan struct{} // closed to let held fetches finish; nil holds none
+	hold    time.Duration // how long each fetch takes when release is nil
+
+	mu      sync.Mutex
+	active  int
+	maxSeen int
+	calls   int
+	entered chan struct{}
+}
+
+func newChainGate(release chan struct{}) *chainGate {
+	return &chainGate{release: release, entered: make(chan struct{}, 16)}
+}
+
+func (g *chainGate) enter(ctx context.Context) error {
+	g.mu.Lock()
+	g.active++
+	g.calls++
+	if g.active > g.maxSeen {
+		g.maxSeen = g.active
+	}
+	g.mu.Unlock()
+	g.entered <- struct{}{}
+
+	var wait <-chan time.Time
+	if g.release == nil {
+		wait = time.After(g.hold)
+	}
+	select {
+	case <-g.release:
+	case <-wait:
+	case <-ctx.Done():
+		g.leave()
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (g *chainGate) leave() {
+	g.mu.Lock()
+	g.active--
+	g.mu.Unlock()
+}
+
+func TestFetchSwapsAcrossChains_FetchesConcurrently(t *testing.T) {
+	release := make(chan struct{})
+	gate := newChainGate(release)
+	var chains []domain.ChainService
+	for _, network := range []string{"ethereum", "base", "arbitrum"} {
+		chains = append(chains, &networkChain{network: network, gate: gate, fakeChain: fakeChain{
+			trades: map[string][]domain.Trade{"0x" + network: {{Type: "buy", Amount: 1, TxHash: "0x" + network}}},
+		}})
+	}
+	svc := NewAgentService(chains, &fakePrice{current: 1}, NewPnLCalculator())
+
+	done := make(chan *CrossChainSwaps, 1)
+	go func() {
+		swaps, err := svc.FetchSwapsAcrossChains(context.Background(), "0xtoken", []string{"ethereum", "base", "arbitrum"}, domain.TradeWindow{})
+		if err != nil {
+			t.Errorf("FetchSwapsAcrossChains() error = %v", err)
+		}
+		done <- swaps
+	}()
+
+	// Every chain is fetching before any of them is allowed to finish
+	for i := 0; i < 3; i++ {
+		select {
+		case <-gate.entered:
+		case <-time.After(time.Second):
+			t.Fatalf("only %d of 3 chains were fetched concurrently", i)
+		}
+	}
+	close(release)
+
+	swaps := <-done
+	if swaps == nil {
+		t.Fatal("FetchSwapsAcrossChains() returned no swaps")
+	}
+	if len(swaps.Holders) != 3 {
+		t.Errorf("merged holders = %v, want one wallet per chain", swaps.Holders)
+	}
+	for i, network := range []string{"ethereum", "base", "arbitrum"} {
+		source := swaps.Sources[i]
+		if source.Chain != network || source.Name != network+"-rpc" || source.Status != domain.SourceStatusOK || source.Transactions != 1 {
+			t.Errorf("source %d = %+v, want %s in request order", i, source, network)
+		}
+	}
+}
+
+func TestFetchSwapsAcrossChains_BoundsConcurrency(t *testing.T) {
+	gate := newChainGate(nil)
+	gate.hold = 20 * time.Millisecond
+	networks := []string{"ethereum", "base", "arbitrum", "optimism", "polygon"}
+	var chains []domain.ChainService
+	for _, network := range networks {
+		chains = append(chains, &networkChain{network: network, gate: gate})
+	}
+	svc := NewAgentService(chains, &fakePrice{current: 1}, NewPnLCalculator())
+	svc.SetChainConcurrency(2)
+
+	if _, err := svc.FetchSwapsAcrossChains(context.Background(), "0xtoken", networks, domain.TradeWindow{}); err != nil {
+		t.Fatalf("FetchSwapsAcrossChains() error = %v", err)
+	}
+	if gate.calls != len(networks) {
+		t.Errorf("fetched %d chains, want %d", gate.calls, len(networks))
+	}
+	if gate.maxSeen != 2 {
+		t.Errorf("at most %d chains fetched at once, want 2", gate.maxSeen)
+	}
+}
+
+func TestFetchSwapsAcrossChains_IsolatesFailingChains(t *testing.T) {
+	chains := []domain.ChainService{
+		&networkChain{network: "ethereum", fakeChain: fakeChain{
+			trades: map[string][]domain.Trade{"0xwallet": {{Type: "buy", Amount: 1}}},
+		}},
+		&networkChain{network: "base", err: errors.New("rate limited")},
+	}
+	svc := NewAgentService(chains, &fakePrice{current: 1}, NewPnLCalculator())
+
+	swaps, err := svc.FetchSwapsAcrossChains(context.Background(), "0xtoken", []string{"ethereum", "base", "fantom"}, domain.TradeWindow{})
+	if err != nil {
+		t.Fatalf("FetchSwapsAcrossChains() error = %v", err)
+	}
+	if len(swaps.Holders["0xwallet"]) != 1 {
+		t.Errorf("merged holders = %v, want the ethereum wallet", swaps.Holders)
+	}
+	if got := swaps.Sources[1]; got.Chain != "base" || got.Status != domain.SourceStatusFailed || got.Error != "rate limited" {
+		t.Errorf("base source = %+v, want the failure", got)
+	}
+	if got := swaps.Sources[2]; got.Chain != "fantom" || got.Status != domain.SourceStatusFailed || got.Error == "" {
+		t.Errorf("fantom source = %+v, want an unsupported chain", got)
+	}
+
+	_, err = svc.FetchSwapsAcrossChains(context.Background(), "0xtoken", []string{"base", "fantom"}, domain.TradeWindow{})
+	if err == nil {
+		t.Error("FetchSwapsAcrossChains() succeeded with every chain failing")
+	}
+}
+
+func TestFetchSwapsAcrossChains_StopsOnCancel(t *testing.T) {
+	gate := newChainGate(make(chan struct{}))
+	var chains []domain.ChainService
+	for _, network := range []string{"ethereum", "base"} {
+		chains = append(chains, &networkChain{network: network, gate: gate})
+	}
+	svc := NewAgentService(chains, &fakePrice{current: 1}, NewPnLCalculator())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-gate.entered
+		cancel()
+	}()
+	if _, err := svc.FetchSwapsAcrossChains(ctx, "0xtoken", []string{"ethereum", "base"}, domain.TradeWindow{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("FetchSwapsAcrossChains() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestAnalyzeToken_MergesSwapsAcrossChains(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	// The token has 6 decimals on ethereum and 18 on base; the same wallet
+	// bought on both
+	ethereum := &networkChain{network: "ethereum", fakeChain: fakeChain{
+		meta: &domain.TokenMetadata{Symbol: "TEST", Decimals: 6},
+		trades: map[string][]domain.Trade{
+			"0xAbC0000000000000000000000000000000000001": {{Type: "buy", RawAmount: big.NewInt(2_000_000), PriceUSD: 1, Timestamp: ts, TxHash: "0x1"}},
+		},
+	}}
+	base := &networkChain{network: "base", fakeChain: fakeChain{
+		meta: &domain.TokenMetadata{Symbol: "TEST", Decimals: 18},
+		trades: map[string][]domain.Trade{
+			"0xabc0000000000000000000000000000000000001": {{Type: "buy", RawAmount: new(big.Int).Mul(big.NewInt(3), big.NewInt(1e18)), PriceUSD: 1, Timestamp: ts, TxHash: "0x2"}},
+			"0xdef0000000000000000000000000000000000002": {{Type: "buy", RawAmount: big.NewInt(1e18), PriceUSD: 1, Timestamp: ts, TxHash: "0x3"}},
+		},
+	}}
+	svc := NewAgentService([]domain.ChainService{ethereum, base}, &fakePrice{current: 2}, NewPnLCalculator())
+
+	out, err := svc.AnalyzeToken(context.Background(), domain.AgentInput{
+		Chain:        "ethereum",
+		Chains:       []string{"base"},
+		TokenAddress: "0xtoken",
+		Limit:        10,
+	}, nil)
+	if err != nil {
+		t.Fatalf("AnalyzeToken failed: %v", err)
+	}
+
+	if len(out.TopWallets) != 2 {
+		t.Fatalf("ranked %d wallets, want 2", len(out.TopWallets))
+	}
+	top := out.TopWallets[0]
+	if top.Address != "0xabc0000000000000000000000000000000000001" || top.TotalBought != 5 {
+		t.Errorf("top wallet = %s with %v bought, want 5 tokens across both chains", top.Address, top.TotalBought)
+	}
+
+	var chains []string
+	for _, source := range out.DataSources {
+		if source.Role == domain.SourceRoleTrades {
+			chains = append(chains, source.Chain)
+		}
+	}
+	if len(chains) != 2 || chains[0] != "ethereum" || chains[1] != "base" {
+		t.Errorf("trade sources cover chains %v, want [ethereum base]", chains)
+	}
+}
+
+func TestResultCacheKey_IncludesChains(t *testing.T) {
+	single := domain.AgentInput{Chain: "ethereum", TokenAddress: "0xtoken", Limit: 10}
+	multi := single
+	multi.Chains = []string{"base"}
+	alias := single
+	alias.Chains = []string{"eth"}
+
+	if resultCacheKey(single) == resultCacheKey(multi) {
+		t.Error("a multi-chain analysis shares the cache key of a single-chain one")
+	}
+	if resultCacheKey(single) != resultCacheKey(alias) {
+		t.Error("repeating the chain under an alias changed the cache key")
+	}
+}
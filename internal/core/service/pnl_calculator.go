@@ -1,6 +1,7 @@
 package service
 
 import (
+	"math/big"
 	"sort"
 
 	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/core/domain"
@@ -13,77 +14,91 @@ func NewPnLCalculator() domain.PnLCalculator {
 }
 
 // Calculate computes PnL metrics for a set of trades.
+// Token amounts are accumulated as big.Float, scaling raw on-chain amounts by
+// the token's decimals, so large balances do not lose precision before the
+// final conversion to float64.
 func (p *PnLCalculator) Calculate(trades []domain.Trade, currentPrice float64) *domain.WalletPnL {
-	var totalBought, totalSold float64
-	var totalCost, totalRevenue float64
-	var realizedPnL float64
+	newFloat := func() *big.Float { return new(big.Float).SetPrec(domain.AmountPrecision) }
+	totalBought, totalSold := newFloat(), newFloat()
+	totalCost, totalRevenue := newFloat(), newFloat()
+	tradeCount := 0
 
 	// Sort trades by date (ascending) to process sequentially if needed
-	// For this simple aggregation, order might not strictly matter for averages, 
+	// For this simple aggregation, order might not strictly matter for averages,
 	// but good practice.
 	sort.Slice(trades, func(i, j int) bool {
 		return trades[i].Timestamp.Before(trades[j].Timestamp)
 	})
 
 	for _, t := range trades {
+		amount := t.TokenAmount()
+		value := newFloat().Mul(amount, newFloat().SetFloat64(t.PriceUSD))
 		if t.Type == "buy" {
-			totalBought += t.Amount
-			totalCost += t.Amount * t.PriceUSD
+			tradeCount++
+			totalBought.Add(totalBought, amount)
+			totalCost.Add(totalCost, value)
 		} else if t.Type == "sell" {
-			// Basic FIFO or Average Cost logic could differ. 
 			// Here we use Average Buy Price logic for PnL.
 			// Realized PnL on this sell = (SellPrice - AvgBuyPrice) * Amount
-			
-			// However, simple approach:
-			totalSold += t.Amount
-			totalRevenue += t.Amount * t.PriceUSD
+			tradeCount++
+			totalSold.Add(totalSold, amount)
+			totalRevenue.Add(totalRevenue, value)
 		}
 	}
 
-	currentBalance := totalBought - totalSold
+	currentBalance := newFloat().Sub(totalBought, totalSold)
 	// Sanity check for data inconsistencies
-	if currentBalance < 0 {
-		currentBalance = 0 
+	if currentBalance.Sign() < 0 {
+		currentBalance.SetInt64(0)
 	}
 
-	avgBuyPrice := 0.0
-	if totalBought > 0 {
-		avgBuyPrice = totalCost / totalBought
+	avgBuyPrice := newFloat()
+	if totalBought.Sign() > 0 {
+		avgBuyPrice.Quo(totalCost, totalBought)
 	}
 
-	avgSellPrice := 0.0
-	if totalSold > 0 {
-		avgSellPrice = totalRevenue / totalSold
+	avgSellPrice := newFloat()
+	if totalSold.Sign() > 0 {
+		avgSellPrice.Quo(totalRevenue, totalSold)
 	}
 
 	// Realized PnL: Revenue - (Cost of Sold Tokens)
 	// Cost of Sold Tokens = TotalSold * AvgBuyPrice
-	costOfSold := totalSold * avgBuyPrice
-	realizedPnL = totalRevenue - costOfSold
+	costOfSold := newFloat().Mul(totalSold, avgBuyPrice)
+	realizedPnL := newFloat().Sub(totalRevenue, costOfSold)
 
 	// Unrealized PnL: Value of current holdings - Cost of current holdings
 	// Cost of current holdings = CurrentBalance * AvgBuyPrice
-	currentValue := currentBalance * currentPrice
-	costOfHeld := currentBalance * avgBuyPrice
-	unrealizedPnL := currentValue - costOfHeld
+	currentValue := newFloat().Mul(currentBalance, newFloat().SetFloat64(currentPrice))
+	costOfHeld := newFloat().Mul(currentBalance, avgBuyPrice)
+	unrealizedPnL := newFloat().Sub(currentValue, costOfHeld)
 
-	totalPnL := realizedPnL + unrealizedPnL
+	totalPnL := newFloat().Add(realizedPnL, unrealizedPnL)
 
 	// ROI = TotalPnL / Total Cost Invested (TotalCost) * 100
-	roi := 0.0
-	if totalCost > 0 {
-		roi = (totalPnL / totalCost) * 100
+	roi := newFloat()
+	if totalCost.Sign() > 0 {
+		roi.Quo(totalPnL, totalCost)
+		roi.Mul(roi, newFloat().SetInt64(100))
 	}
 
 	return &domain.WalletPnL{
-		TotalBought:     totalBought,
-		TotalSold:       totalSold,
-		CurrentBalance:  currentBalance,
-		AverageBuyPrice: avgBuyPrice,
-		AverageSellPrice: avgSellPrice,
-		RealizedPnL:     realizedPnL,
-		UnrealizedPnL:   unrealizedPnL,
-		TotalPnL:        totalPnL,
-		ROI:             roi,
+		TotalBought:      toFloat64(totalBought),
+		TotalSold:        toFloat64(totalSold),
+		CurrentBalance:   toFloat64(currentBalance),
+		AverageBuyPrice:  toFloat64(avgBuyPrice),
+		AverageSellPrice: toFloat64(avgSellPrice),
+		RealizedPnL:      toFloat64(realizedPnL),
+		UnrealizedPnL:    toFloat64(unrealizedPnL),
+		TotalPnL:         toFloat64(totalPnL),
+		ROI:              toFloat64(roi),
+		TradeCount:       tradeCount,
+		VolumeUSD:        toFloat64(newFloat().Add(totalCost, totalRevenue)),
 	}
 }
+
+// toFloat64 rounds an accumulated amount to the nearest float64
+func toFloat64(f *big.Float) float64 {
+	v, _ := f.Float64()
+	return v
+}
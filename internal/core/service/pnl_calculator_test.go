@@ -0,0 +1,75 @@
+package service
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/core/domain"
+)
+
+// baseUnits returns whole * 10^decimals + frac as a raw on-chain amount
+func baseUnits(whole int64, frac int64, decimals int) *big.Int {
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	v := new(big.Int).Mul(big.NewInt(whole), unit)
+	return v.Add(v, big.NewInt(frac))
+}
+
+func TestPnLCalculator_ScalesRawAmounts(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		decimals int
+		buy      *big.Int
+		sell     *big.Int
+		wantBuy  float64
+		wantSell float64
+	}{
+		// 2,000 USDC bought, 1,500.5 sold
+		{"6 decimals", 6, baseUnits(2000, 0, 6), baseUnits(1500, 500000, 6), 2000, 1500.5},
+		// 2,000 tokens bought, 1,500.5 sold
+		{"18 decimals", 18, baseUnits(2000, 0, 18), baseUnits(1500, 5e17, 18), 2000, 1500.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trades := []domain.Trade{
+				{Type: "buy", RawAmount: tt.buy, Decimals: tt.decimals, PriceUSD: 1, Timestamp: t0},
+				{Type: "sell", RawAmount: tt.sell, Decimals: tt.decimals, PriceUSD: 2, Timestamp: t0.Add(time.Hour)},
+			}
+
+			got := NewPnLCalculator().Calculate(trades, 3)
+			if got.TotalBought != tt.wantBuy || got.TotalSold != tt.wantSell {
+				t.Errorf("bought/sold = %v/%v, want %v/%v", got.TotalBought, got.TotalSold, tt.wantBuy, tt.wantSell)
+			}
+			if got.CurrentBalance != tt.wantBuy-tt.wantSell {
+				t.Errorf("balance = %v, want %v", got.CurrentBalance, tt.wantBuy-tt.wantSell)
+			}
+			// Sold 1,500.5 at $2 against a $1 cost basis
+			if got.RealizedPnL != 1500.5 {
+				t.Errorf("realized PnL = %v, want 1500.5", got.RealizedPnL)
+			}
+		})
+	}
+}
+
+func TestPnLCalculator_KeepsPrecisionOnLargeAmounts(t *testing.T) {
+	// 10^12 tokens with 18 decimals plus one base unit per trade; summing
+	// these as float64 would drop the fractional dust entirely
+	amount := baseUnits(1_000_000_000_000, 1, 18)
+	var trades []domain.Trade
+	for i := 0; i < 1000; i++ {
+		trades = append(trades, domain.Trade{Type: "buy", RawAmount: amount, Decimals: 18, PriceUSD: 1})
+	}
+	trades = append(trades, domain.Trade{Type: "sell", RawAmount: baseUnits(1000*1_000_000_000_000, 0, 18), Decimals: 18, PriceUSD: 1})
+
+	got := NewPnLCalculator().Calculate(trades, 1)
+	if got.TotalBought != 1e15 {
+		t.Errorf("total bought = %v, want 1e15", got.TotalBought)
+	}
+	// 1000 base units of dust remain, i.e. 1e-15 tokens
+	if got.CurrentBalance <= 0 || got.CurrentBalance > 2e-15 {
+		t.Errorf("current balance = %v, want ~1e-15", got.CurrentBalance)
+	}
+}
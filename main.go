@@ -2,17 +2,19 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"os"
-	"strings"
+	"strconv"
+	"time"
 
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/adapters/breaker"
 	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/adapters/chain"
 	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/adapters/price"
 	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/core/domain"
 	"github.com/TeneoProtocolAI/teneo-agent-sdk/internal/core/service"
 	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/agent"
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/types"
 	"github.com/joho/godotenv"
 )
 
@@ -21,50 +23,51 @@ type AlphaWalletFinderAgent struct {
 }
 
 func (a *AlphaWalletFinderAgent) ProcessTask(ctx context.Context, task string) (string, error) {
-	log.Printf("Processing task: %s", task)
+	return a.analyze(ctx, task, nil)
+}
 
-	// Clean input
-	task = strings.TrimSpace(task)
-	task = strings.TrimPrefix(task, "/")
-
-	// Expected Input: JSON string or simple command?
-	// Requirement: "Input: { chain, tokenAddress, limit }"
-	// We'll try to parse JSON first. If fails, check for command style.
-	
-	var input domain.AgentInput
-	if err := json.Unmarshal([]byte(task), &input); err != nil {
-		// Fallback: Try whitespace separated "ethereum 0x... 5"
-		parts := strings.Fields(task)
-		if len(parts) >= 2 {
-			input.Chain = parts[0]
-			input.TokenAddress = parts[1]
-			if len(parts) >= 3 {
-				fmt.Sscanf(parts[2], "%d", &input.Limit)
-			}
-			if input.Limit == 0 {
-				input.Limit = 10 // default
-			}
-		} else {
-			return "", fmt.Errorf("invalid input format: expected JSON or 'chain address [limit]'")
+// ProcessTaskWithStreaming runs the same analysis as ProcessTask, sending a
+// task update as each phase starts so long analyses do not look stalled.
+func (a *AlphaWalletFinderAgent) ProcessTaskWithStreaming(ctx context.Context, task string, room string, sender types.MessageSender) error {
+	result, err := a.analyze(ctx, task, func(p domain.Progress) {
+		if err := sender.SendTaskUpdate(p.Message); err != nil {
+			log.Printf("Failed to send progress update: %v", err)
 		}
+	})
+	if err != nil {
+		return err
 	}
+	return sender.SendMessage(result)
+}
 
-	// Validate (basic)
-	if input.Chain == "" || input.TokenAddress == "" {
-		return "", fmt.Errorf("missing chain or token address")
-	}
+// analyze parses a task, runs the analysis and formats its result
+func (a *AlphaWalletFinderAgent) analyze(ctx context.Context, task string, progress domain.ProgressFunc) (string, error) {
+	log.Printf("Processing task: %s", task)
 
-	result, err := a.agentService.AnalyzeToken(ctx, input)
+	input, err := service.ParseInput(task)
 	if err != nil {
-		return "", fmt.Errorf("analysis failed: %w", err)
+		return "", err
+	}
+
+	// Reject unknown formats before spending time on the analysis
+	if _, err := service.ParseOutputFormat(input.Format); err != nil {
+		return "", err
 	}
 
-	outputBytes, err := json.MarshalIndent(result, "", "  ")
+	if input.IsBatch() {
+		batch, err := a.agentService.AnalyzeTokens(ctx, input, progress)
+		if err != nil {
+			return "", fmt.Errorf("analysis failed: %w", err)
+		}
+		return service.FormatBatchOutput(batch, input.Format)
+	}
+
+	result, err := a.agentService.AnalyzeToken(ctx, input, progress)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal result: %w", err)
+		return "", fmt.Errorf("analysis failed: %w", err)
 	}
 
-	return string(outputBytes), nil
+	return service.FormatOutput(result, input.Format)
 }
 
 func main() {
@@ -76,15 +79,50 @@ func main() {
 	solURL := os.Getenv("ALCHEMY_SOLANA_URL")
 
 	// Initialize chain services with Alchemy RPC URLs
-	ethService := chain.NewEthereumService(ethURL)
-	solService := chain.NewSolanaService(solURL)
-	
-	chains := []domain.ChainService{ethService, solService}
-	priceService := price.NewDexScreenerService()
+	ethService := chain.NewEthereumService(ethURL, nil)
+	solService := chain.NewSolanaService(solURL, nil)
+
+	// Each upstream fails fast during an outage: SERVICE_BREAKER_FAILURES
+	// consecutive failures open its circuit for SERVICE_BREAKER_COOLDOWN
+	breakerConfig := breaker.DefaultConfig()
+	if v := os.Getenv("SERVICE_BREAKER_FAILURES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			log.Fatalf("Invalid SERVICE_BREAKER_FAILURES: %q", v)
+		}
+		breakerConfig.MaxFailures = n
+	}
+	if v := os.Getenv("SERVICE_BREAKER_COOLDOWN"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			log.Fatalf("Invalid SERVICE_BREAKER_COOLDOWN: %q", v)
+		}
+		breakerConfig.Cooldown = d
+	}
+
+	chains := []domain.ChainService{
+		breaker.NewChainService(ethService, breakerConfig),
+		breaker.NewChainService(solService, breakerConfig),
+	}
+	priceService := price.NewCompositeService(
+		breaker.NewPriceService(price.NewDexScreenerService(), breakerConfig),
+		breaker.NewPriceService(price.NewCoinGeckoService(os.Getenv("COINGECKO_API_KEY")), breakerConfig),
+	)
 	pnlCalc := service.NewPnLCalculator()
 
 	agentService := service.NewAgentService(chains, priceService, pnlCalc)
 
+	// Addresses excluded from rankings: WALLET_BLOCKLIST adds comma-separated
+	// chain:address entries; WALLET_BLOCKLIST_DEFAULTS=false drops the built-ins
+	blocklist := service.DefaultBlocklist()
+	if os.Getenv("WALLET_BLOCKLIST_DEFAULTS") == "false" {
+		blocklist = service.NewBlocklist()
+	}
+	if err := blocklist.AddEntries(os.Getenv("WALLET_BLOCKLIST")); err != nil {
+		log.Fatalf("Invalid WALLET_BLOCKLIST: %v", err)
+	}
+	agentService.SetBlocklist(blocklist)
+
 	// Configure Agent
 	config := agent.DefaultConfig()
 	config.Name = "Alpha Wallet Finder"
@@ -129,6 +167,18 @@ func main() {
 		log.Fatal(err)
 	}
 
+	// Reuse results from the agent cache (Redis when enabled);
+	// ANALYSIS_CACHE_TTL sets how long, and 0 disables reuse
+	cacheTTL := service.DefaultResultCacheTTL
+	if v := os.Getenv("ANALYSIS_CACHE_TTL"); v != "" {
+		if cacheTTL, err = time.ParseDuration(v); err != nil {
+			log.Fatalf("Invalid ANALYSIS_CACHE_TTL: %v", err)
+		}
+	}
+	if cacheTTL > 0 {
+		agentService.SetResultCache(enhancedAgent.GetCache(), cacheTTL)
+	}
+
 	log.Println("Starting Alpha Wallet Finder Agent...")
 	enhancedAgent.Run()
 }
\ No newline at end of file
@@ -2,14 +2,30 @@ package agent
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/network"
 	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/types"
 )
 
+// Queue full policies for Config.QueueFullPolicy
+const (
+	QueueFullPolicyReject = "reject" // answer new tasks with an "agent busy" error
+	QueueFullPolicyBlock  = "block"  // wait until a queue slot frees
+)
+
+// MaxRoomLength is the longest room ID Config.Room accepts
+const MaxRoomLength = 128
+
+// roomPattern matches room IDs: letters, digits, '_', '-' and '.', in
+// segments that may be separated by ':' or '/'
+var roomPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+([:/][A-Za-z0-9_.-]+)*$`)
+
 // Config represents the configuration for a Teneo agent
 type Config struct {
 	// Basic agent info
@@ -34,16 +50,48 @@ type Config struct {
 	PingInterval     time.Duration `json:"ping_interval"`
 	HandshakeTimeout time.Duration `json:"handshake_timeout"`
 
+	// PongTimeout is how long the server has to answer each WebSocket ping;
+	// after MaxMissedPongs unanswered pings in a row the connection is
+	// treated as dead and reconnected
+	PongTimeout    time.Duration `json:"pong_timeout"`
+	MaxMissedPongs int           `json:"max_missed_pongs"`
+
+	// EnableCompression negotiates WebSocket permessage-deflate compression,
+	// falling back to uncompressed frames if the server does not support it
+	EnableCompression bool `json:"enable_compression"`
+
+	// OnConnectionStateChange is called on every WebSocket connection state
+	// transition, e.g. to alert when the agent drops offline
+	OnConnectionStateChange func(old, new network.ConnectionState) `json:"-"`
+
+	// RestoreVisibility re-applies the visibility last requested with
+	// SetVisibility whenever the agent authenticates again after a reconnect
+	RestoreVisibility bool `json:"restore_visibility"`
+
+	// Reconnect backoff (exponential with jitter)
+	ReconnectBaseDelay  time.Duration `json:"reconnect_base_delay"`
+	ReconnectMaxDelay   time.Duration `json:"reconnect_max_delay"`
+	ReconnectMultiplier float64       `json:"reconnect_multiplier"`
+
 	// Health monitoring
 	HealthEnabled bool `json:"health_enabled"`
 	HealthPort    int  `json:"health_port"`
 
+	// Local HTTP task endpoint (POST /task) for smoke tests and local tools.
+	// Off by default; LocalHTTPAddr should stay on localhost since tasks
+	// sent to it are not authenticated.
+	LocalHTTPEnabled bool   `json:"local_http_enabled"`
+	LocalHTTPAddr    string `json:"local_http_addr"`
+
 	// Authentication
 	PrivateKey   string `json:"private_key"`
 	OwnerAddress string `json:"owner_address"`
 	NFTTokenID   string `json:"nft_token_id"`
 
-	// Room configuration
+	// Room is the room the agent registers in. Empty (the default) joins no
+	// room of its own; tasks are still answered in the room they came from.
+	// IDs use letters, digits, '_', '-' and '.' and may be namespaced with
+	// ':' or '/'; surrounding whitespace is trimmed and case is kept.
 	Room string `json:"room"`
 
 	// Blockchain configuration
@@ -55,8 +103,37 @@ type Config struct {
 	TaskTimeout        int `json:"task_timeout"`
 	TaskCheckInterval  int `json:"task_check_interval"`
 
+	// Task queue: tasks beyond MaxConcurrentTasks wait in a queue of up to
+	// MaxQueuedTasks (0 = unbounded). QueueFullPolicy is "reject" or "block".
+	MaxQueuedTasks  int    `json:"max_queued_tasks"`
+	QueueFullPolicy string `json:"queue_full_policy"`
+
+	// TaskUpdateCoalesceWindow collapses streaming task updates sent within
+	// this window into the latest one, e.g. 250ms (0 = send every update)
+	TaskUpdateCoalesceWindow time.Duration `json:"task_update_coalesce_window"`
+
+	// TaskProviderInterval is how often an agent implementing
+	// types.TaskProvider is asked for tasks of its own
+	// (0 = network.DefaultTaskProviderInterval)
+	TaskProviderInterval time.Duration `json:"task_provider_interval"`
+
+	// OnTaskFailure is a dead-letter hook called with the task (content and
+	// sender) and its error when a task fails or times out, e.g. to log it
+	// to a file, push it to a queue or alert. It runs in its own goroutine.
+	OnTaskFailure func(task types.Task, err error) `json:"-"`
+
+	// NLPFallback retries tasks the handler rejects with
+	// types.ErrUnrecognizedCommand through its types.NLPFallbackHandler
+	NLPFallback bool `json:"nlp_fallback"`
+
+	// MaxMessageBytes limits the content of each outgoing task message for
+	// backends that reject large frames (0 = unlimited). Longer text and
+	// markdown are split into sequential messages; JSON fails instead.
+	MaxMessageBytes int `json:"max_message_bytes"`
+
 	// Rate limiting
-	RateLimitPerMinute int `json:"rate_limit_per_minute"` // 0 = unlimited
+	RateLimitPerMinute        int `json:"rate_limit_per_minute"`          // 0 = unlimited
+	RateLimitPerUserPerMinute int `json:"rate_limit_per_user_per_minute"` // per sender wallet/user, 0 = unlimited
 
 	// Redis cache configuration
 	RedisEnabled   bool   `json:"redis_enabled"`    // Enable Redis caching
@@ -76,10 +153,58 @@ func (c *Config) Validate() error {
 	if c.PrivateKey == "" {
 		return fmt.Errorf("private key is required")
 	}
+	switch c.QueueFullPolicy {
+	case "", QueueFullPolicyReject, QueueFullPolicyBlock:
+	default:
+		return fmt.Errorf("queue full policy must be %q or %q", QueueFullPolicyReject, QueueFullPolicyBlock)
+	}
 	// OwnerAddress is derived from private key, so we don't require it to be set
 	return nil
 }
 
+// validateWebSocketURL checks that rawURL is a ws:// or wss:// URL with a host
+func validateWebSocketURL(rawURL string) error {
+	if rawURL == "" {
+		return fmt.Errorf("websocket URL is required (e.g. wss://backend.example.com/ws)")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid websocket URL %q: %w", rawURL, err)
+	}
+	if u.Scheme != "ws" && u.Scheme != "wss" {
+		return fmt.Errorf("invalid websocket URL %q: scheme must be ws:// or wss:// (e.g. wss://backend.example.com/ws)", rawURL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid websocket URL %q: missing host", rawURL)
+	}
+	return nil
+}
+
+// normalizeRoom trims room and checks it is a valid room ID. An empty room
+// is valid and means the agent joins no room of its own.
+func normalizeRoom(room string) (string, error) {
+	room = strings.TrimSpace(room)
+	if room == "" {
+		return "", nil
+	}
+	if len(room) > MaxRoomLength {
+		return "", fmt.Errorf("invalid room %q: longer than %d characters", room, MaxRoomLength)
+	}
+	if !roomPattern.MatchString(room) {
+		return "", fmt.Errorf("invalid room %q: use letters, digits, '_', '-' and '.', optionally namespaced with ':' or '/' (e.g. general or team:support)", room)
+	}
+	return room, nil
+}
+
+// deriveWebSocketURL derives the WebSocket endpoint from a backend URL, the
+// inverse of deriving the backend URL (https->wss, http->ws, append /ws)
+func deriveWebSocketURL(backendURL string) string {
+	derived := strings.TrimSuffix(backendURL, "/")
+	derived = strings.Replace(derived, "https://", "wss://", 1)
+	derived = strings.Replace(derived, "http://", "ws://", 1)
+	return derived + "/ws"
+}
+
 // LoadFromEnv loads configuration from environment variables
 func (c *Config) LoadFromEnv() error {
 	if name := os.Getenv("AGENT_NAME"); name != "" {
@@ -135,11 +260,72 @@ func (c *Config) LoadFromEnv() error {
 			c.HealthPort = port
 		}
 	}
+	if localHTTP := os.Getenv("LOCAL_HTTP_ENABLED"); localHTTP != "" {
+		if enabled, err := strconv.ParseBool(localHTTP); err == nil {
+			c.LocalHTTPEnabled = enabled
+		}
+	}
+	if addr := os.Getenv("LOCAL_HTTP_ADDR"); addr != "" {
+		c.LocalHTTPAddr = addr
+	}
+	if maxQueued := os.Getenv("MAX_QUEUED_TASKS"); maxQueued != "" {
+		if n, err := strconv.Atoi(maxQueued); err == nil {
+			c.MaxQueuedTasks = n
+		}
+	}
+	if policy := os.Getenv("QUEUE_FULL_POLICY"); policy != "" {
+		c.QueueFullPolicy = policy
+	}
+	if compression := os.Getenv("WEBSOCKET_COMPRESSION"); compression != "" {
+		if enabled, err := strconv.ParseBool(compression); err == nil {
+			c.EnableCompression = enabled
+		}
+	}
+	if window := os.Getenv("TASK_UPDATE_COALESCE_WINDOW"); window != "" {
+		if d, err := time.ParseDuration(window); err == nil {
+			c.TaskUpdateCoalesceWindow = d
+		}
+	}
+	if interval := os.Getenv("TASK_PROVIDER_INTERVAL"); interval != "" {
+		if d, err := time.ParseDuration(interval); err == nil {
+			c.TaskProviderInterval = d
+		}
+	}
+	if timeout := os.Getenv("PONG_TIMEOUT"); timeout != "" {
+		if d, err := time.ParseDuration(timeout); err == nil {
+			c.PongTimeout = d
+		}
+	}
+	if missed := os.Getenv("MAX_MISSED_PONGS"); missed != "" {
+		if n, err := strconv.Atoi(missed); err == nil {
+			c.MaxMissedPongs = n
+		}
+	}
+	if restore := os.Getenv("RESTORE_VISIBILITY"); restore != "" {
+		if enabled, err := strconv.ParseBool(restore); err == nil {
+			c.RestoreVisibility = enabled
+		}
+	}
+	if nlpFallback := os.Getenv("NLP_FALLBACK"); nlpFallback != "" {
+		if enabled, err := strconv.ParseBool(nlpFallback); err == nil {
+			c.NLPFallback = enabled
+		}
+	}
+	if maxBytes := os.Getenv("MAX_MESSAGE_BYTES"); maxBytes != "" {
+		if n, err := strconv.Atoi(maxBytes); err == nil {
+			c.MaxMessageBytes = n
+		}
+	}
 	if rateLimit := os.Getenv("RATE_LIMIT_PER_MINUTE"); rateLimit != "" {
 		if limit, err := strconv.Atoi(rateLimit); err == nil {
 			c.RateLimitPerMinute = limit
 		}
 	}
+	if rateLimit := os.Getenv("RATE_LIMIT_PER_USER_PER_MINUTE"); rateLimit != "" {
+		if limit, err := strconv.Atoi(rateLimit); err == nil {
+			c.RateLimitPerUserPerMinute = limit
+		}
+	}
 	// Redis configuration
 	if redisEnabled := os.Getenv("REDIS_ENABLED"); redisEnabled != "" {
 		if enabled, err := strconv.ParseBool(redisEnabled); err == nil {
@@ -191,15 +377,21 @@ func DefaultConfig() *Config {
 		ReconnectDelay:     5 * time.Second,
 		MaxReconnects:      10,
 		MessageTimeout:     30 * time.Second,
-		PingInterval:       30 * time.Second,
+		PingInterval:       network.DefaultPingInterval,
 		HandshakeTimeout:   10 * time.Second,
+		PongTimeout:        network.DefaultPongTimeout,
+		MaxMissedPongs:     network.DefaultMaxMissedPongs,
 		HealthEnabled:      true,
+		RestoreVisibility:  true,
 		HealthPort:         8080,
+		LocalHTTPAddr:      DefaultLocalHTTPAddr,
 		EthereumRPC:        "https://peaq.api.onfinality.io/public",
 		NFTContractAddress: "0x811FF962AcBe432344AC974c1111b70847195d3C",
-		MaxConcurrentTasks: 5,
+		MaxConcurrentTasks: 0, // 0 = unlimited
 		TaskTimeout:        30,
 		TaskCheckInterval:  10,
+		MaxQueuedTasks:     0, // 0 = unbounded
+		QueueFullPolicy:    QueueFullPolicyReject,
 		RateLimitPerMinute: 0, // 0 = unlimited
 		RedisEnabled:       false,
 		RedisAddress:       "localhost:6379",
@@ -208,5 +400,9 @@ func DefaultConfig() *Config {
 		RedisDB:            0,
 		RedisKeyPrefix:     "", // Will be set to "teneo:agent:<agent_name>:" if empty
 		RedisUseTLS:        false,
+
+		ReconnectBaseDelay:  network.DefaultReconnectBaseDelay,
+		ReconnectMaxDelay:   network.DefaultReconnectMaxDelay,
+		ReconnectMultiplier: network.DefaultReconnectMultiplier,
 	}
 }
@@ -0,0 +1,175 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateWebSocketURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantErr string
+	}{
+		{"wss", "wss://backend.example.com/ws", ""},
+		{"ws with port", "ws://localhost:8080/ws", ""},
+		{"empty", "", "websocket URL is required"},
+		{"missing scheme", "backend.example.com/ws", "scheme must be ws:// or wss://"},
+		{"http scheme", "https://backend.example.com/ws", "scheme must be ws:// or wss://"},
+		{"missing host", "wss:///ws", "missing host"},
+		{"unparseable", "wss://backend example.com/ws", "invalid websocket URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWebSocketURL(tt.url)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateWebSocketURL(%q) error = %v", tt.url, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateWebSocketURL(%q) error = %v, want it to contain %q", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolveWebSocketURL(t *testing.T) {
+	tests := []struct {
+		name         string
+		webSocketURL string
+		backendURL   string
+		envBackend   string
+		want         string
+		wantErr      bool
+	}{
+		{"explicit URL kept", "wss://ws.example.com/ws", "https://api.example.com", "", "wss://ws.example.com/ws", false},
+		{"derived from https backend", "", "https://api.example.com", "", "wss://api.example.com/ws", false},
+		{"derived from http backend", "", "http://localhost:8080/", "", "ws://localhost:8080/ws", false},
+		{"derived from BACKEND_URL", "", "", "https://env.example.com", "wss://env.example.com/ws", false},
+		{"empty", "", "", "", "", true},
+		{"missing scheme", "backend.example.com/ws", "", "", "backend.example.com/ws", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("BACKEND_URL", tt.envBackend)
+			config := &EnhancedAgentConfig{
+				Config:     &Config{WebSocketURL: tt.webSocketURL},
+				BackendURL: tt.backendURL,
+			}
+
+			err := resolveWebSocketURL(config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveWebSocketURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if config.Config.WebSocketURL != tt.want {
+				t.Errorf("WebSocketURL = %q, want %q", config.Config.WebSocketURL, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewEnhancedAgent_RejectsInvalidWebSocketURL(t *testing.T) {
+	config := DefaultConfig()
+	config.PrivateKey = testPrivateKey
+	config.WebSocketURL = "backend.example.com/ws"
+
+	_, err := NewEnhancedAgent(&EnhancedAgentConfig{Config: config, AgentHandler: echoHandler{}})
+	if err == nil || !strings.Contains(err.Error(), "scheme must be ws:// or wss://") {
+		t.Fatalf("NewEnhancedAgent() error = %v, want a websocket URL error", err)
+	}
+}
+
+func TestNormalizeRoom(t *testing.T) {
+	tests := []struct {
+		name    string
+		room    string
+		want    string
+		wantErr bool
+	}{
+		{"plain", "general", "general", false},
+		{"case kept", "Team-Alpha_2", "Team-Alpha_2", false},
+		{"namespaced", "team:support", "team:support", false},
+		{"path namespaced", "org/team.eu", "org/team.eu", false},
+		{"trimmed", "  general\n", "general", false},
+		{"empty", "", "", false},
+		{"whitespace only", "   ", "", false},
+		{"inner space", "my room", "", true},
+		{"empty segment", "team::support", "", true},
+		{"trailing separator", "team:", "", true},
+		{"leading separator", "/general", "", true},
+		{"symbols", "room#1", "", true},
+		{"non-ASCII", "räume", "", true},
+		{"too long", strings.Repeat("r", MaxRoomLength+1), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeRoom(tt.room)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeRoom(%q) error = %v, wantErr %v", tt.room, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("normalizeRoom(%q) = %q, want %q", tt.room, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewEnhancedAgent_Room(t *testing.T) {
+	tests := []struct {
+		name    string
+		room    string
+		want    string
+		wantErr string
+	}{
+		{"valid", " team:support ", "team:support", ""},
+		{"empty", "", "", ""},
+		{"malformed", "team support", "", "invalid room"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.PrivateKey = testPrivateKey
+			config.WebSocketURL = "ws://127.0.0.1:1/ws"
+			config.Room = tt.room
+
+			agent, err := NewEnhancedAgent(&EnhancedAgentConfig{Config: config, AgentHandler: echoHandler{}, TokenID: 1})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("NewEnhancedAgent() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewEnhancedAgent() error = %v", err)
+			}
+			defer agent.cancel()
+			if agent.config.Room != tt.want {
+				t.Errorf("Room = %q, want %q", agent.config.Room, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_LoadFromEnvDurations(t *testing.T) {
+	t.Setenv("TASK_UPDATE_COALESCE_WINDOW", "250ms")
+	t.Setenv("TASK_PROVIDER_INTERVAL", "1m")
+	t.Setenv("PONG_TIMEOUT", "5s")
+
+	config := DefaultConfig()
+	if err := config.LoadFromEnv(); err != nil {
+		t.Fatalf("LoadFromEnv() error = %v", err)
+	}
+	if config.TaskUpdateCoalesceWindow != 250*time.Millisecond {
+		t.Errorf("TaskUpdateCoalesceWindow = %v, want 250ms", config.TaskUpdateCoalesceWindow)
+	}
+	if config.TaskProviderInterval != time.Minute || config.PongTimeout != 5*time.Second {
+		t.Errorf("TaskProviderInterval = %v, PongTimeout = %v, want 1m and 5s", config.TaskProviderInterval, config.PongTimeout)
+	}
+}
@@ -0,0 +1,176 @@
+package agent
+
+import (
+	"context"
+	"sync"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/types"
+)
+
+// Methods recorded by MessageRecorder, matching the types.MessageSender
+// method names
+const (
+	MethodSendMessage        = "SendMessage"
+	MethodSendTaskUpdate     = "SendTaskUpdate"
+	MethodSendMessageAsJSON  = "SendMessageAsJSON"
+	MethodSendMessageAsMD    = "SendMessageAsMD"
+	MethodSendMessageAsArray = "SendMessageAsArray"
+	MethodSendErrorMessage   = "SendErrorMessage"
+	MethodTriggerWalletTx    = "TriggerWalletTx"
+)
+
+// RecordedMessage is one call made on a MessageRecorder
+type RecordedMessage struct {
+	Method  string      // MessageSender method called, e.g. MethodSendMessageAsJSON
+	Content interface{} // content argument; the TxRequest for TriggerWalletTx
+
+	// Set by SendErrorMessage
+	ErrorCode string
+	Details   map[string]interface{}
+
+	// Set by TriggerWalletTx
+	Description string
+	Optional    bool
+}
+
+// MessageRecorder is a types.MessageSender that records every call instead of
+// sending it, so streaming handlers can be tested without a network
+type MessageRecorder struct {
+	mu       sync.Mutex
+	messages []RecordedMessage
+}
+
+// NewMessageRecorder creates an empty recorder
+func NewMessageRecorder() *MessageRecorder {
+	return &MessageRecorder{}
+}
+
+func (r *MessageRecorder) record(msg RecordedMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, msg)
+	return nil
+}
+
+// SendMessage records a text message
+func (r *MessageRecorder) SendMessage(content string) error {
+	return r.record(RecordedMessage{Method: MethodSendMessage, Content: content})
+}
+
+// SendTaskUpdate records a progress update
+func (r *MessageRecorder) SendTaskUpdate(content string) error {
+	return r.record(RecordedMessage{Method: MethodSendTaskUpdate, Content: content})
+}
+
+// SendMessageAsJSON records structured JSON data
+func (r *MessageRecorder) SendMessageAsJSON(content interface{}) error {
+	return r.record(RecordedMessage{Method: MethodSendMessageAsJSON, Content: content})
+}
+
+// SendMessageAsMD records a markdown message
+func (r *MessageRecorder) SendMessageAsMD(content string) error {
+	return r.record(RecordedMessage{Method: MethodSendMessageAsMD, Content: content})
+}
+
+// SendMessageAsArray records array data
+func (r *MessageRecorder) SendMessageAsArray(content []interface{}) error {
+	return r.record(RecordedMessage{Method: MethodSendMessageAsArray, Content: content})
+}
+
+// SendErrorMessage records an error message with its code and details
+func (r *MessageRecorder) SendErrorMessage(content string, errorCode string, details map[string]interface{}) error {
+	return r.record(RecordedMessage{
+		Method:    MethodSendErrorMessage,
+		Content:   content,
+		ErrorCode: errorCode,
+		Details:   details,
+	})
+}
+
+// TriggerWalletTx records a wallet transaction request
+func (r *MessageRecorder) TriggerWalletTx(tx types.TxRequest, description string, optional bool) error {
+	return r.record(RecordedMessage{
+		Method:      MethodTriggerWalletTx,
+		Content:     tx,
+		Description: description,
+		Optional:    optional,
+	})
+}
+
+// Messages returns the recorded calls in the order they were made
+func (r *MessageRecorder) Messages() []RecordedMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RecordedMessage(nil), r.messages...)
+}
+
+// MessagesOf returns the recorded calls of one method, e.g. MethodSendMessage
+func (r *MessageRecorder) MessagesOf(method string) []RecordedMessage {
+	var matching []RecordedMessage
+	for _, msg := range r.Messages() {
+		if msg.Method == method {
+			matching = append(matching, msg)
+		}
+	}
+	return matching
+}
+
+// Reset discards the recorded calls
+func (r *MessageRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = nil
+}
+
+// TestRun is the outcome of one task run by a TestHarness
+type TestRun struct {
+	// Result is the string returned by ProcessTask, the Result of a
+	// RichTaskHandler, or empty for streaming handlers
+	Result string
+	// RichResult is the result returned by a RichTaskHandler
+	RichResult *types.TaskResult
+	// Messages are the calls a streaming handler made on its MessageSender
+	Messages []RecordedMessage
+}
+
+// TestHarness runs tasks through an agent handler without any WebSocket,
+// backend or blockchain, for unit testing agent logic. Like the task
+// coordinator it prefers ProcessTaskWithStreaming, then ProcessRichTask,
+// then ProcessTask.
+type TestHarness struct {
+	handler types.AgentHandler
+
+	// Room is passed to streaming handlers (default "test-room")
+	Room string
+}
+
+// NewTestHarness creates a test harness for handler
+func NewTestHarness(handler types.AgentHandler) *TestHarness {
+	return &TestHarness{handler: handler, Room: "test-room"}
+}
+
+// Run runs task through the handler. The returned TestRun is never nil, so
+// messages sent before a streaming handler failed can still be inspected.
+func (h *TestHarness) Run(ctx context.Context, task string) (*TestRun, error) {
+	run := &TestRun{}
+
+	if streaming, ok := h.handler.(types.StreamingTaskHandler); ok {
+		recorder := NewMessageRecorder()
+		err := streaming.ProcessTaskWithStreaming(ctx, task, h.Room, recorder)
+		run.Messages = recorder.Messages()
+		return run, err
+	}
+
+	if rich, ok := h.handler.(types.RichTaskHandler); ok {
+		result, err := rich.ProcessRichTask(ctx, task)
+		if result != nil {
+			run.Result = result.Result
+		}
+		run.RichResult = result
+		return run, err
+	}
+
+	result, err := h.handler.ProcessTask(ctx, task)
+	run.Result = result
+	return run, err
+}
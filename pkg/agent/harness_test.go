@@ -0,0 +1,99 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/types"
+)
+
+// walletAgent is a streaming agent that reports progress, returns balances as
+// JSON and asks the user to sign a transfer
+type walletAgent struct{}
+
+func (walletAgent) ProcessTask(ctx context.Context, task string) (string, error) {
+	return "", errors.New("streaming only")
+}
+
+func (walletAgent) ProcessTaskWithStreaming(ctx context.Context, task string, room string, sender types.MessageSender) error {
+	command, arg, _ := strings.Cut(task, " ")
+	switch command {
+	case "balance":
+		sender.SendTaskUpdate("Fetching balance...")
+		return sender.SendMessageAsJSON(map[string]string{"wallet": arg, "balance": "1.5"})
+	case "send":
+		sender.SendMessage("Preparing transfer")
+		return sender.TriggerWalletTx(types.TxRequest{To: arg, Value: "1000", ChainId: 3338}, "Send 1000 wei", false)
+	default:
+		sender.SendErrorMessage("Unknown command", "unknown_command", map[string]interface{}{"command": command})
+		return types.ErrUnrecognizedCommand
+	}
+}
+
+func TestTestHarness_Streaming(t *testing.T) {
+	harness := NewTestHarness(walletAgent{})
+
+	run, err := harness.Run(context.Background(), "balance 0xabc")
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(run.Messages) != 2 {
+		t.Fatalf("got %d messages, want 2", len(run.Messages))
+	}
+	if update := run.Messages[0]; update.Method != MethodSendTaskUpdate || update.Content != "Fetching balance..." {
+		t.Errorf("first message = %+v, want a progress update", update)
+	}
+	balance, ok := run.Messages[1].Content.(map[string]string)
+	if run.Messages[1].Method != MethodSendMessageAsJSON || !ok || balance["wallet"] != "0xabc" {
+		t.Errorf("second message = %+v, want the balance as JSON", run.Messages[1])
+	}
+
+	run, err = harness.Run(context.Background(), "send 0xdef")
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(run.Messages) != 2 || run.Messages[1].Method != MethodTriggerWalletTx {
+		t.Fatalf("messages = %+v, want a wallet transaction last", run.Messages)
+	}
+	if tx := run.Messages[1].Content.(types.TxRequest); tx.To != "0xdef" || run.Messages[1].Description != "Send 1000 wei" {
+		t.Errorf("wallet transaction = %+v, want a transfer to 0xdef", run.Messages[1])
+	}
+
+	// Messages sent before a failure are still recorded
+	run, err = harness.Run(context.Background(), "mint")
+	if !errors.Is(err, types.ErrUnrecognizedCommand) {
+		t.Errorf("Run() error = %v, want ErrUnrecognizedCommand", err)
+	}
+	if errs := run.Messages; len(errs) != 1 || errs[0].Method != MethodSendErrorMessage || errs[0].ErrorCode != "unknown_command" {
+		t.Errorf("messages = %+v, want one unknown_command error", errs)
+	}
+}
+
+func TestTestHarness_ProcessTask(t *testing.T) {
+	run, err := NewTestHarness(echoHandler{}).Run(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if run.Result != "hello" || len(run.Messages) != 0 {
+		t.Errorf("Run() = %+v, want the echoed result and no messages", run)
+	}
+}
+
+func TestMessageRecorder_MessagesOf(t *testing.T) {
+	recorder := NewMessageRecorder()
+	recorder.SendMessage("one")
+	recorder.SendMessageAsMD("**two**")
+	recorder.SendMessage("three")
+
+	got := recorder.MessagesOf(MethodSendMessage)
+	if len(got) != 2 || got[0].Content != "one" || got[1].Content != "three" {
+		t.Errorf("MessagesOf(SendMessage) = %+v, want one and three", got)
+	}
+
+	recorder.Reset()
+	if got := recorder.Messages(); len(got) != 0 {
+		t.Errorf("Messages() after Reset = %+v, want none", got)
+	}
+}
@@ -0,0 +1,115 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/network"
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/types"
+)
+
+// DefaultLocalHTTPAddr is where the local task endpoint listens by default.
+// It is bound to localhost because tasks sent to it are not authenticated.
+const DefaultLocalHTTPAddr = "127.0.0.1:8081"
+
+// maxLocalTaskBytes bounds the request body of POST /task
+const maxLocalTaskBytes = 1 << 20
+
+// localTaskRequest is the body of POST /task
+type localTaskRequest struct {
+	Task string `json:"task"`
+}
+
+// localTaskServer accepts tasks over HTTP and runs them through the task
+// coordinator, answering with the task's output as JSON
+type localTaskServer struct {
+	addr        string
+	coordinator *network.TaskCoordinator
+	server      *http.Server
+}
+
+// newLocalTaskServer creates a local task server listening on addr
+func newLocalTaskServer(addr string, coordinator *network.TaskCoordinator) *localTaskServer {
+	if addr == "" {
+		addr = DefaultLocalHTTPAddr
+	}
+	return &localTaskServer{addr: addr, coordinator: coordinator}
+}
+
+// Start serves the local task endpoint until Stop is called
+func (s *localTaskServer) Start() error {
+	s.server = &http.Server{
+		Addr:    s.addr,
+		Handler: s.handler(),
+	}
+
+	log.Printf("🌐 Starting local task endpoint on http://%s/task", s.addr)
+	err := s.server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Stop stops the local task server
+func (s *localTaskServer) Stop() error {
+	if s.server != nil {
+		return s.server.Close()
+	}
+	return nil
+}
+
+// handler routes the local task endpoint
+func (s *localTaskServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/task", s.taskHandler)
+	return mux
+}
+
+// taskHandler runs the task in the request body and writes its output
+func (s *localTaskServer) taskHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeLocalError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req localTaskRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxLocalTaskBytes)).Decode(&req); err != nil {
+		writeLocalError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if strings.TrimSpace(req.Task) == "" {
+		writeLocalError(w, http.StatusBadRequest, "task is required")
+		return
+	}
+
+	output, err := s.coordinator.ProcessTask(r.Context(), types.Task{Content: req.Task})
+	switch {
+	case errors.Is(err, network.ErrTasksPaused), errors.Is(err, network.ErrQueueFull):
+		writeLocalError(w, http.StatusServiceUnavailable, err.Error())
+	case errors.Is(err, network.ErrRateLimited):
+		writeLocalError(w, http.StatusTooManyRequests, err.Error())
+	case err != nil:
+		writeLocalError(w, http.StatusInternalServerError, err.Error())
+	default:
+		writeLocalJSON(w, http.StatusOK, output)
+	}
+}
+
+// writeLocalError writes an error response
+func writeLocalError(w http.ResponseWriter, status int, message string) {
+	writeLocalJSON(w, status, map[string]string{"error": message})
+}
+
+// writeLocalJSON writes v as a JSON response with status
+func writeLocalJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("⚠️ Failed to write local task response: %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/network"
+)
+
+// recordingHandler echoes tasks and records the ones it was given
+type recordingHandler struct {
+	mu    sync.Mutex
+	tasks []string
+}
+
+func (h *recordingHandler) ProcessTask(ctx context.Context, task string) (string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.tasks = append(h.tasks, task)
+	return "echo: " + task, nil
+}
+
+// newLocalTaskTestServer serves the local task endpoint of a coordinator
+// running handler
+func newLocalTaskTestServer(t *testing.T, handler *recordingHandler) (*httptest.Server, *network.TaskCoordinator) {
+	t.Helper()
+	client := network.NewNetworkClient(network.DefaultNetworkConfig())
+	protocol := network.NewProtocolHandler(client, nil, "test-agent", nil, "0xagent", "", "room")
+	coordinator := network.NewTaskCoordinator(handler, protocol, nil)
+
+	srv := httptest.NewServer(newLocalTaskServer("", coordinator).handler())
+	t.Cleanup(srv.Close)
+	return srv, coordinator
+}
+
+func TestLocalTaskServer_RunsTask(t *testing.T) {
+	handler := &recordingHandler{}
+	srv, _ := newLocalTaskTestServer(t, handler)
+
+	resp, err := http.Post(srv.URL+"/task", "application/json", strings.NewReader(`{"task": "hello world"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+
+	var output network.TaskOutput
+	if err := json.NewDecoder(resp.Body).Decode(&output); err != nil {
+		t.Fatal(err)
+	}
+	if !output.Success || output.Result != "echo: hello world" || output.TaskID == "" {
+		t.Errorf("output = %+v, want a successful echo", output)
+	}
+	if len(output.Messages) != 1 || output.Messages[0].Content != "echo: hello world" {
+		t.Errorf("messages = %v, want the echoed response", output.Messages)
+	}
+	if len(handler.tasks) != 1 || handler.tasks[0] != "hello world" {
+		t.Errorf("handler got %q, want [hello world]", handler.tasks)
+	}
+}
+
+func TestLocalTaskServer_Rejections(t *testing.T) {
+	handler := &recordingHandler{}
+	srv, coordinator := newLocalTaskTestServer(t, handler)
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		pause  bool
+		want   int
+	}{
+		{"wrong method", http.MethodGet, "", false, http.StatusMethodNotAllowed},
+		{"invalid body", http.MethodPost, `{"task":`, false, http.StatusBadRequest},
+		{"empty task", http.MethodPost, `{"task": "  "}`, false, http.StatusBadRequest},
+		{"paused", http.MethodPost, `{"task": "hello"}`, true, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.pause {
+				coordinator.Pause()
+				defer coordinator.Resume()
+			}
+			req, err := http.NewRequest(tt.method, srv.URL+"/task", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+
+	if len(handler.tasks) != 0 {
+		t.Errorf("handler got %q, want no tasks", handler.tasks)
+	}
+}
+
+func TestLocalTaskServer_RateLimited(t *testing.T) {
+	srv, coordinator := newLocalTaskTestServer(t, &recordingHandler{})
+	coordinator.SetRateLimit(1)
+
+	for _, want := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		resp, err := http.Post(srv.URL+"/task", "application/json", strings.NewReader(`{"task": "hello"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("status = %d, want %d", resp.StatusCode, want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+
+	"github.com/joho/godotenv"
+)
+
+// hotReloadableFields lists the Config fields ReloadConfig applies to a running
+// agent. Every other field is fixed at startup.
+var hotReloadableFields = map[string]bool{
+	"Capabilities":              true,
+	"RateLimitPerMinute":        true,
+	"RateLimitPerUserPerMinute": true,
+}
+
+// ReloadConfig loads the configuration from the reload source and applies its
+// hot-reloadable fields (capabilities and rate limits) without reconnecting or
+// re-authenticating. Changes to other fields are ignored with a warning.
+func (a *EnhancedAgent) ReloadConfig() error {
+	next, err := a.reloadSource()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if next == nil {
+		return fmt.Errorf("reload source returned no config")
+	}
+
+	a.applyConfig(next)
+	return nil
+}
+
+// applyConfig applies the hot-reloadable fields of next to the running agent
+func (a *EnhancedAgent) applyConfig(next *Config) {
+	current := reflect.ValueOf(a.config).Elem()
+	updated := reflect.ValueOf(next).Elem()
+	changed := false
+	a.mu.RLock()
+	for i := 0; i < current.NumField(); i++ {
+		name := current.Type().Field(i).Name
+		if current.Field(i).Kind() == reflect.Func {
+			continue // hooks cannot be compared or reloaded
+		}
+		if reflect.DeepEqual(current.Field(i).Interface(), updated.Field(i).Interface()) {
+			continue
+		}
+		if !hotReloadableFields[name] {
+			log.Printf("⚠️ Config field %s changed but is not hot-reloadable, restart the agent to apply it", name)
+			continue
+		}
+		changed = true
+	}
+	a.mu.RUnlock()
+
+	if !reflect.DeepEqual(a.GetCapabilities(), next.Capabilities) {
+		a.UpdateCapabilities(append([]string(nil), next.Capabilities...))
+	}
+
+	a.mu.Lock()
+	rateChanged := a.config.RateLimitPerMinute != next.RateLimitPerMinute
+	userRateChanged := a.config.RateLimitPerUserPerMinute != next.RateLimitPerUserPerMinute
+	a.config.RateLimitPerMinute = next.RateLimitPerMinute
+	a.config.RateLimitPerUserPerMinute = next.RateLimitPerUserPerMinute
+	a.mu.Unlock()
+
+	if rateChanged {
+		a.taskCoordinator.SetRateLimit(next.RateLimitPerMinute)
+	}
+	if userRateChanged {
+		a.taskCoordinator.SetUserRateLimit(next.RateLimitPerUserPerMinute)
+	}
+
+	if !changed {
+		log.Println("🔄 Config reloaded, no hot-reloadable changes")
+	}
+}
+
+// reloadFromEnv is the default reload source: the current config overlaid
+// with .env (when present) and the environment, as at startup
+func (a *EnhancedAgent) reloadFromEnv() (*Config, error) {
+	if err := godotenv.Overload(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read .env: %w", err)
+	}
+
+	a.mu.RLock()
+	next := *a.config
+	next.Capabilities = append([]string(nil), a.config.Capabilities...)
+	a.mu.RUnlock()
+	if err := next.LoadFromEnv(); err != nil {
+		return nil, err
+	}
+	return &next, nil
+}
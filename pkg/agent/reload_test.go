@@ -0,0 +1,226 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/auth"
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/health"
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/network"
+	"github.com/gorilla/websocket"
+)
+
+type echoHandler struct{}
+
+func (echoHandler) ProcessTask(ctx context.Context, task string) (string, error) {
+	return task, nil
+}
+
+// newConnectedAgent returns an agent connected to a WebSocket server that
+// counts the connections it accepts
+func newConnectedAgent(t *testing.T, config *Config) (*EnhancedAgent, *int32) {
+	t.Helper()
+	connections := new(int32)
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		atomic.AddInt32(connections, 1)
+		defer conn.Close()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	networkConfig := network.DefaultNetworkConfig()
+	networkConfig.WebSocketURL = "ws" + strings.TrimPrefix(srv.URL, "http")
+	client := network.NewNetworkClient(networkConfig)
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { client.Disconnect() })
+
+	protocol := network.NewProtocolHandler(client, nil, config.Name, config.Capabilities, "0xagent", "", "room")
+	return &EnhancedAgent{
+		config:          config,
+		agentHandler:    echoHandler{},
+		networkClient:   client,
+		protocolHandler: protocol,
+		taskCoordinator: network.NewTaskCoordinator(echoHandler{}, protocol, config.Capabilities),
+	}, connections
+}
+
+func TestEnhancedAgent_SIGHUPReloadsConfig(t *testing.T) {
+	config := DefaultConfig()
+	config.Capabilities = []string{"general"}
+	agent, connections := newConnectedAgent(t, config)
+
+	reloaded := *config
+	reloaded.Capabilities = []string{"general", "translation"}
+	reloaded.RateLimitPerMinute = 30
+	reloaded.WebSocketURL = "wss://elsewhere.example/ws" // not hot-reloadable
+	agent.reloadSource = func() (*Config, error) {
+		next := reloaded
+		return &next, nil
+	}
+
+	sigChan := make(chan os.Signal, 2)
+	done := make(chan struct{})
+	go func() {
+		agent.waitForShutdown(sigChan)
+		close(done)
+	}()
+
+	sigChan <- syscall.SIGHUP
+	sigChan <- syscall.SIGTERM
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("waitForShutdown() did not return after SIGTERM")
+	}
+
+	want := []string{"general", "translation"}
+	if !reflect.DeepEqual(agent.config.Capabilities, want) {
+		t.Errorf("capabilities = %v, want %v", agent.config.Capabilities, want)
+	}
+	if !agent.taskCoordinator.CanHandleCapability("translation") {
+		t.Error("task coordinator did not receive the new capabilities")
+	}
+	if agent.config.RateLimitPerMinute != 30 {
+		t.Errorf("rate limit = %d, want 30", agent.config.RateLimitPerMinute)
+	}
+	if agent.config.WebSocketURL == reloaded.WebSocketURL {
+		t.Error("non-reloadable WebSocketURL should be ignored")
+	}
+
+	if !agent.IsConnected() {
+		t.Error("reload should keep the connection up")
+	}
+	if n := atomic.LoadInt32(connections); n != 1 {
+		t.Errorf("server saw %d connections, want 1 (no reconnect)", n)
+	}
+}
+
+func TestEnhancedAgent_UpdateCapabilitiesConcurrently(t *testing.T) {
+	config := DefaultConfig()
+	config.Capabilities = []string{"general"}
+	authManager, err := auth.NewManager(testPrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := network.NewNetworkClient(network.DefaultNetworkConfig())
+	protocol := network.NewProtocolHandler(client, authManager, config.Name, config.Capabilities, authManager.GetAddress(), "", "room")
+	agent := &EnhancedAgent{
+		config:          config,
+		authManager:     authManager,
+		networkClient:   client,
+		protocolHandler: protocol,
+		taskCoordinator: network.NewTaskCoordinator(echoHandler{}, protocol, config.Capabilities),
+	}
+	agent.healthServer = health.NewServer(0, &health.AgentInfo{Name: config.Name, Capabilities: config.Capabilities}, agent)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				agent.GetCapabilities()
+				agent.taskCoordinator.CanHandleCapability("translation")
+				agent.protocolHandler.GetCapabilities()
+			}
+		}()
+	}
+
+	var writers sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		writers.Add(1)
+		go func(i int) {
+			defer writers.Done()
+			for j := 0; j < 50; j++ {
+				agent.UpdateCapabilities([]string{"general", fmt.Sprintf("cap-%d-%d", i, j)})
+			}
+		}(i)
+	}
+	writers.Wait()
+	close(stop)
+	wg.Wait()
+
+	agent.UpdateCapabilities([]string{"general", "translation"})
+	want := []string{"general", "translation"}
+	if got := agent.GetCapabilities(); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetCapabilities() = %v, want %v", got, want)
+	}
+	if got := agent.protocolHandler.GetCapabilities(); !reflect.DeepEqual(got, want) {
+		t.Errorf("protocol handler capabilities = %v, want %v", got, want)
+	}
+	if !agent.taskCoordinator.CanHandleCapability("translation") {
+		t.Error("task coordinator did not receive the final capabilities")
+	}
+}
+
+func TestEnhancedAgent_ApplyConfigConcurrently(t *testing.T) {
+	config := DefaultConfig()
+	config.Capabilities = []string{"general"}
+	protocol := network.NewProtocolHandler(network.NewNetworkClient(network.DefaultNetworkConfig()), nil, config.Name, config.Capabilities, "0xagent", "", "room")
+	agent := &EnhancedAgent{
+		config:          config,
+		protocolHandler: protocol,
+		taskCoordinator: network.NewTaskCoordinator(echoHandler{}, protocol, config.Capabilities),
+	}
+
+	stop := make(chan struct{})
+	var readers sync.WaitGroup
+	readers.Add(1)
+	go func() {
+		defer readers.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			agent.mu.RLock()
+			_ = agent.config.RateLimitPerMinute
+			_ = agent.config.RateLimitPerUserPerMinute
+			agent.mu.RUnlock()
+		}
+	}()
+
+	for i := 1; i <= 200; i++ {
+		next := *config
+		next.Capabilities = []string{"general"}
+		next.RateLimitPerMinute = i
+		next.RateLimitPerUserPerMinute = i
+		agent.applyConfig(&next)
+	}
+	close(stop)
+	readers.Wait()
+
+	agent.mu.RLock()
+	defer agent.mu.RUnlock()
+	if agent.config.RateLimitPerMinute != 200 || agent.config.RateLimitPerUserPerMinute != 200 {
+		t.Errorf("rate limits = %d/%d, want 200/200", agent.config.RateLimitPerMinute, agent.config.RateLimitPerUserPerMinute)
+	}
+}
@@ -3,7 +3,6 @@ package agent
 import (
 	"bytes"
 	"context"
-	"crypto/ecdsa"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -13,6 +12,7 @@ import (
 	"os/signal"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -23,7 +23,6 @@ import (
 	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/network"
 	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/nft"
 	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/types"
-	"github.com/ethereum/go-ethereum/crypto"
 )
 
 // EnhancedAgent represents a fully functional Teneo network agent with all capabilities
@@ -35,14 +34,31 @@ type EnhancedAgent struct {
 	protocolHandler *network.ProtocolHandler
 	taskCoordinator *network.TaskCoordinator
 	healthServer    *health.Server
+	localServer     *localTaskServer // nil unless LocalHTTPEnabled
 	agentCache      cache.AgentCache
 	backendURL      string
+	agentID         string // deployed or configured agent ID; empty derives it from the name
 	setPublicOnRun  bool
+	visibility      *bool // last visibility requested with SetVisibility, nil if never
+	authenticated   bool  // set on the first authentication, later ones are reconnects
 	running         bool
+	shuttingDown    int32 // atomic flag, set once Stop begins so readiness fails while draining
 	startTime       time.Time
 	mu              sync.RWMutex
 	ctx             context.Context
 	cancel          context.CancelFunc
+
+	// reconnectBackoff is shared by the initial connect loop and health-check reconnects
+	reconnectBackoff *network.Backoff
+	nextReconnectAt  time.Time
+
+	// reloadSource loads the configuration applied on SIGHUP
+	reloadSource func() (*Config, error)
+
+	// wallet checks the agent's wallet on-chain, nil without an RPC endpoint
+	wallet     *deploy.Minter
+	tokenID    uint64
+	walletInfo *health.WalletInfo // result of the last wallet check
 }
 
 // EnhancedAgentConfig represents configuration for the enhanced agent
@@ -60,8 +76,18 @@ type EnhancedAgentConfig struct {
 	StateFilePath string // Path to state file for Deploy (default: .teneo-deploy-state.json)
 
 	// Backend Configuration
-	BackendURL  string // Default from env or "http://localhost:8080"
-	RPCEndpoint string // Ethereum RPC endpoint
+	BackendURL  string       // Default from env or "http://localhost:8080"
+	RPCEndpoint string       // Ethereum RPC endpoint
+	HTTPClient  *http.Client // Client for deploy/mint backend requests (optional)
+	EIP712Auth  bool         // Sign backend auth challenges as EIP-712 typed data
+
+	// ReloadSource loads the configuration applied when the agent receives
+	// SIGHUP. Defaults to re-reading .env and the environment.
+	ReloadSource func() (*Config, error)
+
+	// ChainFactory connects WalletStatus to the NFT contract. Defaults to
+	// deploy.NewChainOps; tests can return a fake.
+	ChainFactory deploy.ChainFactory
 }
 
 // NewEnhancedAgent creates a new enhanced agent with network capabilities
@@ -76,6 +102,15 @@ func NewEnhancedAgent(config *EnhancedAgentConfig) (*EnhancedAgent, error) {
 		return nil, fmt.Errorf("agent handler is required")
 	}
 
+	if err := resolveWebSocketURL(config); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	room, err := normalizeRoom(config.Config.Room)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	config.Config.Room = room
+
 	// Set default backend URL if not provided
 	if config.BackendURL == "" {
 		if backendURL := os.Getenv("BACKEND_URL"); backendURL != "" {
@@ -98,17 +133,19 @@ func NewEnhancedAgent(config *EnhancedAgentConfig) (*EnhancedAgent, error) {
 		}
 	}
 
+	// Generate the agent ID from the name if not provided, so every flow
+	// below uses the same ID. Only a generated ID that is taken by another
+	// wallet gets a suffix on deploy instead of failing it.
+	generatedAgentID := config.AgentID == ""
+	if generatedAgentID {
+		config.AgentID = generateAgentID(config.Config.Name)
+	}
+
 	// Handle NFT deployment/minting
 	if config.Deploy {
 		// Use the new secure deploy flow with authentication and database persistence
 		log.Printf("🚀 Deploying agent using secure SDK flow: %s", config.Config.Name)
 
-		// Generate agent ID from name if not provided
-		agentID := config.AgentID
-		if agentID == "" {
-			agentID = generateAgentID(config.Config.Name)
-		}
-
 		// Build capabilities JSON
 		capabilitiesJSON, err := buildCapabilitiesJSON(config.Config.Capabilities)
 		if err != nil {
@@ -117,17 +154,20 @@ func NewEnhancedAgent(config *EnhancedAgentConfig) (*EnhancedAgent, error) {
 
 		// Create deploy configuration
 		deployCfg := deploy.DeployConfig{
-			BackendURL:      config.BackendURL,
-			RPCEndpoint:     config.RPCEndpoint,
-			PrivateKey:      config.Config.PrivateKey,
-			AgentID:         agentID,
-			AgentName:       config.Config.Name,
-			Description:     config.Config.Description,
-			Image:           config.Config.Image,
-			AgentType:       "command", // Default to command type
-			Capabilities:    capabilitiesJSON,
-			StateFilePath:   config.StateFilePath,
-			MetadataVersion: "2.3.0",
+			BackendURL:              config.BackendURL,
+			RPCEndpoint:             config.RPCEndpoint,
+			HTTPClient:              config.HTTPClient,
+			PrivateKey:              config.Config.PrivateKey,
+			EIP712Auth:              config.EIP712Auth,
+			AgentID:                 config.AgentID,
+			SuffixAgentIDOnConflict: generatedAgentID,
+			AgentName:               config.Config.Name,
+			Description:             config.Config.Description,
+			Image:                   config.Config.Image,
+			AgentType:               "command", // Default to command type
+			Capabilities:            capabilitiesJSON,
+			StateFilePath:           config.StateFilePath,
+			MetadataVersion:         "2.3.0",
 		}
 
 		// Execute deployment
@@ -137,6 +177,7 @@ func NewEnhancedAgent(config *EnhancedAgentConfig) (*EnhancedAgent, error) {
 		}
 
 		config.TokenID = result.TokenID
+		config.AgentID = result.AgentID
 		if result.AlreadyMinted {
 			log.Printf("✅ Agent was already deployed - Token ID: %d", result.TokenID)
 		} else {
@@ -149,21 +190,18 @@ func NewEnhancedAgent(config *EnhancedAgentConfig) (*EnhancedAgent, error) {
 	} else if config.Mint {
 		// Use legacy mint flow (no database persistence)
 		// Create NFT minter
-		minter, err := nft.NewNFTMinter(config.BackendURL, config.RPCEndpoint, config.Config.PrivateKey)
+		minter, err := nft.NewNFTMinter(config.BackendURL, config.RPCEndpoint, config.Config.PrivateKey, nft.WithHTTPClient(config.HTTPClient), nft.WithEIP712Signing(config.EIP712Auth))
 		if err != nil {
 			return nil, fmt.Errorf("failed to create NFT minter: %w", err)
 		}
 
-		// Generate agent ID from name
-		agentID := generateAgentID(config.Config.Name)
-
 		// Prepare metadata
 		metadata := nft.AgentMetadata{
 			Name:         config.Config.Name,
 			Description:  config.Config.Description,
 			Image:        config.Config.Image,
 			Capabilities: config.Config.Capabilities,
-			AgentID:      agentID,
+			AgentID:      config.AgentID,
 		}
 
 		log.Printf("🎨 Minting NFT for agent (legacy flow): %s", config.Config.Name)
@@ -203,14 +241,14 @@ func NewEnhancedAgent(config *EnhancedAgentConfig) (*EnhancedAgent, error) {
 			Description:  config.Config.Description,
 			Image:        config.Config.Image,
 			Capabilities: config.Config.Capabilities,
-			AgentID:      generateAgentID(config.Config.Name),
+			AgentID:      config.AgentID,
 		}
 
 		hash := nft.GenerateMetadataHash(metadata)
 		log.Printf("📋 Using existing NFT token ID: %d with metadata hash: %s", config.TokenID, hash)
 
 		// Send metadata hash to backend
-		minter, err := nft.NewNFTMinter(config.BackendURL, config.RPCEndpoint, config.Config.PrivateKey)
+		minter, err := nft.NewNFTMinter(config.BackendURL, config.RPCEndpoint, config.Config.PrivateKey, nft.WithHTTPClient(config.HTTPClient), nft.WithEIP712Signing(config.EIP712Auth))
 		if err != nil {
 			return nil, fmt.Errorf("failed to create NFT minter: %w", err)
 		}
@@ -239,6 +277,12 @@ func NewEnhancedAgent(config *EnhancedAgentConfig) (*EnhancedAgent, error) {
 		backendURL:   config.BackendURL,
 		ctx:          ctx,
 		cancel:       cancel,
+		reloadSource: config.ReloadSource,
+		tokenID:      config.TokenID,
+		agentID:      config.AgentID,
+	}
+	if agent.reloadSource == nil {
+		agent.reloadSource = agent.reloadFromEnv
 	}
 
 	// Initialize authentication manager
@@ -249,6 +293,21 @@ func NewEnhancedAgent(config *EnhancedAgentConfig) (*EnhancedAgent, error) {
 	}
 	agent.authManager = authManager
 
+	if config.RPCEndpoint != "" {
+		agent.wallet, err = deploy.NewMinter(&deploy.MintConfig{
+			PrivateKey:   config.Config.PrivateKey,
+			BackendURL:   config.BackendURL,
+			RPCEndpoint:  config.RPCEndpoint,
+			HTTPClient:   config.HTTPClient,
+			EIP712Auth:   config.EIP712Auth,
+			ChainFactory: config.ChainFactory,
+		})
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to create wallet client: %w", err)
+		}
+	}
+
 	// Initialize network client
 	networkConfig := &network.Config{
 		WebSocketURL:     config.Config.WebSocketURL,
@@ -258,8 +317,22 @@ func NewEnhancedAgent(config *EnhancedAgentConfig) (*EnhancedAgent, error) {
 		MessageTimeout:   config.Config.MessageTimeout,
 		PingInterval:     config.Config.PingInterval,
 		HandshakeTimeout: config.Config.HandshakeTimeout,
+		PongTimeout:      config.Config.PongTimeout,
+		MaxMissedPongs:   config.Config.MaxMissedPongs,
+
+		ReconnectBaseDelay:  config.Config.ReconnectBaseDelay,
+		ReconnectMaxDelay:   config.Config.ReconnectMaxDelay,
+		ReconnectMultiplier: config.Config.ReconnectMultiplier,
+
+		EnableCompression: config.Config.EnableCompression,
+		OnStateChange:     agent.onConnectionStateChange,
 	}
 	agent.networkClient = network.NewNetworkClient(networkConfig)
+	agent.reconnectBackoff = network.NewBackoff(
+		config.Config.ReconnectBaseDelay,
+		config.Config.ReconnectMaxDelay,
+		config.Config.ReconnectMultiplier,
+	)
 
 	// Initialize protocol handler
 	agent.protocolHandler = network.NewProtocolHandler(
@@ -283,6 +356,41 @@ func NewEnhancedAgent(config *EnhancedAgentConfig) (*EnhancedAgent, error) {
 	if config.Config.RateLimitPerMinute > 0 {
 		agent.taskCoordinator.SetRateLimit(config.Config.RateLimitPerMinute)
 	}
+	if config.Config.RateLimitPerUserPerMinute > 0 {
+		agent.taskCoordinator.SetUserRateLimit(config.Config.RateLimitPerUserPerMinute)
+	}
+
+	// Report failed tasks to the dead-letter hook if configured
+	if config.Config.OnTaskFailure != nil {
+		agent.taskCoordinator.SetTaskFailureHandler(config.Config.OnTaskFailure)
+	}
+
+	// Bound concurrent and queued tasks if configured
+	if config.Config.MaxConcurrentTasks > 0 {
+		agent.taskCoordinator.SetMaxConcurrentTasks(config.Config.MaxConcurrentTasks)
+	}
+	if config.Config.MaxQueuedTasks > 0 {
+		policy := network.QueuePolicyReject
+		if config.Config.QueueFullPolicy == QueueFullPolicyBlock {
+			policy = network.QueuePolicyBlock
+		}
+		agent.taskCoordinator.SetTaskQueue(config.Config.MaxQueuedTasks, policy)
+	}
+
+	// Let the agent interpret conversational input if configured
+	if config.Config.NLPFallback {
+		agent.taskCoordinator.SetNLPFallback(true)
+	}
+
+	// Split or reject oversized responses if configured
+	if config.Config.MaxMessageBytes > 0 {
+		agent.taskCoordinator.SetMaxMessageBytes(config.Config.MaxMessageBytes)
+	}
+
+	// Coalesce rapid streaming updates if configured
+	if config.Config.TaskUpdateCoalesceWindow > 0 {
+		agent.taskCoordinator.SetUpdateCoalesceWindow(config.Config.TaskUpdateCoalesceWindow)
+	}
 
 	// Initialize Redis cache if enabled
 	if config.Config.RedisEnabled {
@@ -294,14 +402,13 @@ func NewEnhancedAgent(config *EnhancedAgentConfig) (*EnhancedAgent, error) {
 			keyPrefix = fmt.Sprintf("teneo:agent:%s:", strings.ReplaceAll(strings.ToLower(config.Config.Name), " ", "_"))
 		}
 
-		redisConfig := &cache.RedisConfig{
-			Address:   config.Config.RedisAddress,
-			Username:  config.Config.RedisUsername,
-			Password:  config.Config.RedisPassword,
-			DB:        config.Config.RedisDB,
-			KeyPrefix: keyPrefix,
-			UseTLS:    config.Config.RedisUseTLS,
-		}
+		redisConfig := cache.DefaultRedisConfig()
+		redisConfig.Address = config.Config.RedisAddress
+		redisConfig.Username = config.Config.RedisUsername
+		redisConfig.Password = config.Config.RedisPassword
+		redisConfig.DB = config.Config.RedisDB
+		redisConfig.KeyPrefix = keyPrefix
+		redisConfig.UseTLS = config.Config.RedisUseTLS
 
 		redisCache, err := cache.NewRedisCache(redisConfig)
 		if err != nil {
@@ -334,6 +441,10 @@ func NewEnhancedAgent(config *EnhancedAgentConfig) (*EnhancedAgent, error) {
 		)
 	}
 
+	if config.Config.LocalHTTPEnabled {
+		agent.localServer = newLocalTaskServer(config.Config.LocalHTTPAddr, agent.taskCoordinator)
+	}
+
 	return agent, nil
 }
 
@@ -348,6 +459,7 @@ func (a *EnhancedAgent) Start() error {
 
 	a.startTime = time.Now()
 	a.running = true
+	atomic.StoreInt32(&a.shuttingDown, 0)
 
 	log.Printf("🚀 Starting enhanced agent: %s v%s", a.config.Name, a.config.Version)
 	log.Printf("💼 Wallet: %s", a.authManager.GetAddress())
@@ -371,6 +483,15 @@ func (a *EnhancedAgent) Start() error {
 		}()
 	}
 
+	// Start local task endpoint if enabled
+	if a.localServer != nil {
+		go func() {
+			if err := a.localServer.Start(); err != nil {
+				log.Printf("❌ Local task endpoint error: %v", err)
+			}
+		}()
+	}
+
 	// Connect to network with retry logic
 	connectRetries := 3
 	var connectErr error
@@ -379,10 +500,11 @@ func (a *EnhancedAgent) Start() error {
 			connectErr = err
 			log.Printf("⚠️ Connection attempt %d/%d failed: %v", i+1, connectRetries, err)
 			if i < connectRetries-1 {
-				time.Sleep(time.Duration(i+1) * 2 * time.Second)
+				time.Sleep(a.reconnectBackoff.Next())
 			}
 		} else {
 			connectErr = nil
+			a.reconnectBackoff.Reset()
 			break
 		}
 	}
@@ -415,12 +537,18 @@ func (a *EnhancedAgent) Start() error {
 	// Start periodic tasks
 	go a.startPeriodicTasks()
 
+	// Let agents that find their own work pull tasks
+	a.taskCoordinator.StartTaskProvider(a.ctx, a.config.TaskProviderInterval)
+
 	log.Printf("✅ Enhanced agent %s started successfully", a.config.Name)
 	return nil
 }
 
 // Stop gracefully stops the enhanced agent
 func (a *EnhancedAgent) Stop() error {
+	// Set before taking the lock so readiness probes answered during shutdown fail
+	atomic.StoreInt32(&a.shuttingDown, 1)
+
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
@@ -443,6 +571,13 @@ func (a *EnhancedAgent) Stop() error {
 		}
 	}
 
+	// Stop local task endpoint
+	if a.localServer != nil {
+		if err := a.localServer.Stop(); err != nil {
+			log.Printf("⚠️ Error stopping local task endpoint: %v", err)
+		}
+	}
+
 	// Disconnect from network
 	if err := a.networkClient.Disconnect(); err != nil {
 		log.Printf("⚠️ Error disconnecting from network: %v", err)
@@ -480,20 +615,44 @@ func (a *EnhancedAgent) Run() error {
 		}
 	}
 
-	// Wait for interrupt signal
+	// Wait for interrupt signal, reloading config on SIGHUP
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(sigChan)
 
-	<-sigChan
+	a.waitForShutdown(sigChan)
 	log.Println("📡 Received interrupt signal")
 
 	return a.Stop()
 }
 
+// waitForShutdown blocks until a signal other than SIGHUP arrives, reloading
+// the configuration on each SIGHUP
+func (a *EnhancedAgent) waitForShutdown(sigChan <-chan os.Signal) {
+	for sig := range sigChan {
+		if sig != syscall.SIGHUP {
+			return
+		}
+		log.Println("📡 Received SIGHUP, reloading config...")
+		if err := a.ReloadConfig(); err != nil {
+			log.Printf("⚠️ Config reload failed: %v", err)
+		}
+	}
+}
+
 // SetVisibility updates the agent's public/private visibility on the Teneo network.
 // Requires the agent to have been deployed and connected at least once.
+// With Config.RestoreVisibility the requested visibility is applied again
+// each time the agent re-authenticates, even if this request failed.
 func (a *EnhancedAgent) SetVisibility(public bool) error {
-	agentID := generateAgentID(a.config.Name)
+	a.mu.Lock()
+	a.visibility = &public
+	a.mu.Unlock()
+
+	agentID := a.agentID
+	if agentID == "" {
+		agentID = generateAgentID(a.config.Name)
+	}
 	walletAddress := a.authManager.GetAddress()
 
 	reqBody, err := json.Marshal(map[string]interface{}{
@@ -531,6 +690,45 @@ func (a *EnhancedAgent) SetVisibility(public bool) error {
 	return nil
 }
 
+// onConnectionStateChange restores the requested visibility once the agent
+// is authenticated again after a reconnect and passes the change on to
+// Config.OnConnectionStateChange
+func (a *EnhancedAgent) onConnectionStateChange(old, new network.ConnectionState) {
+	if new == network.StateAuthenticated {
+		a.mu.Lock()
+		reconnected := a.authenticated
+		a.authenticated = true
+		a.mu.Unlock()
+		if reconnected {
+			a.restoreVisibility()
+		}
+	}
+
+	a.mu.RLock()
+	onChange := a.config.OnConnectionStateChange
+	a.mu.RUnlock()
+	if onChange != nil {
+		onChange(old, new)
+	}
+}
+
+// restoreVisibility re-applies the visibility last requested with
+// SetVisibility, since a re-registered agent may otherwise go private
+func (a *EnhancedAgent) restoreVisibility() {
+	a.mu.RLock()
+	visibility := a.visibility
+	restore := a.config.RestoreVisibility
+	a.mu.RUnlock()
+
+	if visibility == nil || !restore {
+		return
+	}
+	log.Printf("🔁 Re-applying agent visibility after re-authentication")
+	if err := a.SetVisibility(*visibility); err != nil {
+		log.Printf("⚠️ Failed to restore agent visibility: %v", err)
+	}
+}
+
 // startPeriodicTasks starts periodic maintenance tasks
 func (a *EnhancedAgent) startPeriodicTasks() {
 	// Send periodic pings
@@ -545,6 +743,8 @@ func (a *EnhancedAgent) startPeriodicTasks() {
 	statusTicker := time.NewTicker(5 * time.Minute)
 	defer statusTicker.Stop()
 
+	go a.checkWallet()
+
 	for {
 		select {
 		case <-a.ctx.Done():
@@ -561,6 +761,7 @@ func (a *EnhancedAgent) startPeriodicTasks() {
 		case <-statusTicker.C:
 			// Log status
 			a.logStatus()
+			a.checkWallet()
 		}
 	}
 }
@@ -568,9 +769,25 @@ func (a *EnhancedAgent) startPeriodicTasks() {
 // performHealthCheck performs periodic health checks
 func (a *EnhancedAgent) performHealthCheck() {
 	if !a.networkClient.IsConnected() {
-		log.Printf("⚠️ Network disconnected, attempting reconnection...")
-		if err := a.networkClient.Connect(); err != nil {
-			log.Printf("❌ Reconnection failed: %v", err)
+		a.mu.Lock()
+		due := !time.Now().Before(a.nextReconnectAt)
+		a.mu.Unlock()
+
+		// Skip this tick while still backing off from a failed attempt
+		if due {
+			log.Printf("⚠️ Network disconnected, attempting reconnection...")
+			if err := a.networkClient.Connect(); err != nil {
+				delay := a.reconnectBackoff.Next()
+				log.Printf("❌ Reconnection failed: %v (next attempt in %v)", err, delay)
+				a.mu.Lock()
+				a.nextReconnectAt = time.Now().Add(delay)
+				a.mu.Unlock()
+			} else {
+				a.reconnectBackoff.Reset()
+				a.mu.Lock()
+				a.nextReconnectAt = time.Time{}
+				a.mu.Unlock()
+			}
 		}
 	}
 
@@ -585,12 +802,14 @@ func (a *EnhancedAgent) performHealthCheck() {
 // logStatus logs the current agent status
 func (a *EnhancedAgent) logStatus() {
 	activeTasks := a.taskCoordinator.GetActiveTaskCount()
+	queuedTasks := a.taskCoordinator.GetQueuedTaskCount()
 	uptime := time.Since(a.startTime)
 
-	log.Printf("📊 Status - Connected: %v, Authenticated: %v, Active Tasks: %d, Uptime: %v",
+	log.Printf("📊 Status - Connected: %v, Authenticated: %v, Active Tasks: %d, Queued Tasks: %d, Uptime: %v",
 		a.networkClient.IsConnected(),
 		a.networkClient.IsAuthenticated(),
 		activeTasks,
+		queuedTasks,
 		uptime.Round(time.Second),
 	)
 }
@@ -605,11 +824,51 @@ func (a *EnhancedAgent) IsAuthenticated() bool {
 	return a.networkClient.IsAuthenticated()
 }
 
+// Pause stops accepting new tasks while staying connected and visible. New
+// tasks are answered with a "temporarily unavailable" error; active tasks finish.
+func (a *EnhancedAgent) Pause() {
+	a.taskCoordinator.Pause()
+}
+
+// Resume accepts new tasks again after Pause
+func (a *EnhancedAgent) Resume() {
+	a.taskCoordinator.Resume()
+}
+
+// IsPaused implements the health.PauseReporter interface
+func (a *EnhancedAgent) IsPaused() bool {
+	return a.taskCoordinator.IsPaused()
+}
+
+// IsShuttingDown implements the health.ShutdownReporter interface
+func (a *EnhancedAgent) IsShuttingDown() bool {
+	return atomic.LoadInt32(&a.shuttingDown) == 1
+}
+
+// RegisterHealthCheck adds a custom readiness check to the health server.
+// It has no effect when health monitoring is disabled.
+func (a *EnhancedAgent) RegisterHealthCheck(name string, check health.CheckFunc) {
+	if a.healthServer != nil {
+		a.healthServer.RegisterCheck(name, check)
+	}
+}
+
+// GetMetrics returns task metrics such as success rate and average response
+// time. It implements the health.MetricsReporter interface.
+func (a *EnhancedAgent) GetMetrics() types.AgentMetrics {
+	return a.taskCoordinator.GetMetrics()
+}
+
 // GetActiveTaskCount implements the health.StatusGetter interface
 func (a *EnhancedAgent) GetActiveTaskCount() int {
 	return a.taskCoordinator.GetActiveTaskCount()
 }
 
+// GetQueuedTaskCount implements the health.QueueReporter interface
+func (a *EnhancedAgent) GetQueuedTaskCount() int {
+	return a.taskCoordinator.GetQueuedTaskCount()
+}
+
 // GetUptime implements the health.StatusGetter interface
 func (a *EnhancedAgent) GetUptime() time.Duration {
 	a.mu.RLock()
@@ -655,8 +914,16 @@ func (a *EnhancedAgent) IsRunning() bool {
 	return a.running
 }
 
-// UpdateCapabilities updates the agent's capabilities at runtime
+// UpdateCapabilities updates the agent's capabilities at runtime. It is safe
+// to call while the agent runs. The new list is also sent to the backend so
+// discovery reflects it, right away when authenticated and otherwise after
+// the next registration.
 func (a *EnhancedAgent) UpdateCapabilities(capabilities []string) {
+	capabilities = append([]string(nil), capabilities...)
+
+	// Holding the lock until every component is updated keeps concurrent
+	// updates from leaving them with different lists
+	a.mu.Lock()
 	a.config.Capabilities = capabilities
 	a.taskCoordinator.UpdateCapabilities(capabilities)
 
@@ -670,40 +937,53 @@ func (a *EnhancedAgent) UpdateCapabilities(capabilities []string) {
 		}
 		a.healthServer.UpdateAgentInfo(agentInfo)
 	}
+	a.mu.Unlock()
 
 	log.Printf("🔄 Updated capabilities: %v", capabilities)
+
+	// Sent without the lock so a slow connection does not block other agent
+	// calls. The protocol handler sends its current list, so the last push
+	// carries the latest update even when pushes overlap.
+	if err := a.protocolHandler.PushCapabilities(); err != nil {
+		log.Printf("⚠️ Failed to send updated capabilities: %v", err)
+	}
 }
 
-// generateAgentID generates a unique agent ID from the agent name
-func generateAgentID(name string) string {
-	// Convert to lowercase and replace spaces with hyphens
-	agentID := strings.ToLower(name)
-	agentID = strings.ReplaceAll(agentID, " ", "-")
-	// Remove any characters that aren't lowercase letters, numbers, or hyphens
-	result := ""
-	for _, char := range agentID {
-		if (char >= 'a' && char <= 'z') || (char >= '0' && char <= '9') || char == '-' {
-			result += string(char)
+// GetCapabilities returns a copy of the agent's current capabilities
+func (a *EnhancedAgent) GetCapabilities() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return append([]string(nil), a.config.Capabilities...)
+}
+
+// resolveWebSocketURL derives the WebSocket URL from the backend URL (or
+// BACKEND_URL) when only that is set, then validates it so a bad URL fails
+// here instead of as a dial error in Connect
+func resolveWebSocketURL(config *EnhancedAgentConfig) error {
+	if config.Config.WebSocketURL == "" {
+		backendURL := config.BackendURL
+		if backendURL == "" {
+			backendURL = os.Getenv("BACKEND_URL")
+		}
+		if backendURL != "" {
+			config.Config.WebSocketURL = deriveWebSocketURL(backendURL)
 		}
 	}
-	return result
+	return validateWebSocketURL(config.Config.WebSocketURL)
+}
+
+// generateAgentID generates a unique agent ID from the agent name, see
+// deploy.GenerateAgentID
+func generateAgentID(name string) string {
+	return deploy.GenerateAgentID(name)
 }
 
 // getAddressFromPrivateKey derives the Ethereum address from a private key
 func getAddressFromPrivateKey(privateKeyHex string) string {
-	// Import crypto package
-	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(privateKeyHex, "0x"))
+	_, address, err := auth.ParsePrivateKey(privateKeyHex)
 	if err != nil {
 		return ""
 	}
-
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		return ""
-	}
-
-	address := crypto.PubkeyToAddress(*publicKeyECDSA)
 	return address.Hex()
 }
 
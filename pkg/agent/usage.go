@@ -0,0 +1,76 @@
+package agent
+
+import (
+	"strings"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/deploy"
+)
+
+// GenerateUsage builds a usage string for a command from its trigger and
+// parameter schema. Required parameters are shown in <>, optional ones in [].
+//
+// Example output:
+//
+//	analyze <contract> [limit]
+//	Analyzes a token contract
+//	  <contract> (string) - Token contract address
+//	  [limit] (integer) - Number of wallets to return
+func GenerateUsage(command deploy.Command) string {
+	var b strings.Builder
+	b.WriteString(usageLine(command))
+
+	if command.Description != "" {
+		b.WriteString("\n")
+		b.WriteString(command.Description)
+	}
+
+	for _, p := range command.Parameters {
+		b.WriteString("\n  ")
+		b.WriteString(parameterPlaceholder(p))
+		if p.Type != "" {
+			b.WriteString(" (" + p.Type + ")")
+		}
+		if p.Description != "" {
+			b.WriteString(" - " + p.Description)
+		}
+	}
+
+	return b.String()
+}
+
+// GenerateHelp lists every command with its usage line and description,
+// suitable for replying to "help" or an unrecognised command.
+func GenerateHelp(commands []deploy.Command) string {
+	if len(commands) == 0 {
+		return "No commands available."
+	}
+
+	var b strings.Builder
+	b.WriteString("Available commands:")
+	for _, cmd := range commands {
+		b.WriteString("\n  ")
+		b.WriteString(usageLine(cmd))
+		if cmd.Description != "" {
+			b.WriteString(" - " + cmd.Description)
+		}
+	}
+	return b.String()
+}
+
+// usageLine renders the trigger followed by parameter placeholders
+func usageLine(command deploy.Command) string {
+	parts := make([]string, 0, len(command.Parameters)+1)
+	parts = append(parts, command.Trigger)
+	for _, p := range command.Parameters {
+		parts = append(parts, parameterPlaceholder(p))
+	}
+	return strings.Join(parts, " ")
+}
+
+// parameterPlaceholder wraps a parameter name in <> if required, [] otherwise
+func parameterPlaceholder(p deploy.CommandParameter) string {
+	if p.Required {
+		return "<" + p.Name + ">"
+	}
+	return "[" + p.Name + "]"
+}
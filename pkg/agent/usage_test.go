@@ -0,0 +1,77 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/deploy"
+)
+
+func TestGenerateUsage(t *testing.T) {
+	tests := []struct {
+		name    string
+		command deploy.Command
+		want    string
+	}{
+		{
+			name:    "no parameters",
+			command: deploy.Command{Trigger: "ping"},
+			want:    "ping",
+		},
+		{
+			name: "required and optional parameters",
+			command: deploy.Command{
+				Trigger:     "analyze",
+				Description: "Analyzes a token contract",
+				Parameters: []deploy.CommandParameter{
+					{Name: "contract", Type: "string", Required: true, Description: "Token contract address"},
+					{Name: "limit", Type: "integer", Description: "Number of wallets to return"},
+				},
+			},
+			want: "analyze <contract> [limit]\n" +
+				"Analyzes a token contract\n" +
+				"  <contract> (string) - Token contract address\n" +
+				"  [limit] (integer) - Number of wallets to return",
+		},
+		{
+			name: "parameter without type or description",
+			command: deploy.Command{
+				Trigger:    "echo",
+				Parameters: []deploy.CommandParameter{{Name: "message", Required: true}},
+			},
+			want: "echo <message>\n  <message>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GenerateUsage(tt.command); got != tt.want {
+				t.Errorf("GenerateUsage() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateHelp(t *testing.T) {
+	commands := []deploy.Command{
+		{
+			Trigger:     "analyze",
+			Description: "Analyzes a token",
+			Parameters: []deploy.CommandParameter{
+				{Name: "contract", Type: "string", Required: true},
+				{Name: "limit", Type: "integer"},
+			},
+		},
+		{Trigger: "help"},
+	}
+
+	want := "Available commands:\n" +
+		"  analyze <contract> [limit] - Analyzes a token\n" +
+		"  help"
+	if got := GenerateHelp(commands); got != want {
+		t.Errorf("GenerateHelp() =\n%s\nwant:\n%s", got, want)
+	}
+
+	if got := GenerateHelp(nil); got != "No commands available." {
+		t.Errorf("GenerateHelp(nil) = %q", got)
+	}
+}
@@ -50,9 +50,15 @@ import (
 //
 // The agent ID is derived from the agent name: lowercased, spaces replaced with hyphens,
 // non-alphanumeric characters removed. For example "Interior Architecture Advisor" becomes
-// "interior-architecture-advisor".
+// "interior-architecture-advisor". An agent deployed with an explicit AgentID, or whose
+// generated ID got a suffix because it was taken, must use UpdateAgentVisibilityByID.
 func UpdateAgentVisibility(backendURL, agentName, creatorWallet string, public bool) error {
-	agentID := generateAgentID(agentName)
+	return UpdateAgentVisibilityByID(backendURL, generateAgentID(agentName), creatorWallet, public)
+}
+
+// UpdateAgentVisibilityByID is UpdateAgentVisibility for a known agent ID, such as
+// the AgentID of a deploy result or of EnhancedAgentConfig after NewEnhancedAgent
+func UpdateAgentVisibilityByID(backendURL, agentID, creatorWallet string, public bool) error {
 	backendURL = strings.TrimRight(backendURL, "/")
 
 	reqBody, err := json.Marshal(map[string]interface{}{
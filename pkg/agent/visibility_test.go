@@ -0,0 +1,186 @@
+package agent
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/auth"
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/network"
+	"github.com/gorilla/websocket"
+)
+
+// Hardhat's first default account
+const testPrivateKey = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+
+// newReconnectingAgent returns an agent whose WebSocket server hands each
+// connection to the test, which closes it to simulate a dropped connection,
+// and whose backend reports every visibility request
+func newReconnectingAgent(t *testing.T, config *Config) (*EnhancedAgent, chan *websocket.Conn, chan bool) {
+	t.Helper()
+	conns := make(chan *websocket.Conn, 10)
+	upgrader := websocket.Upgrader{}
+	wsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		conns <- conn
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(wsServer.Close)
+
+	visibility := make(chan bool, 10)
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			IsPublic bool `json:"is_public"`
+		}
+		if r.URL.Path != "/api/agents/visibility-agent/visibility" || json.NewDecoder(r.Body).Decode(&req) != nil {
+			http.Error(w, `{"error":"bad request"}`, http.StatusBadRequest)
+			return
+		}
+		visibility <- req.IsPublic
+	}))
+	t.Cleanup(backend.Close)
+
+	authManager, err := auth.NewManager(testPrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agent := &EnhancedAgent{config: config, authManager: authManager, backendURL: backend.URL}
+
+	networkConfig := network.DefaultNetworkConfig()
+	networkConfig.WebSocketURL = "ws" + strings.TrimPrefix(wsServer.URL, "http")
+	networkConfig.ReconnectBaseDelay = 10 * time.Millisecond
+	networkConfig.ReconnectMaxDelay = 10 * time.Millisecond
+	networkConfig.OnStateChange = agent.onConnectionStateChange
+	agent.networkClient = network.NewNetworkClient(networkConfig)
+	if err := agent.networkClient.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { agent.networkClient.Disconnect() })
+
+	return agent, conns, visibility
+}
+
+func TestEnhancedAgent_RestoresVisibilityAfterReconnect(t *testing.T) {
+	tests := []struct {
+		name        string
+		restore     bool
+		wantRestore bool
+	}{
+		{"restore enabled", true, true},
+		{"restore disabled", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Name = "Visibility Agent"
+			config.RestoreVisibility = tt.restore
+			agent, conns, visibility := newReconnectingAgent(t, config)
+			agent.networkClient.SetAuthenticated(true)
+
+			if err := agent.SetVisibility(true); err != nil {
+				t.Fatalf("SetVisibility() error = %v", err)
+			}
+			if !<-visibility {
+				t.Fatal("SetVisibility(true) requested private")
+			}
+
+			// Drop the connection; once the client reconnects the agent
+			// authenticates again
+			(<-conns).Close()
+			<-conns
+			waitForState(t, agent.networkClient, network.StateConnected)
+			agent.networkClient.SetAuthenticated(true)
+
+			select {
+			case public := <-visibility:
+				if !tt.wantRestore {
+					t.Fatal("visibility was re-applied with RestoreVisibility disabled")
+				}
+				if !public {
+					t.Error("visibility restored as private, want public")
+				}
+			case <-time.After(500 * time.Millisecond):
+				if tt.wantRestore {
+					t.Fatal("visibility was not re-applied after reconnect")
+				}
+			}
+		})
+	}
+}
+
+func TestEnhancedAgent_NoVisibilityRestoreWithoutRequest(t *testing.T) {
+	config := DefaultConfig()
+	config.Name = "Visibility Agent"
+	agent, _, visibility := newReconnectingAgent(t, config)
+
+	agent.networkClient.SetAuthenticated(true)
+	select {
+	case <-visibility:
+		t.Fatal("visibility was set although SetVisibility was never called")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestEnhancedAgent_NoVisibilityRestoreOnFirstAuthentication(t *testing.T) {
+	config := DefaultConfig()
+	config.Name = "Visibility Agent"
+	agent, _, visibility := newReconnectingAgent(t, config)
+
+	if err := agent.SetVisibility(true); err != nil {
+		t.Fatalf("SetVisibility() error = %v", err)
+	}
+	<-visibility
+
+	agent.networkClient.SetAuthenticated(true)
+	select {
+	case <-visibility:
+		t.Fatal("visibility was re-applied on the first authentication")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// waitForState waits until client reaches state
+func waitForState(t *testing.T, client *network.NetworkClient, state network.ConnectionState) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for client.State() != state {
+		if time.Now().After(deadline) {
+			t.Fatalf("State() = %v, want %v", client.State(), state)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestUpdateAgentVisibility_AgentID(t *testing.T) {
+	paths := make(chan string, 2)
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths <- r.URL.Path
+	}))
+	defer backend.Close()
+
+	if err := UpdateAgentVisibility(backend.URL, "Visibility Agent", "0xwallet", true); err != nil {
+		t.Fatalf("UpdateAgentVisibility() error = %v", err)
+	}
+	if got := <-paths; got != "/api/agents/visibility-agent/visibility" {
+		t.Errorf("UpdateAgentVisibility() path = %q, want the ID derived from the name", got)
+	}
+
+	// A suffixed or configured ID cannot be derived from the name
+	if err := UpdateAgentVisibilityByID(backend.URL, "visibility-agent-3f2a", "0xwallet", true); err != nil {
+		t.Fatalf("UpdateAgentVisibilityByID() error = %v", err)
+	}
+	if got := <-paths; got != "/api/agents/visibility-agent-3f2a/visibility" {
+		t.Errorf("UpdateAgentVisibilityByID() path = %q, want the given ID", got)
+	}
+}
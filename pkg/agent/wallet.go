@@ -0,0 +1,77 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/deploy"
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/health"
+)
+
+// walletCheckTimeout bounds the periodic on-chain wallet check
+const walletCheckTimeout = 30 * time.Second
+
+// WalletStatus checks on-chain that the agent's wallet still owns its agent
+// NFT and holds enough native tokens for gas. The result is also reported in
+// the health server's /status. It requires an RPC endpoint.
+func (a *EnhancedAgent) WalletStatus(ctx context.Context) (*deploy.WalletStatus, error) {
+	if a.wallet == nil {
+		return nil, fmt.Errorf("RPC endpoint is required to check the wallet")
+	}
+
+	status, err := a.wallet.WalletStatus(ctx, a.tokenID)
+	info := &health.WalletInfo{
+		Address:   a.authManager.GetAddress(),
+		TokenID:   a.tokenID,
+		CheckedAt: time.Now(),
+	}
+	if err != nil {
+		info.Error = err.Error()
+	} else {
+		info.Address = status.Wallet
+		info.Balance = status.FormattedBalance()
+		info.HasGas = status.HasGas
+		info.OwnsToken = status.OwnsToken
+	}
+
+	a.mu.Lock()
+	a.walletInfo = info
+	a.mu.Unlock()
+	return status, err
+}
+
+// GetWalletInfo returns the result of the last wallet check, or nil before
+// the first one
+func (a *EnhancedAgent) GetWalletInfo() *health.WalletInfo {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.walletInfo
+}
+
+// checkWallet runs WalletStatus and warns when the agent has lost its NFT or
+// is running out of gas
+func (a *EnhancedAgent) checkWallet() {
+	if a.wallet == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(a.ctx, walletCheckTimeout)
+	defer cancel()
+	status, err := a.WalletStatus(ctx)
+	if err != nil {
+		log.Printf("⚠️ Wallet check failed: %v", err)
+		return
+	}
+	if !status.OwnsToken {
+		if status.TokenID != 0 {
+			log.Printf("🚨 Wallet %s no longer owns agent NFT #%d (owner: %s)", status.Wallet, status.TokenID, status.Owner)
+		} else {
+			log.Printf("🚨 Wallet %s owns no agent NFT", status.Wallet)
+		}
+	}
+	if !status.HasGas {
+		log.Printf("⚠️ Wallet %s is low on gas: %s", status.Wallet, status.FormattedBalance())
+	}
+}
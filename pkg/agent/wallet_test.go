@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/auth"
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/deploy"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// fakeWalletChain answers the balance and ownership queries of WalletStatus.
+// Other ChainOps methods are not used and panic.
+type fakeWalletChain struct {
+	deploy.ChainOps
+	address common.Address
+	balance *big.Int
+	owner   common.Address
+}
+
+func (c *fakeWalletChain) Close()                                             {}
+func (c *fakeWalletChain) SetReceiptWait(timeout, pollInterval time.Duration) {}
+func (c *fakeWalletChain) GetAddress() string                                 { return c.address.Hex() }
+
+func (c *fakeWalletChain) GetBalance(ctx context.Context) (*big.Int, error) {
+	return c.balance, nil
+}
+
+func (c *fakeWalletChain) OwnerOf(ctx context.Context, tokenID uint64) (common.Address, error) {
+	return c.owner, nil
+}
+
+func newWalletTestAgent(t *testing.T, chain *fakeWalletChain) *EnhancedAgent {
+	t.Helper()
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/contract/config" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(deploy.ContractConfigResponse{ContractAddress: "0x00000000000000000000000000000000000000c0", ChainID: "3338"})
+	}))
+	t.Cleanup(backend.Close)
+
+	authManager, err := auth.NewManager(testPrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain.address = common.HexToAddress(authManager.GetAddress())
+	wallet, err := deploy.NewMinter(&deploy.MintConfig{
+		PrivateKey:     testPrivateKey,
+		BackendURL:     backend.URL,
+		RPCEndpoint:    "http://rpc.invalid",
+		SchemaCacheDir: t.TempDir(),
+		WALStore:       deploy.NewFileWALStore(t.TempDir()),
+		ChainFactory: func(rpcEndpoint, contractAddress, chainID string, signer deploy.Signer) (deploy.ChainOps, error) {
+			return chain, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &EnhancedAgent{authManager: authManager, wallet: wallet, tokenID: 7}
+}
+
+func TestEnhancedAgent_WalletStatus(t *testing.T) {
+	oneToken := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	stranger := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+	tests := []struct {
+		name     string
+		balance  *big.Int
+		owner    *common.Address // nil = the agent's wallet
+		wantOwns bool
+		wantGas  bool
+	}{
+		{"owned with gas", oneToken, nil, true, true},
+		{"NFT lost", oneToken, &stranger, false, true},
+		{"out of gas", big.NewInt(1000), nil, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := &fakeWalletChain{balance: tt.balance}
+			agent := newWalletTestAgent(t, chain)
+			chain.owner = chain.address
+			if tt.owner != nil {
+				chain.owner = *tt.owner
+			}
+
+			if agent.GetWalletInfo() != nil {
+				t.Fatal("wallet info reported before the first check")
+			}
+			status, err := agent.WalletStatus(context.Background())
+			if err != nil {
+				t.Fatalf("WalletStatus() error = %v", err)
+			}
+			if status.OwnsToken != tt.wantOwns || status.HasGas != tt.wantGas || status.TokenID != 7 {
+				t.Errorf("WalletStatus() = %+v, want owns %v, has gas %v", status, tt.wantOwns, tt.wantGas)
+			}
+
+			info := agent.GetWalletInfo()
+			if info == nil || info.OwnsToken != tt.wantOwns || info.HasGas != tt.wantGas || info.Error != "" {
+				t.Fatalf("GetWalletInfo() = %+v, want the last check", info)
+			}
+			if !strings.HasSuffix(info.Balance, " PEAQ") || info.Address != chain.address.Hex() {
+				t.Errorf("GetWalletInfo() = %+v", info)
+			}
+		})
+	}
+}
+
+func TestEnhancedAgent_WalletStatusWithoutRPC(t *testing.T) {
+	agent := &EnhancedAgent{}
+	if _, err := agent.WalletStatus(context.Background()); err == nil {
+		t.Error("WalletStatus() succeeded without an RPC endpoint")
+	}
+}
@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// ChallengeScheme selects how SDK backend auth challenges are signed
+type ChallengeScheme string
+
+const (
+	// ChallengeSchemePersonalSign signs "Teneo SDK auth: <challenge>" with
+	// personal_sign. This is the default.
+	ChallengeSchemePersonalSign ChallengeScheme = "personal_sign"
+
+	// ChallengeSchemeEIP712 signs the challenge as EIP-712 typed data, see
+	// ChallengeTypedData
+	ChallengeSchemeEIP712 ChallengeScheme = "eip712"
+)
+
+// SDKChallengePrefix is prepended to the challenge for personal_sign
+const SDKChallengePrefix = "Teneo SDK auth: "
+
+// EIP-712 domain and primary type for SDK auth challenges
+const (
+	EIP712DomainName    = "Teneo SDK"
+	EIP712DomainVersion = "1"
+	EIP712ChallengeType = "SDKAuth"
+)
+
+// ChallengeTypedData returns the EIP-712 typed data signed for a challenge:
+// SDKAuth(address wallet,string challenge) under the "Teneo SDK" v1 domain
+func ChallengeTypedData(wallet, challenge string) apitypes.TypedData {
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+			},
+			EIP712ChallengeType: {
+				{Name: "wallet", Type: "address"},
+				{Name: "challenge", Type: "string"},
+			},
+		},
+		PrimaryType: EIP712ChallengeType,
+		Domain: apitypes.TypedDataDomain{
+			Name:    EIP712DomainName,
+			Version: EIP712DomainVersion,
+		},
+		Message: apitypes.TypedDataMessage{
+			"wallet":    wallet,
+			"challenge": challenge,
+		},
+	}
+}
+
+// ChallengeHash returns the digest that is signed for a challenge under scheme.
+// An empty scheme means personal_sign.
+func ChallengeHash(scheme ChallengeScheme, wallet, challenge string) ([]byte, error) {
+	switch scheme {
+	case "", ChallengeSchemePersonalSign:
+		return accounts.TextHash([]byte(SDKChallengePrefix + challenge)), nil
+	case ChallengeSchemeEIP712:
+		if !common.IsHexAddress(wallet) {
+			return nil, fmt.Errorf("invalid wallet address: %s", wallet)
+		}
+		hash, _, err := apitypes.TypedDataAndHash(ChallengeTypedData(wallet, challenge))
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash typed data: %w", err)
+		}
+		return hash, nil
+	default:
+		return nil, fmt.Errorf("unsupported challenge scheme: %s", scheme)
+	}
+}
+
+// SignChallenge signs a challenge for wallet under scheme and returns the
+// 0x-prefixed signature with a 27/28 recovery ID
+func SignChallenge(privateKey *ecdsa.PrivateKey, scheme ChallengeScheme, wallet, challenge string) (string, error) {
+	return SignChallengeWith(func(hash []byte) ([]byte, error) {
+		return crypto.Sign(hash, privateKey)
+	}, scheme, wallet, challenge)
+}
+
+// SignChallengeWith is like SignChallenge but delegates signing of the digest
+// to sign, e.g. a hardware wallet. sign may return a 0/1 or 27/28 recovery ID.
+func SignChallengeWith(sign func(hash []byte) ([]byte, error), scheme ChallengeScheme, wallet, challenge string) (string, error) {
+	hash, err := ChallengeHash(scheme, wallet, challenge)
+	if err != nil {
+		return "", err
+	}
+
+	sig, err := sign(hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign challenge: %w", err)
+	}
+	if len(sig) != crypto.SignatureLength {
+		return "", fmt.Errorf("invalid signature length: %d", len(sig))
+	}
+
+	// Adjust recovery ID for Ethereum (27/28 instead of 0/1)
+	if sig[64] < 27 {
+		sig[64] += 27
+	}
+	return hexutil.Encode(sig), nil
+}
+
+// RecoverChallengeSigner returns the address that produced signature for a
+// challenge under scheme. Both 0/1 and 27/28 recovery IDs are accepted.
+func RecoverChallengeSigner(scheme ChallengeScheme, wallet, challenge, signature string) (common.Address, error) {
+	sig, err := hexutil.Decode(signature)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to decode signature: %w", err)
+	}
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("invalid signature length: %d", len(sig))
+	}
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+
+	hash, err := ChallengeHash(scheme, wallet, challenge)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	pubkey, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to recover public key: %w", err)
+	}
+	return crypto.PubkeyToAddress(*pubkey), nil
+}
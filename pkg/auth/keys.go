@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// privateKeyHexLength is the length of a hex-encoded 32-byte private key
+const privateKeyHexLength = 64
+
+// ParsePrivateKey parses a hex-encoded private key and returns it with its
+// address. Surrounding whitespace, such as a trailing newline from a .env
+// file, and a 0x prefix are ignored. Errors never include the key itself.
+func ParsePrivateKey(s string) (*ecdsa.PrivateKey, common.Address, error) {
+	keyHex := strings.TrimSpace(s)
+	if strings.HasPrefix(keyHex, "0x") || strings.HasPrefix(keyHex, "0X") {
+		keyHex = keyHex[2:]
+	}
+
+	if keyHex == "" {
+		return nil, common.Address{}, fmt.Errorf("invalid private key: key is empty")
+	}
+	if len(keyHex) != privateKeyHexLength {
+		return nil, common.Address{}, fmt.Errorf("invalid private key: expected %d hex characters (32 bytes), got %d", privateKeyHexLength, len(keyHex))
+	}
+	for i, c := range keyHex {
+		if !isHexDigit(c) {
+			return nil, common.Address{}, fmt.Errorf("invalid private key: non-hex character at position %d", i+1)
+		}
+	}
+
+	privateKey, err := crypto.HexToECDSA(keyHex)
+	if err != nil {
+		return nil, common.Address{}, fmt.Errorf("invalid private key: %w", err)
+	}
+	return privateKey, crypto.PubkeyToAddress(privateKey.PublicKey), nil
+}
+
+func isHexDigit(c rune) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
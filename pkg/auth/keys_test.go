@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Hardhat's first default account
+const (
+	testKeyHex  = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+	testAddress = "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"
+)
+
+func TestParsePrivateKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		errMsg string // empty = valid
+	}{
+		{"bare hex", testKeyHex, ""},
+		{"0x prefix", "0x" + testKeyHex, ""},
+		{"0X prefix", "0X" + testKeyHex, ""},
+		{"uppercase hex", strings.ToUpper(testKeyHex), ""},
+		{"trailing newline", testKeyHex + "\n", ""},
+		{"windows line ending", "0x" + testKeyHex + "\r\n", ""},
+		{"surrounding spaces and tabs", " \t" + testKeyHex + "  ", ""},
+		{"empty", "", "key is empty"},
+		{"only whitespace", " \n", "key is empty"},
+		{"only prefix", "0x", "key is empty"},
+		{"too short", testKeyHex[:63], "expected 64 hex characters (32 bytes), got 63"},
+		{"too long", testKeyHex + "00", "expected 64 hex characters (32 bytes), got 66"},
+		{"non-hex character", testKeyHex[:10] + "zz" + testKeyHex[12:], "non-hex character at position 11"},
+		{"inner whitespace", testKeyHex[:32] + " " + testKeyHex[33:], "non-hex character at position 33"},
+		{"zero key", strings.Repeat("0", 64), "invalid private key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, address, err := ParsePrivateKey(tt.input)
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Fatalf("ParsePrivateKey() error = %v", err)
+				}
+				if key == nil || address != common.HexToAddress(testAddress) {
+					t.Errorf("ParsePrivateKey() address = %s, want %s", address.Hex(), testAddress)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Fatalf("ParsePrivateKey() error = %v, want error containing %q", err, tt.errMsg)
+			}
+			if strings.Contains(err.Error(), testKeyHex[:16]) {
+				t.Error("error message must not leak the key")
+			}
+		})
+	}
+}
@@ -22,18 +22,11 @@ type Manager struct {
 
 // NewManager creates a new authentication manager
 func NewManager(privateKeyHex string) (*Manager, error) {
-	// Remove 0x prefix if present
-	if len(privateKeyHex) >= 2 && privateKeyHex[:2] == "0x" {
-		privateKeyHex = privateKeyHex[2:]
-	}
-
-	privateKey, err := crypto.HexToECDSA(privateKeyHex)
+	privateKey, address, err := ParsePrivateKey(privateKeyHex)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse private key: %w", err)
+		return nil, err
 	}
 
-	address := crypto.PubkeyToAddress(privateKey.PublicKey)
-
 	return &Manager{
 		privateKey: privateKey,
 		address:    address,
@@ -202,18 +195,11 @@ type FoundationSignatureService struct {
 
 // NewFoundationSignatureService creates a new foundation signature service
 func NewFoundationSignatureService(privateKeyHex string) (*FoundationSignatureService, error) {
-	// Remove 0x prefix if present
-	if len(privateKeyHex) >= 2 && privateKeyHex[:2] == "0x" {
-		privateKeyHex = privateKeyHex[2:]
-	}
-
-	privateKey, err := crypto.HexToECDSA(privateKeyHex)
+	privateKey, address, err := ParsePrivateKey(privateKeyHex)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse private key: %w", err)
+		return nil, err
 	}
 
-	address := crypto.PubkeyToAddress(privateKey.PublicKey)
-
 	return &FoundationSignatureService{
 		privateKey: privateKey,
 		address:    address,
@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SetJSON stores v in c as JSON under key with an optional TTL
+func SetJSON(ctx context.Context, c AgentCache, key string, v interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal value for key %s: %w", key, err)
+	}
+	return c.Set(ctx, key, data, ttl)
+}
+
+// GetJSON decodes the JSON stored in c under key into out. A missing key
+// returns ErrCacheKeyNotFound and leaves out untouched, so a miss is never
+// mistaken for a cached zero value.
+func GetJSON(ctx context.Context, c AgentCache, key string, out interface{}) error {
+	data, err := c.GetBytes(ctx, key)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode cached value for key %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// memoryCache stores raw values in a map
+type memoryCache struct {
+	NoOpCache
+	entries map[string][]byte
+}
+
+func (c *memoryCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	c.entries[key] = value.([]byte)
+	return nil
+}
+
+func (c *memoryCache) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	data, ok := c.entries[key]
+	if !ok {
+		return nil, ErrCacheKeyNotFound
+	}
+	return data, nil
+}
+
+type analysisResult struct {
+	Token   string             `json:"token"`
+	Holders []string           `json:"holders"`
+	PnL     map[string]float64 `json:"pnl"`
+}
+
+func TestSetJSONGetJSON_RoundTrip(t *testing.T) {
+	redisCache, _ := newTestRedisCache(t)
+	caches := map[string]AgentCache{
+		"memory": &memoryCache{entries: map[string][]byte{}},
+		"redis":  redisCache,
+	}
+
+	want := analysisResult{
+		Token:   "0xtoken",
+		Holders: []string{"0xalpha", "0xbeta"},
+		PnL:     map[string]float64{"0xalpha": 12.5, "0xbeta": -3},
+	}
+
+	for name, c := range caches {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if err := SetJSON(ctx, c, "analysis:0xtoken", want, time.Minute); err != nil {
+				t.Fatalf("SetJSON() error = %v", err)
+			}
+
+			var got analysisResult
+			if err := GetJSON(ctx, c, "analysis:0xtoken", &got); err != nil {
+				t.Fatalf("GetJSON() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("GetJSON() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestGetJSON_Miss(t *testing.T) {
+	redisCache, _ := newTestRedisCache(t)
+	caches := map[string]AgentCache{
+		"memory": &memoryCache{entries: map[string][]byte{}},
+		"redis":  redisCache,
+		"no-op":  &NoOpCache{},
+	}
+
+	for name, c := range caches {
+		t.Run(name, func(t *testing.T) {
+			out := analysisResult{Token: "untouched"}
+			err := GetJSON(context.Background(), c, "analysis:missing", &out)
+			if !errors.Is(err, ErrCacheKeyNotFound) {
+				t.Errorf("GetJSON() error = %v, want ErrCacheKeyNotFound", err)
+			}
+			if out.Token != "untouched" {
+				t.Errorf("GetJSON() overwrote out on a miss: %+v", out)
+			}
+		})
+	}
+}
+
+func TestGetJSON_InvalidValue(t *testing.T) {
+	c := &memoryCache{entries: map[string][]byte{"analysis:bad": []byte("not json")}}
+
+	var out analysisResult
+	err := GetJSON(context.Background(), c, "analysis:bad", &out)
+	if err == nil || errors.Is(err, ErrCacheKeyNotFound) {
+		t.Errorf("GetJSON() error = %v, want a decode error distinct from a miss", err)
+	}
+}
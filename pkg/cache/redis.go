@@ -50,6 +50,10 @@ type RedisConfig struct {
 	// PoolSize is the maximum number of socket connections
 	PoolSize int
 
+	// MinIdleConns is the number of idle connections kept open so requests
+	// don't wait on a new dial
+	MinIdleConns int
+
 	// UseTLS enables TLS/SSL for the Redis connection (required for managed Redis like DigitalOcean)
 	UseTLS bool
 }
@@ -67,16 +71,36 @@ func DefaultRedisConfig() *RedisConfig {
 		ReadTimeout:  3 * time.Second,
 		WriteTimeout: 3 * time.Second,
 		PoolSize:     10,
+		MinIdleConns: 2,
 		UseTLS:       false,
 	}
 }
 
-// NewRedisCache creates a new Redis cache instance
+// NewRedisCache creates a new Redis cache instance. It returns an error
+// wrapping ErrCacheConnectionFailed if Redis does not answer a PING.
 func NewRedisCache(config *RedisConfig) (*RedisCache, error) {
 	if config == nil {
 		config = DefaultRedisConfig()
 	}
 
+	client, err := NewRedisClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RedisCache{
+		client:    client,
+		keyPrefix: config.KeyPrefix,
+	}, nil
+}
+
+// NewRedisClient opens and pings a Redis connection from the config.
+// It lets other packages share the cache's connection settings.
+func NewRedisClient(config *RedisConfig) (*redis.Client, error) {
+	if config == nil {
+		config = DefaultRedisConfig()
+	}
+
 	options := &redis.Options{
 		Addr:         config.Address,
 		Username:     config.Username, // Redis 6+ ACL username
@@ -87,6 +111,7 @@ func NewRedisCache(config *RedisConfig) (*RedisCache, error) {
 		ReadTimeout:  config.ReadTimeout,
 		WriteTimeout: config.WriteTimeout,
 		PoolSize:     config.PoolSize,
+		MinIdleConns: config.MinIdleConns,
 	}
 
 	// Enable TLS if requested (required for managed Redis like DigitalOcean, AWS ElastiCache, etc.)
@@ -99,17 +124,19 @@ func NewRedisCache(config *RedisConfig) (*RedisCache, error) {
 	client := redis.NewClient(options)
 
 	// Test connection
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	pingTimeout := config.DialTimeout
+	if pingTimeout <= 0 {
+		pingTimeout = 5 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
 	defer cancel()
 
 	if err := client.Ping(ctx).Err(); err != nil {
-		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+		client.Close()
+		return nil, fmt.Errorf("%w: failed to connect to Redis at %s: %w", ErrCacheConnectionFailed, config.Address, err)
 	}
 
-	return &RedisCache{
-		client:    client,
-		keyPrefix: config.KeyPrefix,
-	}, nil
+	return client, nil
 }
 
 // prefixKey adds the prefix to a key
@@ -424,6 +451,33 @@ func (r *RedisCache) Ping(ctx context.Context) error {
 	return nil
 }
 
+// RedisHealth reports the result of a Redis health check
+type RedisHealth struct {
+	Latency    time.Duration `json:"latency"`     // Round trip of the PING
+	TotalConns uint32        `json:"total_conns"` // Open connections in the pool
+	IdleConns  uint32        `json:"idle_conns"`  // Idle connections in the pool
+	Timeouts   uint32        `json:"timeouts"`    // Times a caller waited too long for a pooled connection
+}
+
+// HealthCheck pings Redis and reports the round trip and connection pool
+// statistics. It returns an error wrapping ErrCacheConnectionFailed if Redis
+// is unreachable.
+func (r *RedisCache) HealthCheck(ctx context.Context) (*RedisHealth, error) {
+	start := time.Now()
+	if err := r.client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("%w: Redis ping failed: %w", ErrCacheConnectionFailed, err)
+	}
+	latency := time.Since(start)
+
+	stats := r.client.PoolStats()
+	return &RedisHealth{
+		Latency:    latency,
+		TotalConns: stats.TotalConns,
+		IdleConns:  stats.IdleConns,
+		Timeouts:   stats.Timeouts,
+	}, nil
+}
+
 // Close closes the Redis connection
 func (r *RedisCache) Close() error {
 	return r.client.Close()
@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// newTestRedisCache starts an in-memory Redis server and a cache connected to it
+func newTestRedisCache(t *testing.T) (*RedisCache, *miniredis.Miniredis) {
+	t.Helper()
+	srv := miniredis.RunT(t)
+
+	config := DefaultRedisConfig()
+	config.Address = srv.Addr()
+	config.KeyPrefix = "test:"
+	config.MinIdleConns = 1
+
+	c, err := NewRedisCache(config)
+	if err != nil {
+		t.Fatalf("NewRedisCache() error = %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c, srv
+}
+
+func TestNewRedisCache_Connects(t *testing.T) {
+	c, srv := newTestRedisCache(t)
+	ctx := context.Background()
+
+	if err := c.Set(ctx, "greeting", "hello", time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	got, err := c.Get(ctx, "greeting")
+	if err != nil || got != "hello" {
+		t.Errorf("Get() = %q, %v, want hello", got, err)
+	}
+	if !srv.Exists("test:greeting") {
+		t.Error("key was not stored under the configured prefix")
+	}
+
+	health, err := c.HealthCheck(ctx)
+	if err != nil {
+		t.Fatalf("HealthCheck() error = %v", err)
+	}
+	if health.TotalConns == 0 {
+		t.Errorf("HealthCheck() = %+v, want open pool connections", health)
+	}
+}
+
+func TestNewRedisCache_ConnectionRefused(t *testing.T) {
+	// Reserve a port and release it so nothing is listening there
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	config := DefaultRedisConfig()
+	config.Address = addr
+	config.MaxRetries = -1
+	config.DialTimeout = time.Second
+
+	c, err := NewRedisCache(config)
+	if !errors.Is(err, ErrCacheConnectionFailed) {
+		t.Fatalf("NewRedisCache() error = %v, want ErrCacheConnectionFailed", err)
+	}
+	if c != nil {
+		t.Error("NewRedisCache() returned a cache for an unreachable server")
+	}
+}
+
+func TestRedisCache_HealthCheckAfterServerStops(t *testing.T) {
+	c, srv := newTestRedisCache(t)
+	srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if _, err := c.HealthCheck(ctx); !errors.Is(err, ErrCacheConnectionFailed) {
+		t.Errorf("HealthCheck() error = %v, want ErrCacheConnectionFailed", err)
+	}
+}
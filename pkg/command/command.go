@@ -0,0 +1,163 @@
+// Package command parses the free-form commands users send to agents.
+//
+// A command is a line of words separated by whitespace. Words may be quoted
+// with single or double quotes to keep spaces, and words starting with two
+// dashes are named flags:
+//
+//	analyze "So11111111111111111111111111111111111111112" --limit=5 --chain sol
+//
+// parses into the positional arguments [analyze So111...112] and the flags
+// limit=5 and chain=sol.
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Command is a parsed command line.
+type Command struct {
+	// Args holds the positional arguments in order
+	Args []string
+
+	// Flags maps lowercase flag names to their values
+	Flags map[string]string
+}
+
+// Arg returns the i-th positional argument, or "" if there are fewer.
+func (c *Command) Arg(i int) string {
+	if i < 0 || i >= len(c.Args) {
+		return ""
+	}
+	return c.Args[i]
+}
+
+// Flag returns the value of the named flag and whether it was given.
+func (c *Command) Flag(name string) (string, bool) {
+	value, ok := c.Flags[strings.ToLower(name)]
+	return value, ok
+}
+
+// Parser parses commands that accept a fixed set of flags.
+type Parser struct {
+	// Flags lists the accepted flag names without leading dashes; names
+	// match case-insensitively
+	Flags []string
+
+	// KeyValue also accepts flags written as name=value without dashes, for
+	// commands that predate dashed flags. Words with an "=" whose name is
+	// not a known flag stay positional.
+	KeyValue bool
+}
+
+// Parse splits input into positional arguments and flags. Flags are written
+// --name=value or --name value; a later flag overrides an earlier one with
+// the same name, and words after a bare -- are always positional. Unknown
+// flags, flags without a value and unterminated quotes are errors.
+func (p *Parser) Parse(input string) (*Command, error) {
+	words, err := Split(input)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(p.Flags))
+	for _, name := range p.Flags {
+		known[strings.ToLower(name)] = true
+	}
+
+	cmd := &Command{Flags: make(map[string]string)}
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+
+		if word.Quoted {
+			cmd.Args = append(cmd.Args, word.Text)
+			continue
+		}
+		if word.Text == "--" {
+			for _, rest := range words[i+1:] {
+				cmd.Args = append(cmd.Args, rest.Text)
+			}
+			break
+		}
+
+		if name, ok := strings.CutPrefix(word.Text, "--"); ok {
+			name, value, hasValue := strings.Cut(name, "=")
+			name = strings.ToLower(name)
+			if !known[name] {
+				return nil, p.unknownFlag(name)
+			}
+			if !hasValue {
+				if i+1 >= len(words) || isFlag(words[i+1]) {
+					return nil, fmt.Errorf("flag --%s needs a value, e.g. --%s=<value>", name, name)
+				}
+				i++
+				value = words[i].Text
+			}
+			cmd.Flags[name] = value
+			continue
+		}
+
+		if p.KeyValue {
+			if name, value, ok := strings.Cut(word.Text, "="); ok && known[strings.ToLower(name)] {
+				cmd.Flags[strings.ToLower(name)] = value
+				continue
+			}
+		}
+		cmd.Args = append(cmd.Args, word.Text)
+	}
+	return cmd, nil
+}
+
+// isFlag reports whether word is written as a flag
+func isFlag(word Word) bool {
+	return !word.Quoted && strings.HasPrefix(word.Text, "--")
+}
+
+// unknownFlag describes an unknown flag, suggesting the closest known one
+func (p *Parser) unknownFlag(name string) error {
+	if name == "" {
+		return fmt.Errorf("flag name missing after --")
+	}
+	if len(p.Flags) == 0 {
+		return fmt.Errorf("unknown flag --%s, this command takes no flags", name)
+	}
+
+	names := make([]string, len(p.Flags))
+	for i, flag := range p.Flags {
+		names[i] = strings.ToLower(flag)
+	}
+	sort.Strings(names)
+
+	best, bestDistance := "", 3 // Suggest only near misses
+	for _, candidate := range names {
+		if d := editDistance(name, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	if best != "" {
+		return fmt.Errorf("unknown flag --%s, did you mean --%s?", name, best)
+	}
+	return fmt.Errorf("unknown flag --%s, expected one of --%s", name, strings.Join(names, ", --"))
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
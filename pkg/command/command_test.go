@@ -0,0 +1,97 @@
+package command
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParser_Parse(t *testing.T) {
+	parser := &Parser{Flags: []string{"chain", "limit", "Format"}}
+
+	tests := []struct {
+		name      string
+		input     string
+		wantArgs  []string
+		wantFlags map[string]string
+		errMsg    string // empty = valid
+	}{
+		{"positional only", "ethereum 0xabc 5", []string{"ethereum", "0xabc", "5"}, map[string]string{}, ""},
+		{"equals flags", "0xabc --limit=5 --chain=ethereum", []string{"0xabc"}, map[string]string{"limit": "5", "chain": "ethereum"}, ""},
+		{"spaced flag value", "--chain ethereum 0xabc", []string{"0xabc"}, map[string]string{"chain": "ethereum"}, ""},
+		{"flag names ignore case", "--CHAIN=sol --format=csv", nil, map[string]string{"chain": "sol", "format": "csv"}, ""},
+		{"quoted flag value", `--format="mark down"`, nil, map[string]string{"format": "mark down"}, ""},
+		{"quoted spaced flag value", `--format "mark down" x`, []string{"x"}, map[string]string{"format": "mark down"}, ""},
+		{"empty value", "--format=", nil, map[string]string{"format": ""}, ""},
+		{"later flag wins", "--limit=1 --limit=2", nil, map[string]string{"limit": "2"}, ""},
+		{"quoted dashes are positional", `"--limit" 5`, []string{"--limit", "5"}, map[string]string{}, ""},
+		{"double dash ends flags", "a -- --limit=5 b", []string{"a", "--limit=5", "b"}, map[string]string{}, ""},
+		{"single dash is positional", "-5 -x", []string{"-5", "-x"}, map[string]string{}, ""},
+		{"key=value stays positional", "limit=5", []string{"limit=5"}, map[string]string{}, ""},
+		{"near miss suggests flag", "--limt=5", nil, nil, "unknown flag --limt, did you mean --limit?"},
+		{"unknown flag lists flags", "--network=sol", nil, nil, "unknown flag --network, expected one of --chain, --format, --limit"},
+		{"missing flag name", "--=5", nil, nil, "flag name missing after --"},
+		{"missing value at end", "0xabc --limit", nil, nil, "flag --limit needs a value"},
+		{"missing value before flag", "--limit --chain=sol", nil, nil, "flag --limit needs a value"},
+		{"unterminated quote", `--chain="sol`, nil, nil, "unterminated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := parser.Parse(tt.input)
+			if tt.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+					t.Fatalf("Parse() error = %v, want error containing %q", err, tt.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(cmd.Args, tt.wantArgs) {
+				t.Errorf("Args = %q, want %q", cmd.Args, tt.wantArgs)
+			}
+			if !reflect.DeepEqual(cmd.Flags, tt.wantFlags) {
+				t.Errorf("Flags = %v, want %v", cmd.Flags, tt.wantFlags)
+			}
+		})
+	}
+}
+
+func TestParser_KeyValue(t *testing.T) {
+	parser := &Parser{Flags: []string{"from", "max"}, KeyValue: true}
+
+	cmd, err := parser.Parse(`analyze abc from=7d MAX=10 --from=2024-01-01 note=x "max=1"`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if want := []string{"analyze", "abc", "note=x", "max=1"}; !reflect.DeepEqual(cmd.Args, want) {
+		t.Errorf("Args = %q, want %q", cmd.Args, want)
+	}
+	if want := map[string]string{"from": "2024-01-01", "max": "10"}; !reflect.DeepEqual(cmd.Flags, want) {
+		t.Errorf("Flags = %v, want %v", cmd.Flags, want)
+	}
+}
+
+func TestParser_NoFlags(t *testing.T) {
+	_, err := (&Parser{}).Parse("run --fast")
+	if err == nil || !strings.Contains(err.Error(), "takes no flags") {
+		t.Errorf("Parse() error = %v, want a no-flags error", err)
+	}
+}
+
+func TestCommand_Accessors(t *testing.T) {
+	cmd, err := (&Parser{Flags: []string{"limit"}}).Parse("a b --limit=3")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if cmd.Arg(1) != "b" || cmd.Arg(2) != "" || cmd.Arg(-1) != "" {
+		t.Errorf("Arg() = %q, %q, %q", cmd.Arg(1), cmd.Arg(2), cmd.Arg(-1))
+	}
+	if v, ok := cmd.Flag("LIMIT"); !ok || v != "3" {
+		t.Errorf("Flag(LIMIT) = %q, %v", v, ok)
+	}
+	if _, ok := cmd.Flag("chain"); ok {
+		t.Error("Flag(chain) should be missing")
+	}
+}
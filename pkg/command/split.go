@@ -0,0 +1,81 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Word is a word of a command line.
+type Word struct {
+	Text string
+
+	// Quoted is set when the word starts with a quote, so text such as
+	// "--limit" is taken literally rather than as a flag
+	Quoted bool
+}
+
+// Split breaks input into words at unquoted whitespace. Single quotes keep
+// their contents literally; inside double quotes a backslash escapes a
+// double quote or another backslash. Quoted and unquoted parts next to each
+// other form one word, so --name="a b" is the word --name=a b.
+func Split(input string) ([]Word, error) {
+	var (
+		words   []Word
+		current strings.Builder
+		inWord  bool
+		quoted  bool
+	)
+
+	runes := []rune(input)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, Word{Text: current.String(), Quoted: quoted})
+				current.Reset()
+				inWord, quoted = false, false
+			}
+
+		case r == '"' || r == '\'':
+			if !inWord {
+				inWord, quoted = true, true
+			}
+			end := closingQuote(runes, i)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated %c quote starting at character %d", r, i+1)
+			}
+			for j := i + 1; j < end; j++ {
+				if r == '"' && runes[j] == '\\' && j+1 < end && (runes[j+1] == '"' || runes[j+1] == '\\') {
+					j++
+				}
+				current.WriteRune(runes[j])
+			}
+			i = end
+
+		default:
+			inWord = true
+			current.WriteRune(r)
+		}
+	}
+	if inWord {
+		words = append(words, Word{Text: current.String(), Quoted: quoted})
+	}
+	return words, nil
+}
+
+// closingQuote returns the index of the quote closing the one at start, or
+// -1 if it is never closed
+func closingQuote(runes []rune, start int) int {
+	quote := runes[start]
+	for i := start + 1; i < len(runes); i++ {
+		switch {
+		case quote == '"' && runes[i] == '\\':
+			i++ // Skip the escaped character
+		case runes[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,48 @@
+package command
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		want   []Word
+		errMsg string // empty = valid
+	}{
+		{"plain words", "  analyze  abc\t5\n", []Word{{Text: "analyze"}, {Text: "abc"}, {Text: "5"}}, ""},
+		{"empty", "   ", nil, ""},
+		{"double quotes keep spaces", `say "hello world"`, []Word{{Text: "say"}, {Text: "hello world", Quoted: true}}, ""},
+		{"single quotes are literal", `'a \" b'`, []Word{{Text: `a \" b`, Quoted: true}}, ""},
+		{"escaped double quote", `"say \"hi\" \\ now"`, []Word{{Text: `say "hi" \ now`, Quoted: true}}, ""},
+		{"other backslashes kept", `"C:\path"`, []Word{{Text: `C:\path`, Quoted: true}}, ""},
+		{"empty quoted word", `a "" b`, []Word{{Text: "a"}, {Text: "", Quoted: true}, {Text: "b"}}, ""},
+		{"quoted flag value", `--name="a b"`, []Word{{Text: "--name=a b"}}, ""},
+		{"adjacent parts join", `ab'c d'"e"`, []Word{{Text: "abc de"}}, ""},
+		{"unicode", `"héllo wörld" ✓`, []Word{{Text: "héllo wörld", Quoted: true}, {Text: "✓"}}, ""},
+		{"unterminated double", `analyze "abc`, nil, `unterminated " quote starting at character 9`},
+		{"unterminated single", `it's`, nil, `unterminated ' quote starting at character 3`},
+		{"escaped closing quote", `"abc\"`, nil, "unterminated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Split(tt.input)
+			if tt.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+					t.Fatalf("Split() error = %v, want error containing %q", err, tt.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Split() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package deploy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// MaxAgentIDLength is the longest agent ID the backend accepts
+const MaxAgentIDLength = 64
+
+// agentIDHashLength is the number of hex digits of a name's hash used when an
+// ID cannot be derived from the name or has to be shortened
+const agentIDHashLength = 8
+
+// agentIDSuffixLength is the number of hex digits SuffixAgentID appends
+const agentIDSuffixLength = 6
+
+// GenerateAgentID derives an agent ID from a name: lowercased, spaces
+// replaced with hyphens and other characters outside a-z, 0-9 and '-'
+// removed, matching the IDs earlier SDK versions derived. The result is
+// always a valid, non-empty ID: a name without usable characters becomes
+// "agent-" and a hash of the name, and one longer than MaxAgentIDLength is
+// cut short and ends in a hash of the name so long names sharing a prefix
+// still differ.
+func GenerateAgentID(name string) string {
+	var id strings.Builder
+	for _, c := range strings.ReplaceAll(strings.ToLower(name), " ", "-") {
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' {
+			id.WriteRune(c)
+		}
+	}
+
+	hash := shortHash(name, agentIDHashLength)
+	switch {
+	case strings.Trim(id.String(), "-") == "":
+		return "agent-" + hash
+	case id.Len() > MaxAgentIDLength:
+		return withSuffix(id.String(), hash)
+	}
+	return id.String()
+}
+
+// SuffixAgentID returns id with a short suffix derived from id and seed, for
+// deploying under another ID when id is taken. The same id and seed always
+// give the same result, so using the deploying wallet's address as seed lets
+// a re-run find the agent it deployed before.
+func SuffixAgentID(id, seed string) string {
+	return withSuffix(id, shortHash(id+"|"+strings.ToLower(seed), agentIDSuffixLength))
+}
+
+// withSuffix appends "-suffix" to id, shortening id so the result fits in
+// MaxAgentIDLength
+func withSuffix(id, suffix string) string {
+	if max := MaxAgentIDLength - len(suffix) - 1; len(id) > max {
+		id = id[:max]
+	}
+	return strings.TrimRight(id, "-") + "-" + suffix
+}
+
+// shortHash returns the first n hex digits of the SHA-256 of s
+func shortHash(s string, n int) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])[:n]
+}
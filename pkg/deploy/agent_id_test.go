@@ -0,0 +1,218 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// isValidAgentID reports whether id is accepted by the deploy validation
+func isValidAgentID(id string) bool {
+	if id == "" || len(id) > MaxAgentIDLength {
+		return false
+	}
+	for _, c := range id {
+		if !((c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+func TestGenerateAgentID(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Interior Architecture Advisor", "interior-architecture-advisor"},
+		{"Alpha Wallet Finder v2!", "alpha-wallet-finder-v2"},
+		{"Déjà Vu Bot", "dj-vu-bot"},
+		{"already-an-id", "already-an-id"},
+	}
+
+	for _, tt := range tests {
+		if got := GenerateAgentID(tt.name); got != tt.want {
+			t.Errorf("GenerateAgentID(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateAgentID_SymbolOnlyNames(t *testing.T) {
+	seen := make(map[string]string)
+	for _, name := range []string{"!!!", "🚀🚀🚀", "   ", " - ", "", "日本語エージェント"} {
+		id := GenerateAgentID(name)
+		if !isValidAgentID(id) || !strings.HasPrefix(id, "agent-") {
+			t.Errorf("GenerateAgentID(%q) = %q, want a valid agent- ID", name, id)
+		}
+		if id != GenerateAgentID(name) {
+			t.Errorf("GenerateAgentID(%q) is not deterministic", name)
+		}
+		if other, ok := seen[id]; ok {
+			t.Errorf("GenerateAgentID(%q) and GenerateAgentID(%q) both = %q", name, other, id)
+		}
+		seen[id] = name
+	}
+}
+
+func TestGenerateAgentID_VeryLongNames(t *testing.T) {
+	prefix := strings.Repeat("very long agent name ", 4)
+	first := GenerateAgentID(prefix + "one")
+	second := GenerateAgentID(prefix + "two")
+
+	for _, id := range []string{first, second} {
+		if !isValidAgentID(id) || len(id) != MaxAgentIDLength {
+			t.Errorf("GenerateAgentID() = %q (%d characters), want a valid %d character ID", id, len(id), MaxAgentIDLength)
+		}
+		if !strings.HasPrefix(id, "very-long-agent-name-") {
+			t.Errorf("GenerateAgentID() = %q, want it to start with the name", id)
+		}
+	}
+	if first == second {
+		t.Errorf("long names sharing a prefix both became %q", first)
+	}
+
+	// A name that fits is never shortened
+	exact := strings.Repeat("a", MaxAgentIDLength)
+	if got := GenerateAgentID(exact); got != exact {
+		t.Errorf("GenerateAgentID(%d characters) = %q, want it unchanged", MaxAgentIDLength, got)
+	}
+}
+
+func TestSuffixAgentID(t *testing.T) {
+	const wallet = "0x00000000000000000000000000000000000000AA"
+
+	id := SuffixAgentID("price-bot", wallet)
+	if !isValidAgentID(id) || !strings.HasPrefix(id, "price-bot-") || len(id) != len("price-bot-")+agentIDSuffixLength {
+		t.Errorf("SuffixAgentID() = %q, want price-bot- and a short suffix", id)
+	}
+	if again := SuffixAgentID("price-bot", strings.ToLower(wallet)); again != id {
+		t.Errorf("SuffixAgentID() = %q then %q for the same wallet", id, again)
+	}
+	if other := SuffixAgentID("price-bot", "0x00000000000000000000000000000000000000bb"); other == id {
+		t.Errorf("two wallets got the same suffixed ID %q", id)
+	}
+
+	long := GenerateAgentID(strings.Repeat("long name ", 10))
+	if got := SuffixAgentID(long, wallet); !isValidAgentID(got) {
+		t.Errorf("SuffixAgentID(%q) = %q (%d characters), want a valid ID", long, got, len(got))
+	}
+}
+
+// collisionBackend is a backend on which another wallet holds takenID
+type collisionBackend struct {
+	takenID  string
+	ownsID   bool // the deploying wallet holds takenID itself
+	deployed []string
+}
+
+func (b *collisionBackend) handlers() map[string]http.HandlerFunc {
+	return map[string]http.HandlerFunc{
+		"/api/sdk/agent/deploy": func(w http.ResponseWriter, r *http.Request) {
+			var req DeployRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			b.deployed = append(b.deployed, req.AgentID)
+			if req.AgentID == b.takenID {
+				writeJSON(w, http.StatusConflict, ErrorResponse{Error: "agent_id already exists"})
+				return
+			}
+			writeJSON(w, http.StatusOK, DeployResponse{Signature: "0x01", ContractAddress: testContractAddress, ChainID: "3338", ConfigHash: "hash"})
+		},
+		"/api/sdk/agent/status": func(w http.ResponseWriter, r *http.Request) {
+			if b.ownsID && r.URL.Query().Get("agent_id") == b.takenID {
+				writeJSON(w, http.StatusOK, AgentStatusResponse{AgentID: b.takenID, Status: "reserved"})
+				return
+			}
+			writeJSON(w, http.StatusNotFound, ErrorResponse{Error: "not found"})
+		},
+		"/api/sdk/agent/confirm-mint": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, ConfirmMintResponse{Success: true, ID: "db-id"})
+		},
+	}
+}
+
+func newCollisionDeployer(t *testing.T, privateKey, backendURL, stateFile string, suffix bool) (*Deployer, *fakeChainOps) {
+	t.Helper()
+	signer, err := NewPrivateKeySigner(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := newFakeChainOps(signer.Address())
+	chain.mintResult = &MintResult{TokenID: 9, TxHash: "0xmint"}
+
+	deployer, err := NewDeployer(&DeployConfig{
+		BackendURL:              backendURL,
+		Signer:                  signer,
+		AgentID:                 GenerateAgentID("Price Bot"),
+		SuffixAgentIDOnConflict: suffix,
+		AgentName:               "Price Bot",
+		Description:             "Reports token prices",
+		AgentType:               "command",
+		StateFilePath:           stateFile,
+		ChainFactory:            chain.factory(),
+	})
+	if err != nil {
+		t.Fatalf("NewDeployer() error = %v", err)
+	}
+	return deployer, chain
+}
+
+func TestDeployer_SuffixesTakenAgentID(t *testing.T) {
+	backend := &collisionBackend{takenID: "price-bot"}
+	server := newFakeBackend(t, backend.handlers())
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	privateKey := newTestPrivateKey(t)
+	deployer, _ := newCollisionDeployer(t, privateKey, server.URL, stateFile, true)
+	want := SuffixAgentID("price-bot", deployer.authenticator.GetAddress())
+
+	result, err := deployer.Deploy(context.Background())
+	if err != nil {
+		t.Fatalf("Deploy() error = %v", err)
+	}
+	if result.AgentID != want || result.TokenID != 9 {
+		t.Errorf("Deploy() = %+v, want agent %s with token 9", result, want)
+	}
+	if len(backend.deployed) != 2 || backend.deployed[0] != "price-bot" || backend.deployed[1] != want {
+		t.Errorf("deploy requests for %v, want price-bot then %s", backend.deployed, want)
+	}
+
+	// A re-run resumes under the suffixed ID from the state file
+	rerun, chain := newCollisionDeployer(t, privateKey, server.URL, stateFile, true)
+	chain.ownedToken = &result.TokenID
+	again, err := rerun.Deploy(context.Background())
+	if err != nil {
+		t.Fatalf("second Deploy() error = %v", err)
+	}
+	if again.AgentID != want || !again.AlreadyMinted || len(backend.deployed) != 2 {
+		t.Errorf("second Deploy() = %+v after %d deploy requests, want the existing %s", again, len(backend.deployed), want)
+	}
+}
+
+func TestDeployer_DoesNotSuffixWithoutOptionOrWhenWalletHoldsID(t *testing.T) {
+	tests := []struct {
+		name   string
+		suffix bool
+		ownsID bool
+	}{
+		{"option off", false, false},
+		{"wallet holds the ID", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &collisionBackend{takenID: "price-bot", ownsID: tt.ownsID}
+			server := newFakeBackend(t, backend.handlers())
+			deployer, chain := newCollisionDeployer(t, newTestPrivateKey(t), server.URL, filepath.Join(t.TempDir(), "state.json"), tt.suffix)
+
+			if _, err := deployer.Deploy(context.Background()); !errors.Is(err, ErrAgentIDTaken) {
+				t.Fatalf("Deploy() error = %v, want ErrAgentIDTaken", err)
+			}
+			if len(backend.deployed) != 1 || chain.mints != 0 {
+				t.Errorf("deploy requests for %v and %d mints, want only the first attempt", backend.deployed, chain.mints)
+			}
+		})
+	}
+}
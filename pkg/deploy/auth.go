@@ -1,53 +1,70 @@
 package deploy
 
 import (
-	"crypto/ecdsa"
+	"context"
 	"fmt"
-	"strings"
 
-	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/auth"
 )
 
 const (
 	// SDKAuthMessagePrefix is the prefix used for signing auth challenges
-	SDKAuthMessagePrefix = "Teneo SDK auth: "
+	SDKAuthMessagePrefix = auth.SDKChallengePrefix
 )
 
 // Authenticator handles SDK authentication with the backend
 type Authenticator struct {
-	privateKey *ecdsa.PrivateKey
-	address    string
-	client     *HTTPClient
+	signer  Signer
+	address string
+	client  *HTTPClient
+	scheme  auth.ChallengeScheme
 }
 
-// NewAuthenticator creates a new authenticator
-func NewAuthenticator(privateKeyHex string, client *HTTPClient) (*Authenticator, error) {
-	// Parse private key
-	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(privateKeyHex, "0x"))
-	if err != nil {
-		return nil, fmt.Errorf("invalid private key: %w", err)
+// AuthenticatorOption configures an Authenticator
+type AuthenticatorOption func(*Authenticator)
+
+// WithEIP712Signing signs challenges as EIP-712 typed data instead of
+// personal_sign when enabled
+func WithEIP712Signing(enabled bool) AuthenticatorOption {
+	return func(a *Authenticator) {
+		if enabled {
+			a.scheme = auth.ChallengeSchemeEIP712
+		}
 	}
+}
 
-	// Derive address
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		return nil, fmt.Errorf("failed to derive public key")
+// NewAuthenticator creates a new authenticator backed by a raw private key
+func NewAuthenticator(privateKeyHex string, client *HTTPClient, opts ...AuthenticatorOption) (*Authenticator, error) {
+	signer, err := NewPrivateKeySigner(privateKeyHex)
+	if err != nil {
+		return nil, err
 	}
-	address := crypto.PubkeyToAddress(*publicKeyECDSA).Hex()
+	return NewAuthenticatorWithSigner(signer, client, opts...), nil
+}
 
-	return &Authenticator{
-		privateKey: privateKey,
-		address:    address,
-		client:     client,
-	}, nil
+// NewAuthenticatorWithSigner creates an authenticator that signs challenges with signer
+func NewAuthenticatorWithSigner(signer Signer, client *HTTPClient, opts ...AuthenticatorOption) *Authenticator {
+	a := &Authenticator{
+		signer:  signer,
+		address: signer.Address().Hex(),
+		client:  client,
+		scheme:  auth.ChallengeSchemePersonalSign,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
 }
 
 // Authenticate performs the full challenge-response authentication flow
 func (a *Authenticator) Authenticate() (sessionToken string, expiresAt int64, err error) {
+	return a.AuthenticateCtx(context.Background())
+}
+
+// AuthenticateCtx is like Authenticate but aborts when ctx is done
+func (a *Authenticator) AuthenticateCtx(ctx context.Context) (sessionToken string, expiresAt int64, err error) {
 	// Step 1: Request challenge
-	challengeResp, err := a.client.RequestChallenge(a.address)
+	challengeResp, err := a.client.RequestChallengeCtx(ctx, a.address)
 	if err != nil {
 		return "", 0, fmt.Errorf("failed to request challenge: %w", err)
 	}
@@ -59,7 +76,12 @@ func (a *Authenticator) Authenticate() (sessionToken string, expiresAt int64, er
 	}
 
 	// Step 3: Verify signature
-	verifyResp, err := a.client.VerifySignature(a.address, challengeResp.Challenge, signature)
+	verifyResp, err := a.client.VerifyChallengeCtx(ctx, &VerifyRequest{
+		WalletAddress: a.address,
+		Challenge:     challengeResp.Challenge,
+		Signature:     signature,
+		SignatureType: a.SignatureType(),
+	})
 	if err != nil {
 		return "", 0, fmt.Errorf("failed to verify signature: %w", err)
 	}
@@ -67,33 +89,25 @@ func (a *Authenticator) Authenticate() (sessionToken string, expiresAt int64, er
 	return verifyResp.SessionToken, verifyResp.ExpiresAt, nil
 }
 
-// SignChallenge signs a challenge with the private key
+// SignChallenge signs a challenge with the signer using the configured scheme
 func (a *Authenticator) SignChallenge(challenge string) (string, error) {
-	// Construct message with prefix
-	message := SDKAuthMessagePrefix + challenge
-
-	// Hash with Ethereum prefix
-	hash := hashMessage([]byte(message))
-
-	// Sign
-	signature, err := crypto.Sign(hash, a.privateKey)
+	signature, err := auth.SignChallengeWith(a.signer.Sign, a.scheme, a.address, challenge)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign: %w", err)
 	}
+	return signature, nil
+}
 
-	// Adjust v value for Ethereum (27/28 instead of 0/1)
-	signature[64] += 27
-
-	return hexutil.Encode(signature), nil
+// SignatureType returns the signature_type sent alongside signatures: empty
+// for personal_sign, so default requests are unchanged, and "eip712" otherwise
+func (a *Authenticator) SignatureType() string {
+	if a.scheme == auth.ChallengeSchemePersonalSign {
+		return ""
+	}
+	return string(a.scheme)
 }
 
 // GetAddress returns the wallet address
 func (a *Authenticator) GetAddress() string {
 	return a.address
 }
-
-// hashMessage hashes a message with the Ethereum signed message prefix
-func hashMessage(data []byte) []byte {
-	prefix := fmt.Sprintf("\x19Ethereum Signed Message:\n%d", len(data))
-	return crypto.Keccak256([]byte(prefix), data)
-}
@@ -0,0 +1,149 @@
+package deploy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/auth"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// eip712ChallengeDigest recomputes the EIP-712 digest by hand so the test pins
+// the wire format rather than trusting the typed-data helper
+func eip712ChallengeDigest(wallet, challenge string) []byte {
+	domainType := crypto.Keccak256([]byte("EIP712Domain(string name,string version)"))
+	domainSeparator := crypto.Keccak256(domainType,
+		crypto.Keccak256([]byte("Teneo SDK")), crypto.Keccak256([]byte("1")))
+
+	messageType := crypto.Keccak256([]byte("SDKAuth(address wallet,string challenge)"))
+	structHash := crypto.Keccak256(messageType,
+		common.LeftPadBytes(common.HexToAddress(wallet).Bytes(), 32), crypto.Keccak256([]byte(challenge)))
+
+	return crypto.Keccak256([]byte("\x19\x01"), domainSeparator, structHash)
+}
+
+func TestAuthenticator_SignChallengeRecoversSigner(t *testing.T) {
+	const challenge = "challenge-123"
+
+	tests := []struct {
+		name     string
+		eip712   bool
+		scheme   auth.ChallengeScheme
+		wantType string
+		digest   func(wallet string) []byte
+	}{
+		{
+			name:   "personal_sign",
+			scheme: auth.ChallengeSchemePersonalSign,
+			digest: func(string) []byte { return accounts.TextHash([]byte("Teneo SDK auth: " + challenge)) },
+		},
+		{
+			name:     "eip712",
+			eip712:   true,
+			scheme:   auth.ChallengeSchemeEIP712,
+			wantType: "eip712",
+			digest:   func(wallet string) []byte { return eip712ChallengeDigest(wallet, challenge) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator, err := NewAuthenticator(newTestPrivateKey(t), nil, WithEIP712Signing(tt.eip712))
+			if err != nil {
+				t.Fatalf("NewAuthenticator() error = %v", err)
+			}
+			if authenticator.SignatureType() != tt.wantType {
+				t.Errorf("SignatureType() = %q, want %q", authenticator.SignatureType(), tt.wantType)
+			}
+
+			signature, err := authenticator.SignChallenge(challenge)
+			if err != nil {
+				t.Fatalf("SignChallenge() error = %v", err)
+			}
+			sig, err := hexutil.Decode(signature)
+			if err != nil || len(sig) != 65 {
+				t.Fatalf("expected a 65-byte hex signature, got %q", signature)
+			}
+			if sig[64] != 27 && sig[64] != 28 {
+				t.Fatalf("recovery ID = %d, want 27 or 28", sig[64])
+			}
+
+			sig[64] -= 27
+			pubkey, err := crypto.SigToPub(tt.digest(authenticator.GetAddress()), sig)
+			if err != nil {
+				t.Fatalf("SigToPub() error = %v", err)
+			}
+			if got := crypto.PubkeyToAddress(*pubkey).Hex(); got != authenticator.GetAddress() {
+				t.Errorf("recovered %s, want %s", got, authenticator.GetAddress())
+			}
+
+			recovered, err := auth.RecoverChallengeSigner(tt.scheme, authenticator.GetAddress(), challenge, signature)
+			if err != nil || recovered.Hex() != authenticator.GetAddress() {
+				t.Errorf("RecoverChallengeSigner() = %s, %v; want %s", recovered.Hex(), err, authenticator.GetAddress())
+			}
+		})
+	}
+}
+
+func TestAuthenticator_SchemesAreNotInterchangeable(t *testing.T) {
+	authenticator, err := NewAuthenticator(newTestPrivateKey(t), nil, WithEIP712Signing(true))
+	if err != nil {
+		t.Fatalf("NewAuthenticator() error = %v", err)
+	}
+	signature, err := authenticator.SignChallenge("challenge")
+	if err != nil {
+		t.Fatalf("SignChallenge() error = %v", err)
+	}
+
+	recovered, err := auth.RecoverChallengeSigner(auth.ChallengeSchemePersonalSign, authenticator.GetAddress(), "challenge", signature)
+	if err == nil && recovered.Hex() == authenticator.GetAddress() {
+		t.Error("an EIP-712 signature should not verify as personal_sign")
+	}
+}
+
+func TestAuthenticator_VerifySendsSignatureType(t *testing.T) {
+	for _, eip712 := range []bool{false, true} {
+		var got VerifyRequest
+		var raw map[string]interface{}
+		backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/api/sdk/auth/challenge" {
+				writeJSON(w, http.StatusOK, ChallengeResponse{Challenge: "test-challenge", ExpiresAt: 1 << 40})
+				return
+			}
+			var body json.RawMessage
+			json.NewDecoder(r.Body).Decode(&body)
+			json.Unmarshal(body, &got)
+			json.Unmarshal(body, &raw)
+			writeJSON(w, http.StatusOK, VerifyResponse{SessionToken: "session", ExpiresAt: 1 << 40})
+		}))
+		defer backend.Close()
+
+		authenticator, err := NewAuthenticator(newTestPrivateKey(t), NewHTTPClient(backend.URL), WithEIP712Signing(eip712))
+		if err != nil {
+			t.Fatalf("NewAuthenticator() error = %v", err)
+		}
+		if _, _, err := authenticator.Authenticate(); err != nil {
+			t.Fatalf("Authenticate() error = %v", err)
+		}
+
+		scheme := auth.ChallengeSchemePersonalSign
+		if eip712 {
+			scheme = auth.ChallengeSchemeEIP712
+			if got.SignatureType != "eip712" {
+				t.Errorf("signature_type = %q, want eip712", got.SignatureType)
+			}
+		} else if _, ok := raw["signature_type"]; ok {
+			t.Error("personal_sign requests should not carry signature_type")
+		}
+
+		recovered, err := auth.RecoverChallengeSigner(scheme, got.WalletAddress, got.Challenge, got.Signature)
+		if err != nil || recovered.Hex() != authenticator.GetAddress() {
+			t.Errorf("eip712=%v: backend recovered %s, %v; want %s", eip712, recovered.Hex(), err, authenticator.GetAddress())
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+)
+
+// nativeTokenDecimals is the number of decimals of every supported chain's native token
+const nativeTokenDecimals = 18
+
+// chainSymbols maps chain IDs to their native token symbol
+var chainSymbols = map[string]string{
+	"3338": "PEAQ", // peaq mainnet
+	"9990": "AGNG", // agung testnet
+	"1":    "ETH",
+}
+
+// ErrInsufficientBalance is returned when the wallet cannot pay the mint price
+var ErrInsufficientBalance = errors.New("insufficient balance")
+
+// ChainSymbol returns the native token symbol for a chain ID, defaulting to PEAQ
+func ChainSymbol(chainID string) string {
+	if symbol, ok := chainSymbols[chainID]; ok {
+		return symbol
+	}
+	return "PEAQ"
+}
+
+// FormatTokenAmount renders a wei amount in whole tokens, trimming trailing
+// zeros but keeping at least one decimal ("1.2", "2.0", "0.000001")
+func FormatTokenAmount(wei *big.Int) string {
+	if wei == nil {
+		return "0.0"
+	}
+
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(nativeTokenDecimals), nil)
+	whole, frac := new(big.Int).QuoRem(new(big.Int).Abs(wei), unit, new(big.Int))
+
+	decimals := strings.TrimRight(fmt.Sprintf("%0*s", nativeTokenDecimals, frac.String()), "0")
+	if decimals == "" {
+		decimals = "0"
+	}
+
+	sign := ""
+	if wei.Sign() < 0 {
+		sign = "-"
+	}
+	return sign + whole.String() + "." + decimals
+}
+
+// insufficientBalanceError builds the ErrInsufficientBalance error shown to users
+func insufficientBalanceError(have, need *big.Int, symbol string) error {
+	return fmt.Errorf("%w: have %s %s, need %s %s", ErrInsufficientBalance,
+		FormatTokenAmount(have), symbol, FormatTokenAmount(need), symbol)
+}
+
+// BalanceCheck is the result of Minter.CheckBalance
+type BalanceCheck struct {
+	Wallet      string
+	Balance     *big.Int // Wallet balance in wei
+	Required    *big.Int // Mint price in wei
+	Symbol      string   // Native token symbol, e.g. "PEAQ"
+	Sufficient  bool
+	PriceSource MintPriceSource
+}
+
+// FormattedBalance returns the balance in whole tokens with the symbol, e.g. "1.2 PEAQ"
+func (b *BalanceCheck) FormattedBalance() string {
+	return FormatTokenAmount(b.Balance) + " " + b.Symbol
+}
+
+// FormattedRequired returns the mint price in whole tokens with the symbol, e.g. "2.0 PEAQ"
+func (b *BalanceCheck) FormattedRequired() string {
+	return FormatTokenAmount(b.Required) + " " + b.Symbol
+}
+
+// Err returns an ErrInsufficientBalance error if the balance does not cover the mint price
+func (b *BalanceCheck) Err() error {
+	if b.Sufficient {
+		return nil
+	}
+	return insufficientBalanceError(b.Balance, b.Required, b.Symbol)
+}
+
+// CheckBalance compares the wallet balance against the mint price on the
+// backend's NFT contract. It requires an RPC endpoint.
+func (m *Minter) CheckBalance(ctx context.Context) (*BalanceCheck, error) {
+	chainClient, contract, err := m.contractChainClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer chainClient.Close()
+
+	balance, err := chainClient.GetBalance(ctx)
+	if err != nil {
+		return nil, err
+	}
+	required, source := chainClient.ResolveMintPrice(ctx, m.config.MintPrice)
+
+	return &BalanceCheck{
+		Wallet:      chainClient.GetAddress(),
+		Balance:     balance,
+		Required:    required,
+		Symbol:      ChainSymbol(contract.ChainID),
+		Sufficient:  balance.Cmp(required) >= 0,
+		PriceSource: source,
+	}, nil
+}
+
+// preflightBalance fails fast when the wallet cannot pay for the mint. A check
+// that cannot run is only logged; ExecuteMint checks the balance again.
+func (m *Minter) preflightBalance(ctx context.Context) error {
+	check, err := m.CheckBalance(ctx)
+	if err != nil {
+		log.Printf("⚠️ Skipping balance pre-flight: %v", err)
+		return nil
+	}
+
+	log.Printf("💰 Balance: %s, mint price: %s", check.FormattedBalance(), check.FormattedRequired())
+	return check.Err()
+}
+
+// contractChainClient connects to the NFT contract reported by the backend
+// through the configured RPC endpoint
+func (m *Minter) contractChainClient(ctx context.Context) (ChainOps, *ContractConfigResponse, error) {
+	if m.config.RPCEndpoint == "" {
+		return nil, nil, fmt.Errorf("RPC endpoint is required for on-chain operations")
+	}
+
+	contract, err := m.httpClient.GetContractConfigCtx(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkExpectedContract("backend", m.config.ExpectedContract, m.config.ExpectedChainID, contract.ContractAddress, contract.ChainID); err != nil {
+		// Fetch again next time in case the backend is fixed meanwhile
+		m.httpClient.InvalidateContractConfig()
+		return nil, nil, err
+	}
+
+	chainClient, err := m.newChain(m.config.RPCEndpoint, contract.ContractAddress, contract.ChainID, m.signer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create chain client: %w", err)
+	}
+	chainClient.SetReceiptWait(m.config.ReceiptTimeout, m.config.ReceiptPollInterval)
+	return chainClient, contract, nil
+}
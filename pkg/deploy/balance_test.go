@@ -0,0 +1,191 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// peaq converts a decimal token amount such as "1.2" to wei
+func peaq(t *testing.T, amount string) *big.Int {
+	t.Helper()
+	whole, frac, _ := strings.Cut(amount, ".")
+	wei, ok := new(big.Int).SetString(whole+frac+strings.Repeat("0", nativeTokenDecimals-len(frac)), 10)
+	if !ok {
+		t.Fatalf("invalid amount %q", amount)
+	}
+	return wei
+}
+
+// newBalanceChain starts a JSON-RPC node reporting balance for every wallet
+// and price from the contract's mintPrice()
+func newBalanceChain(t *testing.T, balance, price *big.Int) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		var result interface{}
+		switch req.Method {
+		case "eth_getBalance":
+			result = hexutil.EncodeBig(balance)
+		case "eth_call":
+			result = hexutil.Encode(common.BigToHash(price).Bytes())
+		default:
+			t.Errorf("unexpected RPC method %s", req.Method)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newBalanceTestMinter(t *testing.T, rpcURL, chainID string, handlers map[string]http.HandlerFunc) *Minter {
+	t.Helper()
+	all := map[string]http.HandlerFunc{
+		"/api/contract/config": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, ContractConfigResponse{ContractAddress: testContractAddress, ChainID: chainID})
+		},
+	}
+	for path, handler := range handlers {
+		all[path] = handler
+	}
+	backend := newFakeBackend(t, all)
+
+	minter, err := NewMinter(&MintConfig{
+		PrivateKey:  newTestPrivateKey(t),
+		BackendURL:  backend.URL,
+		RPCEndpoint: rpcURL,
+	})
+	if err != nil {
+		t.Fatalf("NewMinter() error = %v", err)
+	}
+	minter.walClient = NewWALClientWithDir(t.TempDir())
+	return minter
+}
+
+func TestFormatTokenAmount(t *testing.T) {
+	tests := []struct {
+		wei  *big.Int
+		want string
+	}{
+		{nil, "0.0"},
+		{big.NewInt(0), "0.0"},
+		{big.NewInt(1), "0.000000000000000001"},
+		{big.NewInt(1e12), "0.000001"},
+		{big.NewInt(12e17), "1.2"},
+		{big.NewInt(2e18), "2.0"},
+		{big.NewInt(1999e15), "1.999"},
+		{new(big.Int).Mul(big.NewInt(1234567), big.NewInt(1e18)), "1234567.0"},
+		{big.NewInt(-5e17), "-0.5"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatTokenAmount(tt.wei); got != tt.want {
+			t.Errorf("FormatTokenAmount(%v) = %q, want %q", tt.wei, got, tt.want)
+		}
+	}
+}
+
+func TestChainSymbol(t *testing.T) {
+	for chainID, want := range map[string]string{"3338": "PEAQ", "9990": "AGNG", "1": "ETH", "unknown": "PEAQ"} {
+		if got := ChainSymbol(chainID); got != want {
+			t.Errorf("ChainSymbol(%q) = %q, want %q", chainID, got, want)
+		}
+	}
+}
+
+func TestMinter_CheckBalance(t *testing.T) {
+	tests := []struct {
+		name           string
+		balance        string
+		chainID        string
+		wantSufficient bool
+		wantBalance    string
+		wantRequired   string
+	}{
+		{"short", "1.2", "3338", false, "1.2 PEAQ", "2.0 PEAQ"},
+		{"exact", "2", "3338", true, "2.0 PEAQ", "2.0 PEAQ"},
+		{"plenty", "150.25", "9990", true, "150.25 AGNG", "2.0 AGNG"},
+		{"empty", "0", "3338", false, "0.0 PEAQ", "2.0 PEAQ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := newBalanceChain(t, peaq(t, tt.balance), peaq(t, "2"))
+			minter := newBalanceTestMinter(t, chain.URL, tt.chainID, nil)
+
+			check, err := minter.CheckBalance(context.Background())
+			if err != nil {
+				t.Fatalf("CheckBalance() error = %v", err)
+			}
+			if check.Sufficient != tt.wantSufficient {
+				t.Errorf("Sufficient = %v, want %v", check.Sufficient, tt.wantSufficient)
+			}
+			if check.FormattedBalance() != tt.wantBalance || check.FormattedRequired() != tt.wantRequired {
+				t.Errorf("formatted = %q / %q, want %q / %q",
+					check.FormattedBalance(), check.FormattedRequired(), tt.wantBalance, tt.wantRequired)
+			}
+			if check.PriceSource != MintPriceSourceContract {
+				t.Errorf("PriceSource = %s, want contract", check.PriceSource)
+			}
+			if (check.Err() == nil) != tt.wantSufficient {
+				t.Errorf("Err() = %v, want error only when insufficient", check.Err())
+			}
+		})
+	}
+}
+
+func TestMinter_CheckBalanceRequiresRPCEndpoint(t *testing.T) {
+	t.Setenv("RPC_ENDPOINT", "")
+	minter := newBalanceTestMinter(t, "", "3338", nil)
+
+	if _, err := minter.CheckBalance(context.Background()); err == nil {
+		t.Fatal("expected an error without an RPC endpoint")
+	}
+}
+
+func TestSyncAndMint_InsufficientBalanceFailsBeforeDeploy(t *testing.T) {
+	chain := newBalanceChain(t, peaq(t, "1.2"), peaq(t, "2"))
+
+	var deployCalls, abandonCalls int
+	minter := newBalanceTestMinter(t, chain.URL, "3338", map[string]http.HandlerFunc{
+		"/api/sdk/agent/sync": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, SyncResponse{Status: "MINT_REQUIRED"})
+		},
+		"/api/sdk/agent/deploy": func(w http.ResponseWriter, r *http.Request) {
+			deployCalls++
+			writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "should not deploy"})
+		},
+		"/api/sdk/agent/abandon": func(w http.ResponseWriter, r *http.Request) {
+			abandonCalls++
+			writeJSON(w, http.StatusOK, AbandonResponse{Success: true})
+		},
+	})
+
+	config := conflictTestConfig()
+	_, err := minter.syncAndMint(context.Background(), config, GenerateConfigHash(config), "")
+	if !errors.Is(err, ErrInsufficientBalance) {
+		t.Fatalf("expected ErrInsufficientBalance, got %v", err)
+	}
+	if want := "insufficient balance: have 1.2 PEAQ, need 2.0 PEAQ"; err.Error() != want {
+		t.Errorf("error = %q, want %q", err.Error(), want)
+	}
+	if deployCalls != 0 {
+		t.Errorf("expected no deploy call, got %d", deployCalls)
+	}
+	if abandonCalls != 1 {
+		t.Errorf("expected the fresh reservation to be abandoned, got %d abandon calls", abandonCalls)
+	}
+}
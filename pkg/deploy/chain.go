@@ -2,8 +2,9 @@ package deploy
 
 import (
 	"context"
-	"crypto/ecdsa"
+	"errors"
 	"fmt"
+	"log"
 	"math/big"
 	"strings"
 	"time"
@@ -13,7 +14,6 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
@@ -22,8 +22,37 @@ type ChainClient struct {
 	client          *ethclient.Client
 	contractAddress common.Address
 	chainID         *big.Int
-	privateKey      *ecdsa.PrivateKey
+	signer          Signer
 	address         common.Address
+
+	onTxSent            func(txHash string, nonce uint64) // Called once a transaction or a replacement is broadcast (optional)
+	speedUpAfter        time.Duration                     // Resubmit pending transactions with a higher gas price after this long (0 = never)
+	receiptTimeout      time.Duration                     // How long to wait for a receipt after broadcasting
+	receiptPollInterval time.Duration                     // How often to poll for the receipt
+}
+
+// Defaults for waiting on the receipt of a broadcast transaction
+const (
+	DefaultReceiptTimeout      = 5 * time.Minute
+	DefaultReceiptPollInterval = 2 * time.Second
+)
+
+// ErrReceiptTimeout matches every ReceiptTimeoutError
+var ErrReceiptTimeout = errors.New("receipt timeout, tx may still confirm")
+
+// ReceiptTimeoutError is returned when a broadcast transaction has no receipt
+// within the receipt timeout. The transaction may still be mined; TxHash is
+// the latest hash sent, a replacement if the transaction was sped up.
+type ReceiptTimeoutError struct {
+	TxHash string
+}
+
+func (e *ReceiptTimeoutError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrReceiptTimeout, e.TxHash)
+}
+
+func (e *ReceiptTimeoutError) Unwrap() error {
+	return ErrReceiptTimeout
 }
 
 // MintResult contains the result of a mint operation
@@ -44,22 +73,17 @@ const (
 	MintStatusUpdated        = "UPDATED"
 )
 
-// NewChainClient creates a new chain client
+// NewChainClient creates a new chain client backed by a raw private key
 func NewChainClient(rpcEndpoint, contractAddress, chainIDStr, privateKeyHex string) (*ChainClient, error) {
-	// Parse private key
-	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(privateKeyHex, "0x"))
+	signer, err := NewPrivateKeySigner(privateKeyHex)
 	if err != nil {
-		return nil, fmt.Errorf("invalid private key: %w", err)
+		return nil, err
 	}
+	return NewChainClientWithSigner(rpcEndpoint, contractAddress, chainIDStr, signer)
+}
 
-	// Derive address
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		return nil, fmt.Errorf("failed to derive public key")
-	}
-	address := crypto.PubkeyToAddress(*publicKeyECDSA)
-
+// NewChainClientWithSigner creates a chain client that signs transactions with signer
+func NewChainClientWithSigner(rpcEndpoint, contractAddress, chainIDStr string, signer Signer) (*ChainClient, error) {
 	// Parse chain ID
 	chainID, ok := new(big.Int).SetString(chainIDStr, 10)
 	if !ok {
@@ -73,11 +97,13 @@ func NewChainClient(rpcEndpoint, contractAddress, chainIDStr, privateKeyHex stri
 	}
 
 	return &ChainClient{
-		client:          client,
-		contractAddress: common.HexToAddress(contractAddress),
-		chainID:         chainID,
-		privateKey:      privateKey,
-		address:         address,
+		client:              client,
+		contractAddress:     common.HexToAddress(contractAddress),
+		chainID:             chainID,
+		signer:              signer,
+		address:             signer.Address(),
+		receiptTimeout:      DefaultReceiptTimeout,
+		receiptPollInterval: DefaultReceiptPollInterval,
 	}, nil
 }
 
@@ -152,25 +178,49 @@ func (c *ChainClient) GetTokenID(ctx context.Context) (uint64, error) {
 	return tokenID.Uint64(), nil
 }
 
-// ExecuteMint executes the on-chain mint transaction
-func (c *ChainClient) ExecuteMint(ctx context.Context, signature string, mintPrice *big.Int) (*MintResult, error) {
-	// Query mint price from contract if not provided
-	if mintPrice == nil {
-		var err error
-		mintPrice, err = c.GetMintPrice(ctx)
-		if err != nil {
-			// Fallback to 2 PEAQ if contract call fails
-			mintPrice = new(big.Int).Mul(big.NewInt(2), new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))
-		}
+// MintPriceSource identifies where the mint price came from
+type MintPriceSource string
+
+const (
+	MintPriceSourceOverride MintPriceSource = "override" // Set explicitly in the config
+	MintPriceSourceContract MintPriceSource = "contract" // Read from the contract's mintPrice()
+	MintPriceSourceDefault  MintPriceSource = "default"  // DefaultMintPrice, used when the contract call fails
+)
+
+// DefaultMintPrice returns the fallback mint price (2 PEAQ), used only when no
+// override is configured and the contract's mintPrice() cannot be read
+func DefaultMintPrice() *big.Int {
+	return new(big.Int).Mul(big.NewInt(2), new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))
+}
+
+// ResolveMintPrice returns override if set, otherwise the contract's
+// mintPrice(), otherwise DefaultMintPrice
+func (c *ChainClient) ResolveMintPrice(ctx context.Context, override *big.Int) (*big.Int, MintPriceSource) {
+	if override != nil {
+		return override, MintPriceSourceOverride
+	}
+
+	price, err := c.GetMintPrice(ctx)
+	if err != nil || price == nil {
+		log.Printf("⚠️ Failed to read mintPrice() from contract (%v), using default", err)
+		return DefaultMintPrice(), MintPriceSourceDefault
 	}
+	return price, MintPriceSourceContract
+}
+
+// ExecuteMint executes the on-chain mint transaction. A nil mintPrice is
+// resolved with ResolveMintPrice.
+func (c *ChainClient) ExecuteMint(ctx context.Context, signature string, mintPrice *big.Int) (*MintResult, error) {
+	mintPrice, source := c.ResolveMintPrice(ctx, mintPrice)
+	log.Printf("💰 Mint price: %s wei (%s)", mintPrice.String(), source)
 
 	// Check wallet balance
-	balance, err := c.client.BalanceAt(ctx, c.address, nil)
+	balance, err := c.GetBalance(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check balance: %w", err)
+		return nil, err
 	}
 	if balance.Cmp(mintPrice) < 0 {
-		return nil, fmt.Errorf("insufficient balance: have %s wei, need %s wei for mint", balance.String(), mintPrice.String())
+		return nil, insufficientBalanceError(balance, mintPrice, ChainSymbol(c.chainID.String()))
 	}
 
 	// ABI for mint(address to, bytes signature)
@@ -191,27 +241,47 @@ func (c *ChainClient) ExecuteMint(ctx context.Context, signature string, mintPri
 		return nil, fmt.Errorf("failed to pack mint call: %w", err)
 	}
 
+	receipt, txHash, err := c.sendContractTx(ctx, "mint", mintPrice, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract token ID from Minted or Transfer event
+	tokenID, err := c.ExtractTokenIDFromReceipt(receipt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract token ID: %w", err)
+	}
+
+	return &MintResult{
+		TokenID: tokenID,
+		TxHash:  txHash,
+	}, nil
+}
+
+// sendContractTx signs and sends a transaction calling the contract with data
+// and waits for a successful receipt. action names the call in revert errors.
+func (c *ChainClient) sendContractTx(ctx context.Context, action string, value *big.Int, data []byte) (*types.Receipt, string, error) {
 	// Get nonce
 	nonce, err := c.client.PendingNonceAt(ctx, c.address)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get nonce: %w", err)
+		return nil, "", fmt.Errorf("failed to get nonce: %w", err)
 	}
 
 	// Get gas price
 	gasPrice, err := c.client.SuggestGasPrice(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get gas price: %w", err)
+		return nil, "", fmt.Errorf("failed to get gas price: %w", err)
 	}
 
 	// Estimate gas (also validates the tx won't revert)
 	estimatedGas, err := c.client.EstimateGas(ctx, ethereum.CallMsg{
 		From:  c.address,
 		To:    &c.contractAddress,
-		Value: mintPrice,
+		Value: value,
 		Data:  data,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("mint would revert: %w", err)
+		return nil, "", fmt.Errorf("%s would revert: %w", action, err)
 	}
 	gasLimit := estimatedGas * 120 / 100 // 20% safety margin
 
@@ -219,54 +289,70 @@ func (c *ChainClient) ExecuteMint(ctx context.Context, signature string, mintPri
 	tx := types.NewTransaction(
 		nonce,
 		c.contractAddress,
-		mintPrice,
+		value,
 		gasLimit,
 		gasPrice,
 		data,
 	)
 
 	// Sign transaction
-	signer := types.NewEIP155Signer(c.chainID)
-	signedTx, err := types.SignTx(tx, signer, c.privateKey)
+	signedTx, err := c.signer.SignTx(tx, c.chainID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+		return nil, "", fmt.Errorf("failed to sign transaction: %w", err)
 	}
 
 	// Send transaction
 	if err := c.client.SendTransaction(ctx, signedTx); err != nil {
-		return nil, fmt.Errorf("failed to send transaction: %w", err)
+		return nil, "", fmt.Errorf("failed to send transaction: %w", err)
 	}
 
-	txHash := signedTx.Hash().Hex()
+	if c.onTxSent != nil {
+		c.onTxSent(signedTx.Hash().Hex(), nonce)
+	}
 
 	// Wait for receipt with timeout
-	receiptCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	receiptCtx, cancel := context.WithTimeout(ctx, c.receiptTimeout)
 	defer cancel()
 
-	receipt, err := c.waitForReceipt(receiptCtx, signedTx.Hash())
+	receipt, latest, err := c.waitWithSpeedUp(receiptCtx, signedTx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get transaction receipt: %w", err)
+		return nil, "", receiptWaitError(ctx, err, latest)
 	}
 
 	if receipt.Status != types.ReceiptStatusSuccessful {
-		return nil, fmt.Errorf("transaction reverted")
+		return nil, "", fmt.Errorf("transaction reverted")
 	}
 
-	// Extract token ID from Minted or Transfer event
-	tokenID, err := c.ExtractTokenIDFromReceipt(receipt)
-	if err != nil {
-		return nil, fmt.Errorf("failed to extract token ID: %w", err)
+	// A replacement may have been mined instead of the original
+	return receipt, receipt.TxHash.Hex(), nil
+}
+
+// SetSpeedUpAfter enables resubmitting transactions that are still pending
+// after d with a bumped gas price. Zero or negative disables it.
+func (c *ChainClient) SetSpeedUpAfter(d time.Duration) {
+	c.speedUpAfter = d
+}
+
+// SetReceiptWait sets how long to wait for the receipt of a broadcast
+// transaction and how often to poll for it. Zero keeps the current value.
+func (c *ChainClient) SetReceiptWait(timeout, pollInterval time.Duration) {
+	if timeout > 0 {
+		c.receiptTimeout = timeout
+	}
+	if pollInterval > 0 {
+		c.receiptPollInterval = pollInterval
 	}
+}
 
-	return &MintResult{
-		TokenID: tokenID,
-		TxHash:  txHash,
-	}, nil
+// SetOnTxSent registers fn to run each time a transaction or a replacement is
+// broadcast, e.g. to record the pending hash and nonce
+func (c *ChainClient) SetOnTxSent(fn func(txHash string, nonce uint64)) {
+	c.onTxSent = fn
 }
 
-// waitForReceipt polls for transaction receipt
-func (c *ChainClient) waitForReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
-	ticker := time.NewTicker(2 * time.Second)
+// waitForReceipt polls until one of the transactions has a receipt
+func (c *ChainClient) waitForReceipt(ctx context.Context, txHashes ...common.Hash) (*types.Receipt, error) {
+	ticker := time.NewTicker(c.receiptPollInterval)
 	defer ticker.Stop()
 
 	for {
@@ -274,15 +360,26 @@ func (c *ChainClient) waitForReceipt(ctx context.Context, txHash common.Hash) (*
 		case <-ctx.Done():
 			return nil, ctx.Err()
 		case <-ticker.C:
-			receipt, err := c.client.TransactionReceipt(ctx, txHash)
-			if err == nil {
-				return receipt, nil
+			for _, txHash := range txHashes {
+				receipt, err := c.client.TransactionReceipt(ctx, txHash)
+				if err == nil {
+					return receipt, nil
+				}
 			}
 			// Continue polling if receipt not found yet
 		}
 	}
 }
 
+// receiptWaitError reports a failed wait for the receipt of txHash, as a
+// ReceiptTimeoutError when the receipt timeout expired before ctx did
+func receiptWaitError(ctx context.Context, err error, txHash common.Hash) error {
+	if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+		return &ReceiptTimeoutError{TxHash: txHash.Hex()}
+	}
+	return fmt.Errorf("failed to get transaction receipt: %w", err)
+}
+
 // extractTokenIDFromReceipt extracts the token ID from the Minted event in the receipt
 func (c *ChainClient) extractTokenIDFromReceipt(receipt *types.Receipt, contractABI *abi.ABI) (uint64, error) {
 	// Find Minted event
@@ -330,6 +427,15 @@ func (c *ChainClient) GetMintPrice(ctx context.Context) (*big.Int, error) {
 	return price, nil
 }
 
+// GetBalance returns the wallet's native token balance in wei
+func (c *ChainClient) GetBalance(ctx context.Context) (*big.Int, error) {
+	balance, err := c.client.BalanceAt(ctx, c.address, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check balance: %w", err)
+	}
+	return balance, nil
+}
+
 // GetAddress returns the wallet address
 func (c *ChainClient) GetAddress() string {
 	return c.address.Hex()
@@ -345,6 +451,25 @@ func (c *ChainClient) GetTransactionReceipt(ctx context.Context, txHash string)
 	return receipt, nil
 }
 
+// IsTransactionDropped reports whether a transaction without a receipt can no
+// longer be mined: the node does not know it and the wallet's pending nonce has
+// moved past its nonce, e.g. because it was underpriced or replaced
+func (c *ChainClient) IsTransactionDropped(ctx context.Context, txHash string, nonce uint64) (bool, error) {
+	_, _, err := c.client.TransactionByHash(ctx, common.HexToHash(txHash))
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, ethereum.NotFound) {
+		return false, fmt.Errorf("failed to look up transaction: %w", err)
+	}
+
+	pendingNonce, err := c.client.PendingNonceAt(ctx, c.address)
+	if err != nil {
+		return false, fmt.Errorf("failed to get nonce: %w", err)
+	}
+	return pendingNonce > nonce, nil
+}
+
 // ExtractTokenIDFromReceipt extracts the token ID from Minted or Transfer event in the receipt
 func (c *ChainClient) ExtractTokenIDFromReceipt(receipt *types.Receipt) (uint64, error) {
 	// Try Minted event first (custom event)
@@ -0,0 +1,83 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ChainOps is the on-chain side of minting and deploying. ChainClient
+// implements it against a live RPC endpoint; tests can inject a fake through
+// MintConfig.ChainFactory or DeployConfig.ChainFactory.
+type ChainOps interface {
+	// Close releases the RPC connection
+	Close()
+	// GetAddress returns the wallet address transactions are sent from
+	GetAddress() string
+	// GetBalance returns the wallet's native token balance
+	GetBalance(ctx context.Context) (*big.Int, error)
+	// ResolveMintPrice returns override if set, else the contract's mint price
+	ResolveMintPrice(ctx context.Context, override *big.Int) (*big.Int, MintPriceSource)
+
+	// HasAccess reports whether the wallet owns an agent NFT
+	HasAccess(ctx context.Context) (bool, error)
+	// GetTokenID returns the first agent NFT owned by the wallet
+	GetTokenID(ctx context.Context) (uint64, error)
+	// OwnerOf returns the owner of an agent NFT
+	OwnerOf(ctx context.Context, tokenID uint64) (common.Address, error)
+
+	// VerifyMintSignature checks that the backend signature for nonce was
+	// produced by the contract's signer, failing with ErrMintSignatureMismatch.
+	// It is only called when MintConfig.VerifyMintSignature is set.
+	VerifyMintSignature(ctx context.Context, signature string, nonce uint64) error
+	// ExecuteMint mints with the backend signature and waits for the receipt
+	ExecuteMint(ctx context.Context, signature string, mintPrice *big.Int) (*MintResult, error)
+	// GetTransactionReceipt returns the receipt of a mined transaction
+	GetTransactionReceipt(ctx context.Context, txHash string) (*types.Receipt, error)
+	// IsTransactionDropped reports whether a transaction without a receipt
+	// can no longer be mined
+	IsTransactionDropped(ctx context.Context, txHash string, nonce uint64) (bool, error)
+	// ExtractTokenIDFromReceipt returns the token ID minted by a receipt
+	ExtractTokenIDFromReceipt(receipt *types.Receipt) (uint64, error)
+
+	// TransferAgent transfers an agent NFT owned by the wallet
+	TransferAgent(ctx context.Context, to string, tokenID uint64) (string, error)
+	// BurnAgent burns an agent NFT owned by the wallet
+	BurnAgent(ctx context.Context, tokenID uint64) (string, error)
+
+	// SetSpeedUpAfter sets how long a mint may stay pending before it is resubmitted
+	SetSpeedUpAfter(d time.Duration)
+	// SetReceiptWait sets how long and how often to poll for a receipt
+	SetReceiptWait(timeout, pollInterval time.Duration)
+	// SetOnTxSent registers a callback run each time a transaction is broadcast
+	SetOnTxSent(fn func(txHash string, nonce uint64))
+}
+
+// ChainFactory connects ChainOps to the NFT contract at contractAddress on
+// chainID through rpcEndpoint, signing with signer
+type ChainFactory func(rpcEndpoint, contractAddress, chainID string, signer Signer) (ChainOps, error)
+
+// NewChainOps is the default ChainFactory, backed by a ChainClient
+func NewChainOps(rpcEndpoint, contractAddress, chainID string, signer Signer) (ChainOps, error) {
+	client, err := NewChainClientWithSigner(rpcEndpoint, contractAddress, chainID, signer)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// requireOwnership fails with ErrNotTokenOwner unless the wallet of chain owns tokenID
+func requireOwnership(ctx context.Context, chain ChainOps, tokenID uint64) error {
+	owner, err := chain.OwnerOf(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	if owner != common.HexToAddress(chain.GetAddress()) {
+		return fmt.Errorf("%w: token %d is owned by %s", ErrNotTokenOwner, tokenID, owner.Hex())
+	}
+	return nil
+}
@@ -0,0 +1,402 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakeChainOps is an in-memory ChainOps. Receipts are looked up by hash and
+// their token ID comes from the receipt's block number, so tests can script
+// what WAL recovery finds on-chain without an RPC endpoint.
+type fakeChainOps struct {
+	mu sync.Mutex
+
+	address    common.Address
+	balance    *big.Int
+	mintPrice  *big.Int
+	ownedToken *uint64 // token owned by the wallet, nil if none
+	owners     map[uint64]common.Address
+	receipts   map[string]*types.Receipt
+	dropped    map[string]bool
+
+	signatureErr error       // returned by VerifyMintSignature
+	mintResult   *MintResult // returned by ExecuteMint
+	mintErr      error       // returned by ExecuteMint after broadcasting
+	mintTx       string      // hash ExecuteMint reports as broadcast
+	mintNonce    uint64
+
+	onTxSent            func(txHash string, nonce uint64)
+	speedUpAfter        time.Duration
+	receiptTimeout      time.Duration
+	receiptPollInterval time.Duration
+	mints               int
+	closed              bool
+}
+
+func newFakeChainOps(address common.Address) *fakeChainOps {
+	return &fakeChainOps{
+		address:   address,
+		balance:   big.NewInt(1e18),
+		mintPrice: DefaultMintPrice(),
+		owners:    make(map[uint64]common.Address),
+		receipts:  make(map[string]*types.Receipt),
+		dropped:   make(map[string]bool),
+	}
+}
+
+// factory returns a ChainFactory that always connects to c
+func (c *fakeChainOps) factory() ChainFactory {
+	return func(rpcEndpoint, contractAddress, chainID string, signer Signer) (ChainOps, error) {
+		return c, nil
+	}
+}
+
+// setReceipt records a mined receipt for txHash minting tokenID
+func (c *fakeChainOps) setReceipt(txHash string, status uint64, tokenID uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.receipts[txHash] = &types.Receipt{
+		Status:      status,
+		TxHash:      common.HexToHash(txHash),
+		BlockNumber: new(big.Int).SetUint64(tokenID),
+	}
+}
+
+func (c *fakeChainOps) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+}
+
+func (c *fakeChainOps) GetAddress() string { return c.address.Hex() }
+
+func (c *fakeChainOps) GetBalance(ctx context.Context) (*big.Int, error) {
+	return c.balance, nil
+}
+
+func (c *fakeChainOps) ResolveMintPrice(ctx context.Context, override *big.Int) (*big.Int, MintPriceSource) {
+	if override != nil {
+		return override, MintPriceSourceOverride
+	}
+	return c.mintPrice, MintPriceSourceContract
+}
+
+func (c *fakeChainOps) HasAccess(ctx context.Context) (bool, error) {
+	return c.ownedToken != nil, nil
+}
+
+func (c *fakeChainOps) GetTokenID(ctx context.Context) (uint64, error) {
+	if c.ownedToken == nil {
+		return 0, errors.New("wallet owns no agent NFT")
+	}
+	return *c.ownedToken, nil
+}
+
+func (c *fakeChainOps) OwnerOf(ctx context.Context, tokenID uint64) (common.Address, error) {
+	owner, ok := c.owners[tokenID]
+	if !ok {
+		return common.Address{}, fmt.Errorf("token %d does not exist", tokenID)
+	}
+	return owner, nil
+}
+
+func (c *fakeChainOps) VerifyMintSignature(ctx context.Context, signature string, nonce uint64) error {
+	return c.signatureErr
+}
+
+func (c *fakeChainOps) ExecuteMint(ctx context.Context, signature string, mintPrice *big.Int) (*MintResult, error) {
+	c.mu.Lock()
+	c.mints++
+	onTxSent := c.onTxSent
+	c.mu.Unlock()
+
+	if c.mintTx != "" && onTxSent != nil {
+		onTxSent(c.mintTx, c.mintNonce)
+	}
+	if c.mintErr != nil {
+		return nil, c.mintErr
+	}
+	return c.mintResult, nil
+}
+
+func (c *fakeChainOps) GetTransactionReceipt(ctx context.Context, txHash string) (*types.Receipt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	receipt, ok := c.receipts[txHash]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return receipt, nil
+}
+
+func (c *fakeChainOps) IsTransactionDropped(ctx context.Context, txHash string, nonce uint64) (bool, error) {
+	return c.dropped[txHash], nil
+}
+
+func (c *fakeChainOps) ExtractTokenIDFromReceipt(receipt *types.Receipt) (uint64, error) {
+	return receipt.BlockNumber.Uint64(), nil
+}
+
+func (c *fakeChainOps) TransferAgent(ctx context.Context, to string, tokenID uint64) (string, error) {
+	if err := requireOwnership(ctx, c, tokenID); err != nil {
+		return "", err
+	}
+	c.owners[tokenID] = common.HexToAddress(to)
+	return "0x" + strings.Repeat("cd", 32), nil
+}
+
+func (c *fakeChainOps) BurnAgent(ctx context.Context, tokenID uint64) (string, error) {
+	if err := requireOwnership(ctx, c, tokenID); err != nil {
+		return "", err
+	}
+	delete(c.owners, tokenID)
+	return "0x" + strings.Repeat("ef", 32), nil
+}
+
+func (c *fakeChainOps) SetSpeedUpAfter(d time.Duration) { c.speedUpAfter = d }
+
+func (c *fakeChainOps) SetReceiptWait(timeout, pollInterval time.Duration) {
+	c.receiptTimeout, c.receiptPollInterval = timeout, pollInterval
+}
+
+func (c *fakeChainOps) SetOnTxSent(fn func(txHash string, nonce uint64)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onTxSent = fn
+}
+
+// newFakeChainMinter creates a minter whose on-chain calls go to a fakeChainOps
+func newFakeChainMinter(t *testing.T, backendURL string) (*Minter, *fakeChainOps) {
+	t.Helper()
+	signer, err := NewPrivateKeySigner(newTestPrivateKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := newFakeChainOps(signer.Address())
+	minter, err := NewMinter(&MintConfig{
+		BackendURL:            backendURL,
+		Signer:                signer,
+		ChainFactory:          chain.factory(),
+		WALStore:              NewMemoryWALStore(),
+		SchemaCacheDir:        t.TempDir(),
+		PendingTxTimeout:      30 * time.Millisecond,
+		PendingTxPollInterval: 10 * time.Millisecond,
+		ConfirmRetryBackoff:   time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewMinter() error = %v", err)
+	}
+	return minter, chain
+}
+
+// pendingMintWAL returns a WAL entry for a mint broadcast as txHash
+func pendingMintWAL(wallet, txHash string) *WALEntry {
+	nonce := uint64(4)
+	return &WALEntry{
+		AgentID:         "fake-agent",
+		Wallet:          wallet,
+		State:           WALStateMinting,
+		PendingTxHash:   txHash,
+		PendingNonce:    &nonce,
+		ContractAddress: testContractAddress,
+		ChainID:         "3338",
+		RPCURL:          "http://rpc.invalid",
+		ConfigHash:      "wal-hash",
+	}
+}
+
+func TestMinter_RecoverFromWALConfirmsMinedTransaction(t *testing.T) {
+	backend := newUnconfirmedBackend(t, 0)
+	minter, chain := newFakeChainMinter(t, backend.URL)
+	txHash := "0x" + strings.Repeat("ab", 32)
+	chain.setReceipt(txHash, types.ReceiptStatusSuccessful, 12)
+
+	wal := pendingMintWAL(chain.GetAddress(), txHash)
+	minter.walClient.Save(wal)
+
+	result, err := minter.recoverFromWAL(context.Background(), wal, &AgentConfig{AgentID: "fake-agent"})
+	if err != nil {
+		t.Fatalf("recoverFromWAL() error = %v", err)
+	}
+	if result.Status != MintStatusMinted || result.TokenID != 12 || result.TxHash != txHash {
+		t.Errorf("result = %+v, want MINTED token 12 from %s", result, txHash)
+	}
+
+	confirms := backend.confirmed()
+	if len(confirms) != 1 || confirms[0].TokenID != 12 || confirms[0].ConfigHash != "wal-hash" {
+		t.Errorf("confirms = %+v, want token 12 with the WAL config hash", confirms)
+	}
+	if minter.walClient.Exists("fake-agent") {
+		t.Error("WAL should be deleted once the mint is confirmed")
+	}
+	if !chain.closed {
+		t.Error("chain connection was not closed")
+	}
+}
+
+func TestMinter_RecoverFromWALKeepsEntryWhenConfirmFails(t *testing.T) {
+	backend := newUnconfirmedBackend(t, 1+DefaultConfirmRetries)
+	minter, chain := newFakeChainMinter(t, backend.URL)
+	txHash := "0x" + strings.Repeat("ab", 32)
+	chain.setReceipt(txHash, types.ReceiptStatusSuccessful, 12)
+
+	wal := pendingMintWAL(chain.GetAddress(), txHash)
+	minter.walClient.Save(wal)
+
+	if _, err := minter.recoverFromWAL(context.Background(), wal, &AgentConfig{AgentID: "fake-agent"}); err != nil {
+		t.Fatalf("recoverFromWAL() error = %v", err)
+	}
+	if !minter.walClient.Exists("fake-agent") {
+		t.Error("WAL should be kept so the confirm is retried")
+	}
+}
+
+func TestMinter_RecoverFromWALRestartsAfterFailedTransaction(t *testing.T) {
+	var syncs int
+	backend := newFakeBackend(t, map[string]http.HandlerFunc{
+		"/api/sdk/agent/sync": func(w http.ResponseWriter, r *http.Request) {
+			syncs++
+			tokenID := int64(8)
+			writeJSON(w, http.StatusOK, SyncResponse{Status: "SYNCED", TokenID: &tokenID})
+		},
+	})
+	minter, chain := newFakeChainMinter(t, backend.URL)
+	txHash := "0x" + strings.Repeat("ab", 32)
+	chain.setReceipt(txHash, types.ReceiptStatusFailed, 0)
+
+	wal := pendingMintWAL(chain.GetAddress(), txHash)
+	minter.walClient.Save(wal)
+
+	result, err := minter.recoverFromWAL(context.Background(), wal, &AgentConfig{AgentID: "fake-agent"})
+	if err != nil {
+		t.Fatalf("recoverFromWAL() error = %v", err)
+	}
+	if syncs != 1 || result.Status != MintStatusAlreadyOwned {
+		t.Errorf("expected the mint to restart with a sync, got %d syncs and %+v", syncs, result)
+	}
+	if minter.walClient.Exists("fake-agent") {
+		t.Error("WAL for a failed transaction should be deleted")
+	}
+}
+
+func TestMinter_RecoverFromWALPendingTransaction(t *testing.T) {
+	backend := newFakeBackend(t, nil)
+	minter, chain := newFakeChainMinter(t, backend.URL)
+	txHash := "0x" + strings.Repeat("ab", 32)
+
+	wal := pendingMintWAL(chain.GetAddress(), txHash)
+	minter.walClient.Save(wal)
+
+	_, err := minter.recoverFromWAL(context.Background(), wal, &AgentConfig{AgentID: "fake-agent"})
+	if err == nil || !strings.Contains(err.Error(), "status unknown") {
+		t.Errorf("recoverFromWAL() error = %v, want pending status unknown", err)
+	}
+	if !minter.walClient.Exists("fake-agent") {
+		t.Error("WAL should be kept while the transaction may still be mined")
+	}
+	if chain.mints != 0 {
+		t.Errorf("ExecuteMint called %d times, want 0", chain.mints)
+	}
+}
+
+func TestMinter_FailedMintLeavesRecoverableWAL(t *testing.T) {
+	var abandons int
+	backend := newFakeBackend(t, map[string]http.HandlerFunc{
+		"/api/sdk/agent/sync": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, SyncResponse{Status: "MINT_REQUIRED"})
+		},
+		"/api/sdk/agent/deploy": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, DeployResponse{
+				ContractAddress: testContractAddress,
+				ChainID:         "3338",
+				Signature:       "0x" + strings.Repeat("ab", 65),
+				ConfigHash:      "deploy-hash",
+			})
+		},
+		"/api/sdk/agent/abandon": func(w http.ResponseWriter, r *http.Request) {
+			abandons++
+			writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+		},
+	})
+	minter, chain := newFakeChainMinter(t, backend.URL)
+	chain.mintTx = "0x" + strings.Repeat("ab", 32)
+	chain.mintErr = errors.New("receipt wait timed out")
+
+	_, err := minter.syncAndMint(context.Background(), &AgentConfig{AgentID: "fake-agent"}, "config-hash", "")
+	if err == nil || !strings.Contains(err.Error(), "on-chain mint failed") {
+		t.Fatalf("syncAndMint() error = %v, want on-chain mint failed", err)
+	}
+	if chain.speedUpAfter != DefaultSpeedUpAfter {
+		t.Errorf("speedUpAfter = %v, want %v", chain.speedUpAfter, DefaultSpeedUpAfter)
+	}
+	if chain.receiptTimeout != DefaultReceiptTimeout || chain.receiptPollInterval != DefaultReceiptPollInterval {
+		t.Errorf("receipt wait = %v every %v, want the defaults", chain.receiptTimeout, chain.receiptPollInterval)
+	}
+
+	wal, err := minter.walClient.Load("fake-agent")
+	if err != nil || wal == nil {
+		t.Fatalf("Load() = %v, %v; want the pending mint", wal, err)
+	}
+	if wal.PendingTxHash != chain.mintTx || wal.PendingNonce == nil || wal.State != WALStateMinting {
+		t.Errorf("WAL = %+v, want the broadcast transaction recorded", wal)
+	}
+	if abandons != 0 {
+		t.Errorf("abandon called %d times after the mint was broadcast, want 0", abandons)
+	}
+}
+
+func TestDeployer_RecoversPendingStateFromChain(t *testing.T) {
+	backend := newUnconfirmedBackend(t, 0)
+	signer, err := NewPrivateKeySigner(newTestPrivateKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := newFakeChainOps(signer.Address())
+	tokenID := uint64(21)
+	chain.ownedToken = &tokenID
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	deployer, err := NewDeployer(&DeployConfig{
+		BackendURL:    backend.URL,
+		Signer:        signer,
+		AgentID:       "fake-agent",
+		AgentName:     "Fake Agent",
+		StateFilePath: statePath,
+		ChainFactory:  chain.factory(),
+	})
+	if err != nil {
+		t.Fatalf("NewDeployer() error = %v", err)
+	}
+	deployer.stateManager.Save(&DeployState{
+		AgentID:         "fake-agent",
+		WalletAddress:   chain.GetAddress(),
+		ContractAddress: testContractAddress,
+		ChainID:         "3338",
+		Status:          StatusPending,
+	})
+
+	result, err := deployer.Deploy(context.Background())
+	if err != nil {
+		t.Fatalf("Deploy() error = %v", err)
+	}
+	if result.TokenID != 21 {
+		t.Errorf("TokenID = %d, want 21", result.TokenID)
+	}
+	if confirms := backend.confirmed(); len(confirms) != 1 || confirms[0].TokenID != 21 {
+		t.Errorf("confirms = %+v, want token 21", confirms)
+	}
+	if chain.mints != 0 {
+		t.Errorf("ExecuteMint called %d times, want 0 for an already minted agent", chain.mints)
+	}
+}
@@ -0,0 +1,196 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultSpeedUpAfter is how long a mint transaction may stay pending before
+// it is resubmitted with a higher gas price
+const DefaultSpeedUpAfter = 90 * time.Second
+
+// speedUpFeeBumpPercent is how much a replacement raises the gas price. Nodes
+// reject replacements that bump the price by less than 10%.
+const speedUpFeeBumpPercent = 20
+
+// bumpGasPrice returns the gas price for a replacement of a transaction priced
+// at price: raised by speedUpFeeBumpPercent (rounded up), or the node's
+// suggested price if that is higher. A nil suggested price is ignored.
+func bumpGasPrice(price, suggested *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(price, big.NewInt(100+speedUpFeeBumpPercent))
+	bumped.Add(bumped, big.NewInt(99))
+	bumped.Div(bumped, big.NewInt(100))
+
+	if suggested != nil && suggested.Cmp(bumped) > 0 {
+		return new(big.Int).Set(suggested)
+	}
+	return bumped
+}
+
+// SpeedUpMint resubmits a pending mint transaction with the same nonce and
+// calldata at a higher gas price, then waits for either transaction to be
+// mined. If the original is already mined the replacement is a no-op and its
+// result is returned.
+func (c *ChainClient) SpeedUpMint(ctx context.Context, txHash string) (*MintResult, error) {
+	tx, pending, err := c.client.TransactionByHash(ctx, common.HexToHash(txHash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up transaction: %w", err)
+	}
+
+	if !pending {
+		log.Printf("✅ Transaction %s is already mined, nothing to speed up", txHash)
+		receipt, err := c.GetTransactionReceipt(ctx, txHash)
+		if err != nil {
+			return nil, err
+		}
+		return c.mintResultFromReceipt(receipt)
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(c.chainID), tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover transaction sender: %w", err)
+	}
+	if from != c.address {
+		return nil, fmt.Errorf("transaction %s was sent by %s, not this wallet", txHash, from.Hex())
+	}
+	if tx.To() == nil || *tx.To() != c.contractAddress {
+		return nil, fmt.Errorf("transaction %s is not a call to contract %s", txHash, c.contractAddress.Hex())
+	}
+
+	receiptCtx, cancel := context.WithTimeout(ctx, c.receiptTimeout)
+	defer cancel()
+
+	sent := []common.Hash{tx.Hash()}
+	replacement, receipt, err := c.speedUp(receiptCtx, tx, sent)
+	if err != nil {
+		return nil, err
+	}
+	if receipt == nil {
+		if replacement != nil {
+			sent = append(sent, replacement.Hash())
+		}
+		receipt, err = c.waitForReceipt(receiptCtx, sent...)
+		if err != nil {
+			return nil, receiptWaitError(ctx, err, sent[len(sent)-1])
+		}
+	}
+
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, fmt.Errorf("transaction reverted")
+	}
+	return c.mintResultFromReceipt(receipt)
+}
+
+// mintResultFromReceipt builds a MintResult from the receipt of a mint transaction
+func (c *ChainClient) mintResultFromReceipt(receipt *types.Receipt) (*MintResult, error) {
+	tokenID, err := c.ExtractTokenIDFromReceipt(receipt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract token ID: %w", err)
+	}
+	return &MintResult{
+		TokenID: tokenID,
+		TxHash:  receipt.TxHash.Hex(),
+	}, nil
+}
+
+// waitWithSpeedUp waits for tx, or a replacement of it, to be mined. Each time
+// speedUpAfter elapses without a receipt the latest transaction is resubmitted
+// at a bumped gas price. A zero or negative speedUpAfter only waits. The hash
+// of the mined transaction, which may be the original or any replacement, is
+// returned alongside; on failure it is the hash of the latest one sent.
+func (c *ChainClient) waitWithSpeedUp(ctx context.Context, tx *types.Transaction) (*types.Receipt, common.Hash, error) {
+	if c.speedUpAfter <= 0 {
+		receipt, err := c.waitForReceipt(ctx, tx.Hash())
+		return receipt, tx.Hash(), err
+	}
+
+	sent := []common.Hash{tx.Hash()}
+	latest := tx
+	for {
+		waitCtx, cancel := context.WithTimeout(ctx, c.speedUpAfter)
+		receipt, err := c.waitForReceipt(waitCtx, sent...)
+		cancel()
+		if err == nil {
+			return receipt, receipt.TxHash, nil
+		}
+		if ctx.Err() != nil {
+			return nil, latest.Hash(), ctx.Err()
+		}
+
+		log.Printf("🐢 Transaction %s still pending after %s, speeding up...", latest.Hash().Hex(), c.speedUpAfter)
+		replacement, receipt, err := c.speedUp(ctx, latest, sent)
+		if err != nil {
+			log.Printf("⚠️ Failed to speed up transaction: %v", err)
+			continue
+		}
+		if receipt != nil {
+			return receipt, receipt.TxHash, nil
+		}
+		if replacement != nil {
+			latest = replacement
+			sent = append(sent, replacement.Hash())
+		}
+	}
+}
+
+// speedUp broadcasts a replacement for tx with the same nonce, value, gas
+// limit and calldata and a bumped gas price. If one of the already sent
+// transactions was mined in the meantime no replacement is sent and its
+// receipt is returned instead. Both results are nil when the nonce turned out
+// to be used already; waiting on sent then yields the mined transaction.
+func (c *ChainClient) speedUp(ctx context.Context, tx *types.Transaction, sent []common.Hash) (*types.Transaction, *types.Receipt, error) {
+	for _, hash := range sent {
+		if receipt, err := c.client.TransactionReceipt(ctx, hash); err == nil {
+			log.Printf("✅ Transaction %s confirmed, no replacement needed", hash.Hex())
+			return nil, receipt, nil
+		}
+	}
+
+	suggested, err := c.client.SuggestGasPrice(ctx)
+	if err != nil {
+		log.Printf("⚠️ Failed to get gas price (%v), bumping the previous price", err)
+		suggested = nil
+	}
+	gasPrice := bumpGasPrice(tx.GasPrice(), suggested)
+
+	replacement := types.NewTransaction(
+		tx.Nonce(),
+		*tx.To(),
+		tx.Value(),
+		tx.Gas(),
+		gasPrice,
+		tx.Data(),
+	)
+
+	signedTx, err := c.signer.SignTx(replacement, c.chainID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to sign replacement transaction: %w", err)
+	}
+
+	if err := c.client.SendTransaction(ctx, signedTx); err != nil {
+		if isNonceTooLow(err) {
+			log.Printf("✅ Nonce %d already used, the earlier transaction was mined", tx.Nonce())
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to send replacement transaction: %w", err)
+	}
+
+	log.Printf("🚀 Replacement %s sent with gas price %s wei (nonce %d)", signedTx.Hash().Hex(), gasPrice, tx.Nonce())
+	if c.onTxSent != nil {
+		c.onTxSent(signedTx.Hash().Hex(), tx.Nonce())
+	}
+	return signedTx, nil, nil
+}
+
+// isNonceTooLow reports whether a node rejected a transaction because its
+// nonce was already used
+func isNonceTooLow(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "nonce too low")
+}
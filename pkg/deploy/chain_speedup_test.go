@@ -0,0 +1,331 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestBumpGasPrice(t *testing.T) {
+	gwei := big.NewInt(1e9)
+
+	tests := []struct {
+		name      string
+		price     *big.Int
+		suggested *big.Int
+		want      *big.Int
+	}{
+		{"bumps by 20%", gwei, nil, big.NewInt(1.2e9)},
+		{"rounds up", big.NewInt(1), nil, big.NewInt(2)},
+		{"suggested below bump", gwei, big.NewInt(1.1e9), big.NewInt(1.2e9)},
+		{"suggested above bump", gwei, big.NewInt(3e9), big.NewInt(3e9)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bumpGasPrice(tt.price, tt.suggested)
+			if got.Cmp(tt.want) != 0 {
+				t.Errorf("bumpGasPrice(%s, %v) = %s, want %s", tt.price, tt.suggested, got, tt.want)
+			}
+			// Nodes only accept replacements priced at least 10% higher
+			minimum := new(big.Int).Div(new(big.Int).Mul(tt.price, big.NewInt(110)), big.NewInt(100))
+			if got.Cmp(minimum) < 0 {
+				t.Errorf("bumpGasPrice(%s) = %s is below the 10%% replacement minimum", tt.price, got)
+			}
+		})
+	}
+
+	suggested := big.NewInt(5e9)
+	bumpGasPrice(gwei, suggested).SetInt64(1)
+	if suggested.Int64() != 5e9 {
+		t.Error("bumpGasPrice() should not return the suggested price itself")
+	}
+}
+
+// speedUpChain is a JSON-RPC node that holds a pending mint transaction and
+// records broadcasts. Only transactions in mined have receipts; mineSent
+// decides whether the n-th broadcast (0-based) is mined.
+type speedUpChain struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	pending  *types.Transaction
+	sentTxs  []*types.Transaction
+	mined    map[common.Hash]bool
+	mineSent func(n int) bool
+	sendErr  string      // when set, broadcasts fail and the pending tx is mined
+	polls    []time.Time // times of receipt lookups
+}
+
+func newSpeedUpChain(t *testing.T, pending *types.Transaction) *speedUpChain {
+	t.Helper()
+	chain := &speedUpChain{pending: pending, mined: map[common.Hash]bool{}, mineSent: func(int) bool { return true }}
+	chain.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		chain.mu.Lock()
+		defer chain.mu.Unlock()
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		switch req.Method {
+		case "eth_getTransactionByHash":
+			raw, _ := chain.pending.MarshalJSON()
+			var fields map[string]interface{}
+			json.Unmarshal(raw, &fields)
+			fields["blockNumber"] = nil
+			if chain.mined[chain.pending.Hash()] {
+				fields["blockNumber"] = "0x1"
+			}
+			resp["result"] = fields
+		case "eth_getTransactionReceipt":
+			chain.polls = append(chain.polls, time.Now())
+			var hash common.Hash
+			json.Unmarshal(req.Params[0], &hash)
+			if chain.mined[hash] {
+				resp["result"] = mintReceipt(hash, 11)
+			} else {
+				resp["result"] = nil
+			}
+		case "eth_getTransactionCount":
+			resp["result"] = hexutil.EncodeUint64(chain.pending.Nonce())
+		case "eth_gasPrice":
+			resp["result"] = "0x3b9aca00" // 1 gwei
+		case "eth_estimateGas":
+			resp["result"] = "0x30d40"
+		case "eth_sendRawTransaction":
+			if chain.sendErr != "" {
+				chain.mined[chain.pending.Hash()] = true
+				resp["error"] = map[string]interface{}{"code": -32000, "message": chain.sendErr}
+				break
+			}
+			var raw hexutil.Bytes
+			json.Unmarshal(req.Params[0], &raw)
+			tx := new(types.Transaction)
+			tx.UnmarshalBinary(raw)
+			if chain.mineSent(len(chain.sentTxs)) {
+				chain.mined[tx.Hash()] = true
+			}
+			chain.sentTxs = append(chain.sentTxs, tx)
+			resp["result"] = tx.Hash().Hex()
+		default:
+			t.Errorf("unexpected RPC method %s", req.Method)
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(chain.Close)
+	return chain
+}
+
+func (c *speedUpChain) sent() []*types.Transaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*types.Transaction(nil), c.sentTxs...)
+}
+
+func (c *speedUpChain) mine(hash common.Hash) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mined[hash] = true
+}
+
+// mintReceipt is a successful receipt for txHash with a Transfer event minting tokenID
+func mintReceipt(txHash common.Hash, tokenID int64) *types.Receipt {
+	return &types.Receipt{
+		Status:            types.ReceiptStatusSuccessful,
+		CumulativeGasUsed: 21000,
+		GasUsed:           21000,
+		TxHash:            txHash,
+		BlockNumber:       big.NewInt(1),
+		Logs: []*types.Log{{
+			Address: common.HexToAddress(testContractAddress),
+			Topics: []common.Hash{
+				crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")),
+				{},
+				{},
+				common.BigToHash(big.NewInt(tokenID)),
+			},
+			TxHash: txHash,
+		}},
+	}
+}
+
+// newPendingMint signs an underpriced mint transaction with nonce 7
+func newPendingMint(t *testing.T, signer *PrivateKeySigner) *types.Transaction {
+	t.Helper()
+	tx := types.NewTransaction(7, common.HexToAddress(testContractAddress), big.NewInt(2e18), 240000, big.NewInt(5e8), []byte{0xde, 0xad, 0xbe, 0xef})
+	signed, err := signer.SignTx(tx, big.NewInt(3338))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func newSpeedUpClient(t *testing.T, chain *speedUpChain, signer Signer) *ChainClient {
+	t.Helper()
+	client, err := NewChainClientWithSigner(chain.URL, testContractAddress, "3338", signer)
+	if err != nil {
+		t.Fatalf("NewChainClientWithSigner() error = %v", err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+func TestChainClient_SpeedUpMintReusesNonceAndCalldata(t *testing.T) {
+	signer, _ := NewPrivateKeySigner(newTestPrivateKey(t))
+	original := newPendingMint(t, signer)
+	chain := newSpeedUpChain(t, original)
+	client := newSpeedUpClient(t, chain, signer)
+
+	result, err := client.SpeedUpMint(context.Background(), original.Hash().Hex())
+	if err != nil {
+		t.Fatalf("SpeedUpMint() error = %v", err)
+	}
+
+	sent := chain.sent()
+	if len(sent) != 1 {
+		t.Fatalf("expected 1 replacement, got %d", len(sent))
+	}
+	replacement := sent[0]
+	if replacement.Nonce() != original.Nonce() {
+		t.Errorf("replacement nonce = %d, want %d", replacement.Nonce(), original.Nonce())
+	}
+	if !bytes.Equal(replacement.Data(), original.Data()) || *replacement.To() != *original.To() ||
+		replacement.Value().Cmp(original.Value()) != 0 || replacement.Gas() != original.Gas() {
+		t.Error("replacement must reuse the original calldata, target, value and gas limit")
+	}
+	// 0.5 gwei bumped by 20% is below the suggested 1 gwei
+	if replacement.GasPrice().Cmp(big.NewInt(1e9)) != 0 {
+		t.Errorf("replacement gas price = %s, want 1 gwei", replacement.GasPrice())
+	}
+	if result.TxHash != replacement.Hash().Hex() || result.TokenID != 11 {
+		t.Errorf("SpeedUpMint() = %+v, want replacement %s with token 11", result, replacement.Hash().Hex())
+	}
+}
+
+func TestChainClient_SpeedUpMintNoopWhenOriginalConfirmed(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(chain *speedUpChain, original *types.Transaction)
+	}{
+		{"already mined", func(chain *speedUpChain, original *types.Transaction) {
+			chain.mine(original.Hash())
+		}},
+		{"nonce used while replacing", func(chain *speedUpChain, original *types.Transaction) {
+			chain.sendErr = "nonce too low"
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, _ := NewPrivateKeySigner(newTestPrivateKey(t))
+			original := newPendingMint(t, signer)
+			chain := newSpeedUpChain(t, original)
+			tt.setup(chain, original)
+			client := newSpeedUpClient(t, chain, signer)
+
+			result, err := client.SpeedUpMint(context.Background(), original.Hash().Hex())
+			if err != nil {
+				t.Fatalf("SpeedUpMint() error = %v", err)
+			}
+			if result.TxHash != original.Hash().Hex() || result.TokenID != 11 {
+				t.Errorf("SpeedUpMint() = %+v, want the original transaction", result)
+			}
+			if len(chain.sent()) != 0 {
+				t.Error("no replacement should be accepted")
+			}
+		})
+	}
+}
+
+func TestChainClient_SpeedUpMintRejectsForeignTransaction(t *testing.T) {
+	owner, _ := NewPrivateKeySigner(newTestPrivateKey(t))
+	other, _ := NewPrivateKeySigner(newTestPrivateKey(t))
+	original := newPendingMint(t, owner)
+	chain := newSpeedUpChain(t, original)
+	client := newSpeedUpClient(t, chain, other)
+
+	if _, err := client.SpeedUpMint(context.Background(), original.Hash().Hex()); err == nil {
+		t.Error("SpeedUpMint() should refuse a transaction sent by another wallet")
+	}
+	if len(chain.sent()) != 0 {
+		t.Error("no replacement should be sent")
+	}
+}
+
+func TestChainClient_SendContractTxSpeedsUpAutomatically(t *testing.T) {
+	signer, _ := NewPrivateKeySigner(newTestPrivateKey(t))
+	chain := newSpeedUpChain(t, newPendingMint(t, signer))
+	chain.mineSent = func(n int) bool { return n > 0 } // only the replacement is mined
+	client := newSpeedUpClient(t, chain, signer)
+	client.SetSpeedUpAfter(10 * time.Millisecond)
+
+	type broadcast struct {
+		hash  string
+		nonce uint64
+	}
+	var broadcasts []broadcast
+	client.onTxSent = func(txHash string, nonce uint64) {
+		broadcasts = append(broadcasts, broadcast{txHash, nonce})
+	}
+
+	_, txHash, err := client.sendContractTx(context.Background(), "mint", big.NewInt(0), []byte{0x01})
+	if err != nil {
+		t.Fatalf("sendContractTx() error = %v", err)
+	}
+
+	sent := chain.sent()
+	if len(sent) != 2 {
+		t.Fatalf("expected original and replacement, got %d transactions", len(sent))
+	}
+	if sent[1].Nonce() != sent[0].Nonce() || !bytes.Equal(sent[1].Data(), sent[0].Data()) {
+		t.Error("replacement must reuse the original nonce and calldata")
+	}
+	if sent[1].GasPrice().Cmp(bumpGasPrice(sent[0].GasPrice(), nil)) != 0 {
+		t.Errorf("replacement gas price = %s, want %s", sent[1].GasPrice(), bumpGasPrice(sent[0].GasPrice(), nil))
+	}
+	if txHash != sent[1].Hash().Hex() {
+		t.Errorf("sendContractTx() hash = %s, want the mined replacement %s", txHash, sent[1].Hash().Hex())
+	}
+	if len(broadcasts) != 2 || broadcasts[1].hash != txHash || broadcasts[1].nonce != sent[0].Nonce() {
+		t.Errorf("onTxSent calls = %+v, want original and replacement", broadcasts)
+	}
+}
+
+func TestChainClient_WaitWithSpeedUpReturnsMinedHash(t *testing.T) {
+	signer, _ := NewPrivateKeySigner(newTestPrivateKey(t))
+	original := newPendingMint(t, signer)
+	chain := newSpeedUpChain(t, original)
+	chain.mineSent = func(int) bool { return false }
+	client := newSpeedUpClient(t, chain, signer)
+	client.SetSpeedUpAfter(10 * time.Millisecond)
+
+	// The original, lower priced transaction is mined once a replacement is out
+	client.onTxSent = func(string, uint64) { chain.mine(original.Hash()) }
+
+	receipt, txHash, err := client.waitWithSpeedUp(context.Background(), original)
+	if err != nil {
+		t.Fatalf("waitWithSpeedUp() error = %v", err)
+	}
+	if len(chain.sent()) == 0 {
+		t.Fatal("expected a replacement to be sent")
+	}
+	if txHash != original.Hash() || receipt.TxHash != original.Hash() {
+		t.Errorf("waitWithSpeedUp() hash = %s, want the mined original %s", txHash.Hex(), original.Hash().Hex())
+	}
+}
@@ -0,0 +1,155 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// newMintPriceChain starts a JSON-RPC node whose contract returns price from
+// mintPrice(), or reverts when price is nil. It counts eth_call requests.
+func newMintPriceChain(t *testing.T, price *big.Int) (*ChainClient, *int) {
+	t.Helper()
+	calls := new(int)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Method != "eth_call" {
+			t.Errorf("unexpected RPC method %s", req.Method)
+		}
+		*calls++
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if price == nil {
+			resp["error"] = map[string]interface{}{"code": 3, "message": "execution reverted"}
+		} else {
+			resp["result"] = hexutil.Encode(common.BigToHash(price).Bytes())
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := NewChainClient(srv.URL, testContractAddress, "3338", newTestPrivateKey(t))
+	if err != nil {
+		t.Fatalf("NewChainClient() error = %v", err)
+	}
+	return client, calls
+}
+
+func TestChainClient_ResolveMintPrice(t *testing.T) {
+	contractPrice := big.NewInt(5e17)
+	override := big.NewInt(42)
+
+	tests := []struct {
+		name       string
+		onChain    *big.Int
+		override   *big.Int
+		wantPrice  *big.Int
+		wantSource MintPriceSource
+		wantCalls  int
+	}{
+		{"override takes precedence", contractPrice, override, override, MintPriceSourceOverride, 0},
+		{"contract price", contractPrice, nil, contractPrice, MintPriceSourceContract, 1},
+		{"default when contract call fails", nil, nil, DefaultMintPrice(), MintPriceSourceDefault, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, calls := newMintPriceChain(t, tt.onChain)
+
+			price, source := client.ResolveMintPrice(context.Background(), tt.override)
+			if price.Cmp(tt.wantPrice) != 0 || source != tt.wantSource {
+				t.Errorf("ResolveMintPrice() = %s (%s), want %s (%s)", price, source, tt.wantPrice, tt.wantSource)
+			}
+			if *calls != tt.wantCalls {
+				t.Errorf("expected %d mintPrice() calls, got %d", tt.wantCalls, *calls)
+			}
+		})
+	}
+}
+
+func TestDefaultMintPrice(t *testing.T) {
+	want, _ := new(big.Int).SetString("2000000000000000000", 10)
+	if DefaultMintPrice().Cmp(want) != 0 {
+		t.Errorf("DefaultMintPrice() = %s, want 2 PEAQ in wei", DefaultMintPrice())
+	}
+	// Callers must not be able to mutate the shared default
+	DefaultMintPrice().SetInt64(1)
+	if DefaultMintPrice().Cmp(want) != 0 {
+		t.Error("DefaultMintPrice() should return a fresh value")
+	}
+}
+
+func TestChainClient_ReceiptPollInterval(t *testing.T) {
+	signer, _ := NewPrivateKeySigner(newTestPrivateKey(t))
+	chain := newSpeedUpChain(t, newPendingMint(t, signer))
+	chain.mineSent = func(int) bool { return false }
+	client := newSpeedUpClient(t, chain, signer)
+	client.SetReceiptWait(300*time.Millisecond, 50*time.Millisecond)
+
+	client.sendContractTx(context.Background(), "mint", big.NewInt(0), []byte{0x01})
+
+	chain.mu.Lock()
+	polls := append([]time.Time(nil), chain.polls...)
+	chain.mu.Unlock()
+	if len(polls) < 3 || len(polls) > 6 {
+		t.Fatalf("polled %d times in 300ms, want about 6 at a 50ms interval", len(polls))
+	}
+	for i := 1; i < len(polls); i++ {
+		if gap := polls[i].Sub(polls[i-1]); gap < 40*time.Millisecond {
+			t.Errorf("poll %d came %s after the previous one, want at least 50ms", i, gap)
+		}
+	}
+}
+
+func TestChainClient_ReceiptTimeout(t *testing.T) {
+	signer, _ := NewPrivateKeySigner(newTestPrivateKey(t))
+	chain := newSpeedUpChain(t, newPendingMint(t, signer))
+	chain.mineSent = func(int) bool { return false }
+	client := newSpeedUpClient(t, chain, signer)
+	client.SetReceiptWait(50*time.Millisecond, 10*time.Millisecond)
+
+	start := time.Now()
+	_, _, err := client.sendContractTx(context.Background(), "mint", big.NewInt(0), []byte{0x01})
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("sendContractTx() returned after %s, want about 50ms", elapsed)
+	}
+
+	var timeoutErr *ReceiptTimeoutError
+	if !errors.As(err, &timeoutErr) || !errors.Is(err, ErrReceiptTimeout) {
+		t.Fatalf("sendContractTx() error = %v, want a ReceiptTimeoutError", err)
+	}
+	sent := chain.sent()
+	if len(sent) != 1 || timeoutErr.TxHash != sent[0].Hash().Hex() {
+		t.Errorf("TxHash = %s, want the broadcast transaction", timeoutErr.TxHash)
+	}
+	if want := "receipt timeout, tx may still confirm: " + timeoutErr.TxHash; err.Error() != want {
+		t.Errorf("error = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestChainClient_ReceiptWaitRespectsContextDeadline(t *testing.T) {
+	signer, _ := NewPrivateKeySigner(newTestPrivateKey(t))
+	chain := newSpeedUpChain(t, newPendingMint(t, signer))
+	chain.mineSent = func(int) bool { return false }
+	client := newSpeedUpClient(t, chain, signer)
+	client.SetReceiptWait(time.Hour, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, _, err := client.sendContractTx(ctx, "mint", big.NewInt(0), []byte{0x01})
+	if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrReceiptTimeout) {
+		t.Errorf("sendContractTx() error = %v, want the context deadline", err)
+	}
+}
@@ -0,0 +1,171 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// agentNFTABI covers the ERC721 ownership calls used for transfers and burns
+const agentNFTABI = `[
+	{"inputs":[{"name":"tokenId","type":"uint256"}],"name":"ownerOf","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"tokenId","type":"uint256"}],"name":"safeTransferFrom","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[{"name":"tokenId","type":"uint256"}],"name":"burn","outputs":[],"stateMutability":"nonpayable","type":"function"}
+]`
+
+var (
+	// ErrNotTokenOwner is returned when the wallet does not own the agent NFT it
+	// tries to transfer or burn
+	ErrNotTokenOwner = errors.New("wallet does not own this agent NFT")
+
+	// ErrBurnNotSupported is returned when the contract has no burn(uint256) function
+	ErrBurnNotSupported = errors.New("contract does not support burning")
+)
+
+// parseAgentNFTABI parses agentNFTABI
+func parseAgentNFTABI() (*abi.ABI, error) {
+	parsed, err := abi.JSON(strings.NewReader(agentNFTABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse agent NFT ABI: %w", err)
+	}
+	return &parsed, nil
+}
+
+// OwnerOf returns the owner of an agent NFT
+func (c *ChainClient) OwnerOf(ctx context.Context, tokenID uint64) (common.Address, error) {
+	nftABI, err := parseAgentNFTABI()
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	data, err := nftABI.Pack("ownerOf", new(big.Int).SetUint64(tokenID))
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to pack ownerOf call: %w", err)
+	}
+
+	result, err := c.client.CallContract(ctx, ethereum.CallMsg{
+		To:   &c.contractAddress,
+		Data: data,
+	}, nil)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to call ownerOf: %w", err)
+	}
+
+	var owner common.Address
+	if err := nftABI.UnpackIntoInterface(&owner, "ownerOf", result); err != nil {
+		return common.Address{}, fmt.Errorf("failed to unpack ownerOf result: %w", err)
+	}
+
+	return owner, nil
+}
+
+// TransferAgent transfers an agent NFT owned by the wallet to another address
+// with safeTransferFrom and returns the transaction hash
+func (c *ChainClient) TransferAgent(ctx context.Context, to string, tokenID uint64) (string, error) {
+	if !common.IsHexAddress(to) {
+		return "", fmt.Errorf("invalid recipient address: %q", to)
+	}
+	recipient := common.HexToAddress(to)
+	if recipient == (common.Address{}) {
+		return "", fmt.Errorf("cannot transfer to the zero address; use BurnAgent to destroy an agent")
+	}
+
+	if err := requireOwnership(ctx, c, tokenID); err != nil {
+		return "", err
+	}
+
+	nftABI, err := parseAgentNFTABI()
+	if err != nil {
+		return "", err
+	}
+	data, err := nftABI.Pack("safeTransferFrom", c.address, recipient, new(big.Int).SetUint64(tokenID))
+	if err != nil {
+		return "", fmt.Errorf("failed to pack safeTransferFrom call: %w", err)
+	}
+
+	log.Printf("📦 Transferring agent NFT #%d to %s...", tokenID, recipient.Hex())
+	_, txHash, err := c.sendContractTx(ctx, "transfer", big.NewInt(0), data)
+	if err != nil {
+		return "", err
+	}
+	return txHash, nil
+}
+
+// SupportsBurn reports whether the contract bytecode exposes burn(uint256)
+func (c *ChainClient) SupportsBurn(ctx context.Context) (bool, error) {
+	nftABI, err := parseAgentNFTABI()
+	if err != nil {
+		return false, err
+	}
+
+	code, err := c.client.CodeAt(ctx, c.contractAddress, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to read contract code: %w", err)
+	}
+
+	// Solidity dispatchers compare the calldata selector against PUSH4 <selector>
+	push4 := append([]byte{0x63}, nftABI.Methods["burn"].ID...)
+	return bytes.Contains(code, push4), nil
+}
+
+// BurnAgent burns an agent NFT owned by the wallet and returns the transaction
+// hash. It fails with ErrBurnNotSupported if the contract cannot burn.
+func (c *ChainClient) BurnAgent(ctx context.Context, tokenID uint64) (string, error) {
+	if err := requireOwnership(ctx, c, tokenID); err != nil {
+		return "", err
+	}
+
+	supported, err := c.SupportsBurn(ctx)
+	if err != nil {
+		return "", err
+	}
+	if !supported {
+		return "", ErrBurnNotSupported
+	}
+
+	nftABI, err := parseAgentNFTABI()
+	if err != nil {
+		return "", err
+	}
+	data, err := nftABI.Pack("burn", new(big.Int).SetUint64(tokenID))
+	if err != nil {
+		return "", fmt.Errorf("failed to pack burn call: %w", err)
+	}
+
+	log.Printf("🔥 Burning agent NFT #%d...", tokenID)
+	_, txHash, err := c.sendContractTx(ctx, "burn", big.NewInt(0), data)
+	if err != nil {
+		return "", err
+	}
+	return txHash, nil
+}
+
+// TransferAgent transfers one of this wallet's agent NFTs to another address
+func (m *Minter) TransferAgent(ctx context.Context, to string, tokenID uint64) (string, error) {
+	chainClient, _, err := m.contractChainClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer chainClient.Close()
+
+	return chainClient.TransferAgent(ctx, to, tokenID)
+}
+
+// BurnAgent burns one of this wallet's agent NFTs
+func (m *Minter) BurnAgent(ctx context.Context, tokenID uint64) (string, error) {
+	chainClient, _, err := m.contractChainClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer chainClient.Close()
+
+	return chainClient.BurnAgent(ctx, tokenID)
+}
@@ -0,0 +1,224 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ownershipChain is a JSON-RPC node whose contract reports owner for every
+// token and has code as its bytecode. It records broadcast transactions.
+type ownershipChain struct {
+	*httptest.Server
+
+	mu  sync.Mutex
+	txs []*types.Transaction
+}
+
+func newOwnershipChain(t *testing.T, owner common.Address, code []byte) *ownershipChain {
+	t.Helper()
+	chain := &ownershipChain{}
+	chain.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		var result interface{}
+		switch req.Method {
+		case "eth_call": // ownerOf
+			result = hexutil.Encode(common.LeftPadBytes(owner.Bytes(), 32))
+		case "eth_getCode":
+			result = hexutil.Encode(code)
+		case "eth_getTransactionCount":
+			result = "0x0"
+		case "eth_gasPrice":
+			result = "0x3b9aca00"
+		case "eth_estimateGas":
+			result = "0x30d40"
+		case "eth_sendRawTransaction":
+			var raw hexutil.Bytes
+			json.Unmarshal(req.Params[0], &raw)
+			tx := new(types.Transaction)
+			tx.UnmarshalBinary(raw)
+			chain.mu.Lock()
+			chain.txs = append(chain.txs, tx)
+			chain.mu.Unlock()
+			result = tx.Hash().Hex()
+		case "eth_getTransactionReceipt":
+			chain.mu.Lock()
+			txHash := chain.txs[len(chain.txs)-1].Hash()
+			chain.mu.Unlock()
+			result = &types.Receipt{
+				Status:            types.ReceiptStatusSuccessful,
+				CumulativeGasUsed: 21000,
+				GasUsed:           21000,
+				TxHash:            txHash,
+				BlockNumber:       big.NewInt(1),
+				Logs:              []*types.Log{},
+			}
+		default:
+			t.Errorf("unexpected RPC method %s", req.Method)
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	t.Cleanup(chain.Close)
+	return chain
+}
+
+func (c *ownershipChain) sent() []*types.Transaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*types.Transaction(nil), c.txs...)
+}
+
+// burnableCode is contract bytecode whose dispatcher includes burn(uint256)
+var burnableCode = append([]byte{0x60, 0x80, 0x63}, crypto.Keccak256([]byte("burn(uint256)"))[:4]...)
+
+func newOwnershipClient(t *testing.T, chain *ownershipChain) (*ChainClient, *PrivateKeySigner) {
+	t.Helper()
+	signer, err := NewPrivateKeySigner(newTestPrivateKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewChainClientWithSigner(chain.URL, testContractAddress, "3338", signer)
+	if err != nil {
+		t.Fatalf("NewChainClientWithSigner() error = %v", err)
+	}
+	t.Cleanup(client.Close)
+	return client, signer
+}
+
+// selectorCalldata builds calldata by hand: a 4-byte selector followed by 32-byte words
+func selectorCalldata(signature string, words ...[]byte) []byte {
+	data := crypto.Keccak256([]byte(signature))[:4]
+	for _, word := range words {
+		data = append(data, common.LeftPadBytes(word, 32)...)
+	}
+	return data
+}
+
+func TestMinter_TransferAgent(t *testing.T) {
+	signer, err := NewPrivateKeySigner(newTestPrivateKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := newOwnershipChain(t, signer.Address(), nil)
+	backend := newFakeBackend(t, map[string]http.HandlerFunc{
+		"/api/contract/config": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, ContractConfigResponse{ContractAddress: testContractAddress, ChainID: "3338"})
+		},
+	})
+	minter, err := NewMinter(&MintConfig{BackendURL: backend.URL, RPCEndpoint: chain.URL, Signer: signer})
+	if err != nil {
+		t.Fatalf("NewMinter() error = %v", err)
+	}
+
+	recipient := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	txHash, err := minter.TransferAgent(context.Background(), recipient.Hex(), 7)
+	if err != nil {
+		t.Fatalf("TransferAgent() error = %v", err)
+	}
+
+	sent := chain.sent()
+	if len(sent) != 1 {
+		t.Fatalf("expected 1 transaction, got %d", len(sent))
+	}
+	tx := sent[0]
+	if tx.Hash().Hex() != txHash {
+		t.Errorf("returned hash %s, sent %s", txHash, tx.Hash().Hex())
+	}
+	if *tx.To() != common.HexToAddress(testContractAddress) || tx.Value().Sign() != 0 {
+		t.Errorf("unexpected tx target/value: %s, %s", tx.To().Hex(), tx.Value())
+	}
+
+	want := selectorCalldata("safeTransferFrom(address,address,uint256)",
+		signer.Address().Bytes(), recipient.Bytes(), big.NewInt(7).Bytes())
+	if !bytes.Equal(tx.Data(), want) {
+		t.Errorf("calldata = %x, want %x", tx.Data(), want)
+	}
+}
+
+func TestChainClient_TransferAgentRejectsBadRecipients(t *testing.T) {
+	chain := newOwnershipChain(t, common.Address{}, nil)
+	client, _ := newOwnershipClient(t, chain)
+
+	for _, to := range []string{"0x0000000000000000000000000000000000000000", "not-an-address", ""} {
+		if _, err := client.TransferAgent(context.Background(), to, 1); err == nil {
+			t.Errorf("TransferAgent(%q) should fail", to)
+		}
+	}
+	if len(chain.sent()) != 0 {
+		t.Error("no transaction should be sent")
+	}
+}
+
+func TestChainClient_OwnershipPreChecks(t *testing.T) {
+	other := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	chain := newOwnershipChain(t, other, burnableCode)
+	client, _ := newOwnershipClient(t, chain)
+
+	if _, err := client.TransferAgent(context.Background(), "0x00000000000000000000000000000000000000aa", 3); !errors.Is(err, ErrNotTokenOwner) {
+		t.Errorf("TransferAgent() error = %v, want ErrNotTokenOwner", err)
+	}
+	if _, err := client.BurnAgent(context.Background(), 3); !errors.Is(err, ErrNotTokenOwner) {
+		t.Errorf("BurnAgent() error = %v, want ErrNotTokenOwner", err)
+	}
+	if len(chain.sent()) != 0 {
+		t.Error("no transaction should be sent for a token the wallet does not own")
+	}
+}
+
+func TestChainClient_BurnAgent(t *testing.T) {
+	t.Run("unsupported", func(t *testing.T) {
+		signer, _ := NewPrivateKeySigner(newTestPrivateKey(t))
+		chain := newOwnershipChain(t, signer.Address(), []byte{0x60, 0x80})
+		client, err := NewChainClientWithSigner(chain.URL, testContractAddress, "3338", signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+
+		if _, err := client.BurnAgent(context.Background(), 5); !errors.Is(err, ErrBurnNotSupported) {
+			t.Errorf("BurnAgent() error = %v, want ErrBurnNotSupported", err)
+		}
+		if len(chain.sent()) != 0 {
+			t.Error("no transaction should be sent")
+		}
+	})
+
+	t.Run("burns", func(t *testing.T) {
+		signer, _ := NewPrivateKeySigner(newTestPrivateKey(t))
+		chain := newOwnershipChain(t, signer.Address(), burnableCode)
+		client, err := NewChainClientWithSigner(chain.URL, testContractAddress, "3338", signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+
+		if _, err := client.BurnAgent(context.Background(), 5); err != nil {
+			t.Fatalf("BurnAgent() error = %v", err)
+		}
+		sent := chain.sent()
+		if len(sent) != 1 {
+			t.Fatalf("expected 1 transaction, got %d", len(sent))
+		}
+		if want := selectorCalldata("burn(uint256)", big.NewInt(5).Bytes()); !bytes.Equal(sent[0].Data(), want) {
+			t.Errorf("calldata = %x, want %x", sent[0].Data(), want)
+		}
+	})
+}
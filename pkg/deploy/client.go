@@ -2,21 +2,38 @@ package deploy
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/version"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // HTTPClient wraps HTTP operations for SDK deploy endpoints
 type HTTPClient struct {
 	baseURL    string
 	httpClient *http.Client
+	tracer     trace.Tracer
+
+	contractMu        sync.Mutex
+	contractConfig    *ContractConfigResponse // cached after a successful fetch
+	contractFetchedAt time.Time
 }
 
+// ContractConfigCacheTTL is how long a fetched contract config is reused
+// before GetContractConfig asks the backend again
+const ContractConfigCacheTTL = 10 * time.Minute
+
 // ChallengeRequest is the request body for /api/sdk/auth/challenge
 type ChallengeRequest struct {
 	WalletAddress string `json:"wallet_address"`
@@ -33,6 +50,7 @@ type VerifyRequest struct {
 	WalletAddress string `json:"wallet_address"`
 	Challenge     string `json:"challenge"`
 	Signature     string `json:"signature"`
+	SignatureType string `json:"signature_type,omitempty"` // "eip712", or empty for personal_sign
 }
 
 // VerifyResponse is the response from /api/sdk/auth/verify
@@ -69,6 +87,10 @@ type DeployResponse struct {
 	ConfigHash      string `json:"config_hash"`
 }
 
+// IdempotencyKeyHeader carries the idempotency key of a confirm-mint request so
+// the backend can drop duplicates of a confirm it already processed
+const IdempotencyKeyHeader = "Idempotency-Key"
+
 // ConfirmMintRequest is the request body for /api/sdk/agent/confirm-mint.
 type ConfirmMintRequest struct {
 	AgentID         string          `json:"agent_id"`
@@ -85,6 +107,15 @@ type ConfirmMintRequest struct {
 	NlpFallback     bool            `json:"nlp_fallback"`
 	Categories      json.RawMessage `json:"categories,omitempty"`
 	MetadataVersion string          `json:"metadata_version,omitempty"`
+	IdempotencyKey  string          `json:"idempotency_key,omitempty"` // default: ConfirmMintIdempotencyKey(AgentID, TxHash)
+}
+
+// ConfirmMintIdempotencyKey derives the idempotency key of the confirm for
+// agentID's mint in txHash. It only depends on its arguments, so every retry
+// of one logical confirm, in this process or after a restart, sends the same key.
+func ConfirmMintIdempotencyKey(agentID, txHash string) string {
+	sum := sha256.Sum256([]byte("confirm-mint:" + agentID + ":" + strings.ToLower(txHash)))
+	return hex.EncodeToString(sum[:])
 }
 
 // ConfirmMintResponse is the response from /api/sdk/agent/confirm-mint
@@ -126,29 +157,70 @@ type ErrorResponse struct {
 	Error   string `json:"error"`
 }
 
-// NewHTTPClient creates a new HTTP client for SDK endpoints
-func NewHTTPClient(baseURL string) *HTTPClient {
-	return &HTTPClient{
+// HTTPClientOption configures an HTTPClient
+type HTTPClientOption func(*HTTPClient)
+
+// WithHTTPClient makes the client send all requests through httpClient, e.g.
+// to use a proxy, custom TLS, a tracing transport or a shared connection pool.
+// A nil client keeps the default.
+func WithHTTPClient(httpClient *http.Client) HTTPClientOption {
+	return func(c *HTTPClient) {
+		if httpClient != nil {
+			c.httpClient = httpClient
+		}
+	}
+}
+
+// WithTracerProvider creates a client span for every backend request and
+// propagates the trace context to the backend. A nil provider keeps the
+// default no-op tracer.
+func WithTracerProvider(tp trace.TracerProvider) HTTPClientOption {
+	return func(c *HTTPClient) {
+		c.tracer = newTracer(tp)
+	}
+}
+
+// NewHTTPClient creates a new HTTP client for SDK endpoints.
+// By default requests use a dedicated http.Client with a 60s timeout.
+func NewHTTPClient(baseURL string, opts ...HTTPClientOption) *HTTPClient {
+	c := &HTTPClient{
 		baseURL: baseURL,
 		httpClient: &http.Client{
 			Timeout: 60 * time.Second,
 		},
+		tracer: newTracer(nil),
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
 }
 
 // RequestChallenge requests an authentication challenge from the backend
 func (c *HTTPClient) RequestChallenge(walletAddress string) (*ChallengeResponse, error) {
+	return c.RequestChallengeCtx(context.Background(), walletAddress)
+}
+
+// RequestChallengeCtx is like RequestChallenge but aborts the request when ctx is done
+func (c *HTTPClient) RequestChallengeCtx(ctx context.Context, walletAddress string) (*ChallengeResponse, error) {
 	reqBody := ChallengeRequest{WalletAddress: walletAddress}
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal challenge request: %w", err)
 	}
 
-	resp, err := c.httpClient.Post(
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
 		c.baseURL+"/api/sdk/auth/challenge",
-		"application/json",
 		bytes.NewReader(bodyBytes),
 	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create challenge request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.doTraced(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to request challenge: %w", err)
 	}
@@ -158,6 +230,13 @@ func (c *HTTPClient) RequestChallenge(walletAddress string) (*ChallengeResponse,
 	if err != nil {
 		return nil, fmt.Errorf("failed to read challenge response: %w", err)
 	}
+	if err := checkHTMLResponse(resp, body); err != nil {
+		return nil, fmt.Errorf("challenge failed: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, tooManyRequestsError(body)
+	}
 
 	if resp.StatusCode != http.StatusOK {
 		var errResp ErrorResponse
@@ -177,21 +256,38 @@ func (c *HTTPClient) RequestChallenge(walletAddress string) (*ChallengeResponse,
 
 // VerifySignature verifies the signed challenge and returns a session token
 func (c *HTTPClient) VerifySignature(walletAddress, challenge, signature string) (*VerifyResponse, error) {
-	reqBody := VerifyRequest{
+	return c.VerifySignatureCtx(context.Background(), walletAddress, challenge, signature)
+}
+
+// VerifySignatureCtx is like VerifySignature but aborts the request when ctx is done
+func (c *HTTPClient) VerifySignatureCtx(ctx context.Context, walletAddress, challenge, signature string) (*VerifyResponse, error) {
+	return c.VerifyChallengeCtx(ctx, &VerifyRequest{
 		WalletAddress: walletAddress,
 		Challenge:     challenge,
 		Signature:     signature,
-	}
+	})
+}
+
+// VerifyChallengeCtx is like VerifySignatureCtx but sends a full VerifyRequest,
+// e.g. one carrying an EIP-712 signature type
+func (c *HTTPClient) VerifyChallengeCtx(ctx context.Context, reqBody *VerifyRequest) (*VerifyResponse, error) {
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal verify request: %w", err)
 	}
 
-	resp, err := c.httpClient.Post(
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
 		c.baseURL+"/api/sdk/auth/verify",
-		"application/json",
 		bytes.NewReader(bodyBytes),
 	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create verify request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.doTraced(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to verify signature: %w", err)
 	}
@@ -201,6 +297,9 @@ func (c *HTTPClient) VerifySignature(walletAddress, challenge, signature string)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read verify response: %w", err)
 	}
+	if err := checkHTMLResponse(resp, body); err != nil {
+		return nil, fmt.Errorf("verify failed: %w", err)
+	}
 
 	if resp.StatusCode == http.StatusUnauthorized {
 		var errResp ErrorResponse
@@ -210,6 +309,10 @@ func (c *HTTPClient) VerifySignature(walletAddress, challenge, signature string)
 		return nil, fmt.Errorf("authentication failed")
 	}
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, tooManyRequestsError(body)
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		var errResp ErrorResponse
 		if json.Unmarshal(body, &errResp) == nil && errResp.Error != "" {
@@ -228,12 +331,18 @@ func (c *HTTPClient) VerifySignature(walletAddress, challenge, signature string)
 
 // Deploy calls the deploy endpoint to prepare for minting
 func (c *HTTPClient) Deploy(sessionToken string, req *DeployRequest) (*DeployResponse, error) {
+	return c.DeployCtx(context.Background(), sessionToken, req)
+}
+
+// DeployCtx is like Deploy but aborts the request when ctx is done
+func (c *HTTPClient) DeployCtx(ctx context.Context, sessionToken string, req *DeployRequest) (*DeployResponse, error) {
 	bodyBytes, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal deploy request: %w", err)
 	}
 
-	httpReq, err := http.NewRequest(
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodPost,
 		c.baseURL+"/api/sdk/agent/deploy",
 		bytes.NewReader(bodyBytes),
@@ -246,7 +355,7 @@ func (c *HTTPClient) Deploy(sessionToken string, req *DeployRequest) (*DeployRes
 	httpReq.Header.Set("X-SDK-Session-Token", sessionToken)
 	httpReq.Header.Set("X-SDK-Version", version.Version())
 
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.doTraced(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call deploy endpoint: %w", err)
 	}
@@ -256,17 +365,25 @@ func (c *HTTPClient) Deploy(sessionToken string, req *DeployRequest) (*DeployRes
 	if err != nil {
 		return nil, fmt.Errorf("failed to read deploy response: %w", err)
 	}
-
+	// Session and lookup errors keep their sentinels even when a proxy
+	// answers with an HTML page
 	if resp.StatusCode == http.StatusUnauthorized {
 		return nil, ErrSessionExpired
 	}
+	if err := checkHTMLResponse(resp, body); err != nil {
+		return nil, fmt.Errorf("deploy failed: %w", err)
+	}
 
 	if resp.StatusCode == http.StatusConflict {
 		var errResp ErrorResponse
 		if json.Unmarshal(body, &errResp) == nil && errResp.Error != "" {
-			return nil, fmt.Errorf("conflict: %s", errResp.Error)
+			return nil, fmt.Errorf("%w: %s", ErrAgentExists, errResp.Error)
 		}
-		return nil, fmt.Errorf("agent already exists")
+		return nil, ErrAgentExists
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, tooManyRequestsError(body)
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -287,12 +404,22 @@ func (c *HTTPClient) Deploy(sessionToken string, req *DeployRequest) (*DeployRes
 
 // ConfirmMint confirms the mint and saves the agent to the database
 func (c *HTTPClient) ConfirmMint(sessionToken string, req *ConfirmMintRequest) (*ConfirmMintResponse, error) {
+	return c.ConfirmMintCtx(context.Background(), sessionToken, req)
+}
+
+// ConfirmMintCtx is like ConfirmMint but aborts the request when ctx is done
+func (c *HTTPClient) ConfirmMintCtx(ctx context.Context, sessionToken string, req *ConfirmMintRequest) (*ConfirmMintResponse, error) {
+	if req.IdempotencyKey == "" {
+		req.IdempotencyKey = ConfirmMintIdempotencyKey(req.AgentID, req.TxHash)
+	}
+
 	bodyBytes, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal confirm request: %w", err)
 	}
 
-	httpReq, err := http.NewRequest(
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodPost,
 		c.baseURL+"/api/sdk/agent/confirm-mint",
 		bytes.NewReader(bodyBytes),
@@ -304,8 +431,9 @@ func (c *HTTPClient) ConfirmMint(sessionToken string, req *ConfirmMintRequest) (
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("X-SDK-Session-Token", sessionToken)
 	httpReq.Header.Set("X-SDK-Version", version.Version())
+	httpReq.Header.Set(IdempotencyKeyHeader, req.IdempotencyKey)
 
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.doTraced(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call confirm-mint endpoint: %w", err)
 	}
@@ -315,10 +443,30 @@ func (c *HTTPClient) ConfirmMint(sessionToken string, req *ConfirmMintRequest) (
 	if err != nil {
 		return nil, fmt.Errorf("failed to read confirm response: %w", err)
 	}
-
+	// Session and lookup errors keep their sentinels even when a proxy
+	// answers with an HTML page
 	if resp.StatusCode == http.StatusUnauthorized {
 		return nil, ErrSessionExpired
 	}
+	if err := checkHTMLResponse(resp, body); err != nil {
+		return nil, fmt.Errorf("confirm-mint failed: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, tooManyRequestsError(body)
+	}
+
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		return nil, fmt.Errorf("confirm-mint %w, please try again later", ErrServiceUnavailable)
+	}
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		var errResp ErrorResponse
+		if json.Unmarshal(body, &errResp) == nil && errResp.Error != "" {
+			return nil, fmt.Errorf("confirm-mint %w: %s", ErrServerError, errResp.Error)
+		}
+		return nil, fmt.Errorf("confirm-mint %w (status %d): %s", ErrServerError, resp.StatusCode, string(body))
+	}
 
 	if resp.StatusCode != http.StatusOK {
 		var errResp ErrorResponse
@@ -338,12 +486,18 @@ func (c *HTTPClient) ConfirmMint(sessionToken string, req *ConfirmMintRequest) (
 
 // UpdateMetadata calls the update endpoint to re-upload metadata and update on-chain tokenURI
 func (c *HTTPClient) UpdateMetadata(sessionToken string, req *UpdateMetadataRequest) (*UpdateMetadataResponse, error) {
+	return c.UpdateMetadataCtx(context.Background(), sessionToken, req)
+}
+
+// UpdateMetadataCtx is like UpdateMetadata but aborts the request when ctx is done
+func (c *HTTPClient) UpdateMetadataCtx(ctx context.Context, sessionToken string, req *UpdateMetadataRequest) (*UpdateMetadataResponse, error) {
 	bodyBytes, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal update request: %w", err)
 	}
 
-	httpReq, err := http.NewRequest(
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodPost,
 		c.baseURL+"/api/sdk/agent/update",
 		bytes.NewReader(bodyBytes),
@@ -356,7 +510,7 @@ func (c *HTTPClient) UpdateMetadata(sessionToken string, req *UpdateMetadataRequ
 	httpReq.Header.Set("X-SDK-Session-Token", sessionToken)
 	httpReq.Header.Set("X-SDK-Version", version.Version())
 
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.doTraced(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call update endpoint: %w", err)
 	}
@@ -366,37 +520,40 @@ func (c *HTTPClient) UpdateMetadata(sessionToken string, req *UpdateMetadataRequ
 	if err != nil {
 		return nil, fmt.Errorf("failed to read update response: %w", err)
 	}
-
-	if resp.StatusCode == http.StatusBadRequest {
+	// Session and lookup errors keep their sentinels even when a proxy
+	// answers with an HTML page
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, ErrSessionExpired
+	}
+	if resp.StatusCode == http.StatusNotFound {
 		var errResp ErrorResponse
 		if json.Unmarshal(body, &errResp) == nil && errResp.Error != "" {
-			return nil, fmt.Errorf("update failed: %s", errResp.Error)
+			return nil, fmt.Errorf("%w: %s", ErrAgentNotFound, errResp.Error)
 		}
-		return nil, fmt.Errorf("update failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, ErrAgentNotFound
 	}
-
-	if resp.StatusCode == http.StatusUnauthorized {
-		return nil, ErrSessionExpired
+	if err := checkHTMLResponse(resp, body); err != nil {
+		return nil, fmt.Errorf("update failed: %w", err)
 	}
 
-	if resp.StatusCode == http.StatusForbidden {
+	if resp.StatusCode == http.StatusBadRequest {
 		var errResp ErrorResponse
 		if json.Unmarshal(body, &errResp) == nil && errResp.Error != "" {
-			return nil, fmt.Errorf("update forbidden: %s", errResp.Error)
+			return nil, fmt.Errorf("update failed: %s", errResp.Error)
 		}
-		return nil, fmt.Errorf("update forbidden")
+		return nil, fmt.Errorf("update failed with status %d: %s", resp.StatusCode, string(body))
 	}
 
-	if resp.StatusCode == http.StatusNotFound {
+	if resp.StatusCode == http.StatusForbidden {
 		var errResp ErrorResponse
 		if json.Unmarshal(body, &errResp) == nil && errResp.Error != "" {
-			return nil, fmt.Errorf("agent not found: %s", errResp.Error)
+			return nil, fmt.Errorf("update %w: %s", ErrForbidden, errResp.Error)
 		}
-		return nil, fmt.Errorf("agent not found")
+		return nil, fmt.Errorf("update %w", ErrForbidden)
 	}
 
 	if resp.StatusCode == http.StatusTooManyRequests {
-		return nil, fmt.Errorf("rate limit exceeded, please wait and retry")
+		return nil, tooManyRequestsError(body)
 	}
 
 	if resp.StatusCode == http.StatusInternalServerError {
@@ -408,7 +565,7 @@ func (c *HTTPClient) UpdateMetadata(sessionToken string, req *UpdateMetadataRequ
 	}
 
 	if resp.StatusCode == http.StatusServiceUnavailable {
-		return nil, fmt.Errorf("update service unavailable, please try again later")
+		return nil, fmt.Errorf("update %w, please try again later", ErrServiceUnavailable)
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -430,12 +587,90 @@ func (c *HTTPClient) UpdateMetadata(sessionToken string, req *UpdateMetadataRequ
 // ErrSessionExpired indicates the session token has expired
 var ErrSessionExpired = fmt.Errorf("session expired")
 
+// ErrAgentIDTaken indicates the agent ID is already reserved or registered,
+// either by another wallet or by a concurrent request
+var ErrAgentIDTaken = fmt.Errorf("agent ID already taken")
+
+// ErrAgentExists indicates the deploy endpoint rejected the agent ID as already existing.
+// It matches ErrAgentIDTaken, but the retryable conflict from Sync does not match it.
+var ErrAgentExists = fmt.Errorf("agent already exists: %w", ErrAgentIDTaken)
+
+// ErrRateLimited indicates the backend rejected the request with 429 Too Many Requests
+var ErrRateLimited = fmt.Errorf("rate limit exceeded")
+
+// ErrMaxReservations indicates the wallet has reached its limit of unminted reservations
+var ErrMaxReservations = fmt.Errorf("MAX_RESERVATIONS: maximum pending reservations reached")
+
+// ErrForbidden indicates the wallet is not allowed to perform the operation
+var ErrForbidden = fmt.Errorf("forbidden")
+
+// ErrAgentNotFound indicates the backend has no agent with the given ID
+var ErrAgentNotFound = fmt.Errorf("agent not found")
+
+// ErrServiceUnavailable indicates the backend is temporarily unavailable
+var ErrServiceUnavailable = fmt.Errorf("service unavailable")
+
+// ErrServerError indicates the backend failed with a 5xx status, e.g. a
+// transient database error
+var ErrServerError = fmt.Errorf("backend server error")
+
 // ErrHeadlessMintingDisabled indicates headless minting is disabled
 var ErrHeadlessMintingDisabled = fmt.Errorf("headless minting is temporarily disabled")
 
 // ErrSchemaOutdated indicates the schema version is outdated
 var ErrSchemaOutdated = fmt.Errorf("schema version outdated")
 
+// ErrHTMLResponse indicates the backend, or a proxy in front of it, answered
+// with an HTML page instead of JSON
+var ErrHTMLResponse = fmt.Errorf("backend returned an HTML error page")
+
+// HTMLResponseError is returned when a response body is an HTML page. It
+// matches ErrHTMLResponse and, for 429 and 5xx statuses, the sentinel the
+// status would otherwise map to, so retries still apply.
+type HTMLResponseError struct {
+	StatusCode int
+}
+
+func (e *HTMLResponseError) Error() string {
+	return fmt.Sprintf("%v (status %d)", ErrHTMLResponse, e.StatusCode)
+}
+
+func (e *HTMLResponseError) Unwrap() []error {
+	errs := []error{ErrHTMLResponse}
+	switch {
+	case e.StatusCode == http.StatusTooManyRequests:
+		errs = append(errs, ErrRateLimited)
+	case e.StatusCode == http.StatusServiceUnavailable:
+		errs = append(errs, ErrServiceUnavailable)
+	case e.StatusCode >= 500:
+		errs = append(errs, ErrServerError)
+	}
+	return errs
+}
+
+// checkHTMLResponse returns an HTMLResponseError when resp is an HTML page,
+// judged by its Content-Type or a body starting with '<', so callers never
+// try to unmarshal it as JSON
+func checkHTMLResponse(resp *http.Response, body []byte) error {
+	contentType := strings.ToLower(resp.Header.Get("Content-Type"))
+	if strings.HasPrefix(contentType, "text/html") || bytes.HasPrefix(bytes.TrimSpace(body), []byte("<")) {
+		return &HTMLResponseError{StatusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+// tooManyRequestsError maps a 429 response body to ErrMaxReservations or ErrRateLimited
+func tooManyRequestsError(body []byte) error {
+	var errResp map[string]interface{}
+	if json.Unmarshal(body, &errResp) == nil && errResp["error"] == "MAX_RESERVATIONS" {
+		if msg, ok := errResp["message"].(string); ok && msg != "" {
+			return fmt.Errorf("%w: %s", ErrMaxReservations, msg)
+		}
+		return ErrMaxReservations
+	}
+	return fmt.Errorf("%w, please wait and retry", ErrRateLimited)
+}
+
 // SchemaResponse is the response from GET /api/sdk/schema
 type SchemaResponse struct {
 	Schema        json.RawMessage `json:"schema"`
@@ -457,6 +692,7 @@ type SyncRequest struct {
 	ConfigHash    string `json:"config_hash"`
 	Challenge     string `json:"challenge"`
 	Signature     string `json:"signature"`
+	SignatureType string `json:"signature_type,omitempty"`
 	SchemaVersion string `json:"schema_version,omitempty"`
 }
 
@@ -477,7 +713,17 @@ type SyncResponse struct {
 
 // GetSchema fetches the validation schema from the backend
 func (c *HTTPClient) GetSchema() (*SchemaResponse, error) {
-	resp, err := c.httpClient.Get(c.baseURL + "/api/sdk/schema")
+	return c.GetSchemaCtx(context.Background())
+}
+
+// GetSchemaCtx is like GetSchema but aborts the request when ctx is done
+func (c *HTTPClient) GetSchemaCtx(ctx context.Context) (*SchemaResponse, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/sdk/schema", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema request: %w", err)
+	}
+
+	resp, err := c.doTraced(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get schema: %w", err)
 	}
@@ -487,6 +733,9 @@ func (c *HTTPClient) GetSchema() (*SchemaResponse, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read schema response: %w", err)
 	}
+	if err := checkHTMLResponse(resp, body); err != nil {
+		return nil, fmt.Errorf("get schema failed: %w", err)
+	}
 
 	if resp.StatusCode != http.StatusOK {
 		var errResp ErrorResponse
@@ -504,9 +753,86 @@ func (c *HTTPClient) GetSchema() (*SchemaResponse, error) {
 	return &result, nil
 }
 
+// ContractConfigResponse is the response from GET /api/contract/config
+type ContractConfigResponse struct {
+	ContractAddress string `json:"contract_address"`
+	ChainID         string `json:"chain_id"`
+	NetworkName     string `json:"network_name"`
+}
+
+// GetContractConfig fetches the NFT contract address and chain the backend
+// mints on. The config is cached for ContractConfigCacheTTL or until
+// InvalidateContractConfig is called.
+func (c *HTTPClient) GetContractConfig() (*ContractConfigResponse, error) {
+	return c.GetContractConfigCtx(context.Background())
+}
+
+// GetContractConfigCtx is like GetContractConfig but aborts the request when ctx is done
+func (c *HTTPClient) GetContractConfigCtx(ctx context.Context) (*ContractConfigResponse, error) {
+	c.contractMu.Lock()
+	defer c.contractMu.Unlock()
+	if c.contractConfig != nil && time.Since(c.contractFetchedAt) < ContractConfigCacheTTL {
+		cached := *c.contractConfig
+		return &cached, nil
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/contract/config", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create contract config request: %w", err)
+	}
+
+	resp, err := c.doTraced(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contract config: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read contract config response: %w", err)
+	}
+	if err := checkHTMLResponse(resp, body); err != nil {
+		return nil, fmt.Errorf("get contract config failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp ErrorResponse
+		if json.Unmarshal(body, &errResp) == nil && errResp.Error != "" {
+			return nil, fmt.Errorf("get contract config failed: %s", errResp.Error)
+		}
+		return nil, fmt.Errorf("get contract config failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result ContractConfigResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse contract config response: %w", err)
+	}
+	if result.ContractAddress == "" || result.ChainID == "" {
+		return nil, fmt.Errorf("contract config is missing contract_address or chain_id")
+	}
+
+	cached := result
+	c.contractConfig = &cached
+	c.contractFetchedAt = time.Now()
+	return &result, nil
+}
+
+// InvalidateContractConfig drops the cached contract config so the next
+// GetContractConfig fetches it from the backend again
+func (c *HTTPClient) InvalidateContractConfig() {
+	c.contractMu.Lock()
+	defer c.contractMu.Unlock()
+	c.contractConfig = nil
+}
+
 // GetChallenge requests a challenge for authentication (used by sync flow)
 func (c *HTTPClient) GetChallenge(walletAddress string) (string, error) {
-	resp, err := c.RequestChallenge(walletAddress)
+	return c.GetChallengeCtx(context.Background(), walletAddress)
+}
+
+// GetChallengeCtx is like GetChallenge but aborts the request when ctx is done
+func (c *HTTPClient) GetChallengeCtx(ctx context.Context, walletAddress string) (string, error) {
+	resp, err := c.RequestChallengeCtx(ctx, walletAddress)
 	if err != nil {
 		return "", err
 	}
@@ -515,12 +841,18 @@ func (c *HTTPClient) GetChallenge(walletAddress string) (string, error) {
 
 // Sync calls the sync endpoint to check agent status
 func (c *HTTPClient) Sync(req *SyncRequest) (*SyncResponse, error) {
+	return c.SyncCtx(context.Background(), req)
+}
+
+// SyncCtx is like Sync but aborts the request when ctx is done
+func (c *HTTPClient) SyncCtx(ctx context.Context, req *SyncRequest) (*SyncResponse, error) {
 	bodyBytes, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal sync request: %w", err)
 	}
 
-	httpReq, err := http.NewRequest(
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodPost,
 		c.baseURL+"/api/sdk/agent/sync",
 		bytes.NewReader(bodyBytes),
@@ -533,7 +865,7 @@ func (c *HTTPClient) Sync(req *SyncRequest) (*SyncResponse, error) {
 	httpReq.Header.Set("Cache-Control", "no-store")
 	httpReq.Header.Set("X-SDK-Version", version.Version())
 
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.doTraced(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call sync endpoint: %w", err)
 	}
@@ -543,6 +875,9 @@ func (c *HTTPClient) Sync(req *SyncRequest) (*SyncResponse, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read sync response: %w", err)
 	}
+	if err := checkHTMLResponse(resp, body); err != nil {
+		return nil, fmt.Errorf("sync failed: %w", err)
+	}
 
 	// Handle specific error codes
 	if resp.StatusCode == http.StatusServiceUnavailable {
@@ -552,7 +887,7 @@ func (c *HTTPClient) Sync(req *SyncRequest) (*SyncResponse, error) {
 				return nil, ErrHeadlessMintingDisabled
 			}
 		}
-		return nil, fmt.Errorf("service unavailable: %s", string(body))
+		return nil, fmt.Errorf("%w: %s", ErrServiceUnavailable, string(body))
 	}
 
 	if resp.StatusCode == http.StatusBadRequest {
@@ -578,24 +913,18 @@ func (c *HTTPClient) Sync(req *SyncRequest) (*SyncResponse, error) {
 		if json.Unmarshal(body, &errResp) == nil {
 			msg := errResp["message"]
 			if msg != nil {
-				return nil, fmt.Errorf("forbidden: %v", msg)
+				return nil, fmt.Errorf("%w: %v", ErrForbidden, msg)
 			}
 		}
-		return nil, fmt.Errorf("forbidden: %s", string(body))
+		return nil, fmt.Errorf("%w: %s", ErrForbidden, string(body))
 	}
 
 	if resp.StatusCode == http.StatusConflict {
-		return nil, fmt.Errorf("conflict: agent ID was just reserved by another request, retry")
+		return nil, fmt.Errorf("conflict: %w (just reserved by another request), retry", ErrAgentIDTaken)
 	}
 
 	if resp.StatusCode == http.StatusTooManyRequests {
-		var errResp map[string]interface{}
-		if json.Unmarshal(body, &errResp) == nil {
-			if errResp["error"] == "MAX_RESERVATIONS" {
-				return nil, fmt.Errorf("MAX_RESERVATIONS: %v", errResp["message"])
-			}
-		}
-		return nil, fmt.Errorf("rate limit exceeded, please wait and retry")
+		return nil, tooManyRequestsError(body)
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -616,10 +945,200 @@ func (c *HTTPClient) Sync(req *SyncRequest) (*SyncResponse, error) {
 
 // AbandonRequest is the request body for POST /api/sdk/agent/abandon
 type AbandonRequest struct {
-	Wallet    string `json:"wallet"`
+	Wallet        string `json:"wallet"`
+	AgentID       string `json:"agent_id"`
+	Challenge     string `json:"challenge"`
+	Signature     string `json:"signature"`
+	SignatureType string `json:"signature_type,omitempty"`
+}
+
+// AgentStatusResponse is the response from GET /api/sdk/agent/status
+type AgentStatusResponse struct {
+	AgentID         string    `json:"agent_id"`
+	Wallet          string    `json:"wallet"`
+	TokenID         *int64    `json:"token_id,omitempty"` // nil until the agent is minted
+	ContractAddress string    `json:"contract_address,omitempty"`
+	ConfigHash      string    `json:"config_hash,omitempty"`
+	IsPublic        bool      `json:"is_public"`
+	Status          string    `json:"status,omitempty"` // e.g. "reserved", "minted"
+	UpdatedAt       time.Time `json:"updated_at"`
+
+	// Config is the agent config the backend last hashed, for backends that
+	// report it
+	Config *AgentConfig `json:"config,omitempty"`
+}
+
+// GetAgentStatus reads the backend's current view of an agent without
+// changing it. Returns ErrAgentNotFound if the wallet has no such agent.
+func (c *HTTPClient) GetAgentStatus(wallet, agentID string) (*AgentStatusResponse, error) {
+	return c.GetAgentStatusCtx(context.Background(), wallet, agentID)
+}
+
+// GetAgentStatusCtx is like GetAgentStatus but aborts the request when ctx is done
+func (c *HTTPClient) GetAgentStatusCtx(ctx context.Context, wallet, agentID string) (*AgentStatusResponse, error) {
+	query := url.Values{}
+	query.Set("wallet", wallet)
+	query.Set("agent_id", agentID)
+
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		c.baseURL+"/api/sdk/agent/status?"+query.Encode(),
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create status request: %w", err)
+	}
+
+	httpReq.Header.Set("Cache-Control", "no-store")
+	httpReq.Header.Set("X-SDK-Version", version.Version())
+
+	resp, err := c.doTraced(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call status endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status response: %w", err)
+	}
+	if err := checkHTMLResponse(resp, body); err != nil {
+		return nil, fmt.Errorf("get agent status failed: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrAgentNotFound, agentID)
+	case http.StatusTooManyRequests:
+		return nil, tooManyRequestsError(body)
+	case http.StatusServiceUnavailable:
+		return nil, fmt.Errorf("%w: %s", ErrServiceUnavailable, string(body))
+	default:
+		var errResp ErrorResponse
+		if json.Unmarshal(body, &errResp) == nil && errResp.Error != "" {
+			return nil, fmt.Errorf("get agent status failed: %s", errResp.Error)
+		}
+		return nil, fmt.Errorf("get agent status failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result AgentStatusResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse status response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// ListAgents page size limits
+const (
+	DefaultListPageSize = 20
+	MaxListPageSize     = 100
+)
+
+// AgentSummary is one agent in a ListAgentsResponse
+type AgentSummary struct {
 	AgentID   string `json:"agent_id"`
-	Challenge string `json:"challenge"`
-	Signature string `json:"signature"`
+	AgentName string `json:"agent_name"`
+	TokenID   *int64 `json:"token_id,omitempty"` // nil for unminted reservations
+	Status    string `json:"status"`
+}
+
+// ListAgentsResponse is the response from GET /api/sdk/agent/list
+type ListAgentsResponse struct {
+	Agents   []AgentSummary `json:"agents"`
+	Page     int            `json:"page"` // 1-based
+	PageSize int            `json:"page_size"`
+	Total    int            `json:"total"`
+	HasMore  bool           `json:"has_more"`
+}
+
+// ListAgents returns one page of the agents owned by wallet. page is 1-based;
+// pageSize defaults to DefaultListPageSize and is capped at MaxListPageSize.
+func (c *HTTPClient) ListAgents(wallet string, page, pageSize int) (*ListAgentsResponse, error) {
+	return c.ListAgentsCtx(context.Background(), wallet, page, pageSize)
+}
+
+// ListAgentsCtx is like ListAgents but aborts the request when ctx is done
+func (c *HTTPClient) ListAgentsCtx(ctx context.Context, wallet string, page, pageSize int) (*ListAgentsResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = DefaultListPageSize
+	}
+	if pageSize > MaxListPageSize {
+		pageSize = MaxListPageSize
+	}
+
+	query := url.Values{}
+	query.Set("wallet", wallet)
+	query.Set("page", strconv.Itoa(page))
+	query.Set("page_size", strconv.Itoa(pageSize))
+
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		c.baseURL+"/api/sdk/agent/list?"+query.Encode(),
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create list request: %w", err)
+	}
+
+	httpReq.Header.Set("Cache-Control", "no-store")
+	httpReq.Header.Set("X-SDK-Version", version.Version())
+
+	resp, err := c.doTraced(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call list endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read list response: %w", err)
+	}
+	if err := checkHTMLResponse(resp, body); err != nil {
+		return nil, fmt.Errorf("list agents failed: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusTooManyRequests:
+		return nil, tooManyRequestsError(body)
+	case http.StatusServiceUnavailable:
+		return nil, fmt.Errorf("%w: %s", ErrServiceUnavailable, string(body))
+	default:
+		var errResp ErrorResponse
+		if json.Unmarshal(body, &errResp) == nil && errResp.Error != "" {
+			return nil, fmt.Errorf("list agents failed: %s", errResp.Error)
+		}
+		return nil, fmt.Errorf("list agents failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result ListAgentsResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse list response: %w", err)
+	}
+
+	// Normalize so callers can rely on a non-nil slice and echoed paging
+	if result.Agents == nil {
+		result.Agents = []AgentSummary{}
+	}
+	if result.Page == 0 {
+		result.Page = page
+	}
+	if result.PageSize == 0 {
+		result.PageSize = pageSize
+	}
+	// An empty page is always the last one, whatever the backend claims
+	if len(result.Agents) == 0 {
+		result.HasMore = false
+	}
+
+	return &result, nil
 }
 
 // AbandonResponse is the response from POST /api/sdk/agent/abandon
@@ -631,12 +1150,18 @@ type AbandonResponse struct {
 
 // Abandon calls the abandon endpoint to delete an unminted reservation
 func (c *HTTPClient) Abandon(req *AbandonRequest) (*AbandonResponse, error) {
+	return c.AbandonCtx(context.Background(), req)
+}
+
+// AbandonCtx is like Abandon but aborts the request when ctx is done
+func (c *HTTPClient) AbandonCtx(ctx context.Context, req *AbandonRequest) (*AbandonResponse, error) {
 	bodyBytes, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal abandon request: %w", err)
 	}
 
-	httpReq, err := http.NewRequest(
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodPost,
 		c.baseURL+"/api/sdk/agent/abandon",
 		bytes.NewReader(bodyBytes),
@@ -648,7 +1173,7 @@ func (c *HTTPClient) Abandon(req *AbandonRequest) (*AbandonResponse, error) {
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("X-SDK-Version", version.Version())
 
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.doTraced(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call abandon endpoint: %w", err)
 	}
@@ -658,6 +1183,9 @@ func (c *HTTPClient) Abandon(req *AbandonRequest) (*AbandonResponse, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read abandon response: %w", err)
 	}
+	if err := checkHTMLResponse(resp, body); err != nil {
+		return nil, fmt.Errorf("abandon failed: %w", err)
+	}
 
 	if resp.StatusCode == http.StatusNotFound {
 		return nil, fmt.Errorf("reservation not found or already minted")
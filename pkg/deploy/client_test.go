@@ -0,0 +1,521 @@
+package deploy
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// newTestPrivateKey generates a throwaway wallet key for tests
+func newTestPrivateKey(t *testing.T) string {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return hex.EncodeToString(crypto.FromECDSA(key))
+}
+
+// newFakeBackend starts a backend that answers the auth endpoints and routes
+// everything else to handlers keyed by path.
+func newFakeBackend(t *testing.T, handlers map[string]http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/sdk/auth/challenge", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, ChallengeResponse{Challenge: "test-challenge", ExpiresAt: 1 << 40})
+	})
+	mux.HandleFunc("/api/sdk/auth/verify", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, VerifyResponse{SessionToken: "test-session", ExpiresAt: 1 << 40})
+	})
+	for path, handler := range handlers {
+		mux.HandleFunc(path, handler)
+	}
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func TestHTTPClient_StatusCodeSentinelErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   interface{}
+		want   error
+	}{
+		{"rate limited", http.StatusTooManyRequests, ErrorResponse{Error: "slow down"}, ErrRateLimited},
+		{"max reservations", http.StatusTooManyRequests, map[string]string{"error": "MAX_RESERVATIONS", "message": "3 pending"}, ErrMaxReservations},
+		{"conflict", http.StatusConflict, ErrorResponse{Error: "agent_id already taken"}, ErrAgentIDTaken},
+		{"forbidden", http.StatusForbidden, map[string]string{"message": "not owner"}, ErrForbidden},
+		{"not found", http.StatusNotFound, ErrorResponse{Error: "no such agent"}, ErrAgentNotFound},
+		{"unauthorized", http.StatusUnauthorized, ErrorResponse{Error: "expired"}, ErrSessionExpired},
+		{"unavailable", http.StatusServiceUnavailable, map[string]string{"error": "MAINTENANCE"}, ErrServiceUnavailable},
+		{"server error", http.StatusInternalServerError, ErrorResponse{Error: "database timeout"}, ErrServerError},
+	}
+
+	// Each endpoint only maps the status codes it can return
+	endpoints := map[string]struct {
+		path     string
+		call     func(c *HTTPClient) error
+		statuses map[int]bool
+	}{
+		"Sync": {
+			path: "/api/sdk/agent/sync",
+			call: func(c *HTTPClient) error { _, err := c.Sync(&SyncRequest{}); return err },
+			statuses: map[int]bool{
+				http.StatusTooManyRequests: true, http.StatusConflict: true,
+				http.StatusForbidden: true, http.StatusServiceUnavailable: true,
+			},
+		},
+		"Deploy": {
+			path: "/api/sdk/agent/deploy",
+			call: func(c *HTTPClient) error { _, err := c.Deploy("token", &DeployRequest{}); return err },
+			statuses: map[int]bool{
+				http.StatusTooManyRequests: true, http.StatusConflict: true, http.StatusUnauthorized: true,
+			},
+		},
+		"UpdateMetadata": {
+			path: "/api/sdk/agent/update",
+			call: func(c *HTTPClient) error { _, err := c.UpdateMetadata("token", &UpdateMetadataRequest{}); return err },
+			statuses: map[int]bool{
+				http.StatusTooManyRequests: true, http.StatusForbidden: true, http.StatusNotFound: true,
+				http.StatusUnauthorized: true, http.StatusServiceUnavailable: true,
+			},
+		},
+		"GetAgentStatus": {
+			path: "/api/sdk/agent/status",
+			call: func(c *HTTPClient) error { _, err := c.GetAgentStatus("0xwallet", "agent"); return err },
+			statuses: map[int]bool{
+				http.StatusTooManyRequests: true, http.StatusNotFound: true, http.StatusServiceUnavailable: true,
+			},
+		},
+		"ConfirmMint": {
+			path: "/api/sdk/agent/confirm-mint",
+			call: func(c *HTTPClient) error { _, err := c.ConfirmMint("token", &ConfirmMintRequest{}); return err },
+			statuses: map[int]bool{
+				http.StatusTooManyRequests: true, http.StatusUnauthorized: true,
+				http.StatusServiceUnavailable: true, http.StatusInternalServerError: true,
+			},
+		},
+		"ListAgents": {
+			path: "/api/sdk/agent/list",
+			call: func(c *HTTPClient) error { _, err := c.ListAgents("0xwallet", 1, 10); return err },
+			statuses: map[int]bool{
+				http.StatusTooManyRequests: true, http.StatusServiceUnavailable: true,
+			},
+		},
+	}
+
+	for endpointName, endpoint := range endpoints {
+		for _, tt := range tests {
+			if !endpoint.statuses[tt.status] {
+				continue
+			}
+			endpoint, tt := endpoint, tt
+			t.Run(endpointName+"/"+tt.name, func(t *testing.T) {
+				srv := newFakeBackend(t, map[string]http.HandlerFunc{
+					endpoint.path: func(w http.ResponseWriter, r *http.Request) {
+						writeJSON(w, tt.status, tt.body)
+					},
+				})
+
+				err := endpoint.call(NewHTTPClient(srv.URL))
+				if !errors.Is(err, tt.want) {
+					t.Errorf("expected errors.Is(err, %v), got: %v", tt.want, err)
+				}
+			})
+		}
+	}
+}
+
+func TestHTTPClient_ConflictErrors(t *testing.T) {
+	srv := newFakeBackend(t, map[string]http.HandlerFunc{
+		"/api/sdk/agent/sync": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusConflict, ErrorResponse{Error: "agent_id reserved"})
+		},
+		"/api/sdk/agent/deploy": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusConflict, ErrorResponse{Error: "agent_id already exists"})
+		},
+	})
+	client := NewHTTPClient(srv.URL)
+
+	// A Sync conflict is a race with another request and can be retried,
+	// so it must not look like an existing agent
+	_, err := client.Sync(&SyncRequest{})
+	if !errors.Is(err, ErrAgentIDTaken) || errors.Is(err, ErrAgentExists) {
+		t.Errorf("Sync conflict: want ErrAgentIDTaken but not ErrAgentExists, got: %v", err)
+	}
+
+	_, err = client.Deploy("token", &DeployRequest{})
+	if !errors.Is(err, ErrAgentExists) || !errors.Is(err, ErrAgentIDTaken) {
+		t.Errorf("Deploy conflict: want ErrAgentExists and ErrAgentIDTaken, got: %v", err)
+	}
+}
+
+func TestHTTPClient_HTMLErrorPages(t *testing.T) {
+	endpoints := map[string]struct {
+		path string
+		call func(c *HTTPClient) error
+	}{
+		"Sync":   {"/api/sdk/agent/sync", func(c *HTTPClient) error { _, err := c.Sync(&SyncRequest{}); return err }},
+		"Deploy": {"/api/sdk/agent/deploy", func(c *HTTPClient) error { _, err := c.Deploy("token", &DeployRequest{}); return err }},
+		"ConfirmMint": {"/api/sdk/agent/confirm-mint", func(c *HTTPClient) error {
+			_, err := c.ConfirmMint("token", &ConfirmMintRequest{})
+			return err
+		}},
+		"UpdateMetadata": {"/api/sdk/agent/update", func(c *HTTPClient) error {
+			_, err := c.UpdateMetadata("token", &UpdateMetadataRequest{})
+			return err
+		}},
+		"GetSchema": {"/api/sdk/schema", func(c *HTTPClient) error { _, err := c.GetSchema(); return err }},
+	}
+
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		want        error
+	}{
+		{"proxy 502 page", http.StatusBadGateway, "text/html; charset=utf-8", ErrServerError},
+		{"maintenance page", http.StatusServiceUnavailable, "text/html", ErrServiceUnavailable},
+		{"HTML served as JSON", http.StatusOK, "application/json", nil},
+	}
+
+	for endpointName, endpoint := range endpoints {
+		for _, tt := range tests {
+			endpoint, tt := endpoint, tt
+			t.Run(endpointName+"/"+tt.name, func(t *testing.T) {
+				srv := newFakeBackend(t, map[string]http.HandlerFunc{
+					endpoint.path: func(w http.ResponseWriter, r *http.Request) {
+						w.Header().Set("Content-Type", tt.contentType)
+						w.WriteHeader(tt.status)
+						io.WriteString(w, "\n<!DOCTYPE html><html><body>Bad Gateway</body></html>")
+					},
+				})
+
+				err := endpoint.call(NewHTTPClient(srv.URL))
+				if !errors.Is(err, ErrHTMLResponse) {
+					t.Fatalf("expected errors.Is(err, ErrHTMLResponse), got: %v", err)
+				}
+				if want := fmt.Sprintf("(status %d)", tt.status); !strings.Contains(err.Error(), want) {
+					t.Errorf("expected error to mention %q, got: %v", want, err)
+				}
+				if tt.want != nil && !errors.Is(err, tt.want) {
+					t.Errorf("expected errors.Is(err, %v), got: %v", tt.want, err)
+				}
+			})
+		}
+	}
+}
+
+func TestHTTPClient_HTMLErrorPagesKeepStatusSentinels(t *testing.T) {
+	sessionCalls := map[string]func(c *HTTPClient) error{
+		"/api/sdk/agent/deploy":       func(c *HTTPClient) error { _, err := c.Deploy("token", &DeployRequest{}); return err },
+		"/api/sdk/agent/confirm-mint": func(c *HTTPClient) error { _, err := c.ConfirmMint("token", &ConfirmMintRequest{}); return err },
+		"/api/sdk/agent/update": func(c *HTTPClient) error {
+			_, err := c.UpdateMetadata("token", &UpdateMetadataRequest{})
+			return err
+		},
+	}
+	tests := []struct {
+		path   string
+		status int
+		want   error
+	}{
+		{"/api/sdk/agent/deploy", http.StatusUnauthorized, ErrSessionExpired},
+		{"/api/sdk/agent/confirm-mint", http.StatusUnauthorized, ErrSessionExpired},
+		{"/api/sdk/agent/update", http.StatusUnauthorized, ErrSessionExpired},
+		{"/api/sdk/agent/update", http.StatusNotFound, ErrAgentNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.path, tt.status), func(t *testing.T) {
+			srv := newFakeBackend(t, map[string]http.HandlerFunc{
+				tt.path: func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "text/html")
+					w.WriteHeader(tt.status)
+					io.WriteString(w, "<html><body>Error</body></html>")
+				},
+			})
+
+			if err := sessionCalls[tt.path](NewHTTPClient(srv.URL)); !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPClient_MaxReservationsIsNotRateLimited(t *testing.T) {
+	srv := newFakeBackend(t, map[string]http.HandlerFunc{
+		"/api/sdk/agent/sync": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusTooManyRequests, map[string]string{"error": "MAX_RESERVATIONS"})
+		},
+	})
+
+	_, err := NewHTTPClient(srv.URL).Sync(&SyncRequest{})
+	if !errors.Is(err, ErrMaxReservations) {
+		t.Fatalf("expected ErrMaxReservations, got: %v", err)
+	}
+	if errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected MAX_RESERVATIONS to be distinguishable from a plain rate limit")
+	}
+}
+
+func TestHTTPClient_ChallengeRateLimited(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusTooManyRequests, ErrorResponse{Error: "too many requests"})
+	}))
+	defer srv.Close()
+
+	_, err := NewHTTPClient(srv.URL).RequestChallenge("0xabc")
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected ErrRateLimited, got: %v", err)
+	}
+}
+
+func TestHTTPClient_GetAgentStatus(t *testing.T) {
+	tokenID := int64(77)
+	updatedAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	var gotWallet, gotAgentID string
+	srv := newFakeBackend(t, map[string]http.HandlerFunc{
+		"/api/sdk/agent/status": func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				t.Errorf("expected GET, got %s", r.Method)
+			}
+			gotWallet = r.URL.Query().Get("wallet")
+			gotAgentID = r.URL.Query().Get("agent_id")
+			if gotAgentID != "known-agent" {
+				writeJSON(w, http.StatusNotFound, ErrorResponse{Error: "agent not found"})
+				return
+			}
+			writeJSON(w, http.StatusOK, AgentStatusResponse{
+				AgentID:         gotAgentID,
+				Wallet:          gotWallet,
+				TokenID:         &tokenID,
+				ContractAddress: "0xcontract",
+				ConfigHash:      "abc123",
+				IsPublic:        true,
+				Status:          "minted",
+				UpdatedAt:       updatedAt,
+			})
+		},
+	})
+	client := NewHTTPClient(srv.URL)
+
+	status, err := client.GetAgentStatus("0xwallet", "known-agent")
+	if err != nil {
+		t.Fatalf("GetAgentStatus() error = %v", err)
+	}
+	if gotWallet != "0xwallet" {
+		t.Errorf("expected wallet query param, got %q", gotWallet)
+	}
+	if status.TokenID == nil || *status.TokenID != 77 || status.ConfigHash != "abc123" ||
+		!status.IsPublic || status.ContractAddress != "0xcontract" || !status.UpdatedAt.Equal(updatedAt) {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	if _, err := client.GetAgentStatus("0xwallet", "missing-agent"); !errors.Is(err, ErrAgentNotFound) {
+		t.Errorf("expected ErrAgentNotFound, got %v", err)
+	}
+}
+
+// newPaginatedBackend serves GET /api/sdk/agent/list over total agents and
+// records the page and page_size of every request
+func newPaginatedBackend(t *testing.T, total int, seen *[][2]int) *httptest.Server {
+	t.Helper()
+	return newFakeBackend(t, map[string]http.HandlerFunc{
+		"/api/sdk/agent/list": func(w http.ResponseWriter, r *http.Request) {
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+			*seen = append(*seen, [2]int{page, pageSize})
+
+			resp := ListAgentsResponse{Page: page, PageSize: pageSize, Total: total}
+			for i := (page - 1) * pageSize; i < page*pageSize && i < total; i++ {
+				tokenID := int64(i + 1)
+				resp.Agents = append(resp.Agents, AgentSummary{
+					AgentID:   fmt.Sprintf("agent-%d", i),
+					AgentName: fmt.Sprintf("Agent %d", i),
+					TokenID:   &tokenID,
+					Status:    "minted",
+				})
+			}
+			resp.HasMore = page*pageSize < total
+			writeJSON(w, http.StatusOK, resp)
+		},
+	})
+}
+
+func TestHTTPClient_ListAgents(t *testing.T) {
+	var seen [][2]int
+	srv := newPaginatedBackend(t, 5, &seen)
+	client := NewHTTPClient(srv.URL)
+
+	first, err := client.ListAgents("0xwallet", 1, 2)
+	if err != nil {
+		t.Fatalf("ListAgents() error = %v", err)
+	}
+	if len(first.Agents) != 2 || !first.HasMore || first.Total != 5 || first.Agents[0].AgentID != "agent-0" {
+		t.Errorf("unexpected first page: %+v", first)
+	}
+
+	last, err := client.ListAgents("0xwallet", 3, 2)
+	if err != nil {
+		t.Fatalf("ListAgents() error = %v", err)
+	}
+	if len(last.Agents) != 1 || last.HasMore || last.Agents[0].AgentID != "agent-4" ||
+		last.Agents[0].TokenID == nil || *last.Agents[0].TokenID != 5 {
+		t.Errorf("unexpected last page: %+v", last)
+	}
+
+	beyond, err := client.ListAgents("0xwallet", 4, 2)
+	if err != nil {
+		t.Fatalf("ListAgents() error = %v", err)
+	}
+	if beyond.Agents == nil || len(beyond.Agents) != 0 || beyond.HasMore {
+		t.Errorf("expected empty non-nil last page, got %+v", beyond)
+	}
+
+	// Out-of-range arguments are normalized before the request is sent
+	client.ListAgents("0xwallet", 0, 0)
+	client.ListAgents("0xwallet", 1, MaxListPageSize+1)
+
+	want := [][2]int{{1, 2}, {3, 2}, {4, 2}, {1, DefaultListPageSize}, {1, MaxListPageSize}}
+	if !reflect.DeepEqual(seen, want) {
+		t.Errorf("page cursors = %v, want %v", seen, want)
+	}
+}
+
+func TestHTTPClient_ListAgentsEmpty(t *testing.T) {
+	srv := newFakeBackend(t, map[string]http.HandlerFunc{
+		"/api/sdk/agent/list": func(w http.ResponseWriter, r *http.Request) {
+			// A backend may omit the list entirely and still claim more pages
+			w.Write([]byte(`{"agents":null,"total":0,"has_more":true}`))
+		},
+	})
+
+	resp, err := NewHTTPClient(srv.URL).ListAgents("0xwallet", 1, 10)
+	if err != nil {
+		t.Fatalf("ListAgents() error = %v", err)
+	}
+	if resp.Agents == nil || len(resp.Agents) != 0 || resp.HasMore || resp.Page != 1 || resp.PageSize != 10 {
+		t.Errorf("unexpected empty response: %+v", resp)
+	}
+}
+
+// countingTransport records requests before delegating to the default transport
+type countingTransport struct {
+	paths []string
+}
+
+func (c *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	c.paths = append(c.paths, r.URL.Path)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestHTTPClient_WithHTTPClient(t *testing.T) {
+	srv := newFakeBackend(t, map[string]http.HandlerFunc{
+		"/api/sdk/schema": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, SchemaResponse{SchemaVersion: "3"})
+		},
+	})
+
+	transport := &countingTransport{}
+	client := NewHTTPClient(srv.URL, WithHTTPClient(&http.Client{Transport: transport}))
+	if _, err := client.GetSchema(); err != nil {
+		t.Fatalf("GetSchema() error = %v", err)
+	}
+	if _, err := client.RequestChallenge("0xwallet"); err != nil {
+		t.Fatalf("RequestChallenge() error = %v", err)
+	}
+	if want := []string{"/api/sdk/schema", "/api/sdk/auth/challenge"}; !reflect.DeepEqual(transport.paths, want) {
+		t.Errorf("injected client saw %v, want %v", transport.paths, want)
+	}
+
+	// MintConfig threads the same client through the Minter
+	minterTransport := &countingTransport{}
+	minter, err := NewMinter(&MintConfig{
+		PrivateKey: newTestPrivateKey(t),
+		BackendURL: srv.URL,
+		HTTPClient: &http.Client{Transport: minterTransport},
+	})
+	if err != nil {
+		t.Fatalf("failed to create minter: %v", err)
+	}
+	if _, err := minter.httpClient.GetSchema(); err != nil || len(minterTransport.paths) != 1 {
+		t.Errorf("expected minter to use injected client, saw %v (err %v)", minterTransport.paths, err)
+	}
+
+	// A nil client keeps the default
+	if NewHTTPClient(srv.URL, WithHTTPClient(nil)).httpClient == nil {
+		t.Error("WithHTTPClient(nil) should keep the default client")
+	}
+}
+
+// hangingHandler blocks until the client gives up on the request
+func hangingHandler(w http.ResponseWriter, r *http.Request) {
+	// The server only notices a dropped client once the body has been read
+	io.Copy(io.Discard, r.Body)
+	select {
+	case <-r.Context().Done():
+	case <-time.After(10 * time.Second):
+	}
+}
+
+func TestHTTPClient_ContextCancelsInFlightRequest(t *testing.T) {
+	calls := map[string]func(ctx context.Context, c *HTTPClient) error{
+		"/api/sdk/agent/sync": func(ctx context.Context, c *HTTPClient) error {
+			_, err := c.SyncCtx(ctx, &SyncRequest{})
+			return err
+		},
+		"/api/sdk/agent/deploy": func(ctx context.Context, c *HTTPClient) error {
+			_, err := c.DeployCtx(ctx, "token", &DeployRequest{})
+			return err
+		},
+		"/api/sdk/agent/confirm-mint": func(ctx context.Context, c *HTTPClient) error {
+			_, err := c.ConfirmMintCtx(ctx, "token", &ConfirmMintRequest{})
+			return err
+		},
+		"/api/sdk/schema": func(ctx context.Context, c *HTTPClient) error {
+			_, err := c.GetSchemaCtx(ctx)
+			return err
+		},
+	}
+
+	for path, call := range calls {
+		path, call := path, call
+		t.Run(path, func(t *testing.T) {
+			srv := newFakeBackend(t, map[string]http.HandlerFunc{path: hangingHandler})
+			client := NewHTTPClient(srv.URL)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(50*time.Millisecond, cancel)
+
+			start := time.Now()
+			err := call(ctx, client)
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("expected context.Canceled, got %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("call returned after %v, expected prompt cancellation", elapsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// diffHashedConfig describes, one line per change, how the fields covered by
+// the config hash differ between the backend's last-known config and the
+// local one: name, description, agent type, NLP fallback, capabilities,
+// categories and commands with their prices, billing terms and parameters.
+// The image is not hashed and never reported.
+func diffHashedConfig(previous, current *AgentConfig) []string {
+	var changes []string
+	field := func(name, before, after string) {
+		if before != after {
+			changes = append(changes, fmt.Sprintf("%s changed from %q to %q", name, before, after))
+		}
+	}
+	field("name", previous.Name, current.Name)
+	field("description", previous.Description, current.Description)
+	field("agent type", previous.AgentType, current.AgentType)
+	if previous.NlpFallback != current.NlpFallback {
+		changes = append(changes, fmt.Sprintf("nlp fallback changed from %t to %t", previous.NlpFallback, current.NlpFallback))
+	}
+
+	capabilityNames := func(caps []Capability) []string {
+		names := make([]string, len(caps))
+		for i, c := range caps {
+			names[i] = c.Name
+		}
+		return names
+	}
+	changes = append(changes, diffSet("capability", capabilityNames(previous.Capabilities), capabilityNames(current.Capabilities))...)
+	changes = append(changes, diffSet("category", previous.Categories, current.Categories)...)
+
+	before := make(map[string]Command, len(previous.Commands))
+	for _, cmd := range previous.Commands {
+		before[cmd.Trigger] = cmd
+	}
+	after := make(map[string]Command, len(current.Commands))
+	for _, cmd := range current.Commands {
+		after[cmd.Trigger] = cmd
+	}
+	for _, cmd := range sortedCommands(previous.Commands) {
+		if _, ok := after[cmd.Trigger]; !ok {
+			changes = append(changes, fmt.Sprintf("command %q removed", cmd.Trigger))
+		}
+	}
+	for _, cmd := range sortedCommands(current.Commands) {
+		old, ok := before[cmd.Trigger]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("command %q added", cmd.Trigger))
+			continue
+		}
+		if old.PricePerUnit != cmd.PricePerUnit {
+			changes = append(changes, fmt.Sprintf("command %q price changed from %s to %s", cmd.Trigger,
+				strconv.FormatFloat(old.PricePerUnit, 'f', -1, 64), strconv.FormatFloat(cmd.PricePerUnit, 'f', -1, 64)))
+		}
+		if old.PriceType != cmd.PriceType || old.TaskUnit != cmd.TaskUnit {
+			changes = append(changes, fmt.Sprintf("command %q billing changed from %s/%s to %s/%s", cmd.Trigger,
+				old.PriceType, old.TaskUnit, cmd.PriceType, cmd.TaskUnit))
+		}
+		if canonicalParameters(old.Parameters) != canonicalParameters(cmd.Parameters) {
+			changes = append(changes, fmt.Sprintf("command %q parameters changed", cmd.Trigger))
+		}
+	}
+	return changes
+}
+
+// diffSet reports the items added to and removed from an unordered set
+func diffSet(kind string, previous, current []string) []string {
+	before := make(map[string]bool, len(previous))
+	for _, item := range previous {
+		before[item] = true
+	}
+	after := make(map[string]bool, len(current))
+	for _, item := range current {
+		after[item] = true
+	}
+
+	var added, removed []string
+	for item := range after {
+		if !before[item] {
+			added = append(added, item)
+		}
+	}
+	for item := range before {
+		if !after[item] {
+			removed = append(removed, item)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+
+	var changes []string
+	if len(added) > 0 {
+		changes = append(changes, fmt.Sprintf("%s added: %s", kind, strings.Join(added, ", ")))
+	}
+	if len(removed) > 0 {
+		changes = append(changes, fmt.Sprintf("%s removed: %s", kind, strings.Join(removed, ", ")))
+	}
+	return changes
+}
+
+// configChanges compares config with the config the backend last recorded
+// for it, read from the status endpoint, and logs the differences. Returns
+// nil when the backend does not report its config.
+func (m *Minter) configChanges(ctx context.Context, wallet string, config *AgentConfig) []string {
+	status, err := m.httpClient.GetAgentStatusCtx(ctx, wallet, config.AgentID)
+	if err != nil || status.Config == nil {
+		if err == nil {
+			err = fmt.Errorf("status has no config")
+		}
+		log.Printf("⚠️ Cannot show what changed: %v", err)
+		return nil
+	}
+
+	changes := diffHashedConfig(status.Config, config)
+	for _, change := range changes {
+		log.Printf("   • %s", change)
+	}
+	return changes
+}
+
+// updateMessage is the MintResult message of a metadata update
+func updateMessage(changes []string) string {
+	if len(changes) == 0 {
+		return "Agent metadata updated successfully"
+	}
+	return "Agent metadata updated successfully: " + strings.Join(changes, "; ")
+}
@@ -0,0 +1,109 @@
+package deploy
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func diffTestConfig() *AgentConfig {
+	config := conflictTestConfig()
+	config.Commands = []Command{{Trigger: "quote", PricePerUnit: 0.01, PriceType: "task-transaction", TaskUnit: "per-query"}}
+	return config
+}
+
+func TestDiffHashedConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(c *AgentConfig)
+		want   []string
+	}{
+		{"unchanged", func(c *AgentConfig) {}, nil},
+		{"image is not hashed", func(c *AgentConfig) { c.Image = "https://example.com/new.png" }, nil},
+		{"name", func(c *AgentConfig) { c.Name = "Renamed Agent" },
+			[]string{`name changed from "Conflict Agent" to "Renamed Agent"`}},
+		{"price", func(c *AgentConfig) { c.Commands[0].PricePerUnit = 0.02 },
+			[]string{`command "quote" price changed from 0.01 to 0.02`}},
+		{"billing", func(c *AgentConfig) { c.Commands[0].TaskUnit = "per-item" },
+			[]string{`command "quote" billing changed from task-transaction/per-query to task-transaction/per-item`}},
+		{"parameters", func(c *AgentConfig) { c.Commands[0].Parameters = []CommandParameter{{Name: "symbol", Type: "string"}} },
+			[]string{`command "quote" parameters changed`}},
+		{"commands", func(c *AgentConfig) { c.Commands = []Command{{Trigger: "swap"}} },
+			[]string{`command "quote" removed`, `command "swap" added`}},
+		{"sets", func(c *AgentConfig) {
+			c.Categories = []string{"DeFi", "AI"}
+			c.Capabilities = []Capability{{Name: "other"}}
+		}, []string{"capability added: other", "capability removed: cap", "category added: DeFi"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := diffTestConfig()
+			tt.change(current)
+			got := diffHashedConfig(diffTestConfig(), current)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("diffHashedConfig() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyncAndMint_UpdateMessageNamesChangedFields(t *testing.T) {
+	tokenID := int64(9)
+	tests := []struct {
+		name     string
+		change   func(c *AgentConfig)
+		want     string
+		unwanted string
+	}{
+		{"price change", func(c *AgentConfig) { c.Commands[0].PricePerUnit = 0.05 }, `command "quote" price changed from 0.01 to 0.05`, "name"},
+		{"name change", func(c *AgentConfig) { c.Name = "Renamed Agent" }, `name changed from "Conflict Agent" to "Renamed Agent"`, "price"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var syncs int
+			srv := newFakeBackend(t, map[string]http.HandlerFunc{
+				"/api/sdk/agent/sync": func(w http.ResponseWriter, r *http.Request) {
+					syncs++
+					if syncs == 1 {
+						writeJSON(w, http.StatusOK, SyncResponse{Status: "UPDATE_REQUIRED", TokenID: &tokenID, CurrentHash: "0xold", NewHash: "0xnew"})
+						return
+					}
+					writeJSON(w, http.StatusOK, SyncResponse{Status: "SYNCED", TokenID: &tokenID})
+				},
+				"/api/sdk/agent/status": func(w http.ResponseWriter, r *http.Request) {
+					writeJSON(w, http.StatusOK, AgentStatusResponse{AgentID: "conflict-agent", Config: diffTestConfig()})
+				},
+				"/api/sdk/agent/update": func(w http.ResponseWriter, r *http.Request) {
+					writeJSON(w, http.StatusOK, UpdateMetadataResponse{Success: true, IpfsHash: "Qm", TxHash: "0xupdate"})
+				},
+			})
+			minter, err := NewMinter(&MintConfig{PrivateKey: newTestPrivateKey(t), BackendURL: srv.URL})
+			if err != nil {
+				t.Fatalf("failed to create minter: %v", err)
+			}
+			minter.walClient = NewWALClientWithDir(t.TempDir())
+
+			config := diffTestConfig()
+			tt.change(config)
+			result, err := minter.syncAndMint(context.Background(), config, GenerateConfigHash(config), "")
+			if err != nil {
+				t.Fatalf("syncAndMint() error = %v", err)
+			}
+			if result.Status != MintStatusUpdated || !strings.Contains(result.Message, tt.want) {
+				t.Errorf("result = %+v, want an update message containing %q", result, tt.want)
+			}
+			if strings.Contains(result.Message, tt.unwanted) {
+				t.Errorf("message %q mentions %s, which did not change", result.Message, tt.unwanted)
+			}
+		})
+	}
+}
+
+func TestUpdateMessage_StatusWithoutConfig(t *testing.T) {
+	if got := updateMessage(nil); got != "Agent metadata updated successfully" {
+		t.Errorf("updateMessage(nil) = %q", got)
+	}
+}
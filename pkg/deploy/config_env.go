@@ -0,0 +1,87 @@
+package deploy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// ErrUndefinedEnvVar is returned by strict interpolation when a config
+// references an environment variable that is not set and has no default
+var ErrUndefinedEnvVar = errors.New("undefined environment variable")
+
+// envVarPattern matches ${NAME} and ${NAME:-default}
+var envVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// expandEnv replaces ${NAME} and ${NAME:-default} in s with the value of the
+// environment variable NAME. As in the shell, the default is used when NAME
+// is unset or empty. An unset variable without a default becomes empty, or
+// fails with ErrUndefinedEnvVar when strict is set. Any other '$' is kept.
+func expandEnv(s string, strict bool) (string, error) {
+	var missing error
+	out := envVarPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		m := envVarPattern.FindStringSubmatch(ref)
+		name, hasDefault, def := m[1], m[2] != "", m[3]
+
+		value, ok := os.LookupEnv(name)
+		switch {
+		case hasDefault && value == "":
+			return def
+		case !ok && strict && missing == nil:
+			missing = fmt.Errorf("%w: %s", ErrUndefinedEnvVar, name)
+		}
+		return value
+	})
+	return out, missing
+}
+
+// interpolateEnv expands environment variable references in every string
+// value of a JSON agent config. Values are substituted after decoding, so
+// quotes or backslashes in a variable cannot break the JSON; keys and
+// numbers are left as they are.
+func interpolateEnv(data []byte, strict bool) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	doc, err := expandEnvValues(doc, strict)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// expandEnvValues applies expandEnv to the strings of a decoded JSON value
+func expandEnvValues(v interface{}, strict bool) (interface{}, error) {
+	var err error
+	switch v := v.(type) {
+	case string:
+		return expandEnv(v, strict)
+	case map[string]interface{}:
+		for key, item := range v {
+			if v[key], err = expandEnvValues(item, strict); err != nil {
+				return nil, err
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			if v[i], err = expandEnvValues(item, strict); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return v, nil
+}
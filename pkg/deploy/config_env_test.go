@@ -0,0 +1,92 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("TENEO_TEST_HOST", "cdn.example.com")
+	t.Setenv("TENEO_TEST_EMPTY", "")
+
+	tests := []struct {
+		name    string
+		in      string
+		strict  bool
+		want    string
+		wantErr bool
+	}{
+		{"defined", "https://${TENEO_TEST_HOST}/agent.png", true, "https://cdn.example.com/agent.png", false},
+		{"default unused", "${TENEO_TEST_HOST:-localhost}", true, "cdn.example.com", false},
+		{"default for unset", "${TENEO_TEST_UNSET:-localhost}", true, "localhost", false},
+		{"default for empty", "${TENEO_TEST_EMPTY:-localhost}", true, "localhost", false},
+		{"empty default", "[${TENEO_TEST_UNSET:-}]", true, "[]", false},
+		{"undefined lenient", "[${TENEO_TEST_UNSET}]", false, "[]", false},
+		{"undefined strict", "[${TENEO_TEST_UNSET}]", true, "", true},
+		{"empty is defined", "[${TENEO_TEST_EMPTY}]", true, "[]", false},
+		{"other dollars kept", "costs $5, $HOME or ${not valid}", true, "costs $5, $HOME or ${not valid}", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandEnv(tt.in, tt.strict)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUndefinedEnvVar) {
+					t.Errorf("expandEnv(%q) error = %v, want ErrUndefinedEnvVar", tt.in, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("expandEnv(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_InterpolatesEnv(t *testing.T) {
+	t.Setenv("TENEO_TEST_STAGE", `staging "eu"`)
+
+	fields := validAgentFields()
+	fields["description"] = "Deployed to ${TENEO_TEST_STAGE} for ${TENEO_TEST_TEAM:-the core team}"
+	path := writeAgentJSON(t, fields)
+
+	minter, _ := newOfflineSchemaMinter(t, "")
+	config, _, err := minter.loadConfig(context.Background(), path)
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if config.Description != fields["description"] {
+		t.Errorf("description = %q, want it unchanged when interpolation is off", config.Description)
+	}
+
+	minter.config.InterpolateEnv = true
+	config, _, err = minter.loadConfig(context.Background(), path)
+	if err != nil {
+		t.Fatalf("loadConfig() with InterpolateEnv error = %v", err)
+	}
+	if want := `Deployed to staging "eu" for the core team`; config.Description != want {
+		t.Errorf("description = %q, want %q", config.Description, want)
+	}
+}
+
+func TestLoadConfig_StrictEnvRejectsUndefined(t *testing.T) {
+	fields := validAgentFields()
+	fields["description"] = "Deployed to ${TENEO_TEST_UNSET_STAGE}"
+	path := writeAgentJSON(t, fields)
+
+	minter, _ := newOfflineSchemaMinter(t, "")
+	minter.config.InterpolateEnv = true
+	config, _, err := minter.loadConfig(context.Background(), path)
+	if err != nil {
+		t.Fatalf("lenient loadConfig() error = %v", err)
+	}
+	if config.Description != "Deployed to " {
+		t.Errorf("lenient description = %q, want the variable left empty", config.Description)
+	}
+
+	minter.config.StrictEnv = true
+	if _, _, err := minter.loadConfig(context.Background(), path); !errors.Is(err, ErrUndefinedEnvVar) {
+		t.Errorf("loadConfig() error = %v, want ErrUndefinedEnvVar", err)
+	}
+}
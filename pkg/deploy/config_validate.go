@@ -0,0 +1,72 @@
+package deploy
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ValidateConfig runs the local validation a Minter applies to an agent
+// config before minting: required fields, lengths, HTML tags, agent type,
+// categories, capabilities, commands and, for mcp agents, the presence of
+// mcpManifest. The config's JSON encoding is also checked against the size
+// limit and schema snapshot bundled with the SDK. It needs no private key or
+// backend, e.g. for CI checks.
+func ValidateConfig(cfg *AgentConfig) error {
+	if cfg == nil {
+		return fmt.Errorf("config is required")
+	}
+	if err := preValidate(cfg); err != nil {
+		return fmt.Errorf("pre-validation failed: %w", err)
+	}
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := validateAgainstBundledSchema(data); err != nil {
+		return err
+	}
+
+	if err := validateConfig(cfg); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+	return nil
+}
+
+// ValidateConfigFile reads an agent config file as Minter.Mint does (JSON,
+// or YAML by extension, with local images inlined) and validates it with
+// ValidateConfig and the schema snapshot bundled with the SDK, including its
+// size limit. Nothing is fetched, so the MCP manifest is not downloaded.
+func ValidateConfigFile(path string) error {
+	config, data, err := readConfigFile(path, false, false)
+	if err != nil {
+		return err
+	}
+
+	if err := preValidate(config); err != nil {
+		return fmt.Errorf("pre-validation failed: %w", err)
+	}
+	if err := validateAgainstBundledSchema(data); err != nil {
+		return err
+	}
+	if err := validateConfig(config); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+	return nil
+}
+
+// validateAgainstBundledSchema checks config JSON against the size limit and
+// rules of the schema snapshot bundled with the SDK
+func validateAgainstBundledSchema(data []byte) error {
+	schema, err := loadSchemaSnapshot("")
+	if err != nil {
+		return err
+	}
+	if schema.MaxJSONSize > 0 && len(data) > schema.MaxJSONSize {
+		return fmt.Errorf("JSON file too large (schema limit: %d bytes, got %d)", schema.MaxJSONSize, len(data))
+	}
+	if err := validateAgainstSchema(data, schema); err != nil {
+		return fmt.Errorf("schema validation failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package deploy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateConfig_PublicAPI(t *testing.T) {
+	for _, tt := range validateConfigTests() {
+		t.Run(tt.name, func(t *testing.T) {
+			// The bundled schema may reject a case before validateConfig does,
+			// so only the outcome is compared
+			if err := ValidateConfig(tt.config); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := ValidateConfig(nil); err == nil {
+		t.Error("ValidateConfig(nil) succeeded, want an error")
+	}
+	bad := commandConfig()
+	bad.AgentID = "Not-Lowercase"
+	if err := ValidateConfig(bad); err == nil || !contains(err.Error(), "pre-validation failed") {
+		t.Errorf("ValidateConfig() error = %v, want the agentId format check", err)
+	}
+
+	oversized := commandConfig()
+	oversized.Capabilities = nil
+	for i := 0; i < 50; i++ {
+		oversized.Capabilities = append(oversized.Capabilities, Capability{
+			Name:        fmt.Sprintf("capability-%d", i),
+			Description: strings.Repeat("d", 500),
+		})
+	}
+	if err := ValidateConfig(oversized); err == nil || !contains(err.Error(), "JSON file too large") {
+		t.Errorf("ValidateConfig() error = %v, want the bundled size limit", err)
+	}
+}
+
+func TestValidateConfigFile(t *testing.T) {
+	for _, tt := range validateConfigTests() {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(t.TempDir(), "agent.json")
+			if err := os.WriteFile(path, data, 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			// The bundled schema may reject a case before validateConfig does,
+			// so only the outcome is compared
+			if err := ValidateConfigFile(path); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateConfigFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateConfigFile_ReadErrors(t *testing.T) {
+	dir := t.TempDir()
+	oversized := filepath.Join(dir, "big.json")
+	if err := os.WriteFile(oversized, []byte(`{"name":"`+strings.Repeat("a", DefaultMaxJSONSize)+`"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte(`{"name":`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		errMsg string
+	}{
+		{"missing file", filepath.Join(dir, "missing.json"), "failed to stat file"},
+		{"too large", oversized, "JSON file too large"},
+		{"invalid JSON", invalid, "invalid JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateConfigFile(tt.path); err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("ValidateConfigFile() error = %v, want error containing %q", err, tt.errMsg)
+			}
+		})
+	}
+
+	// YAML goes through the same checks
+	if err := ValidateConfigFile(writeAgentYAML(t, "agent.yaml", offlineAgentYAML)); err != nil {
+		t.Errorf("ValidateConfigFile(YAML) error = %v", err)
+	}
+}
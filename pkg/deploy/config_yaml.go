@@ -0,0 +1,43 @@
+package deploy
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MaxYAMLFileSize bounds how much of a YAML agent config is read. YAML
+// leaves room for comments and block text, so the file may be larger than
+// DefaultMaxJSONSize; its JSON equivalent must still fit the JSON limits.
+const MaxYAMLFileSize = 4 * DefaultMaxJSONSize
+
+// isYAMLPath reports whether an agent config file is YAML, by extension
+func isYAMLPath(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// yamlToJSON converts a YAML agent config into its compact JSON equivalent,
+// so it is validated, size-checked and hashed exactly like a JSON file with
+// the same content.
+func yamlToJSON(data []byte) ([]byte, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if _, ok := doc.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("agent config must be a mapping, got %T", doc)
+	}
+
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("cannot be represented as JSON: %w", err)
+	}
+	return out, nil
+}
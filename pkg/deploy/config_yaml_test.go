@@ -0,0 +1,97 @@
+package deploy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeAgentYAML writes raw agent YAML to a temp file and returns its path
+func writeAgentYAML(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const offlineAgentYAML = `# Same config as validAgentFields, written by hand
+name: Offline Agent
+agentId: offline-agent
+description: >-
+  Validated without
+  network access
+agentType: command
+categories:
+  - AI
+capabilities:
+  - name: cap # the only capability
+`
+
+func TestLoadConfig_YAMLMatchesJSON(t *testing.T) {
+	minter, _ := newOfflineSchemaMinter(t, "")
+
+	fromJSON, jsonVersion, err := minter.loadConfig(context.Background(), writeAgentJSON(t, validAgentFields()))
+	if err != nil {
+		t.Fatalf("loadConfig(JSON) error = %v", err)
+	}
+
+	for _, name := range []string{"agent.yaml", "agent.YML"} {
+		fromYAML, yamlVersion, err := minter.loadConfig(context.Background(), writeAgentYAML(t, name, offlineAgentYAML))
+		if err != nil {
+			t.Fatalf("loadConfig(%s) error = %v", name, err)
+		}
+		if !reflect.DeepEqual(fromYAML, fromJSON) {
+			t.Errorf("loadConfig(%s) = %+v, want %+v", name, fromYAML, fromJSON)
+		}
+		if got, want := GenerateConfigHashForSchema(fromYAML, yamlVersion), GenerateConfigHashForSchema(fromJSON, jsonVersion); got != want {
+			t.Errorf("%s hashes to %s, want the JSON hash %s", name, got, want)
+		}
+	}
+}
+
+func TestLoadConfig_YAMLSizeLimitAppliesToJSONEquivalent(t *testing.T) {
+	minter, _ := newOfflineSchemaMinter(t, "")
+
+	// Comments don't count: a large commented file still loads
+	padding := strings.Repeat("# "+strings.Repeat("x", 78)+"\n", DefaultMaxJSONSize/80+1)
+	if _, _, err := minter.loadConfig(context.Background(), writeAgentYAML(t, "agent.yaml", padding+offlineAgentYAML)); err != nil {
+		t.Errorf("loadConfig() error = %v for a large commented YAML file", err)
+	}
+
+	// Content does: a description that only fits as YAML is rejected
+	long := offlineAgentYAML + "readme: |\n" + strings.Repeat("  \"quoted\" \\ line\n", DefaultMaxJSONSize/20)
+	if int64(len(long)) > MaxYAMLFileSize {
+		t.Fatalf("test YAML is %d bytes, over MaxYAMLFileSize", len(long))
+	}
+	_, _, err := minter.loadConfig(context.Background(), writeAgentYAML(t, "agent.yaml", long))
+	if err == nil || !strings.Contains(err.Error(), "too large as JSON") {
+		t.Errorf("loadConfig() error = %v, want the JSON size limit", err)
+	}
+}
+
+func TestLoadConfig_InvalidYAML(t *testing.T) {
+	minter, _ := newOfflineSchemaMinter(t, "")
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"syntax error", "name: [unclosed\n"},
+		{"list document", "- name: Offline Agent\n"},
+		{"scalar document", "just a string\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := minter.loadConfig(context.Background(), writeAgentYAML(t, "agent.yml", tt.content))
+			if err == nil || !strings.Contains(err.Error(), "invalid YAML") {
+				t.Errorf("loadConfig() error = %v, want an invalid YAML error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"time"
+)
+
+// Defaults for retrying a failed confirm-mint within the mint and deploy flows
+const (
+	DefaultConfirmRetries      = 3
+	DefaultConfirmRetryBackoff = 2 * time.Second
+)
+
+// IsRetriableConfirmError reports whether a confirm-mint failure is transient:
+// a 5xx response, a rate limit or a request that never got a response.
+// Validation, ownership and other rejections are permanent.
+func IsRetriableConfirmError(err error) bool {
+	if errors.Is(err, ErrServerError) || errors.Is(err, ErrServiceUnavailable) || errors.Is(err, ErrRateLimited) {
+		return true
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && !errors.Is(err, context.Canceled)
+}
+
+// retryConfirm calls confirm until it succeeds or fails permanently, retrying
+// transient failures up to retries times with a backoff that doubles after each
+// attempt. It returns the last error; the caller keeps its WAL or deploy state
+// so Reconcile or the next run can still confirm the mint.
+func retryConfirm(ctx context.Context, retries int, backoff time.Duration, confirm func() error) error {
+	err := confirm()
+	for attempt := 1; err != nil && attempt <= retries && IsRetriableConfirmError(err); attempt++ {
+		log.Printf("⚠️ %v, retrying confirm-mint in %s (%d/%d)...", err, backoff, attempt, retries)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		err = confirm()
+	}
+	return err
+}
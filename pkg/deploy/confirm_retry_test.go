@@ -0,0 +1,186 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestIsRetriableConfirmError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"server error", fmt.Errorf("confirm-mint %w: database timeout", ErrServerError), true},
+		{"unavailable", fmt.Errorf("confirm-mint %w", ErrServiceUnavailable), true},
+		{"rate limited", fmt.Errorf("%w, please wait and retry", ErrRateLimited), true},
+		{"no response", fmt.Errorf("failed to call confirm-mint endpoint: %w", &url.Error{Op: "Post", Err: errors.New("connection reset")}), true},
+		{"canceled", fmt.Errorf("failed to call confirm-mint endpoint: %w", &url.Error{Op: "Post", Err: context.Canceled}), false},
+		{"validation", errors.New("confirm-mint failed: invalid tx_hash"), false},
+		{"session expired", ErrSessionExpired, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetriableConfirmError(tt.err); got != tt.want {
+				t.Errorf("IsRetriableConfirmError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMinter_ConfirmRetriesTransientFailures(t *testing.T) {
+	backend := newUnconfirmedBackend(t, 2)
+	minter, chain := newFakeChainMinter(t, backend.URL)
+	txHash := "0x" + strings.Repeat("ab", 32)
+	chain.setReceipt(txHash, types.ReceiptStatusSuccessful, 12)
+
+	wal := pendingMintWAL(chain.GetAddress(), txHash)
+	minter.walClient.Save(wal)
+
+	result, err := minter.recoverFromWAL(context.Background(), wal, &AgentConfig{AgentID: "fake-agent"})
+	if err != nil {
+		t.Fatalf("recoverFromWAL() error = %v", err)
+	}
+	if result.TokenID != 12 {
+		t.Errorf("TokenID = %d, want 12", result.TokenID)
+	}
+
+	backend.mu.Lock()
+	keys := append([]string(nil), backend.idempotencyKeys...)
+	backend.mu.Unlock()
+	if len(keys) != 3 || keys[0] != keys[2] {
+		t.Errorf("%s headers = %q, want the same key on 3 attempts", IdempotencyKeyHeader, keys)
+	}
+	if len(backend.confirmed()) != 1 {
+		t.Errorf("confirmed %d times, want 1", len(backend.confirmed()))
+	}
+	if minter.walClient.Exists("fake-agent") {
+		t.Error("WAL should be deleted once a retry confirms the mint")
+	}
+}
+
+func TestMinter_ConfirmDoesNotRetryPermanentFailures(t *testing.T) {
+	var confirms int
+	backend := newFakeBackend(t, map[string]http.HandlerFunc{
+		"/api/sdk/agent/confirm-mint": func(w http.ResponseWriter, r *http.Request) {
+			confirms++
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "tx_hash does not match the reservation"})
+		},
+	})
+	minter, chain := newFakeChainMinter(t, backend.URL)
+	txHash := "0x" + strings.Repeat("ab", 32)
+	chain.setReceipt(txHash, types.ReceiptStatusSuccessful, 12)
+
+	wal := pendingMintWAL(chain.GetAddress(), txHash)
+	minter.walClient.Save(wal)
+
+	if _, err := minter.recoverFromWAL(context.Background(), wal, &AgentConfig{AgentID: "fake-agent"}); err != nil {
+		t.Fatalf("recoverFromWAL() error = %v", err)
+	}
+	if confirms != 1 {
+		t.Errorf("confirm-mint called %d times, want 1 for a validation error", confirms)
+	}
+	if !minter.walClient.Exists("fake-agent") {
+		t.Error("WAL should be kept so Reconcile can confirm later")
+	}
+}
+
+func TestMinter_ConfirmRetriesStopWhenContextDone(t *testing.T) {
+	backend := newUnconfirmedBackend(t, 100)
+	minter, _ := newFakeChainMinter(t, backend.URL)
+	minter.config.ConfirmRetryBackoff = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := minter.confirmMintedWithRetry(ctx, "test-session", "fake-agent", "0xwallet", 12, "0xminted", "hash")
+	if !errors.Is(err, ErrServerError) {
+		t.Errorf("confirmMintedWithRetry() error = %v, want the last server error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("confirmMintedWithRetry() took %s after the context was done", elapsed)
+	}
+}
+
+// newFakeChainDeployer creates a deployer left with a minted but unconfirmed
+// agent in its state file
+func newFakeChainDeployer(t *testing.T, backendURL string) *Deployer {
+	t.Helper()
+	signer, err := NewPrivateKeySigner(newTestPrivateKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := newFakeChainOps(signer.Address())
+	tokenID := uint64(21)
+	chain.ownedToken = &tokenID
+
+	deployer, err := NewDeployer(&DeployConfig{
+		BackendURL:          backendURL,
+		Signer:              signer,
+		AgentID:             "fake-agent",
+		AgentName:           "Fake Agent",
+		StateFilePath:       filepath.Join(t.TempDir(), "state.json"),
+		ChainFactory:        chain.factory(),
+		ConfirmRetryBackoff: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewDeployer() error = %v", err)
+	}
+	deployer.stateManager.Save(&DeployState{
+		AgentID:         "fake-agent",
+		WalletAddress:   chain.GetAddress(),
+		TokenID:         tokenID,
+		TxHash:          "0xminted",
+		ContractAddress: testContractAddress,
+		ChainID:         "3338",
+		Status:          StatusMinted,
+	})
+	return deployer
+}
+
+func TestDeployer_ConfirmRetriesTransientFailures(t *testing.T) {
+	backend := newUnconfirmedBackend(t, DefaultConfirmRetries)
+	deployer := newFakeChainDeployer(t, backend.URL)
+
+	result, err := deployer.Deploy(context.Background())
+	if err != nil {
+		t.Fatalf("Deploy() error = %v", err)
+	}
+	if result.TokenID != 21 {
+		t.Errorf("TokenID = %d, want 21", result.TokenID)
+	}
+	if state, _ := deployer.stateManager.Load(); state == nil || state.Status != StatusConfirmed {
+		t.Errorf("state = %+v, want confirmed", state)
+	}
+}
+
+func TestDeployer_ConfirmGivesUpAndKeepsState(t *testing.T) {
+	backend := newUnconfirmedBackend(t, 1+DefaultConfirmRetries)
+	deployer := newFakeChainDeployer(t, backend.URL)
+
+	if _, err := deployer.Deploy(context.Background()); !errors.Is(err, ErrServerError) {
+		t.Fatalf("Deploy() error = %v, want ErrServerError", err)
+	}
+	if state, _ := deployer.stateManager.Load(); state == nil || state.Status != StatusMinted {
+		t.Fatalf("state = %+v, want minted so the next Deploy confirms", state)
+	}
+
+	// The backend has recovered: the next run only confirms
+	result, err := deployer.Deploy(context.Background())
+	if err != nil {
+		t.Fatalf("second Deploy() error = %v", err)
+	}
+	if result.TokenID != 21 || len(backend.confirmed()) != 1 {
+		t.Errorf("second Deploy() = %+v with %d confirms, want token 21 confirmed once", result, len(backend.confirmed()))
+	}
+}
@@ -0,0 +1,26 @@
+package deploy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrContractMismatch is returned when the backend points the SDK at a
+// contract or chain other than the expected one
+var ErrContractMismatch = errors.New("backend contract does not match the expected contract")
+
+// checkExpectedContract fails with ErrContractMismatch unless contractAddress
+// and chainID, as recorded by source (the backend, a WAL entry or a state
+// file), match the expected ones. An empty expectation is not checked.
+func checkExpectedContract(source, expectedContract, expectedChainID, contractAddress, chainID string) error {
+	if expectedContract != "" && common.HexToAddress(expectedContract) != common.HexToAddress(contractAddress) {
+		return fmt.Errorf("%w: %s reports contract %s, expected %s", ErrContractMismatch, source, contractAddress, expectedContract)
+	}
+	if expectedChainID != "" && strings.TrimSpace(expectedChainID) != strings.TrimSpace(chainID) {
+		return fmt.Errorf("%w: %s reports chain %s, expected %s", ErrContractMismatch, source, chainID, expectedChainID)
+	}
+	return nil
+}
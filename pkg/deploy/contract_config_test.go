@@ -0,0 +1,233 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCheckExpectedContract(t *testing.T) {
+	tests := []struct {
+		name             string
+		expectedContract string
+		expectedChainID  string
+		wantErr          bool
+	}{
+		{"no expectations", "", "", false},
+		{"matching", testContractAddress, "3338", false},
+		{"matching other case", "0x00000000000000000000000000000000000000C0", "", false},
+		{"other contract", "0x00000000000000000000000000000000000000c1", "3338", true},
+		{"other chain", testContractAddress, "9990", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkExpectedContract("backend", tt.expectedContract, tt.expectedChainID, testContractAddress, "3338")
+			if tt.wantErr != errors.Is(err, ErrContractMismatch) {
+				t.Errorf("checkExpectedContract() error = %v, want mismatch %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHTTPClient_CachesContractConfig(t *testing.T) {
+	var calls int
+	backend := newFakeBackend(t, map[string]http.HandlerFunc{
+		"/api/contract/config": func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				writeJSON(w, http.StatusBadGateway, ErrorResponse{Error: "upstream down"})
+				return
+			}
+			writeJSON(w, http.StatusOK, ContractConfigResponse{ContractAddress: testContractAddress, ChainID: "3338"})
+		},
+	})
+	client := NewHTTPClient(backend.URL)
+
+	if _, err := client.GetContractConfig(); err == nil {
+		t.Fatal("GetContractConfig() succeeded, want the backend error")
+	}
+	for i := 0; i < 2; i++ {
+		config, err := client.GetContractConfig()
+		if err != nil {
+			t.Fatalf("GetContractConfig() error = %v", err)
+		}
+		if config.ContractAddress != testContractAddress || config.ChainID != "3338" {
+			t.Errorf("GetContractConfig() = %+v", config)
+		}
+		config.ChainID = "changed by caller"
+	}
+	if calls != 2 {
+		t.Errorf("contract config fetched %d times, want 2: failures are not cached", calls)
+	}
+
+	client.InvalidateContractConfig()
+	client.GetContractConfig()
+	client.contractMu.Lock()
+	client.contractFetchedAt = time.Now().Add(-ContractConfigCacheTTL)
+	client.contractMu.Unlock()
+	client.GetContractConfig()
+	if calls != 4 {
+		t.Errorf("contract config fetched %d times, want 4: invalidated and expired configs are fetched again", calls)
+	}
+}
+
+func TestMinter_CheckBalanceRejectsUnexpectedContract(t *testing.T) {
+	chain := newBalanceChain(t, peaq(t, "5"), peaq(t, "2"))
+
+	tests := []struct {
+		name             string
+		expectedContract string
+		expectedChainID  string
+		wantErr          bool
+	}{
+		{"matching", testContractAddress, "3338", false},
+		{"other contract", "0x00000000000000000000000000000000000000c1", "", true},
+		{"other chain", "", "9990", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minter := newBalanceTestMinter(t, chain.URL, "3338", nil)
+			minter.config.ExpectedContract = tt.expectedContract
+			minter.config.ExpectedChainID = tt.expectedChainID
+
+			_, err := minter.CheckBalance(context.Background())
+			if tt.wantErr != errors.Is(err, ErrContractMismatch) {
+				t.Errorf("CheckBalance() error = %v, want mismatch %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDeployer_RejectsUnexpectedChain(t *testing.T) {
+	backend := newFakeBackend(t, map[string]http.HandlerFunc{
+		"/api/sdk/agent/deploy": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, DeployResponse{
+				Signature:       "0x01",
+				ContractAddress: testContractAddress,
+				ChainID:         "9990",
+				ConfigHash:      "hash",
+			})
+		},
+	})
+	signer, err := NewPrivateKeySigner(newTestPrivateKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := newFakeChainOps(signer.Address())
+
+	deployer, err := NewDeployer(&DeployConfig{
+		BackendURL:       backend.URL,
+		Signer:           signer,
+		AgentID:          "wrong-chain-agent",
+		AgentName:        "Wrong Chain Agent",
+		Description:      "Deployed against a misconfigured backend",
+		AgentType:        "command",
+		StateFilePath:    filepath.Join(t.TempDir(), "state.json"),
+		ChainFactory:     chain.factory(),
+		ExpectedContract: testContractAddress,
+		ExpectedChainID:  "3338",
+	})
+	if err != nil {
+		t.Fatalf("NewDeployer() error = %v", err)
+	}
+
+	if _, err := deployer.Deploy(context.Background()); !errors.Is(err, ErrContractMismatch) {
+		t.Fatalf("Deploy() error = %v, want ErrContractMismatch", err)
+	}
+	if chain.mints != 0 {
+		t.Errorf("ExecuteMint called %d times, want none", chain.mints)
+	}
+}
+
+func TestMinter_RecoveryRejectsUnexpectedContract(t *testing.T) {
+	walEntry := func(signer *PrivateKeySigner) *WALEntry {
+		return &WALEntry{
+			AgentID:         "moved-agent",
+			Wallet:          signer.Address().Hex(),
+			State:           WALStateMinting,
+			PendingTxHash:   "0xmint",
+			ContractAddress: testContractAddress,
+			ChainID:         "3338",
+		}
+	}
+
+	t.Run("WAL recovery", func(t *testing.T) {
+		backend := newUnconfirmedBackend(t, 0)
+		minter, signer := newReconcileMinter(t, backend.URL, "")
+		minter.config.ExpectedChainID = "9990"
+		chain := newFakeChainOps(signer.Address())
+		chain.setReceipt("0xmint", 1, 17)
+		minter.newChain = chain.factory()
+
+		_, err := minter.recoverFromWAL(context.Background(), walEntry(signer), &AgentConfig{AgentID: "moved-agent"})
+		if !errors.Is(err, ErrContractMismatch) {
+			t.Errorf("recoverFromWAL() error = %v, want ErrContractMismatch", err)
+		}
+		if n := len(backend.confirmed()); n != 0 {
+			t.Errorf("expected no confirm-mint, got %d", n)
+		}
+	})
+
+	t.Run("reconcile", func(t *testing.T) {
+		backend := newUnconfirmedBackend(t, 0)
+		minter, signer := newReconcileMinter(t, backend.URL, "")
+		minter.config.ExpectedContract = "0x00000000000000000000000000000000000000c1"
+		chain := newFakeChainOps(signer.Address())
+		chain.owners[17] = signer.Address()
+		chain.setReceipt("0xmint", 1, 17)
+		minter.newChain = chain.factory()
+		minter.walClient.Save(walEntry(signer))
+
+		if _, err := minter.Reconcile(context.Background(), "moved-agent"); !errors.Is(err, ErrContractMismatch) {
+			t.Errorf("Reconcile() error = %v, want ErrContractMismatch", err)
+		}
+		if n := len(backend.confirmed()); n != 0 {
+			t.Errorf("expected no confirm-mint, got %d", n)
+		}
+	})
+}
+
+func TestDeployer_RecoveryRejectsUnexpectedContract(t *testing.T) {
+	backend := newFakeBackend(t, map[string]http.HandlerFunc{})
+	signer, err := NewPrivateKeySigner(newTestPrivateKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := newFakeChainOps(signer.Address())
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	if err := NewStateManager(statePath).Save(&DeployState{
+		AgentID:         "moved-agent",
+		WalletAddress:   signer.Address().Hex(),
+		ContractAddress: testContractAddress,
+		ChainID:         "3338",
+		Status:          StatusMinted,
+		TokenID:         17,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	deployer, err := NewDeployer(&DeployConfig{
+		BackendURL:      backend.URL,
+		Signer:          signer,
+		AgentID:         "moved-agent",
+		AgentName:       "Moved Agent",
+		Description:     "Recovered against a misconfigured state file",
+		AgentType:       "command",
+		StateFilePath:   statePath,
+		ChainFactory:    chain.factory(),
+		ExpectedChainID: "9990",
+	})
+	if err != nil {
+		t.Fatalf("NewDeployer() error = %v", err)
+	}
+
+	if _, err := deployer.Deploy(context.Background()); !errors.Is(err, ErrContractMismatch) {
+		t.Fatalf("Deploy() error = %v, want ErrContractMismatch", err)
+	}
+}
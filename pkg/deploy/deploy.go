@@ -2,19 +2,19 @@ package deploy
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"math"
 	"math/big"
+	"net/http"
 	"os"
-	"sort"
 	"strconv"
 	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 // DeployConfig contains all configuration for deploying an agent
@@ -22,12 +22,15 @@ type DeployConfig struct {
 	// Backend Configuration
 	BackendURL  string // Backend URL (default: from BACKEND_URL env or http://localhost:8080)
 	RPCEndpoint string // Ethereum RPC endpoint (default: from RPC_ENDPOINT env)
+	HTTPClient  *http.Client // Client for backend requests (default: 60s timeout)
 
 	// Wallet Configuration
-	PrivateKey string // Private key (hex, with or without 0x prefix)
+	PrivateKey string // Private key (hex, with or without 0x prefix), not needed when Signer is set
+	Signer     Signer // Signs challenges and transactions in place of PrivateKey (e.g. hardware wallet, KMS)
+	EIP712Auth bool   // Sign auth challenges as EIP-712 typed data instead of personal_sign
 
 	// Agent Configuration
-	AgentID      string          // Unique agent identifier (lowercase, hyphens allowed)
+	AgentID      string          // Unique agent identifier (lowercase, hyphens allowed), see GenerateAgentID
 	AgentName    string          // Display name for the agent
 	Description  string          // Agent description
 	Image        string          // Image URL or base64 data
@@ -38,13 +41,47 @@ type DeployConfig struct {
 	Categories      json.RawMessage // Agent categories (optional)
 	MetadataVersion string          // Metadata version (e.g. "2.3.0")
 
+	// Agent ID Conflicts (meant for IDs derived with GenerateAgentID; the ID deployed is in DeployResult.AgentID)
+	SuffixAgentIDOnConflict bool // Deploy as SuffixAgentID(AgentID, wallet) when another wallet holds AgentID
+
 	// State Management
 	StateFilePath string // Path to state file (default: .teneo-deploy-state.json)
 
 	// Advanced Options
-	MintPrice *big.Int // Custom mint price (default: 2 PEAQ)
+	MintPrice           *big.Int      // Mint price override (default: contract mintPrice(), then DefaultMintPrice)
+	VerifyMintSignature bool          // Check the mint signature against the contract's signer() first, see MintConfig.VerifyMintSignature
+	ReceiptTimeout      time.Duration // How long to wait for the mint receipt (default: DefaultReceiptTimeout)
+	ReceiptPollInterval time.Duration // How often to poll for the mint receipt (default: DefaultReceiptPollInterval)
+
+	// Confirm Retries (the state file is kept after a final failure so the next Deploy confirms)
+	ConfirmRetries      int           // Retries of a transient confirm-mint failure (default: DefaultConfirmRetries, negative disables)
+	ConfirmRetryBackoff time.Duration // Wait before the first retry, doubled after each (default: DefaultConfirmRetryBackoff)
+
+	// Contract Validation (the deploy fails with ErrContractMismatch before minting or recovering if the backend or state file reports another contract or chain)
+	ExpectedContract string // NFT contract address the backend must report (optional)
+	ExpectedChainID  string // Chain ID the backend must report (optional)
+
+	// Tracing
+	TracerProvider trace.TracerProvider // Traces each deploy phase and backend request (default: no-op)
+
+	// Progress Reporting
+	OnProgress func(step DeployStep, detail string) // Called at each deploy transition (optional)
+
+	// Testing
+	ChainFactory ChainFactory // Connects to the NFT contract (default: NewChainOps); tests can return a fake ChainOps
 }
 
+// DeployStep identifies a transition in the deployment flow
+type DeployStep string
+
+const (
+	DeployStepAuthenticating DeployStep = "authenticating"  // Signing in to the backend
+	DeployStepDeployPrepared DeployStep = "deploy_prepared" // Metadata stored, mint signature received
+	DeployStepMintSent       DeployStep = "mint_sent"       // Mint transaction broadcast
+	DeployStepMintConfirmed  DeployStep = "mint_confirmed"  // Mint transaction mined
+	DeployStepConfirmedInDB  DeployStep = "confirmed_in_db" // Agent saved to the database
+)
+
 // DeployResult contains the result of a successful deployment
 type DeployResult struct {
 	TokenID         uint64 `json:"token_id"`
@@ -60,10 +97,13 @@ type DeployResult struct {
 type Deployer struct {
 	config       *DeployConfig
 	httpClient   *HTTPClient
-	chainClient  *ChainClient
+	newChain     ChainFactory
 	authenticator *Authenticator
+	signer       Signer
 	stateManager *StateManager
 	configHash   string
+	hashVersion  string // backend schema version configHash is computed for
+	tracer       trace.Tracer
 }
 
 // NewDeployer creates a new deployer instance
@@ -85,7 +125,7 @@ func NewDeployer(config *DeployConfig) (*Deployer, error) {
 		}
 	}
 
-	if config.PrivateKey == "" {
+	if config.PrivateKey == "" && config.Signer == nil {
 		if privateKey := os.Getenv("PRIVATE_KEY"); privateKey != "" {
 			config.PrivateKey = privateKey
 		} else {
@@ -101,33 +141,74 @@ func NewDeployer(config *DeployConfig) (*Deployer, error) {
 		config.MetadataVersion = "2.3.0"
 	}
 
+	image, err := resolveImage(strings.TrimSpace(config.Image), "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid image: %w", err)
+	}
+	config.Image = image
+
+	if config.ReceiptTimeout == 0 {
+		config.ReceiptTimeout = DefaultReceiptTimeout
+	}
+	if config.ReceiptPollInterval == 0 {
+		config.ReceiptPollInterval = DefaultReceiptPollInterval
+	}
+
+	if config.ConfirmRetries == 0 {
+		config.ConfirmRetries = DefaultConfirmRetries
+	}
+	if config.ConfirmRetryBackoff == 0 {
+		config.ConfirmRetryBackoff = DefaultConfirmRetryBackoff
+	}
+
 	// Create HTTP client
-	httpClient := NewHTTPClient(config.BackendURL)
+	httpClient := NewHTTPClient(config.BackendURL, WithHTTPClient(config.HTTPClient), WithTracerProvider(config.TracerProvider))
 
 	// Create authenticator
-	authenticator, err := NewAuthenticator(config.PrivateKey, httpClient)
+	signer, err := resolveSigner(config.Signer, config.PrivateKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create authenticator: %w", err)
 	}
+	authenticator := NewAuthenticatorWithSigner(signer, httpClient, WithEIP712Signing(config.EIP712Auth))
 
 	// Create state manager
 	stateManager := NewStateManager(config.StateFilePath)
 
 	// Compute config hash matching GenerateConfigHash logic
-	configHash := computeConfigHash(config)
+	configHash := computeConfigHash(config, HashOptions{})
+
+	newChain := config.ChainFactory
+	if newChain == nil {
+		newChain = NewChainOps
+	}
 
 	return &Deployer{
 		config:       config,
 		httpClient:   httpClient,
 		authenticator: authenticator,
+		signer:       signer,
+		newChain:     newChain,
 		stateManager: stateManager,
 		configHash:   configHash,
+		tracer:       newTracer(config.TracerProvider),
 	}, nil
 }
 
 // Deploy executes the full deployment flow with resilience and idempotency
 func (d *Deployer) Deploy(ctx context.Context) (*DeployResult, error) {
+	ctx, span := d.tracer.Start(ctx, SpanDeploy, trace.WithAttributes(AttrAgentID.String(d.config.AgentID)))
+	result, err := d.deploy(ctx)
+	if result != nil {
+		span.SetAttributes(AttrTxHash.String(result.TxHash), tokenIDAttr(result.TokenID), AttrContractAddress.String(result.ContractAddress))
+	}
+	endSpan(span, err)
+	return result, err
+}
+
+// deploy runs Deploy inside its span
+func (d *Deployer) deploy(ctx context.Context) (*DeployResult, error) {
 	log.Println("🚀 Starting agent deployment...")
+	d.useBackendHashVersion(ctx)
 
 	// Load existing state
 	state, err := d.stateManager.Load()
@@ -137,13 +218,17 @@ func (d *Deployer) Deploy(ctx context.Context) (*DeployResult, error) {
 
 	// Create chain client for on-chain checks
 	// We need contract info first, so we'll create it later if needed
-	var chainClient *ChainClient
+	var chainClient ChainOps
 
 	// Check if we need to recover from partial deployment
 	if state != nil {
 		log.Printf("📋 Found existing state: status=%s, agentID=%s", state.Status, state.AgentID)
 
-		// Verify agent ID matches
+		// Verify agent ID matches; a deploy that moved to the suffixed ID
+		// resumes under it
+		if d.config.SuffixAgentIDOnConflict && state.AgentID == d.suffixedAgentID() {
+			d.useAgentID(state.AgentID)
+		}
 		if state.AgentID != d.config.AgentID {
 			log.Printf("⚠️ State file is for different agent (%s vs %s), starting fresh", state.AgentID, d.config.AgentID)
 			state = nil
@@ -152,7 +237,10 @@ func (d *Deployer) Deploy(ctx context.Context) (*DeployResult, error) {
 
 	// Handle recovery scenarios
 	if state != nil && state.ContractAddress != "" {
-		chainClient, err = NewChainClient(d.config.RPCEndpoint, state.ContractAddress, state.ChainID, d.config.PrivateKey)
+		if err := checkExpectedContract("state file", d.config.ExpectedContract, d.config.ExpectedChainID, state.ContractAddress, state.ChainID); err != nil {
+			return nil, err
+		}
+		chainClient, err = d.newChain(d.config.RPCEndpoint, state.ContractAddress, state.ChainID, d.signer)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create chain client: %w", err)
 		}
@@ -206,6 +294,13 @@ func (d *Deployer) Deploy(ctx context.Context) (*DeployResult, error) {
 	return d.fullDeploy(ctx)
 }
 
+// progress reports a deploy step to the configured callback, if any
+func (d *Deployer) progress(step DeployStep, detail string) {
+	if d.config.OnProgress != nil {
+		d.config.OnProgress(step, detail)
+	}
+}
+
 // fullDeploy executes a complete deployment from scratch
 func (d *Deployer) fullDeploy(ctx context.Context) (*DeployResult, error) {
 	// Validate configuration
@@ -215,6 +310,7 @@ func (d *Deployer) fullDeploy(ctx context.Context) (*DeployResult, error) {
 
 	// Step 1: Authenticate
 	log.Println("[Step 1/5] 🔐 Authenticating with backend...")
+	d.progress(DeployStepAuthenticating, d.authenticator.GetAddress())
 	sessionToken, sessionExpiry, err := d.authenticate(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("authentication failed: %w", err)
@@ -224,6 +320,9 @@ func (d *Deployer) fullDeploy(ctx context.Context) (*DeployResult, error) {
 	// Step 2: Call deploy endpoint
 	log.Println("[Step 2/5] 📤 Preparing deployment (uploading metadata, getting signature)...")
 	deployResp, err := d.callDeploy(ctx, sessionToken)
+	if err != nil && errors.Is(err, ErrAgentIDTaken) && d.config.SuffixAgentIDOnConflict {
+		deployResp, err = d.deployUnderSuffixedID(ctx, sessionToken, err)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("deploy preparation failed: %w", err)
 	}
@@ -233,6 +332,10 @@ func (d *Deployer) fullDeploy(ctx context.Context) (*DeployResult, error) {
 		log.Printf("   ✅ Metadata stored, config hash: %s", deployResp.ConfigHash)
 	}
 	log.Printf("   ✅ Contract: %s (Chain ID: %s)", deployResp.ContractAddress, deployResp.ChainID)
+	if err := checkExpectedContract("backend", d.config.ExpectedContract, d.config.ExpectedChainID, deployResp.ContractAddress, deployResp.ChainID); err != nil {
+		return nil, err
+	}
+	d.progress(DeployStepDeployPrepared, deployResp.ContractAddress)
 
 	// Use RPC URL from backend response, fallback to config/env/default
 	rpcEndpoint := deployResp.RPCURL
@@ -262,17 +365,33 @@ func (d *Deployer) fullDeploy(ctx context.Context) (*DeployResult, error) {
 
 	// Step 3: Execute on-chain mint
 	log.Println("[Step 3/5] ⛓️  Executing on-chain mint transaction...")
-	chainClient, err := NewChainClient(rpcEndpoint, deployResp.ContractAddress, deployResp.ChainID, d.config.PrivateKey)
+	chainClient, err := d.newChain(rpcEndpoint, deployResp.ContractAddress, deployResp.ChainID, d.signer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create chain client: %w", err)
 	}
 	defer chainClient.Close()
+	chainClient.SetReceiptWait(d.config.ReceiptTimeout, d.config.ReceiptPollInterval)
+	chainClient.SetOnTxSent(func(txHash string, _ uint64) {
+		d.progress(DeployStepMintSent, txHash)
+	})
+
+	if d.config.VerifyMintSignature {
+		if err := chainClient.VerifyMintSignature(ctx, deployResp.Signature, deployResp.Nonce); err != nil {
+			return nil, fmt.Errorf("refusing to mint: %w", err)
+		}
+	}
 
-	mintResult, err := chainClient.ExecuteMint(ctx, deployResp.Signature, d.config.MintPrice)
+	mintCtx, mintSpan := d.tracer.Start(ctx, SpanOnChainMint, trace.WithAttributes(AttrContractAddress.String(deployResp.ContractAddress)))
+	mintResult, err := chainClient.ExecuteMint(mintCtx, deployResp.Signature, d.config.MintPrice)
+	if err == nil {
+		mintSpan.SetAttributes(AttrTxHash.String(mintResult.TxHash), tokenIDAttr(mintResult.TokenID))
+	}
+	endSpan(mintSpan, err)
 	if err != nil {
 		return nil, fmt.Errorf("on-chain mint failed: %w", err)
 	}
 	log.Printf("   ✅ Mint successful! Token ID: %d, Tx: %s", mintResult.TokenID, mintResult.TxHash)
+	d.progress(DeployStepMintConfirmed, strconv.FormatUint(mintResult.TokenID, 10))
 
 	// Update state to minted
 	state.TokenID = mintResult.TokenID
@@ -284,7 +403,7 @@ func (d *Deployer) fullDeploy(ctx context.Context) (*DeployResult, error) {
 
 	// Step 4: Confirm mint with backend
 	log.Println("[Step 4/5] 💾 Confirming with backend (saving to database)...")
-	confirmResp, err := d.confirmMint(ctx, sessionToken, state)
+	confirmResp, err := d.confirmMintWithRetry(ctx, sessionToken, state)
 	if err != nil {
 		// If session expired, re-authenticate and retry
 		if errors.Is(err, ErrSessionExpired) {
@@ -297,7 +416,7 @@ func (d *Deployer) fullDeploy(ctx context.Context) (*DeployResult, error) {
 			state.SessionExpiry = sessionExpiry
 			d.stateManager.Save(state)
 
-			confirmResp, err = d.confirmMint(ctx, sessionToken, state)
+			confirmResp, err = d.confirmMintWithRetry(ctx, sessionToken, state)
 			if err != nil {
 				return nil, fmt.Errorf("confirm-mint failed after re-auth: %w", err)
 			}
@@ -306,6 +425,7 @@ func (d *Deployer) fullDeploy(ctx context.Context) (*DeployResult, error) {
 		}
 	}
 	log.Printf("   ✅ Agent saved to database: %s", confirmResp.ID)
+	d.progress(DeployStepConfirmedInDB, confirmResp.ID)
 
 	// Update state to confirmed
 	state.Status = StatusConfirmed
@@ -332,6 +452,7 @@ func (d *Deployer) confirmOnly(ctx context.Context, state *DeployState) (*Deploy
 	sessionToken := state.SessionToken
 	if !state.IsSessionValid() {
 		log.Println("🔐 Re-authenticating...")
+		d.progress(DeployStepAuthenticating, d.authenticator.GetAddress())
 		var err error
 		sessionToken, state.SessionExpiry, err = d.authenticate(ctx)
 		if err != nil {
@@ -342,7 +463,7 @@ func (d *Deployer) confirmOnly(ctx context.Context, state *DeployState) (*Deploy
 	}
 
 	log.Println("[Confirm] 💾 Confirming with backend...")
-	confirmResp, err := d.confirmMint(ctx, sessionToken, state)
+	confirmResp, err := d.confirmMintWithRetry(ctx, sessionToken, state)
 	if err != nil {
 		if errors.Is(err, ErrSessionExpired) {
 			log.Println("   ⚠️ Session expired, re-authenticating...")
@@ -353,7 +474,7 @@ func (d *Deployer) confirmOnly(ctx context.Context, state *DeployState) (*Deploy
 			state.SessionToken = sessionToken
 			d.stateManager.Save(state)
 
-			confirmResp, err = d.confirmMint(ctx, sessionToken, state)
+			confirmResp, err = d.confirmMintWithRetry(ctx, sessionToken, state)
 			if err != nil {
 				return nil, fmt.Errorf("confirm-mint failed: %w", err)
 			}
@@ -366,6 +487,7 @@ func (d *Deployer) confirmOnly(ctx context.Context, state *DeployState) (*Deploy
 	d.stateManager.Save(state)
 
 	log.Println("✅ Agent confirmed successfully!")
+	d.progress(DeployStepConfirmedInDB, confirmResp.ID)
 
 	return &DeployResult{
 		TokenID:         state.TokenID,
@@ -380,7 +502,10 @@ func (d *Deployer) confirmOnly(ctx context.Context, state *DeployState) (*Deploy
 
 // authenticate performs the challenge-response authentication
 func (d *Deployer) authenticate(ctx context.Context) (string, int64, error) {
-	return d.authenticator.Authenticate()
+	ctx, span := d.tracer.Start(ctx, SpanAuth)
+	sessionToken, expiry, err := d.authenticator.AuthenticateCtx(ctx)
+	endSpan(span, err)
+	return sessionToken, expiry, err
 }
 
 // callDeploy calls the deploy endpoint
@@ -400,7 +525,57 @@ func (d *Deployer) callDeploy(ctx context.Context, sessionToken string) (*Deploy
 		MetadataVersion: d.config.MetadataVersion,
 	}
 
-	return d.httpClient.Deploy(sessionToken, req)
+	ctx, span := d.tracer.Start(ctx, SpanDeployCall)
+	resp, err := d.httpClient.DeployCtx(ctx, sessionToken, req)
+	if err == nil {
+		span.SetAttributes(AttrContractAddress.String(resp.ContractAddress))
+	}
+	endSpan(span, err)
+	return resp, err
+}
+
+// suffixedAgentID is the ID SuffixAgentIDOnConflict deploys under when the
+// configured one is taken
+func (d *Deployer) suffixedAgentID() string {
+	return SuffixAgentID(d.config.AgentID, d.authenticator.GetAddress())
+}
+
+// useAgentID switches the deployment to another agent ID, whose config hash
+// differs as the ID is part of it
+func (d *Deployer) useAgentID(agentID string) {
+	d.config.AgentID = agentID
+	d.configHash = computeConfigHash(d.config, HashOptions{SchemaVersion: d.hashVersion})
+}
+
+// useBackendHashVersion recomputes the config hash in the version the
+// backend's schema asks for. If the schema cannot be fetched the default
+// version is kept, as older backends expect.
+func (d *Deployer) useBackendHashVersion(ctx context.Context) {
+	schema, err := d.httpClient.GetSchemaCtx(ctx)
+	if err != nil {
+		log.Printf("⚠️ Warning: Failed to fetch schema: %v (using config hash %s)", err, DefaultConfigHashVersion)
+		return
+	}
+	d.hashVersion = schema.SchemaVersion
+	d.configHash = computeConfigHash(d.config, HashOptions{SchemaVersion: d.hashVersion})
+}
+
+// deployUnderSuffixedID retries a deploy rejected because the agent ID is
+// taken under suffixedAgentID, unless this wallet holds the ID itself: then
+// the deploy is a re-run that lost its state file and conflictErr is returned.
+func (d *Deployer) deployUnderSuffixedID(ctx context.Context, sessionToken string, conflictErr error) (*DeployResponse, error) {
+	_, err := d.httpClient.GetAgentStatusCtx(ctx, d.authenticator.GetAddress(), d.config.AgentID)
+	if err == nil {
+		return nil, fmt.Errorf("%w (this wallet already holds the agent)", conflictErr)
+	}
+	if !errors.Is(err, ErrAgentNotFound) {
+		return nil, fmt.Errorf("%w (ownership check failed: %w)", conflictErr, err)
+	}
+
+	taken := d.config.AgentID
+	d.useAgentID(d.suffixedAgentID())
+	log.Printf("   ⚠️ Agent ID %s is taken by another wallet, deploying as %s", taken, d.config.AgentID)
+	return d.callDeploy(ctx, sessionToken)
 }
 
 // confirmMint calls the confirm-mint endpoint.
@@ -429,7 +604,22 @@ func (d *Deployer) confirmMint(ctx context.Context, sessionToken string, state *
 		MetadataVersion: d.config.MetadataVersion,
 	}
 
-	return d.httpClient.ConfirmMint(sessionToken, req)
+	ctx, span := d.tracer.Start(ctx, SpanConfirm, trace.WithAttributes(AttrTxHash.String(state.TxHash), tokenIDAttr(state.TokenID)))
+	resp, err := d.httpClient.ConfirmMintCtx(ctx, sessionToken, req)
+	endSpan(span, err)
+	return resp, err
+}
+
+// confirmMintWithRetry is confirmMint with transient failures retried as
+// configured by ConfirmRetries
+func (d *Deployer) confirmMintWithRetry(ctx context.Context, sessionToken string, state *DeployState) (*ConfirmMintResponse, error) {
+	var resp *ConfirmMintResponse
+	err := retryConfirm(ctx, d.config.ConfirmRetries, d.config.ConfirmRetryBackoff, func() error {
+		var err error
+		resp, err = d.confirmMint(ctx, sessionToken, state)
+		return err
+	})
+	return resp, err
 }
 
 // validateConfig validates the deployment configuration
@@ -480,86 +670,32 @@ func DeployAgent(cfg DeployConfig) (*DeployResult, error) {
 	return deployer.Deploy(ctx)
 }
 
-// generateAgentID generates a valid agent ID from a name
-func generateAgentID(name string) string {
-	// Convert to lowercase and replace spaces with hyphens
-	id := strings.ToLower(name)
-	id = strings.ReplaceAll(id, " ", "-")
-
-	// Remove invalid characters
-	var result strings.Builder
-	for _, c := range id {
-		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' {
-			result.WriteRune(c)
-		}
-	}
-
-	// Remove leading/trailing hyphens and collapse multiple hyphens
-	id = result.String()
-	for strings.Contains(id, "--") {
-		id = strings.ReplaceAll(id, "--", "-")
-	}
-	id = strings.Trim(id, "-")
-
-	return id
+// computeConfigHash computes the config hash from DeployConfig by decoding
+// its JSON fields into an AgentConfig and hashing that, so it always matches
+// GenerateConfigHash in mint.go and the backend
+func computeConfigHash(config *DeployConfig, opts HashOptions) string {
+	return hashCanonical(strings.Join(canonicalHashParts(config.hashableConfig(), opts), "|"))
 }
 
-// computeConfigHash computes the config hash from DeployConfig, matching the
-// canonical format used by GenerateConfigHash in mint.go and the backend.
-func computeConfigHash(config *DeployConfig) string {
-	// Parse capability names from JSON and sort
-	type capObj struct {
-		Name string `json:"name"`
+// hashableConfig returns the AgentConfig view of the fields covered by the
+// config hash. Malformed JSON fields are treated as empty.
+func (config *DeployConfig) hashableConfig() *AgentConfig {
+	agentConfig := &AgentConfig{
+		AgentID:     config.AgentID,
+		Name:        config.AgentName,
+		Description: config.Description,
+		Image:       config.Image,
+		AgentType:   config.AgentType,
+		NlpFallback: config.NlpFallback,
 	}
-	var caps []capObj
 	if len(config.Capabilities) > 0 {
-		json.Unmarshal(config.Capabilities, &caps)
+		json.Unmarshal(config.Capabilities, &agentConfig.Capabilities)
 	}
-	capNames := make([]string, len(caps))
-	for i, c := range caps {
-		capNames[i] = c.Name
-	}
-	sort.Strings(capNames)
-
-	// Parse categories from JSON and sort
-	var categories []string
 	if len(config.Categories) > 0 {
-		json.Unmarshal(config.Categories, &categories)
-	}
-	sort.Strings(categories)
-
-	parts := []string{
-		"v3",
-		config.AgentID,
-		config.AgentName,
-		config.Description,
-		config.AgentType,
-		strings.Join(capNames, ","),
-		strconv.FormatBool(config.NlpFallback),
-		strings.Join(categories, ","),
+		json.Unmarshal(config.Categories, &agentConfig.Categories)
 	}
-
-	// Parse and include commands with prices (sorted by trigger)
-	type cmdObj struct {
-		Trigger      string  `json:"trigger"`
-		PricePerUnit float64 `json:"pricePerUnit"`
-	}
-	var cmds []cmdObj
 	if len(config.Commands) > 0 {
-		json.Unmarshal(config.Commands, &cmds)
-	}
-	if len(cmds) > 0 {
-		sort.Slice(cmds, func(i, j int) bool {
-			return cmds[i].Trigger < cmds[j].Trigger
-		})
-		cmdParts := make([]string, len(cmds))
-		for i, cmd := range cmds {
-			cmdParts[i] = cmd.Trigger + ":" + strconv.FormatFloat(cmd.PricePerUnit, 'f', -1, 64)
-		}
-		parts = append(parts, strings.Join(cmdParts, ","))
+		json.Unmarshal(config.Commands, &agentConfig.Commands)
 	}
-
-	data := strings.Join(parts, "|")
-	h := sha256.Sum256([]byte(data))
-	return hex.EncodeToString(h[:])
+	return agentConfig
 }
@@ -0,0 +1,186 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const testContractAddress = "0x00000000000000000000000000000000000000c0"
+
+// newFakeChain starts a JSON-RPC node that accepts any mint transaction and
+// returns a successful receipt with a Minted event for tokenID
+func newFakeChain(t *testing.T, tokenID int64) *httptest.Server {
+	t.Helper()
+
+	var sentTx common.Hash
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		var result interface{}
+		switch req.Method {
+		case "eth_getBalance":
+			result = "0x" + new(big.Int).Exp(big.NewInt(10), big.NewInt(20), nil).Text(16)
+		case "eth_getTransactionCount":
+			result = "0x0"
+		case "eth_gasPrice":
+			result = "0x3b9aca00"
+		case "eth_estimateGas":
+			result = "0x30d40"
+		case "eth_sendRawTransaction":
+			var raw hexutil.Bytes
+			json.Unmarshal(req.Params[0], &raw)
+			tx := new(types.Transaction)
+			tx.UnmarshalBinary(raw)
+			sentTx = tx.Hash()
+			result = sentTx.Hex()
+		case "eth_getTransactionReceipt":
+			mintedSig := crypto.Keccak256Hash([]byte("Minted(address,uint256)"))
+			result = &types.Receipt{
+				Status:            types.ReceiptStatusSuccessful,
+				CumulativeGasUsed: 21000,
+				GasUsed:           21000,
+				TxHash:            sentTx,
+				BlockNumber:       big.NewInt(1),
+				Logs: []*types.Log{{
+					Address: common.HexToAddress(testContractAddress),
+					Topics:  []common.Hash{mintedSig, {}, common.BigToHash(big.NewInt(tokenID))},
+					Data:    []byte{},
+					TxHash:  sentTx,
+				}},
+			}
+		default:
+			t.Errorf("unexpected RPC method %s", req.Method)
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestDeployer_ReportsProgressSteps(t *testing.T) {
+	chain := newFakeChain(t, 42)
+	backend := newFakeBackend(t, map[string]http.HandlerFunc{
+		"/api/sdk/agent/deploy": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, DeployResponse{
+				Signature:       "0x01",
+				ContractAddress: testContractAddress,
+				ChainID:         "3338",
+				RPCURL:          chain.URL,
+				ConfigHash:      "hash",
+			})
+		},
+		"/api/sdk/agent/confirm-mint": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, ConfirmMintResponse{Success: true, ID: "db-id-1"})
+		},
+	})
+
+	type event struct {
+		Step   DeployStep
+		Detail string
+	}
+	var events []event
+
+	deployer, err := NewDeployer(&DeployConfig{
+		BackendURL:    backend.URL,
+		RPCEndpoint:   chain.URL,
+		PrivateKey:    newTestPrivateKey(t),
+		AgentID:       "progress-agent",
+		AgentName:     "Progress Agent",
+		Description:   "Reports deploy progress",
+		AgentType:     "command",
+		StateFilePath: filepath.Join(t.TempDir(), "state.json"),
+		MintPrice:     big.NewInt(1),
+		OnProgress: func(step DeployStep, detail string) {
+			events = append(events, event{step, detail})
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewDeployer() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	result, err := deployer.Deploy(ctx)
+	if err != nil {
+		t.Fatalf("Deploy() error = %v", err)
+	}
+	if result.TokenID != 42 {
+		t.Errorf("expected token ID 42, got %d", result.TokenID)
+	}
+
+	steps := make([]DeployStep, len(events))
+	for i, e := range events {
+		steps[i] = e.Step
+	}
+	want := []DeployStep{
+		DeployStepAuthenticating,
+		DeployStepDeployPrepared,
+		DeployStepMintSent,
+		DeployStepMintConfirmed,
+		DeployStepConfirmedInDB,
+	}
+	if !reflect.DeepEqual(steps, want) {
+		t.Fatalf("steps = %v, want %v", steps, want)
+	}
+
+	if events[2].Detail != result.TxHash {
+		t.Errorf("mint-sent detail = %q, want tx hash %q", events[2].Detail, result.TxHash)
+	}
+	if events[3].Detail != "42" || events[4].Detail != "db-id-1" {
+		t.Errorf("unexpected step details: %+v", events)
+	}
+}
+
+func TestDeployer_HashesForBackendSchema(t *testing.T) {
+	var sentHash string
+	backend := newFakeBackend(t, map[string]http.HandlerFunc{
+		"/api/sdk/schema": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, SchemaResponse{SchemaVersion: "4"})
+		},
+		"/api/sdk/agent/deploy": func(w http.ResponseWriter, r *http.Request) {
+			var req DeployRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			sentHash = req.ConfigHash
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "stop here"})
+		},
+	})
+
+	config := &DeployConfig{
+		BackendURL:    backend.URL,
+		PrivateKey:    newTestPrivateKey(t),
+		AgentID:       "schema-agent",
+		AgentName:     "Schema Agent",
+		Description:   "Hashes for the backend schema",
+		AgentType:     "command",
+		StateFilePath: filepath.Join(t.TempDir(), "state.json"),
+	}
+	deployer, err := NewDeployer(config)
+	if err != nil {
+		t.Fatalf("NewDeployer() error = %v", err)
+	}
+	if _, err := deployer.Deploy(context.Background()); err == nil {
+		t.Fatal("expected the rejected deploy to fail")
+	}
+
+	if want := computeConfigHash(config, HashOptions{SchemaVersion: "4"}); sentHash != want {
+		t.Errorf("deploy config hash = %s, want the v4 hash %s", sentHash, want)
+	}
+}
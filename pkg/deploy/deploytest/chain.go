@@ -0,0 +1,189 @@
+package deploytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// MockChain is a JSON-RPC node that mines every transaction it receives at
+// once, with a receipt holding a Minted event for the next token ID. It
+// answers the calls deploy.ChainClient makes to check balances and mint; its
+// contract accepts mint signatures from MintSigner.
+type MockChain struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	balance    *big.Int
+	mintPrice  *big.Int
+	mintSigner common.Address
+	nextToken  int64
+	sent       []*types.Transaction
+	receipts   map[common.Hash]*types.Receipt
+}
+
+// NewMockChain starts a mock chain on which every wallet holds 100 tokens,
+// minting costs 1 token and the first minted token ID is 1. Close it when done.
+func NewMockChain() *MockChain {
+	ether := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	c := &MockChain{
+		balance:    new(big.Int).Mul(big.NewInt(100), ether),
+		mintPrice:  ether,
+		mintSigner: MintSigner,
+		nextToken:  1,
+		receipts:   make(map[common.Hash]*types.Receipt),
+	}
+	c.Server = httptest.NewServer(http.HandlerFunc(c.serveRPC))
+	return c
+}
+
+// SetBalance sets the balance reported for every wallet
+func (c *MockChain) SetBalance(balance *big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.balance = balance
+}
+
+// SetMintPrice sets the price returned by the contract's mintPrice()
+func (c *MockChain) SetMintPrice(price *big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mintPrice = price
+}
+
+// SetMintSigner sets the address returned by the contract's signer(), e.g.
+// to test mints with a signature from the wrong key
+func (c *MockChain) SetMintSigner(signer common.Address) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mintSigner = signer
+}
+
+// SetNextTokenID sets the token ID minted by the next transaction
+func (c *MockChain) SetNextTokenID(tokenID int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nextToken = tokenID
+}
+
+// Sent returns the transactions received so far, oldest first
+func (c *MockChain) Sent() []*types.Transaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*types.Transaction(nil), c.sent...)
+}
+
+func (c *MockChain) serveRPC(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+	switch req.Method {
+	case "eth_getBalance":
+		resp["result"] = hexutil.EncodeBig(c.balance)
+	case "eth_call":
+		if bytes.HasPrefix(callData(req.Params), signerSelector) {
+			resp["result"] = hexutil.Encode(common.BytesToHash(c.mintSigner.Bytes()).Bytes())
+		} else {
+			resp["result"] = hexutil.Encode(common.BigToHash(c.mintPrice).Bytes())
+		}
+	case "eth_getTransactionCount":
+		resp["result"] = hexutil.EncodeUint64(uint64(len(c.sent)))
+	case "eth_gasPrice":
+		resp["result"] = "0x3b9aca00" // 1 gwei
+	case "eth_estimateGas":
+		resp["result"] = "0x30d40"
+	case "eth_sendRawTransaction":
+		var raw hexutil.Bytes
+		tx := new(types.Transaction)
+		if len(req.Params) == 0 || json.Unmarshal(req.Params[0], &raw) != nil || tx.UnmarshalBinary(raw) != nil {
+			resp["error"] = map[string]interface{}{"code": -32602, "message": "invalid transaction"}
+			break
+		}
+		c.sent = append(c.sent, tx)
+		c.receipts[tx.Hash()] = c.mint(tx)
+		resp["result"] = tx.Hash().Hex()
+	case "eth_getTransactionReceipt":
+		var hash common.Hash
+		if len(req.Params) > 0 {
+			json.Unmarshal(req.Params[0], &hash)
+		}
+		if receipt, ok := c.receipts[hash]; ok {
+			resp["result"] = receipt
+		} else {
+			resp["result"] = nil
+		}
+	default:
+		resp["error"] = map[string]interface{}{"code": -32601, "message": "method not supported by mock chain: " + req.Method}
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// signerSelector is the selector of the contract's signer() view
+var signerSelector = crypto.Keccak256([]byte("signer()"))[:4]
+
+// callData returns the input of an eth_call
+func callData(params []json.RawMessage) []byte {
+	var call struct {
+		Data  hexutil.Bytes `json:"data"`
+		Input hexutil.Bytes `json:"input"`
+	}
+	if len(params) == 0 || json.Unmarshal(params[0], &call) != nil {
+		return nil
+	}
+	if len(call.Input) > 0 {
+		return call.Input
+	}
+	return call.Data
+}
+
+// mint returns the receipt of tx, minting the next token ID to its sender
+func (c *MockChain) mint(tx *types.Transaction) *types.Receipt {
+	tokenID := c.nextToken
+	c.nextToken++
+
+	var contract common.Address
+	if tx.To() != nil {
+		contract = *tx.To()
+	}
+	var to common.Hash
+	if sender, err := types.LatestSignerForChainID(tx.ChainId()).Sender(tx); err == nil {
+		to = common.BytesToHash(sender.Bytes())
+	}
+
+	return &types.Receipt{
+		Status:            types.ReceiptStatusSuccessful,
+		CumulativeGasUsed: 21000,
+		GasUsed:           21000,
+		TxHash:            tx.Hash(),
+		BlockNumber:       big.NewInt(int64(len(c.sent))),
+		Logs: []*types.Log{{
+			Address: contract,
+			Topics: []common.Hash{
+				crypto.Keccak256Hash([]byte("Minted(address,uint256)")),
+				to,
+				common.BigToHash(big.NewInt(tokenID)),
+			},
+			Data:   []byte{},
+			TxHash: tx.Hash(),
+		}},
+	}
+}
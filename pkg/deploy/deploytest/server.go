@@ -0,0 +1,361 @@
+// Package deploytest provides an in-memory Teneo backend and chain for testing
+// deploy and mint flows without network access, a live backend or real funds.
+//
+//	chain := deploytest.NewMockChain()
+//	defer chain.Close()
+//	backend := deploytest.NewMockServer()
+//	backend.RPCURL = chain.URL
+//	defer backend.Close()
+//
+//	minter, err := deploy.NewMinter(&deploy.MintConfig{
+//		PrivateKey: key,
+//		BackendURL: backend.URL,
+//		WALStore:   deploy.NewMemoryWALStore(),
+//	})
+package deploytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/deploy"
+)
+
+// Defaults reported by a MockServer
+const (
+	DefaultContractAddress = "0x00000000000000000000000000000000000000c0"
+	DefaultChainID         = "3338"
+	DefaultSchemaVersion   = "4"
+)
+
+// Agent is the mock backend's record of an agent
+type Agent struct {
+	Wallet     string
+	AgentName  string
+	ConfigHash string
+	TokenID    *int64 // nil while the agent ID is only reserved
+}
+
+// MockServer is an httptest server implementing the /api/sdk/* endpoints used
+// by deploy.Minter and deploy.Deployer. By default it behaves like the real
+// backend for a single wallet: sync reserves unknown agents (MINT_REQUIRED),
+// confirm-mint records the minted token, and a later sync reports SYNCED or,
+// once the config hash changed, UPDATE_REQUIRED. Use Handle to replace any
+// endpoint, e.g. to return errors.
+type MockServer struct {
+	*httptest.Server
+
+	// Returned by deploy and contract config. RPCURL defaults to empty, so
+	// the minter falls back to its RPCEndpoint; set it to a MockChain's URL.
+	ContractAddress string
+	ChainID         string
+	RPCURL          string
+
+	// Schema is returned by GET /api/sdk/schema
+	Schema deploy.SchemaResponse
+
+	mu        sync.Mutex
+	agents    map[string]*Agent
+	overrides map[string]http.HandlerFunc
+	calls     map[string]int
+	requests  map[string][][]byte
+	challenge int
+	nonce     uint64
+}
+
+// NewMockServer starts a mock backend. Close it when done.
+func NewMockServer() *MockServer {
+	s := &MockServer{
+		ContractAddress: DefaultContractAddress,
+		ChainID:         DefaultChainID,
+		Schema: deploy.SchemaResponse{
+			SchemaVersion: DefaultSchemaVersion,
+			MaxJSONSize:   deploy.DefaultMaxJSONSize,
+		},
+		agents:    make(map[string]*Agent),
+		overrides: make(map[string]http.HandlerFunc),
+		calls:     make(map[string]int),
+		requests:  make(map[string][][]byte),
+	}
+
+	mux := http.NewServeMux()
+	routes := map[string]http.HandlerFunc{
+		"/api/sdk/auth/challenge":     s.handleChallenge,
+		"/api/sdk/auth/verify":        s.handleVerify,
+		"/api/sdk/schema":             s.handleSchema,
+		"/api/contract/config":        s.handleContractConfig,
+		"/api/sdk/agent/sync":         s.handleSync,
+		"/api/sdk/agent/deploy":       s.handleDeploy,
+		"/api/sdk/agent/confirm-mint": s.handleConfirmMint,
+		"/api/sdk/agent/update":       s.handleUpdate,
+		"/api/sdk/agent/abandon":      s.handleAbandon,
+	}
+	for path, handler := range routes {
+		mux.HandleFunc(path, s.route(path, handler))
+	}
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Handle replaces the handler of path, e.g. "/api/sdk/agent/deploy". Calls
+// are still counted and recorded.
+func (s *MockServer) Handle(path string, handler http.HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overrides[path] = handler
+}
+
+// SetAgent seeds or replaces the backend's record of agentID
+func (s *MockServer) SetAgent(agentID string, agent Agent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.agents[agentID] = &agent
+}
+
+// Agent returns the backend's record of agentID
+func (s *MockServer) Agent(agentID string) (Agent, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	agent, ok := s.agents[agentID]
+	if !ok {
+		return Agent{}, false
+	}
+	return *agent, true
+}
+
+// Calls returns how many requests were made to path
+func (s *MockServer) Calls(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[path]
+}
+
+// Requests returns the bodies of the requests made to path, oldest first
+func (s *MockServer) Requests(path string) [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]byte(nil), s.requests[path]...)
+}
+
+// route records each request to path and dispatches it to the override or
+// the default handler
+func (s *MockServer) route(path string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		s.mu.Lock()
+		s.calls[path]++
+		s.requests[path] = append(s.requests[path], body)
+		serve := handler
+		if override, ok := s.overrides[path]; ok {
+			serve = override
+		}
+		s.mu.Unlock()
+
+		serve(w, r)
+	}
+}
+
+func (s *MockServer) handleChallenge(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.challenge++
+	challenge := fmt.Sprintf("mock-challenge-%d", s.challenge)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, deploy.ChallengeResponse{Challenge: challenge, ExpiresAt: 1 << 40})
+}
+
+func (s *MockServer) handleVerify(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, deploy.VerifyResponse{SessionToken: "mock-session", ExpiresAt: 1 << 40})
+}
+
+func (s *MockServer) handleSchema(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.Schema)
+}
+
+func (s *MockServer) handleContractConfig(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, deploy.ContractConfigResponse{
+		ContractAddress: s.ContractAddress,
+		ChainID:         s.ChainID,
+		NetworkName:     "mock",
+	})
+}
+
+func (s *MockServer) handleSync(w http.ResponseWriter, r *http.Request) {
+	var req deploy.SyncRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, deploy.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := deploy.SyncResponse{
+		AgentID:         req.AgentID,
+		ContractAddress: s.ContractAddress,
+		RPCURL:          s.RPCURL,
+		ConfigHash:      req.ConfigHash,
+	}
+
+	agent, ok := s.agents[req.AgentID]
+	switch {
+	case !ok:
+		s.agents[req.AgentID] = &Agent{Wallet: req.Wallet, ConfigHash: req.ConfigHash}
+		resp.Status = "MINT_REQUIRED"
+	case !strings.EqualFold(agent.Wallet, req.Wallet):
+		writeJSON(w, http.StatusForbidden, map[string]string{
+			"error":   "FORBIDDEN",
+			"message": "agent is owned by another wallet",
+		})
+		return
+	case agent.TokenID == nil:
+		resp.Status = "RESUME_MINT"
+	case agent.ConfigHash == req.ConfigHash:
+		resp.Status = "SYNCED"
+		resp.TokenID = agent.TokenID
+		resp.Creator = agent.Wallet
+	default:
+		resp.Status = "UPDATE_REQUIRED"
+		resp.TokenID = agent.TokenID
+		resp.Creator = agent.Wallet
+		resp.CurrentHash = agent.ConfigHash
+		resp.NewHash = req.ConfigHash
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *MockServer) handleDeploy(w http.ResponseWriter, r *http.Request) {
+	var req deploy.DeployRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, deploy.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, ok := s.agents[req.AgentID]
+	if ok && (agent.TokenID != nil || !strings.EqualFold(agent.Wallet, req.WalletAddress)) {
+		writeJSON(w, http.StatusConflict, deploy.ErrorResponse{Error: "agent_id already exists"})
+		return
+	}
+	if !ok {
+		agent = &Agent{Wallet: req.WalletAddress}
+		s.agents[req.AgentID] = agent
+	}
+	agent.AgentName = req.AgentName
+	agent.ConfigHash = req.ConfigHash
+
+	s.nonce++
+	signature, err := signMint(req.WalletAddress, s.nonce, s.ChainID, s.ContractAddress)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, deploy.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, deploy.DeployResponse{
+		Signature:       signature,
+		Nonce:           s.nonce,
+		ContractAddress: s.ContractAddress,
+		ChainID:         s.ChainID,
+		RPCURL:          s.RPCURL,
+		AgentID:         req.AgentID,
+		ConfigHash:      req.ConfigHash,
+	})
+}
+
+func (s *MockServer) handleConfirmMint(w http.ResponseWriter, r *http.Request) {
+	var req deploy.ConfirmMintRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, deploy.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, ok := s.agents[req.AgentID]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, deploy.ErrorResponse{Error: "agent not found"})
+		return
+	}
+	tokenID := req.TokenID
+	agent.TokenID = &tokenID
+	if req.ConfigHash != "" {
+		agent.ConfigHash = req.ConfigHash
+	}
+
+	writeJSON(w, http.StatusOK, deploy.ConfirmMintResponse{
+		Success:     true,
+		ID:          "mock-" + req.AgentID,
+		Message:     "Agent confirmed",
+		MetadataURI: "ipfs://mock-" + req.AgentID,
+	})
+}
+
+func (s *MockServer) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	var req deploy.UpdateMetadataRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, deploy.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, ok := s.agents[req.AgentID]
+	if !ok || agent.TokenID == nil {
+		writeJSON(w, http.StatusNotFound, deploy.ErrorResponse{Error: "agent not minted"})
+		return
+	}
+	agent.AgentName = req.AgentName
+	agent.ConfigHash = req.ConfigHash
+
+	writeJSON(w, http.StatusOK, deploy.UpdateMetadataResponse{
+		Success:     true,
+		IpfsHash:    "QmMock" + req.AgentID,
+		MetadataURI: "ipfs://QmMock" + req.AgentID,
+		TxHash:      "0x" + strings.Repeat("0", 63) + "1",
+		Message:     "Metadata updated",
+	})
+}
+
+func (s *MockServer) handleAbandon(w http.ResponseWriter, r *http.Request) {
+	var req deploy.AbandonRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, deploy.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, ok := s.agents[req.AgentID]
+	if !ok || agent.TokenID != nil {
+		writeJSON(w, http.StatusNotFound, deploy.ErrorResponse{Error: "reservation not found"})
+		return
+	}
+	delete(s.agents, req.AgentID)
+	writeJSON(w, http.StatusOK, deploy.AbandonResponse{Success: true, Message: "Reservation abandoned", AgentID: req.AgentID})
+}
+
+func decodeJSON(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
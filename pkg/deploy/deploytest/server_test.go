@@ -0,0 +1,212 @@
+package deploytest
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/deploy"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// newWallet returns a fresh private key and its address
+func newWallet(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hex.EncodeToString(crypto.FromECDSA(key)), crypto.PubkeyToAddress(key.PublicKey).Hex()
+}
+
+// newMockEnv starts a mock chain and a backend pointing at it
+func newMockEnv(t *testing.T) (*MockServer, *MockChain) {
+	t.Helper()
+	chain := NewMockChain()
+	t.Cleanup(chain.Close)
+	backend := NewMockServer()
+	backend.RPCURL = chain.URL
+	t.Cleanup(backend.Close)
+	return backend, chain
+}
+
+func newTestMinter(t *testing.T, backend *MockServer, privateKey string) *deploy.Minter {
+	t.Helper()
+	minter, err := deploy.NewMinter(&deploy.MintConfig{
+		PrivateKey:     privateKey,
+		BackendURL:     backend.URL,
+		WALStore:       deploy.NewMemoryWALStore(),
+		SchemaCacheDir: t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("NewMinter() error = %v", err)
+	}
+	return minter
+}
+
+// writeAgentConfig writes an agent JSON config with description to a temp file
+func writeAgentConfig(t *testing.T, description string) string {
+	t.Helper()
+	data, err := json.Marshal(&deploy.AgentConfig{
+		Name:         "Mock Agent",
+		AgentID:      "mock-agent",
+		Description:  description,
+		AgentType:    "command",
+		Categories:   []string{"AI"},
+		Capabilities: []deploy.Capability{{Name: "cap"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "agent.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMockServer_MintRequiredToMinted(t *testing.T) {
+	backend, chain := newMockEnv(t)
+	chain.SetNextTokenID(42)
+	privateKey, wallet := newWallet(t)
+	minter := newTestMinter(t, backend, privateKey)
+	jsonPath := writeAgentConfig(t, "An agent minted against the mock backend")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := minter.MintWithContext(ctx, jsonPath)
+	if err != nil {
+		t.Fatalf("MintWithContext() error = %v", err)
+	}
+	if result.Status != deploy.MintStatusMinted || result.TokenID != 42 {
+		t.Errorf("result = %+v, want MINTED with token 42", result)
+	}
+	if sent := chain.Sent(); len(sent) != 1 {
+		t.Errorf("chain received %d transactions, want 1", len(sent))
+	}
+	agent, ok := backend.Agent("mock-agent")
+	if !ok || agent.TokenID == nil || *agent.TokenID != 42 || agent.Wallet != wallet {
+		t.Errorf("backend agent = %+v, want token 42 owned by %s", agent, wallet)
+	}
+
+	// Minting the same config again only syncs
+	result, err = minter.MintWithContext(ctx, jsonPath)
+	if err != nil {
+		t.Fatalf("second MintWithContext() error = %v", err)
+	}
+	if result.Status != deploy.MintStatusAlreadyOwned || result.TokenID != 42 {
+		t.Errorf("second result = %+v, want ALREADY_OWNED with token 42", result)
+	}
+	if got := backend.Calls("/api/sdk/agent/deploy"); got != 1 {
+		t.Errorf("deploy called %d times, want 1", got)
+	}
+}
+
+func TestMockServer_UpdateRequired(t *testing.T) {
+	backend, chain := newMockEnv(t)
+	privateKey, wallet := newWallet(t)
+	tokenID := int64(7)
+	backend.SetAgent("mock-agent", Agent{Wallet: wallet, ConfigHash: "old-hash", TokenID: &tokenID})
+	minter := newTestMinter(t, backend, privateKey)
+
+	result, err := minter.MintWithContext(context.Background(), writeAgentConfig(t, "An agent with a changed description"))
+	if err != nil {
+		t.Fatalf("MintWithContext() error = %v", err)
+	}
+	if result.Status != deploy.MintStatusUpdated || result.TokenID != 7 {
+		t.Errorf("result = %+v, want UPDATED with token 7", result)
+	}
+	if got := backend.Calls("/api/sdk/agent/update"); got != 1 {
+		t.Errorf("update called %d times, want 1", got)
+	}
+	if agent, _ := backend.Agent("mock-agent"); agent.ConfigHash == "old-hash" {
+		t.Error("backend still has the old config hash after the update")
+	}
+	if sent := chain.Sent(); len(sent) != 0 {
+		t.Errorf("update sent %d transactions, want none", len(sent))
+	}
+}
+
+func TestMockServer_Deployer(t *testing.T) {
+	backend, chain := newMockEnv(t)
+	privateKey, _ := newWallet(t)
+
+	deployer, err := deploy.NewDeployer(&deploy.DeployConfig{
+		BackendURL:    backend.URL,
+		RPCEndpoint:   chain.URL,
+		PrivateKey:    privateKey,
+		AgentID:       "mock-deployed-agent",
+		AgentName:     "Mock Deployed Agent",
+		Description:   "Deployed against the mock backend",
+		AgentType:     "command",
+		StateFilePath: filepath.Join(t.TempDir(), "state.json"),
+		MintPrice:     big.NewInt(1),
+	})
+	if err != nil {
+		t.Fatalf("NewDeployer() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	result, err := deployer.Deploy(ctx)
+	if err != nil {
+		t.Fatalf("Deploy() error = %v", err)
+	}
+	if result.TokenID != 1 {
+		t.Errorf("TokenID = %d, want 1", result.TokenID)
+	}
+	if got := backend.Calls("/api/sdk/agent/confirm-mint"); got != 1 {
+		t.Errorf("confirm-mint called %d times, want 1", got)
+	}
+}
+
+func TestMockServer_RefusesMintSignedByWrongKey(t *testing.T) {
+	backend, chain := newMockEnv(t)
+	_, otherSigner := newWallet(t)
+	chain.SetMintSigner(common.HexToAddress(otherSigner))
+	privateKey, _ := newWallet(t)
+	minter, err := deploy.NewMinter(&deploy.MintConfig{
+		PrivateKey:          privateKey,
+		BackendURL:          backend.URL,
+		WALStore:            deploy.NewMemoryWALStore(),
+		SchemaCacheDir:      t.TempDir(),
+		VerifyMintSignature: true,
+	})
+	if err != nil {
+		t.Fatalf("NewMinter() error = %v", err)
+	}
+
+	_, err = minter.MintWithContext(context.Background(), writeAgentConfig(t, "An agent with a bad mint signature"))
+	if !errors.Is(err, deploy.ErrMintSignatureMismatch) {
+		t.Fatalf("MintWithContext() error = %v, want ErrMintSignatureMismatch", err)
+	}
+	if sent := chain.Sent(); len(sent) != 0 {
+		t.Errorf("chain received %d transactions, want none", len(sent))
+	}
+	if got := backend.Calls("/api/sdk/agent/abandon"); got != 1 {
+		t.Errorf("abandon called %d times, want 1 so the agent ID is released", got)
+	}
+}
+
+func TestMockServer_SkipsMintSignatureCheckByDefault(t *testing.T) {
+	backend, chain := newMockEnv(t)
+	_, otherSigner := newWallet(t)
+	chain.SetMintSigner(common.HexToAddress(otherSigner))
+	privateKey, _ := newWallet(t)
+	minter := newTestMinter(t, backend, privateKey)
+
+	if _, err := minter.MintWithContext(context.Background(), writeAgentConfig(t, "An agent minted without a signature check")); err != nil {
+		t.Fatalf("MintWithContext() error = %v", err)
+	}
+	if sent := chain.Sent(); len(sent) != 1 {
+		t.Errorf("chain received %d transactions, want the mint", len(sent))
+	}
+}
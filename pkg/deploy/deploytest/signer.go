@@ -0,0 +1,41 @@
+package deploytest
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/deploy"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// mintSignerKey signs the mint signatures a MockServer hands out
+var mintSignerKey = mustMintSignerKey()
+
+// MintSigner is the address a MockServer signs mints with and the address a
+// MockChain's contract expects them from
+var MintSigner = crypto.PubkeyToAddress(mintSignerKey.PublicKey)
+
+func mustMintSignerKey() *ecdsa.PrivateKey {
+	key, err := crypto.ToECDSA(crypto.Keccak256([]byte("deploytest mint signer")))
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// signMint returns the backend signature allowing wallet to mint on contract
+func signMint(wallet string, nonce uint64, chainID, contract string) (string, error) {
+	chain, _ := new(big.Int).SetString(chainID, 10)
+	if chain == nil {
+		chain = new(big.Int)
+	}
+	digest := deploy.MintSignatureDigest(common.HexToAddress(wallet), nonce, chain, common.HexToAddress(contract))
+	sig, err := crypto.Sign(digest, mintSignerKey)
+	if err != nil {
+		return "", err
+	}
+	sig[64] += 27
+	return hexutil.Encode(sig), nil
+}
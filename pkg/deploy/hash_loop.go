@@ -0,0 +1,72 @@
+package deploy
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ErrConfigHashLoop is returned when the backend still reports
+// UPDATE_REQUIRED with the same hashes right after a metadata update. The SDK
+// and backend disagree on the config hash, so every further update would
+// spend gas without ever syncing.
+var ErrConfigHashLoop = errors.New("config hash mismatch loop")
+
+// checkUpdateLoop returns an ErrConfigHashLoop error if the re-sync after an
+// update reports the same UPDATE_REQUIRED hashes as the sync that triggered it
+func checkUpdateLoop(config *AgentConfig, configHash string, before, after *SyncResponse) error {
+	if before.Status != "UPDATE_REQUIRED" || after.Status != "UPDATE_REQUIRED" {
+		return nil
+	}
+	if before.CurrentHash != after.CurrentHash || before.NewHash != after.NewHash {
+		return nil
+	}
+	return fmt.Errorf("%w: backend still reports UPDATE_REQUIRED for %s after the update (current hash %s, new hash %s, SDK hash %s); likely causes: %s",
+		ErrConfigHashLoop, config.AgentID, after.CurrentHash, after.NewHash, configHash,
+		strings.Join(hashMismatchHints(config, configHash, after), "; "))
+}
+
+// hashMismatchHints guesses which parts of the config the SDK and backend
+// hash differently: another hash version or image handling, or fields whose
+// normalization is easy to get wrong
+func hashMismatchHints(config *AgentConfig, configHash string, resp *SyncResponse) []string {
+	var hints []string
+	for _, version := range []string{ConfigHashV3, ConfigHashV4} {
+		for _, includeImage := range []bool{false, true} {
+			hash := GenerateConfigHashWithOptions(config, HashOptions{SchemaVersion: version, IncludeImage: includeImage})
+			if hash == configHash || (hash != resp.CurrentHash && hash != resp.NewHash) {
+				continue
+			}
+			variant := version
+			if includeImage {
+				variant += " with the image"
+			}
+			hints = append(hints, fmt.Sprintf("the backend hashes the config as %s; check the schema version", variant))
+		}
+	}
+	if resp.NewHash != "" && resp.NewHash != configHash && len(hints) == 0 {
+		hints = append(hints, "the backend computes a different hash than the SDK for the same config")
+	}
+
+	if config.Name != strings.TrimSpace(config.Name) || config.Description != strings.TrimSpace(config.Description) {
+		hints = append(hints, "name or description has surrounding whitespace")
+	}
+	for _, cmd := range config.Commands {
+		if cmd.PricePerUnit != math.Trunc(cmd.PricePerUnit) {
+			hints = append(hints, "fractional command prices may be formatted differently")
+			break
+		}
+	}
+	for _, cmd := range config.Commands {
+		if len(cmd.Parameters) > 0 {
+			hints = append(hints, "command parameters may be canonicalized differently")
+			break
+		}
+	}
+
+	if len(hints) == 0 {
+		hints = append(hints, "capabilities, categories or commands are normalized differently")
+	}
+	return hints
+}
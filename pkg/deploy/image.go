@@ -0,0 +1,66 @@
+package deploy
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// isLocalImagePath reports whether image names a file rather than a URL or
+// data URI: it has no scheme, or a single-letter one as in C:\agent.png.
+func isLocalImagePath(image string) bool {
+	if image == "" || (len(image) >= 5 && strings.EqualFold(image[:5], "data:")) {
+		return false
+	}
+	u, err := url.Parse(image)
+	if err != nil {
+		return !strings.Contains(image, "://")
+	}
+	return u.Scheme == "" || len(u.Scheme) == 1
+}
+
+// resolveImage inlines an image given as a local file path as a base64 data
+// URI, so the backend receives the image rather than a path it cannot read.
+// Relative paths are resolved against baseDir when it is set. URLs and data
+// URIs are returned unchanged. The file must be a PNG, JPEG, GIF, WebP, BMP,
+// ICO or SVG image of at most MaxInlineImageSize bytes.
+func resolveImage(image, baseDir string) (string, error) {
+	if !isLocalImagePath(image) {
+		return image, nil
+	}
+
+	path := image
+	if baseDir != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read image file: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, MaxInlineImageSize+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read image file: %w", err)
+	}
+	if len(data) > MaxInlineImageSize {
+		return "", fmt.Errorf("image file %s must not exceed %d bytes; host larger images and set image to their URL", path, MaxInlineImageSize)
+	}
+
+	mediaType := http.DetectContentType(data)
+	if strings.EqualFold(filepath.Ext(path), ".svg") && strings.Contains(string(data), "<svg") {
+		mediaType = "image/svg+xml"
+	}
+	if !strings.HasPrefix(mediaType, "image/") {
+		return "", fmt.Errorf("image file %s is not an image (detected %s)", path, mediaType)
+	}
+
+	log.Printf("🖼️ Inlining image %s (%s, %d bytes)", path, mediaType, len(data))
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
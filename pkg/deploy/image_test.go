@@ -0,0 +1,91 @@
+package deploy
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testPNG is a 1x1 transparent PNG
+var testPNG, _ = base64.StdEncoding.DecodeString("iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNkYPhfDwAChwGA60e6kgAAAABJRU5ErkJggg==")
+
+func TestResolveImage(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "agent.png"), testPNG, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not an image"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "huge.png"), append(testPNG, make([]byte, MaxInlineImageSize)...), 0600); err != nil {
+		t.Fatal(err)
+	}
+	inlined := "data:image/png;base64," + base64.StdEncoding.EncodeToString(testPNG)
+
+	tests := []struct {
+		name    string
+		image   string
+		want    string
+		wantErr string
+	}{
+		{"empty", "", "", ""},
+		{"http URL", "http://example.com/agent.png", "http://example.com/agent.png", ""},
+		{"ipfs URI", "ipfs://bafyimage", "ipfs://bafyimage", ""},
+		{"data URI", inlined, inlined, ""},
+		{"absolute PNG path", filepath.Join(dir, "agent.png"), inlined, ""},
+		{"relative PNG path", "agent.png", inlined, ""},
+		{"missing file", "missing.png", "", "failed to read image file"},
+		{"not an image", "notes.txt", "", "is not an image"},
+		{"too large", "huge.png", "", "must not exceed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveImage(tt.image, dir)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("resolveImage() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveImage() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveImage() = %.60s, want %.60s", got, tt.want)
+			}
+			if err := validateImage(got); err != nil {
+				t.Errorf("resolved image fails validation: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_InlinesLocalImage(t *testing.T) {
+	minter, _ := newOfflineSchemaMinter(t, "")
+
+	fields := validAgentFields()
+	fields["image"] = "agent.png"
+	jsonPath := writeAgentJSON(t, fields)
+	if err := os.WriteFile(filepath.Join(filepath.Dir(jsonPath), "agent.png"), testPNG, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, _, err := minter.loadConfig(context.Background(), jsonPath)
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if want := "data:image/png;base64," + base64.StdEncoding.EncodeToString(testPNG); config.Image != want {
+		t.Errorf("config.Image = %.60s, want the inlined PNG", config.Image)
+	}
+
+	fields["image"] = "missing.png"
+	if _, _, err := minter.loadConfig(context.Background(), writeAgentJSON(t, fields)); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("loadConfig() error = %v, want fs.ErrNotExist for a missing image", err)
+	}
+}
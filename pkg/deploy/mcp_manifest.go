@@ -0,0 +1,141 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultMcpManifestTimeout bounds fetching an MCP manifest when
+// MintConfig.VerifyMcpManifest is set
+const DefaultMcpManifestTimeout = 10 * time.Second
+
+// maxMcpManifestSize caps how much of a manifest response is read
+const maxMcpManifestSize = 1 << 20
+
+// McpManifest is the part of an MCP server manifest checked before minting
+type McpManifest struct {
+	Name      string        `json:"name,omitempty"`
+	Tools     []McpTool     `json:"tools,omitempty"`
+	Resources []McpResource `json:"resources,omitempty"`
+}
+
+// McpTool is a tool declared in an MCP manifest
+type McpTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"inputSchema,omitempty"`
+}
+
+// McpResource is a resource declared in an MCP manifest
+type McpResource struct {
+	URI  string `json:"uri"`
+	Name string `json:"name,omitempty"`
+}
+
+// FetchMcpManifest downloads the MCP manifest at manifestURL and checks that
+// it is JSON declaring at least one tool or resource, each with a name or URI.
+// A nil client uses http.DefaultClient; ctx bounds the request.
+func FetchMcpManifest(ctx context.Context, client *http.Client, manifestURL string) (*McpManifest, error) {
+	u, err := url.Parse(manifestURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("mcpManifest must be an http(s) URL, got %q", manifestURL)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, manifestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MCP manifest request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if isTimeout(err) {
+			return nil, fmt.Errorf("timed out fetching MCP manifest from %s", manifestURL)
+		}
+		return nil, fmt.Errorf("failed to fetch MCP manifest from %s: %w", manifestURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("MCP manifest at %s returned status %d", manifestURL, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxMcpManifestSize+1))
+	if err != nil {
+		if isTimeout(err) {
+			return nil, fmt.Errorf("timed out reading MCP manifest from %s", manifestURL)
+		}
+		return nil, fmt.Errorf("failed to read MCP manifest from %s: %w", manifestURL, err)
+	}
+	if len(body) > maxMcpManifestSize {
+		return nil, fmt.Errorf("MCP manifest at %s exceeds %d bytes", manifestURL, maxMcpManifestSize)
+	}
+
+	var manifest McpManifest
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		contentType := resp.Header.Get("Content-Type")
+		return nil, fmt.Errorf("MCP manifest at %s is not valid JSON (content type %q): %w", manifestURL, contentType, err)
+	}
+	if err := manifest.validate(); err != nil {
+		return nil, fmt.Errorf("invalid MCP manifest at %s: %w", manifestURL, err)
+	}
+	return &manifest, nil
+}
+
+// isTimeout reports whether err is a context deadline or client timeout
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// validate checks the fields required of an MCP manifest
+func (m *McpManifest) validate() error {
+	if len(m.Tools) == 0 && len(m.Resources) == 0 {
+		return fmt.Errorf("manifest must declare at least one tool or resource")
+	}
+	for i, tool := range m.Tools {
+		if strings.TrimSpace(tool.Name) == "" {
+			return fmt.Errorf("tool %d: name is required", i+1)
+		}
+	}
+	for i, resource := range m.Resources {
+		if strings.TrimSpace(resource.URI) == "" {
+			return fmt.Errorf("resource %d: uri is required", i+1)
+		}
+	}
+	return nil
+}
+
+// verifyMcpManifest fetches and checks an mcp agent's manifest when
+// MintConfig.VerifyMcpManifest is set
+func (m *Minter) verifyMcpManifest(ctx context.Context, config *AgentConfig) error {
+	if m.config == nil || !m.config.VerifyMcpManifest || config.AgentType != "mcp" {
+		return nil
+	}
+
+	timeout := m.config.McpManifestTimeout
+	if timeout <= 0 {
+		timeout = DefaultMcpManifestTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	manifest, err := FetchMcpManifest(ctx, m.config.HTTPClient, config.McpManifest)
+	if err != nil {
+		return err
+	}
+	log.Printf("✅ MCP manifest verified: %d tools, %d resources", len(manifest.Tools), len(manifest.Resources))
+	return nil
+}
@@ -0,0 +1,140 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newManifestServer serves MCP manifests of varying quality
+func newManifestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/valid.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"name":  "weather",
+			"tools": []map[string]interface{}{{"name": "get_forecast", "inputSchema": map[string]string{"type": "object"}}},
+		})
+	})
+	mux.HandleFunc("/resources-only.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"resources": []map[string]string{{"uri": "file:///logs/app.log", "name": "logs"}},
+		})
+	})
+	mux.HandleFunc("/empty.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"name": "nothing"})
+	})
+	mux.HandleFunc("/unnamed-tool.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"tools": []map[string]string{{"description": "no name"}}})
+	})
+	mux.HandleFunc("/html", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><body>Not found</body></html>"))
+	})
+	mux.HandleFunc("/missing.json", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/slow.json", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFetchMcpManifest(t *testing.T) {
+	srv := newManifestServer(t)
+
+	tests := []struct {
+		name   string
+		url    string
+		errMsg string // empty = valid
+	}{
+		{"valid manifest", srv.URL + "/valid.json", ""},
+		{"resources only", srv.URL + "/resources-only.json", ""},
+		{"no tools or resources", srv.URL + "/empty.json", "at least one tool or resource"},
+		{"tool without name", srv.URL + "/unnamed-tool.json", "tool 1: name is required"},
+		{"non-JSON response", srv.URL + "/html", "is not valid JSON"},
+		{"not found", srv.URL + "/missing.json", "returned status 404"},
+		{"timeout", srv.URL + "/slow.json", "timed out"},
+		{"unsupported scheme", "file:///etc/manifest.json", "must be an http(s) URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			manifest, err := FetchMcpManifest(ctx, nil, tt.url)
+			if tt.errMsg == "" {
+				if err != nil || manifest == nil {
+					t.Errorf("FetchMcpManifest() = %v, %v, want a manifest", manifest, err)
+				}
+				return
+			}
+			if err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("FetchMcpManifest() error = %v, want error containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestMinter_VerifiesMcpManifestBeforeMinting(t *testing.T) {
+	srv := newManifestServer(t)
+
+	writeConfig := func(t *testing.T, manifestURL string) string {
+		t.Helper()
+		data, _ := json.Marshal(map[string]interface{}{
+			"name":         "MCP Agent",
+			"agentId":      "mcp-agent",
+			"description":  "Exposes tools over MCP",
+			"agentType":    "mcp",
+			"categories":   []string{"AI"},
+			"capabilities": []map[string]string{{"name": "weather/forecast"}},
+			"mcpManifest":  manifestURL,
+		})
+		path := filepath.Join(t.TempDir(), "agent.json")
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name     string
+		verify   bool
+		manifest string
+		wantErr  bool
+	}{
+		{"valid manifest", true, srv.URL + "/valid.json", false},
+		{"invalid manifest", true, srv.URL + "/empty.json", true},
+		{"slow manifest", true, srv.URL + "/slow.json", true},
+		{"verification disabled", false, "http://127.0.0.1:1/unreachable.json", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minter := &Minter{
+				config: &MintConfig{VerifyMcpManifest: tt.verify, McpManifestTimeout: 100 * time.Millisecond},
+				// An unreachable backend makes loadConfig fall back to local validation
+				httpClient: NewHTTPClient("http://127.0.0.1:1"),
+			}
+
+			_, _, err := minter.loadConfig(context.Background(), writeConfig(t, tt.manifest))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loadConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && err != nil && !contains(err.Error(), "mcp manifest verification failed") {
+				t.Errorf("loadConfig() error = %v, want an mcp manifest error", err)
+			}
+		})
+	}
+}
@@ -3,23 +3,40 @@ package deploy
 import (
 	"context"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"math"
+	"math/big"
+	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // htmlTagPattern matches HTML/script tags for XSS prevention
 var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
 
+// capabilityNamePattern matches capability names the backend accepts: a
+// lowercase slug, optionally namespaced with slashes (e.g. "social/profile_lookup")
+var capabilityNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*(/[a-z0-9][a-z0-9_-]*)*$`)
+
+// commandTriggerPattern matches command triggers the backend accepts: a
+// lowercase slug (e.g. "search_places")
+var commandTriggerPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
 // DefaultMaxJSONSize is the fallback max size for agent JSON files (24KB)
 // The actual limit is fetched from backend via schema endpoint
 const DefaultMaxJSONSize = 24 * 1024
@@ -47,11 +64,168 @@ type Capability struct {
 
 // Command represents an agent command
 type Command struct {
-	Trigger      string   `json:"trigger"`
-	Description  string   `json:"description,omitempty"`
-	PricePerUnit float64  `json:"pricePerUnit,omitempty"`
-	PriceType    string   `json:"priceType,omitempty"`
-	TaskUnit     string   `json:"taskUnit,omitempty"`
+	Trigger      string             `json:"trigger"`
+	Description  string             `json:"description,omitempty"`
+	Parameters   []CommandParameter `json:"parameters,omitempty"`
+	PricePerUnit float64            `json:"pricePerUnit,omitempty"`
+	PriceType    string             `json:"priceType,omitempty"`
+	TaskUnit     string             `json:"taskUnit,omitempty"`
+}
+
+// CommandParameter describes a single argument accepted by a command
+type CommandParameter struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Required    bool   `json:"required"`
+	Description string `json:"description,omitempty"`
+}
+
+// validParameterTypes lists the parameter types accepted by the backend
+var validParameterTypes = map[string]bool{
+	"string":  true,
+	"number":  true,
+	"integer": true,
+	"boolean": true,
+	"array":   true,
+	"object":  true,
+}
+
+// Image limits: URLs up to MaxImageURLLength characters, inline data URIs up
+// to MaxInlineImageSize decoded bytes
+const (
+	MaxImageURLLength  = 2048
+	MaxInlineImageSize = 16 * 1024
+)
+
+// validateImage checks that image is empty, an http(s) or ipfs URL, or a
+// base64 data:image/... URI within MaxInlineImageSize. Other schemes such as
+// javascript: or file: are rejected.
+func validateImage(image string) error {
+	if image == "" {
+		return nil
+	}
+
+	if len(image) >= 5 && strings.EqualFold(image[:5], "data:") {
+		header, payload, ok := strings.Cut(image[5:], ",")
+		mediaType, encoding, _ := strings.Cut(header, ";")
+		if !ok || !strings.HasPrefix(strings.ToLower(mediaType), "image/") || !strings.EqualFold(encoding, "base64") {
+			return fmt.Errorf("image data URI must start with data:image/<type>;base64,")
+		}
+		if size := base64.StdEncoding.DecodedLen(len(payload)); size > MaxInlineImageSize {
+			return fmt.Errorf("inline image must not exceed %d bytes, got about %d", MaxInlineImageSize, size)
+		}
+		if _, err := base64.StdEncoding.DecodeString(payload); err != nil {
+			return fmt.Errorf("image data URI is not valid base64: %w", err)
+		}
+		return nil
+	}
+
+	if len(image) > MaxImageURLLength {
+		return fmt.Errorf("image URL must not exceed %d characters", MaxImageURLLength)
+	}
+	u, err := url.Parse(image)
+	if err != nil {
+		return fmt.Errorf("image is not a valid URL: %w", err)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "ipfs":
+		if u.Host == "" {
+			return fmt.Errorf("image URL must include a host")
+		}
+		return nil
+	default:
+		return fmt.Errorf("image must be an http(s) or ipfs URL or a data:image/...;base64 URI, got scheme '%s'", u.Scheme)
+	}
+}
+
+// Command price types accepted by the backend
+const (
+	PriceTypeTaskTransaction = "task-transaction"
+	PriceTypeTimeBasedTask   = "time-based-task"
+)
+
+// validTaskUnits lists the task units accepted for task-transaction pricing
+var validTaskUnits = map[string]bool{
+	"per-query": true,
+	"per-item":  true,
+}
+
+// validateCommandPricing checks that a command's price, price type and task
+// unit are consistent: prices are not negative, and a priced command has a
+// known price type and, for task-transaction pricing, a task unit
+func validateCommandPricing(cmd Command) error {
+	if cmd.PricePerUnit < 0 {
+		return fmt.Errorf("pricePerUnit must not be negative")
+	}
+	if cmd.PriceType != "" && cmd.PriceType != PriceTypeTaskTransaction && cmd.PriceType != PriceTypeTimeBasedTask {
+		return fmt.Errorf("priceType must be '%s' or '%s', got '%s'", PriceTypeTaskTransaction, PriceTypeTimeBasedTask, cmd.PriceType)
+	}
+	if cmd.PriceType == PriceTypeTaskTransaction && cmd.TaskUnit != "" && !validTaskUnits[cmd.TaskUnit] {
+		return fmt.Errorf("taskUnit must be 'per-query' or 'per-item' for %s pricing, got '%s'", PriceTypeTaskTransaction, cmd.TaskUnit)
+	}
+
+	if cmd.PricePerUnit == 0 {
+		return nil
+	}
+	if cmd.PriceType == "" {
+		return fmt.Errorf("priceType is required when pricePerUnit is set")
+	}
+	if cmd.PriceType == PriceTypeTaskTransaction && cmd.TaskUnit == "" {
+		return fmt.Errorf("taskUnit is required for %s pricing", PriceTypeTaskTransaction)
+	}
+	return nil
+}
+
+// validateCommandParameters checks the parameter definitions of a single command
+func validateCommandParameters(params []CommandParameter) error {
+	if len(params) > 20 {
+		return fmt.Errorf("maximum 20 parameters allowed")
+	}
+
+	seen := make(map[string]bool, len(params))
+	for i, p := range params {
+		if p.Name == "" {
+			return fmt.Errorf("parameter %d: name is required", i+1)
+		}
+		if len(p.Name) > 64 {
+			return fmt.Errorf("parameter %d: name must not exceed 64 characters", i+1)
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("parameter %d: duplicate name '%s'", i+1, p.Name)
+		}
+		seen[p.Name] = true
+
+		if !validParameterTypes[p.Type] {
+			return fmt.Errorf("parameter '%s': type must be one of string, number, integer, boolean, array, object", p.Name)
+		}
+		if len(p.Description) > 500 {
+			return fmt.Errorf("parameter '%s': description must not exceed 500 characters", p.Name)
+		}
+	}
+
+	// Required parameters must come first so positional arguments stay unambiguous
+	optionalSeen := false
+	for _, p := range params {
+		if !p.Required {
+			optionalSeen = true
+		} else if optionalSeen {
+			return fmt.Errorf("parameter '%s': required parameters must precede optional ones", p.Name)
+		}
+	}
+
+	return nil
+}
+
+// canonicalParameters renders parameters for the config hash as
+// name/type/required entries sorted by name. Descriptions are excluded,
+// matching how command descriptions are treated.
+func canonicalParameters(params []CommandParameter) string {
+	entries := make([]string, len(params))
+	for i, p := range params {
+		entries[i] = p.Name + "/" + p.Type + "/" + strconv.FormatBool(p.Required)
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ";")
 }
 
 // MintResult is defined in chain.go with fields:
@@ -63,15 +237,149 @@ type Minter struct {
 	httpClient   *HTTPClient
 	walClient    *WALClient
 	schemaCache  *SchemaCache
+
+	schemaCachePath string // persisted schema cache, empty if disabled
+	signer          Signer
+	newChain        ChainFactory
+	tracer          trace.Tracer
 }
 
 // MintConfig contains configuration for minting
 type MintConfig struct {
-	PrivateKey  string // Wallet private key (hex)
+	PrivateKey  string // Wallet private key (hex), not needed when Signer is set
 	BackendURL  string // Backend API URL
 	RPCEndpoint string // Blockchain RPC endpoint
+
+	// FailOnDeployConflict treats an "agent already exists" deploy response as fatal.
+	// By default the conflict is resolved with a sync so idempotent re-runs succeed.
+	FailOnDeployConflict bool
+
+	// KeepReservationOnFailure leaves the agent ID reserved when a fresh mint
+	// fails before the on-chain transaction is sent. By default the reservation
+	// is abandoned so it does not consume one of the wallet's reservation slots.
+	KeepReservationOnFailure bool
+
+	// WALStore persists the mint write-ahead log. Defaults to files under ~/.teneo/wal/;
+	// use a RedisWALStore for read-only or multi-replica deployments.
+	WALStore WALStore
+
+	// WALEncryptionKey encrypts WAL entries at rest with AES-GCM. It may be a
+	// 32-byte hex key or a passphrase. Empty keeps plaintext entries. Minting
+	// an agent whose entry cannot be decrypted fails until the key is set or
+	// the agent is abandoned.
+	WALEncryptionKey string
+
+	// SchemaSnapshotPath points to a saved /api/sdk/schema response used when
+	// the backend schema cannot be fetched. Defaults to the snapshot bundled
+	// with the SDK.
+	SchemaSnapshotPath string
+
+	// SchemaCacheDir is where fetched schemas are cached across runs.
+	// Defaults to DefaultSchemaCacheDir().
+	SchemaCacheDir string
+
+	// HTTPClient sends all backend requests. Defaults to a client with a 60s timeout.
+	HTTPClient *http.Client
+
+	// MintPrice overrides the mint price. By default the contract's mintPrice()
+	// is used, falling back to DefaultMintPrice if it cannot be read.
+	MintPrice *big.Int
+
+	// EIP712Auth signs backend auth challenges as EIP-712 typed data instead
+	// of personal_sign, for hardware wallets and typed-data verifiers
+	EIP712Auth bool
+
+	// Signer signs auth challenges and mint transactions in place of
+	// PrivateKey, e.g. a hardware wallet or KMS-backed signer
+	Signer Signer
+
+	// VerifyMintSignature checks the backend's mint signature against the
+	// contract's signer() before the mint is sent, failing with
+	// ErrMintSignatureMismatch. The signed digest is assumed to be
+	// MintSignatureDigest, so only enable it for contracts known to use it.
+	VerifyMintSignature bool
+
+	// PendingTxTimeout is how long WAL recovery waits for the receipt of a
+	// pending mint transaction before checking whether it was dropped.
+	// Defaults to DefaultPendingTxTimeout.
+	PendingTxTimeout time.Duration
+
+	// PendingTxPollInterval is how often WAL recovery polls for that receipt.
+	// Defaults to DefaultPendingTxPollInterval.
+	PendingTxPollInterval time.Duration
+
+	// SpeedUpAfter is how long a mint transaction may stay pending before it
+	// is resubmitted with the same nonce at a higher gas price. Defaults to
+	// DefaultSpeedUpAfter; a negative value disables speed-ups.
+	SpeedUpAfter time.Duration
+
+	// ReceiptTimeout is how long to wait for the receipt of a broadcast mint
+	// before failing with a ReceiptTimeoutError (the WAL keeps the hash for
+	// recovery), and ReceiptPollInterval how often to poll for it. They
+	// default to DefaultReceiptTimeout and DefaultReceiptPollInterval; the
+	// context deadline still applies.
+	ReceiptTimeout      time.Duration
+	ReceiptPollInterval time.Duration
+
+	// ConfirmRetries is how many times a transient confirm-mint failure (a
+	// 5xx response, rate limit or network error) is retried after a mint,
+	// waiting ConfirmRetryBackoff before the first retry and doubling it
+	// after each. Defaults to DefaultConfirmRetries and
+	// DefaultConfirmRetryBackoff; a negative ConfirmRetries disables retries.
+	// The WAL is kept after a final failure so Reconcile can confirm later.
+	ConfirmRetries      int
+	ConfirmRetryBackoff time.Duration
+
+	// TracerProvider traces each mint phase (auth, deploy call, on-chain mint,
+	// confirm) and backend request. Defaults to a no-op tracer.
+	TracerProvider trace.TracerProvider
+
+	// VerifyMcpManifest fetches the mcpManifest URL of mcp agents before
+	// minting and checks it declares tools or resources. Off by default so
+	// validation works offline. McpManifestTimeout bounds the fetch and
+	// defaults to DefaultMcpManifestTimeout.
+	VerifyMcpManifest  bool
+	McpManifestTimeout time.Duration
+
+	// InterpolateEnv expands ${VAR} and ${VAR:-default} in the string values
+	// of agent config files from the environment, so one file can serve
+	// several environments. Off by default so a '$' in a description is kept
+	// as written. An unset variable without a default becomes empty, unless
+	// StrictEnv is set, which fails with ErrUndefinedEnvVar instead.
+	InterpolateEnv bool
+	StrictEnv      bool
+
+	// ChainFactory connects to the NFT contract for on-chain operations.
+	// Defaults to NewChainOps; tests can return a fake ChainOps.
+	ChainFactory ChainFactory
+
+	// ExpectedContract and ExpectedChainID, when set, are compared with the
+	// contract and chain the backend reports before anything is sent on-chain,
+	// failing with ErrContractMismatch so a misconfigured backend cannot point
+	// the mint at the wrong contract
+	ExpectedContract string
+	ExpectedChainID  string
 }
 
+// Defaults for waiting on a pending mint transaction during WAL recovery
+const (
+	DefaultPendingTxTimeout      = 2 * time.Minute
+	DefaultPendingTxPollInterval = 5 * time.Second
+)
+
+// ErrAgentNotMinted is returned by ForceUpdate when the agent has no NFT yet
+var ErrAgentNotMinted = errors.New("agent is not minted yet")
+
+// keepReservationError marks mint failures after which the reservation must
+// not be abandoned: the on-chain mint was attempted (WAL recovery takes over)
+// or the agent ID already belonged to an earlier deploy.
+type keepReservationError struct {
+	err error
+}
+
+func (e *keepReservationError) Error() string { return e.err.Error() }
+func (e *keepReservationError) Unwrap() error { return e.err }
+
 // NewMinter creates a new minter instance
 func NewMinter(config *MintConfig) (*Minter, error) {
 	// Apply defaults from environment
@@ -86,23 +394,82 @@ func NewMinter(config *MintConfig) (*Minter, error) {
 		config.RPCEndpoint = os.Getenv("RPC_ENDPOINT")
 	}
 
-	if config.PrivateKey == "" {
+	if config.PrivateKey == "" && config.Signer == nil {
 		config.PrivateKey = os.Getenv("PRIVATE_KEY")
 		if config.PrivateKey == "" {
 			return nil, fmt.Errorf("private key is required")
 		}
 	}
 
-	httpClient := NewHTTPClient(config.BackendURL)
+	signer, err := resolveSigner(config.Signer, config.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.WALEncryptionKey == "" {
+		config.WALEncryptionKey = os.Getenv("WAL_ENCRYPTION_KEY")
+	}
+
+	if config.PendingTxTimeout == 0 {
+		config.PendingTxTimeout = DefaultPendingTxTimeout
+	}
+	if config.PendingTxPollInterval == 0 {
+		config.PendingTxPollInterval = DefaultPendingTxPollInterval
+	}
+	if config.SpeedUpAfter == 0 {
+		config.SpeedUpAfter = DefaultSpeedUpAfter
+	}
+	if config.ReceiptTimeout == 0 {
+		config.ReceiptTimeout = DefaultReceiptTimeout
+	}
+	if config.ReceiptPollInterval == 0 {
+		config.ReceiptPollInterval = DefaultReceiptPollInterval
+	}
+	if config.ConfirmRetries == 0 {
+		config.ConfirmRetries = DefaultConfirmRetries
+	}
+	if config.ConfirmRetryBackoff == 0 {
+		config.ConfirmRetryBackoff = DefaultConfirmRetryBackoff
+	}
+
+	walClient := NewWALClient(config.WALStore)
+	if config.WALEncryptionKey != "" {
+		if err := walClient.SetEncryptionKey(config.WALEncryptionKey); err != nil {
+			return nil, fmt.Errorf("invalid WAL encryption key: %w", err)
+		}
+	}
+
+	httpClient := NewHTTPClient(config.BackendURL, WithHTTPClient(config.HTTPClient), WithTracerProvider(config.TracerProvider))
+
+	schemaCacheDir := config.SchemaCacheDir
+	if schemaCacheDir == "" {
+		schemaCacheDir = DefaultSchemaCacheDir()
+	}
+	cachePath := schemaCachePath(schemaCacheDir, config.BackendURL)
+
+	newChain := config.ChainFactory
+	if newChain == nil {
+		newChain = NewChainOps
+	}
 
 	return &Minter{
-		config:     config,
-		httpClient: httpClient,
-		walClient:  NewWALClient(),
+		config:          config,
+		httpClient:      httpClient,
+		walClient:       walClient,
+		schemaCache:     loadSchemaCacheFile(cachePath, config.BackendURL),
+		schemaCachePath: cachePath,
+		signer:          signer,
+		newChain:        newChain,
+		tracer:          newTracer(config.TracerProvider),
 	}, nil
 }
 
-// Mint loads an agent config from JSON file and mints/syncs the agent
+// newAuthenticator creates an authenticator for the configured wallet and signing scheme
+func (m *Minter) newAuthenticator() *Authenticator {
+	return NewAuthenticatorWithSigner(m.signer, m.httpClient, WithEIP712Signing(m.config.EIP712Auth))
+}
+
+// Mint loads an agent config from a JSON or YAML file and mints/syncs the agent
 func (m *Minter) Mint(jsonPath string) (*MintResult, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
 	defer cancel()
@@ -110,90 +477,211 @@ func (m *Minter) Mint(jsonPath string) (*MintResult, error) {
 	return m.MintWithContext(ctx, jsonPath)
 }
 
-// MintWithContext loads an agent config from JSON file and mints/syncs with context
+// MintWithContext loads an agent config from a JSON or YAML file (by its
+// .yaml or .yml extension) and mints/syncs with context
 func (m *Minter) MintWithContext(ctx context.Context, jsonPath string) (*MintResult, error) {
-	log.Printf("📦 Loading agent config from: %s", jsonPath)
+	ctx, span := m.startSpan(ctx, SpanMint)
+	result, err := m.mintWithContext(ctx, jsonPath)
+	if result != nil {
+		span.SetAttributes(AttrAgentID.String(result.AgentID), tokenIDAttr(result.TokenID), AttrContractAddress.String(result.ContractAddress))
+		if result.TxHash != "" {
+			span.SetAttributes(AttrTxHash.String(result.TxHash))
+		}
+	}
+	endSpan(span, err)
+	return result, err
+}
+
+// mintWithContext runs MintWithContext inside its span
+func (m *Minter) mintWithContext(ctx context.Context, jsonPath string) (*MintResult, error) {
+	config, schemaVersion, err := m.loadConfig(ctx, jsonPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check WAL for pending operations. An entry that cannot be read may hold
+	// a pending mint, so minting again could mint twice.
+	wal, err := m.walClient.Load(config.AgentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read WAL for %s, set its encryption key or abandon the agent: %w", config.AgentID, err)
+	}
+	if wal != nil && wal.PendingTxHash != "" {
+		log.Printf("🔍 Found pending transaction in WAL: %s", wal.PendingTxHash)
+		return m.recoverFromWAL(ctx, wal, config)
+	}
+
+	// Generate the config hash in the version the backend expects
+	configHash := GenerateConfigHashForSchema(config, schemaVersion)
+	if len(configHash) >= 16 {
+		log.Printf("🔐 Config hash: %s", configHash[:16]+"...")
+	} else {
+		log.Printf("🔐 Config hash: %s", configHash)
+	}
+
+	// Proceed to sync
+	return m.syncAndMint(ctx, config, configHash, schemaVersion)
+}
+
+// ForceUpdate re-uploads an owned agent's metadata even when sync reports it
+// as SYNCED, e.g. after a lost IPFS pin or backend drift. It fails with
+// ErrAgentNotMinted if the agent has no NFT yet.
+func (m *Minter) ForceUpdate(ctx context.Context, jsonPath string) (*MintResult, error) {
+	config, schemaVersion, err := m.loadConfig(ctx, jsonPath)
+	if err != nil {
+		return nil, err
+	}
+
+	configHash := GenerateConfigHashForSchema(config, schemaVersion)
+
+	authenticator, syncResp, err := m.sync(ctx, config, configHash, schemaVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	switch syncResp.Status {
+	case "SYNCED", "UPDATE_REQUIRED":
+		if syncResp.Creator != "" && !strings.EqualFold(syncResp.Creator, authenticator.GetAddress()) {
+			return nil, fmt.Errorf("%w: agent %s is owned by %s", ErrForbidden, config.AgentID, syncResp.Creator)
+		}
+		log.Println("🔁 Forcing metadata update...")
+		return m.executeUpdate(ctx, config, configHash, syncResp)
+
+	case "MINT_REQUIRED", "RESUME_MINT":
+		// A fresh MINT_REQUIRED sync reserved the ID for us; release it
+		if syncResp.Status == "MINT_REQUIRED" {
+			m.abandonAfterFailure(config.AgentID, ErrAgentNotMinted)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrAgentNotMinted, config.AgentID)
+
+	default:
+		return nil, fmt.Errorf("unexpected sync status: %s", syncResp.Status)
+	}
+}
+
+// readConfigFile reads and parses an agent config file. Files ending in
+// .yaml or .yml are YAML and are converted to JSON first; size limits then
+// apply to the JSON equivalent. With interpolate, environment variables are
+// expanded before the config is parsed. It also returns the JSON the config
+// was parsed from, for schema validation.
+func readConfigFile(path string, interpolate, strictEnv bool) (*AgentConfig, []byte, error) {
+	isYAML := isYAMLPath(path)
+	maxFileSize := int64(DefaultMaxJSONSize)
+	if isYAML {
+		maxFileSize = MaxYAMLFileSize
+	}
 
 	// Step 1: Check file size (fast fail against default limit)
-	fileInfo, err := os.Stat(jsonPath)
+	fileInfo, err := os.Stat(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to stat file: %w", err)
+		return nil, nil, fmt.Errorf("failed to stat file: %w", err)
 	}
 
-	fileSize := fileInfo.Size()
-	if fileSize > DefaultMaxJSONSize {
-		return nil, fmt.Errorf("JSON file too large (max %d bytes, got %d)", DefaultMaxJSONSize, fileSize)
+	if fileSize := fileInfo.Size(); fileSize > maxFileSize {
+		if isYAML {
+			return nil, nil, fmt.Errorf("YAML file too large (max %d bytes, got %d)", maxFileSize, fileSize)
+		}
+		return nil, nil, fmt.Errorf("JSON file too large (max %d bytes, got %d)", DefaultMaxJSONSize, fileSize)
 	}
 
 	// Step 2: Read file
-	file, err := os.Open(jsonPath)
+	file, err := os.Open(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %w", err)
+		return nil, nil, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 
-	data, err := io.ReadAll(io.LimitReader(file, DefaultMaxJSONSize+1))
+	data, err := io.ReadAll(io.LimitReader(file, maxFileSize+1))
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+		return nil, nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if isYAML {
+		if data, err = yamlToJSON(data); err != nil {
+			return nil, nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+		if len(data) > DefaultMaxJSONSize {
+			return nil, nil, fmt.Errorf("YAML config too large as JSON (max %d bytes, got %d)", DefaultMaxJSONSize, len(data))
+		}
+	}
+	if interpolate {
+		if data, err = interpolateEnv(data, strictEnv); err != nil {
+			return nil, nil, fmt.Errorf("failed to interpolate environment variables: %w", err)
+		}
+		if len(data) > DefaultMaxJSONSize {
+			return nil, nil, fmt.Errorf("config too large after interpolation (max %d bytes, got %d)", DefaultMaxJSONSize, len(data))
+		}
 	}
 
 	// Step 3: Parse JSON
 	var config AgentConfig
 	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("invalid JSON: %w", err)
+		return nil, nil, fmt.Errorf("invalid JSON: %w", err)
 	}
+	config.Image = strings.TrimSpace(config.Image)
+	if config.Image, err = resolveImage(config.Image, filepath.Dir(path)); err != nil {
+		return nil, nil, fmt.Errorf("invalid image: %w", err)
+	}
+
+	return &config, data, nil
+}
+
+// loadConfig reads, parses and validates an agent config file, see
+// readConfigFile. It returns the backend schema version if the schema could
+// be fetched.
+func (m *Minter) loadConfig(ctx context.Context, jsonPath string) (*AgentConfig, string, error) {
+	log.Printf("📦 Loading agent config from: %s", jsonPath)
+
+	// Steps 1-3: Read, size-check and parse the file
+	var interpolate, strictEnv bool
+	if m.config != nil {
+		interpolate, strictEnv = m.config.InterpolateEnv, m.config.StrictEnv
+	}
+	config, data, err := readConfigFile(jsonPath, interpolate, strictEnv)
+	if err != nil {
+		return nil, "", err
+	}
+	fileSize := len(data)
 
 	// Step 4: Pre-validation (O(1) cheap checks)
-	if err := m.preValidate(&config); err != nil {
-		return nil, fmt.Errorf("pre-validation failed: %w", err)
+	if err := preValidate(config); err != nil {
+		return nil, "", fmt.Errorf("pre-validation failed: %w", err)
 	}
 
 	// Step 5: Fetch and verify schema (with caching)
+	schemaVersion := ""
 	schema, err := m.getSchema(ctx)
 	if err != nil {
 		log.Printf("⚠️ Warning: Failed to fetch schema: %v (proceeding with local validation)", err)
 	} else {
 		log.Printf("📋 Schema version: %s, max JSON size: %d bytes", schema.SchemaVersion, schema.MaxJSONSize)
+		schemaVersion = schema.SchemaVersion
 
 		// Validate file size against backend limit
-		if schema.MaxJSONSize > 0 && int(fileSize) > schema.MaxJSONSize {
-			return nil, fmt.Errorf("JSON file too large (backend limit: %d bytes, got %d)", schema.MaxJSONSize, fileSize)
+		if schema.MaxJSONSize > 0 && fileSize > schema.MaxJSONSize {
+			return nil, "", fmt.Errorf("JSON file too large (backend limit: %d bytes, got %d)", schema.MaxJSONSize, fileSize)
 		}
-	}
 
-	// Step 6: Full validation against schema
-	if err := m.validateConfig(&config); err != nil {
-		return nil, fmt.Errorf("validation failed: %w", err)
+		if err := validateAgainstSchema(data, schema); err != nil {
+			return nil, "", fmt.Errorf("schema validation failed: %w", err)
+		}
 	}
 
-	log.Printf("✅ Agent config validated: %s (%s)", config.Name, config.AgentID)
-
-	// Step 7: Check WAL for pending operations
-	wal, err := m.walClient.Load(config.AgentID)
-	if err == nil && wal != nil && wal.PendingTxHash != "" {
-		log.Printf("🔍 Found pending transaction in WAL: %s", wal.PendingTxHash)
-		return m.recoverFromWAL(ctx, wal, &config)
+	// Step 6: Full validation against schema
+	if err := validateConfig(config); err != nil {
+		return nil, "", fmt.Errorf("validation failed: %w", err)
 	}
 
-	// Step 8: Generate config hash
-	configHash := GenerateConfigHash(&config)
-	if len(configHash) >= 16 {
-		log.Printf("🔐 Config hash: %s", configHash[:16]+"...")
-	} else {
-		log.Printf("🔐 Config hash: %s", configHash)
+	// Step 7: Optionally fetch and check the MCP manifest
+	if err := m.verifyMcpManifest(ctx, config); err != nil {
+		return nil, "", fmt.Errorf("mcp manifest verification failed: %w", err)
 	}
 
-	// Step 9: Proceed to sync
-	schemaVersion := ""
-	if schema != nil {
-		schemaVersion = schema.SchemaVersion
-	}
+	log.Printf("✅ Agent config validated: %s (%s)", config.Name, config.AgentID)
 
-	return m.syncAndMint(ctx, &config, configHash, schemaVersion)
+	return config, schemaVersion, nil
 }
 
 // preValidate performs cheap O(1) checks before full validation
-func (m *Minter) preValidate(config *AgentConfig) error {
+func preValidate(config *AgentConfig) error {
 	// Check required top-level fields
 	if config.Name == "" {
 		return fmt.Errorf("name is required")
@@ -212,11 +700,24 @@ func (m *Minter) preValidate(config *AgentConfig) error {
 		}
 	}
 
+	// Check capability name and command trigger formats. Missing names are
+	// reported by validateConfig.
+	for i, cap := range config.Capabilities {
+		if cap.Name != "" && !capabilityNamePattern.MatchString(cap.Name) {
+			return fmt.Errorf("capability %d: name must match %s (lowercase letters, numbers, '_' and '-', namespaced with '/'), got %q", i+1, capabilityNamePattern, cap.Name)
+		}
+	}
+	for i, cmd := range config.Commands {
+		if cmd.Trigger != "" && !commandTriggerPattern.MatchString(cmd.Trigger) {
+			return fmt.Errorf("command %d: trigger must match %s (lowercase letters, numbers, '_' and '-'), got %q", i+1, commandTriggerPattern, cmd.Trigger)
+		}
+	}
+
 	return nil
 }
 
 // validateConfig performs full validation against schema
-func (m *Minter) validateConfig(config *AgentConfig) error {
+func validateConfig(config *AgentConfig) error {
 	// Name validation
 	if len(config.Name) < 3 {
 		return fmt.Errorf("name must be at least 3 characters")
@@ -231,8 +732,8 @@ func (m *Minter) validateConfig(config *AgentConfig) error {
 	}
 
 	// AgentID validation
-	if len(config.AgentID) > 64 {
-		return fmt.Errorf("agentId must not exceed 64 characters")
+	if len(config.AgentID) > MaxAgentIDLength {
+		return fmt.Errorf("agentId must not exceed %d characters", MaxAgentIDLength)
 	}
 
 	// Description validation
@@ -246,6 +747,11 @@ func (m *Minter) validateConfig(config *AgentConfig) error {
 		return fmt.Errorf("description must not contain HTML tags")
 	}
 
+	// Image validation (optional)
+	if err := validateImage(config.Image); err != nil {
+		return err
+	}
+
 	// AgentType validation
 	validTypes := map[string]bool{"command": true, "nlp": true, "mcp": true}
 	if !validTypes[config.AgentType] {
@@ -268,6 +774,7 @@ func (m *Minter) validateConfig(config *AgentConfig) error {
 		return fmt.Errorf("maximum 50 capabilities allowed")
 	}
 
+	capabilityNames := make(map[string]int, len(config.Capabilities))
 	for i, cap := range config.Capabilities {
 		if cap.Name == "" {
 			return fmt.Errorf("capability %d: name is required", i+1)
@@ -275,6 +782,10 @@ func (m *Minter) validateConfig(config *AgentConfig) error {
 		if len(cap.Name) > 100 {
 			return fmt.Errorf("capability %d: name must not exceed 100 characters", i+1)
 		}
+		if first, ok := capabilityNames[cap.Name]; ok {
+			return fmt.Errorf("capability %d: duplicate name '%s' (also capability %d)", i+1, cap.Name, first)
+		}
+		capabilityNames[cap.Name] = i + 1
 		if len(cap.Description) > 500 {
 			return fmt.Errorf("capability %d: description must not exceed 500 characters", i+1)
 		}
@@ -285,6 +796,7 @@ func (m *Minter) validateConfig(config *AgentConfig) error {
 		return fmt.Errorf("maximum 100 commands allowed")
 	}
 
+	triggers := make(map[string]int, len(config.Commands))
 	for i, cmd := range config.Commands {
 		if cmd.Trigger == "" {
 			return fmt.Errorf("command %d: trigger is required", i+1)
@@ -292,9 +804,19 @@ func (m *Minter) validateConfig(config *AgentConfig) error {
 		if len(cmd.Trigger) > 100 {
 			return fmt.Errorf("command %d: trigger must not exceed 100 characters", i+1)
 		}
+		if first, ok := triggers[cmd.Trigger]; ok {
+			return fmt.Errorf("command %d: duplicate trigger '%s' (also command %d)", i+1, cmd.Trigger, first)
+		}
+		triggers[cmd.Trigger] = i + 1
 		if len(cmd.Description) > 500 {
 			return fmt.Errorf("command %d: description must not exceed 500 characters", i+1)
 		}
+		if err := validateCommandParameters(cmd.Parameters); err != nil {
+			return fmt.Errorf("command %d: %w", i+1, err)
+		}
+		if err := validateCommandPricing(cmd); err != nil {
+			return fmt.Errorf("command %d: %w", i+1, err)
+		}
 	}
 
 	// MCP manifest validation
@@ -308,19 +830,26 @@ func (m *Minter) validateConfig(config *AgentConfig) error {
 // getSchema fetches the validation schema from backend
 func (m *Minter) getSchema(ctx context.Context) (*SchemaResponse, error) {
 	// Check cache
-	if m.schemaCache != nil && time.Since(m.schemaCache.FetchedAt) < time.Hour {
+	if m.schemaCache != nil && time.Since(m.schemaCache.FetchedAt) < schemaCacheTTL {
 		return m.schemaCache.Schema, nil
 	}
 
 	// Fetch from backend
-	schema, err := m.httpClient.GetSchema()
+	schema, err := m.httpClient.GetSchemaCtx(ctx)
 	if err != nil {
 		// Use stale cache if available
 		if m.schemaCache != nil {
 			log.Printf("⚠️ Using stale schema cache")
 			return m.schemaCache.Schema, nil
 		}
-		return nil, err
+
+		// Fall back to the offline snapshot so validation still follows backend rules
+		snapshot, snapshotErr := loadSchemaSnapshot(m.config.SchemaSnapshotPath)
+		if snapshotErr != nil {
+			return nil, fmt.Errorf("%w (snapshot fallback: %v)", err, snapshotErr)
+		}
+		log.Printf("⚠️ Schema fetch failed (%v), using schema snapshot version %s", err, snapshot.SchemaVersion)
+		return snapshot, nil
 	}
 
 	// Update cache
@@ -328,47 +857,20 @@ func (m *Minter) getSchema(ctx context.Context) (*SchemaResponse, error) {
 		Schema:    schema,
 		FetchedAt: time.Now(),
 	}
+	if err := saveSchemaCacheFile(m.schemaCachePath, m.config.BackendURL, m.schemaCache); err != nil {
+		log.Printf("⚠️ Failed to persist schema cache: %v", err)
+	}
 
 	return schema, nil
 }
 
 // syncAndMint performs the sync and mint flow
 func (m *Minter) syncAndMint(ctx context.Context, config *AgentConfig, configHash, schemaVersion string) (*MintResult, error) {
-	// Create authenticator
-	authenticator, err := NewAuthenticator(m.config.PrivateKey, m.httpClient)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create authenticator: %w", err)
-	}
-
-	// Get challenge
-	log.Println("🔐 Getting authentication challenge...")
-	challenge, err := m.httpClient.GetChallenge(authenticator.GetAddress())
-	if err != nil {
-		return nil, fmt.Errorf("failed to get challenge: %w", err)
-	}
-
-	// Sign challenge
-	signature, err := authenticator.SignChallenge(challenge)
+	authenticator, syncResp, err := m.sync(ctx, config, configHash, schemaVersion)
 	if err != nil {
-		return nil, fmt.Errorf("failed to sign challenge: %w", err)
-	}
-
-	// Call sync endpoint
-	log.Println("🔄 Syncing with backend...")
-	syncResp, err := m.httpClient.Sync(&SyncRequest{
-		Wallet:        authenticator.GetAddress(),
-		AgentID:       config.AgentID,
-		ConfigHash:    configHash,
-		Challenge:     challenge,
-		Signature:     signature,
-		SchemaVersion: schemaVersion,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("sync failed: %w", err)
+		return nil, err
 	}
 
-	log.Printf("📋 Sync status: %s", syncResp.Status)
-
 	switch syncResp.Status {
 	case "SYNCED":
 		log.Println("✅ Agent already synced!")
@@ -389,18 +891,86 @@ func (m *Minter) syncAndMint(ctx context.Context, config *AgentConfig, configHas
 
 	case "MINT_REQUIRED", "RESUME_MINT":
 		log.Println("💰 Minting required, proceeding...")
-		return m.executeMint(ctx, config, authenticator, configHash)
+		var result *MintResult
+		err := m.preflightBalance(ctx)
+		if err == nil {
+			result, err = m.executeMint(ctx, config, authenticator, configHash)
+		}
+		// Only a reservation created by this sync is ours to release
+		if err != nil && syncResp.Status == "MINT_REQUIRED" {
+			m.abandonAfterFailure(config.AgentID, err)
+		}
+		return result, err
 
 	default:
 		return nil, fmt.Errorf("unexpected sync status: %s", syncResp.Status)
 	}
 }
 
+// sync authenticates and asks the backend what the agent needs next
+func (m *Minter) sync(ctx context.Context, config *AgentConfig, configHash, schemaVersion string) (*Authenticator, *SyncResponse, error) {
+	// Create authenticator
+	authenticator := m.newAuthenticator()
+
+	// Get and sign a challenge
+	log.Println("🔐 Getting authentication challenge...")
+	challenge, signature, err := m.signedChallenge(ctx, authenticator)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Call sync endpoint
+	log.Println("🔄 Syncing with backend...")
+	syncCtx, span := m.startSpan(ctx, SpanSync, trace.WithAttributes(AttrAgentID.String(config.AgentID)))
+	syncResp, err := m.httpClient.SyncCtx(syncCtx, &SyncRequest{
+		Wallet:        authenticator.GetAddress(),
+		AgentID:       config.AgentID,
+		ConfigHash:    configHash,
+		Challenge:     challenge,
+		Signature:     signature,
+		SignatureType: authenticator.SignatureType(),
+		SchemaVersion: schemaVersion,
+	})
+	endSpan(span, err)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sync failed: %w", err)
+	}
+
+	log.Printf("📋 Sync status: %s", syncResp.Status)
+	return authenticator, syncResp, nil
+}
+
+// signedChallenge requests an auth challenge and signs it
+func (m *Minter) signedChallenge(ctx context.Context, authenticator *Authenticator) (string, string, error) {
+	ctx, span := m.startSpan(ctx, SpanAuth)
+	challenge, err := m.httpClient.GetChallengeCtx(ctx, authenticator.GetAddress())
+	if err != nil {
+		err = fmt.Errorf("failed to get challenge: %w", err)
+		endSpan(span, err)
+		return "", "", err
+	}
+
+	signature, err := authenticator.SignChallenge(challenge)
+	if err != nil {
+		err = fmt.Errorf("failed to sign challenge: %w", err)
+	}
+	endSpan(span, err)
+	return challenge, signature, err
+}
+
+// authenticate signs in to the backend and returns a session token
+func (m *Minter) authenticate(ctx context.Context, authenticator *Authenticator) (string, error) {
+	ctx, span := m.startSpan(ctx, SpanAuth)
+	sessionToken, _, err := authenticator.AuthenticateCtx(ctx)
+	endSpan(span, err)
+	return sessionToken, err
+}
+
 // executeMint performs the actual minting operation
 func (m *Minter) executeMint(ctx context.Context, config *AgentConfig, authenticator *Authenticator, configHash string) (*MintResult, error) {
 	// Authenticate for deploy endpoint
 	log.Println("🔐 Authenticating for deploy...")
-	sessionToken, _, err := authenticator.Authenticate()
+	sessionToken, err := m.authenticate(ctx, authenticator)
 	if err != nil {
 		return nil, fmt.Errorf("authentication failed: %w", err)
 	}
@@ -427,8 +997,21 @@ func (m *Minter) executeMint(ctx context.Context, config *AgentConfig, authentic
 
 	// Call deploy endpoint
 	log.Println("📤 Storing metadata and getting mint signature...")
-	deployResp, err := m.httpClient.Deploy(sessionToken, deployReq)
+	deployCtx, deploySpan := m.startSpan(ctx, SpanDeployCall, trace.WithAttributes(AttrAgentID.String(config.AgentID)))
+	deployResp, err := m.httpClient.DeployCtx(deployCtx, sessionToken, deployReq)
+	endSpan(deploySpan, err)
 	if err != nil {
+		if errors.Is(err, ErrAgentExists) {
+			if m.config.FailOnDeployConflict {
+				return nil, &keepReservationError{fmt.Errorf("deploy failed: %w", err)}
+			}
+			log.Println("⚠️ Agent already exists, checking ownership via sync...")
+			result, err := m.resolveDeployConflict(ctx, config, authenticator, configHash, err)
+			if err != nil {
+				return nil, &keepReservationError{err}
+			}
+			return result, nil
+		}
 		return nil, fmt.Errorf("deploy failed: %w", err)
 	}
 
@@ -438,6 +1021,10 @@ func (m *Minter) executeMint(ctx context.Context, config *AgentConfig, authentic
 		log.Printf("✅ Deploy prepared, config hash: %s", deployResp.ConfigHash)
 	}
 
+	if err := checkExpectedContract("backend", m.config.ExpectedContract, m.config.ExpectedChainID, deployResp.ContractAddress, deployResp.ChainID); err != nil {
+		return nil, err
+	}
+
 	// Use RPC URL from backend response, fallback to config/env/default
 	rpcEndpoint := deployResp.RPCURL
 	if rpcEndpoint == "" {
@@ -464,15 +1051,38 @@ func (m *Minter) executeMint(ctx context.Context, config *AgentConfig, authentic
 
 	// Execute on-chain mint
 	log.Println("⛓️ Executing on-chain mint...")
-	chainClient, err := NewChainClient(rpcEndpoint, deployResp.ContractAddress, deployResp.ChainID, m.config.PrivateKey)
+	chainClient, err := m.newChain(rpcEndpoint, deployResp.ContractAddress, deployResp.ChainID, m.signer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create chain client: %w", err)
 	}
 	defer chainClient.Close()
+	chainClient.SetSpeedUpAfter(m.config.SpeedUpAfter)
+	chainClient.SetReceiptWait(m.config.ReceiptTimeout, m.config.ReceiptPollInterval)
+	chainClient.SetOnTxSent(func(txHash string, nonce uint64) {
+		// Record the broadcast so recovery can find the tx or tell it was dropped
+		wal.recordSentTx(txHash, nonce)
+		if err := m.walClient.Save(wal); err != nil {
+			log.Printf("⚠️ Warning: Failed to save WAL: %v", err)
+		}
+	})
 
-	mintResult, err := chainClient.ExecuteMint(ctx, deployResp.Signature, nil)
+	if m.config.VerifyMintSignature {
+		if err := chainClient.VerifyMintSignature(ctx, deployResp.Signature, deployResp.Nonce); err != nil {
+			// Nothing was sent, so there is nothing for WAL recovery to find
+			m.walClient.Delete(config.AgentID)
+			return nil, fmt.Errorf("refusing to mint: %w", err)
+		}
+	}
+
+	mintCtx, mintSpan := m.startSpan(ctx, SpanOnChainMint, trace.WithAttributes(AttrContractAddress.String(deployResp.ContractAddress)))
+	mintResult, err := chainClient.ExecuteMint(mintCtx, deployResp.Signature, m.config.MintPrice)
+	if err == nil {
+		mintSpan.SetAttributes(AttrTxHash.String(mintResult.TxHash), tokenIDAttr(mintResult.TokenID))
+	}
+	endSpan(mintSpan, err)
 	if err != nil {
-		return nil, fmt.Errorf("on-chain mint failed: %w", err)
+		// The transaction may already be broadcast; leave it to WAL recovery
+		return nil, &keepReservationError{fmt.Errorf("on-chain mint failed: %w", err)}
 	}
 
 	log.Printf("✅ Mint successful! Token ID: %d, Tx: %s", mintResult.TokenID, mintResult.TxHash)
@@ -486,56 +1096,119 @@ func (m *Minter) executeMint(ctx context.Context, config *AgentConfig, authentic
 
 	// Confirm with backend (IPFS upload + tokenURI update happens server-side)
 	log.Println("💾 Confirming with backend...")
-	
+
 	// Validate token ID fits in int64 before conversion
 	if mintResult.TokenID > math.MaxInt64 {
-		return nil, fmt.Errorf("token ID %d exceeds int64 maximum", mintResult.TokenID)
-	}
-	
-	confirmReq := &ConfirmMintRequest{
-		AgentID:       config.AgentID,
-		WalletAddress: authenticator.GetAddress(),
-		TokenID:       int64(mintResult.TokenID),
-		TxHash:        mintResult.TxHash,
-		ConfigHash:    configHash,
+		return nil, &keepReservationError{fmt.Errorf("token ID %d exceeds int64 maximum", mintResult.TokenID)}
 	}
 
-	_, err = m.httpClient.ConfirmMint(sessionToken, confirmReq)
-	if err != nil {
-		log.Printf("⚠️ Warning: Confirm-mint failed: %v (agent minted, will reconcile later)", err)
-	} else {
-		log.Println("✅ Agent confirmed in database!")
+	message := "Agent minted successfully"
+	if err := m.confirmMintedWithRetry(ctx, sessionToken, config.AgentID, authenticator.GetAddress(), mintResult.TokenID, mintResult.TxHash, configHash); err != nil {
+		// The WAL stays in CONFIRMING so the next Mint or Reconcile retries
+		log.Printf("⚠️ Warning: %v (agent minted, run Reconcile to confirm it)", err)
+		message = "Agent minted, backend confirmation pending"
 	}
 
-	// Clean up WAL
-	m.walClient.Delete(config.AgentID)
-
 	return &MintResult{
 		TokenID:         mintResult.TokenID,
 		AgentID:         config.AgentID,
 		Status:          MintStatusMinted,
 		ContractAddress: deployResp.ContractAddress,
 		TxHash:          mintResult.TxHash,
-		Message:         "Agent minted successfully",
+		Message:         message,
 	}, nil
 }
 
+// abandonAfterFailure releases the reservation created for a mint that failed
+// before reaching the chain, unless configured to keep it
+func (m *Minter) abandonAfterFailure(agentID string, mintErr error) {
+	if m.config.KeepReservationOnFailure {
+		return
+	}
+	var keep *keepReservationError
+	if errors.As(mintErr, &keep) {
+		return
+	}
+
+	log.Printf("🧹 Mint failed before reaching the chain, abandoning reservation for %s...", agentID)
+	if err := m.Abandon(agentID); err != nil {
+		log.Printf("⚠️ Warning: Failed to abandon reservation: %v", err)
+	}
+}
+
+// resolveDeployConflict re-syncs after a deploy conflict to tell an idempotent
+// re-run (agent already owned by this wallet) apart from an agent ID that
+// belongs to another wallet.
+func (m *Minter) resolveDeployConflict(ctx context.Context, config *AgentConfig, authenticator *Authenticator, configHash string, deployErr error) (*MintResult, error) {
+	challenge, err := m.httpClient.GetChallengeCtx(ctx, authenticator.GetAddress())
+	if err != nil {
+		return nil, fmt.Errorf("deploy failed: %w (ownership check failed: %w)", deployErr, err)
+	}
+
+	signature, err := authenticator.SignChallenge(challenge)
+	if err != nil {
+		return nil, fmt.Errorf("deploy failed: %w (ownership check failed: %w)", deployErr, err)
+	}
+
+	syncResp, err := m.httpClient.SyncCtx(ctx, &SyncRequest{
+		Wallet:        authenticator.GetAddress(),
+		AgentID:       config.AgentID,
+		ConfigHash:    configHash,
+		Challenge:     challenge,
+		Signature:     signature,
+		SignatureType: authenticator.SignatureType(),
+	})
+	if err != nil {
+		// A failed lookup says nothing about the owner, so it is not reported
+		// as a foreign agent
+		return nil, fmt.Errorf("deploy failed: %w (ownership check failed: %w)", deployErr, err)
+	}
+
+	if syncResp.Creator != "" && !strings.EqualFold(syncResp.Creator, authenticator.GetAddress()) {
+		return nil, fmt.Errorf("deploy failed: %w (agent is owned by %s)", deployErr, syncResp.Creator)
+	}
+
+	log.Printf("📋 Sync status after conflict: %s", syncResp.Status)
+
+	switch syncResp.Status {
+	case "SYNCED":
+		if syncResp.TokenID == nil {
+			return nil, fmt.Errorf("backend returned SYNCED status but no token_id")
+		}
+		log.Println("✅ Agent already owned by this wallet")
+		return &MintResult{
+			TokenID:         uint64(*syncResp.TokenID),
+			AgentID:         config.AgentID,
+			Status:          MintStatusAlreadyOwned,
+			ContractAddress: syncResp.ContractAddress,
+			Message:         "Agent already exists and is owned by this wallet",
+		}, nil
+
+	case "UPDATE_REQUIRED":
+		log.Printf("⚠️ Agent owned by this wallet but config changed, auto-updating...")
+		return m.executeUpdate(ctx, config, configHash, syncResp)
+
+	default:
+		return nil, fmt.Errorf("deploy failed: %w (sync status after conflict: %s)", deployErr, syncResp.Status)
+	}
+}
+
 // executeUpdate handles automatic metadata re-upload when config changes
 func (m *Minter) executeUpdate(ctx context.Context, config *AgentConfig, configHash string, syncResp *SyncResponse) (*MintResult, error) {
 	// 1. Create authenticator
-	authenticator, err := NewAuthenticator(m.config.PrivateKey, m.httpClient)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create authenticator: %w", err)
-	}
+	authenticator := m.newAuthenticator()
 
 	// 2. Authenticate to get session token
 	log.Println("🔐 Authenticating for metadata update...")
-	sessionToken, _, err := authenticator.Authenticate()
+	sessionToken, err := m.authenticate(ctx, authenticator)
 	if err != nil {
 		return nil, fmt.Errorf("authentication failed: %w", err)
 	}
 
-	// 3. Convert config to UpdateMetadataRequest
+	// 3. Explain what changed since the backend's last-known config
+	changes := m.configChanges(ctx, authenticator.GetAddress(), config)
+
+	// 4. Convert config to UpdateMetadataRequest
 	capabilitiesJSON, _ := json.Marshal(config.Capabilities)
 	commandsJSON, _ := json.Marshal(config.Commands)
 	categoriesJSON, _ := json.Marshal(config.Categories)
@@ -555,19 +1228,19 @@ func (m *Minter) executeUpdate(ctx context.Context, config *AgentConfig, configH
 		MetadataVersion: config.MetadataVersion,
 	}
 
-	// 4. Call update endpoint
+	// 5. Call update endpoint
 	log.Println("📤 Uploading updated metadata to IPFS and updating on-chain...")
-	updateResp, err := m.httpClient.UpdateMetadata(sessionToken, updateReq)
+	updateResp, err := m.httpClient.UpdateMetadataCtx(ctx, sessionToken, updateReq)
 	if err != nil {
 		return nil, fmt.Errorf("metadata update failed: %w", err)
 	}
 
 	log.Printf("✅ Metadata updated: IPFS=%s, TxHash=%s", updateResp.IpfsHash, updateResp.TxHash)
 
-	// 5. Re-sync to verify SYNCED status
+	// 6. Re-sync to verify SYNCED status
 	log.Println("🔄 Verifying update with re-sync...")
 	// Get new challenge for re-sync
-	challenge, err := m.httpClient.GetChallenge(authenticator.GetAddress())
+	challenge, err := m.httpClient.GetChallengeCtx(ctx, authenticator.GetAddress())
 	if err != nil {
 		// Update succeeded, but re-sync failed - still return success
 		log.Printf("⚠️ Re-sync challenge failed: %v (update was successful)", err)
@@ -581,7 +1254,7 @@ func (m *Minter) executeUpdate(ctx context.Context, config *AgentConfig, configH
 			ContractAddress: syncResp.ContractAddress,
 			Status:          MintStatusUpdated,
 			TxHash:          updateResp.TxHash,
-			Message:         "Agent metadata updated successfully",
+			Message:         updateMessage(changes),
 		}, nil
 	}
 
@@ -599,22 +1272,26 @@ func (m *Minter) executeUpdate(ctx context.Context, config *AgentConfig, configH
 			ContractAddress: syncResp.ContractAddress,
 			Status:          MintStatusUpdated,
 			TxHash:          updateResp.TxHash,
-			Message:         "Agent metadata updated successfully",
+			Message:         updateMessage(changes),
 		}, nil
 	}
 
-	reSyncResp, err := m.httpClient.Sync(&SyncRequest{
-		Wallet:     authenticator.GetAddress(),
-		AgentID:    config.AgentID,
-		ConfigHash: configHash,
-		Challenge:  challenge,
-		Signature:  signature,
+	reSyncResp, err := m.httpClient.SyncCtx(ctx, &SyncRequest{
+		Wallet:        authenticator.GetAddress(),
+		AgentID:       config.AgentID,
+		ConfigHash:    configHash,
+		Challenge:     challenge,
+		Signature:     signature,
+		SignatureType: authenticator.SignatureType(),
 	})
 
 	if err != nil {
 		log.Printf("⚠️ Re-sync failed: %v (update was successful)", err)
 	} else {
 		log.Printf("✅ Re-sync status: %s", reSyncResp.Status)
+		if err := checkUpdateLoop(config, configHash, syncResp, reSyncResp); err != nil {
+			return nil, err
+		}
 	}
 
 	var tokenID uint64
@@ -632,7 +1309,7 @@ func (m *Minter) executeUpdate(ctx context.Context, config *AgentConfig, configH
 		ContractAddress: syncResp.ContractAddress,
 		Status:          MintStatusUpdated,
 		TxHash:          updateResp.TxHash,
-		Message:         "Agent metadata updated successfully",
+		Message:         updateMessage(changes),
 	}, nil
 }
 
@@ -640,6 +1317,10 @@ func (m *Minter) executeUpdate(ctx context.Context, config *AgentConfig, configH
 func (m *Minter) recoverFromWAL(ctx context.Context, wal *WALEntry, config *AgentConfig) (*MintResult, error) {
 	log.Printf("🔄 Recovering from WAL state: %s", wal.State)
 
+	if err := checkExpectedContract("WAL", m.config.ExpectedContract, m.config.ExpectedChainID, wal.ContractAddress, wal.ChainID); err != nil {
+		return nil, err
+	}
+
 	// Use RPC URL from WAL (saved from deploy response), fallback to config
 	rpcEndpoint := wal.RPCURL
 	if rpcEndpoint == "" {
@@ -647,22 +1328,32 @@ func (m *Minter) recoverFromWAL(ctx context.Context, wal *WALEntry, config *Agen
 	}
 
 	// Create chain client
-	chainClient, err := NewChainClient(rpcEndpoint, wal.ContractAddress, wal.ChainID, m.config.PrivateKey)
+	chainClient, err := m.newChain(rpcEndpoint, wal.ContractAddress, wal.ChainID, m.signer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create chain client: %w", err)
 	}
 	defer chainClient.Close()
 
 	// Check transaction receipt
-	if wal.PendingTxHash != "" {
-		log.Printf("🔍 Checking transaction: %s", wal.PendingTxHash)
+	if hashes := wal.pendingTxHashes(); len(hashes) > 0 {
+		log.Printf("🔍 Checking transaction: %s", strings.Join(hashes, ", "))
 
-		receipt, err := chainClient.GetTransactionReceipt(ctx, wal.PendingTxHash)
+		receipt, minedHash, err := m.waitForPendingReceipt(ctx, chainClient, hashes)
 		if err != nil {
-			log.Printf("⚠️ Transaction not found or pending: %v", err)
-			// Transaction might be pending or dropped - wait or retry
-			return nil, fmt.Errorf("pending transaction status unknown, please check: %s", wal.PendingTxHash)
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if !m.pendingTxDropped(ctx, chainClient, wal) {
+				log.Printf("⚠️ Transaction not found or pending: %v", err)
+				return nil, fmt.Errorf("pending transaction status unknown, please check: %s", strings.Join(hashes, ", "))
+			}
+
+			// Dropped from the mempool: nothing can mine it any more, start over
+			log.Printf("🗑️ Transaction %s was dropped, cleaning up WAL and minting again...", strings.Join(hashes, ", "))
+			m.walClient.Delete(config.AgentID)
+			return m.syncAndMint(ctx, config, wal.ConfigHash, "")
 		}
+		wal.PendingTxHash = minedHash
 
 		if receipt.Status == 1 {
 			// Transaction succeeded
@@ -678,32 +1369,17 @@ func (m *Minter) recoverFromWAL(ctx context.Context, wal *WALEntry, config *Agen
 				tokenID = &extractedID
 			}
 
-			// Confirm with backend (IPFS upload + tokenURI update happens server-side)
-			authenticator, err := NewAuthenticator(m.config.PrivateKey, m.httpClient)
-			if err != nil {
-				return nil, fmt.Errorf("failed to create authenticator: %w", err)
-			}
+			// Confirm with backend (IPFS upload + tokenURI update happens server-side).
+			// A failed confirm keeps the WAL so it is retried next time.
+			authenticator := m.newAuthenticator()
 
-			sessionToken, _, err := authenticator.Authenticate()
+			sessionToken, err := m.authenticate(ctx, authenticator)
 			if err != nil {
 				log.Printf("⚠️ Warning: Failed to authenticate for confirm: %v", err)
-			} else {
-				confirmReq := &ConfirmMintRequest{
-					AgentID:       config.AgentID,
-					WalletAddress: wal.Wallet,
-					TokenID:       int64(*tokenID),
-					TxHash:        wal.PendingTxHash,
-					ConfigHash:    wal.ConfigHash,
-				}
-
-				if _, err := m.httpClient.ConfirmMint(sessionToken, confirmReq); err != nil {
-					log.Printf("⚠️ Warning: Confirm-mint failed: %v", err)
-				}
+			} else if err := m.confirmMintedWithRetry(ctx, sessionToken, config.AgentID, wal.Wallet, *tokenID, wal.PendingTxHash, wal.ConfigHash); err != nil {
+				log.Printf("⚠️ Warning: %v", err)
 			}
 
-			// Clean up WAL
-			m.walClient.Delete(config.AgentID)
-
 			return &MintResult{
 				TokenID:         *tokenID,
 				AgentID:         config.AgentID,
@@ -723,10 +1399,186 @@ func (m *Minter) recoverFromWAL(ctx context.Context, wal *WALEntry, config *Agen
 	return m.syncAndMint(ctx, config, wal.ConfigHash, "")
 }
 
-// GenerateConfigHash generates a canonical hash of the agent config.
-// Image is deliberately excluded — image changes are cosmetic, not functional.
-// Includes: agentId, name, description, agentType, capabilities, nlpFallback, categories, command triggers+prices
+// waitForPendingReceipt polls for the receipt of any of the transactions sent
+// for a WAL's nonce until PendingTxTimeout elapses. It returns the receipt
+// with the hash of the mined transaction, or the last lookup error on timeout.
+func (m *Minter) waitForPendingReceipt(ctx context.Context, chainClient ChainOps, hashes []string) (*types.Receipt, string, error) {
+	deadline := time.Now().Add(m.config.PendingTxTimeout)
+	for {
+		receipt, hash, err := findReceipt(ctx, chainClient, hashes)
+		if err == nil {
+			return receipt, hash, nil
+		}
+		if !time.Now().Add(m.config.PendingTxPollInterval).Before(deadline) {
+			return nil, "", err
+		}
+
+		log.Printf("⏳ Waiting for transaction %s...", strings.Join(hashes, ", "))
+		select {
+		case <-ctx.Done():
+			return nil, "", ctx.Err()
+		case <-time.After(m.config.PendingTxPollInterval):
+		}
+	}
+}
+
+// findReceipt returns the first receipt found for hashes with its hash, or
+// the last lookup error if none of them is mined
+func findReceipt(ctx context.Context, chainClient ChainOps, hashes []string) (*types.Receipt, string, error) {
+	var lastErr error
+	for _, hash := range hashes {
+		receipt, err := chainClient.GetTransactionReceipt(ctx, hash)
+		if err == nil {
+			return receipt, hash, nil
+		}
+		lastErr = err
+	}
+	return nil, "", lastErr
+}
+
+// pendingTxDropped reports whether every transaction sent for a WAL's nonce
+// was dropped. A mined transaction is never dropped, so a speed-up whose
+// original was mined is not restarted. Entries without a recorded nonce are
+// never treated as dropped.
+func (m *Minter) pendingTxDropped(ctx context.Context, chainClient ChainOps, wal *WALEntry) bool {
+	if wal.PendingNonce == nil {
+		return false
+	}
+
+	for _, hash := range wal.pendingTxHashes() {
+		dropped, err := chainClient.IsTransactionDropped(ctx, hash, *wal.PendingNonce)
+		if err != nil {
+			log.Printf("⚠️ Could not check whether %s was dropped: %v", hash, err)
+			return false
+		}
+		if !dropped {
+			return false
+		}
+	}
+	return true
+}
+
+// Config hash versions. The backend advertises the version it expects through
+// the schema endpoint; backends that predate v4 always use v3.
+const (
+	ConfigHashV3             = "v3"
+	ConfigHashV4             = "v4"
+	DefaultConfigHashVersion = ConfigHashV3
+)
+
+// ConfigHashVersion returns the config hash version for a backend schema
+// version such as "4", "v4" or "4.1.0". Empty or unrecognized versions map to
+// DefaultConfigHashVersion so older backends keep working.
+func ConfigHashVersion(schemaVersion string) string {
+	major := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(schemaVersion)), "v")
+	if i := strings.IndexByte(major, '.'); i >= 0 {
+		major = major[:i]
+	}
+	if n, err := strconv.Atoi(major); err == nil && n >= 4 {
+		return ConfigHashV4
+	}
+	return DefaultConfigHashVersion
+}
+
+// HashOptions selects a config hash variant. The zero value is the hash the
+// backend verifies: the default version with the image excluded.
+type HashOptions struct {
+	// SchemaVersion selects the hash version; see ConfigHashVersion
+	SchemaVersion string
+
+	// IncludeImage appends the image as a final "image:<value>" segment so that
+	// image-only changes are detected. The backend treats the image as cosmetic
+	// and never hashes it, so hashes with this set are for local change
+	// detection only and must not be sent to sync or deploy.
+	IncludeImage bool
+}
+
+// GenerateConfigHash generates the canonical hash the backend verifies, in the
+// default version. Use GenerateConfigHashForSchema when the backend schema
+// version is known.
 func GenerateConfigHash(config *AgentConfig) string {
+	return GenerateConfigHashWithOptions(config, HashOptions{})
+}
+
+// GenerateConfigHashForSchema generates the config hash the backend with the
+// given schema version expects
+func GenerateConfigHashForSchema(config *AgentConfig, schemaVersion string) string {
+	switch ConfigHashVersion(schemaVersion) {
+	case ConfigHashV4:
+		return generateConfigHashV4(config)
+	default:
+		return generateConfigHashV3(config)
+	}
+}
+
+// generateConfigHashV3 hashes the v3 canonical string
+func generateConfigHashV3(config *AgentConfig) string {
+	return GenerateConfigHashWithOptions(config, HashOptions{SchemaVersion: ConfigHashV3})
+}
+
+// generateConfigHashV4 hashes the v4 canonical string
+func generateConfigHashV4(config *AgentConfig) string {
+	return GenerateConfigHashWithOptions(config, HashOptions{SchemaVersion: ConfigHashV4})
+}
+
+// GenerateConfigHashWithOptions generates the config hash variant selected by opts
+func GenerateConfigHashWithOptions(config *AgentConfig, opts HashOptions) string {
+	return hashCanonical(strings.Join(canonicalHashParts(config, opts), "|"))
+}
+
+// canonicalHashParts returns the "|"-separated segments of the canonical
+// config string. It is the single source of truth for config hashing: both
+// GenerateConfigHash and computeConfigHash normalize their input to an
+// AgentConfig and call it.
+//
+// v3: v3|agentId|name|description|agentType|caps|nlpFallback|categories[|commands]
+// where caps and categories are sorted and comma-joined, and commands (only
+// present when the agent has any) are trigger:price[:params] sorted by trigger,
+// with params appended only for commands that declare them.
+//
+// v4 differs from v3 in that billing terms are hashed and every segment is
+// always present:
+// v4|agentId|name|description|agentType|caps|nlpFallback|categories|commands
+// where commands are trigger:price:priceType:taskUnit:params sorted by
+// trigger, and params is empty for commands without parameters.
+//
+// Image is excluded — image changes are cosmetic, not functional — unless
+// opts.IncludeImage appends a final image:<value> segment.
+func canonicalHashParts(config *AgentConfig, opts HashOptions) []string {
+	version := ConfigHashVersion(opts.SchemaVersion)
+	parts := canonicalConfigHeader(version, config)
+
+	// Include commands with prices (sorted by trigger for determinism)
+	commands := sortedCommands(config.Commands)
+	cmdParts := make([]string, len(commands))
+	for i, cmd := range commands {
+		price := strconv.FormatFloat(cmd.PricePerUnit, 'f', -1, 64)
+		switch version {
+		case ConfigHashV4:
+			cmdParts[i] = strings.Join([]string{
+				cmd.Trigger, price, cmd.PriceType, cmd.TaskUnit, canonicalParameters(cmd.Parameters),
+			}, ":")
+		default:
+			cmdParts[i] = cmd.Trigger + ":" + price
+			// Only appended when present so hashes of parameterless commands are unchanged
+			if len(cmd.Parameters) > 0 {
+				cmdParts[i] += ":" + canonicalParameters(cmd.Parameters)
+			}
+		}
+	}
+	if len(commands) > 0 || version == ConfigHashV4 {
+		parts = append(parts, strings.Join(cmdParts, ","))
+	}
+
+	if opts.IncludeImage {
+		parts = append(parts, "image:"+config.Image)
+	}
+
+	return parts
+}
+
+// canonicalConfigHeader returns the segments shared by every hash version
+func canonicalConfigHeader(version string, config *AgentConfig) []string {
 	// Sort capabilities alphabetically by name
 	capNames := make([]string, len(config.Capabilities))
 	for i, cap := range config.Capabilities {
@@ -739,9 +1591,8 @@ func GenerateConfigHash(config *AgentConfig) string {
 	copy(categories, config.Categories)
 	sort.Strings(categories)
 
-	// Build deterministic string (no image)
-	parts := []string{
-		"v3",
+	return []string{
+		version,
 		config.AgentID,
 		config.Name,
 		config.Description,
@@ -750,33 +1601,20 @@ func GenerateConfigHash(config *AgentConfig) string {
 		strconv.FormatBool(config.NlpFallback),
 		strings.Join(categories, ","),
 	}
+}
 
-	// Include commands with prices (sorted by trigger for determinism)
-	if len(config.Commands) > 0 {
-		// Build trigger:price pairs sorted by trigger
-		type cmdEntry struct {
-			trigger string
-			price   string
-		}
-		entries := make([]cmdEntry, len(config.Commands))
-		for i, cmd := range config.Commands {
-			entries[i] = cmdEntry{
-				trigger: cmd.Trigger,
-				price:   strconv.FormatFloat(cmd.PricePerUnit, 'f', -1, 64),
-			}
-		}
-		sort.Slice(entries, func(i, j int) bool {
-			return entries[i].trigger < entries[j].trigger
-		})
-
-		cmdParts := make([]string, len(entries))
-		for i, e := range entries {
-			cmdParts[i] = e.trigger + ":" + e.price
-		}
-		parts = append(parts, strings.Join(cmdParts, ","))
-	}
+// sortedCommands returns a copy of commands sorted by trigger
+func sortedCommands(commands []Command) []Command {
+	sorted := make([]Command, len(commands))
+	copy(sorted, commands)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Trigger < sorted[j].Trigger
+	})
+	return sorted
+}
 
-	data := strings.Join(parts, "|")
+// hashCanonical returns the hex SHA-256 of a canonical config string
+func hashCanonical(data string) string {
 	hash := sha256.Sum256([]byte(data))
 	return hex.EncodeToString(hash[:])
 }
@@ -797,14 +1635,15 @@ func MintAgent(jsonPath string, config *MintConfig) (*MintResult, error) {
 
 // Abandon abandons an unminted agent reservation
 func (m *Minter) Abandon(agentID string) error {
+	return m.abandon(context.Background(), agentID)
+}
+
+func (m *Minter) abandon(ctx context.Context, agentID string) error {
 	// Create authenticator
-	authenticator, err := NewAuthenticator(m.config.PrivateKey, m.httpClient)
-	if err != nil {
-		return fmt.Errorf("failed to create authenticator: %w", err)
-	}
+	authenticator := m.newAuthenticator()
 
 	// Get challenge
-	challenge, err := m.httpClient.GetChallenge(authenticator.GetAddress())
+	challenge, err := m.httpClient.GetChallengeCtx(ctx, authenticator.GetAddress())
 	if err != nil {
 		return fmt.Errorf("failed to get challenge: %w", err)
 	}
@@ -817,13 +1656,14 @@ func (m *Minter) Abandon(agentID string) error {
 
 	// Call abandon endpoint
 	abandonReq := &AbandonRequest{
-		Wallet:    authenticator.GetAddress(),
-		AgentID:   agentID,
-		Challenge: challenge,
-		Signature: signature,
+		Wallet:        authenticator.GetAddress(),
+		AgentID:       agentID,
+		Challenge:     challenge,
+		Signature:     signature,
+		SignatureType: authenticator.SignatureType(),
 	}
 
-	_, err = m.httpClient.Abandon(abandonReq)
+	_, err = m.httpClient.AbandonCtx(ctx, abandonReq)
 	if err != nil {
 		return fmt.Errorf("abandon failed: %w", err)
 	}
@@ -835,6 +1675,85 @@ func (m *Minter) Abandon(agentID string) error {
 	return nil
 }
 
+// Status returns the backend's current view of one of this wallet's agents
+func (m *Minter) Status(agentID string) (*AgentStatusResponse, error) {
+	authenticator := m.newAuthenticator()
+
+	return m.httpClient.GetAgentStatus(authenticator.GetAddress(), agentID)
+}
+
+// ListAgents returns one page of the agents owned by this wallet
+func (m *Minter) ListAgents(page, pageSize int) (*ListAgentsResponse, error) {
+	return m.listAgents(context.Background(), page, pageSize)
+}
+
+func (m *Minter) listAgents(ctx context.Context, page, pageSize int) (*ListAgentsResponse, error) {
+	authenticator := m.newAuthenticator()
+
+	return m.httpClient.ListAgentsCtx(ctx, authenticator.GetAddress(), page, pageSize)
+}
+
+// ListAllAgents walks every page of ListAgents and returns all agents owned by this wallet
+func (m *Minter) ListAllAgents() ([]AgentSummary, error) {
+	return m.listAllAgents(context.Background())
+}
+
+func (m *Minter) listAllAgents(ctx context.Context) ([]AgentSummary, error) {
+	agents := []AgentSummary{}
+	for page := 1; ; page++ {
+		resp, err := m.listAgents(ctx, page, MaxListPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list page %d: %w", page, err)
+		}
+		agents = append(agents, resp.Agents...)
+		if !resp.HasMore {
+			return agents, nil
+		}
+	}
+}
+
+// AbandonAll abandons every unminted reservation owned by this wallet and
+// returns the IDs it abandoned. Minted agents and reservations with a mint
+// transaction pending in the WAL are skipped, as are reservations whose WAL
+// entry cannot be read. Individual failures do not stop the sweep; they are
+// joined into the returned error.
+func (m *Minter) AbandonAll(ctx context.Context) ([]string, error) {
+	agents, err := m.listAllAgents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agents: %w", err)
+	}
+
+	abandoned := []string{}
+	var errs []error
+	for _, agent := range agents {
+		if agent.TokenID != nil {
+			continue // Already minted
+		}
+		wal, err := m.walClient.Load(agent.AgentID)
+		if err != nil {
+			// An unreadable entry may hold a pending mint
+			errs = append(errs, fmt.Errorf("%s: failed to read WAL: %w", agent.AgentID, err))
+			continue
+		}
+		if wal != nil && wal.PendingTxHash != "" {
+			log.Printf("⏭️  Skipping %s: mint transaction %s is pending", agent.AgentID, wal.PendingTxHash)
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		if err := m.abandon(ctx, agent.AgentID); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", agent.AgentID, err))
+			continue
+		}
+		abandoned = append(abandoned, agent.AgentID)
+	}
+
+	return abandoned, errors.Join(errs...)
+}
+
 // AbandonAgent is a convenience function to abandon a reservation
 func AbandonAgent(agentID string, config *MintConfig) error {
 	if config == nil {
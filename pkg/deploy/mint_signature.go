@@ -0,0 +1,103 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrMintSignatureMismatch is returned when the backend's mint signature was
+// not produced by the contract's signer, so the mint would revert
+var ErrMintSignatureMismatch = errors.New("mint signature is not from the contract's signer")
+
+// MintSignatureDigest returns the hash the SDK assumes the NFT contract's
+// mint() recovers the backend signer from: the EIP-191 personal message hash
+// of keccak256(abi.encodePacked(to, nonce, chainID, contract)). Contracts
+// signing another layout fail VerifyMintSignature for valid signatures.
+func MintSignatureDigest(to common.Address, nonce uint64, chainID *big.Int, contract common.Address) []byte {
+	message := crypto.Keccak256(
+		to.Bytes(),
+		common.LeftPadBytes(new(big.Int).SetUint64(nonce).Bytes(), 32),
+		common.LeftPadBytes(chainID.Bytes(), 32),
+		contract.Bytes(),
+	)
+	return accounts.TextHash(message)
+}
+
+// RecoverMintSigner returns the address that produced a mint signature for a
+// mint to the wallet to. Both 0/1 and 27/28 recovery IDs are accepted.
+func RecoverMintSigner(signature string, to common.Address, nonce uint64, chainID *big.Int, contract common.Address) (common.Address, error) {
+	sig, err := hexutil.Decode(signature)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid signature format: %w", err)
+	}
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("invalid signature length: %d", len(sig))
+	}
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+
+	pubkey, err := crypto.SigToPub(MintSignatureDigest(to, nonce, chainID, contract), sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to recover mint signer: %w", err)
+	}
+	return crypto.PubkeyToAddress(*pubkey), nil
+}
+
+// MintSigner returns the address the contract accepts mint signatures from,
+// read from its signer() view
+func (c *ChainClient) MintSigner(ctx context.Context) (common.Address, error) {
+	signerABI, err := abi.JSON(strings.NewReader(`[{"inputs":[],"name":"signer","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"}]`))
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to parse signer ABI: %w", err)
+	}
+
+	data, err := signerABI.Pack("signer")
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to pack signer call: %w", err)
+	}
+	result, err := c.client.CallContract(ctx, ethereum.CallMsg{
+		To:   &c.contractAddress,
+		Data: data,
+	}, nil)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to call signer: %w", err)
+	}
+
+	var signer common.Address
+	if err := signerABI.UnpackIntoInterface(&signer, "signer", result); err != nil {
+		return common.Address{}, fmt.Errorf("failed to unpack signer result: %w", err)
+	}
+	return signer, nil
+}
+
+// VerifyMintSignature checks that the backend's mint signature for nonce was
+// produced by the contract's signer before any gas is spent on it. Contracts
+// whose signer cannot be read are not checked.
+func (c *ChainClient) VerifyMintSignature(ctx context.Context, signature string, nonce uint64) error {
+	expected, err := c.MintSigner(ctx)
+	if err != nil {
+		log.Printf("⚠️ Cannot read the contract's mint signer (%v), skipping signature check", err)
+		return nil
+	}
+
+	recovered, err := RecoverMintSigner(signature, c.address, nonce, c.chainID, c.contractAddress)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMintSignatureMismatch, err)
+	}
+	if recovered != expected {
+		return fmt.Errorf("%w: signed by %s, contract expects %s", ErrMintSignatureMismatch, recovered.Hex(), expected.Hex())
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// signTestMint signs a mint of to with nonce on the test contract, as the
+// backend would
+func signTestMint(t *testing.T, to common.Address, nonce uint64) (string, common.Address) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := crypto.Sign(MintSignatureDigest(to, nonce, big.NewInt(3338), common.HexToAddress(testContractAddress)), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig[64] += 27
+	return hexutil.Encode(sig), crypto.PubkeyToAddress(key.PublicKey)
+}
+
+// newSignerChain starts a JSON-RPC node whose contract returns signer from
+// signer() or, when hasSigner is false, only from other views such as
+// owner(), and a client for the wallet of privateKey
+func newSignerChain(t *testing.T, privateKey string, signer common.Address, hasSigner bool) *ChainClient {
+	t.Helper()
+	signerSelector := crypto.Keccak256([]byte("signer()"))[:4]
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params []struct {
+				Data  hexutil.Bytes `json:"data"`
+				Input hexutil.Bytes `json:"input"`
+			} `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Method != "eth_call" {
+			t.Errorf("unexpected RPC method %s", req.Method)
+		}
+		input := req.Params[0].Input
+		if len(input) == 0 {
+			input = req.Params[0].Data
+		}
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if bytes.HasPrefix(input, signerSelector) && !hasSigner {
+			resp["error"] = map[string]interface{}{"code": 3, "message": "execution reverted"}
+		} else {
+			resp["result"] = hexutil.Encode(common.LeftPadBytes(signer.Bytes(), 32))
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := NewChainClient(srv.URL, testContractAddress, "3338", privateKey)
+	if err != nil {
+		t.Fatalf("NewChainClient() error = %v", err)
+	}
+	return client
+}
+
+func TestRecoverMintSigner(t *testing.T) {
+	to := common.HexToAddress("0x00000000000000000000000000000000000000a1")
+	signature, signer := signTestMint(t, to, 5)
+	contract := common.HexToAddress(testContractAddress)
+
+	got, err := RecoverMintSigner(signature, to, 5, big.NewInt(3338), contract)
+	if err != nil || got != signer {
+		t.Errorf("RecoverMintSigner() = %s, %v, want %s", got.Hex(), err, signer.Hex())
+	}
+
+	// A 0/1 recovery ID recovers the same signer
+	sig := hexutil.MustDecode(signature)
+	sig[64] -= 27
+	if got, err := RecoverMintSigner(hexutil.Encode(sig), to, 5, big.NewInt(3338), contract); err != nil || got != signer {
+		t.Errorf("RecoverMintSigner() with 0/1 recovery ID = %s, %v, want %s", got.Hex(), err, signer.Hex())
+	}
+
+	// A signature for another nonce recovers some other address
+	if got, _ := RecoverMintSigner(signature, to, 6, big.NewInt(3338), contract); got == signer {
+		t.Error("RecoverMintSigner() recovered the signer for a different nonce")
+	}
+
+	if _, err := RecoverMintSigner("0xabcd", to, 5, big.NewInt(3338), contract); err == nil {
+		t.Error("RecoverMintSigner() accepted a short signature")
+	}
+}
+
+func TestChainClient_VerifyMintSignature(t *testing.T) {
+	privateKey := newTestPrivateKey(t)
+	signerKey, err := NewPrivateKeySigner(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wallet := signerKey.Address()
+	signature, signer := signTestMint(t, wallet, 1)
+	_, otherSigner := signTestMint(t, wallet, 1)
+
+	tests := []struct {
+		name      string
+		signer    common.Address
+		hasSigner bool
+		signature string
+		wantErr   bool
+	}{
+		{"signed by signer()", signer, true, signature, false},
+		{"owner() is not a signer", otherSigner, false, signature, false},
+		{"wrong signer", otherSigner, true, signature, true},
+		{"malformed signature", signer, true, "0x01", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newSignerChain(t, privateKey, tt.signer, tt.hasSigner)
+			err := client.VerifyMintSignature(context.Background(), tt.signature, 1)
+			if tt.wantErr != errors.Is(err, ErrMintSignatureMismatch) {
+				t.Errorf("VerifyMintSignature() error = %v, want mismatch %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChainClient_VerifyMintSignatureWithoutSignerView(t *testing.T) {
+	client, _ := newMintPriceChain(t, nil)
+	if err := client.VerifyMintSignature(context.Background(), "0x01", 1); err != nil {
+		t.Errorf("VerifyMintSignature() error = %v, want the check skipped", err)
+	}
+}
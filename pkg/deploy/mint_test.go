@@ -1,9 +1,23 @@
 package deploy
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
 func TestGenerateConfigHash(t *testing.T) {
@@ -128,6 +142,76 @@ func TestGenerateConfigHash(t *testing.T) {
 				Categories:   []string{"AI"},
 			},
 		},
+		{
+			name: "command parameters affect hash",
+			config: &AgentConfig{
+				AgentID:      "test",
+				Name:         "Test",
+				AgentType:    "command",
+				Capabilities: []Capability{{Name: "cap"}},
+				Categories:   []string{"AI"},
+				Commands: []Command{{Trigger: "echo", Parameters: []CommandParameter{
+					{Name: "message", Type: "string", Required: true},
+				}}},
+			},
+			wantSame: false,
+			config2: &AgentConfig{
+				AgentID:      "test",
+				Name:         "Test",
+				AgentType:    "command",
+				Capabilities: []Capability{{Name: "cap"}},
+				Categories:   []string{"AI"},
+				Commands: []Command{{Trigger: "echo", Parameters: []CommandParameter{
+					{Name: "message", Type: "string", Required: false},
+				}}},
+			},
+		},
+		{
+			name: "parameter order and descriptions dont matter",
+			config: &AgentConfig{
+				AgentID:      "test",
+				Name:         "Test",
+				AgentType:    "command",
+				Capabilities: []Capability{{Name: "cap"}},
+				Categories:   []string{"AI"},
+				Commands: []Command{{Trigger: "search", Parameters: []CommandParameter{
+					{Name: "query", Type: "string", Required: true, Description: "What to search"},
+					{Name: "limit", Type: "integer"},
+				}}},
+			},
+			wantSame: true,
+			config2: &AgentConfig{
+				AgentID:      "test",
+				Name:         "Test",
+				AgentType:    "command",
+				Capabilities: []Capability{{Name: "cap"}},
+				Categories:   []string{"AI"},
+				Commands: []Command{{Trigger: "search", Parameters: []CommandParameter{
+					{Name: "limit", Type: "integer", Description: "Max results"},
+					{Name: "query", Type: "string", Required: true},
+				}}},
+			},
+		},
+		{
+			name: "empty parameters match missing parameters",
+			config: &AgentConfig{
+				AgentID:      "test",
+				Name:         "Test",
+				AgentType:    "command",
+				Capabilities: []Capability{{Name: "cap"}},
+				Categories:   []string{"AI"},
+				Commands:     []Command{{Trigger: "ping", Parameters: []CommandParameter{}}},
+			},
+			wantSame: true,
+			config2: &AgentConfig{
+				AgentID:      "test",
+				Name:         "Test",
+				AgentType:    "command",
+				Capabilities: []Capability{{Name: "cap"}},
+				Categories:   []string{"AI"},
+				Commands:     []Command{{Trigger: "ping"}},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -157,8 +241,6 @@ func TestGenerateConfigHash(t *testing.T) {
 }
 
 func TestPreValidate(t *testing.T) {
-	minter := &Minter{}
-
 	tests := []struct {
 		name    string
 		config  *AgentConfig
@@ -234,7 +316,7 @@ func TestPreValidate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := minter.preValidate(tt.config)
+			err := preValidate(tt.config)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("preValidate() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -247,15 +329,62 @@ func TestPreValidate(t *testing.T) {
 	}
 }
 
-func TestValidateConfig(t *testing.T) {
-	minter := &Minter{}
-
+func TestPreValidate_CapabilityAndCommandNames(t *testing.T) {
 	tests := []struct {
-		name    string
-		config  *AgentConfig
-		wantErr bool
-		errMsg  string
+		name         string
+		capabilities []string
+		triggers     []string
+		errMsg       string // empty = valid
 	}{
+		{"plain slugs", []string{"ping", "example_capability", "tx-trace2"}, []string{"help", "search_places", "top-reviews"}, ""},
+		{"namespaced capability", []string{"social/profile_lookup", "chain/wallet/analysis"}, nil, ""},
+		{"uppercase capability", []string{"ping", "Social/Profile"}, nil, "capability 2: name must match"},
+		{"capability with space", []string{"profile lookup"}, nil, "capability 1: name must match"},
+		{"leading slash", []string{"/social"}, nil, "capability 1: name must match"},
+		{"trailing slash", []string{"social/"}, nil, "capability 1: name must match"},
+		{"empty namespace segment", []string{"social//lookup"}, nil, "capability 1: name must match"},
+		{"leading underscore", []string{"_private"}, nil, "capability 1: name must match"},
+		{"trigger with slash", []string{"ping"}, []string{"help", "social/profile"}, "command 2: trigger must match"},
+		{"trigger with leading slash", []string{"ping"}, []string{"/help"}, "command 1: trigger must match"},
+		{"uppercase trigger", []string{"ping"}, []string{"Help"}, "command 1: trigger must match"},
+		{"trigger with dot", []string{"ping"}, []string{"v1.run"}, "command 1: trigger must match"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &AgentConfig{Name: "Test", AgentID: "test", AgentType: "command"}
+			for _, name := range tt.capabilities {
+				config.Capabilities = append(config.Capabilities, Capability{Name: name})
+			}
+			for _, trigger := range tt.triggers {
+				config.Commands = append(config.Commands, Command{Trigger: trigger})
+			}
+
+			err := preValidate(config)
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("preValidate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("preValidate() error = %v, want error containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+// validateConfigTest is a validateConfig case, shared with the
+// ValidateConfig tests
+type validateConfigTest struct {
+	name    string
+	config  *AgentConfig
+	wantErr bool
+	errMsg  string
+}
+
+func validateConfigTests() []validateConfigTest {
+	return []validateConfigTest{
 		{
 			name: "valid full config",
 			config: &AgentConfig{
@@ -375,11 +504,84 @@ func TestValidateConfig(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "unique capabilities and triggers",
+			config: &AgentConfig{
+				Name:         "Valid Name",
+				AgentID:      "test",
+				Description:  "Valid description here",
+				AgentType:    "command",
+				Categories:   []string{"AI"},
+				Capabilities: []Capability{{Name: "social/profile"}, {Name: "social/timeline"}},
+				Commands:     []Command{{Trigger: "profile"}, {Trigger: "timeline"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "duplicate capability names",
+			config: &AgentConfig{
+				Name:         "Valid Name",
+				AgentID:      "test",
+				Description:  "Valid description here",
+				AgentType:    "command",
+				Categories:   []string{"AI"},
+				Capabilities: []Capability{{Name: "social/profile"}, {Name: "social/timeline"}, {Name: "social/profile"}},
+			},
+			wantErr: true,
+			errMsg:  "capability 3: duplicate name 'social/profile' (also capability 1)",
+		},
+		{
+			name: "duplicate command triggers",
+			config: &AgentConfig{
+				Name:         "Valid Name",
+				AgentID:      "test",
+				Description:  "Valid description here",
+				AgentType:    "command",
+				Categories:   []string{"AI"},
+				Capabilities: []Capability{{Name: "cap"}},
+				Commands:     []Command{{Trigger: "help"}, {Trigger: "profile"}, {Trigger: "help"}},
+			},
+			wantErr: true,
+			errMsg:  "command 3: duplicate trigger 'help' (also command 1)",
+		},
+		{
+			name:    "command with valid parameters",
+			config:  commandConfig(CommandParameter{Name: "message", Type: "string", Required: true}, CommandParameter{Name: "count", Type: "integer"}),
+			wantErr: false,
+		},
+		{
+			name:    "parameter without name",
+			config:  commandConfig(CommandParameter{Type: "string"}),
+			wantErr: true,
+			errMsg:  "name is required",
+		},
+		{
+			name:    "parameter with unknown type",
+			config:  commandConfig(CommandParameter{Name: "when", Type: "date"}),
+			wantErr: true,
+			errMsg:  "type must be one of",
+		},
+		{
+			name:    "duplicate parameter names",
+			config:  commandConfig(CommandParameter{Name: "q", Type: "string"}, CommandParameter{Name: "q", Type: "number"}),
+			wantErr: true,
+			errMsg:  "duplicate name",
+		},
+		{
+			name:    "required parameter after optional",
+			config:  commandConfig(CommandParameter{Name: "limit", Type: "integer"}, CommandParameter{Name: "query", Type: "string", Required: true}),
+			wantErr: true,
+			errMsg:  "must precede optional",
+		},
 	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	tests := validateConfigTests()
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := minter.validateConfig(tt.config)
+			err := validateConfig(tt.config)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("validateConfig() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -392,6 +594,169 @@ func TestValidateConfig(t *testing.T) {
 	}
 }
 
+func TestValidateConfig_CommandPricing(t *testing.T) {
+	tests := []struct {
+		name    string
+		command Command
+		errMsg  string // empty = valid
+	}{
+		{"free command", Command{Trigger: "help"}, ""},
+		{"free command with price type", Command{Trigger: "help", PriceType: PriceTypeTaskTransaction, TaskUnit: "per-query"}, ""},
+		{"priced per query", Command{Trigger: "search", PricePerUnit: 0.001, PriceType: PriceTypeTaskTransaction, TaskUnit: "per-query"}, ""},
+		{"priced per item", Command{Trigger: "fetch", PricePerUnit: 25, PriceType: PriceTypeTaskTransaction, TaskUnit: "per-item"}, ""},
+		{"time based", Command{Trigger: "stream", PricePerUnit: 0.5, PriceType: PriceTypeTimeBasedTask, TaskUnit: "minute"}, ""},
+		{"time based without task unit", Command{Trigger: "stream", PricePerUnit: 0.5, PriceType: PriceTypeTimeBasedTask}, ""},
+		{"negative price", Command{Trigger: "search", PricePerUnit: -1, PriceType: PriceTypeTaskTransaction, TaskUnit: "per-query"}, "command 1: pricePerUnit must not be negative"},
+		{"price without price type", Command{Trigger: "search", PricePerUnit: 25, TaskUnit: "per-query"}, "command 1: priceType is required"},
+		{"price without task unit", Command{Trigger: "search", PricePerUnit: 25, PriceType: PriceTypeTaskTransaction}, "command 1: taskUnit is required"},
+		{"unknown price type", Command{Trigger: "search", PricePerUnit: 25, PriceType: "subscription", TaskUnit: "per-query"}, "command 1: priceType must be"},
+		{"unknown price type on free command", Command{Trigger: "help", PriceType: "per-query"}, "command 1: priceType must be"},
+		{"unknown task unit", Command{Trigger: "search", PricePerUnit: 25, PriceType: PriceTypeTaskTransaction, TaskUnit: "per-call"}, "command 1: taskUnit must be"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := commandConfig()
+			config.Commands = []Command{tt.command}
+
+			err := validateConfig(config)
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("validateConfig() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("validateConfig() error = %v, want error containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestValidateConfig_Image(t *testing.T) {
+	smallPNG := "data:image/png;base64," + base64.StdEncoding.EncodeToString([]byte("\x89PNG\r\n\x1a\n"))
+	oversized := "data:image/png;base64," + base64.StdEncoding.EncodeToString(make([]byte, MaxInlineImageSize+1))
+
+	tests := []struct {
+		name   string
+		image  string
+		errMsg string // empty = valid
+	}{
+		{"no image", "", ""},
+		{"https URL", "https://example.com/agent.png", ""},
+		{"http URL", "http://example.com/agent.png", ""},
+		{"ipfs URI", "ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi", ""},
+		{"small data URI", smallPNG, ""},
+		{"oversized data URI", oversized, "inline image must not exceed"},
+		{"data URI that is not an image", "data:text/html;base64,PGgxPmhpPC9oMT4=", "data:image/<type>;base64"},
+		{"data URI without base64", "data:image/svg+xml,<svg></svg>", "data:image/<type>;base64"},
+		{"data URI with invalid base64", "data:image/png;base64,not base64!", "not valid base64"},
+		{"javascript scheme", "javascript:alert(1)", "scheme 'javascript'"},
+		{"file scheme", "file:///etc/passwd", "scheme 'file'"},
+		{"bare base64", "iVBORw0KGgo=", "scheme ''"},
+		{"URL without host", "https:///agent.png", "must include a host"},
+		{"overlong URL", "https://example.com/" + strings.Repeat("a", MaxImageURLLength), "must not exceed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := commandConfig()
+			config.Image = tt.image
+
+			err := validateConfig(config)
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("validateConfig() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("validateConfig() error = %v, want error containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+// commandConfig returns a valid config with a single command taking params
+func commandConfig(params ...CommandParameter) *AgentConfig {
+	return &AgentConfig{
+		Name:         "Valid Name",
+		AgentID:      "test",
+		Description:  "Valid description here",
+		AgentType:    "command",
+		Categories:   []string{"AI"},
+		Capabilities: []Capability{{Name: "cap"}},
+		Commands:     []Command{{Trigger: "run", Parameters: params}},
+	}
+}
+
+func TestComputeConfigHashMatchesGenerateConfigHash(t *testing.T) {
+	agentConfig := &AgentConfig{
+		AgentID:      "param-agent",
+		Name:         "Param Agent",
+		Description:  "Agent with parameterised commands",
+		Image:        "https://example.com/param.png",
+		AgentType:    "command",
+		Capabilities: []Capability{{Name: "echo"}},
+		Categories:   []string{"AI"},
+		Commands: []Command{
+			{Trigger: "echo", PricePerUnit: 0.01, Parameters: []CommandParameter{
+				{Name: "message", Type: "string", Required: true},
+			}},
+			{Trigger: "ping", PricePerUnit: 2.5, PriceType: "task-transaction", TaskUnit: "per-query"},
+		},
+		NlpFallback: true,
+	}
+
+	caps, _ := json.Marshal(agentConfig.Capabilities)
+	cmds, _ := json.Marshal(agentConfig.Commands)
+	cats, _ := json.Marshal(agentConfig.Categories)
+	deployConfig := &DeployConfig{
+		AgentID:      agentConfig.AgentID,
+		AgentName:    agentConfig.Name,
+		Description:  agentConfig.Description,
+		Image:        agentConfig.Image,
+		AgentType:    agentConfig.AgentType,
+		Capabilities: caps,
+		Commands:     cmds,
+		Categories:   cats,
+		NlpFallback:  agentConfig.NlpFallback,
+	}
+
+	for _, opts := range []HashOptions{
+		{},
+		{IncludeImage: true},
+		{SchemaVersion: "4"},
+		{SchemaVersion: "4", IncludeImage: true},
+	} {
+		if got, want := computeConfigHash(deployConfig, opts), GenerateConfigHashWithOptions(agentConfig, opts); got != want {
+			t.Errorf("computeConfigHash(%+v) = %s, want %s", opts, got, want)
+		}
+	}
+}
+
+func TestGenerateConfigHash_ImageOnlyWithOption(t *testing.T) {
+	config := hashVersionTestConfig()
+	changed := hashVersionTestConfig()
+	changed.Image = "https://example.com/other.png"
+
+	// The backend hash treats the image as cosmetic
+	if GenerateConfigHash(config) != GenerateConfigHash(changed) {
+		t.Error("default hash should not change when only the image changes")
+	}
+
+	withImage := HashOptions{IncludeImage: true}
+	if GenerateConfigHashWithOptions(config, withImage) == GenerateConfigHashWithOptions(changed, withImage) {
+		t.Error("IncludeImage hash should change when the image changes")
+	}
+	if got, want := GenerateConfigHashWithOptions(config, withImage), hashCanonical(canonicalConfig(config, HashOptions{})+"|image:https://example.com/a.png"); got != want {
+		t.Errorf("IncludeImage hash = %s, want %s", got, want)
+	}
+	if GenerateConfigHashWithOptions(config, withImage) == GenerateConfigHash(config) {
+		t.Error("IncludeImage should produce a distinct hash")
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsHelper(s, substr))
 }
@@ -421,7 +786,7 @@ func TestFileSizeLimit(t *testing.T) {
 
 	minter := &Minter{
 		httpClient: NewHTTPClient("http://localhost:8080"),
-		walClient:  NewWALClient(),
+		walClient:  NewWALClient(nil),
 	}
 
 	_, err := minter.Mint(largePath)
@@ -432,3 +797,889 @@ func TestFileSizeLimit(t *testing.T) {
 		t.Errorf("Expected 'too large' error, got: %v", err)
 	}
 }
+
+func conflictTestConfig() *AgentConfig {
+	return &AgentConfig{
+		Name:         "Conflict Agent",
+		AgentID:      "conflict-agent",
+		Description:  "Agent used for deploy conflict tests",
+		AgentType:    "command",
+		Categories:   []string{"AI"},
+		Capabilities: []Capability{{Name: "cap"}},
+	}
+}
+
+func newConflictTestMinter(t *testing.T, syncHandler http.HandlerFunc, failOnConflict bool) (*Minter, *Authenticator) {
+	t.Helper()
+	srv := newFakeBackend(t, map[string]http.HandlerFunc{
+		"/api/sdk/agent/deploy": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusConflict, ErrorResponse{Error: "agent_id already exists"})
+		},
+		"/api/sdk/agent/sync": syncHandler,
+	})
+
+	minter, err := NewMinter(&MintConfig{
+		PrivateKey:           newTestPrivateKey(t),
+		BackendURL:           srv.URL,
+		FailOnDeployConflict: failOnConflict,
+	})
+	if err != nil {
+		t.Fatalf("failed to create minter: %v", err)
+	}
+	minter.walClient = NewWALClientWithDir(t.TempDir())
+
+	authenticator, err := NewAuthenticator(minter.config.PrivateKey, minter.httpClient)
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+	return minter, authenticator
+}
+
+func TestExecuteMint_DeployConflictSelfOwned(t *testing.T) {
+	tokenID := int64(42)
+	var syncCalls int
+	minter, authenticator := newConflictTestMinter(t, func(w http.ResponseWriter, r *http.Request) {
+		syncCalls++
+		writeJSON(w, http.StatusOK, SyncResponse{
+			Status:          "SYNCED",
+			TokenID:         &tokenID,
+			ContractAddress: "0xcontract",
+		})
+	}, false)
+
+	config := conflictTestConfig()
+	result, err := minter.executeMint(context.Background(), config, authenticator, GenerateConfigHash(config))
+	if err != nil {
+		t.Fatalf("expected conflict to resolve, got error: %v", err)
+	}
+	if result.Status != MintStatusAlreadyOwned {
+		t.Errorf("expected status %s, got %s", MintStatusAlreadyOwned, result.Status)
+	}
+	if result.TokenID != 42 {
+		t.Errorf("expected token ID 42, got %d", result.TokenID)
+	}
+	if syncCalls != 1 {
+		t.Errorf("expected 1 sync call, got %d", syncCalls)
+	}
+}
+
+func TestExecuteMint_DeployConflictOtherOwned(t *testing.T) {
+	minter, authenticator := newConflictTestMinter(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusForbidden, map[string]string{
+			"error":   "FORBIDDEN",
+			"message": "agent is owned by another wallet",
+		})
+	}, false)
+
+	config := conflictTestConfig()
+	_, err := minter.executeMint(context.Background(), config, authenticator, GenerateConfigHash(config))
+	if err == nil {
+		t.Fatal("expected error for agent owned by another wallet")
+	}
+	if !errors.Is(err, ErrAgentExists) {
+		t.Errorf("expected error to wrap ErrAgentExists, got: %v", err)
+	}
+	if !contains(err.Error(), "owned by another wallet") {
+		t.Errorf("expected ownership reason in error, got: %v", err)
+	}
+}
+
+func TestExecuteMint_DeployConflictCreatorMismatch(t *testing.T) {
+	tokenID := int64(7)
+	minter, authenticator := newConflictTestMinter(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, SyncResponse{
+			Status:  "SYNCED",
+			TokenID: &tokenID,
+			Creator: "0x000000000000000000000000000000000000dEaD",
+		})
+	}, false)
+
+	config := conflictTestConfig()
+	_, err := minter.executeMint(context.Background(), config, authenticator, GenerateConfigHash(config))
+	if err == nil {
+		t.Fatal("expected error when creator is a different wallet")
+	}
+	if !errors.Is(err, ErrAgentExists) {
+		t.Errorf("expected error to wrap ErrAgentExists, got: %v", err)
+	}
+}
+
+func TestExecuteMint_DeployConflictLookupFailure(t *testing.T) {
+	minter, authenticator := newConflictTestMinter(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusServiceUnavailable, ErrorResponse{Error: "maintenance"})
+	}, false)
+
+	config := conflictTestConfig()
+	_, err := minter.executeMint(context.Background(), config, authenticator, GenerateConfigHash(config))
+	if !errors.Is(err, ErrServiceUnavailable) {
+		t.Fatalf("expected the sync error to be returned, got: %v", err)
+	}
+	if contains(err.Error(), "not owned") {
+		t.Errorf("a failed lookup must not be reported as not owned, got: %v", err)
+	}
+}
+
+func TestExecuteMint_DeployConflictFailFast(t *testing.T) {
+	var syncCalls int
+	minter, authenticator := newConflictTestMinter(t, func(w http.ResponseWriter, r *http.Request) {
+		syncCalls++
+		writeJSON(w, http.StatusOK, SyncResponse{Status: "SYNCED"})
+	}, true)
+
+	config := conflictTestConfig()
+	_, err := minter.executeMint(context.Background(), config, authenticator, GenerateConfigHash(config))
+	if !errors.Is(err, ErrAgentExists) {
+		t.Fatalf("expected ErrAgentExists, got: %v", err)
+	}
+	if syncCalls != 0 {
+		t.Errorf("expected no sync when FailOnDeployConflict is set, got %d calls", syncCalls)
+	}
+}
+
+// newReserveTestMinter returns a minter whose sync reports syncStatus and whose
+// deploy rejects the config, counting abandon calls in abandoned
+func newReserveTestMinter(t *testing.T, syncStatus string, keepReservation bool, abandoned *[]string) *Minter {
+	t.Helper()
+	srv := newFakeBackend(t, map[string]http.HandlerFunc{
+		"/api/sdk/agent/sync": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, SyncResponse{Status: syncStatus})
+		},
+		"/api/sdk/agent/deploy": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid capabilities"})
+		},
+		"/api/sdk/agent/abandon": func(w http.ResponseWriter, r *http.Request) {
+			var req AbandonRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			*abandoned = append(*abandoned, req.AgentID)
+			writeJSON(w, http.StatusOK, AbandonResponse{Success: true, AgentID: req.AgentID})
+		},
+	})
+
+	minter, err := NewMinter(&MintConfig{
+		PrivateKey:               newTestPrivateKey(t),
+		BackendURL:               srv.URL,
+		KeepReservationOnFailure: keepReservation,
+	})
+	if err != nil {
+		t.Fatalf("failed to create minter: %v", err)
+	}
+	minter.walClient = NewWALClientWithDir(t.TempDir())
+	return minter
+}
+
+func TestSyncAndMint_AbandonsReservationWhenDeployFails(t *testing.T) {
+	var abandoned []string
+	minter := newReserveTestMinter(t, "MINT_REQUIRED", false, &abandoned)
+	config := conflictTestConfig()
+
+	_, err := minter.syncAndMint(context.Background(), config, GenerateConfigHash(config), "")
+	if err == nil || !contains(err.Error(), "invalid capabilities") {
+		t.Fatalf("expected deploy failure, got: %v", err)
+	}
+
+	if len(abandoned) != 1 || abandoned[0] != config.AgentID {
+		t.Errorf("expected reservation for %s to be abandoned, got %v", config.AgentID, abandoned)
+	}
+}
+
+func TestSyncAndMint_KeepReservationOnFailure(t *testing.T) {
+	var abandoned []string
+	minter := newReserveTestMinter(t, "MINT_REQUIRED", true, &abandoned)
+	config := conflictTestConfig()
+
+	if _, err := minter.syncAndMint(context.Background(), config, GenerateConfigHash(config), ""); err == nil {
+		t.Fatal("expected deploy failure")
+	}
+	if len(abandoned) != 0 {
+		t.Errorf("expected reservation to be kept, got abandon calls for %v", abandoned)
+	}
+}
+
+func TestSyncAndMint_ResumedReservationNotAbandoned(t *testing.T) {
+	var abandoned []string
+	minter := newReserveTestMinter(t, "RESUME_MINT", false, &abandoned)
+	config := conflictTestConfig()
+
+	if _, err := minter.syncAndMint(context.Background(), config, GenerateConfigHash(config), ""); err == nil {
+		t.Fatal("expected deploy failure")
+	}
+	if len(abandoned) != 0 {
+		t.Errorf("expected pre-existing reservation to be kept, got abandon calls for %v", abandoned)
+	}
+}
+
+// newForceUpdateMinter returns a minter whose backend answers sync with
+// syncHandler and counts update and abandon calls
+func newForceUpdateMinter(t *testing.T, syncHandler http.HandlerFunc) (minter *Minter, jsonPath string, updates, abandons *int) {
+	t.Helper()
+	updates, abandons = new(int), new(int)
+	srv := newFakeBackend(t, map[string]http.HandlerFunc{
+		"/api/sdk/agent/sync": syncHandler,
+		"/api/sdk/agent/update": func(w http.ResponseWriter, r *http.Request) {
+			*updates++
+			writeJSON(w, http.StatusOK, UpdateMetadataResponse{Success: true, IpfsHash: "Qm", TxHash: "0xupdate"})
+		},
+		"/api/sdk/agent/abandon": func(w http.ResponseWriter, r *http.Request) {
+			*abandons++
+			writeJSON(w, http.StatusOK, AbandonResponse{Success: true})
+		},
+	})
+
+	minter, err := NewMinter(&MintConfig{PrivateKey: newTestPrivateKey(t), BackendURL: srv.URL})
+	if err != nil {
+		t.Fatalf("failed to create minter: %v", err)
+	}
+	minter.walClient = NewWALClientWithDir(t.TempDir())
+
+	data, _ := json.Marshal(conflictTestConfig())
+	jsonPath = filepath.Join(t.TempDir(), "agent.json")
+	if err := os.WriteFile(jsonPath, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return minter, jsonPath, updates, abandons
+}
+
+func TestForceUpdate_OwnedAgentUpdatesEvenWhenSynced(t *testing.T) {
+	tokenID := int64(9)
+	minter, jsonPath, updates, _ := newForceUpdateMinter(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, SyncResponse{Status: "SYNCED", TokenID: &tokenID, ContractAddress: "0xcontract"})
+	})
+
+	result, err := minter.ForceUpdate(context.Background(), jsonPath)
+	if err != nil {
+		t.Fatalf("ForceUpdate() error = %v", err)
+	}
+	if *updates != 1 {
+		t.Errorf("expected 1 update call, got %d", *updates)
+	}
+	if result.Status != MintStatusUpdated || result.TxHash != "0xupdate" || result.TokenID != 9 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestForceUpdate_NotOwned(t *testing.T) {
+	tokenID := int64(9)
+	tests := map[string]http.HandlerFunc{
+		"backend forbids": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusForbidden, ErrorResponse{Error: "not the owner"})
+		},
+		"different creator": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, SyncResponse{Status: "SYNCED", TokenID: &tokenID, Creator: "0x000000000000000000000000000000000000dEaD"})
+		},
+	}
+
+	for name, syncHandler := range tests {
+		t.Run(name, func(t *testing.T) {
+			minter, jsonPath, updates, _ := newForceUpdateMinter(t, syncHandler)
+
+			_, err := minter.ForceUpdate(context.Background(), jsonPath)
+			if !errors.Is(err, ErrForbidden) {
+				t.Errorf("expected ErrForbidden, got %v", err)
+			}
+			if *updates != 0 {
+				t.Errorf("expected no update calls, got %d", *updates)
+			}
+		})
+	}
+}
+
+func TestForceUpdate_NotMinted(t *testing.T) {
+	minter, jsonPath, updates, abandons := newForceUpdateMinter(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, SyncResponse{Status: "MINT_REQUIRED"})
+	})
+
+	_, err := minter.ForceUpdate(context.Background(), jsonPath)
+	if !errors.Is(err, ErrAgentNotMinted) {
+		t.Fatalf("expected ErrAgentNotMinted, got %v", err)
+	}
+	if *updates != 0 {
+		t.Errorf("expected no update calls, got %d", *updates)
+	}
+	if *abandons != 1 {
+		t.Errorf("expected the reservation made by sync to be abandoned, got %d abandon calls", *abandons)
+	}
+}
+
+func TestSyncAndMint_DetectsConfigHashLoop(t *testing.T) {
+	tokenID := int64(9)
+	config := conflictTestConfig()
+	configHash := GenerateConfigHash(config)
+	backendHash := GenerateConfigHashWithOptions(config, HashOptions{SchemaVersion: ConfigHashV4})
+
+	tests := []struct {
+		name     string
+		newHash  string
+		wantHint string
+	}{
+		{"other hash version", backendHash, "hashes the config as v4"},
+		{"unknown hash", "0xfeed", "computes a different hash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minter, _, updates, _ := newForceUpdateMinter(t, func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(w, http.StatusOK, SyncResponse{Status: "UPDATE_REQUIRED", TokenID: &tokenID, CurrentHash: "0xstored", NewHash: tt.newHash})
+			})
+
+			_, err := minter.syncAndMint(context.Background(), config, configHash, "")
+			if !errors.Is(err, ErrConfigHashLoop) {
+				t.Fatalf("syncAndMint() error = %v, want ErrConfigHashLoop", err)
+			}
+			for _, want := range []string{"0xstored", tt.newHash, configHash, tt.wantHint} {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+			if *updates != 1 {
+				t.Errorf("expected 1 update call, got %d", *updates)
+			}
+		})
+	}
+}
+
+func TestSyncAndMint_UpdateThenNewHashesIsNotALoop(t *testing.T) {
+	tokenID := int64(9)
+	config := conflictTestConfig()
+	var syncs int
+	minter, _, updates, _ := newForceUpdateMinter(t, func(w http.ResponseWriter, r *http.Request) {
+		syncs++
+		// The backend has moved on to another pending change
+		writeJSON(w, http.StatusOK, SyncResponse{Status: "UPDATE_REQUIRED", TokenID: &tokenID, CurrentHash: fmt.Sprintf("0xstored%d", syncs), NewHash: "0xnew"})
+	})
+
+	result, err := minter.syncAndMint(context.Background(), config, GenerateConfigHash(config), "")
+	if err != nil {
+		t.Fatalf("syncAndMint() error = %v", err)
+	}
+	if result.Status != MintStatusUpdated || *updates != 1 {
+		t.Errorf("result = %+v after %d updates, want one update", result, *updates)
+	}
+}
+
+func TestMinter_StatusQueriesOwnWallet(t *testing.T) {
+	var gotWallet string
+	srv := newFakeBackend(t, map[string]http.HandlerFunc{
+		"/api/sdk/agent/status": func(w http.ResponseWriter, r *http.Request) {
+			gotWallet = r.URL.Query().Get("wallet")
+			writeJSON(w, http.StatusOK, AgentStatusResponse{AgentID: r.URL.Query().Get("agent_id"), Wallet: gotWallet})
+		},
+	})
+
+	privateKey := newTestPrivateKey(t)
+	minter, err := NewMinter(&MintConfig{PrivateKey: privateKey, BackendURL: srv.URL})
+	if err != nil {
+		t.Fatalf("failed to create minter: %v", err)
+	}
+
+	status, err := minter.Status("my-agent")
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+
+	authenticator, _ := NewAuthenticator(privateKey, minter.httpClient)
+	if gotWallet != authenticator.GetAddress() || status.AgentID != "my-agent" {
+		t.Errorf("expected status for %s/my-agent, got wallet %s, %+v", authenticator.GetAddress(), gotWallet, status)
+	}
+}
+
+func TestMinter_ListAllAgentsWalksEveryPage(t *testing.T) {
+	var seen [][2]int
+	srv := newPaginatedBackend(t, 2*MaxListPageSize+3, &seen)
+
+	minter, err := NewMinter(&MintConfig{PrivateKey: newTestPrivateKey(t), BackendURL: srv.URL})
+	if err != nil {
+		t.Fatalf("failed to create minter: %v", err)
+	}
+
+	agents, err := minter.ListAllAgents()
+	if err != nil {
+		t.Fatalf("ListAllAgents() error = %v", err)
+	}
+	if len(agents) != 2*MaxListPageSize+3 || agents[len(agents)-1].AgentID != fmt.Sprintf("agent-%d", len(agents)-1) {
+		t.Errorf("expected all agents in order, got %d", len(agents))
+	}
+
+	want := [][2]int{{1, MaxListPageSize}, {2, MaxListPageSize}, {3, MaxListPageSize}}
+	if !reflect.DeepEqual(seen, want) {
+		t.Errorf("page cursors = %v, want %v", seen, want)
+	}
+}
+
+func TestMinter_ListAllAgentsEmpty(t *testing.T) {
+	var seen [][2]int
+	srv := newPaginatedBackend(t, 0, &seen)
+
+	minter, err := NewMinter(&MintConfig{PrivateKey: newTestPrivateKey(t), BackendURL: srv.URL})
+	if err != nil {
+		t.Fatalf("failed to create minter: %v", err)
+	}
+
+	agents, err := minter.ListAllAgents()
+	if err != nil || agents == nil || len(agents) != 0 {
+		t.Errorf("ListAllAgents() = %v, %v; want empty", agents, err)
+	}
+	if len(seen) != 1 {
+		t.Errorf("expected a single request for an empty wallet, got %v", seen)
+	}
+}
+
+// canonicalConfig joins canonicalHashParts into the hashed string
+func canonicalConfig(config *AgentConfig, opts HashOptions) string {
+	return strings.Join(canonicalHashParts(config, opts), "|")
+}
+
+// hashVersionTestConfig exercises every segment of the canonical strings
+func hashVersionTestConfig() *AgentConfig {
+	return &AgentConfig{
+		AgentID:      "hash-agent",
+		Name:         "Hash Agent",
+		Description:  "Hashes things",
+		Image:        "https://example.com/a.png",
+		AgentType:    "command",
+		Capabilities: []Capability{{Name: "zeta"}, {Name: "alpha"}},
+		Categories:   []string{"Utilities", "AI"},
+		NlpFallback:  true,
+		Commands: []Command{
+			{Trigger: "ping", PricePerUnit: 0.5, PriceType: "task-transaction", TaskUnit: "per-query"},
+			{Trigger: "echo", PricePerUnit: 0.01, Parameters: []CommandParameter{
+				{Name: "message", Type: "string", Required: true},
+			}},
+		},
+	}
+}
+
+func TestCanonicalConfigStrings(t *testing.T) {
+	config := hashVersionTestConfig()
+
+	wantV3 := "v3|hash-agent|Hash Agent|Hashes things|command|alpha,zeta|true|AI,Utilities|" +
+		"echo:0.01:message/string/true,ping:0.5"
+	if got := canonicalConfig(config, HashOptions{SchemaVersion: "3"}); got != wantV3 {
+		t.Errorf("canonicalConfig(v3) =\n%s\nwant:\n%s", got, wantV3)
+	}
+
+	wantV4 := "v4|hash-agent|Hash Agent|Hashes things|command|alpha,zeta|true|AI,Utilities|" +
+		"echo:0.01:::message/string/true,ping:0.5:task-transaction:per-query:"
+	if got := canonicalConfig(config, HashOptions{SchemaVersion: "4"}); got != wantV4 {
+		t.Errorf("canonicalConfig(v4) =\n%s\nwant:\n%s", got, wantV4)
+	}
+
+	// v3 omits the commands segment entirely; v4 always carries it
+	config.Commands = nil
+	if got, want := canonicalConfig(config, HashOptions{SchemaVersion: "3"}), "v3|hash-agent|Hash Agent|Hashes things|command|alpha,zeta|true|AI,Utilities"; got != want {
+		t.Errorf("canonicalConfig(v3) without commands = %s, want %s", got, want)
+	}
+	if got, want := canonicalConfig(config, HashOptions{SchemaVersion: "4"}), "v4|hash-agent|Hash Agent|Hashes things|command|alpha,zeta|true|AI,Utilities|"; got != want {
+		t.Errorf("canonicalConfig(v4) without commands = %s, want %s", got, want)
+	}
+}
+
+func TestConfigHashVersion(t *testing.T) {
+	tests := map[string]string{
+		"":        ConfigHashV3,
+		"3":       ConfigHashV3,
+		"v3":      ConfigHashV3,
+		"3.2.1":   ConfigHashV3,
+		"4":       ConfigHashV4,
+		"v4":      ConfigHashV4,
+		"4.0.0":   ConfigHashV4,
+		"V5":      ConfigHashV4,
+		"unknown": ConfigHashV3,
+	}
+	for schemaVersion, want := range tests {
+		if got := ConfigHashVersion(schemaVersion); got != want {
+			t.Errorf("ConfigHashVersion(%q) = %s, want %s", schemaVersion, got, want)
+		}
+	}
+}
+
+func TestGenerateConfigHashForSchema(t *testing.T) {
+	config := hashVersionTestConfig()
+
+	if got := GenerateConfigHashForSchema(config, ""); got != GenerateConfigHash(config) {
+		t.Errorf("unknown schema version should use the v3 default")
+	}
+	if got, want := GenerateConfigHashForSchema(config, "4"), hashCanonical(canonicalConfig(config, HashOptions{SchemaVersion: "4"})); got != want {
+		t.Errorf("schema version 4 hash = %s, want %s", got, want)
+	}
+	if GenerateConfigHashForSchema(config, "3") == GenerateConfigHashForSchema(config, "4") {
+		t.Error("v3 and v4 hashes should differ")
+	}
+	if GenerateConfigHashForSchema(config, "3") != generateConfigHashV3(config) || GenerateConfigHashForSchema(config, "4") != generateConfigHashV4(config) {
+		t.Error("schema versions should select generateConfigHashV3 and generateConfigHashV4")
+	}
+}
+
+func TestMint_SendsHashForBackendSchemaVersion(t *testing.T) {
+	for _, schemaVersion := range []string{"3", "4"} {
+		t.Run(schemaVersion, func(t *testing.T) {
+			tokenID := int64(3)
+			var got SyncRequest
+			srv := newFakeBackend(t, map[string]http.HandlerFunc{
+				"/api/sdk/schema": func(w http.ResponseWriter, r *http.Request) {
+					writeJSON(w, http.StatusOK, SchemaResponse{SchemaVersion: schemaVersion, MaxJSONSize: DefaultMaxJSONSize})
+				},
+				"/api/sdk/agent/sync": func(w http.ResponseWriter, r *http.Request) {
+					json.NewDecoder(r.Body).Decode(&got)
+					writeJSON(w, http.StatusOK, SyncResponse{Status: "SYNCED", TokenID: &tokenID})
+				},
+			})
+
+			minter, err := NewMinter(&MintConfig{
+				PrivateKey:     newTestPrivateKey(t),
+				BackendURL:     srv.URL,
+				SchemaCacheDir: t.TempDir(),
+			})
+			if err != nil {
+				t.Fatalf("failed to create minter: %v", err)
+			}
+			minter.walClient = NewWALClientWithDir(t.TempDir())
+
+			config := conflictTestConfig()
+			data, _ := json.Marshal(config)
+			jsonPath := filepath.Join(t.TempDir(), "agent.json")
+			if err := os.WriteFile(jsonPath, data, 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := minter.MintWithContext(context.Background(), jsonPath); err != nil {
+				t.Fatalf("MintWithContext() error = %v", err)
+			}
+			if want := GenerateConfigHashForSchema(config, schemaVersion); got.ConfigHash != want || got.SchemaVersion != schemaVersion {
+				t.Errorf("sync sent hash %s (schema %q), want %s", got.ConfigHash, got.SchemaVersion, want)
+			}
+		})
+	}
+}
+
+func TestMintWithContext_UnreadableWALStopsMint(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr error
+	}{
+		{"no key", "", ErrWALEncrypted},
+		{"wrong key", "wrong passphrase", ErrWALDecryptFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var syncs int
+			srv := newFakeBackend(t, map[string]http.HandlerFunc{
+				"/api/sdk/agent/sync": func(w http.ResponseWriter, r *http.Request) {
+					syncs++
+					writeJSON(w, http.StatusOK, SyncResponse{Status: "NOT_FOUND"})
+				},
+			})
+
+			minter, err := NewMinter(&MintConfig{
+				PrivateKey:       newTestPrivateKey(t),
+				BackendURL:       srv.URL,
+				SchemaCacheDir:   t.TempDir(),
+				WALEncryptionKey: tt.key,
+			})
+			if err != nil {
+				t.Fatalf("failed to create minter: %v", err)
+			}
+			dir := t.TempDir()
+			minter.walClient.store = NewFileWALStore(dir)
+
+			config := conflictTestConfig()
+			encrypted := NewWALClientWithDir(dir)
+			encrypted.SetEncryptionKey("passphrase")
+			encrypted.Save(&WALEntry{AgentID: config.AgentID, State: WALStateMinting, PendingTxHash: "0xpending"})
+
+			data, _ := json.Marshal(config)
+			jsonPath := filepath.Join(t.TempDir(), "agent.json")
+			if err := os.WriteFile(jsonPath, data, 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := minter.MintWithContext(context.Background(), jsonPath); !errors.Is(err, tt.wantErr) {
+				t.Errorf("MintWithContext() error = %v, want %v", err, tt.wantErr)
+			}
+			if syncs != 0 {
+				t.Errorf("sync called %d times, want no new mint", syncs)
+			}
+		})
+	}
+}
+
+func TestMintWithContext_DeadlineCancelsHungSync(t *testing.T) {
+	srv := newFakeBackend(t, map[string]http.HandlerFunc{"/api/sdk/agent/sync": hangingHandler})
+
+	minter, err := NewMinter(&MintConfig{
+		PrivateKey:     newTestPrivateKey(t),
+		BackendURL:     srv.URL,
+		SchemaCacheDir: t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create minter: %v", err)
+	}
+	minter.walClient = NewWALClientWithDir(t.TempDir())
+
+	data, _ := json.Marshal(conflictTestConfig())
+	jsonPath := filepath.Join(t.TempDir(), "agent.json")
+	if err := os.WriteFile(jsonPath, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = minter.MintWithContext(ctx, jsonPath)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("MintWithContext returned after %v, expected the deadline to cancel sync", elapsed)
+	}
+}
+
+func TestMinter_AbandonAll(t *testing.T) {
+	minted := int64(11)
+	agents := []AgentSummary{
+		{AgentID: "minted-agent", TokenID: &minted, Status: "minted"},
+		{AgentID: "stale-1", Status: "reserved"},
+		{AgentID: "broken", Status: "reserved"},
+		{AgentID: "pending-tx", Status: "reserved"},
+		{AgentID: "locked-wal", Status: "reserved"},
+		{AgentID: "stale-2", Status: "reserved"},
+	}
+
+	var abandonCalls []string
+	srv := newFakeBackend(t, map[string]http.HandlerFunc{
+		"/api/sdk/agent/list": func(w http.ResponseWriter, r *http.Request) {
+			// Two agents per page to exercise pagination
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			start, end := (page-1)*2, page*2
+			if end > len(agents) {
+				end = len(agents)
+			}
+			writeJSON(w, http.StatusOK, ListAgentsResponse{Agents: agents[start:end], Total: len(agents), HasMore: end < len(agents)})
+		},
+		"/api/sdk/agent/abandon": func(w http.ResponseWriter, r *http.Request) {
+			var req AbandonRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			abandonCalls = append(abandonCalls, req.AgentID)
+			if req.AgentID == "broken" {
+				writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "boom"})
+				return
+			}
+			writeJSON(w, http.StatusOK, AbandonResponse{Success: true})
+		},
+	})
+
+	minter, err := NewMinter(&MintConfig{PrivateKey: newTestPrivateKey(t), BackendURL: srv.URL})
+	if err != nil {
+		t.Fatalf("failed to create minter: %v", err)
+	}
+	minter.walClient = NewWALClientWithDir(t.TempDir())
+	minter.walClient.Save(&WALEntry{AgentID: "pending-tx", State: WALStateMinting, PendingTxHash: "0xpending"})
+
+	// A WAL entry this minter cannot decrypt may hold a pending mint
+	encrypted := NewWALClient(minter.walClient.store)
+	encrypted.SetEncryptionKey("passphrase")
+	encrypted.Save(&WALEntry{AgentID: "locked-wal", State: WALStateMinting, PendingTxHash: "0xlocked"})
+
+	abandoned, err := minter.AbandonAll(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("expected the failed abandon to be reported, got %v", err)
+	}
+	if !errors.Is(err, ErrWALEncrypted) || !strings.Contains(err.Error(), "locked-wal") {
+		t.Errorf("expected the unreadable WAL to be reported, got %v", err)
+	}
+	if want := []string{"stale-1", "stale-2"}; !reflect.DeepEqual(abandoned, want) {
+		t.Errorf("abandoned = %v, want %v", abandoned, want)
+	}
+	if want := []string{"stale-1", "broken", "stale-2"}; !reflect.DeepEqual(abandonCalls, want) {
+		t.Errorf("abandon calls = %v, want %v (minted, pending and unreadable agents skipped)", abandonCalls, want)
+	}
+}
+
+func TestMinter_AbandonAllNothingPending(t *testing.T) {
+	var seen [][2]int
+	srv := newPaginatedBackend(t, 3, &seen) // every agent is minted
+
+	minter, err := NewMinter(&MintConfig{PrivateKey: newTestPrivateKey(t), BackendURL: srv.URL})
+	if err != nil {
+		t.Fatalf("failed to create minter: %v", err)
+	}
+
+	abandoned, err := minter.AbandonAll(context.Background())
+	if err != nil || abandoned == nil || len(abandoned) != 0 {
+		t.Errorf("AbandonAll() = %v, %v; want empty, nil", abandoned, err)
+	}
+}
+
+// newDroppedTxChain starts a JSON-RPC node that knows no transactions and
+// reports pendingNonce for the wallet
+func newDroppedTxChain(t *testing.T, pendingNonce uint64) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		var result interface{}
+		switch req.Method {
+		case "eth_getTransactionReceipt", "eth_getTransactionByHash":
+			result = nil
+		case "eth_getTransactionCount":
+			result = hexutil.EncodeUint64(pendingNonce)
+		default:
+			t.Errorf("unexpected RPC method %s", req.Method)
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func uint64Ptr(v uint64) *uint64 { return &v }
+
+func TestMinter_RecoverFromWALDroppedTransaction(t *testing.T) {
+	tests := []struct {
+		name         string
+		pendingNonce uint64
+		walNonce     *uint64
+		wantRestart  bool
+	}{
+		{"nonce moved past the tx", 5, uint64Ptr(3), true},
+		{"nonce not reached yet", 3, uint64Ptr(3), false},
+		{"no nonce recorded", 5, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var syncs int
+			backend := newFakeBackend(t, map[string]http.HandlerFunc{
+				"/api/sdk/agent/sync": func(w http.ResponseWriter, r *http.Request) {
+					syncs++
+					tokenID := int64(8)
+					writeJSON(w, http.StatusOK, SyncResponse{Status: "SYNCED", TokenID: &tokenID})
+				},
+			})
+			chain := newDroppedTxChain(t, tt.pendingNonce)
+
+			minter, err := NewMinter(&MintConfig{
+				PrivateKey:            newTestPrivateKey(t),
+				BackendURL:            backend.URL,
+				PendingTxTimeout:      30 * time.Millisecond,
+				PendingTxPollInterval: 10 * time.Millisecond,
+			})
+			if err != nil {
+				t.Fatalf("NewMinter() error = %v", err)
+			}
+			minter.walClient = NewWALClientWithDir(t.TempDir())
+
+			wal := &WALEntry{
+				AgentID:         "stuck-agent",
+				State:           WALStateMinting,
+				PendingTxHash:   "0x" + strings.Repeat("ab", 32),
+				PendingNonce:    tt.walNonce,
+				ContractAddress: testContractAddress,
+				ChainID:         "3338",
+				RPCURL:          chain.URL,
+				ConfigHash:      "hash",
+			}
+			minter.walClient.Save(wal)
+
+			result, err := minter.recoverFromWAL(context.Background(), wal, &AgentConfig{AgentID: "stuck-agent"})
+			if tt.wantRestart {
+				if err != nil {
+					t.Fatalf("recoverFromWAL() error = %v", err)
+				}
+				if syncs != 1 || result.Status != MintStatusAlreadyOwned {
+					t.Errorf("expected the mint to restart with a sync, got %d syncs and %+v", syncs, result)
+				}
+				if minter.walClient.Exists("stuck-agent") {
+					t.Error("WAL for a dropped transaction should be deleted")
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), "status unknown") {
+				t.Errorf("recoverFromWAL() error = %v, want pending status unknown", err)
+			}
+			if syncs != 0 {
+				t.Errorf("expected no restart, got %d syncs", syncs)
+			}
+			if !minter.walClient.Exists("stuck-agent") {
+				t.Error("WAL should be kept while the transaction may still be mined")
+			}
+		})
+	}
+}
+
+func TestMinter_RecoverFromWALAfterSpeedUp(t *testing.T) {
+	original := "0x" + strings.Repeat("01", 32)
+	replacement := "0x" + strings.Repeat("02", 32)
+
+	newRecoveryMinter := func(t *testing.T, chain *fakeChainOps) (*Minter, *unconfirmedBackend, *WALEntry) {
+		backend := newUnconfirmedBackend(t, 0)
+		minter, err := NewMinter(&MintConfig{
+			PrivateKey:            newTestPrivateKey(t),
+			BackendURL:            backend.URL,
+			PendingTxTimeout:      30 * time.Millisecond,
+			PendingTxPollInterval: 10 * time.Millisecond,
+			ChainFactory:          chain.factory(),
+		})
+		if err != nil {
+			t.Fatalf("NewMinter() error = %v", err)
+		}
+		minter.walClient = NewWALClientWithDir(t.TempDir())
+
+		// The original was sped up, so the replacement is the latest tx
+		wal := &WALEntry{
+			AgentID:         "sped-up-agent",
+			State:           WALStateMinting,
+			ContractAddress: testContractAddress,
+			ChainID:         "3338",
+			RPCURL:          "http://rpc.invalid",
+			ConfigHash:      "hash",
+		}
+		wal.recordSentTx(original, 3)
+		wal.recordSentTx(replacement, 3)
+		minter.walClient.Save(wal)
+		return minter, backend, wal
+	}
+
+	t.Run("original mined", func(t *testing.T) {
+		chain := newFakeChainOps(common.Address{})
+		chain.setReceipt(original, 1, 31)
+		chain.dropped[replacement] = true
+		minter, backend, wal := newRecoveryMinter(t, chain)
+
+		result, err := minter.recoverFromWAL(context.Background(), wal, &AgentConfig{AgentID: "sped-up-agent"})
+		if err != nil {
+			t.Fatalf("recoverFromWAL() error = %v", err)
+		}
+		if result.TokenID != 31 || result.TxHash != original {
+			t.Errorf("recoverFromWAL() = token %d tx %s, want token 31 from the original tx", result.TokenID, result.TxHash)
+		}
+		if confirms := backend.confirmed(); len(confirms) != 1 || confirms[0].TxHash != original {
+			t.Errorf("unexpected confirm-mint requests: %+v", confirms)
+		}
+	})
+
+	t.Run("original still pending", func(t *testing.T) {
+		chain := newFakeChainOps(common.Address{})
+		chain.dropped[replacement] = true
+		minter, backend, wal := newRecoveryMinter(t, chain)
+
+		_, err := minter.recoverFromWAL(context.Background(), wal, &AgentConfig{AgentID: "sped-up-agent"})
+		if err == nil || !strings.Contains(err.Error(), "status unknown") {
+			t.Errorf("recoverFromWAL() error = %v, want pending status unknown", err)
+		}
+		if !minter.walClient.Exists("sped-up-agent") {
+			t.Error("WAL should be kept while the original may still be mined")
+		}
+		if n := len(backend.confirmed()); n != 0 {
+			t.Errorf("expected no confirm-mint, got %d", n)
+		}
+	})
+}
@@ -0,0 +1,179 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MintStatusReconciled is returned by Reconcile after it confirmed an agent
+// that was minted on-chain but never recorded by the backend
+const MintStatusReconciled = "RECONCILED"
+
+// ErrTokenIDUnknown is returned by Reconcile when neither the WAL, the mint
+// receipt nor the backend identifies the agent's token
+var ErrTokenIDUnknown = errors.New("agent token ID unknown")
+
+// Reconcile repairs an agent that was minted on-chain while confirm-mint
+// failed, leaving the backend waiting for a mint. It recovers the token ID
+// from the WAL or the mint receipt, checks the wallet owns it
+// and re-runs confirm-mint. Reconcile is idempotent: an agent the backend
+// already has a token for is returned as MintStatusAlreadyOwned without
+// calling confirm-mint again.
+func (m *Minter) Reconcile(ctx context.Context, agentID string) (*MintResult, error) {
+	authenticator := m.newAuthenticator()
+	wallet := authenticator.GetAddress()
+
+	status, err := m.httpClient.GetAgentStatusCtx(ctx, wallet, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agent status: %w", err)
+	}
+	if status.TokenID != nil {
+		log.Printf("✅ Agent %s already confirmed with token ID %d", agentID, *status.TokenID)
+		m.walClient.Delete(agentID)
+		return &MintResult{
+			TokenID:         uint64(*status.TokenID),
+			AgentID:         agentID,
+			Status:          MintStatusAlreadyOwned,
+			ContractAddress: status.ContractAddress,
+			Message:         "Agent already confirmed",
+		}, nil
+	}
+
+	// A missing WAL only means the token ID must come from the chain, but an
+	// unreadable one may name a different mint transaction
+	wal, err := m.walClient.Load(agentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read WAL for %s: %w", agentID, err)
+	}
+	if wal == nil {
+		wal = &WALEntry{AgentID: agentID}
+	}
+
+	chainClient, contractAddress, err := m.reconcileChainClient(ctx, wal)
+	if err != nil {
+		return nil, err
+	}
+	defer chainClient.Close()
+
+	tokenID, err := m.recoverTokenID(ctx, chainClient, wal)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireOwnership(ctx, chainClient, tokenID); err != nil {
+		return nil, err
+	}
+
+	configHash := wal.ConfigHash
+	if configHash == "" {
+		configHash = status.ConfigHash
+	}
+
+	log.Printf("🔧 Reconciling agent %s with token ID %d...", agentID, tokenID)
+	sessionToken, err := m.authenticate(ctx, authenticator)
+	if err != nil {
+		return nil, fmt.Errorf("authentication failed: %w", err)
+	}
+	if err := m.confirmMinted(ctx, sessionToken, agentID, wallet, tokenID, wal.PendingTxHash, configHash); err != nil {
+		return nil, err
+	}
+
+	return &MintResult{
+		TokenID:         tokenID,
+		AgentID:         agentID,
+		Status:          MintStatusReconciled,
+		ContractAddress: contractAddress,
+		TxHash:          wal.PendingTxHash,
+		Message:         "Minted agent confirmed with backend",
+	}, nil
+}
+
+// reconcileChainClient connects to the contract recorded in the WAL, or to the
+// backend's contract when the WAL has none
+func (m *Minter) reconcileChainClient(ctx context.Context, wal *WALEntry) (ChainOps, string, error) {
+	if wal.ContractAddress == "" {
+		chainClient, contract, err := m.contractChainClient(ctx)
+		if err != nil {
+			return nil, "", err
+		}
+		return chainClient, contract.ContractAddress, nil
+	}
+
+	if err := checkExpectedContract("WAL", m.config.ExpectedContract, m.config.ExpectedChainID, wal.ContractAddress, wal.ChainID); err != nil {
+		return nil, "", err
+	}
+	rpcEndpoint := wal.RPCURL
+	if rpcEndpoint == "" {
+		rpcEndpoint = m.config.RPCEndpoint
+	}
+	chainClient, err := m.newChain(rpcEndpoint, wal.ContractAddress, wal.ChainID, m.signer)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create chain client: %w", err)
+	}
+	return chainClient, wal.ContractAddress, nil
+}
+
+// recoverTokenID finds the minted token ID: from the WAL, or from the Minted
+// or Transfer event of the mint receipt. Without a recorded mint transaction
+// it fails rather than guess from the tokens the wallet owns, which may
+// belong to other agents.
+func (m *Minter) recoverTokenID(ctx context.Context, chainClient ChainOps, wal *WALEntry) (uint64, error) {
+	if wal.PendingTokenID != nil {
+		return *wal.PendingTokenID, nil
+	}
+
+	if hashes := wal.pendingTxHashes(); len(hashes) > 0 {
+		// Any transaction sent for the nonce, speed-ups included, may be the
+		// one mined
+		receipt, hash, err := findReceipt(ctx, chainClient, hashes)
+		if err != nil {
+			return 0, fmt.Errorf("pending transaction status unknown, please check %s: %w", strings.Join(hashes, ", "), err)
+		}
+		if receipt.Status != 1 {
+			return 0, fmt.Errorf("%w: mint transaction %s failed", ErrAgentNotMinted, hash)
+		}
+		wal.PendingTxHash = hash
+		return chainClient.ExtractTokenIDFromReceipt(receipt)
+	}
+
+	return 0, fmt.Errorf("%w: no mint transaction is recorded for agent %s and the backend has no token for it", ErrTokenIDUnknown, wal.AgentID)
+}
+
+// confirmMintedWithRetry is confirmMinted with transient failures retried as
+// configured by ConfirmRetries
+func (m *Minter) confirmMintedWithRetry(ctx context.Context, sessionToken, agentID, wallet string, tokenID uint64, txHash, configHash string) error {
+	return retryConfirm(ctx, m.config.ConfirmRetries, m.config.ConfirmRetryBackoff, func() error {
+		return m.confirmMinted(ctx, sessionToken, agentID, wallet, tokenID, txHash, configHash)
+	})
+}
+
+// confirmMinted records an on-chain mint with the backend and clears the WAL.
+// On failure the WAL is kept so a later Mint or Reconcile can retry.
+func (m *Minter) confirmMinted(ctx context.Context, sessionToken, agentID, wallet string, tokenID uint64, txHash, configHash string) error {
+	if tokenID > math.MaxInt64 {
+		return fmt.Errorf("token ID %d exceeds int64 maximum", tokenID)
+	}
+
+	confirmReq := &ConfirmMintRequest{
+		AgentID:       agentID,
+		WalletAddress: wallet,
+		TokenID:       int64(tokenID),
+		TxHash:        txHash,
+		ConfigHash:    configHash,
+	}
+	ctx, span := m.startSpan(ctx, SpanConfirm, trace.WithAttributes(AttrTxHash.String(txHash), tokenIDAttr(tokenID)))
+	_, err := m.httpClient.ConfirmMintCtx(ctx, sessionToken, confirmReq)
+	endSpan(span, err)
+	if err != nil {
+		return fmt.Errorf("confirm-mint failed: %w", err)
+	}
+
+	log.Println("✅ Agent confirmed in database!")
+	m.walClient.Delete(agentID)
+	return nil
+}
@@ -0,0 +1,322 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// newReconcileChain starts a JSON-RPC node whose contract reports that owner
+// holds tokenID and nothing else
+func newReconcileChain(t *testing.T, owner common.Address, tokenID int64) *httptest.Server {
+	t.Helper()
+	selector := func(sig string) []byte { return crypto.Keccak256([]byte(sig))[:4] }
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Method != "eth_call" {
+			t.Errorf("unexpected RPC method %s", req.Method)
+		}
+
+		var call struct {
+			Input hexutil.Bytes `json:"input"`
+			Data  hexutil.Bytes `json:"data"`
+		}
+		json.Unmarshal(req.Params[0], &call)
+		input := call.Input
+		if len(input) == 0 {
+			input = call.Data
+		}
+
+		var word []byte
+		switch {
+		case bytes.HasPrefix(input, selector("hasAccess(address)")):
+			word = []byte{1}
+		case bytes.HasPrefix(input, selector("tokenOfOwnerByIndex(address,uint256)")):
+			word = big.NewInt(tokenID).Bytes()
+		case bytes.HasPrefix(input, selector("ownerOf(uint256)")):
+			word = owner.Bytes()
+		default:
+			t.Errorf("unexpected eth_call %x", input)
+		}
+
+		result := hexutil.Encode(common.LeftPadBytes(word, 32))
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// unconfirmedBackend reports an agent as reserved until confirm-mint succeeds,
+// failing the first failConfirms confirm-mint calls. It records the
+// idempotency key of every confirm-mint call, failed or not.
+type unconfirmedBackend struct {
+	*httptest.Server
+
+	mu              sync.Mutex
+	confirms        []ConfirmMintRequest
+	failConfirms    int
+	idempotencyKeys []string
+}
+
+func newUnconfirmedBackend(t *testing.T, failConfirms int) *unconfirmedBackend {
+	t.Helper()
+	b := &unconfirmedBackend{failConfirms: failConfirms}
+	b.Server = newFakeBackend(t, map[string]http.HandlerFunc{
+		"/api/contract/config": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, ContractConfigResponse{ContractAddress: testContractAddress, ChainID: "3338"})
+		},
+		"/api/sdk/agent/status": func(w http.ResponseWriter, r *http.Request) {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			status := AgentStatusResponse{AgentID: r.URL.Query().Get("agent_id"), Status: "reserved", ConfigHash: "backend-hash"}
+			if n := len(b.confirms); n > 0 {
+				tokenID := b.confirms[n-1].TokenID
+				status.TokenID = &tokenID
+				status.Status = "minted"
+			}
+			writeJSON(w, http.StatusOK, status)
+		},
+		"/api/sdk/agent/confirm-mint": func(w http.ResponseWriter, r *http.Request) {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			b.idempotencyKeys = append(b.idempotencyKeys, r.Header.Get(IdempotencyKeyHeader))
+			if b.failConfirms > 0 {
+				b.failConfirms--
+				writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "ipfs unavailable"})
+				return
+			}
+			var req ConfirmMintRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			b.confirms = append(b.confirms, req)
+			writeJSON(w, http.StatusOK, ConfirmMintResponse{Success: true, ID: "db-id"})
+		},
+	})
+	return b
+}
+
+func (b *unconfirmedBackend) confirmed() []ConfirmMintRequest {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]ConfirmMintRequest(nil), b.confirms...)
+}
+
+func newReconcileMinter(t *testing.T, backendURL, rpcURL string) (*Minter, *PrivateKeySigner) {
+	t.Helper()
+	signer, err := NewPrivateKeySigner(newTestPrivateKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	minter, err := NewMinter(&MintConfig{BackendURL: backendURL, RPCEndpoint: rpcURL, Signer: signer})
+	if err != nil {
+		t.Fatalf("NewMinter() error = %v", err)
+	}
+	minter.walClient = NewWALClientWithDir(t.TempDir())
+	return minter, signer
+}
+
+func TestMinter_ReconcileRecoversTokenFromReceipt(t *testing.T) {
+	backend := newUnconfirmedBackend(t, 0)
+	minter, signer := newReconcileMinter(t, backend.URL, "")
+	chain := newFakeChainOps(signer.Address())
+	chain.owners[17] = signer.Address()
+	chain.setReceipt("0xmint", 1, 17)
+	minter.newChain = chain.factory()
+
+	// The WAL of a mint whose receipt was never read
+	minter.walClient.Save(&WALEntry{
+		AgentID:         "lost-agent",
+		Wallet:          signer.Address().Hex(),
+		State:           WALStateMinting,
+		PendingTxHash:   "0xmint",
+		ContractAddress: testContractAddress,
+		ChainID:         "3338",
+	})
+
+	result, err := minter.Reconcile(context.Background(), "lost-agent")
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if result.Status != MintStatusReconciled || result.TokenID != 17 {
+		t.Errorf("Reconcile() = %s token %d, want %s token 17", result.Status, result.TokenID, MintStatusReconciled)
+	}
+
+	confirms := backend.confirmed()
+	if len(confirms) != 1 {
+		t.Fatalf("expected 1 confirm-mint, got %d", len(confirms))
+	}
+	if got := confirms[0]; got.AgentID != "lost-agent" || got.TokenID != 17 || got.ConfigHash != "backend-hash" ||
+		got.WalletAddress != signer.Address().Hex() {
+		t.Errorf("unexpected confirm-mint request: %+v", got)
+	}
+
+	// A second run finds the agent confirmed and does nothing
+	result, err = minter.Reconcile(context.Background(), "lost-agent")
+	if err != nil {
+		t.Fatalf("second Reconcile() error = %v", err)
+	}
+	if result.Status != MintStatusAlreadyOwned || result.TokenID != 17 {
+		t.Errorf("second Reconcile() = %s token %d, want %s token 17", result.Status, result.TokenID, MintStatusAlreadyOwned)
+	}
+	if n := len(backend.confirmed()); n != 1 {
+		t.Errorf("expected no further confirm-mint, got %d in total", n)
+	}
+}
+
+func TestMinter_ReconcileWithoutMintTransactionFails(t *testing.T) {
+	backend := newUnconfirmedBackend(t, 0)
+	minter, signer := newReconcileMinter(t, backend.URL, "http://rpc.invalid")
+
+	// The wallet owns another agent's token, which must not be picked
+	chain := newFakeChainOps(signer.Address())
+	owned := uint64(17)
+	chain.ownedToken = &owned
+	chain.owners[owned] = signer.Address()
+	minter.newChain = chain.factory()
+
+	if _, err := minter.Reconcile(context.Background(), "lost-agent"); !errors.Is(err, ErrTokenIDUnknown) {
+		t.Errorf("Reconcile() error = %v, want ErrTokenIDUnknown", err)
+	}
+	if n := len(backend.confirmed()); n != 0 {
+		t.Errorf("expected no confirm-mint, got %d", n)
+	}
+}
+
+func TestMinter_ReconcileUnreadableWALFails(t *testing.T) {
+	backend := newUnconfirmedBackend(t, 0)
+	minter, signer := newReconcileMinter(t, backend.URL, "")
+	chain := newFakeChainOps(signer.Address())
+	chain.owners[17] = signer.Address()
+	chain.setReceipt("0xmint", 1, 17)
+	minter.newChain = chain.factory()
+
+	// The entry was written by a minter with an encryption key this one lacks
+	encrypted := NewWALClient(minter.walClient.store)
+	encrypted.SetEncryptionKey("passphrase")
+	encrypted.Save(&WALEntry{AgentID: "lost-agent", State: WALStateMinting, PendingTxHash: "0xmint"})
+
+	if _, err := minter.Reconcile(context.Background(), "lost-agent"); !errors.Is(err, ErrWALEncrypted) {
+		t.Errorf("Reconcile() error = %v, want ErrWALEncrypted", err)
+	}
+	if n := len(backend.confirmed()); n != 0 {
+		t.Errorf("expected no confirm-mint, got %d", n)
+	}
+}
+
+func TestMinter_ReconcileUsesWALAfterFailedConfirm(t *testing.T) {
+	backend := newUnconfirmedBackend(t, 1)
+	minter, signer := newReconcileMinter(t, backend.URL, "")
+	chain := newReconcileChain(t, signer.Address(), 99) // token 99 is not the one minted
+
+	// The state executeMint leaves behind when confirm-mint fails
+	tokenID := uint64(23)
+	minter.walClient.Save(&WALEntry{
+		AgentID:         "wal-agent",
+		Wallet:          signer.Address().Hex(),
+		State:           WALStateConfirming,
+		PendingTxHash:   "0xminted",
+		PendingTokenID:  &tokenID,
+		ContractAddress: testContractAddress,
+		ChainID:         "3338",
+		RPCURL:          chain.URL,
+		ConfigHash:      "wal-hash",
+	})
+
+	if _, err := minter.Reconcile(context.Background(), "wal-agent"); err == nil {
+		t.Fatal("Reconcile() should fail while confirm-mint fails")
+	}
+	if !minter.walClient.Exists("wal-agent") {
+		t.Fatal("WAL should be kept after a failed confirm-mint")
+	}
+
+	result, err := minter.Reconcile(context.Background(), "wal-agent")
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if result.TokenID != 23 || result.TxHash != "0xminted" {
+		t.Errorf("Reconcile() = token %d tx %s, want token 23 tx 0xminted", result.TokenID, result.TxHash)
+	}
+	if confirms := backend.confirmed(); len(confirms) != 1 || confirms[0].ConfigHash != "wal-hash" || confirms[0].TxHash != "0xminted" {
+		t.Errorf("unexpected confirm-mint requests: %+v", confirms)
+	}
+	if minter.walClient.Exists("wal-agent") {
+		t.Error("WAL should be deleted once the agent is confirmed")
+	}
+}
+
+func TestMinter_ConfirmRetriesReuseIdempotencyKey(t *testing.T) {
+	backend := newUnconfirmedBackend(t, 1)
+	minter, signer := newReconcileMinter(t, backend.URL, "")
+	chain := newReconcileChain(t, signer.Address(), 23)
+
+	tokenID := uint64(23)
+	minter.walClient.Save(&WALEntry{
+		AgentID:         "retry-agent",
+		Wallet:          signer.Address().Hex(),
+		State:           WALStateConfirming,
+		PendingTxHash:   "0xMinted",
+		PendingTokenID:  &tokenID,
+		ContractAddress: testContractAddress,
+		ChainID:         "3338",
+		RPCURL:          chain.URL,
+	})
+
+	// The first confirm fails after reaching the backend, the retry succeeds
+	minter.Reconcile(context.Background(), "retry-agent")
+	if _, err := minter.Reconcile(context.Background(), "retry-agent"); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	backend.mu.Lock()
+	keys := append([]string(nil), backend.idempotencyKeys...)
+	backend.mu.Unlock()
+	want := ConfirmMintIdempotencyKey("retry-agent", "0xminted")
+	if len(keys) != 2 || keys[0] != want || keys[1] != want {
+		t.Errorf("%s headers = %q, want %q on the initial call and the retry", IdempotencyKeyHeader, keys, want)
+	}
+	if confirms := backend.confirmed(); len(confirms) != 1 || confirms[0].IdempotencyKey != want {
+		t.Errorf("unexpected confirm-mint requests: %+v", confirms)
+	}
+
+	if other := ConfirmMintIdempotencyKey("retry-agent", "0xother"); other == want {
+		t.Error("a different mint transaction must get a different key")
+	}
+}
+
+func TestMinter_ReconcileRequiresOwnership(t *testing.T) {
+	backend := newUnconfirmedBackend(t, 0)
+	other := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	minter, signer := newReconcileMinter(t, backend.URL, newReconcileChain(t, other, 5).URL)
+
+	tokenID := uint64(5)
+	minter.walClient.Save(&WALEntry{
+		AgentID:         "someone-elses",
+		Wallet:          signer.Address().Hex(),
+		State:           WALStateConfirming,
+		PendingTokenID:  &tokenID,
+		ContractAddress: testContractAddress,
+		ChainID:         "3338",
+	})
+
+	if _, err := minter.Reconcile(context.Background(), "someone-elses"); !errors.Is(err, ErrNotTokenOwner) {
+		t.Errorf("Reconcile() error = %v, want ErrNotTokenOwner", err)
+	}
+	if n := len(backend.confirmed()); n != 0 {
+		t.Errorf("expected no confirm-mint, got %d", n)
+	}
+}
@@ -0,0 +1,110 @@
+package deploy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// schemaCacheTTL is how long a fetched schema is used before refetching
+const schemaCacheTTL = time.Hour
+
+// schemaCacheFile is the on-disk form of a SchemaCache
+type schemaCacheFile struct {
+	BackendURL string          `json:"backend_url"`
+	FetchedAt  time.Time       `json:"fetched_at"`
+	Schema     *SchemaResponse `json:"schema"`
+}
+
+// DefaultSchemaCacheDir returns the default schema cache directory:
+// <user cache dir>/teneo/schema/, or "" if the platform has no cache dir
+func DefaultSchemaCacheDir() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cacheDir, "teneo", "schema")
+}
+
+// schemaCachePath returns the cache file for a backend. Each backend URL gets
+// its own file since schemas differ between environments.
+func schemaCachePath(dir, backendURL string) string {
+	if dir == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(backendURL))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// loadSchemaCacheFile reads a persisted schema cache. A missing, corrupt or
+// foreign file returns nil so the schema is refetched.
+func loadSchemaCacheFile(path, backendURL string) *SchemaCache {
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️ Failed to read schema cache: %v", err)
+		}
+		return nil
+	}
+
+	var file schemaCacheFile
+	if err := json.Unmarshal(data, &file); err != nil || file.Schema == nil || file.FetchedAt.IsZero() {
+		log.Printf("⚠️ Ignoring corrupt schema cache %s", path)
+		return nil
+	}
+	if file.BackendURL != backendURL {
+		return nil
+	}
+
+	return &SchemaCache{Schema: file.Schema, FetchedAt: file.FetchedAt}
+}
+
+// saveSchemaCacheFile persists a schema cache atomically using temp file + rename
+func saveSchemaCacheFile(path, backendURL string, cache *SchemaCache) error {
+	if path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(schemaCacheFile{
+		BackendURL: backendURL,
+		FetchedAt:  cache.FetchedAt,
+		Schema:     cache.Schema,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal schema cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create schema cache directory: %w", err)
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create schema cache temp file: %w", err)
+	}
+	tempPath := temp.Name()
+
+	_, err = temp.Write(data)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to write schema cache: %w", err)
+	}
+
+	if err := os.Rename(tempPath, path); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to rename schema cache: %w", err)
+	}
+	return nil
+}
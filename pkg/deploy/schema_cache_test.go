@@ -0,0 +1,128 @@
+package deploy
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+// newSchemaCacheBackend serves a schema with the given version and counts fetches
+func newSchemaCacheBackend(t *testing.T, schemaVersion string) (url string, fetches *int) {
+	t.Helper()
+	fetches = new(int)
+	srv := newFakeBackend(t, map[string]http.HandlerFunc{
+		"/api/sdk/schema": func(w http.ResponseWriter, r *http.Request) {
+			*fetches++
+			writeJSON(w, http.StatusOK, SchemaResponse{SchemaVersion: schemaVersion, MaxJSONSize: DefaultMaxJSONSize})
+		},
+	})
+	return srv.URL, fetches
+}
+
+func newSchemaCacheMinter(t *testing.T, backendURL, cacheDir string) *Minter {
+	t.Helper()
+	minter, err := NewMinter(&MintConfig{
+		PrivateKey:     newTestPrivateKey(t),
+		BackendURL:     backendURL,
+		SchemaCacheDir: cacheDir,
+	})
+	if err != nil {
+		t.Fatalf("failed to create minter: %v", err)
+	}
+	return minter
+}
+
+func TestSchemaCache_NewMinterReadsCacheFile(t *testing.T) {
+	backendURL, fetches := newSchemaCacheBackend(t, "live")
+	cacheDir := t.TempDir()
+
+	cached := &SchemaCache{
+		Schema:    &SchemaResponse{SchemaVersion: "cached", MaxJSONSize: 1024},
+		FetchedAt: time.Now().Add(-10 * time.Minute),
+	}
+	if err := saveSchemaCacheFile(schemaCachePath(cacheDir, backendURL), backendURL, cached); err != nil {
+		t.Fatalf("saveSchemaCacheFile() error = %v", err)
+	}
+
+	schema, err := newSchemaCacheMinter(t, backendURL, cacheDir).getSchema(context.Background())
+	if err != nil {
+		t.Fatalf("getSchema() error = %v", err)
+	}
+	if *fetches != 0 {
+		t.Errorf("expected no HTTP fetch with a fresh cache file, got %d", *fetches)
+	}
+	if schema.SchemaVersion != "cached" || schema.MaxJSONSize != 1024 {
+		t.Errorf("expected cached schema, got %+v", schema)
+	}
+}
+
+func TestSchemaCache_PersistsAcrossMinters(t *testing.T) {
+	backendURL, fetches := newSchemaCacheBackend(t, "3")
+	cacheDir := t.TempDir()
+
+	if _, err := newSchemaCacheMinter(t, backendURL, cacheDir).getSchema(context.Background()); err != nil {
+		t.Fatalf("getSchema() error = %v", err)
+	}
+	schema, err := newSchemaCacheMinter(t, backendURL, cacheDir).getSchema(context.Background())
+	if err != nil {
+		t.Fatalf("getSchema() error = %v", err)
+	}
+	if *fetches != 1 || schema.SchemaVersion != "3" {
+		t.Errorf("expected a single fetch shared by both minters, got %d fetches, %+v", *fetches, schema)
+	}
+
+	// A different backend must not reuse the cached schema
+	otherURL, otherFetches := newSchemaCacheBackend(t, "4")
+	if schema, _ := newSchemaCacheMinter(t, otherURL, cacheDir).getSchema(context.Background()); *otherFetches != 1 || schema.SchemaVersion != "4" {
+		t.Errorf("expected other backend to fetch its own schema, got %d fetches, %+v", *otherFetches, schema)
+	}
+}
+
+func TestSchemaCache_StaleFileRefetched(t *testing.T) {
+	backendURL, fetches := newSchemaCacheBackend(t, "live")
+	cacheDir := t.TempDir()
+	path := schemaCachePath(cacheDir, backendURL)
+
+	stale := &SchemaCache{
+		Schema:    &SchemaResponse{SchemaVersion: "stale"},
+		FetchedAt: time.Now().Add(-2 * schemaCacheTTL),
+	}
+	if err := saveSchemaCacheFile(path, backendURL, stale); err != nil {
+		t.Fatal(err)
+	}
+
+	schema, err := newSchemaCacheMinter(t, backendURL, cacheDir).getSchema(context.Background())
+	if err != nil {
+		t.Fatalf("getSchema() error = %v", err)
+	}
+	if *fetches != 1 || schema.SchemaVersion != "live" {
+		t.Errorf("expected stale cache to be refetched, got %d fetches, %+v", *fetches, schema)
+	}
+	if reloaded := loadSchemaCacheFile(path, backendURL); reloaded == nil || reloaded.Schema.SchemaVersion != "live" {
+		t.Errorf("expected cache file to be refreshed, got %+v", reloaded)
+	}
+}
+
+func TestSchemaCache_CorruptFileRefetched(t *testing.T) {
+	backendURL, fetches := newSchemaCacheBackend(t, "live")
+	cacheDir := t.TempDir()
+	path := schemaCachePath(cacheDir, backendURL)
+
+	os.MkdirAll(cacheDir, 0700)
+	if err := os.WriteFile(path, []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	schema, err := newSchemaCacheMinter(t, backendURL, cacheDir).getSchema(context.Background())
+	if err != nil {
+		t.Fatalf("getSchema() error = %v", err)
+	}
+	if *fetches != 1 || schema.SchemaVersion != "live" {
+		t.Errorf("expected corrupt cache to be refetched, got %d fetches, %+v", *fetches, schema)
+	}
+	if loadSchemaCacheFile(path, backendURL) == nil {
+		t.Error("expected corrupt cache file to be replaced")
+	}
+}
@@ -0,0 +1,144 @@
+package deploy
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"unicode/utf8"
+)
+
+// bundledSchemaSnapshot is the /api/sdk/schema response shipped with the SDK.
+// It is used when the backend cannot be reached, e.g. in air-gapped CI.
+//
+//go:embed schema_snapshot.json
+var bundledSchemaSnapshot []byte
+
+// loadSchemaSnapshot reads a schema snapshot from path, or the bundled one if
+// path is empty. The file has the same format as the schema endpoint response.
+func loadSchemaSnapshot(path string) (*SchemaResponse, error) {
+	data := bundledSchemaSnapshot
+	if path != "" {
+		var err error
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema snapshot: %w", err)
+		}
+	}
+
+	var schema SchemaResponse
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("failed to parse schema snapshot: %w", err)
+	}
+	return &schema, nil
+}
+
+// schemaProperty is the subset of JSON Schema keywords checked locally
+type schemaProperty struct {
+	Type      string        `json:"type"`
+	MinLength *int          `json:"minLength"`
+	MaxLength *int          `json:"maxLength"`
+	MinItems  *int          `json:"minItems"`
+	MaxItems  *int          `json:"maxItems"`
+	Enum      []interface{} `json:"enum"`
+}
+
+// objectSchema is the top-level shape of the agent config schema
+type objectSchema struct {
+	Required   []string                  `json:"required"`
+	Properties map[string]schemaProperty `json:"properties"`
+}
+
+// validateAgainstSchema checks the raw agent JSON against the top-level
+// required, type, length, item-count and enum rules of a backend schema.
+// Nested rules are left to validateConfig and the backend.
+func validateAgainstSchema(data []byte, schema *SchemaResponse) error {
+	if schema == nil || len(schema.Schema) == 0 {
+		return nil
+	}
+
+	var rules objectSchema
+	if err := json.Unmarshal(schema.Schema, &rules); err != nil {
+		return fmt.Errorf("invalid schema: %w", err)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	for _, name := range rules.Required {
+		if _, ok := fields[name]; !ok {
+			return fmt.Errorf("%s is required by schema", name)
+		}
+	}
+
+	// Sorted for deterministic error messages
+	names := make([]string, 0, len(rules.Properties))
+	for name := range rules.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		raw, ok := fields[name]
+		if !ok || string(raw) == "null" {
+			continue
+		}
+		if err := rules.Properties[name].validate(name, raw); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validate checks a single field value against its property rules
+func (p schemaProperty) validate(name string, raw json.RawMessage) error {
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return fmt.Errorf("%s: invalid value: %w", name, err)
+	}
+
+	switch p.Type {
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s must be a string", name)
+		}
+		if p.MinLength != nil && utf8.RuneCountInString(s) < *p.MinLength {
+			return fmt.Errorf("%s must be at least %d characters", name, *p.MinLength)
+		}
+		if p.MaxLength != nil && utf8.RuneCountInString(s) > *p.MaxLength {
+			return fmt.Errorf("%s must not exceed %d characters", name, *p.MaxLength)
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s must be an array", name)
+		}
+		if p.MinItems != nil && len(items) < *p.MinItems {
+			return fmt.Errorf("%s must have at least %d items", name, *p.MinItems)
+		}
+		if p.MaxItems != nil && len(items) > *p.MaxItems {
+			return fmt.Errorf("%s must not have more than %d items", name, *p.MaxItems)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s must be a boolean", name)
+		}
+	}
+
+	if len(p.Enum) > 0 {
+		for _, allowed := range p.Enum {
+			if reflect.DeepEqual(allowed, value) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s must be one of %v", name, p.Enum)
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newOfflineSchemaMinter returns a minter whose backend fails every schema
+// fetch and counts the attempts
+func newOfflineSchemaMinter(t *testing.T, snapshotPath string) (*Minter, *int) {
+	t.Helper()
+	fetches := new(int)
+	srv := newFakeBackend(t, map[string]http.HandlerFunc{
+		"/api/sdk/schema": func(w http.ResponseWriter, r *http.Request) {
+			*fetches++
+			writeJSON(w, http.StatusServiceUnavailable, ErrorResponse{Error: "offline"})
+		},
+	})
+
+	minter, err := NewMinter(&MintConfig{
+		PrivateKey:         newTestPrivateKey(t),
+		BackendURL:         srv.URL,
+		SchemaSnapshotPath: snapshotPath,
+	})
+	if err != nil {
+		t.Fatalf("failed to create minter: %v", err)
+	}
+	return minter, fetches
+}
+
+// writeAgentJSON writes raw agent JSON to a temp file and returns its path
+func writeAgentJSON(t *testing.T, fields map[string]interface{}) string {
+	t.Helper()
+	data, _ := json.Marshal(fields)
+	path := filepath.Join(t.TempDir(), "agent.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func validAgentFields() map[string]interface{} {
+	return map[string]interface{}{
+		"name":         "Offline Agent",
+		"agentId":      "offline-agent",
+		"description":  "Validated without network access",
+		"agentType":    "command",
+		"categories":   []string{"AI"},
+		"capabilities": []map[string]string{{"name": "cap"}},
+	}
+}
+
+func TestBundledSchemaSnapshotParses(t *testing.T) {
+	schema, err := loadSchemaSnapshot("")
+	if err != nil {
+		t.Fatalf("loadSchemaSnapshot() error = %v", err)
+	}
+	if schema.SchemaVersion == "" || schema.MaxJSONSize != DefaultMaxJSONSize || len(schema.Schema) == 0 {
+		t.Errorf("unexpected bundled snapshot: version %q, max size %d", schema.SchemaVersion, schema.MaxJSONSize)
+	}
+	if err := validateAgainstSchema(mustJSON(t, validAgentFields()), schema); err != nil {
+		t.Errorf("valid config rejected by bundled schema: %v", err)
+	}
+}
+
+func TestLoadConfig_FallsBackToBundledSnapshot(t *testing.T) {
+	minter, fetches := newOfflineSchemaMinter(t, "")
+
+	config, schemaVersion, err := minter.loadConfig(context.Background(), writeAgentJSON(t, validAgentFields()))
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if *fetches != 1 {
+		t.Errorf("expected one schema fetch attempt, got %d", *fetches)
+	}
+	if config.AgentID != "offline-agent" || schemaVersion != "3" {
+		t.Errorf("unexpected result: %s, schema version %q", config.AgentID, schemaVersion)
+	}
+
+	// validateAgainstSchema runs before the built-in checks, so a
+	// schema-specific message proves the snapshot was applied
+	fields := validAgentFields()
+	delete(fields, "categories")
+	_, _, err = minter.loadConfig(context.Background(), writeAgentJSON(t, fields))
+	if err == nil || !strings.Contains(err.Error(), "schema validation failed: categories is required") {
+		t.Errorf("expected snapshot schema error, got %v", err)
+	}
+}
+
+func TestLoadConfig_SchemaSnapshotPathOverride(t *testing.T) {
+	snapshot := SchemaResponse{
+		SchemaVersion: "4",
+		MaxJSONSize:   DefaultMaxJSONSize,
+		Schema:        json.RawMessage(`{"required":["image"],"properties":{"image":{"type":"string","minLength":1}}}`),
+	}
+	snapshotPath := filepath.Join(t.TempDir(), "schema.json")
+	if err := os.WriteFile(snapshotPath, mustJSON(t, snapshot), 0600); err != nil {
+		t.Fatal(err)
+	}
+	minter, _ := newOfflineSchemaMinter(t, snapshotPath)
+
+	_, _, err := minter.loadConfig(context.Background(), writeAgentJSON(t, validAgentFields()))
+	if err == nil || !strings.Contains(err.Error(), "image is required") {
+		t.Fatalf("expected override snapshot to require image, got %v", err)
+	}
+
+	fields := validAgentFields()
+	fields["image"] = "https://example.com/a.png"
+	_, schemaVersion, err := minter.loadConfig(context.Background(), writeAgentJSON(t, fields))
+	if err != nil || schemaVersion != "4" {
+		t.Errorf("loadConfig() = schema version %q, %v; want override version 4", schemaVersion, err)
+	}
+}
+
+func TestLoadConfig_MissingSnapshotFallsBackToLocalValidation(t *testing.T) {
+	minter, _ := newOfflineSchemaMinter(t, filepath.Join(t.TempDir(), "missing.json"))
+
+	_, schemaVersion, err := minter.loadConfig(context.Background(), writeAgentJSON(t, validAgentFields()))
+	if err != nil || schemaVersion != "" {
+		t.Errorf("loadConfig() = schema version %q, %v; want local validation only", schemaVersion, err)
+	}
+}
+
+func TestValidateAgainstSchema(t *testing.T) {
+	schema, err := loadSchemaSnapshot("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(map[string]interface{})
+		wantErr string
+	}{
+		{"valid", func(map[string]interface{}) {}, ""},
+		{"name too short", func(f map[string]interface{}) { f["name"] = "ab" }, "name must be at least 3 characters"},
+		{"wrong type", func(f map[string]interface{}) { f["name"] = 42 }, "name must be a string"},
+		{"enum", func(f map[string]interface{}) { f["agentType"] = "robot" }, "agentType must be one of"},
+		{"too many items", func(f map[string]interface{}) { f["categories"] = []string{"a", "b", "c"} }, "categories must not have more than 2 items"},
+		{"null optional", func(f map[string]interface{}) { f["commands"] = nil }, ""},
+		{"boolean", func(f map[string]interface{}) { f["nlpFallback"] = "yes" }, "nlpFallback must be a boolean"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := validAgentFields()
+			tt.modify(fields)
+			err := validateAgainstSchema(mustJSON(t, fields), schema)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	if err := validateAgainstSchema([]byte(`{}`), &SchemaResponse{}); err != nil {
+		t.Errorf("schema without rules should accept anything, got %v", err)
+	}
+}
+
+func mustJSON(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
@@ -0,0 +1,68 @@
+package deploy
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/auth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Signer signs on behalf of a wallet without exposing its key. Implement it to
+// keep keys in a hardware wallet, a KMS or a remote signing service.
+// Implementations must be safe for concurrent use.
+type Signer interface {
+	// Address returns the wallet address
+	Address() common.Address
+
+	// Sign signs a 32-byte hash and returns a 65-byte [R || S || V] signature.
+	// V may be 0/1 or 27/28; callers normalize it.
+	Sign(hash []byte) ([]byte, error)
+
+	// SignTx signs a transaction for the given chain
+	SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// PrivateKeySigner is the default Signer, backed by an in-memory private key
+type PrivateKeySigner struct {
+	privateKey *ecdsa.PrivateKey
+	address    common.Address
+}
+
+// NewPrivateKeySigner parses a hex private key, with or without 0x prefix
+func NewPrivateKeySigner(privateKeyHex string) (*PrivateKeySigner, error) {
+	privateKey, address, err := auth.ParsePrivateKey(privateKeyHex)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PrivateKeySigner{
+		privateKey: privateKey,
+		address:    address,
+	}, nil
+}
+
+// Address returns the address derived from the private key
+func (s *PrivateKeySigner) Address() common.Address {
+	return s.address
+}
+
+// Sign signs hash with the private key, returning a 0/1 recovery ID
+func (s *PrivateKeySigner) Sign(hash []byte) ([]byte, error) {
+	return crypto.Sign(hash, s.privateKey)
+}
+
+// SignTx signs tx with an EIP-155 signer for chainID
+func (s *PrivateKeySigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.NewEIP155Signer(chainID), s.privateKey)
+}
+
+// resolveSigner returns signer if set, otherwise a PrivateKeySigner for privateKeyHex
+func resolveSigner(signer Signer, privateKeyHex string) (Signer, error) {
+	if signer != nil {
+		return signer, nil
+	}
+	return NewPrivateKeySigner(privateKeyHex)
+}
@@ -0,0 +1,155 @@
+package deploy
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// softwareSigner stands in for an external signer: it only exposes the
+// Signer methods, reports 27/28 recovery IDs and counts transaction signatures
+type softwareSigner struct {
+	inner   *PrivateKeySigner
+	signTxs atomic.Int32
+}
+
+func newSoftwareSigner(t *testing.T, privateKeyHex string) *softwareSigner {
+	t.Helper()
+	inner, err := NewPrivateKeySigner(privateKeyHex)
+	if err != nil {
+		t.Fatalf("NewPrivateKeySigner() error = %v", err)
+	}
+	return &softwareSigner{inner: inner}
+}
+
+func (s *softwareSigner) Address() common.Address { return s.inner.Address() }
+
+func (s *softwareSigner) Sign(hash []byte) ([]byte, error) {
+	sig, err := s.inner.Sign(hash)
+	if err == nil {
+		sig[64] += 27
+	}
+	return sig, err
+}
+
+func (s *softwareSigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	s.signTxs.Add(1)
+	return s.inner.SignTx(tx, chainID)
+}
+
+func TestPrivateKeySigner_MatchesRawKey(t *testing.T) {
+	keyHex := newTestPrivateKey(t)
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(keyHex, "0x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := NewPrivateKeySigner("0x" + strings.TrimPrefix(keyHex, "0x"))
+	if err != nil {
+		t.Fatalf("NewPrivateKeySigner() error = %v", err)
+	}
+	if signer.Address() != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Errorf("Address() = %s, want %s", signer.Address().Hex(), crypto.PubkeyToAddress(key.PublicKey).Hex())
+	}
+
+	chainID := big.NewInt(3338)
+	tx := types.NewTransaction(0, common.HexToAddress(testContractAddress), big.NewInt(1), 21000, big.NewInt(1e9), []byte{1})
+
+	got, err := signer.SignTx(tx, chainID)
+	if err != nil {
+		t.Fatalf("SignTx() error = %v", err)
+	}
+	want, err := types.SignTx(tx, types.NewEIP155Signer(chainID), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Hash() != want.Hash() {
+		t.Errorf("signed tx %s, want %s from the raw-key path", got.Hash().Hex(), want.Hash().Hex())
+	}
+
+	sender, err := types.Sender(types.NewEIP155Signer(chainID), got)
+	if err != nil || sender != signer.Address() {
+		t.Errorf("tx sender = %s, %v; want %s", sender.Hex(), err, signer.Address().Hex())
+	}
+
+	if _, err := NewPrivateKeySigner("not-a-key"); err == nil {
+		t.Error("expected an error for an invalid key")
+	}
+}
+
+func TestAuthenticatorWithSigner_MatchesRawKey(t *testing.T) {
+	keyHex := newTestPrivateKey(t)
+
+	for _, eip712 := range []bool{false, true} {
+		raw, err := NewAuthenticator(keyHex, nil, WithEIP712Signing(eip712))
+		if err != nil {
+			t.Fatalf("NewAuthenticator() error = %v", err)
+		}
+		external := NewAuthenticatorWithSigner(newSoftwareSigner(t, keyHex), nil, WithEIP712Signing(eip712))
+
+		if external.GetAddress() != raw.GetAddress() {
+			t.Errorf("address = %s, want %s", external.GetAddress(), raw.GetAddress())
+		}
+
+		want, err := raw.SignChallenge("challenge")
+		if err != nil {
+			t.Fatalf("SignChallenge() error = %v", err)
+		}
+		got, err := external.SignChallenge("challenge")
+		if err != nil {
+			t.Fatalf("SignChallenge() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("eip712=%v: signer signature %s, want %s", eip712, got, want)
+		}
+	}
+}
+
+func TestChainClientWithSigner_ExecuteMint(t *testing.T) {
+	chain := newFakeChain(t, 11)
+	signer := newSoftwareSigner(t, newTestPrivateKey(t))
+
+	client, err := NewChainClientWithSigner(chain.URL, testContractAddress, "3338", signer)
+	if err != nil {
+		t.Fatalf("NewChainClientWithSigner() error = %v", err)
+	}
+	defer client.Close()
+
+	if client.GetAddress() != signer.Address().Hex() {
+		t.Errorf("GetAddress() = %s, want %s", client.GetAddress(), signer.Address().Hex())
+	}
+
+	result, err := client.ExecuteMint(context.Background(), "0x01", big.NewInt(1))
+	if err != nil {
+		t.Fatalf("ExecuteMint() error = %v", err)
+	}
+	if result.TokenID != 11 {
+		t.Errorf("TokenID = %d, want 11", result.TokenID)
+	}
+	if n := signer.signTxs.Load(); n != 1 {
+		t.Errorf("expected the signer to sign 1 transaction, got %d", n)
+	}
+}
+
+func TestNewMinter_AcceptsSignerWithoutPrivateKey(t *testing.T) {
+	t.Setenv("PRIVATE_KEY", "")
+	signer := newSoftwareSigner(t, newTestPrivateKey(t))
+
+	minter, err := NewMinter(&MintConfig{BackendURL: "http://localhost:0", Signer: signer})
+	if err != nil {
+		t.Fatalf("NewMinter() error = %v", err)
+	}
+	if got := minter.newAuthenticator().GetAddress(); got != signer.Address().Hex() {
+		t.Errorf("authenticator address = %s, want %s", got, signer.Address().Hex())
+	}
+
+	if _, err := NewMinter(&MintConfig{BackendURL: "http://localhost:0"}); err == nil {
+		t.Error("expected an error with neither a private key nor a signer")
+	}
+}
@@ -0,0 +1,96 @@
+package deploy
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/version"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// tracerName identifies spans created by this package
+const tracerName = "github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/deploy"
+
+// Span names for the phases of a deploy or mint. Each phase is a child of the
+// Deployer.Deploy or Minter.Mint span; backend requests are children of their phase.
+const (
+	SpanDeploy      = "Deployer.Deploy"
+	SpanMint        = "Minter.Mint"
+	SpanAuth        = "auth"
+	SpanSync        = "sync"
+	SpanDeployCall  = "deploy"
+	SpanOnChainMint = "mint"
+	SpanConfirm     = "confirm"
+)
+
+// Span attribute keys
+const (
+	AttrAgentID         = attribute.Key("teneo.agent_id")
+	AttrTxHash          = attribute.Key("teneo.tx_hash")
+	AttrTokenID         = attribute.Key("teneo.token_id")
+	AttrContractAddress = attribute.Key("teneo.contract_address")
+)
+
+// newTracer returns the package tracer from tp, or a no-op tracer when tp is nil
+func newTracer(tp trace.TracerProvider) trace.Tracer {
+	if tp == nil {
+		tp = noop.NewTracerProvider()
+	}
+	return tp.Tracer(tracerName, trace.WithInstrumentationVersion(version.Version()))
+}
+
+// startSpan starts a mint phase span, tolerating a Minter built without a tracer
+func (m *Minter) startSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	tracer := m.tracer
+	if tracer == nil {
+		tracer = newTracer(nil)
+	}
+	return tracer.Start(ctx, name, opts...)
+}
+
+// endSpan records err on span, if any, and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// tokenIDAttr formats a token ID as a span attribute
+func tokenIDAttr(tokenID uint64) attribute.KeyValue {
+	return AttrTokenID.String(strconv.FormatUint(tokenID, 10))
+}
+
+// doTraced sends req in a client span named after its method and path and
+// propagates the trace context to the backend in W3C traceparent headers
+func (c *HTTPClient) doTraced(req *http.Request) (*http.Response, error) {
+	ctx, span := c.tracer.Start(req.Context(), "HTTP "+req.Method+" "+req.URL.Path,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("url.full", req.URL.String()),
+		),
+	)
+	defer span.End()
+
+	req = req.WithContext(ctx)
+	propagation.TraceContext{}.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= 400 {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	return resp, nil
+}
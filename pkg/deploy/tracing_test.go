@@ -0,0 +1,140 @@
+package deploy
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestDeployer_TracesPhases(t *testing.T) {
+	chain := newFakeChain(t, 42)
+
+	var mu sync.Mutex
+	traceparents := map[string]string{}
+	recordTraceparent := func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		traceparents[r.URL.Path] = r.Header.Get("traceparent")
+	}
+	backend := newFakeBackend(t, map[string]http.HandlerFunc{
+		"/api/sdk/agent/deploy": func(w http.ResponseWriter, r *http.Request) {
+			recordTraceparent(w, r)
+			writeJSON(w, http.StatusOK, DeployResponse{
+				Signature:       "0x01",
+				ContractAddress: testContractAddress,
+				ChainID:         "3338",
+				RPCURL:          chain.URL,
+				ConfigHash:      "hash",
+			})
+		},
+		"/api/sdk/agent/confirm-mint": func(w http.ResponseWriter, r *http.Request) {
+			recordTraceparent(w, r)
+			writeJSON(w, http.StatusOK, ConfirmMintResponse{Success: true, ID: "db-id-1"})
+		},
+	})
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { tp.Shutdown(context.Background()) })
+
+	deployer, err := NewDeployer(&DeployConfig{
+		BackendURL:     backend.URL,
+		RPCEndpoint:    chain.URL,
+		PrivateKey:     newTestPrivateKey(t),
+		AgentID:        "traced-agent",
+		AgentName:      "Traced Agent",
+		Description:    "Traces deploy phases",
+		AgentType:      "command",
+		StateFilePath:  filepath.Join(t.TempDir(), "state.json"),
+		MintPrice:      big.NewInt(1),
+		TracerProvider: tp,
+	})
+	if err != nil {
+		t.Fatalf("NewDeployer() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	result, err := deployer.Deploy(ctx)
+	if err != nil {
+		t.Fatalf("Deploy() error = %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	byName := map[string]tracetest.SpanStub{}
+	for _, span := range spans {
+		byName[span.Name] = span
+	}
+	parentName := func(span tracetest.SpanStub) string {
+		for _, candidate := range spans {
+			if candidate.SpanContext.SpanID() == span.Parent.SpanID() {
+				return candidate.Name
+			}
+		}
+		return ""
+	}
+
+	root, ok := byName[SpanDeploy]
+	if !ok {
+		t.Fatalf("missing %s span, got %d spans", SpanDeploy, len(spans))
+	}
+	if root.Parent.IsValid() {
+		t.Errorf("%s should be a root span", SpanDeploy)
+	}
+
+	var phases []string
+	for _, span := range spans {
+		if parentName(span) == SpanDeploy {
+			phases = append(phases, span.Name)
+		}
+	}
+	wantPhases := []string{"HTTP GET /api/sdk/schema", SpanAuth, SpanDeployCall, SpanOnChainMint, SpanConfirm}
+	if !reflect.DeepEqual(phases, wantPhases) {
+		t.Errorf("phases under %s = %v, want %v", SpanDeploy, phases, wantPhases)
+	}
+
+	wantParents := map[string]string{
+		"HTTP POST /api/sdk/auth/challenge":     SpanAuth,
+		"HTTP POST /api/sdk/auth/verify":        SpanAuth,
+		"HTTP POST /api/sdk/agent/deploy":       SpanDeployCall,
+		"HTTP POST /api/sdk/agent/confirm-mint": SpanConfirm,
+	}
+	for name, want := range wantParents {
+		span, ok := byName[name]
+		if !ok {
+			t.Errorf("missing span %s", name)
+			continue
+		}
+		if got := parentName(span); got != want {
+			t.Errorf("span %s parent = %q, want %q", name, got, want)
+		}
+	}
+
+	for _, name := range []string{SpanDeploy, SpanOnChainMint} {
+		attrs := attribute.NewSet(byName[name].Attributes...)
+		if v, _ := attrs.Value(AttrTxHash); v.AsString() != result.TxHash {
+			t.Errorf("%s tx hash = %q, want %q", name, v.AsString(), result.TxHash)
+		}
+		if v, _ := attrs.Value(AttrTokenID); v.AsString() != "42" {
+			t.Errorf("%s token id = %q, want 42", name, v.AsString())
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for path, header := range traceparents {
+		if !strings.Contains(header, root.SpanContext.TraceID().String()) {
+			t.Errorf("%s traceparent = %q, want trace %s", path, header, root.SpanContext.TraceID())
+		}
+	}
+}
@@ -3,8 +3,6 @@ package deploy
 import (
 	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
 	"time"
 )
 
@@ -21,6 +19,8 @@ type WALEntry struct {
 	Wallet          string    `json:"wallet"`
 	State           string    `json:"state"` // IDLE, MINTING, CONFIRMING
 	PendingTxHash   string    `json:"pending_tx_hash,omitempty"`
+	PendingNonce    *uint64   `json:"pending_nonce,omitempty"`
+	SentTxHashes    []string  `json:"sent_tx_hashes,omitempty"` // Every tx sent for PendingNonce, speed-ups included
 	PendingTokenID  *uint64   `json:"pending_token_id,omitempty"`
 	ContractAddress string    `json:"contract_address,omitempty"`
 	ChainID         string    `json:"chain_id,omitempty"`
@@ -31,58 +31,87 @@ type WALEntry struct {
 	UpdatedAt       time.Time `json:"updated_at"`
 }
 
-// WALClient handles Write-Ahead Log operations
-type WALClient struct {
-	walDir string
+// recordSentTx records a transaction broadcast with nonce. A speed-up reuses
+// the nonce, so either transaction may be the one mined and all are kept.
+func (e *WALEntry) recordSentTx(txHash string, nonce uint64) {
+	if e.PendingNonce == nil || *e.PendingNonce != nonce {
+		e.SentTxHashes = nil
+	}
+	e.SentTxHashes = append(e.SentTxHashes, txHash)
+	e.PendingTxHash = txHash
+	e.PendingNonce = &nonce
+	e.UpdatedAt = time.Now()
 }
 
-// NewWALClient creates a new WAL client
-func NewWALClient() *WALClient {
-	// Default WAL directory: ~/.teneo/wal/
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		homeDir = "."
+// pendingTxHashes returns every transaction sent for the pending nonce.
+// Entries written before speed-ups were recorded only have PendingTxHash.
+func (e *WALEntry) pendingTxHashes() []string {
+	if len(e.SentTxHashes) > 0 {
+		return e.SentTxHashes
 	}
+	if e.PendingTxHash != "" {
+		return []string{e.PendingTxHash}
+	}
+	return nil
+}
 
-	walDir := filepath.Join(homeDir, ".teneo", "wal")
+// WALClient handles Write-Ahead Log operations on top of a WALStore
+type WALClient struct {
+	store  WALStore
+	cipher *walCipher // nil stores entries as plaintext JSON
+}
 
-	return &WALClient{
-		walDir: walDir,
+// NewWALClient creates a WAL client backed by store.
+// A nil store uses a FileWALStore in the default directory (~/.teneo/wal/).
+func NewWALClient(store WALStore) *WALClient {
+	if store == nil {
+		store = NewFileWALStore(DefaultWALDir())
 	}
-}
 
-// NewWALClientWithDir creates a WAL client with custom directory
-func NewWALClientWithDir(walDir string) *WALClient {
 	return &WALClient{
-		walDir: walDir,
+		store: store,
 	}
 }
 
-// getPath returns the WAL file path for an agent
-func (w *WALClient) getPath(agentID string) string {
-	return filepath.Join(w.walDir, agentID+".json")
+// NewWALClientWithDir creates a file-backed WAL client with custom directory
+func NewWALClientWithDir(walDir string) *WALClient {
+	return NewWALClient(NewFileWALStore(walDir))
 }
 
-// ensureDir ensures the WAL directory exists
-func (w *WALClient) ensureDir() error {
-	return os.MkdirAll(w.walDir, 0700)
+// SetEncryptionKey enables AES-GCM encryption of saved entries. key is either
+// a 32-byte hex key or a passphrase. Plaintext entries written before
+// encryption was enabled can still be loaded.
+func (w *WALClient) SetEncryptionKey(key string) error {
+	c, err := newWALCipher(key)
+	if err != nil {
+		return err
+	}
+	w.cipher = c
+	return nil
 }
 
 // Load loads a WAL entry for an agent
 func (w *WALClient) Load(agentID string) (*WALEntry, error) {
-	path := w.getPath(agentID)
-
-	data, err := os.ReadFile(path)
+	data, err := w.store.Load(agentID)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil // No WAL exists
+		return nil, err
+	}
+	if data == nil {
+		return nil, nil // No WAL exists
+	}
+
+	if env, ok := parseWALEnvelope(data); ok {
+		if w.cipher == nil {
+			return nil, ErrWALEncrypted
+		}
+		if data, err = w.cipher.open(env); err != nil {
+			return nil, err
 		}
-		return nil, fmt.Errorf("failed to read WAL file: %w", err)
 	}
 
 	var entry WALEntry
 	if err := json.Unmarshal(data, &entry); err != nil {
-		return nil, fmt.Errorf("failed to parse WAL file: %w", err)
+		return nil, fmt.Errorf("failed to parse WAL entry: %w", err)
 	}
 
 	return &entry, nil
@@ -90,10 +119,6 @@ func (w *WALClient) Load(agentID string) (*WALEntry, error) {
 
 // Save saves a WAL entry
 func (w *WALClient) Save(entry *WALEntry) error {
-	if err := w.ensureDir(); err != nil {
-		return fmt.Errorf("failed to create WAL directory: %w", err)
-	}
-
 	entry.UpdatedAt = time.Now()
 
 	data, err := json.MarshalIndent(entry, "", "  ")
@@ -101,62 +126,35 @@ func (w *WALClient) Save(entry *WALEntry) error {
 		return fmt.Errorf("failed to marshal WAL entry: %w", err)
 	}
 
-	path := w.getPath(entry.AgentID)
-
-	// Write atomically using temp file + rename
-	tempPath := path + ".tmp"
-	if err := os.WriteFile(tempPath, data, 0600); err != nil {
-		return fmt.Errorf("failed to write WAL temp file: %w", err)
-	}
-
-	if err := os.Rename(tempPath, path); err != nil {
-		os.Remove(tempPath)
-		return fmt.Errorf("failed to rename WAL temp file: %w", err)
+	if w.cipher != nil {
+		if data, err = w.cipher.seal(data); err != nil {
+			return fmt.Errorf("failed to encrypt WAL entry: %w", err)
+		}
 	}
 
-	return nil
+	return w.store.Save(entry.AgentID, data)
 }
 
 // Delete removes a WAL entry
 func (w *WALClient) Delete(agentID string) error {
-	path := w.getPath(agentID)
-
-	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to delete WAL file: %w", err)
-	}
-
-	return nil
+	return w.store.Delete(agentID)
 }
 
 // Exists checks if a WAL entry exists
 func (w *WALClient) Exists(agentID string) bool {
-	path := w.getPath(agentID)
-	_, err := os.Stat(path)
-	return err == nil
+	data, err := w.store.Load(agentID)
+	return err == nil && data != nil
 }
 
 // List lists all WAL entries
 func (w *WALClient) List() ([]*WALEntry, error) {
-	if err := w.ensureDir(); err != nil {
-		return nil, fmt.Errorf("failed to create WAL directory: %w", err)
-	}
-
-	entries, err := os.ReadDir(w.walDir)
+	agentIDs, err := w.store.List()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read WAL directory: %w", err)
+		return nil, err
 	}
 
 	var walEntries []*WALEntry
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-
-		if filepath.Ext(entry.Name()) != ".json" {
-			continue
-		}
-
-		agentID := entry.Name()[:len(entry.Name())-5] // Remove .json
+	for _, agentID := range agentIDs {
 		walEntry, err := w.Load(agentID)
 		if err != nil {
 			continue // Skip invalid entries
@@ -0,0 +1,178 @@
+package deploy
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// WAL encryption envelope parameters
+const (
+	walEnvelopeVersion = 1
+	walCipherAlg       = "AES-256-GCM"
+	walKDFRaw          = "raw"
+	walKDFPBKDF2       = "pbkdf2-sha256"
+	walPBKDF2Iter      = 200000
+	walSaltSize        = 16
+)
+
+var (
+	// ErrWALDecryptFailed is returned when an encrypted WAL entry cannot be
+	// decrypted, usually because the configured key is wrong
+	ErrWALDecryptFailed = errors.New("failed to decrypt WAL entry: wrong key or corrupted data")
+
+	// ErrWALEncrypted is returned when an encrypted WAL entry is loaded by a
+	// client without an encryption key
+	ErrWALEncrypted = errors.New("WAL entry is encrypted but no encryption key is configured")
+)
+
+// walEnvelope is the on-store format of an encrypted WAL entry
+type walEnvelope struct {
+	Version    int    `json:"version"`
+	Alg        string `json:"alg"`
+	KDF        string `json:"kdf"`
+	Salt       []byte `json:"salt,omitempty"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// walCipher encrypts WAL payloads with AES-GCM. The key is either a raw
+// 32-byte hex key or a passphrase stretched with PBKDF2.
+type walCipher struct {
+	rawKey     []byte // set when a hex key was supplied
+	passphrase string // set otherwise
+
+	mu      sync.Mutex
+	salt    []byte            // salt used for new entries
+	derived map[string][]byte // passphrase keys by salt, to avoid re-deriving on every call
+}
+
+// newWALCipher parses key as a 64-character hex key (optionally 0x-prefixed)
+// or, failing that, treats it as a passphrase
+func newWALCipher(key string) (*walCipher, error) {
+	if key == "" {
+		return nil, fmt.Errorf("WAL encryption key cannot be empty")
+	}
+
+	hexKey := strings.TrimPrefix(key, "0x")
+	if len(hexKey) == 64 {
+		if raw, err := hex.DecodeString(hexKey); err == nil {
+			return &walCipher{rawKey: raw}, nil
+		}
+	}
+
+	salt := make([]byte, walSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate WAL salt: %w", err)
+	}
+
+	return &walCipher{
+		passphrase: key,
+		salt:       salt,
+		derived:    make(map[string][]byte),
+	}, nil
+}
+
+// keyFor returns the AES key for an entry with the given KDF and salt
+func (c *walCipher) keyFor(kdf string, salt []byte) ([]byte, error) {
+	switch kdf {
+	case walKDFRaw:
+		if c.rawKey == nil {
+			return nil, ErrWALDecryptFailed
+		}
+		return c.rawKey, nil
+	case walKDFPBKDF2:
+		if c.passphrase == "" {
+			return nil, ErrWALDecryptFailed
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if key, ok := c.derived[string(salt)]; ok {
+			return key, nil
+		}
+		key, err := pbkdf2.Key(sha256.New, c.passphrase, salt, walPBKDF2Iter, 32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive WAL key: %w", err)
+		}
+		c.derived[string(salt)] = key
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported WAL key derivation: %s", kdf)
+	}
+}
+
+// seal encrypts a plaintext WAL payload into an envelope
+func (c *walCipher) seal(plaintext []byte) ([]byte, error) {
+	env := walEnvelope{Version: walEnvelopeVersion, Alg: walCipherAlg, KDF: walKDFRaw}
+	if c.rawKey == nil {
+		env.KDF = walKDFPBKDF2
+		env.Salt = c.salt
+	}
+
+	key, err := c.keyFor(env.KDF, env.Salt)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	env.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(env.Nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate WAL nonce: %w", err)
+	}
+	env.Ciphertext = aead.Seal(nil, env.Nonce, plaintext, nil)
+
+	return json.Marshal(env)
+}
+
+// open decrypts an envelope produced by seal
+func (c *walCipher) open(env *walEnvelope) ([]byte, error) {
+	if env.Version != walEnvelopeVersion || env.Alg != walCipherAlg {
+		return nil, fmt.Errorf("unsupported WAL encryption: version %d, %s", env.Version, env.Alg)
+	}
+
+	key, err := c.keyFor(env.KDF, env.Salt)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(env.Nonce) != aead.NonceSize() {
+		return nil, ErrWALDecryptFailed
+	}
+
+	plaintext, err := aead.Open(nil, env.Nonce, env.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrWALDecryptFailed
+	}
+	return plaintext, nil
+}
+
+// parseWALEnvelope reports whether data is an encrypted WAL envelope
+func parseWALEnvelope(data []byte) (*walEnvelope, bool) {
+	var env walEnvelope
+	if err := json.Unmarshal(data, &env); err != nil || env.Ciphertext == nil || env.Alg == "" {
+		return nil, false
+	}
+	return &env, true
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid WAL encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,361 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/cache"
+	"github.com/redis/go-redis/v9"
+)
+
+// WALStore persists serialized WAL entries keyed by agent ID.
+// Implementations must be safe for concurrent use.
+type WALStore interface {
+	// Load returns the stored entry, or nil data if none exists
+	Load(agentID string) ([]byte, error)
+
+	// Save stores an entry, replacing any previous one
+	Save(agentID string, data []byte) error
+
+	// Delete removes an entry. Deleting a missing entry is not an error.
+	Delete(agentID string) error
+
+	// List returns the agent IDs that have a stored entry
+	List() ([]string, error)
+}
+
+// File lock timing. Locks are held only for a single write, so a lock older
+// than fileLockStaleAge was left behind by a crashed process.
+const (
+	fileLockRetryInterval = 10 * time.Millisecond
+	fileLockTimeout       = 5 * time.Second
+	fileLockStaleAge      = 30 * time.Second
+)
+
+// FileWALStore stores each WAL entry as a JSON file in a directory.
+// Writes for the same agent are serialized within the process by a mutex and
+// across processes by a "<agentID>.lock" file; reads rely on atomic renames.
+type FileWALStore struct {
+	dir   string
+	locks sync.Map // agent ID -> *sync.Mutex
+}
+
+// NewFileWALStore creates a file store rooted at dir
+func NewFileWALStore(dir string) *FileWALStore {
+	return &FileWALStore{dir: dir}
+}
+
+// DefaultWALDir returns the default WAL directory: ~/.teneo/wal/
+func DefaultWALDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		homeDir = "."
+	}
+	return filepath.Join(homeDir, ".teneo", "wal")
+}
+
+// getPath returns the WAL file path for an agent
+func (s *FileWALStore) getPath(agentID string) string {
+	return filepath.Join(s.dir, agentID+".json")
+}
+
+// Load reads the WAL file for an agent
+func (s *FileWALStore) Load(agentID string) ([]byte, error) {
+	data, err := os.ReadFile(s.getPath(agentID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil // No WAL exists
+		}
+		return nil, fmt.Errorf("failed to read WAL file: %w", err)
+	}
+	return data, nil
+}
+
+// Save writes the WAL file atomically using temp file + rename
+func (s *FileWALStore) Save(agentID string, data []byte) error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create WAL directory: %w", err)
+	}
+
+	unlock, err := s.lock(agentID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// A unique temp name keeps concurrent writers from sharing a temp file
+	temp, err := os.CreateTemp(s.dir, agentID+".json.*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create WAL temp file: %w", err)
+	}
+	tempPath := temp.Name()
+
+	_, err = temp.Write(data)
+	if err == nil {
+		err = temp.Sync()
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to write WAL temp file: %w", err)
+	}
+
+	if err := os.Rename(tempPath, s.getPath(agentID)); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to rename WAL temp file: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the WAL file for an agent
+func (s *FileWALStore) Delete(agentID string) error {
+	if _, err := os.Stat(s.getPath(agentID)); os.IsNotExist(err) {
+		return nil
+	}
+
+	unlock, err := s.lock(agentID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := os.Remove(s.getPath(agentID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete WAL file: %w", err)
+	}
+	return nil
+}
+
+// lock takes the in-process and cross-process locks for an agent's WAL file
+func (s *FileWALStore) lock(agentID string) (func(), error) {
+	mu, _ := s.locks.LoadOrStore(agentID, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+
+	lockPath := filepath.Join(s.dir, agentID+".lock")
+	if err := acquireLockFile(lockPath); err != nil {
+		mu.(*sync.Mutex).Unlock()
+		return nil, err
+	}
+
+	return func() {
+		os.Remove(lockPath)
+		mu.(*sync.Mutex).Unlock()
+	}, nil
+}
+
+// acquireLockFile creates lockPath exclusively, waiting for other holders and
+// breaking locks older than fileLockStaleAge
+func acquireLockFile(lockPath string) error {
+	deadline := time.Now().Add(fileLockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return nil
+		}
+		if !os.IsExist(err) {
+			return fmt.Errorf("failed to create WAL lock file: %w", err)
+		}
+
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > fileLockStaleAge {
+			os.Remove(lockPath) // Left behind by a crashed process
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for WAL lock %s", lockPath)
+		}
+		time.Sleep(fileLockRetryInterval)
+	}
+}
+
+// List returns the agent IDs of all WAL files in the directory
+func (s *FileWALStore) List() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read WAL directory: %w", err)
+	}
+
+	var agentIDs []string
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		agentIDs = append(agentIDs, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+
+	return agentIDs, nil
+}
+
+// MemoryWALStore keeps WAL entries in process memory.
+// Entries do not survive a restart; useful for tests and read-only filesystems
+// where crash recovery is not needed.
+type MemoryWALStore struct {
+	mu      sync.RWMutex
+	entries map[string][]byte
+}
+
+// NewMemoryWALStore creates an empty in-memory store
+func NewMemoryWALStore() *MemoryWALStore {
+	return &MemoryWALStore{entries: make(map[string][]byte)}
+}
+
+// Load returns a copy of the stored entry
+func (s *MemoryWALStore) Load(agentID string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, ok := s.entries[agentID]
+	if !ok {
+		return nil, nil
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// Save stores a copy of the entry
+func (s *MemoryWALStore) Save(agentID string, data []byte) error {
+	s.mu.Lock()
+	s.entries[agentID] = append([]byte(nil), data...)
+	s.mu.Unlock()
+	return nil
+}
+
+// Delete removes an entry
+func (s *MemoryWALStore) Delete(agentID string) error {
+	s.mu.Lock()
+	delete(s.entries, agentID)
+	s.mu.Unlock()
+	return nil
+}
+
+// List returns the stored agent IDs in sorted order
+func (s *MemoryWALStore) List() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	agentIDs := make([]string, 0, len(s.entries))
+	for agentID := range s.entries {
+		agentIDs = append(agentIDs, agentID)
+	}
+	sort.Strings(agentIDs)
+	return agentIDs, nil
+}
+
+// redisWALKeySegment separates WAL keys from other keys under the same prefix
+const redisWALKeySegment = "wal:"
+
+// redisWALTimeout bounds each Redis round trip
+const redisWALTimeout = 5 * time.Second
+
+// RedisWALStore persists WAL entries in Redis so that ephemeral or
+// multi-replica deployments can recover a pending mint after a restart.
+// Keys are "<KeyPrefix>wal:<agentID>" and never expire.
+type RedisWALStore struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+// NewRedisWALStore connects to Redis using the same configuration as the agent cache
+func NewRedisWALStore(config *cache.RedisConfig) (*RedisWALStore, error) {
+	if config == nil {
+		config = cache.DefaultRedisConfig()
+	}
+
+	client, err := cache.NewRedisClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RedisWALStore{
+		client:    client,
+		keyPrefix: config.KeyPrefix + redisWALKeySegment,
+	}, nil
+}
+
+// key returns the Redis key for an agent's WAL entry
+func (s *RedisWALStore) key(agentID string) string {
+	return s.keyPrefix + agentID
+}
+
+// Load reads an agent's WAL entry from Redis
+func (s *RedisWALStore) Load(agentID string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisWALTimeout)
+	defer cancel()
+
+	data, err := s.client.Get(ctx, s.key(agentID)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil // No WAL exists
+		}
+		return nil, fmt.Errorf("failed to read WAL from Redis: %w", err)
+	}
+	return data, nil
+}
+
+// Save writes an agent's WAL entry to Redis without expiry
+func (s *RedisWALStore) Save(agentID string, data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisWALTimeout)
+	defer cancel()
+
+	if err := s.client.Set(ctx, s.key(agentID), data, 0).Err(); err != nil {
+		return fmt.Errorf("failed to write WAL to Redis: %w", err)
+	}
+	return nil
+}
+
+// Delete removes an agent's WAL entry from Redis
+func (s *RedisWALStore) Delete(agentID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisWALTimeout)
+	defer cancel()
+
+	if err := s.client.Del(ctx, s.key(agentID)).Err(); err != nil {
+		return fmt.Errorf("failed to delete WAL from Redis: %w", err)
+	}
+	return nil
+}
+
+// List scans for WAL keys under the store's prefix
+func (s *RedisWALStore) List() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisWALTimeout)
+	defer cancel()
+
+	var agentIDs []string
+	var cursor uint64
+	for {
+		keys, next, err := s.client.Scan(ctx, cursor, s.keyPrefix+"*", 100).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list WAL keys in Redis: %w", err)
+		}
+
+		for _, key := range keys {
+			if strings.HasPrefix(key, s.keyPrefix) {
+				agentIDs = append(agentIDs, strings.TrimPrefix(key, s.keyPrefix))
+			}
+		}
+
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+
+	return agentIDs, nil
+}
+
+// Close closes the Redis connection
+func (s *RedisWALStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,348 @@
+package deploy
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/cache"
+)
+
+// walStoreFactories builds every WALStore implementation for the contract tests
+var walStoreFactories = map[string]func(t *testing.T) WALStore{
+	"file": func(t *testing.T) WALStore {
+		return NewFileWALStore(t.TempDir())
+	},
+	"memory": func(t *testing.T) WALStore {
+		return NewMemoryWALStore()
+	},
+	"redis": func(t *testing.T) WALStore {
+		config := cache.DefaultRedisConfig()
+		config.Address = newFakeRedis(t)
+		config.KeyPrefix = "teneo:agent:test:"
+		store, err := NewRedisWALStore(config)
+		if err != nil {
+			t.Fatalf("NewRedisWALStore() error = %v", err)
+		}
+		t.Cleanup(func() { store.Close() })
+		return store
+	},
+}
+
+func TestWALStore_Contract(t *testing.T) {
+	for name, newStore := range walStoreFactories {
+		t.Run(name, func(t *testing.T) {
+			t.Run("LoadMissing", func(t *testing.T) {
+				data, err := newStore(t).Load("missing")
+				if err != nil || data != nil {
+					t.Errorf("Load() = %q, %v; want nil, nil", data, err)
+				}
+			})
+
+			t.Run("SaveLoadOverwrite", func(t *testing.T) {
+				store := newStore(t)
+				if err := store.Save("agent-1", []byte(`{"v":1}`)); err != nil {
+					t.Fatalf("Save() error = %v", err)
+				}
+				if err := store.Save("agent-1", []byte(`{"v":2}`)); err != nil {
+					t.Fatalf("Save() error = %v", err)
+				}
+
+				data, err := store.Load("agent-1")
+				if err != nil {
+					t.Fatalf("Load() error = %v", err)
+				}
+				if string(data) != `{"v":2}` {
+					t.Errorf("Load() = %q, want latest value", data)
+				}
+			})
+
+			t.Run("Delete", func(t *testing.T) {
+				store := newStore(t)
+				store.Save("agent-1", []byte("x"))
+
+				if err := store.Delete("agent-1"); err != nil {
+					t.Fatalf("Delete() error = %v", err)
+				}
+				if data, _ := store.Load("agent-1"); data != nil {
+					t.Errorf("expected entry to be gone, got %q", data)
+				}
+				if err := store.Delete("agent-1"); err != nil {
+					t.Errorf("deleting a missing entry should succeed, got %v", err)
+				}
+			})
+
+			t.Run("List", func(t *testing.T) {
+				store := newStore(t)
+				if ids, err := store.List(); err != nil || len(ids) != 0 {
+					t.Fatalf("List() on empty store = %v, %v", ids, err)
+				}
+
+				for _, id := range []string{"b-agent", "a-agent", "c-agent"} {
+					store.Save(id, []byte("x"))
+				}
+				store.Delete("c-agent")
+
+				ids, err := store.List()
+				if err != nil {
+					t.Fatalf("List() error = %v", err)
+				}
+				sort.Strings(ids)
+				if strings.Join(ids, ",") != "a-agent,b-agent" {
+					t.Errorf("List() = %v, want [a-agent b-agent]", ids)
+				}
+			})
+
+			t.Run("WALClient", func(t *testing.T) {
+				walClient := NewWALClient(newStore(t))
+				tokenID := uint64(7)
+				entry := &WALEntry{
+					AgentID:        "client-agent",
+					State:          WALStateConfirming,
+					PendingTxHash:  "0xabc",
+					PendingTokenID: &tokenID,
+					CreatedAt:      time.Now(),
+				}
+				if err := walClient.Save(entry); err != nil {
+					t.Fatalf("Save() error = %v", err)
+				}
+
+				loaded, err := walClient.Load("client-agent")
+				if err != nil || loaded == nil {
+					t.Fatalf("Load() = %v, %v", loaded, err)
+				}
+				if loaded.State != WALStateConfirming || loaded.PendingTokenID == nil || *loaded.PendingTokenID != 7 {
+					t.Errorf("unexpected entry after round trip: %+v", loaded)
+				}
+
+				list, err := walClient.List()
+				if err != nil || len(list) != 1 {
+					t.Errorf("List() = %d entries, %v; want 1", len(list), err)
+				}
+			})
+		})
+	}
+}
+
+func TestNewWALClient_DefaultsToFileStore(t *testing.T) {
+	walClient := NewWALClient(nil)
+	store, ok := walClient.store.(*FileWALStore)
+	if !ok {
+		t.Fatalf("expected *FileWALStore, got %T", walClient.store)
+	}
+	if store.dir != DefaultWALDir() {
+		t.Errorf("expected default dir %s, got %s", DefaultWALDir(), store.dir)
+	}
+}
+
+// newFakeRedis starts a minimal RESP server that supports the commands the
+// Redis WAL store uses (GET, SET, DEL, SCAN, PING) and returns its address
+func newFakeRedis(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	var mu sync.Mutex
+	data := make(map[string]string)
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveFakeRedis(conn, &mu, data)
+		}
+	}()
+
+	return ln.Addr().String()
+}
+
+func serveFakeRedis(conn net.Conn, mu *sync.Mutex, data map[string]string) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	for {
+		args, err := readRESPCommand(r)
+		if err != nil {
+			return
+		}
+
+		mu.Lock()
+		var reply string
+		switch strings.ToUpper(args[0]) {
+		case "PING":
+			reply = "+PONG\r\n"
+		case "GET":
+			if v, ok := data[args[1]]; ok {
+				reply = respBulk(v)
+			} else {
+				reply = "$-1\r\n"
+			}
+		case "SET":
+			data[args[1]] = args[2]
+			reply = "+OK\r\n"
+		case "DEL":
+			n := 0
+			for _, key := range args[1:] {
+				if _, ok := data[key]; ok {
+					delete(data, key)
+					n++
+				}
+			}
+			reply = fmt.Sprintf(":%d\r\n", n)
+		case "SCAN":
+			pattern := "*"
+			for i := 2; i+1 < len(args); i += 2 {
+				if strings.ToUpper(args[i]) == "MATCH" {
+					pattern = args[i+1]
+				}
+			}
+			var keys []string
+			for key := range data {
+				if ok, _ := path.Match(pattern, key); ok {
+					keys = append(keys, key)
+				}
+			}
+			reply = "*2\r\n" + respBulk("0") + fmt.Sprintf("*%d\r\n", len(keys))
+			for _, key := range keys {
+				reply += respBulk(key)
+			}
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		mu.Unlock()
+
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func readRESPCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("invalid command header %q", line)
+	}
+
+	args := make([]string, n)
+	for i := range args {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(header, "$")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid bulk header %q", header)
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func respBulk(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+func TestFileWALStore_ConcurrentSavers(t *testing.T) {
+	dir := t.TempDir()
+	// Two clients over the same directory stand in for separate Minter instances
+	clients := []*WALClient{NewWALClientWithDir(dir), NewWALClientWithDir(dir)}
+
+	const agents, rounds = 8, 25
+	var wg sync.WaitGroup
+	for a := 0; a < agents; a++ {
+		for c, walClient := range clients {
+			wg.Add(1)
+			go func(agentID string, writer int, walClient *WALClient) {
+				defer wg.Done()
+				for r := 0; r < rounds; r++ {
+					entry := &WALEntry{
+						AgentID:       agentID,
+						Wallet:        fmt.Sprintf("writer-%d", writer),
+						State:         WALStateMinting,
+						PendingTxHash: fmt.Sprintf("0x%s-%d-%d", agentID, writer, r),
+					}
+					if err := walClient.Save(entry); err != nil {
+						t.Errorf("Save(%s) error = %v", agentID, err)
+						return
+					}
+					if _, err := walClient.Load(agentID); err != nil {
+						t.Errorf("Load(%s) error = %v", agentID, err)
+						return
+					}
+				}
+			}(fmt.Sprintf("agent-%d", a), c, walClient)
+		}
+	}
+	wg.Wait()
+
+	walClient := clients[0]
+	for a := 0; a < agents; a++ {
+		agentID := fmt.Sprintf("agent-%d", a)
+		entry, err := walClient.Load(agentID)
+		if err != nil || entry == nil {
+			t.Fatalf("Load(%s) = %v, %v", agentID, entry, err)
+		}
+		// The surviving entry must be one writer's complete last write
+		want := fmt.Sprintf("0x%s-%s-%d", agentID, strings.TrimPrefix(entry.Wallet, "writer-"), rounds-1)
+		if entry.AgentID != agentID || entry.PendingTxHash != want {
+			t.Errorf("corrupted entry for %s: %+v", agentID, entry)
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	if len(files) != agents {
+		t.Errorf("expected only %d WAL files, found %v", agents, files)
+	}
+}
+
+func TestFileWALStore_BreaksStaleLock(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileWALStore(dir)
+
+	// Simulate a lock left behind by a crashed process
+	lockPath := filepath.Join(dir, "crashed-agent.lock")
+	if err := os.WriteFile(lockPath, []byte("12345\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * fileLockStaleAge)
+	os.Chtimes(lockPath, old, old)
+
+	done := make(chan error, 1)
+	go func() { done <- store.Save("crashed-agent", []byte(`{}`)) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	case <-time.After(fileLockTimeout):
+		t.Fatal("Save() blocked on a stale lock")
+	}
+
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Errorf("expected stale lock to be removed, got %v", err)
+	}
+}
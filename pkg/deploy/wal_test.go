@@ -1,8 +1,11 @@
 package deploy
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -255,6 +258,35 @@ func TestWALClient_TokenID(t *testing.T) {
 	}
 }
 
+func TestWALEntry_RecordSentTx(t *testing.T) {
+	entry := &WALEntry{AgentID: "speedup-test"}
+	if hashes := entry.pendingTxHashes(); len(hashes) != 0 {
+		t.Errorf("pendingTxHashes() = %v before any tx, want none", hashes)
+	}
+
+	// A speed-up reuses the nonce and keeps the original
+	entry.recordSentTx("0xoriginal", 7)
+	entry.recordSentTx("0xreplacement", 7)
+	if got := entry.pendingTxHashes(); !reflect.DeepEqual(got, []string{"0xoriginal", "0xreplacement"}) {
+		t.Errorf("pendingTxHashes() = %v, want both transactions", got)
+	}
+	if entry.PendingTxHash != "0xreplacement" || *entry.PendingNonce != 7 {
+		t.Errorf("pending tx = %s nonce %d, want the replacement", entry.PendingTxHash, *entry.PendingNonce)
+	}
+
+	// A new nonce starts over
+	entry.recordSentTx("0xnext", 8)
+	if got := entry.pendingTxHashes(); !reflect.DeepEqual(got, []string{"0xnext"}) {
+		t.Errorf("pendingTxHashes() = %v after a new nonce, want only the new tx", got)
+	}
+
+	// Entries written before speed-ups were recorded
+	legacy := &WALEntry{PendingTxHash: "0xlegacy"}
+	if got := legacy.pendingTxHashes(); !reflect.DeepEqual(got, []string{"0xlegacy"}) {
+		t.Errorf("pendingTxHashes() = %v, want the pending tx", got)
+	}
+}
+
 func TestWALStates(t *testing.T) {
 	// Verify constants
 	if WALStateIdle != "IDLE" {
@@ -267,3 +299,83 @@ func TestWALStates(t *testing.T) {
 		t.Errorf("WALStateConfirming = %q, want %q", WALStateConfirming, "CONFIRMING")
 	}
 }
+
+func TestWALClient_EncryptedRoundTrip(t *testing.T) {
+	keys := map[string]string{
+		"passphrase": "correct horse battery staple",
+		"hex key":    "0x" + strings.Repeat("ab", 32),
+	}
+
+	for name, key := range keys {
+		t.Run(name, func(t *testing.T) {
+			store := NewMemoryWALStore()
+			walClient := NewWALClient(store)
+			if err := walClient.SetEncryptionKey(key); err != nil {
+				t.Fatalf("SetEncryptionKey() error = %v", err)
+			}
+
+			entry := &WALEntry{
+				AgentID:         "secret-agent",
+				State:           WALStateMinting,
+				Signature:       "0xdeploysignature",
+				ContractAddress: "0xcontract",
+				CreatedAt:       time.Now(),
+			}
+			if err := walClient.Save(entry); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			raw, _ := store.Load("secret-agent")
+			if strings.Contains(string(raw), "0xdeploysignature") || strings.Contains(string(raw), "0xcontract") {
+				t.Fatalf("stored WAL contains plaintext: %s", raw)
+			}
+
+			// A fresh client with the same key decrypts it
+			reader := NewWALClient(store)
+			reader.SetEncryptionKey(key)
+			loaded, err := reader.Load("secret-agent")
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if loaded.Signature != entry.Signature || loaded.ContractAddress != entry.ContractAddress {
+				t.Errorf("decrypted entry mismatch: %+v", loaded)
+			}
+		})
+	}
+}
+
+func TestWALClient_WrongKeyFails(t *testing.T) {
+	store := NewMemoryWALStore()
+	walClient := NewWALClient(store)
+	walClient.SetEncryptionKey("right passphrase")
+	walClient.Save(&WALEntry{AgentID: "agent", State: WALStateMinting})
+
+	wrong := NewWALClient(store)
+	wrong.SetEncryptionKey("wrong passphrase")
+	if _, err := wrong.Load("agent"); !errors.Is(err, ErrWALDecryptFailed) {
+		t.Errorf("expected ErrWALDecryptFailed, got %v", err)
+	}
+
+	wrongKind := NewWALClient(store)
+	wrongKind.SetEncryptionKey(strings.Repeat("cd", 32))
+	if _, err := wrongKind.Load("agent"); !errors.Is(err, ErrWALDecryptFailed) {
+		t.Errorf("expected ErrWALDecryptFailed for hex key, got %v", err)
+	}
+
+	plain := NewWALClient(store)
+	if _, err := plain.Load("agent"); !errors.Is(err, ErrWALEncrypted) {
+		t.Errorf("expected ErrWALEncrypted without a key, got %v", err)
+	}
+}
+
+func TestWALClient_EncryptedClientReadsPlaintext(t *testing.T) {
+	store := NewMemoryWALStore()
+	NewWALClient(store).Save(&WALEntry{AgentID: "legacy", State: WALStateConfirming})
+
+	walClient := NewWALClient(store)
+	walClient.SetEncryptionKey("passphrase")
+	loaded, err := walClient.Load("legacy")
+	if err != nil || loaded == nil || loaded.State != WALStateConfirming {
+		t.Fatalf("expected plaintext entry to load, got %+v, %v", loaded, err)
+	}
+}
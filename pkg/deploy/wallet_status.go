@@ -0,0 +1,71 @@
+package deploy
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DefaultMinGasBalance returns the balance below which a wallet is reported
+// as low on gas (0.01 native tokens)
+func DefaultMinGasBalance() *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(16), nil)
+}
+
+// WalletStatus is the result of Minter.WalletStatus
+type WalletStatus struct {
+	Wallet     string
+	Balance    *big.Int // Wallet balance in wei
+	MinBalance *big.Int // Balance below which HasGas is false
+	Symbol     string   // Native token symbol, e.g. "PEAQ"
+	HasGas     bool
+
+	TokenID   uint64 // 0 = any agent NFT of the wallet
+	OwnsToken bool
+	Owner     string // Current owner of TokenID, empty when TokenID is 0
+}
+
+// FormattedBalance returns the balance in whole tokens with the symbol, e.g. "1.2 PEAQ"
+func (s *WalletStatus) FormattedBalance() string {
+	return FormatTokenAmount(s.Balance) + " " + s.Symbol
+}
+
+// WalletStatus reports the wallet's native balance and whether it still owns
+// agent NFT tokenID, or any agent NFT when tokenID is 0. It requires an RPC
+// endpoint.
+func (m *Minter) WalletStatus(ctx context.Context, tokenID uint64) (*WalletStatus, error) {
+	chainClient, contract, err := m.contractChainClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer chainClient.Close()
+
+	balance, err := chainClient.GetBalance(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status := &WalletStatus{
+		Wallet:     chainClient.GetAddress(),
+		Balance:    balance,
+		MinBalance: DefaultMinGasBalance(),
+		Symbol:     ChainSymbol(contract.ChainID),
+		TokenID:    tokenID,
+	}
+	status.HasGas = balance.Cmp(status.MinBalance) >= 0
+
+	if tokenID == 0 {
+		if status.OwnsToken, err = chainClient.HasAccess(ctx); err != nil {
+			return nil, err
+		}
+		return status, nil
+	}
+
+	owner, err := chainClient.OwnerOf(ctx, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	status.Owner = owner.Hex()
+	status.OwnsToken = owner == common.HexToAddress(status.Wallet)
+	return status, nil
+}
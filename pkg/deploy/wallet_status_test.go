@@ -0,0 +1,61 @@
+package deploy
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestMinter_WalletStatus(t *testing.T) {
+	other := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	tokenID := uint64(7)
+
+	tests := []struct {
+		name      string
+		balance   *big.Int
+		owner     *common.Address // nil = the wallet
+		tokenID   uint64
+		wantOwns  bool
+		wantGas   bool
+		wantOwner bool
+	}{
+		{"owned with gas", peaq(t, "1"), nil, tokenID, true, true, true},
+		{"transferred away", peaq(t, "1"), &other, tokenID, false, true, true},
+		{"out of gas", peaq(t, "0.001"), nil, tokenID, true, false, true},
+		{"any token", peaq(t, "0.01"), nil, 0, true, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minter := newBalanceTestMinter(t, "http://rpc.invalid", "3338", nil)
+			chain := newFakeChainOps(minter.signer.Address())
+			chain.balance = tt.balance
+			owner := chain.address
+			if tt.owner != nil {
+				owner = *tt.owner
+			}
+			chain.owners[tokenID] = owner
+			chain.ownedToken = &tokenID
+			minter.newChain = chain.factory()
+
+			status, err := minter.WalletStatus(context.Background(), tt.tokenID)
+			if err != nil {
+				t.Fatalf("WalletStatus() error = %v", err)
+			}
+			if status.OwnsToken != tt.wantOwns || status.HasGas != tt.wantGas {
+				t.Errorf("WalletStatus() = owns %v, has gas %v, want %v, %v", status.OwnsToken, status.HasGas, tt.wantOwns, tt.wantGas)
+			}
+			if (status.Owner != "") != tt.wantOwner || (tt.wantOwner && status.Owner != owner.Hex()) {
+				t.Errorf("owner = %q, want %s", status.Owner, owner.Hex())
+			}
+			if status.Wallet != chain.address.Hex() || status.Symbol != "PEAQ" || status.Balance.Cmp(tt.balance) != 0 {
+				t.Errorf("unexpected status: %+v", status)
+			}
+			if !chain.closed {
+				t.Error("chain client was not closed")
+			}
+		})
+	}
+}
@@ -1,20 +1,43 @@
 package health
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"sort"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/types"
 )
 
+// DefaultCheckTimeout bounds how long a registered check may run per probe
+const DefaultCheckTimeout = 3 * time.Second
+
 // Server provides health monitoring endpoints
 type Server struct {
 	port         int
+	infoMu       sync.RWMutex
 	agentInfo    *AgentInfo
 	statusGetter StatusGetter
 	server       *http.Server
+
+	checksMu     sync.RWMutex
+	checks       map[string]CheckFunc
+	checkTimeout time.Duration
+}
+
+// CheckFunc is a custom readiness check. It returns nil when the dependency
+// it checks is healthy and must honour ctx cancellation.
+type CheckFunc func(ctx context.Context) error
+
+// CheckResult is the outcome of a custom check
+type CheckResult struct {
+	Status string `json:"status"` // "pass" or "fail"
+	Error  string `json:"error,omitempty"`
 }
 
 // AgentInfo contains basic agent information
@@ -34,15 +57,62 @@ type StatusGetter interface {
 	GetUptime() time.Duration
 }
 
+// QueueReporter is optionally implemented by a StatusGetter that queues tasks
+// beyond its concurrency limit. Others report no queued tasks.
+type QueueReporter interface {
+	GetQueuedTaskCount() int
+}
+
+// ShutdownReporter is optionally implemented by a StatusGetter to take the
+// agent out of readiness while it shuts down
+type ShutdownReporter interface {
+	IsShuttingDown() bool
+}
+
+// PauseReporter is optionally implemented by a StatusGetter whose task
+// processing can be paused. A paused agent is reported but not ready.
+type PauseReporter interface {
+	IsPaused() bool
+}
+
+// MetricsReporter is optionally implemented by a StatusGetter that tracks
+// task metrics, which are then included in /status
+type MetricsReporter interface {
+	GetMetrics() types.AgentMetrics
+}
+
+// WalletInfo is the last on-chain check of the agent's wallet
+type WalletInfo struct {
+	Address   string    `json:"address"`
+	Balance   string    `json:"balance,omitempty"` // e.g. "1.2 PEAQ"
+	HasGas    bool      `json:"has_gas"`
+	TokenID   uint64    `json:"token_id,omitempty"`
+	OwnsToken bool      `json:"owns_token"`
+	CheckedAt time.Time `json:"checked_at"`
+	Error     string    `json:"error,omitempty"` // set when the last check failed
+}
+
+// WalletReporter is optionally implemented by a StatusGetter that checks its
+// wallet on-chain. The last result is included in /status.
+type WalletReporter interface {
+	GetWalletInfo() *WalletInfo
+}
+
 // HealthStatus represents the agent's health status
 type HealthStatus struct {
 	Status        string    `json:"status"`
 	Connected     bool      `json:"connected"`
 	Authenticated bool      `json:"authenticated"`
+	Paused        bool      `json:"paused"`
 	ActiveTasks   int       `json:"active_tasks"`
+	QueuedTasks   int       `json:"queued_tasks"`
 	Uptime        string    `json:"uptime"`
 	Timestamp     time.Time `json:"timestamp"`
 	Agent         AgentInfo `json:"agent"`
+
+	Checks  map[string]CheckResult `json:"checks,omitempty"`
+	Metrics *types.AgentMetrics    `json:"metrics,omitempty"`
+	Wallet  *WalletInfo            `json:"wallet,omitempty"`
 }
 
 // NewServer creates a new health monitoring server
@@ -51,26 +121,133 @@ func NewServer(port int, agentInfo *AgentInfo, statusGetter StatusGetter) *Serve
 		port:         port,
 		agentInfo:    agentInfo,
 		statusGetter: statusGetter,
+		checks:       make(map[string]CheckFunc),
+		checkTimeout: DefaultCheckTimeout,
+	}
+}
+
+// RegisterCheck adds a custom readiness check, e.g. "openai" or "redis".
+// Checks run on every /readyz and /status request with DefaultCheckTimeout;
+// a failing check makes the agent not ready. Registering an existing name
+// replaces its check.
+func (s *Server) RegisterCheck(name string, check CheckFunc) {
+	s.checksMu.Lock()
+	defer s.checksMu.Unlock()
+	s.checks[name] = check
+}
+
+// info returns a copy of the agent information, safe to use while
+// UpdateAgentInfo runs
+func (s *Server) info() AgentInfo {
+	s.infoMu.RLock()
+	defer s.infoMu.RUnlock()
+	return *s.agentInfo
+}
+
+// isPaused reports whether the status getter reports paused task processing
+func (s *Server) isPaused() bool {
+	reporter, ok := s.statusGetter.(PauseReporter)
+	return ok && reporter.IsPaused()
+}
+
+// queuedTasks returns the queue depth of a QueueReporter, 0 for other status getters
+func (s *Server) queuedTasks() int {
+	if reporter, ok := s.statusGetter.(QueueReporter); ok {
+		return reporter.GetQueuedTaskCount()
+	}
+	return 0
+}
+
+// runChecks runs all registered checks concurrently and reports whether all passed
+func (s *Server) runChecks(ctx context.Context) (map[string]CheckResult, bool) {
+	s.checksMu.RLock()
+	names := make([]string, 0, len(s.checks))
+	for name := range s.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	checks := make([]CheckFunc, len(names))
+	for i, name := range names {
+		checks[i] = s.checks[name]
+	}
+	s.checksMu.RUnlock()
+
+	if len(checks) == 0 {
+		return nil, true
+	}
+
+	errs := make([]error, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check CheckFunc) {
+			defer wg.Done()
+			errs[i] = s.runCheck(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	results := make(map[string]CheckResult, len(names))
+	passed := true
+	for i, name := range names {
+		if errs[i] != nil {
+			results[name] = CheckResult{Status: "fail", Error: errs[i].Error()}
+			passed = false
+		} else {
+			results[name] = CheckResult{Status: "pass"}
+		}
+	}
+	return results, passed
+}
+
+// runCheck runs check with the check timeout. A check that ignores its context
+// is abandoned once the timeout expires.
+func (s *Server) runCheck(ctx context.Context, check CheckFunc) error {
+	ctx, cancel := context.WithTimeout(ctx, s.checkTimeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("check panicked: %v", r)
+			}
+		}()
+		done <- check(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("check timed out after %s", s.checkTimeout)
 	}
 }
 
 // Start starts the health monitoring server
 func (s *Server) Start() error {
+	s.server = &http.Server{
+		Addr:    fmt.Sprintf(":%d", s.port),
+		Handler: s.handler(),
+	}
+
+	log.Printf("🌐 Starting health server on port %d...", s.port)
+	return s.server.ListenAndServe()
+}
+
+// handler routes the health endpoints
+func (s *Server) handler() http.Handler {
 	mux := http.NewServeMux()
 
 	// Health endpoints
 	mux.HandleFunc("/", s.rootHandler)
 	mux.HandleFunc("/health", s.healthHandler)
+	mux.HandleFunc("/livez", s.livenessHandler)
+	mux.HandleFunc("/readyz", s.readinessHandler)
 	mux.HandleFunc("/status", s.statusHandler)
 	mux.HandleFunc("/info", s.infoHandler)
 
-	s.server = &http.Server{
-		Addr:    fmt.Sprintf(":%d", s.port),
-		Handler: mux,
-	}
-
-	log.Printf("🌐 Starting health server on port %d...", s.port)
-	return s.server.ListenAndServe()
+	return mux
 }
 
 // Stop stops the health monitoring server
@@ -86,16 +263,21 @@ func (s *Server) rootHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/plain")
 	w.WriteHeader(http.StatusOK)
 
-	fmt.Fprintf(w, "Hello World from %s!\n", s.agentInfo.Name)
-	fmt.Fprintf(w, "Agent: %s v%s\n", s.agentInfo.Name, s.agentInfo.Version)
-	fmt.Fprintf(w, "Wallet: %s\n", s.agentInfo.Wallet)
+	info := s.info()
+	fmt.Fprintf(w, "Hello World from %s!\n", info.Name)
+	fmt.Fprintf(w, "Agent: %s v%s\n", info.Name, info.Version)
+	fmt.Fprintf(w, "Wallet: %s\n", info.Wallet)
 	fmt.Fprintf(w, "Connected: %v\n", s.statusGetter.IsConnected())
 	fmt.Fprintf(w, "Authenticated: %v\n", s.statusGetter.IsAuthenticated())
+	fmt.Fprintf(w, "Paused: %v\n", s.isPaused())
 	fmt.Fprintf(w, "Active Tasks: %d\n", s.statusGetter.GetActiveTaskCount())
-	fmt.Fprintf(w, "Capabilities: %s\n", strings.Join(s.agentInfo.Capabilities, ", "))
+	fmt.Fprintf(w, "Queued Tasks: %d\n", s.queuedTasks())
+	fmt.Fprintf(w, "Capabilities: %s\n", strings.Join(info.Capabilities, ", "))
 	fmt.Fprintf(w, "Uptime: %v\n", s.statusGetter.GetUptime())
 	fmt.Fprintf(w, "\nEndpoints:\n")
 	fmt.Fprintf(w, "  /health - Health check\n")
+	fmt.Fprintf(w, "  /livez  - Liveness probe\n")
+	fmt.Fprintf(w, "  /readyz - Readiness probe\n")
 	fmt.Fprintf(w, "  /status - Detailed status (JSON)\n")
 	fmt.Fprintf(w, "  /info   - Agent information (JSON)\n")
 }
@@ -126,12 +308,63 @@ func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
 	health := map[string]interface{}{
 		"status":    status,
 		"timestamp": time.Now(),
-		"agent":     s.agentInfo.Name,
+		"agent":     s.info().Name,
 	}
 
 	json.NewEncoder(w).Encode(health)
 }
 
+// livenessHandler reports that the process is alive. It succeeds for as long
+// as the server can answer, regardless of the network connection.
+func (s *Server) livenessHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    "alive",
+		"timestamp": time.Now(),
+	})
+}
+
+// readinessHandler reports whether the agent can take tasks: connected,
+// authenticated and not shutting down
+func (s *Server) readinessHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	connected := s.statusGetter.IsConnected()
+	authenticated := s.statusGetter.IsAuthenticated()
+	shuttingDown := false
+	if reporter, ok := s.statusGetter.(ShutdownReporter); ok {
+		shuttingDown = reporter.IsShuttingDown()
+	}
+
+	paused := s.isPaused()
+	checks, checksPassed := s.runChecks(r.Context())
+
+	status := "ready"
+	statusCode := http.StatusOK
+	if !connected || !authenticated || shuttingDown || paused || !checksPassed {
+		status = "not_ready"
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	w.WriteHeader(statusCode)
+
+	response := map[string]interface{}{
+		"status":        status,
+		"connected":     connected,
+		"authenticated": authenticated,
+		"shutting_down": shuttingDown,
+		"paused":        paused,
+		"timestamp":     time.Now(),
+	}
+	if checks != nil {
+		response["checks"] = checks
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
 // statusHandler provides detailed status information
 func (s *Server) statusHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -140,8 +373,12 @@ func (s *Server) statusHandler(w http.ResponseWriter, r *http.Request) {
 	connected := s.statusGetter.IsConnected()
 	authenticated := s.statusGetter.IsAuthenticated()
 
+	paused := s.isPaused()
+
 	var status string
-	if connected && authenticated {
+	if connected && authenticated && paused {
+		status = "paused"
+	} else if connected && authenticated {
 		status = "operational"
 	} else if connected {
 		status = "connected"
@@ -153,10 +390,20 @@ func (s *Server) statusHandler(w http.ResponseWriter, r *http.Request) {
 		Status:        status,
 		Connected:     connected,
 		Authenticated: authenticated,
+		Paused:        paused,
 		ActiveTasks:   s.statusGetter.GetActiveTaskCount(),
+		QueuedTasks:   s.queuedTasks(),
 		Uptime:        s.statusGetter.GetUptime().String(),
 		Timestamp:     time.Now(),
-		Agent:         *s.agentInfo,
+		Agent:         s.info(),
+	}
+	healthStatus.Checks, _ = s.runChecks(r.Context())
+	if reporter, ok := s.statusGetter.(MetricsReporter); ok {
+		metrics := reporter.GetMetrics()
+		healthStatus.Metrics = &metrics
+	}
+	if reporter, ok := s.statusGetter.(WalletReporter); ok {
+		healthStatus.Wallet = reporter.GetWalletInfo()
 	}
 
 	json.NewEncoder(w).Encode(healthStatus)
@@ -167,10 +414,13 @@ func (s *Server) infoHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 
-	json.NewEncoder(w).Encode(s.agentInfo)
+	info := s.info()
+	json.NewEncoder(w).Encode(&info)
 }
 
 // UpdateAgentInfo updates the agent information
 func (s *Server) UpdateAgentInfo(info *AgentInfo) {
+	s.infoMu.Lock()
+	defer s.infoMu.Unlock()
 	s.agentInfo = info
 }
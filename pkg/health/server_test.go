@@ -0,0 +1,271 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/types"
+)
+
+// fakeStatus is a StatusGetter with fixed connection state
+type fakeStatus struct {
+	connected     bool
+	authenticated bool
+}
+
+func (f *fakeStatus) IsConnected() bool        { return f.connected }
+func (f *fakeStatus) IsAuthenticated() bool    { return f.authenticated }
+func (f *fakeStatus) GetActiveTaskCount() int  { return 0 }
+func (f *fakeStatus) GetUptime() time.Duration { return time.Minute }
+
+// drainingStatus also reports a shutdown in progress
+type drainingStatus struct {
+	fakeStatus
+	shuttingDown bool
+}
+
+func (d *drainingStatus) IsShuttingDown() bool { return d.shuttingDown }
+
+// pausableStatus also reports paused task processing
+type pausableStatus struct {
+	fakeStatus
+	paused bool
+}
+
+func (p *pausableStatus) IsPaused() bool { return p.paused }
+
+// meteredStatus also reports task metrics
+type meteredStatus struct {
+	fakeStatus
+	metrics types.AgentMetrics
+}
+
+func (m *meteredStatus) GetMetrics() types.AgentMetrics { return m.metrics }
+
+// queueingStatus also reports its task queue depth
+type queueingStatus struct {
+	fakeStatus
+	queued int
+}
+
+func (q *queueingStatus) GetQueuedTaskCount() int { return q.queued }
+
+func get(t *testing.T, status StatusGetter, path string) (int, map[string]interface{}) {
+	t.Helper()
+	server := NewServer(0, &AgentInfo{Name: "test-agent"}, status)
+
+	rec := httptest.NewRecorder()
+	server.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var body map[string]interface{}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("GET %s: invalid JSON body: %v", path, err)
+	}
+	return rec.Code, body
+}
+
+func TestServer_Livez(t *testing.T) {
+	tests := []struct {
+		name   string
+		status StatusGetter
+	}{
+		{"connected", &fakeStatus{connected: true, authenticated: true}},
+		{"disconnected", &fakeStatus{}},
+		{"shutting down", &drainingStatus{shuttingDown: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body := get(t, tt.status, "/livez")
+			if code != http.StatusOK || body["status"] != "alive" {
+				t.Errorf("GET /livez = %d %v, want 200 alive", code, body["status"])
+			}
+		})
+	}
+}
+
+func TestServer_Readyz(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   StatusGetter
+		wantCode int
+	}{
+		{"connected and authenticated", &fakeStatus{connected: true, authenticated: true}, http.StatusOK},
+		{"disconnected", &fakeStatus{}, http.StatusServiceUnavailable},
+		{"connected, not authenticated", &fakeStatus{connected: true}, http.StatusServiceUnavailable},
+		{"not shutting down", &drainingStatus{fakeStatus: fakeStatus{connected: true, authenticated: true}}, http.StatusOK},
+		{"shutting down", &drainingStatus{fakeStatus: fakeStatus{connected: true, authenticated: true}, shuttingDown: true}, http.StatusServiceUnavailable},
+		{"paused", &pausableStatus{fakeStatus: fakeStatus{connected: true, authenticated: true}, paused: true}, http.StatusServiceUnavailable},
+		{"resumed", &pausableStatus{fakeStatus: fakeStatus{connected: true, authenticated: true}}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body := get(t, tt.status, "/readyz")
+			if code != tt.wantCode {
+				t.Errorf("GET /readyz = %d, want %d (body %v)", code, tt.wantCode, body)
+			}
+			wantStatus := "ready"
+			if tt.wantCode != http.StatusOK {
+				wantStatus = "not_ready"
+			}
+			if body["status"] != wantStatus {
+				t.Errorf("status = %v, want %s", body["status"], wantStatus)
+			}
+		})
+	}
+}
+
+func TestServer_StatusReportsPaused(t *testing.T) {
+	code, body := get(t, &pausableStatus{fakeStatus: fakeStatus{connected: true, authenticated: true}, paused: true}, "/status")
+	if code != http.StatusOK || body["status"] != "paused" || body["paused"] != true {
+		t.Errorf("GET /status = %d %v, want paused", code, body)
+	}
+}
+
+func TestServer_StatusReportsQueuedTasks(t *testing.T) {
+	if _, body := get(t, &queueingStatus{queued: 3}, "/status"); body["queued_tasks"] != 3.0 {
+		t.Errorf("GET /status queued_tasks = %v, want 3", body["queued_tasks"])
+	}
+
+	// Status getters without a queue report none
+	if _, body := get(t, &fakeStatus{}, "/status"); body["queued_tasks"] != 0.0 {
+		t.Errorf("GET /status queued_tasks = %v, want 0", body["queued_tasks"])
+	}
+}
+
+func TestServer_StatusReportsMetrics(t *testing.T) {
+	status := &meteredStatus{
+		fakeStatus: fakeStatus{connected: true, authenticated: true},
+		metrics:    types.AgentMetrics{TasksProcessed: 4, SuccessRate: 0.75, ErrorRate: 0.25},
+	}
+	_, body := get(t, status, "/status")
+	metrics, ok := body["metrics"].(map[string]interface{})
+	if !ok || metrics["tasks_processed"] != 4.0 || metrics["success_rate"] != 0.75 || metrics["error_rate"] != 0.25 {
+		t.Errorf("GET /status metrics = %v, want the reported metrics", body["metrics"])
+	}
+
+	// Status getters without metrics leave them out
+	if _, body := get(t, &fakeStatus{connected: true}, "/status"); body["metrics"] != nil {
+		t.Errorf("GET /status metrics = %v, want none", body["metrics"])
+	}
+}
+
+func TestServer_RegisterCheck(t *testing.T) {
+	ready := &fakeStatus{connected: true, authenticated: true}
+	pass := func(ctx context.Context) error { return nil }
+	fail := func(ctx context.Context) error { return errors.New("redis unreachable") }
+	hang := func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() }
+
+	tests := []struct {
+		name       string
+		checks     map[string]CheckFunc
+		wantCode   int
+		wantFailed []string
+	}{
+		{"all passing", map[string]CheckFunc{"openai": pass, "redis": pass}, http.StatusOK, nil},
+		{"one failing", map[string]CheckFunc{"openai": pass, "redis": fail}, http.StatusServiceUnavailable, []string{"redis"}},
+		{"slow check times out", map[string]CheckFunc{"openai": hang, "redis": pass}, http.StatusServiceUnavailable, []string{"openai"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewServer(0, &AgentInfo{Name: "test-agent"}, ready)
+			server.checkTimeout = 50 * time.Millisecond
+			for name, check := range tt.checks {
+				server.RegisterCheck(name, check)
+			}
+
+			rec := httptest.NewRecorder()
+			server.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if rec.Code != tt.wantCode {
+				t.Errorf("GET /readyz = %d, want %d", rec.Code, tt.wantCode)
+			}
+
+			var body struct {
+				Checks map[string]CheckResult `json:"checks"`
+			}
+			json.NewDecoder(rec.Body).Decode(&body)
+			if len(body.Checks) != len(tt.checks) {
+				t.Fatalf("checks = %v, want %d results", body.Checks, len(tt.checks))
+			}
+			var failed []string
+			for name, result := range body.Checks {
+				if result.Status == "fail" {
+					failed = append(failed, name)
+					if result.Error == "" {
+						t.Errorf("check %s failed without an error message", name)
+					}
+				}
+			}
+			if len(failed) != len(tt.wantFailed) || (len(failed) == 1 && failed[0] != tt.wantFailed[0]) {
+				t.Errorf("failed checks = %v, want %v", failed, tt.wantFailed)
+			}
+
+			// The status JSON reports the same checks
+			rec = httptest.NewRecorder()
+			server.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+			var status HealthStatus
+			json.NewDecoder(rec.Body).Decode(&status)
+			for name := range tt.checks {
+				if _, ok := status.Checks[name]; !ok {
+					t.Errorf("/status is missing check %s", name)
+				}
+			}
+		})
+	}
+}
+
+func TestServer_UpdateAgentInfoWhileServing(t *testing.T) {
+	server := NewServer(0, &AgentInfo{Name: "test-agent"}, &fakeStatus{connected: true})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			server.UpdateAgentInfo(&AgentInfo{Name: "test-agent", Capabilities: []string{"general"}})
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		for _, path := range []string{"/", "/info", "/status"} {
+			server.handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		}
+	}
+	<-done
+
+	rec := httptest.NewRecorder()
+	server.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/info", nil))
+	var info AgentInfo
+	json.NewDecoder(rec.Body).Decode(&info)
+	if len(info.Capabilities) != 1 || info.Capabilities[0] != "general" {
+		t.Errorf("/info capabilities = %v, want [general]", info.Capabilities)
+	}
+}
+
+// walletStatus also reports the last wallet check
+type walletStatus struct {
+	fakeStatus
+	wallet *WalletInfo
+}
+
+func (w *walletStatus) GetWalletInfo() *WalletInfo { return w.wallet }
+
+func TestServer_StatusReportsWallet(t *testing.T) {
+	_, body := get(t, &walletStatus{wallet: &WalletInfo{Address: "0xagent", Balance: "0.001 PEAQ", TokenID: 7}}, "/status")
+	wallet, ok := body["wallet"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("/status has no wallet: %v", body)
+	}
+	if wallet["owns_token"] != false || wallet["has_gas"] != false || wallet["balance"] != "0.001 PEAQ" {
+		t.Errorf("wallet = %v", wallet)
+	}
+
+	if _, body := get(t, &walletStatus{}, "/status"); body["wallet"] != nil {
+		t.Errorf("wallet = %v before the first check, want it omitted", body["wallet"])
+	}
+}
@@ -0,0 +1,98 @@
+package network
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Default reconnect backoff parameters
+const (
+	DefaultReconnectBaseDelay  = 1 * time.Second
+	DefaultReconnectMaxDelay   = 60 * time.Second
+	DefaultReconnectMultiplier = 2.0
+)
+
+// Backoff computes exponential reconnect delays with jitter.
+// It is safe for concurrent use.
+type Backoff struct {
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	multiplier float64
+
+	mu       sync.Mutex
+	attempts int
+	rand     func() float64 // returns [0.0, 1.0)
+}
+
+// NewBackoff creates a backoff. Zero or invalid values fall back to the defaults.
+func NewBackoff(baseDelay, maxDelay time.Duration, multiplier float64) *Backoff {
+	if baseDelay <= 0 {
+		baseDelay = DefaultReconnectBaseDelay
+	}
+	if maxDelay <= 0 {
+		maxDelay = DefaultReconnectMaxDelay
+	}
+	if maxDelay < baseDelay {
+		maxDelay = baseDelay
+	}
+	if multiplier < 1 {
+		multiplier = DefaultReconnectMultiplier
+	}
+
+	return &Backoff{
+		baseDelay:  baseDelay,
+		maxDelay:   maxDelay,
+		multiplier: multiplier,
+		rand:       rand.Float64,
+	}
+}
+
+// Next returns the delay before the next attempt and advances the attempt counter
+func (b *Backoff) Next() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delay := b.delayLocked(b.attempts)
+	b.attempts++
+	return delay
+}
+
+// Delay returns the jittered delay for the given zero-based attempt
+// without changing the attempt counter
+func (b *Backoff) Delay(attempt int) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.delayLocked(attempt)
+}
+
+// Reset starts the backoff over, typically after a successful connection
+func (b *Backoff) Reset() {
+	b.mu.Lock()
+	b.attempts = 0
+	b.mu.Unlock()
+}
+
+// Attempts returns how many delays have been handed out since the last reset
+func (b *Backoff) Attempts() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.attempts
+}
+
+// delayLocked applies "equal jitter": half of the capped exponential delay is
+// fixed and the other half is random, so retries spread out but never collapse to zero.
+func (b *Backoff) delayLocked(attempt int) time.Duration {
+	if attempt < 0 {
+		attempt = 0
+	}
+
+	delay := float64(b.baseDelay) * math.Pow(b.multiplier, float64(attempt))
+	if delay > float64(b.maxDelay) || math.IsInf(delay, 0) {
+		delay = float64(b.maxDelay)
+	}
+
+	half := delay / 2
+	return time.Duration(half + half*b.rand())
+}
@@ -0,0 +1,85 @@
+package network
+
+import (
+	"testing"
+	"time"
+)
+
+// newFixedBackoff returns a backoff whose jitter source always yields r
+func newFixedBackoff(base, max time.Duration, multiplier, r float64) *Backoff {
+	b := NewBackoff(base, max, multiplier)
+	b.rand = func() float64 { return r }
+	return b
+}
+
+func TestBackoff_GrowsExponentially(t *testing.T) {
+	// r = 1 (upper bound) gives the full unjittered delay
+	b := newFixedBackoff(time.Second, time.Minute, 2, 1)
+
+	want := []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second}
+	for i, w := range want {
+		if got := b.Next(); got != w {
+			t.Errorf("attempt %d: expected %v, got %v", i, w, got)
+		}
+	}
+}
+
+func TestBackoff_CapsAtMaxDelay(t *testing.T) {
+	b := newFixedBackoff(time.Second, 5*time.Second, 3, 1)
+
+	if got := b.Delay(10); got != 5*time.Second {
+		t.Errorf("expected delay capped at 5s, got %v", got)
+	}
+	// Very large attempts must not overflow
+	if got := b.Delay(10000); got != 5*time.Second {
+		t.Errorf("expected delay capped at 5s for huge attempt, got %v", got)
+	}
+}
+
+func TestBackoff_JitterStaysWithinBounds(t *testing.T) {
+	low := newFixedBackoff(4*time.Second, time.Minute, 2, 0)
+	if got := low.Delay(0); got != 2*time.Second {
+		t.Errorf("expected minimum jittered delay 2s, got %v", got)
+	}
+
+	b := NewBackoff(4*time.Second, time.Minute, 2)
+	for i := 0; i < 100; i++ {
+		got := b.Delay(1)
+		if got < 4*time.Second || got > 8*time.Second {
+			t.Fatalf("jittered delay %v outside [4s, 8s]", got)
+		}
+	}
+}
+
+func TestBackoff_ResetStartsOver(t *testing.T) {
+	b := newFixedBackoff(time.Second, time.Minute, 2, 1)
+
+	b.Next()
+	b.Next()
+	b.Next()
+	if b.Attempts() != 3 {
+		t.Fatalf("expected 3 attempts, got %d", b.Attempts())
+	}
+
+	b.Reset()
+	if b.Attempts() != 0 {
+		t.Errorf("expected attempts reset to 0, got %d", b.Attempts())
+	}
+	if got := b.Next(); got != time.Second {
+		t.Errorf("expected base delay after reset, got %v", got)
+	}
+}
+
+func TestNewBackoff_Defaults(t *testing.T) {
+	b := newFixedBackoff(0, 0, 0, 1)
+
+	if got := b.Delay(0); got != DefaultReconnectBaseDelay {
+		t.Errorf("expected default base delay %v, got %v", DefaultReconnectBaseDelay, got)
+	}
+	if got := b.Delay(100); got != DefaultReconnectMaxDelay {
+		t.Errorf("expected default max delay %v, got %v", DefaultReconnectMaxDelay, got)
+	}
+	if got := b.Delay(1); got != time.Duration(float64(DefaultReconnectBaseDelay)*DefaultReconnectMultiplier) {
+		t.Errorf("expected default multiplier applied, got %v", got)
+	}
+}
@@ -1,12 +1,16 @@
 package network
 
 import (
-	"fmt"
+	"errors"
 	"sync"
 	"sync/atomic"
 	"time"
 )
 
+// ErrCircuitOpen is returned by Call while the circuit breaker is rejecting
+// requests
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
 // CircuitState represents the state of the circuit breaker
 type CircuitState int32
 
@@ -69,7 +73,7 @@ func (cb *CircuitBreaker) SetStateChangeHandler(handler func(from, to CircuitSta
 // Call executes the given function if the circuit allows it
 func (cb *CircuitBreaker) Call(fn func() error) error {
 	if !cb.CanAttempt() {
-		return fmt.Errorf("circuit breaker is open")
+		return ErrCircuitOpen
 	}
 	
 	err := fn()
@@ -86,12 +90,19 @@ func (cb *CircuitBreaker) CanAttempt() bool {
 		return true
 		
 	case CircuitOpen:
-		cb.mu.RLock()
-		shouldReset := time.Since(cb.lastFailTime) > cb.resetTimeout
-		cb.mu.RUnlock()
+		cb.mu.Lock()
+		defer cb.mu.Unlock()
 		
-		if shouldReset {
-			cb.transitionTo(CircuitHalfOpen)
+		if time.Since(cb.lastFailTime) <= cb.resetTimeout {
+			return false
+		}
+		// Callers racing past the timeout share the half-open attempts, so
+		// only the first of them tests recovery
+		if CircuitState(atomic.LoadInt32(&cb.state)) == CircuitOpen {
+			cb.transitionToLocked(CircuitHalfOpen)
+		}
+		if cb.halfOpenAttempts < cb.halfOpenRequests {
+			cb.halfOpenAttempts++
 			return true
 		}
 		return false
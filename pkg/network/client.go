@@ -3,8 +3,11 @@ package network
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -23,12 +26,24 @@ type NetworkClient struct {
 	running         bool
 	reconnecting    int32 // atomic flag for reconnection state
 	mu              sync.RWMutex
+	writeMu         sync.Mutex // serializes data frames from writeMessages and SendRawData
 	ctx             context.Context
 	cancel          context.CancelFunc
 	sendChan        chan *types.Message
 	receiveChan     chan *types.Message
 	wg              sync.WaitGroup // For goroutine lifecycle management
 
+	enableCompression bool // request permessage-deflate when dialing
+	state             stateTracker
+
+	// Keepalive: a ping every pingInterval must be answered within
+	// pongTimeout; after maxMissedPongs misses in a row the connection is
+	// treated as dead
+	pingInterval   time.Duration
+	pongTimeout    time.Duration
+	maxMissedPongs int
+	lastPong       int64 // atomic UnixNano of the last pong received
+
 	// Resilience components
 	circuitBreaker *CircuitBreaker
 	retryQueue     *MessageRetryQueue
@@ -36,6 +51,10 @@ type NetworkClient struct {
 	supervisor     *GoroutineSupervisor
 }
 
+// errClientStopped is returned by reconnect when Disconnect was called while
+// the client was reconnecting
+var errClientStopped = errors.New("client was disconnected")
+
 // MessageHandler defines the function signature for message handlers
 type MessageHandler func(*types.Message) error
 
@@ -48,8 +67,39 @@ type Config struct {
 	MessageTimeout   time.Duration
 	PingInterval     time.Duration
 	HandshakeTimeout time.Duration
+
+	// Reconnect backoff: delays grow from ReconnectBaseDelay by
+	// ReconnectMultiplier up to ReconnectMaxDelay, with jitter
+	ReconnectBaseDelay  time.Duration
+	ReconnectMaxDelay   time.Duration
+	ReconnectMultiplier float64
+
+	// EnableCompression negotiates permessage-deflate compression so large
+	// messages use less bandwidth. Servers that do not support it are used
+	// uncompressed.
+	EnableCompression bool
+
+	// OnStateChange is called with the old and new state whenever the
+	// connection state changes, e.g. to drive alerting or a status display.
+	// Calls are made in order from a separate goroutine.
+	OnStateChange func(old, new ConnectionState)
+
+	// PongTimeout is how long the server has to answer each ping sent every
+	// PingInterval. It is capped at PingInterval so every ping is judged
+	// before the next one is sent. After MaxMissedPongs unanswered pings in a
+	// row the connection is closed as dead and reconnected. Zero values use
+	// DefaultPongTimeout and DefaultMaxMissedPongs.
+	PongTimeout    time.Duration
+	MaxMissedPongs int
 }
 
+// Default keepalive parameters
+const (
+	DefaultPingInterval   = 25 * time.Second
+	DefaultPongTimeout    = 10 * time.Second
+	DefaultMaxMissedPongs = 3
+)
+
 // DefaultNetworkConfig returns default network configuration
 func DefaultNetworkConfig() *Config {
 	return &Config{
@@ -58,8 +108,14 @@ func DefaultNetworkConfig() *Config {
 		ReconnectDelay:   5 * time.Second,
 		MaxReconnects:    10,
 		MessageTimeout:   30 * time.Second,
-		PingInterval:     30 * time.Second,
+		PingInterval:     DefaultPingInterval,
 		HandshakeTimeout: 10 * time.Second,
+		PongTimeout:      DefaultPongTimeout,
+		MaxMissedPongs:   DefaultMaxMissedPongs,
+
+		ReconnectBaseDelay:  DefaultReconnectBaseDelay,
+		ReconnectMaxDelay:   DefaultReconnectMaxDelay,
+		ReconnectMultiplier: DefaultReconnectMultiplier,
 	}
 }
 
@@ -76,13 +132,36 @@ func NewNetworkClient(config *Config) *NetworkClient {
 		cancel:          cancel,
 		sendChan:        make(chan *types.Message, 100),
 		receiveChan:     make(chan *types.Message, 100),
+
+		enableCompression: config.EnableCompression,
+		state:             stateTracker{onChange: config.OnStateChange},
+	}
+
+	client.pingInterval = config.PingInterval
+	if client.pingInterval <= 0 {
+		client.pingInterval = DefaultPingInterval
+	}
+	client.pongTimeout = config.PongTimeout
+	if client.pongTimeout <= 0 {
+		client.pongTimeout = DefaultPongTimeout
+	}
+	if client.pongTimeout > client.pingInterval {
+		client.pongTimeout = client.pingInterval
+	}
+	client.maxMissedPongs = config.MaxMissedPongs
+	if client.maxMissedPongs <= 0 {
+		client.maxMissedPongs = DefaultMaxMissedPongs
 	}
 
+	backoff := NewBackoff(config.ReconnectBaseDelay, config.ReconnectMaxDelay, config.ReconnectMultiplier)
 	client.reconnector = &ReconnectionManager{
 		enabled:     config.ReconnectEnabled,
 		maxAttempts: config.MaxReconnects,
 		delay:       config.ReconnectDelay,
-		backoffFunc: exponentialBackoff,
+		backoffFunc: func(attempt int) time.Duration {
+			// attempts is incremented before the first delay is computed
+			return backoff.Delay(attempt - 1)
+		},
 	}
 
 	// Initialize resilience components
@@ -113,31 +192,26 @@ func (c *NetworkClient) Connect() error {
 		return fmt.Errorf("client is already running")
 	}
 
-	dialer := websocket.DefaultDialer
-	dialer.HandshakeTimeout = 10 * time.Second
-
-	conn, _, err := dialer.Dial(c.url, nil)
+	c.state.set(StateConnecting)
+	conn, err := c.dial()
 	if err != nil {
+		c.state.set(StateDisconnected)
 		return fmt.Errorf("failed to connect to WebSocket: %w", err)
 	}
 
 	c.conn = conn
 	c.running = true
 	c.authenticated = false
+	c.state.set(StateConnected)
 
-	// Set up pong handler to respond to server pings
-	c.conn.SetPongHandler(func(appData string) error {
-		log.Printf("🏓 Pong received from server")
-		// Reset read deadline when we receive a pong
-		c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
-		return nil
-	})
+	// Set up pong handler to track answers to our pings
+	c.watchPongs(c.conn)
 
 	// Set initial read deadline
 	c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
 
 	// Register and start supervised goroutines
-	c.registerGoroutines()
+	c.registerGoroutines(c.ctx, c.conn)
 	if err := c.supervisor.Start(); err != nil {
 		return fmt.Errorf("failed to start supervisor: %w", err)
 	}
@@ -151,10 +225,52 @@ func (c *NetworkClient) Connect() error {
 	return nil
 }
 
+// dial opens a WebSocket connection to the server, offering permessage-deflate
+// when compression is enabled. The server decides whether to accept it;
+// either way the returned connection is ready to use.
+func (c *NetworkClient) dial() (*websocket.Conn, error) {
+	// Copy the default dialer so its settings are not shared between clients
+	dialer := *websocket.DefaultDialer
+	dialer.HandshakeTimeout = 10 * time.Second
+	dialer.EnableCompression = c.enableCompression
+
+	conn, resp, err := dialer.Dial(c.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.enableCompression {
+		if compressionNegotiated(resp) {
+			log.Printf("🗜️ WebSocket compression enabled")
+		} else {
+			log.Printf("⚠️ Server does not support WebSocket compression, continuing uncompressed")
+		}
+	}
+	return conn, nil
+}
+
+// compressionNegotiated reports whether the handshake response accepted
+// permessage-deflate
+func compressionNegotiated(resp *http.Response) bool {
+	if resp == nil {
+		return false
+	}
+	for _, ext := range resp.Header.Values("Sec-WebSocket-Extensions") {
+		if strings.Contains(ext, "permessage-deflate") {
+			return true
+		}
+	}
+	return false
+}
+
 // Disconnect closes the WebSocket connection with graceful shutdown
 func (c *NetworkClient) Disconnect() error {
 	c.mu.Lock()
 	if !c.running {
+		// Keep a reconnection in progress from bringing the connection back
+		if atomic.LoadInt32(&c.reconnecting) == 1 {
+			c.cancel()
+		}
 		c.mu.Unlock()
 		return nil
 	}
@@ -163,8 +279,15 @@ func (c *NetworkClient) Disconnect() error {
 	c.authenticated = false
 	oldConn := c.conn
 	c.conn = nil
+	c.state.set(StateDisconnected)
 	c.mu.Unlock()
 
+	// Stop the message loops, waking the reader blocked on the connection
+	c.cancel()
+	if oldConn != nil {
+		oldConn.SetReadDeadline(time.Now())
+	}
+
 	// Stop resilience components
 	c.supervisor.Stop()
 	c.retryQueue.Stop()
@@ -178,9 +301,7 @@ func (c *NetworkClient) Disconnect() error {
 		oldConn.Close()
 	}
 
-	// Cancel context and wait for goroutines
-	c.cancel()
-
+	// Wait for goroutines
 	done := make(chan struct{})
 	go func() {
 		c.wg.Wait()
@@ -219,13 +340,14 @@ func (c *NetworkClient) sendMessageDirect(msg *types.Message) error {
 		c.mu.RUnlock()
 		return fmt.Errorf("client is not running")
 	}
+	ctx := c.ctx
 	c.mu.RUnlock()
 
 	select {
 	case c.sendChan <- msg:
 		c.healthMonitor.RecordMessageSent()
 		return nil
-	case <-c.ctx.Done():
+	case <-ctx.Done():
 		return fmt.Errorf("client is shutting down")
 	case <-time.After(5 * time.Second):
 		return fmt.Errorf("send timeout")
@@ -242,6 +364,8 @@ func (c *NetworkClient) SendRawData(data []byte) error {
 	conn := c.conn
 	c.mu.RUnlock()
 
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
 	return conn.WriteMessage(1, data) // 1 = TextMessage
 }
 
@@ -271,10 +395,24 @@ func (c *NetworkClient) SetAuthenticated(authenticated bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.authenticated = authenticated
+
+	if !c.running {
+		return
+	}
+	if authenticated {
+		c.state.set(StateAuthenticated)
+	} else {
+		c.state.set(StateConnected)
+	}
+}
+
+// State returns the current connection state
+func (c *NetworkClient) State() ConnectionState {
+	return c.state.get()
 }
 
-// readMessages reads messages from WebSocket connection
-func (c *NetworkClient) readMessages() {
+// readMessages reads messages from conn until ctx is done
+func (c *NetworkClient) readMessages(ctx context.Context, conn *websocket.Conn) {
 	defer func() {
 		if r := recover(); r != nil {
 			log.Printf("❌ Panic in readMessages: %v", r)
@@ -283,22 +421,31 @@ func (c *NetworkClient) readMessages() {
 
 	for {
 		select {
-		case <-c.ctx.Done():
+		case <-ctx.Done():
 			return
 		default:
-			if c.conn == nil {
-				time.Sleep(100 * time.Millisecond)
-				continue
-			}
-
 			// Set read deadline before reading
-			c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+			conn.SetReadDeadline(time.Now().Add(60 * time.Second))
 
-			_, messageData, err := c.conn.ReadMessage()
+			_, messageData, err := conn.ReadMessage()
 			if err != nil {
 				log.Printf("❌ Read error: %v", err)
-				if c.reconnector.enabled && atomic.CompareAndSwapInt32(&c.reconnecting, 0, 1) {
-					go c.attemptReconnection()
+
+				// Disconnect closes the connection on purpose, and a
+				// reconnect closes the connection it replaces
+				c.mu.RLock()
+				running := c.running
+				c.mu.RUnlock()
+				if !running || ctx.Err() != nil {
+					return
+				}
+
+				if c.reconnector.enabled {
+					if atomic.CompareAndSwapInt32(&c.reconnecting, 0, 1) {
+						go c.attemptReconnection()
+					}
+				} else {
+					c.state.set(StateDisconnected)
 				}
 				return
 			}
@@ -314,15 +461,15 @@ func (c *NetworkClient) readMessages() {
 
 			select {
 			case c.receiveChan <- &msg:
-			case <-c.ctx.Done():
+			case <-ctx.Done():
 				return
 			}
 		}
 	}
 }
 
-// writeMessages writes messages to WebSocket connection
-func (c *NetworkClient) writeMessages() {
+// writeMessages writes queued messages to conn until ctx is done
+func (c *NetworkClient) writeMessages(ctx context.Context, conn *websocket.Conn) {
 	defer func() {
 		if r := recover(); r != nil {
 			log.Printf("❌ Panic in writeMessages: %v", r)
@@ -331,13 +478,9 @@ func (c *NetworkClient) writeMessages() {
 
 	for {
 		select {
-		case <-c.ctx.Done():
+		case <-ctx.Done():
 			return
 		case msg := <-c.sendChan:
-			if c.conn == nil {
-				continue
-			}
-
 			data, err := json.Marshal(msg)
 			if err != nil {
 				log.Printf("❌ Failed to marshal message: %v", err)
@@ -347,9 +490,12 @@ func (c *NetworkClient) writeMessages() {
 			// Add debug logging to see what we're actually sending over WebSocket
 			log.Printf("🐛 DEBUG: Sending WebSocket message: %s", string(data))
 
-			if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+			c.writeMu.Lock()
+			err = conn.WriteMessage(websocket.TextMessage, data)
+			c.writeMu.Unlock()
+			if err != nil {
 				log.Printf("❌ Write error: %v", err)
-				if c.reconnector.enabled && atomic.CompareAndSwapInt32(&c.reconnecting, 0, 1) {
+				if ctx.Err() == nil && c.reconnector.enabled && atomic.CompareAndSwapInt32(&c.reconnecting, 0, 1) {
 					go c.attemptReconnection()
 				}
 				return
@@ -358,8 +504,9 @@ func (c *NetworkClient) writeMessages() {
 	}
 }
 
-// processMessages processes incoming messages
-func (c *NetworkClient) processMessages() {
+// processMessages passes incoming messages to their handlers until ctx is
+// done
+func (c *NetworkClient) processMessages(ctx context.Context) {
 	defer func() {
 		if r := recover(); r != nil {
 			log.Printf("❌ Panic in processMessages: %v", r)
@@ -368,10 +515,14 @@ func (c *NetworkClient) processMessages() {
 
 	for {
 		select {
-		case <-c.ctx.Done():
+		case <-ctx.Done():
 			return
 		case msg := <-c.receiveChan:
-			if handler, exists := c.messageHandlers[msg.Type]; exists {
+			c.mu.RLock()
+			handler, exists := c.messageHandlers[msg.Type]
+			c.mu.RUnlock()
+
+			if exists {
 				if err := handler(msg); err != nil {
 					log.Printf("❌ Handler error for message type %s: %v", msg.Type, err)
 				}
@@ -390,8 +541,10 @@ func (c *NetworkClient) attemptReconnection() {
 	if !c.reconnector.ShouldReconnect() {
 		log.Printf("❌ Max reconnection attempts reached, giving up")
 		c.healthMonitor.RecordReconnectAttempt(false)
+		c.state.set(StateDisconnected)
 		return
 	}
+	c.state.set(StateReconnecting)
 
 	// Increment attempts (minimal lock time)
 	c.mu.Lock()
@@ -406,7 +559,10 @@ func (c *NetworkClient) attemptReconnection() {
 	time.Sleep(backoff)
 
 	// Attempt reconnection
-	if err := c.reconnect(); err != nil {
+	if err := c.reconnect(); errors.Is(err, errClientStopped) {
+		log.Printf("🔌 Client disconnected, reconnection stopped")
+		return
+	} else if err != nil {
 		log.Printf("❌ Reconnection failed: %v", err)
 		c.healthMonitor.RecordReconnectAttempt(false)
 
@@ -414,6 +570,9 @@ func (c *NetworkClient) attemptReconnection() {
 		if c.reconnector.ShouldReconnect() {
 			atomic.StoreInt32(&c.reconnecting, 0) // Reset flag before next attempt
 			go c.attemptReconnection()
+		} else {
+			log.Printf("❌ Max reconnection attempts reached, giving up")
+			c.state.set(StateDisconnected)
 		}
 	} else {
 		log.Printf("✅ Reconnected successfully")
@@ -423,102 +582,141 @@ func (c *NetworkClient) attemptReconnection() {
 	}
 }
 
-// reconnect performs the actual reconnection
+// reconnect replaces the connection and restarts the message loops on the
+// new one. Returns errClientStopped if Disconnect was called meanwhile.
 func (c *NetworkClient) reconnect() error {
-	// Close existing connection
-	if c.conn != nil {
-		c.conn.Close()
-		c.conn = nil
+	c.mu.Lock()
+	if c.ctx.Err() != nil {
+		c.mu.Unlock()
+		return errClientStopped
 	}
-
+	oldConn := c.conn
+	c.conn = nil
 	c.running = false
 	c.authenticated = false
 
 	// Cancel existing context and create new one for fresh goroutines
 	c.cancel()
-	c.ctx, c.cancel = context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+	c.ctx, c.cancel = ctx, cancel
+	c.mu.Unlock()
 
-	// Establish new connection
-	dialer := websocket.DefaultDialer
-	dialer.HandshakeTimeout = 10 * time.Second
+	// Close existing connection
+	if oldConn != nil {
+		oldConn.Close()
+	}
 
-	conn, _, err := dialer.Dial(c.url, nil)
+	// Establish new connection
+	conn, err := c.dial()
 	if err != nil {
 		return fmt.Errorf("failed to reconnect to WebSocket: %w", err)
 	}
 
+	c.mu.Lock()
+	if ctx.Err() != nil {
+		c.mu.Unlock()
+		conn.Close()
+		return errClientStopped
+	}
 	c.conn = conn
 	c.running = true
 	c.authenticated = false
+	c.state.set(StateConnected)
 
-	// Set up pong handler to respond to server pings
-	c.conn.SetPongHandler(func(appData string) error {
-		log.Printf("🏓 Pong received from server")
-		// Reset read deadline when we receive a pong
-		c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
-		return nil
-	})
+	// Set up pong handler to track answers to our pings
+	c.watchPongs(conn)
 
 	// Set initial read deadline
-	c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+	conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+	c.mu.Unlock()
 
 	// Restart message processing goroutines
-	go c.readMessages()
-	go c.writeMessages()
-	go c.processMessages()
-	go c.pingPongHandler()
+	go c.readMessages(ctx, conn)
+	go c.writeMessages(ctx, conn)
+	go c.processMessages(ctx)
+	go c.pingPongHandler(ctx, conn)
 
 	log.Printf("🔗 Reconnected to WebSocket server: %s", c.url)
 	return nil
 }
 
-// pingPongHandler handles WebSocket ping/pong to keep connection alive
-func (c *NetworkClient) pingPongHandler() {
+// pingPongHandler pings conn to keep it alive until ctx is done
+func (c *NetworkClient) pingPongHandler(ctx context.Context, conn *websocket.Conn) {
 	defer func() {
 		if r := recover(); r != nil {
 			log.Printf("❌ Panic in pingPongHandler: %v", r)
 		}
 	}()
 
-	pingInterval := 25 * time.Second
-	ticker := time.NewTicker(pingInterval)
+	ticker := time.NewTicker(c.pingInterval)
 	defer ticker.Stop()
 
+	var lastPing time.Time
+	missed := 0
+
 	for {
 		select {
-		case <-c.ctx.Done():
+		case <-ctx.Done():
 			return
 		case <-ticker.C:
 			c.mu.RLock()
-			conn := c.conn
 			running := c.running
 			c.mu.RUnlock()
 
-			if !running || conn == nil {
+			if !running {
 				continue
 			}
 
-			// Send ping message
+			// The previous ping's pong window has passed by now
+			if !lastPing.IsZero() {
+				if c.pongReceivedFor(lastPing) {
+					missed = 0
+				} else {
+					missed++
+					log.Printf("⚠️ No pong within %v (%d/%d missed)", c.pongTimeout, missed, c.maxMissedPongs)
+				}
+				if missed >= c.maxMissedPongs {
+					// Closing the connection makes readMessages fail and
+					// reconnect as for any other dropped connection
+					log.Printf("💀 Connection dead after %d missed pongs, closing it", missed)
+					conn.Close()
+					return
+				}
+			}
+
+			// Send ping message, noting the time first so a fast pong is
+			// never taken to be older than it
+			sent := time.Now()
 			if err := conn.WriteControl(websocket.PingMessage, []byte{}, time.Now().Add(10*time.Second)); err != nil {
 				log.Printf("⚠️ Ping failed: %v", err)
 				// Trigger reconnection if ping fails
-				if c.reconnector.enabled && atomic.CompareAndSwapInt32(&c.reconnecting, 0, 1) {
+				if ctx.Err() == nil && c.reconnector.enabled && atomic.CompareAndSwapInt32(&c.reconnecting, 0, 1) {
 					go c.attemptReconnection()
 				}
 				return
 			}
+			lastPing = sent
 			log.Printf("🏓 Ping sent successfully")
 		}
 	}
 }
 
-// exponentialBackoff calculates exponential backoff delay
-func exponentialBackoff(attempt int) time.Duration {
-	delay := time.Duration(attempt) * 5 * time.Second
-	if delay > 60*time.Second {
-		delay = 60 * time.Second
-	}
-	return delay
+// watchPongs records when conn receives a pong and extends its read deadline
+func (c *NetworkClient) watchPongs(conn *websocket.Conn) {
+	conn.SetPongHandler(func(appData string) error {
+		log.Printf("🏓 Pong received from server")
+		atomic.StoreInt64(&c.lastPong, time.Now().UnixNano())
+		// Reset read deadline when we receive a pong
+		conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+		return nil
+	})
+}
+
+// pongReceivedFor reports whether a pong arrived within the pong timeout of
+// the ping sent at pingTime
+func (c *NetworkClient) pongReceivedFor(pingTime time.Time) bool {
+	pong := time.Unix(0, atomic.LoadInt64(&c.lastPong))
+	return !pong.Before(pingTime) && pong.Sub(pingTime) <= c.pongTimeout
 }
 
 // getConn returns the connection safely
@@ -551,43 +749,44 @@ func (c *NetworkClient) healthCheck() error {
 	return nil
 }
 
-// registerGoroutines registers all goroutines with the supervisor
-func (c *NetworkClient) registerGoroutines() {
+// registerGoroutines registers the message loops for conn with the
+// supervisor, stopping them when ctx is done
+func (c *NetworkClient) registerGoroutines(ctx context.Context, conn *websocket.Conn) {
 	policy := DefaultRestartPolicy()
 
 	// Register read messages goroutine
 	c.supervisor.Register("read-messages", "Message Reader",
-		func(ctx context.Context) error {
+		func(context.Context) error {
 			c.wg.Add(1)
 			defer c.wg.Done()
-			c.readMessages()
+			c.readMessages(ctx, conn)
 			return nil
 		}, policy)
 
 	// Register write messages goroutine
 	c.supervisor.Register("write-messages", "Message Writer",
-		func(ctx context.Context) error {
+		func(context.Context) error {
 			c.wg.Add(1)
 			defer c.wg.Done()
-			c.writeMessages()
+			c.writeMessages(ctx, conn)
 			return nil
 		}, policy)
 
 	// Register process messages goroutine
 	c.supervisor.Register("process-messages", "Message Processor",
-		func(ctx context.Context) error {
+		func(context.Context) error {
 			c.wg.Add(1)
 			defer c.wg.Done()
-			c.processMessages()
+			c.processMessages(ctx)
 			return nil
 		}, policy)
 
 	// Register ping/pong handler
 	c.supervisor.Register("ping-pong", "Ping/Pong Handler",
-		func(ctx context.Context) error {
+		func(context.Context) error {
 			c.wg.Add(1)
 			defer c.wg.Done()
-			c.pingPongHandler()
+			c.pingPongHandler(ctx, conn)
 			return nil
 		}, policy)
 }
@@ -0,0 +1,329 @@
+package network
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/types"
+	"github.com/gorilla/websocket"
+)
+
+// echoServer echoes every message back and records whether the client
+// negotiated compression and sent pings
+type echoServer struct {
+	*httptest.Server
+	negotiated chan bool
+	pings      chan struct{}
+}
+
+func newEchoServer(t *testing.T, compression bool) *echoServer {
+	t.Helper()
+	srv := &echoServer{negotiated: make(chan bool, 1), pings: make(chan struct{}, 10)}
+	upgrader := websocket.Upgrader{EnableCompression: compression}
+
+	srv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		srv.negotiated <- compression && strings.Contains(r.Header.Get("Sec-WebSocket-Extensions"), "permessage-deflate")
+
+		conn.SetPingHandler(func(data string) error {
+			srv.pings <- struct{}{}
+			return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+		})
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := conn.WriteMessage(messageType, data); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestNetworkClient_CompressedRoundTrip(t *testing.T) {
+	tests := []struct {
+		name              string
+		clientCompression bool
+		serverCompression bool
+		wantNegotiated    bool
+	}{
+		{"compression negotiated", true, true, true},
+		{"server without compression", true, false, false},
+		{"compression disabled", false, true, false},
+	}
+
+	// A large, compressible analysis table
+	payload := strings.Repeat(`{"wallet":"0xabc","pnl":1234.5678},`, 40000)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newEchoServer(t, tt.serverCompression)
+
+			config := DefaultNetworkConfig()
+			config.WebSocketURL = "ws" + strings.TrimPrefix(srv.URL, "http")
+			config.ReconnectEnabled = false
+			config.EnableCompression = tt.clientCompression
+			client := NewNetworkClient(config)
+
+			received := make(chan *types.Message, 1)
+			client.RegisterHandler("echo", func(msg *types.Message) error {
+				received <- msg
+				return nil
+			})
+			if err := client.Connect(); err != nil {
+				t.Fatalf("Connect() error = %v", err)
+			}
+			defer client.Disconnect()
+
+			if got := <-srv.negotiated; got != tt.wantNegotiated {
+				t.Errorf("compression negotiated = %v, want %v", got, tt.wantNegotiated)
+			}
+
+			// Pings still reach the server and are answered
+			if err := client.getConn().WriteControl(websocket.PingMessage, []byte("hi"), time.Now().Add(time.Second)); err != nil {
+				t.Fatalf("ping error = %v", err)
+			}
+			select {
+			case <-srv.pings:
+			case <-time.After(2 * time.Second):
+				t.Fatal("server did not receive the ping")
+			}
+
+			data, _ := json.Marshal(map[string]string{"table": payload})
+			if err := client.SendMessage(&types.Message{Type: "echo", Content: payload, Data: data}); err != nil {
+				t.Fatalf("SendMessage() error = %v", err)
+			}
+
+			select {
+			case msg := <-received:
+				if msg.Content != payload {
+					t.Errorf("content round-tripped with %d bytes, want %d", len(msg.Content), len(payload))
+				}
+				var decoded map[string]string
+				if err := json.Unmarshal(msg.Data, &decoded); err != nil || decoded["table"] != payload {
+					t.Errorf("data did not round-trip: %v", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("message was not echoed back")
+			}
+		})
+	}
+}
+
+// newDroppableServer accepts WebSocket connections and hands each one to the
+// test, which can close it to simulate a dropped connection
+func newDroppableServer(t *testing.T) (*httptest.Server, chan *websocket.Conn) {
+	t.Helper()
+	conns := make(chan *websocket.Conn, 10)
+	upgrader := websocket.Upgrader{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		conns <- conn
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, conns
+}
+
+func TestNetworkClient_StateChanges(t *testing.T) {
+	srv, conns := newDroppableServer(t)
+
+	changes := make(chan string, 20)
+	config := DefaultNetworkConfig()
+	config.WebSocketURL = "ws" + strings.TrimPrefix(srv.URL, "http")
+	config.ReconnectBaseDelay = 10 * time.Millisecond
+	config.ReconnectMaxDelay = 10 * time.Millisecond
+	config.OnStateChange = func(old, new ConnectionState) {
+		changes <- old.String() + "→" + new.String()
+	}
+	client := NewNetworkClient(config)
+
+	expect := func(want ...string) {
+		t.Helper()
+		for _, w := range want {
+			select {
+			case got := <-changes:
+				if got != w {
+					t.Fatalf("state change = %s, want %s", got, w)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatalf("no state change, want %s", w)
+			}
+		}
+	}
+
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	client.SetAuthenticated(true)
+	expect("disconnected→connecting", "connecting→connected", "connected→authenticated")
+
+	// The server drops the connection and the client reconnects
+	(<-conns).Close()
+	expect("authenticated→reconnecting", "reconnecting→connected")
+	<-conns
+	client.SetAuthenticated(true)
+	expect("connected→authenticated")
+	if got := client.State(); got != StateAuthenticated {
+		t.Errorf("State() = %v, want %v", got, StateAuthenticated)
+	}
+
+	client.Disconnect()
+	expect("authenticated→disconnected")
+
+	select {
+	case got := <-changes:
+		t.Errorf("unexpected state change %s after Disconnect", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestNetworkClient_DisconnectStopsReconnection(t *testing.T) {
+	srv, conns := newDroppableServer(t)
+
+	reconnecting := make(chan struct{}, 1)
+	config := DefaultNetworkConfig()
+	config.WebSocketURL = "ws" + strings.TrimPrefix(srv.URL, "http")
+	config.ReconnectBaseDelay = 100 * time.Millisecond
+	config.ReconnectMaxDelay = 100 * time.Millisecond
+	config.OnStateChange = func(old, new ConnectionState) {
+		if new == StateReconnecting {
+			reconnecting <- struct{}{}
+		}
+	}
+	client := NewNetworkClient(config)
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+
+	// Disconnect while the client waits to reconnect
+	(<-conns).Close()
+	select {
+	case <-reconnecting:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the client to start reconnecting")
+	}
+	client.Disconnect()
+
+	select {
+	case <-conns:
+		t.Error("client reconnected after Disconnect")
+	case <-time.After(300 * time.Millisecond):
+	}
+	if client.IsConnected() {
+		t.Error("IsConnected() = true after Disconnect")
+	}
+}
+
+func TestNetworkClient_StateChangesOnFailedConnect(t *testing.T) {
+	changes := make(chan string, 10)
+	config := DefaultNetworkConfig()
+	config.WebSocketURL = "ws://127.0.0.1:1/ws"
+	config.OnStateChange = func(old, new ConnectionState) {
+		changes <- old.String() + "→" + new.String()
+	}
+	client := NewNetworkClient(config)
+
+	if err := client.Connect(); err == nil {
+		t.Fatal("Connect() to an unreachable server succeeded")
+	}
+	for _, want := range []string{"disconnected→connecting", "connecting→disconnected"} {
+		select {
+		case got := <-changes:
+			if got != want {
+				t.Errorf("state change = %s, want %s", got, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("no state change, want %s", want)
+		}
+	}
+}
+
+func TestNetworkClient_MissedPongsTriggerReconnect(t *testing.T) {
+	tests := []struct {
+		name          string
+		answerPings   bool
+		wantReconnect bool
+	}{
+		{"pongs answered", true, false},
+		{"pongs missed", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conns := make(chan *websocket.Conn, 10)
+			upgrader := websocket.Upgrader{}
+			var accepted int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				conn, err := upgrader.Upgrade(w, r, nil)
+				if err != nil {
+					return
+				}
+				if atomic.AddInt32(&accepted, 1) == 1 && !tt.answerPings {
+					// Simulate a half-open first connection that never
+					// answers; the reconnected one does
+					conn.SetPingHandler(func(string) error { return nil })
+				}
+				conns <- conn
+				for {
+					if _, _, err := conn.ReadMessage(); err != nil {
+						return
+					}
+				}
+			}))
+			defer srv.Close()
+
+			config := DefaultNetworkConfig()
+			config.WebSocketURL = "ws" + strings.TrimPrefix(srv.URL, "http")
+			config.PingInterval = 20 * time.Millisecond
+			config.PongTimeout = 10 * time.Millisecond
+			config.MaxMissedPongs = 2
+			config.ReconnectBaseDelay = 10 * time.Millisecond
+			config.ReconnectMaxDelay = 10 * time.Millisecond
+			client := NewNetworkClient(config)
+			if err := client.Connect(); err != nil {
+				t.Fatalf("Connect() error = %v", err)
+			}
+			defer client.Disconnect()
+			<-conns
+
+			select {
+			case <-conns:
+				if !tt.wantReconnect {
+					t.Fatal("client reconnected although every ping was answered")
+				}
+				waitFor(t, func() bool { return client.State() == StateConnected })
+			case <-time.After(300 * time.Millisecond):
+				if tt.wantReconnect {
+					t.Fatal("client did not reconnect after missing pongs")
+				}
+			}
+		})
+	}
+}
+
+func TestNewNetworkClient_DefaultPingInterval(t *testing.T) {
+	if client := NewNetworkClient(DefaultNetworkConfig()); client.pingInterval != 25*time.Second {
+		t.Errorf("default ping interval = %v, want 25s", client.pingInterval)
+	}
+}
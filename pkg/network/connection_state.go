@@ -0,0 +1,92 @@
+package network
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// ConnectionState represents the state of the WebSocket connection
+type ConnectionState int32
+
+const (
+	// StateDisconnected means there is no connection and none is being made
+	StateDisconnected ConnectionState = iota
+	// StateConnecting means Connect is dialing the server
+	StateConnecting
+	// StateConnected means the connection is open but not authenticated
+	StateConnected
+	// StateAuthenticated means the connection is open and authenticated
+	StateAuthenticated
+	// StateReconnecting means the connection dropped and the client is
+	// retrying with backoff
+	StateReconnecting
+)
+
+// String returns string representation of connection state
+func (s ConnectionState) String() string {
+	switch s {
+	case StateDisconnected:
+		return "disconnected"
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateAuthenticated:
+		return "authenticated"
+	case StateReconnecting:
+		return "reconnecting"
+	default:
+		return "unknown"
+	}
+}
+
+// stateTracker records the connection state and reports transitions to a
+// handler. Transitions are delivered in order from a single goroutine, so the
+// handler runs without any client lock held and may query the client.
+type stateTracker struct {
+	state    int32 // atomic ConnectionState
+	onChange func(old, new ConnectionState)
+
+	mu       sync.Mutex
+	pending  [][2]ConnectionState
+	draining bool
+}
+
+// get returns the current state
+func (s *stateTracker) get() ConnectionState {
+	return ConnectionState(atomic.LoadInt32(&s.state))
+}
+
+// set moves to state, queueing a notification if it changed
+func (s *stateTracker) set(state ConnectionState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old := ConnectionState(atomic.SwapInt32(&s.state, int32(state)))
+	if old == state || s.onChange == nil {
+		return
+	}
+
+	s.pending = append(s.pending, [2]ConnectionState{old, state})
+	if !s.draining {
+		s.draining = true
+		go s.drain()
+	}
+}
+
+// drain delivers queued transitions until none are left
+func (s *stateTracker) drain() {
+	for {
+		s.mu.Lock()
+		if len(s.pending) == 0 {
+			s.draining = false
+			s.mu.Unlock()
+			return
+		}
+		next := s.pending[0]
+		s.pending = s.pending[1:]
+		s.mu.Unlock()
+
+		s.onChange(next[0], next[1])
+	}
+}
@@ -3,10 +3,12 @@ package network
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/types"
@@ -18,10 +20,59 @@ type TaskCoordinator struct {
 	protocolHandler   *ProtocolHandler
 	activeTasksMu     sync.RWMutex
 	activeTasks       map[string]*TaskExecution
+	capabilitiesMu    sync.RWMutex
 	capabilities      []string
 	rateLimitPerMin   int
 	rateLimitMu       sync.Mutex
 	requestTimestamps []time.Time
+	userRateLimit     int                    // per sender, 0 = unlimited
+	userTimestamps    map[string][]time.Time // requests in the last minute by sender
+	updateWindow      time.Duration          // 0 = task updates are not coalesced
+	paused            int32                  // atomic flag, new tasks are rejected while set
+	onTaskFailure     func(task types.Task, err error)
+	nlpFallback       bool          // retry unrecognized commands through an NLPFallbackHandler
+	maxMessageBytes   int           // 0 = outgoing message content is not limited
+	walletTxTimeout   time.Duration // 0 = DefaultWalletTxTimeout
+	taskTimeout       time.Duration // 0 = defaultTaskTimeout
+	metrics           *taskMetrics
+
+	// IDs of tasks from the agent's TaskProvider that are queued or running
+	providedMu sync.Mutex
+	provided   map[string]bool
+
+	// Task queue: tasks wait for one of maxConcurrent run slots, with at most
+	// queueCapacity waiting at once
+	slots         chan struct{} // nil = unlimited concurrency
+	maxConcurrent int
+	queueMu       sync.Mutex
+	queueCond     *sync.Cond
+	inFlight      int // admitted and not yet finished
+	running       int // holding a run slot
+	held          int // submitted to a full queue under QueuePolicyBlock
+	queueCapacity int // 0 = unbounded
+	queuePolicy   QueuePolicy
+}
+
+// QueuePolicy controls what happens to new tasks when the task queue is full
+type QueuePolicy int
+
+const (
+	// QueuePolicyReject answers new tasks with an "agent busy" error
+	QueuePolicyReject QueuePolicy = iota
+	// QueuePolicyBlock holds new tasks until a queue slot frees
+	QueuePolicyBlock
+)
+
+// String returns the policy name
+func (p QueuePolicy) String() string {
+	switch p {
+	case QueuePolicyReject:
+		return "reject"
+	case QueuePolicyBlock:
+		return "block"
+	default:
+		return "unknown"
+	}
 }
 
 // TaskExecution represents an active task execution
@@ -37,36 +88,94 @@ type TaskMessageSender struct {
 	taskID          string
 	protocolHandler *ProtocolHandler
 	room            string
+	updates         *updateCoalescer // nil when updates are sent immediately
+	maxBytes        int              // 0 = message content is not limited
+	lastMessage     string           // content of the last message sent successfully, the task's result
+	walletTxTimeout time.Duration    // how long RequestWalletTx waits for the user
+	taskContext     *taskContext     // held while RequestWalletTx waits, nil outside a task
+	requester       string           // the task's sender, the only user who may answer its wallet transactions
+
+	// deliver receives the task's messages instead of the network when the
+	// task runs locally through ProcessTask
+	deliver func(msg *types.Message) error
+}
+
+// send delivers msg to the network, or to deliver for a local task
+func (s *TaskMessageSender) send(msg *types.Message) error {
+	if s.deliver != nil {
+		return s.deliver(msg)
+	}
+	return s.protocolHandler.client.SendMessage(msg)
 }
 
-// SendMessage sends a message with content (backward compatibility - STRING type)
+// sendResponse sends content as a task response
+func (s *TaskMessageSender) sendResponse(content, contentType string, success bool, errorMsg string) error {
+	if s.deliver == nil {
+		return s.protocolHandler.SendTaskResponseToRoom(s.taskID, content, contentType, success, errorMsg, s.room)
+	}
+	msg, err := s.protocolHandler.newTaskResponse(s.taskID, content, contentType, success, errorMsg, s.room)
+	if err != nil {
+		return err
+	}
+	return s.deliver(msg)
+}
+
+// SendMessage sends a message with content (backward compatibility - STRING type).
+// Content over the size limit is sent as several sequential messages.
 func (s *TaskMessageSender) SendMessage(content string) error {
 	return s.sendStandardizedMessage(types.StandardMessageTypeString, content)
 }
 
-// SendTaskUpdate sends a progress update for the current task
+// SendTaskUpdate sends a progress update for the current task.
+// When coalescing is enabled, rapid updates collapse into the latest one.
 func (s *TaskMessageSender) SendTaskUpdate(content string) error {
 	updateContent := fmt.Sprintf("🔄 Update: %s", content)
-	return s.sendStandardizedMessage(types.StandardMessageTypeString, updateContent)
+	if s.updates != nil {
+		return s.updates.Submit(updateContent)
+	}
+	return s.sendUpdate(updateContent)
+}
+
+// sendUpdate delivers a task update without coalescing, truncated to the
+// size limit since a split update would read as two
+func (s *TaskMessageSender) sendUpdate(content string) error {
+	return s.sendResponse(truncateMessage(content, s.maxBytes), types.StandardMessageTypeString, true, "")
 }
 
-// SendMessageAsJSON sends structured JSON data
+// flushUpdates sends any pending coalesced update so it is not reordered
+// after a final message
+func (s *TaskMessageSender) flushUpdates() {
+	if s.updates == nil {
+		return
+	}
+	if err := s.updates.Flush(); err != nil {
+		log.Printf("⚠️ Failed to flush task update: %v", err)
+	}
+}
+
+// SendMessageAsJSON sends structured JSON data. Content is marshaled unless
+// it is already a string; JSON over the size limit cannot be split and
+// fails with types.ErrMessageTooLarge.
 func (s *TaskMessageSender) SendMessageAsJSON(content interface{}) error {
 	return s.sendStandardizedMessage(types.StandardMessageTypeJSON, content)
 }
 
-// SendMessageAsMD sends markdown formatted text
+// SendMessageAsMD sends markdown formatted text. Content over the size limit
+// is sent as several sequential messages, split between lines where possible.
 func (s *TaskMessageSender) SendMessageAsMD(content string) error {
 	return s.sendStandardizedMessage(types.StandardMessageTypeMD, content)
 }
 
-// SendMessageAsArray sends array/list data
+// SendMessageAsArray sends array/list data, which like JSON fails with
+// types.ErrMessageTooLarge when over the size limit
 func (s *TaskMessageSender) SendMessageAsArray(content []interface{}) error {
 	return s.sendStandardizedMessage(types.StandardMessageTypeArray, content)
 }
 
 // SendErrorMessage sends an error message to the user without triggering a transaction
 func (s *TaskMessageSender) SendErrorMessage(content string, errorCode string, details map[string]interface{}) error {
+	s.flushUpdates()
+
 	errorData := types.AgentErrorData{
 		TaskID:    s.taskID,
 		ErrorCode: errorCode,
@@ -84,17 +193,23 @@ func (s *TaskMessageSender) SendErrorMessage(content string, errorCode string, d
 		Room:          s.room,
 		DataRoom:      s.room,
 		MessageRoomId: s.room,
-		Content:       content,
+		Content:       truncateMessage(content, s.maxBytes),
 		TaskID:        s.taskID,
 		Data:          dataBytes,
 		Timestamp:     time.Now(),
 	}
 
-	return s.protocolHandler.client.SendMessage(msg)
+	return s.send(msg)
 }
 
 // TriggerWalletTx requests the user to sign a wallet transaction
 func (s *TaskMessageSender) TriggerWalletTx(tx types.TxRequest, description string, optional bool) error {
+	return s.triggerWalletTx(tx, description, optional, "")
+}
+
+// triggerWalletTx sends a wallet transaction request, tagged with requestID
+// when the user's answer is awaited
+func (s *TaskMessageSender) triggerWalletTx(tx types.TxRequest, description string, optional bool, requestID string) error {
 	if tx.To == "" {
 		return fmt.Errorf("tx.To is required")
 	}
@@ -105,8 +220,11 @@ func (s *TaskMessageSender) TriggerWalletTx(tx types.TxRequest, description stri
 		return fmt.Errorf("description is required")
 	}
 
+	s.flushUpdates()
+
 	txData := types.TriggerWalletTxData{
 		TaskID:      s.taskID,
+		RequestID:   requestID,
 		Tx:          tx,
 		Description: description,
 		Optional:    optional,
@@ -129,12 +247,67 @@ func (s *TaskMessageSender) TriggerWalletTx(tx types.TxRequest, description stri
 		Timestamp:     time.Now(),
 	}
 
-	return s.protocolHandler.client.SendMessage(msg)
+	return s.send(msg)
+}
+
+// sendResult sends content with the result envelope as its data. Content
+// over the size limit is split, with the envelope on the last part.
+func (s *TaskMessageSender) sendResult(result *types.TaskResult, content string) error {
+	s.flushUpdates()
+
+	chunks := splitMessage(content, s.maxBytes)
+	for _, chunk := range chunks[:len(chunks)-1] {
+		if err := s.sendResponse(chunk, types.StandardMessageTypeString, result.Success, ""); err != nil {
+			return err
+		}
+	}
+	if s.deliver == nil {
+		if err := s.protocolHandler.SendTaskResultToRoom(result, chunks[len(chunks)-1], s.room); err != nil {
+			return err
+		}
+	} else {
+		msg, err := s.protocolHandler.newTaskResult(result, chunks[len(chunks)-1], s.room)
+		if err != nil {
+			return err
+		}
+		if err := s.deliver(msg); err != nil {
+			return err
+		}
+	}
+	s.lastMessage = content
+	return nil
 }
 
-// sendStandardizedMessage sends a message in standardized format
+// sendStandardizedMessage sends a message in standardized format. Text and
+// markdown over the size limit are split into sequential messages; other
+// types are rejected.
 func (s *TaskMessageSender) sendStandardizedMessage(msgType string, content interface{}) error {
-	return s.protocolHandler.SendTaskResponseToRoom(s.taskID, content.(string), msgType, true, "", s.room)
+	text, ok := content.(string)
+	if !ok {
+		data, err := json.Marshal(content)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s content: %w", msgType, err)
+		}
+		text = string(data)
+	}
+
+	chunks := []string{text}
+	if s.maxBytes > 0 && len(text) > s.maxBytes {
+		if msgType != types.StandardMessageTypeString && msgType != types.StandardMessageTypeMD {
+			return fmt.Errorf("%w: %s content is %d bytes, limit is %d", types.ErrMessageTooLarge, msgType, len(text), s.maxBytes)
+		}
+		chunks = splitMessage(text, s.maxBytes)
+		log.Printf("✂️ Splitting %d-byte %s message for task %s into %d parts", len(text), msgType, s.taskID, len(chunks))
+	}
+
+	s.flushUpdates()
+	for _, chunk := range chunks {
+		if err := s.sendResponse(chunk, msgType, true, ""); err != nil {
+			return err
+		}
+	}
+	s.lastMessage = text
+	return nil
 }
 
 // NewTaskCoordinator creates a new task coordinator
@@ -146,7 +319,11 @@ func NewTaskCoordinator(agentHandler types.AgentHandler, protocolHandler *Protoc
 		capabilities:      capabilities,
 		rateLimitPerMin:   0, // Will be set by SetRateLimit
 		requestTimestamps: make([]time.Time, 0),
+		userTimestamps:    make(map[string][]time.Time),
+		metrics:           newTaskMetrics(),
+		provided:          make(map[string]bool),
 	}
+	coordinator.queueCond = sync.NewCond(&coordinator.queueMu)
 
 	// Register task handler
 	protocolHandler.client.RegisterHandler("task", coordinator.HandleIncomingTask)
@@ -164,36 +341,292 @@ func (t *TaskCoordinator) SetRateLimit(tasksPerMinute int) {
 	log.Printf("⚙️ Rate limit set to: %d tasks/minute", tasksPerMinute)
 }
 
-// checkRateLimit checks if the rate limit allows processing a new task
-// Returns true if task can be processed, false if rate limit exceeded
-func (t *TaskCoordinator) checkRateLimit() bool {
+// SetUserRateLimit sets the rate limit for each sender (tasks per minute),
+// applied in addition to the global rate limit. Set to 0 for unlimited
+func (t *TaskCoordinator) SetUserRateLimit(tasksPerMinute int) {
 	t.rateLimitMu.Lock()
 	defer t.rateLimitMu.Unlock()
+	t.userRateLimit = tasksPerMinute
+	log.Printf("⚙️ Per-user rate limit set to: %d tasks/minute", tasksPerMinute)
+}
+
+// SetUpdateCoalesceWindow sets the window within which streaming task updates
+// are collapsed into the latest one. Set to 0 to send every update.
+func (t *TaskCoordinator) SetUpdateCoalesceWindow(window time.Duration) {
+	t.updateWindow = window
+	log.Printf("⚙️ Task update coalesce window set to: %v", window)
+}
+
+// SetMaxMessageBytes limits the content of each outgoing task message.
+// Longer text and markdown are split into sequential messages, updates and
+// error messages are truncated, and JSON and arrays fail with
+// types.ErrMessageTooLarge. Set to 0 for no limit.
+func (t *TaskCoordinator) SetMaxMessageBytes(limit int) {
+	t.maxMessageBytes = limit
+	log.Printf("⚙️ Max message size set to: %d bytes", limit)
+}
+
+// SetWalletTxTimeout sets how long RequestWalletTx waits for the user to
+// answer a wallet transaction request. Set to 0 for DefaultWalletTxTimeout.
+func (t *TaskCoordinator) SetWalletTxTimeout(timeout time.Duration) {
+	t.walletTxTimeout = timeout
+	log.Printf("⚙️ Wallet transaction timeout set to: %v", timeout)
+}
+
+// SetTaskFailureHandler sets a dead-letter hook called with the task and its
+// error whenever a task fails or times out. The hook runs in its own
+// goroutine so it never blocks task processing. Set to nil to disable.
+func (t *TaskCoordinator) SetTaskFailureHandler(handler func(task types.Task, err error)) {
+	t.onTaskFailure = handler
+}
+
+// SetNLPFallback controls whether tasks the agent rejects with
+// types.ErrUnrecognizedCommand are converted by its NLPFallbackHandler and
+// retried. It has no effect on agents that do not implement the interface.
+func (t *TaskCoordinator) SetNLPFallback(enabled bool) {
+	t.nlpFallback = enabled
+	log.Printf("⚙️ NLP fallback enabled: %v", enabled)
+}
+
+// SetMaxConcurrentTasks limits how many tasks execute at once. Tasks beyond
+// the limit wait in the task queue. Set to 0 for unlimited.
+func (t *TaskCoordinator) SetMaxConcurrentTasks(maxConcurrent int) {
+	t.queueMu.Lock()
+	if maxConcurrent > 0 {
+		t.slots = make(chan struct{}, maxConcurrent)
+		t.maxConcurrent = maxConcurrent
+	} else {
+		t.slots = nil
+		t.maxConcurrent = 0
+	}
+	t.queueMu.Unlock()
+	log.Printf("⚙️ Max concurrent tasks set to: %d", maxConcurrent)
+}
+
+// SetTaskQueue bounds the number of tasks waiting for a free slot and sets
+// what happens when the queue is full. Set capacity to 0 for an unbounded queue.
+func (t *TaskCoordinator) SetTaskQueue(capacity int, policy QueuePolicy) {
+	t.queueMu.Lock()
+	t.queueCapacity = capacity
+	t.queuePolicy = policy
+	t.queueMu.Unlock()
+	t.queueCond.Broadcast()
+	log.Printf("⚙️ Task queue capacity set to: %d (policy: %s)", capacity, policy)
+}
+
+// enqueueTask admits a task, run by run, to the queue and starts it once a
+// slot is free. Returns false if the queue is full and the policy is to
+// reject. Under QueuePolicyBlock a task arriving at a full queue is held in
+// its own goroutine until a queue slot frees, so the caller (usually the
+// message loop) never blocks.
+func (t *TaskCoordinator) enqueueTask(run func()) bool {
+	t.queueMu.Lock()
+	defer t.queueMu.Unlock()
+	// The queue only fills up once every run slot is taken. Held tasks go
+	// first so new tasks cannot overtake them.
+	if t.held == 0 && !t.queueFullLocked() {
+		t.admitLocked(run)
+		return true
+	}
+	if t.queuePolicy != QueuePolicyBlock {
+		return false
+	}
+
+	t.held++
+	go t.admitWhenQueueFrees(run)
+	return true
+}
+
+// admitWhenQueueFrees waits for a queue slot for a held task and admits it
+func (t *TaskCoordinator) admitWhenQueueFrees(run func()) {
+	t.queueMu.Lock()
+	defer t.queueMu.Unlock()
+	for t.queueFullLocked() {
+		t.queueCond.Wait()
+	}
+	t.held--
+	t.admitLocked(run)
+}
+
+// admitLocked counts a task as in flight and starts it once a run slot is
+// free. Caller must hold queueMu.
+func (t *TaskCoordinator) admitLocked(run func()) {
+	t.inFlight++
+	go t.runQueuedTask(t.slots, run)
+}
+
+// queueFullLocked reports whether a new task would exceed the queue capacity.
+// Caller must hold queueMu.
+func (t *TaskCoordinator) queueFullLocked() bool {
+	if t.queueCapacity == 0 || t.maxConcurrent == 0 {
+		return false
+	}
+	return t.inFlight >= t.maxConcurrent+t.queueCapacity
+}
+
+// runQueuedTask waits for a run slot and executes the task
+func (t *TaskCoordinator) runQueuedTask(slots chan struct{}, run func()) {
+	if slots != nil {
+		slots <- struct{}{}
+	}
+
+	t.queueMu.Lock()
+	t.running++
+	t.queueMu.Unlock()
+
+	defer func() {
+		if slots != nil {
+			<-slots
+		}
+		t.queueMu.Lock()
+		t.running--
+		t.inFlight--
+		t.queueMu.Unlock()
+		t.queueCond.Signal()
+	}()
 
-	// No rate limit (0 = unlimited)
-	if t.rateLimitPerMin == 0 {
+	run()
+}
+
+// submitTask queues a task, answering the user with an "agent busy" error if
+// the queue is full. Reports whether the task was queued.
+func (t *TaskCoordinator) submitTask(task types.Task, room string) bool {
+	if t.enqueueTask(func() { t.executeTask(task, room) }) {
 		return true
 	}
 
+	log.Printf("⚠️ Task queue full, rejecting task %s", task.ID)
+	t.protocolHandler.SendTaskResponseToRoom(
+		task.ID,
+		"⚠️ Agent busy. This agent is handling its maximum number of tasks. Please try again in a moment.",
+		types.StandardMessageTypeString,
+		false,
+		"agent_busy",
+		room,
+	)
+	return false
+}
+
+// Pause stops accepting new tasks; they are answered with an "agent paused"
+// error while tasks already admitted run to completion
+func (t *TaskCoordinator) Pause() {
+	if atomic.CompareAndSwapInt32(&t.paused, 0, 1) {
+		log.Printf("⏸️ Task processing paused")
+	}
+}
+
+// Resume accepts new tasks again after Pause
+func (t *TaskCoordinator) Resume() {
+	if atomic.CompareAndSwapInt32(&t.paused, 1, 0) {
+		log.Printf("▶️ Task processing resumed")
+	}
+}
+
+// IsPaused reports whether new tasks are being rejected
+func (t *TaskCoordinator) IsPaused() bool {
+	return atomic.LoadInt32(&t.paused) == 1
+}
+
+// rejectIfPaused answers a new task with an "agent paused" error if task
+// processing is paused and reports whether it did
+func (t *TaskCoordinator) rejectIfPaused(taskID, room string) bool {
+	if !t.IsPaused() {
+		return false
+	}
+
+	log.Printf("⏸️ Task processing paused, rejecting task %s", taskID)
+	t.protocolHandler.SendTaskResponseToRoom(
+		taskID,
+		"⏸️ Agent temporarily unavailable. This agent is paused for maintenance. Please try again later.",
+		types.StandardMessageTypeString,
+		false,
+		"agent_paused",
+		room,
+	)
+	return true
+}
+
+// Rate limit rejection codes sent in task responses
+const (
+	rateLimitExceeded     = "rate_limit_exceeded"
+	userRateLimitExceeded = "user_rate_limit_exceeded"
+)
+
+// checkRateLimit checks if the per-user and global rate limits allow
+// processing a new task from sender. An empty sender is only subject to the
+// global limit. Returns "" if the task can be processed, otherwise the code
+// of the exceeded limit. A rejected task counts against neither limit.
+func (t *TaskCoordinator) checkRateLimit(sender string) string {
+	t.rateLimitMu.Lock()
+	defer t.rateLimitMu.Unlock()
+
 	now := time.Now()
 	oneMinuteAgo := now.Add(-1 * time.Minute)
 
-	// Remove timestamps older than 1 minute
-	validTimestamps := make([]time.Time, 0)
-	for _, ts := range t.requestTimestamps {
-		if ts.After(oneMinuteAgo) {
-			validTimestamps = append(validTimestamps, ts)
+	// Drop per-user timestamps older than 1 minute, and idle senders with them
+	for user, timestamps := range t.userTimestamps {
+		timestamps = recentTimestamps(timestamps, oneMinuteAgo)
+		if len(timestamps) == 0 {
+			delete(t.userTimestamps, user)
+		} else {
+			t.userTimestamps[user] = timestamps
 		}
 	}
-	t.requestTimestamps = validTimestamps
+	t.requestTimestamps = recentTimestamps(t.requestTimestamps, oneMinuteAgo)
 
-	// Check if we've exceeded the limit
-	if len(t.requestTimestamps) >= t.rateLimitPerMin {
-		return false
+	// 0 = unlimited
+	limitUser := t.userRateLimit > 0 && sender != ""
+	if limitUser && len(t.userTimestamps[sender]) >= t.userRateLimit {
+		return userRateLimitExceeded
+	}
+	if t.rateLimitPerMin > 0 && len(t.requestTimestamps) >= t.rateLimitPerMin {
+		return rateLimitExceeded
 	}
 
 	// Add current timestamp
-	t.requestTimestamps = append(t.requestTimestamps, now)
+	if t.rateLimitPerMin > 0 {
+		t.requestTimestamps = append(t.requestTimestamps, now)
+	}
+	if limitUser {
+		t.userTimestamps[sender] = append(t.userTimestamps[sender], now)
+	}
+	return ""
+}
+
+// recentTimestamps returns the timestamps after since
+func recentTimestamps(timestamps []time.Time, since time.Time) []time.Time {
+	valid := make([]time.Time, 0, len(timestamps))
+	for _, ts := range timestamps {
+		if ts.After(since) {
+			valid = append(valid, ts)
+		}
+	}
+	return valid
+}
+
+// rejectIfRateLimited answers a new task from sender with a rate limit error
+// if the per-user or global rate limit is exceeded and reports whether it did
+func (t *TaskCoordinator) rejectIfRateLimited(taskID, sender, room string) bool {
+	code := t.checkRateLimit(sender)
+	if code == "" {
+		return false
+	}
+
+	content := "⚠️ Agent rate limit exceeded. This agent has reached its maximum request capacity. Please try again in a moment."
+	if code == userRateLimitExceeded {
+		log.Printf("⚠️ Per-user rate limit exceeded for %s, rejecting task %s", sender, taskID)
+		content = "⚠️ Rate limit exceeded. You have sent too many requests to this agent. Please try again in a moment."
+	} else {
+		log.Printf("⚠️ Rate limit exceeded, rejecting task %s", taskID)
+	}
+	t.protocolHandler.SendTaskResponseToRoom(
+		taskID,
+		content,
+		types.StandardMessageTypeString,
+		false,
+		code,
+		room,
+	)
 	return true
 }
 
@@ -219,22 +652,23 @@ func (t *TaskCoordinator) HandleIncomingTask(msg *types.Message) error {
 		taskID = fmt.Sprintf("task-%d", time.Now().Unix())
 	}
 
-	// Check rate limit
-	if !t.checkRateLimit() {
-		log.Printf("⚠️ Rate limit exceeded, rejecting task %s", taskID)
-		t.protocolHandler.SendTaskResponseToRoom(
-			taskID,
-			"⚠️ Agent rate limit exceeded. This agent has reached its maximum request capacity. Please try again in a moment.",
-			types.StandardMessageTypeString,
-			false,
-			"rate_limit_exceeded",
-			msg.Room,
-		)
+	if t.rejectIfPaused(taskID, msg.Room) {
+		return nil
+	}
+
+	sender := t.protocolHandler.taskSender(msg)
+	if t.rejectIfRateLimited(taskID, sender, msg.Room) {
 		return nil
 	}
 
-	// Execute task in goroutine
-	go t.ExecuteTask(taskID, msg.Content, msg.Room)
+	// Queue task for execution
+	t.submitTask(types.Task{
+		ID:        taskID,
+		Type:      types.MessageTypeTask,
+		Content:   msg.Content,
+		Sender:    sender,
+		CreatedAt: time.Now(),
+	}, msg.Room)
 
 	return nil
 }
@@ -251,29 +685,121 @@ func (t *TaskCoordinator) HandleUserMessage(msg *types.Message) error {
 	// Treat user messages as tasks
 	taskID := fmt.Sprintf("user-msg-%d", time.Now().Unix())
 
-	// Check rate limit
-	if !t.checkRateLimit() {
-		log.Printf("⚠️ Rate limit exceeded, rejecting message from %s", msg.From)
-		t.protocolHandler.SendTaskResponseToRoom(
-			taskID,
-			"⚠️ Agent rate limit exceeded. This agent has reached its maximum request capacity. Please try again in a moment.",
-			types.StandardMessageTypeString,
-			false,
-			"rate_limit_exceeded",
-			msg.Room,
-		)
+	if t.rejectIfPaused(taskID, msg.Room) {
 		return nil
 	}
 
-	go t.ExecuteTask(taskID, msg.Content, msg.Room)
+	sender := t.protocolHandler.taskSender(msg)
+	if t.rejectIfRateLimited(taskID, sender, msg.Room) {
+		return nil
+	}
+
+	t.submitTask(types.Task{
+		ID:        taskID,
+		Type:      types.MessageTypeMessage,
+		Content:   msg.Content,
+		Sender:    sender,
+		CreatedAt: time.Now(),
+	}, msg.Room)
 
 	return nil
 }
 
 // ExecuteTask executes a task using the agent handler
 func (t *TaskCoordinator) ExecuteTask(taskID, content, room string) {
+	t.executeTask(types.Task{ID: taskID, Content: content, CreatedAt: time.Now()}, room)
+}
+
+// Errors returned by ProcessTask for tasks that were not run
+var (
+	ErrTasksPaused = errors.New("task processing is paused")
+	ErrRateLimited = errors.New("rate limit exceeded")
+	ErrQueueFull   = errors.New("task queue is full")
+)
+
+// TaskOutput is the outcome of a task run with ProcessTask
+type TaskOutput struct {
+	TaskID   string           `json:"task_id"`
+	Success  bool             `json:"success"`
+	Result   string           `json:"result,omitempty"`
+	Error    string           `json:"error,omitempty"`
+	Messages []*types.Message `json:"messages"` // every message the task sent, in order
+}
+
+// ProcessTask runs task through the same pipeline as tasks from the network,
+// including the task queue, NLP fallback, result and failure handlers and
+// metrics, but returns the messages it produces instead of sending them. It
+// waits for a run slot like any other task. Paused agents fail with
+// ErrTasksPaused, exceeded rate limits with ErrRateLimited and a full queue
+// under QueuePolicyReject with ErrQueueFull; a task that ran but failed is
+// reported in the output, not as an error.
+func (t *TaskCoordinator) ProcessTask(ctx context.Context, task types.Task) (*TaskOutput, error) {
+	if t.IsPaused() {
+		return nil, ErrTasksPaused
+	}
+	if code := t.checkRateLimit(task.Sender); code != "" {
+		return nil, fmt.Errorf("%w: %s", ErrRateLimited, code)
+	}
+	if task.ID == "" {
+		task.ID = fmt.Sprintf("local-%d", time.Now().UnixNano())
+	}
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = time.Now()
+	}
+
+	output := &TaskOutput{TaskID: task.ID, Messages: []*types.Message{}}
+	var mu sync.Mutex
+	var result string
+	var err error
+	done := make(chan struct{})
+	queued := t.enqueueTask(func() {
+		defer close(done)
+		if ctx.Err() != nil {
+			return // The caller stopped waiting for a slot
+		}
+		result, err = t.runTask(ctx, task, "", func(msg *types.Message) error {
+			mu.Lock()
+			defer mu.Unlock()
+			output.Messages = append(output.Messages, msg)
+			return nil
+		})
+	})
+	if !queued {
+		return nil, ErrQueueFull
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	output.Success = err == nil
+	output.Result = result
+	if err != nil {
+		output.Error = err.Error()
+	}
+	return output, nil
+}
+
+// executeTask runs task with the agent handler and sends its messages to room
+func (t *TaskCoordinator) executeTask(task types.Task, room string) {
+	t.runTask(context.Background(), task, room, nil)
+}
+
+// runTask runs task with the agent handler, reports failures to the task
+// failure handler and returns the task's result. The task's messages go to
+// deliver, or to room on the network when deliver is nil.
+func (t *TaskCoordinator) runTask(parent context.Context, task types.Task, room string, deliver func(msg *types.Message) error) (string, error) {
+	taskID, content := task.ID, task.Content
+
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	timeout := t.taskTimeout
+	if timeout <= 0 {
+		timeout = defaultTaskTimeout
+	}
+	ctx, cancel := newTaskContext(parent, timeout)
 	defer cancel()
 
 	// Track active task
@@ -288,67 +814,256 @@ func (t *TaskCoordinator) ExecuteTask(taskID, content, room string) {
 	t.activeTasks[taskID] = execution
 	t.activeTasksMu.Unlock()
 
-	// Clean up when done
+	// Clean up and record the outcome when done
+	var taskErr error
 	defer func() {
 		t.activeTasksMu.Lock()
 		delete(t.activeTasks, taskID)
 		t.activeTasksMu.Unlock()
+		t.metrics.record(time.Since(execution.StartTime), taskErr)
+
+		t.providedMu.Lock()
+		delete(t.provided, taskID)
+		t.providedMu.Unlock()
 	}()
 
 	log.Printf("🔄 Executing task %s: %s", taskID, content)
 
+	messageSender := t.newMessageSender(taskID, room)
+	messageSender.deliver = deliver
+	messageSender.taskContext = ctx
+	messageSender.requester = strings.ToLower(task.Sender)
+
 	// Check if agent supports streaming task handling
 	if streamingHandler, ok := t.agentHandler.(types.StreamingTaskHandler); ok {
 		log.Printf("📡 Using streaming task handler for task %s", taskID)
 
-		// Create message sender for this task
-		messageSender := &TaskMessageSender{
-			taskID:          taskID,
-			protocolHandler: t.protocolHandler,
-			room:            room,
+		if t.updateWindow > 0 {
+			messageSender.updates = newUpdateCoalescer(t.updateWindow, messageSender.sendUpdate)
 		}
 
 		// Process the task with streaming capability
-		if err := streamingHandler.ProcessTaskWithStreaming(ctx, content, room, messageSender); err != nil {
+		err := t.withNLPFallback(ctx, content, func(content string) error {
+			return streamingHandler.ProcessTaskWithStreaming(ctx, content, room, messageSender)
+		})
+		messageSender.flushUpdates()
+		if err != nil {
 			log.Printf("❌ Streaming task %s failed: %v", taskID, err)
-			t.protocolHandler.SendTaskResponseToRoom(taskID, fmt.Sprintf("❌ Error: %v", err), types.StandardMessageTypeString, false, err.Error(), room)
-			return
+			taskErr = err
+			t.reportTaskFailure(ctx, task, err)
+			messageSender.sendTaskError(ctx, err)
+			return "", err
 		}
 
 		log.Printf("✅ Streaming task %s completed successfully", taskID)
 
-		// Send final completion message if needed
-		// Note: The agent should send its own completion message using the MessageSender
+		// The agent sends its own messages through the MessageSender, so the
+		// result, the last message sent, can only be handled afterwards
+		t.handleTaskResult(ctx, taskID, messageSender.lastMessage)
+		return messageSender.lastMessage, nil
+
+	} else if richHandler, ok := t.agentHandler.(types.RichTaskHandler); ok {
+		log.Printf("📄 Using rich task handler for task %s", taskID)
+		var result string
+		result, taskErr = t.runRichTask(ctx, task, messageSender, richHandler, execution.StartTime)
+		return result, taskErr
 
 	} else {
 		log.Printf("📄 Using standard task handler for task %s", taskID)
 
 		// Process the task using standard method
-		result, err := t.agentHandler.ProcessTask(ctx, content)
+		var result string
+		err := t.withNLPFallback(ctx, content, func(content string) error {
+			var err error
+			result, err = t.agentHandler.ProcessTask(ctx, content)
+			return err
+		})
 		if err != nil {
 			log.Printf("❌ Task %s failed: %v", taskID, err)
-			t.protocolHandler.SendTaskResponseToRoom(taskID, fmt.Sprintf("❌ Error: %v", err), types.StandardMessageTypeString, false, err.Error(), room)
-			return
+			taskErr = err
+			t.reportTaskFailure(ctx, task, err)
+			messageSender.sendTaskError(ctx, err)
+			return "", err
 		}
 
 		log.Printf("✅ Task %s completed successfully", taskID)
+		t.handleTaskResult(ctx, taskID, result)
 
-		// Send response
-		if err := t.protocolHandler.SendTaskResponseToRoom(taskID, result, types.StandardMessageTypeString, true, "", room); err != nil {
+		// Send response, split if it exceeds the message size limit
+		if err := messageSender.SendMessage(result); err != nil {
 			log.Printf("❌ Failed to send task response: %v", err)
 		}
+		return result, nil
+	}
+}
+
+// runRichTask runs a task with a RichTaskHandler and sends its result
+// envelope through sender, filled in with the task ID, duration, creation
+// time, success flag and the agent name. A handler error is sent as an
+// agent_error message with its error code instead, as for other handlers.
+// Returns the task's result and its error, if it failed.
+func (t *TaskCoordinator) runRichTask(ctx context.Context, task types.Task, sender *TaskMessageSender, handler types.RichTaskHandler, start time.Time) (string, error) {
+	var result *types.TaskResult
+	err := t.withNLPFallback(ctx, task.Content, func(content string) error {
+		var err error
+		result, err = handler.ProcessRichTask(ctx, content)
+		return err
+	})
+	if err != nil {
+		log.Printf("❌ Task %s failed: %v", task.ID, err)
+		t.reportTaskFailure(ctx, task, err)
+		sender.sendTaskError(ctx, err)
+		return "", err
+	}
+	if result == nil {
+		result = &types.TaskResult{}
+	}
+	if result.Error != "" {
+		err = errors.New(result.Error)
 	}
 
-	// Handle task result if handler supports it (works for both streaming and standard)
-	if resultHandler, ok := t.agentHandler.(types.TaskResultHandler); ok {
-		// For streaming tasks, we don't have a single result, so we pass the task content
-		result := content
-		if err := resultHandler.HandleTaskResult(ctx, taskID, result); err != nil {
-			log.Printf("⚠️ Failed to handle task result: %v", err)
+	result.TaskID = task.ID
+	result.Success = err == nil
+	result.Duration = time.Since(start)
+	if result.CreatedAt.IsZero() {
+		result.CreatedAt = time.Now()
+	}
+	if result.Metadata == nil {
+		result.Metadata = make(map[string]string)
+	}
+	if _, ok := result.Metadata["agent"]; !ok {
+		result.Metadata["agent"] = t.protocolHandler.agentName
+	}
+
+	content := result.Result
+	if err != nil {
+		log.Printf("❌ Task %s failed: %v", task.ID, err)
+		t.reportTaskFailure(ctx, task, err)
+		if content == "" {
+			content = fmt.Sprintf("❌ Error: %v", err)
 		}
+	} else {
+		log.Printf("✅ Task %s completed successfully", task.ID)
+		t.handleTaskResult(ctx, task.ID, result.Result)
+	}
+
+	if sendErr := sender.sendResult(result, content); sendErr != nil {
+		log.Printf("❌ Failed to send task response: %v", sendErr)
+	}
+	return result.Result, err
+}
+
+// handleTaskResult passes the result of a successful task to the agent if it
+// implements types.TaskResultHandler. Errors are logged; the result is sent
+// regardless.
+func (t *TaskCoordinator) handleTaskResult(ctx context.Context, taskID, result string) {
+	resultHandler, ok := t.agentHandler.(types.TaskResultHandler)
+	if !ok {
+		return
+	}
+	if err := resultHandler.HandleTaskResult(ctx, taskID, result); err != nil {
+		log.Printf("⚠️ Failed to handle result of task %s: %v", taskID, err)
+	}
+}
+
+// newMessageSender creates the sender for a task's responses
+func (t *TaskCoordinator) newMessageSender(taskID, room string) *TaskMessageSender {
+	return &TaskMessageSender{
+		taskID:          taskID,
+		protocolHandler: t.protocolHandler,
+		room:            room,
+		maxBytes:        t.maxMessageBytes,
+		walletTxTimeout: t.walletTxTimeout,
 	}
 }
 
+// withNLPFallback runs a task and, when NLP fallback is enabled and the
+// agent rejects the text as an unrecognized command, runs it once more with
+// the command its NLPFallbackHandler derives from the text. If the fallback
+// cannot produce a command, the original error is returned so the user still
+// sees the agent's usage message.
+func (t *TaskCoordinator) withNLPFallback(ctx context.Context, content string, run func(content string) error) error {
+	err := run(content)
+	if err == nil || !t.nlpFallback || !errors.Is(err, types.ErrUnrecognizedCommand) {
+		return err
+	}
+	fallback, ok := t.agentHandler.(types.NLPFallbackHandler)
+	if !ok {
+		return err
+	}
+
+	command, nlpErr := fallback.ParseNaturalLanguage(ctx, content)
+	if nlpErr != nil || strings.TrimSpace(command) == "" {
+		log.Printf("⚠️ NLP fallback could not interpret task: %v", nlpErr)
+		return err
+	}
+	log.Printf("🗣️ NLP fallback rewrote task as: %s", command)
+	return run(command)
+}
+
+// classifyTaskError maps a failed task's error to the structured error sent
+// to the user. A *types.AgentError returned by the handler is used as is;
+// other errors are classified as validation, rate limit, timeout or internal
+// errors by the sentinels they wrap.
+func classifyTaskError(ctx context.Context, err error) *types.AgentError {
+	var agentErr *types.AgentError
+	if errors.As(err, &agentErr) {
+		classified := *agentErr
+		if classified.Code == "" {
+			classified.Code = types.ErrorCodeInternal
+		}
+		return &classified
+	}
+
+	code := types.ErrorCodeInternal
+	switch {
+	case errors.Is(err, types.ErrInvalidTask), errors.Is(err, types.ErrUnrecognizedCommand):
+		code = types.ErrorCodeValidation
+	case errors.Is(err, types.ErrRateLimited), errors.Is(err, ErrRateLimited):
+		code = types.ErrorCodeRateLimited
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, types.ErrTaskTimeout),
+		errors.Is(ctx.Err(), context.DeadlineExceeded):
+		code = types.ErrorCodeTimeout
+	}
+	return &types.AgentError{
+		Code:    code,
+		Details: map[string]interface{}{"error": err.Error()},
+		Err:     err,
+	}
+}
+
+// sendTaskError tells the user a task failed with an agent_error message
+// carrying the error's code and details
+func (s *TaskMessageSender) sendTaskError(ctx context.Context, err error) {
+	agentErr := classifyTaskError(ctx, err)
+	content := fmt.Sprintf("❌ Error: %v", agentErr)
+	if sendErr := s.SendErrorMessage(content, agentErr.Code, agentErr.Details); sendErr != nil {
+		log.Printf("❌ Failed to send task error: %v", sendErr)
+	}
+}
+
+// reportTaskFailure passes a failed task to the task failure handler, if any,
+// without blocking. Errors of tasks that ran out of time wrap
+// context.DeadlineExceeded.
+func (t *TaskCoordinator) reportTaskFailure(ctx context.Context, task types.Task, err error) {
+	handler := t.onTaskFailure
+	if handler == nil {
+		return
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) && !errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("%w: %v", context.DeadlineExceeded, err)
+	}
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("❌ Task failure handler panicked for task %s: %v", task.ID, r)
+			}
+		}()
+		handler(task, err)
+	}()
+}
+
 // extractTaskID extracts task ID from message data
 func (t *TaskCoordinator) extractTaskID(msg *types.Message) string {
 	if msg.Data == nil {
@@ -414,6 +1129,22 @@ func (t *TaskCoordinator) GetActiveTaskCount() int {
 	return len(t.activeTasks)
 }
 
+// GetQueuedTaskCount returns the number of tasks waiting for a free slot
+func (t *TaskCoordinator) GetQueuedTaskCount() int {
+	t.queueMu.Lock()
+	defer t.queueMu.Unlock()
+	return t.inFlight - t.running + t.held
+}
+
+// GetMetrics returns the metrics of the tasks run so far: counts, success
+// and error rates, tasks finished in the last hour and the average response
+// time of the last 100 tasks. UptimePercentage is not tracked here.
+func (t *TaskCoordinator) GetMetrics() types.AgentMetrics {
+	metrics := t.metrics.snapshot()
+	metrics.AgentID = t.protocolHandler.agentName
+	return metrics
+}
+
 // CancelTask cancels a specific task
 func (t *TaskCoordinator) CancelTask(taskID string) bool {
 	t.activeTasksMu.Lock()
@@ -445,6 +1176,8 @@ func (t *TaskCoordinator) CancelAllTasks() {
 
 // CanHandleCapability checks if the agent can handle a specific capability
 func (t *TaskCoordinator) CanHandleCapability(capability string) bool {
+	t.capabilitiesMu.RLock()
+	defer t.capabilitiesMu.RUnlock()
 	for _, cap := range t.capabilities {
 		if cap == capability {
 			return true
@@ -455,6 +1188,8 @@ func (t *TaskCoordinator) CanHandleCapability(capability string) bool {
 
 // UpdateCapabilities updates the agent's capabilities
 func (t *TaskCoordinator) UpdateCapabilities(capabilities []string) {
-	t.capabilities = capabilities
+	t.capabilitiesMu.Lock()
+	t.capabilities = append([]string(nil), capabilities...)
+	t.capabilitiesMu.Unlock()
 	t.protocolHandler.UpdateCapabilities(capabilities)
 }
@@ -0,0 +1,844 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/types"
+)
+
+// blockingHandler holds every task until release is closed
+type blockingHandler struct {
+	started chan string
+	release chan struct{}
+}
+
+func newBlockingHandler() *blockingHandler {
+	return &blockingHandler{
+		started: make(chan string, 100),
+		release: make(chan struct{}),
+	}
+}
+
+func (h *blockingHandler) ProcessTask(ctx context.Context, task string) (string, error) {
+	h.started <- task
+	select {
+	case <-h.release:
+	case <-ctx.Done():
+	}
+	return "done", nil
+}
+
+// failingHandler fails every task with err
+type failingHandler struct {
+	err error
+}
+
+func (h failingHandler) ProcessTask(ctx context.Context, task string) (string, error) {
+	return "", h.err
+}
+
+// newTestCoordinator returns a coordinator whose outgoing messages are
+// captured from the client's send channel instead of a WebSocket
+func newTestCoordinator(handler types.AgentHandler) (*TaskCoordinator, chan *types.Message) {
+	client := NewNetworkClient(DefaultNetworkConfig())
+	client.running = true
+	protocol := NewProtocolHandler(client, nil, "test-agent", nil, "0xagent", "", "room")
+	return NewTaskCoordinator(handler, protocol, nil), client.sendChan
+}
+
+func taskMessage(content string) *types.Message {
+	return &types.Message{Type: "task", From: "coordinator", Content: content, Room: "room"}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before timeout")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestTaskCoordinator_RejectsWhenQueueFull(t *testing.T) {
+	handler := newBlockingHandler()
+	defer close(handler.release)
+
+	coordinator, sent := newTestCoordinator(handler)
+	coordinator.SetMaxConcurrentTasks(1)
+	coordinator.SetTaskQueue(2, QueuePolicyReject)
+
+	// One running, two queued
+	for i := 0; i < 3; i++ {
+		coordinator.HandleIncomingTask(taskMessage(fmt.Sprintf("task %d", i)))
+	}
+	<-handler.started
+	waitFor(t, func() bool { return coordinator.GetQueuedTaskCount() == 2 })
+
+	if got := coordinator.GetActiveTaskCount(); got != 1 {
+		t.Errorf("expected 1 active task, got %d", got)
+	}
+
+	// Queue is full, so this one is rejected
+	coordinator.HandleIncomingTask(taskMessage("overflow"))
+
+	select {
+	case msg := <-sent:
+		var data map[string]interface{}
+		json.Unmarshal(msg.Data, &data)
+		if data["success"] != false || data["error"] != "agent_busy" {
+			t.Errorf("expected agent_busy rejection, got %v", data)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a rejection response")
+	}
+
+	if got := coordinator.GetQueuedTaskCount(); got != 2 {
+		t.Errorf("expected rejected task not to be queued, got %d queued", got)
+	}
+}
+
+func TestTaskCoordinator_BlocksWhenQueueFull(t *testing.T) {
+	handler := newBlockingHandler()
+
+	coordinator, sent := newTestCoordinator(handler)
+	coordinator.SetMaxConcurrentTasks(1)
+	coordinator.SetTaskQueue(1, QueuePolicyBlock)
+
+	coordinator.HandleIncomingTask(taskMessage("running"))
+	<-handler.started
+	coordinator.HandleIncomingTask(taskMessage("queued"))
+	waitFor(t, func() bool { return coordinator.GetQueuedTaskCount() == 1 })
+
+	// The task is held rather than rejected, without blocking the caller
+	// (the message loop)
+	submitted := make(chan struct{})
+	go func() {
+		coordinator.HandleIncomingTask(taskMessage("blocked"))
+		close(submitted)
+	}()
+
+	select {
+	case <-submitted:
+	case <-time.After(time.Second):
+		t.Fatal("expected submission to return while the queue is full")
+	}
+	if got := coordinator.GetQueuedTaskCount(); got != 2 {
+		t.Errorf("expected the held task to count as queued, got %d queued", got)
+	}
+	select {
+	case msg := <-sent:
+		t.Fatalf("expected no response while the task is held, got %q", msg.Content)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Finishing tasks frees queue slots and admits the held task
+	close(handler.release)
+
+	waitFor(t, func() bool {
+		return coordinator.GetActiveTaskCount() == 0 && coordinator.GetQueuedTaskCount() == 0
+	})
+
+	// All three tasks complete successfully; none are rejected
+	for i := 0; i < 3; i++ {
+		select {
+		case msg := <-sent:
+			var data map[string]interface{}
+			json.Unmarshal(msg.Data, &data)
+			if data["success"] != true {
+				t.Errorf("expected successful response, got %v", data)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected 3 responses, got %d", i)
+		}
+	}
+}
+
+func TestTaskCoordinator_UnboundedQueueByDefault(t *testing.T) {
+	handler := newBlockingHandler()
+	defer close(handler.release)
+
+	coordinator, sent := newTestCoordinator(handler)
+	coordinator.SetMaxConcurrentTasks(1)
+
+	for i := 0; i < 10; i++ {
+		coordinator.HandleIncomingTask(taskMessage(fmt.Sprintf("task %d", i)))
+	}
+	waitFor(t, func() bool { return coordinator.GetQueuedTaskCount() == 9 })
+
+	select {
+	case msg := <-sent:
+		t.Errorf("expected no rejection with an unbounded queue, got %q", msg.Content)
+	default:
+	}
+}
+
+// nextResponse decodes the next response sent by the coordinator
+func nextResponse(t *testing.T, sent chan *types.Message) map[string]interface{} {
+	t.Helper()
+	select {
+	case msg := <-sent:
+		var data map[string]interface{}
+		json.Unmarshal(msg.Data, &data)
+		return data
+	case <-time.After(time.Second):
+		t.Fatal("expected a task response")
+		return nil
+	}
+}
+
+func TestTaskCoordinator_PauseAndResume(t *testing.T) {
+	handler := newBlockingHandler()
+
+	coordinator, sent := newTestCoordinator(handler)
+
+	// A task admitted before pausing keeps running
+	coordinator.HandleIncomingTask(taskMessage("before pause"))
+	<-handler.started
+
+	coordinator.Pause()
+	if !coordinator.IsPaused() {
+		t.Fatal("expected coordinator to be paused")
+	}
+
+	coordinator.HandleIncomingTask(taskMessage("while paused"))
+	coordinator.HandleUserMessage(&types.Message{Type: "message", From: "0xuser", Content: "hello", Room: "room"})
+	for i := 0; i < 2; i++ {
+		if data := nextResponse(t, sent); data["success"] != false || data["error"] != "agent_paused" {
+			t.Errorf("expected agent_paused rejection, got %v", data)
+		}
+	}
+	if got := coordinator.GetActiveTaskCount(); got != 1 {
+		t.Errorf("expected the running task to continue, got %d active", got)
+	}
+
+	close(handler.release)
+	if data := nextResponse(t, sent); data["success"] != true {
+		t.Errorf("expected the running task to finish successfully, got %v", data)
+	}
+
+	coordinator.Resume()
+	if coordinator.IsPaused() {
+		t.Fatal("expected coordinator to be resumed")
+	}
+	coordinator.HandleIncomingTask(taskMessage("after resume"))
+	if got := <-handler.started; got != "after resume" {
+		t.Errorf("expected the new task to run, got %q", got)
+	}
+	if data := nextResponse(t, sent); data["success"] != true {
+		t.Errorf("expected task to be accepted after resume, got %v", data)
+	}
+}
+
+func TestTaskCoordinator_UserRateLimit(t *testing.T) {
+	handler := newBlockingHandler()
+	close(handler.release)
+
+	coordinator, sent := newTestCoordinator(handler)
+	coordinator.SetRateLimit(10)
+	coordinator.SetUserRateLimit(2)
+
+	userTask := func(user, content string) *types.Message {
+		data, _ := json.Marshal(map[string]string{"from": user})
+		msg := taskMessage(content)
+		msg.Data = data
+		return msg
+	}
+
+	// Alice exceeds the per-user bucket
+	for i := 0; i < 3; i++ {
+		coordinator.HandleIncomingTask(userTask("0xAlice", fmt.Sprintf("alice %d", i)))
+	}
+	var rejected []interface{}
+	for i := 0; i < 3; i++ {
+		if data := nextResponse(t, sent); data["success"] != true {
+			rejected = append(rejected, data["error"])
+		}
+	}
+	if len(rejected) != 1 || rejected[0] != "user_rate_limit_exceeded" {
+		t.Errorf("expected alice's third task to be rejected with user_rate_limit_exceeded, got %v", rejected)
+	}
+
+	// Bob gets a separate bucket; direct messages are limited by sender too
+	coordinator.HandleIncomingTask(userTask("0xbob", "bob 0"))
+	coordinator.HandleUserMessage(&types.Message{Type: "message", From: "0xBob", Content: "bob 1", Room: "room"})
+	for i := 0; i < 2; i++ {
+		if data := nextResponse(t, sent); data["success"] != true {
+			t.Errorf("expected bob's task to be accepted, got %v", data)
+		}
+	}
+	coordinator.HandleUserMessage(&types.Message{Type: "message", From: "0xbob", Content: "bob 2", Room: "room"})
+	if data := nextResponse(t, sent); data["error"] != "user_rate_limit_exceeded" {
+		t.Errorf("expected bob's third task to be rejected, got %v", data)
+	}
+
+	// Rejected tasks do not use the global budget: 4 of 10 used
+	for i := 0; i < 6; i++ {
+		coordinator.HandleIncomingTask(taskMessage(fmt.Sprintf("anonymous %d", i)))
+	}
+	for i := 0; i < 6; i++ {
+		if data := nextResponse(t, sent); data["success"] != true {
+			t.Errorf("expected task within the global limit to be accepted, got %v", data)
+		}
+	}
+	coordinator.HandleIncomingTask(userTask("0xcarol", "carol 0"))
+	if data := nextResponse(t, sent); data["error"] != "rate_limit_exceeded" {
+		t.Errorf("expected the global limit to apply, got %v", data)
+	}
+}
+
+func TestTaskCoordinator_TaskFailureHandler(t *testing.T) {
+	handlerErr := errors.New("upstream API unavailable")
+	coordinator, sent := newTestCoordinator(failingHandler{err: handlerErr})
+
+	type failure struct {
+		task types.Task
+		err  error
+	}
+	failures := make(chan failure, 2)
+	unblock := make(chan struct{})
+	defer close(unblock)
+	coordinator.SetTaskFailureHandler(func(task types.Task, err error) {
+		failures <- failure{task, err}
+		<-unblock // a slow hook must not hold up task processing
+	})
+
+	data, _ := json.Marshal(map[string]string{"task_id": "task-1", "from": "0xAlice"})
+	msg := taskMessage("summarize this")
+	msg.Data = data
+	coordinator.HandleIncomingTask(msg)
+	coordinator.HandleUserMessage(&types.Message{Type: "message", From: "0xbob", Content: "hello", Room: "room"})
+
+	for i := 0; i < 2; i++ {
+		data := nextResponse(t, sent)
+		if details, _ := data["details"].(map[string]interface{}); data["error_code"] != types.ErrorCodeInternal || details["error"] != handlerErr.Error() {
+			t.Errorf("expected the user to get the task error, got %v", data)
+		}
+	}
+
+	got := map[string]failure{}
+	for i := 0; i < 2; i++ {
+		select {
+		case f := <-failures:
+			got[f.task.Sender] = f
+		case <-time.After(time.Second):
+			t.Fatal("expected the failure handler to be called for each failed task")
+		}
+	}
+
+	alice := got["0xalice"]
+	if alice.task.ID != "task-1" || alice.task.Content != "summarize this" || alice.task.Type != types.MessageTypeTask {
+		t.Errorf("unexpected failed task %+v", alice.task)
+	}
+	if !errors.Is(alice.err, handlerErr) {
+		t.Errorf("failure handler err = %v, want %v", alice.err, handlerErr)
+	}
+	if bob := got["0xbob"]; bob.task.Content != "hello" || !errors.Is(bob.err, handlerErr) {
+		t.Errorf("unexpected failure for direct message: %+v", bob)
+	}
+}
+
+func TestTaskCoordinator_TaskFailureHandlerTimeout(t *testing.T) {
+	coordinator, _ := newTestCoordinator(failingHandler{err: errors.New("gave up")})
+
+	failures := make(chan error, 1)
+	coordinator.SetTaskFailureHandler(func(task types.Task, err error) { failures <- err })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	coordinator.reportTaskFailure(ctx, types.Task{ID: "slow"}, errors.New("gave up"))
+
+	select {
+	case err := <-failures:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("failure handler err = %v, want it to wrap context.DeadlineExceeded", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the failure handler to be called")
+	}
+}
+
+// strictHandler accepts only "echo <text>" and rewrites conversational
+// input through its NLP fallback
+type strictHandler struct {
+	mu       sync.Mutex
+	tasks    []string
+	fallback func(text string) (string, error)
+}
+
+func (h *strictHandler) ProcessTask(ctx context.Context, task string) (string, error) {
+	h.mu.Lock()
+	h.tasks = append(h.tasks, task)
+	h.mu.Unlock()
+
+	text, ok := strings.CutPrefix(task, "echo ")
+	if !ok {
+		return "", fmt.Errorf("%w: usage: echo <text>", types.ErrUnrecognizedCommand)
+	}
+	return text, nil
+}
+
+func (h *strictHandler) ParseNaturalLanguage(ctx context.Context, text string) (string, error) {
+	return h.fallback(text)
+}
+
+func (h *strictHandler) seen() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.tasks...)
+}
+
+func TestTaskCoordinator_NLPFallback(t *testing.T) {
+	rewrite := func(text string) (string, error) {
+		return "echo " + strings.TrimPrefix(text, "please say "), nil
+	}
+
+	tests := []struct {
+		name        string
+		enabled     bool
+		fallback    func(string) (string, error)
+		task        string
+		wantSuccess bool
+		wantContent string
+		wantTasks   []string
+	}{
+		{"strict command", true, rewrite, "echo hi", true, "hi", []string{"echo hi"}},
+		{"conversational input", true, rewrite, "please say hello", true, "hello", []string{"please say hello", "echo hello"}},
+		{"fallback disabled", false, rewrite, "please say hello", false, "usage: echo <text>", []string{"please say hello"}},
+		{"fallback fails", true, func(string) (string, error) { return "", errors.New("model unavailable") }, "please say hello", false, "usage: echo <text>", []string{"please say hello"}},
+		{"fallback returns nothing", true, func(string) (string, error) { return " ", nil }, "gibberish", false, "usage: echo <text>", []string{"gibberish"}},
+		{"rewritten command still invalid", true, func(string) (string, error) { return "shout hello", nil }, "please shout", false, "usage: echo <text>", []string{"please shout", "shout hello"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &strictHandler{fallback: tt.fallback}
+			coordinator, sent := newTestCoordinator(handler)
+			coordinator.SetNLPFallback(tt.enabled)
+
+			coordinator.HandleIncomingTask(taskMessage(tt.task))
+			var msg *types.Message
+			select {
+			case msg = <-sent:
+			case <-time.After(time.Second):
+				t.Fatal("expected a task response")
+			}
+			if failed := msg.Type == types.MessageTypeAgentError; failed == tt.wantSuccess {
+				t.Fatalf("message type = %s, want success %v", msg.Type, tt.wantSuccess)
+			}
+			if !strings.Contains(msg.Content, tt.wantContent) {
+				t.Errorf("content = %q, want it to contain %q", msg.Content, tt.wantContent)
+			}
+			if got := handler.seen(); !reflect.DeepEqual(got, tt.wantTasks) {
+				t.Errorf("handler saw %q, want %q", got, tt.wantTasks)
+			}
+		})
+	}
+}
+
+func TestTaskCoordinator_NLPFallbackIgnoresOtherErrors(t *testing.T) {
+	coordinator, sent := newTestCoordinator(failingHandler{err: errors.New("upstream timeout")})
+	coordinator.SetNLPFallback(true)
+
+	coordinator.HandleIncomingTask(taskMessage("please say hello"))
+	if data := nextResponse(t, sent); data["error_code"] != types.ErrorCodeInternal {
+		t.Errorf("expected the task to fail without a fallback, got %v", data)
+	}
+}
+
+func TestTaskCoordinator_TaskErrorCodes(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantCode    string
+		wantContent string
+		wantDetails map[string]interface{}
+	}{
+		{"validation", fmt.Errorf("%w: token address is required", types.ErrInvalidTask), types.ErrorCodeValidation, "", nil},
+		{"unrecognized command", fmt.Errorf("%w: usage: analyze <token>", types.ErrUnrecognizedCommand), types.ErrorCodeValidation, "", nil},
+		{"rate limited", fmt.Errorf("price API: %w", types.ErrRateLimited), types.ErrorCodeRateLimited, "", nil},
+		{"timeout", fmt.Errorf("fetching trades: %w", context.DeadlineExceeded), types.ErrorCodeTimeout, "", nil},
+		{"task timeout", types.ErrTaskTimeout, types.ErrorCodeTimeout, "", nil},
+		{"internal", errors.New("nil pointer"), types.ErrorCodeInternal, "", nil},
+		{
+			"agent error",
+			&types.AgentError{Code: "TOKEN_NOT_FOUND", Message: "token not found", Details: map[string]interface{}{"chain": "base"}},
+			"TOKEN_NOT_FOUND", "❌ Error: token not found", map[string]interface{}{"chain": "base"},
+		},
+		{
+			"wrapped agent error without code",
+			fmt.Errorf("analysis: %w", &types.AgentError{Message: "try again later"}),
+			types.ErrorCodeInternal, "❌ Error: try again later", nil,
+		},
+	}
+
+	handlers := map[string]func(err error) types.AgentHandler{
+		"standard": func(err error) types.AgentHandler { return failingHandler{err: err} },
+		"rich":     func(err error) types.AgentHandler { return richFailingHandler{failingHandler{err: err}} },
+	}
+	for handlerName, newHandler := range handlers {
+		for _, tt := range tests {
+			t.Run(handlerName+"/"+tt.name, func(t *testing.T) {
+				coordinator, sent := newTestCoordinator(newHandler(tt.err))
+				coordinator.ExecuteTask("task-1", "analyze", "room")
+
+				msg := <-sent
+				if msg.Type != types.MessageTypeAgentError || msg.TaskID != "task-1" {
+					t.Fatalf("message = %s for %q, want agent_error for task-1", msg.Type, msg.TaskID)
+				}
+				var data types.AgentErrorData
+				if err := json.Unmarshal(msg.Data, &data); err != nil {
+					t.Fatal(err)
+				}
+				if data.ErrorCode != tt.wantCode || data.TaskID != "task-1" {
+					t.Errorf("error data = %+v, want code %s", data, tt.wantCode)
+				}
+
+				wantContent, wantDetails := tt.wantContent, tt.wantDetails
+				if wantContent == "" {
+					wantContent = "❌ Error: " + tt.err.Error()
+					wantDetails = map[string]interface{}{"error": tt.err.Error()}
+				}
+				if msg.Content != wantContent {
+					t.Errorf("content = %q, want %q", msg.Content, wantContent)
+				}
+				if !reflect.DeepEqual(data.Details, wantDetails) {
+					t.Errorf("details = %v, want %v", data.Details, wantDetails)
+				}
+			})
+		}
+	}
+}
+
+// richFailingHandler fails every rich task with err
+type richFailingHandler struct {
+	failingHandler
+}
+
+func (h richFailingHandler) ProcessRichTask(ctx context.Context, task string) (*types.TaskResult, error) {
+	return nil, h.err
+}
+
+// auditingHandler records the results passed to HandleTaskResult
+type auditingHandler struct {
+	mu      sync.Mutex
+	results map[string]string
+	err     error
+}
+
+func (h *auditingHandler) ProcessTask(ctx context.Context, task string) (string, error) {
+	if task == "fail" {
+		return "", errors.New("task failed")
+	}
+	return "result of " + task, nil
+}
+
+func (h *auditingHandler) HandleTaskResult(ctx context.Context, taskID, result string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.results[taskID] = result
+	return h.err
+}
+
+// streamingAuditingHandler streams an update and two messages per task
+type streamingAuditingHandler struct {
+	*auditingHandler
+}
+
+func (h streamingAuditingHandler) ProcessTaskWithStreaming(ctx context.Context, task, room string, sender types.MessageSender) error {
+	sender.SendTaskUpdate("working")
+	sender.SendMessage("partial " + task)
+	if task == "fail" {
+		return errors.New("task failed")
+	}
+	return sender.SendMessageAsMD("**final " + task + "**")
+}
+
+func TestTaskCoordinator_TaskResultHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(*auditingHandler) types.AgentHandler
+		want    string
+	}{
+		{"standard", func(h *auditingHandler) types.AgentHandler { return h }, "result of analyze"},
+		{"streaming", func(h *auditingHandler) types.AgentHandler { return streamingAuditingHandler{h} }, "**final analyze**"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audit := &auditingHandler{results: make(map[string]string), err: errors.New("audit log unavailable")}
+			coordinator, sent := newTestCoordinator(tt.handler(audit))
+
+			coordinator.ExecuteTask("task-1", "analyze", "room")
+			coordinator.ExecuteTask("task-2", "fail", "room")
+
+			if got := audit.results; len(got) != 1 || got["task-1"] != tt.want {
+				t.Errorf("HandleTaskResult got %v, want only task-1 with %q", got, tt.want)
+			}
+
+			// A failing result handler does not stop the result being sent
+			var last *types.Message
+			for len(sent) > 0 {
+				if msg := <-sent; msg.TaskID == "task-1" {
+					last = msg
+				}
+			}
+			if last == nil || last.Content != tt.want {
+				t.Errorf("last message for task-1 = %v, want %q", last, tt.want)
+			}
+		})
+	}
+}
+
+// resultOrder records how many messages had been sent when HandleTaskResult
+// was called
+type resultOrder struct {
+	sent       chan *types.Message
+	sentBefore int
+}
+
+func (o *resultOrder) HandleTaskResult(ctx context.Context, taskID, result string) error {
+	o.sentBefore = len(o.sent)
+	return nil
+}
+
+func TestTaskCoordinator_TaskResultHandledBeforeSend(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(*resultOrder) types.AgentHandler
+	}{
+		{"standard", func(o *resultOrder) types.AgentHandler {
+			return struct {
+				failingHandler
+				*resultOrder
+			}{failingHandler{}, o}
+		}},
+		{"rich", func(o *resultOrder) types.AgentHandler {
+			return struct {
+				richHandler
+				*resultOrder
+			}{richHandler{}, o}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &resultOrder{sentBefore: -1}
+			coordinator, sent := newTestCoordinator(tt.handler(order))
+			order.sent = sent
+
+			coordinator.ExecuteTask("task-1", "analyze", "room")
+			if order.sentBefore != 0 {
+				t.Errorf("HandleTaskResult saw %d sent messages, want it called before the result is sent", order.sentBefore)
+			}
+			if len(sent) == 0 {
+				t.Error("the result was not sent")
+			}
+		})
+	}
+}
+
+func TestTaskMessageSender_LastMessageAfterSuccessfulSend(t *testing.T) {
+	coordinator, _ := newTestCoordinator(&auditingHandler{})
+	sender := coordinator.newMessageSender("task-1", "room")
+	sender.deliver = func(msg *types.Message) error {
+		if msg.Content == "lost" {
+			return errors.New("connection closed")
+		}
+		return nil
+	}
+
+	if err := sender.SendMessage("delivered"); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if err := sender.SendMessage("lost"); err == nil {
+		t.Fatal("SendMessage() succeeded, want the delivery error")
+	}
+	if err := sender.sendResult(&types.TaskResult{TaskID: "task-1"}, "lost"); err == nil {
+		t.Fatal("sendResult() succeeded, want the delivery error")
+	}
+	if sender.lastMessage != "delivered" {
+		t.Errorf("lastMessage = %q, want the last message delivered", sender.lastMessage)
+	}
+}
+
+// richHandler returns structured results; task "error" fails and task
+// "reported" returns a result with an error code
+type richHandler struct{}
+
+func (richHandler) ProcessTask(ctx context.Context, task string) (string, error) {
+	return "", errors.New("ProcessTask must not be called when ProcessRichTask is implemented")
+}
+
+func (richHandler) ProcessRichTask(ctx context.Context, task string) (*types.TaskResult, error) {
+	switch task {
+	case "error":
+		return nil, errors.New("chain unavailable")
+	case "reported":
+		return &types.TaskResult{Result: "no swaps found", Error: "no_data"}, nil
+	}
+	time.Sleep(10 * time.Millisecond)
+	return &types.TaskResult{Result: "42 wallets", Metadata: map[string]string{"chain": "ethereum"}}, nil
+}
+
+func TestTaskCoordinator_RichTaskHandler(t *testing.T) {
+	tests := []struct {
+		task        string
+		wantContent string
+		wantSuccess bool
+		wantError   string
+	}{
+		{"analyze", "42 wallets", true, ""},
+		{"reported", "no swaps found", false, "no_data"},
+	}
+
+	coordinator, sent := newTestCoordinator(richHandler{})
+	for _, tt := range tests {
+		t.Run(tt.task, func(t *testing.T) {
+			coordinator.ExecuteTask("task-"+tt.task, tt.task, "room")
+
+			msg := <-sent
+			if msg.Content != tt.wantContent || msg.TaskID != "task-"+tt.task {
+				t.Errorf("message = task %q %q, want task-%s %q", msg.TaskID, msg.Content, tt.task, tt.wantContent)
+			}
+
+			var envelope types.TaskResult
+			if err := json.Unmarshal(msg.Data, &envelope); err != nil {
+				t.Fatalf("data is not a TaskResult: %v", err)
+			}
+			if envelope.TaskID != "task-"+tt.task || envelope.Success != tt.wantSuccess || envelope.Error != tt.wantError {
+				t.Errorf("envelope = %+v, want task-%s success=%v error=%q", envelope, tt.task, tt.wantSuccess, tt.wantError)
+			}
+			if envelope.CreatedAt.IsZero() || envelope.Metadata["agent"] != "test-agent" {
+				t.Errorf("envelope = %+v, want creation time and agent metadata", envelope)
+			}
+			if tt.wantSuccess {
+				if envelope.Duration < 10*time.Millisecond || envelope.Metadata["chain"] != "ethereum" {
+					t.Errorf("envelope = %+v, want duration of at least 10ms and the handler's metadata", envelope)
+				}
+			}
+		})
+	}
+
+	// Handler errors are sent as agent_error messages, see TestTaskCoordinator_TaskErrorCodes
+	coordinator.ExecuteTask("task-error", "error", "room")
+	if msg := <-sent; msg.Type != types.MessageTypeAgentError || msg.Content != "❌ Error: chain unavailable" {
+		t.Errorf("message = %s %q, want an agent_error for the handler error", msg.Type, msg.Content)
+	}
+
+	if metrics := coordinator.GetMetrics(); metrics.TasksSuccessful != 1 || metrics.TasksFailed != 2 {
+		t.Errorf("metrics = %d successful, %d failed, want 1 and 2", metrics.TasksSuccessful, metrics.TasksFailed)
+	}
+}
+
+func TestTaskCoordinator_ProcessTask(t *testing.T) {
+	tests := []struct {
+		name        string
+		handler     func(*auditingHandler) types.AgentHandler
+		task        string
+		wantSuccess bool
+		wantResult  string
+		wantLast    string
+	}{
+		{"standard", func(h *auditingHandler) types.AgentHandler { return h }, "analyze", true, "result of analyze", "result of analyze"},
+		{"streaming", func(h *auditingHandler) types.AgentHandler { return streamingAuditingHandler{h} }, "analyze", true, "**final analyze**", "**final analyze**"},
+		{"streaming failure", func(h *auditingHandler) types.AgentHandler { return streamingAuditingHandler{h} }, "fail", false, "", "❌ Error: task failed"},
+		{"rich", func(*auditingHandler) types.AgentHandler { return richHandler{} }, "reported", false, "no swaps found", "no swaps found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audit := &auditingHandler{results: make(map[string]string)}
+			coordinator, sent := newTestCoordinator(tt.handler(audit))
+
+			output, err := coordinator.ProcessTask(context.Background(), types.Task{Content: tt.task})
+			if err != nil {
+				t.Fatalf("ProcessTask() error = %v", err)
+			}
+			if output.TaskID == "" || output.Success != tt.wantSuccess || output.Result != tt.wantResult {
+				t.Errorf("output = %+v, want success=%v result %q", output, tt.wantSuccess, tt.wantResult)
+			}
+			if tt.wantSuccess != (output.Error == "") {
+				t.Errorf("output error = %q with success=%v", output.Error, output.Success)
+			}
+			if n := len(output.Messages); n == 0 || output.Messages[n-1].Content != tt.wantLast || output.Messages[n-1].TaskID != output.TaskID {
+				t.Errorf("messages = %v, want the last one %q for %s", output.Messages, tt.wantLast, output.TaskID)
+			}
+			if len(sent) != 0 {
+				t.Errorf("%d messages were sent to the network", len(sent))
+			}
+			if tt.wantSuccess && audit.results[output.TaskID] != tt.wantResult {
+				t.Errorf("HandleTaskResult got %v, want %q", audit.results, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestTaskCoordinator_ProcessTaskWaitsInQueue(t *testing.T) {
+	handler := newBlockingHandler()
+	coordinator, _ := newTestCoordinator(handler)
+	coordinator.SetMaxConcurrentTasks(1)
+	coordinator.SetTaskQueue(1, QueuePolicyReject)
+
+	coordinator.submitTask(types.Task{ID: "network-1", Content: "network"}, "room")
+	<-handler.started
+
+	outputs := make(chan *TaskOutput, 1)
+	go func() {
+		output, err := coordinator.ProcessTask(context.Background(), types.Task{Content: "local"})
+		if err != nil {
+			t.Errorf("ProcessTask() error = %v", err)
+		}
+		outputs <- output
+	}()
+	waitFor(t, func() bool { return coordinator.GetQueuedTaskCount() == 1 })
+	select {
+	case task := <-handler.started:
+		t.Fatalf("task %q started while the only slot was busy", task)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if _, err := coordinator.ProcessTask(context.Background(), types.Task{Content: "overflow"}); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("ProcessTask() on a full queue error = %v, want ErrQueueFull", err)
+	}
+
+	close(handler.release)
+	if task := <-handler.started; task != "local" {
+		t.Errorf("started %q, want local", task)
+	}
+	if output := <-outputs; output == nil || !output.Success {
+		t.Errorf("ProcessTask() output = %+v, want success", output)
+	}
+}
+
+func TestTaskCoordinator_ProcessTaskRejections(t *testing.T) {
+	coordinator, _ := newTestCoordinator(failingHandler{err: errors.New("boom")})
+	coordinator.SetRateLimit(1)
+
+	coordinator.Pause()
+	if _, err := coordinator.ProcessTask(context.Background(), types.Task{Content: "a"}); !errors.Is(err, ErrTasksPaused) {
+		t.Errorf("paused ProcessTask() error = %v, want ErrTasksPaused", err)
+	}
+	coordinator.Resume()
+
+	output, err := coordinator.ProcessTask(context.Background(), types.Task{Content: "a"})
+	if err != nil || output.Success || output.Error != "boom" {
+		t.Errorf("ProcessTask() = %+v, %v, want a failed task with error boom", output, err)
+	}
+	if _, err := coordinator.ProcessTask(context.Background(), types.Task{Content: "b"}); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("second ProcessTask() error = %v, want ErrRateLimited", err)
+	}
+	if metrics := coordinator.GetMetrics(); metrics.TasksFailed != 1 {
+		t.Errorf("metrics = %d failed, want 1", metrics.TasksFailed)
+	}
+}
@@ -0,0 +1,57 @@
+package network
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// truncatedMarker ends message content cut short by truncateMessage
+const truncatedMarker = "… [truncated]"
+
+// splitMessage splits content into chunks of at most limit bytes. Chunks
+// break after the last newline that fits, so lines stay whole where
+// possible, and otherwise at a UTF-8 character boundary; joining the chunks
+// gives back content. A limit of 0 or less returns content unchanged.
+func splitMessage(content string, limit int) []string {
+	if limit <= 0 || len(content) <= limit {
+		return []string{content}
+	}
+
+	var chunks []string
+	for len(content) > limit {
+		end := strings.LastIndexByte(content[:limit], '\n') + 1
+		if end == 0 {
+			end = runeBoundary(content, limit)
+		}
+		chunks = append(chunks, content[:end])
+		content = content[end:]
+	}
+	if content != "" {
+		chunks = append(chunks, content)
+	}
+	return chunks
+}
+
+// truncateMessage cuts content to at most limit bytes, ending it with
+// truncatedMarker when anything was removed. A limit of 0 or less returns
+// content unchanged.
+func truncateMessage(content string, limit int) string {
+	if limit <= 0 || len(content) <= limit {
+		return content
+	}
+	if limit <= len(truncatedMarker) {
+		return content[:runeBoundary(content, limit)]
+	}
+	return content[:runeBoundary(content, limit-len(truncatedMarker))] + truncatedMarker
+}
+
+// runeBoundary returns the largest index no greater than n that does not
+// split a UTF-8 character, or n itself if that would leave nothing
+func runeBoundary(s string, n int) int {
+	for i := n; i > 0; i-- {
+		if utf8.RuneStart(s[i]) {
+			return i
+		}
+	}
+	return n
+}
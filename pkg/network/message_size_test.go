@@ -0,0 +1,143 @@
+package network
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/types"
+)
+
+func TestSplitMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		limit   int
+		want    []string
+	}{
+		{"no limit", "abcdef", 0, []string{"abcdef"}},
+		{"under limit", "abcde", 6, []string{"abcde"}},
+		{"at limit", "abcdef", 6, []string{"abcdef"}},
+		{"over limit", "abcdefg", 6, []string{"abcdef", "g"}},
+		{"breaks after newline", "ab\ncd\nefgh", 7, []string{"ab\ncd\n", "efgh"}},
+		{"long line", "abcdefghij\nklmno", 4, []string{"abcd", "efgh", "ij\n", "klmn", "o"}},
+		{"multibyte characters", "ééé", 3, []string{"é", "é", "é"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitMessage(tt.content, tt.limit)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Fatalf("splitMessage() = %q, want %q", got, tt.want)
+			}
+			for _, chunk := range got {
+				if tt.limit > 0 && len(chunk) > tt.limit {
+					t.Errorf("chunk %q is over the %d byte limit", chunk, tt.limit)
+				}
+				if !utf8.ValidString(chunk) {
+					t.Errorf("chunk %q splits a character", chunk)
+				}
+			}
+		})
+	}
+}
+
+func TestTruncateMessage(t *testing.T) {
+	long := strings.Repeat("x", 50)
+
+	if got := truncateMessage(long, 50); got != long {
+		t.Errorf("truncateMessage() at limit = %q, want it unchanged", got)
+	}
+	got := truncateMessage(long, 30)
+	if len(got) != 30 || !strings.HasSuffix(got, truncatedMarker) {
+		t.Errorf("truncateMessage() = %q, want 30 bytes ending in %q", got, truncatedMarker)
+	}
+	if got := truncateMessage(long, 5); got != "xxxxx" {
+		t.Errorf("truncateMessage() below marker length = %q, want %q", got, "xxxxx")
+	}
+}
+
+// sentContents drains the contents of every message sent so far
+func sentContents(sent chan *types.Message) []string {
+	var contents []string
+	for {
+		select {
+		case msg := <-sent:
+			contents = append(contents, msg.Content)
+		default:
+			return contents
+		}
+	}
+}
+
+func TestTaskMessageSender_MaxMessageBytes(t *testing.T) {
+	const limit = 20
+
+	tests := []struct {
+		name       string
+		size       int
+		wantChunks int
+	}{
+		{"just under", limit - 1, 1},
+		{"at limit", limit, 1},
+		{"over limit", limit + 1, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coordinator, sent := newTestCoordinator(failingHandler{})
+			coordinator.SetMaxMessageBytes(limit)
+			sender := coordinator.newMessageSender("task-1", "room")
+			content := strings.Repeat("a", tt.size)
+
+			for _, send := range []func(string) error{sender.SendMessage, sender.SendMessageAsMD} {
+				if err := send(content); err != nil {
+					t.Fatalf("send error = %v", err)
+				}
+				got := sentContents(sent)
+				if len(got) != tt.wantChunks || strings.Join(got, "") != content {
+					t.Errorf("sent %q, want %d messages joining to the content", got, tt.wantChunks)
+				}
+			}
+
+			err := sender.SendMessageAsJSON(`"` + content[2:] + `"`)
+			if tt.size > limit {
+				if !errors.Is(err, types.ErrMessageTooLarge) {
+					t.Errorf("SendMessageAsJSON() error = %v, want ErrMessageTooLarge", err)
+				}
+				if got := sentContents(sent); len(got) != 0 {
+					t.Errorf("oversized JSON sent %q, want nothing", got)
+				}
+			} else if err != nil {
+				t.Errorf("SendMessageAsJSON() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestTaskMessageSender_MaxMessageBytesTruncatesUpdates(t *testing.T) {
+	coordinator, sent := newTestCoordinator(failingHandler{})
+	coordinator.SetMaxMessageBytes(30)
+	sender := coordinator.newMessageSender("task-1", "room")
+
+	if err := sender.SendTaskUpdate(strings.Repeat("a", 100)); err != nil {
+		t.Fatal(err)
+	}
+	got := sentContents(sent)
+	if len(got) != 1 || len(got[0]) > 30 || !strings.HasSuffix(got[0], truncatedMarker) {
+		t.Errorf("sent %q, want one truncated update", got)
+	}
+}
+
+func TestTaskMessageSender_MarshalsJSON(t *testing.T) {
+	coordinator, sent := newTestCoordinator(failingHandler{})
+	sender := coordinator.newMessageSender("task-1", "room")
+
+	if err := sender.SendMessageAsJSON(map[string]int{"wallets": 3}); err != nil {
+		t.Fatal(err)
+	}
+	if got := sentContents(sent); len(got) != 1 || got[0] != `{"wallets":3}` {
+		t.Errorf("sent %q, want the marshaled JSON", got)
+	}
+}
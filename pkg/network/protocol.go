@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/auth"
@@ -17,12 +18,18 @@ type ProtocolHandler struct {
 	client                 *NetworkClient
 	auth                   *auth.Manager
 	agentName              string
+	capabilitiesMu         sync.RWMutex
 	capabilities           []string
+	capabilitiesPending    bool // changed before authentication, sent after registration
 	walletAddr             string
 	nftTokenID             string
 	room                   string
 	lastChallenge          string
 	lastChallengeSignature string
+
+	// Wallet transaction requests awaiting the user's tx_result, by request ID
+	pendingTxMu sync.Mutex
+	pendingTx   map[string]*pendingWalletTx
 }
 
 // NewProtocolHandler creates a new protocol handler
@@ -37,6 +44,7 @@ func NewProtocolHandler(client *NetworkClient, authManager *auth.Manager, agentN
 		room:                   room,
 		lastChallenge:          "",
 		lastChallengeSignature: "",
+		pendingTx:              make(map[string]*pendingWalletTx),
 	}
 
 	// Register message handlers
@@ -62,6 +70,7 @@ func (p *ProtocolHandler) registerHandlers() {
 
 	// Add task handling
 	p.client.RegisterHandler("task", p.HandleTask)
+	p.client.RegisterHandler(types.MessageTypeTxResult, p.HandleTxResult)
 }
 
 // StartAuthentication initiates the authentication process
@@ -157,7 +166,7 @@ func (p *ProtocolHandler) HandleAuthResponse(msg *types.Message) error {
 	if strings.Contains(msg.Content, "successful") {
 		p.client.SetAuthenticated(true)
 		log.Printf("✅ Authentication successful! Agent connected to Teneo network")
-		return p.SendRegistration()
+		return p.register()
 	} else {
 		log.Printf("❌ Authentication failed: %s", msg.Content)
 		p.client.SetAuthenticated(false)
@@ -169,7 +178,30 @@ func (p *ProtocolHandler) HandleAuthResponse(msg *types.Message) error {
 func (p *ProtocolHandler) HandleAuthSuccess(msg *types.Message) error {
 	log.Printf("✅ Authentication successful! Agent connected to Teneo network")
 	p.client.SetAuthenticated(true)
-	return p.SendRegistration()
+	return p.register()
+}
+
+// register sends the registration, followed by capabilities that changed
+// while the agent was not authenticated
+func (p *ProtocolHandler) register() error {
+	if err := p.SendRegistration(); err != nil {
+		return err
+	}
+
+	p.capabilitiesMu.Lock()
+	pending := p.capabilitiesPending
+	p.capabilitiesPending = false
+	p.capabilitiesMu.Unlock()
+	if !pending {
+		return nil
+	}
+	if err := p.SendCapabilities(); err != nil {
+		p.capabilitiesMu.Lock()
+		p.capabilitiesPending = true
+		p.capabilitiesMu.Unlock()
+		return fmt.Errorf("failed to send deferred capabilities: %w", err)
+	}
+	return nil
 }
 
 // HandleAuthError handles authentication error messages
@@ -181,7 +213,7 @@ func (p *ProtocolHandler) HandleAuthError(msg *types.Message) error {
 
 // HandleRegistrationSuccess handles successful agent registration
 func (p *ProtocolHandler) HandleRegistrationSuccess(msg *types.Message) error {
-	log.Printf("✅ Agent registered successfully with capabilities: %v", p.capabilities)
+	log.Printf("✅ Agent registered successfully with capabilities: %v", p.GetCapabilities())
 	return nil
 }
 
@@ -218,7 +250,7 @@ func (p *ProtocolHandler) HandleCapabilitiesResponse(msg *types.Message) error {
 		// Process capabilities if present
 		if capData, ok := capabilities["capabilities"].([]interface{}); ok {
 			p.UpdateCapabilities(convertInterfaceSliceToStringSlice(capData))
-			log.Printf("Updated capabilities: %v", p.capabilities)
+			log.Printf("Updated capabilities: %v", p.GetCapabilities())
 		}
 	}
 
@@ -298,6 +330,28 @@ func (p *ProtocolHandler) HandleTask(msg *types.Message) error {
 	return p.processTask(msg.From, taskContent, taskID, msg.Room)
 }
 
+// taskSender returns the wallet or user that originated a task or message.
+// Tasks relayed by the coordinator carry the originator in their data as
+// "from" or "user_address"; other messages come straight from their sender.
+// Returns "" when the originator is unknown.
+func (p *ProtocolHandler) taskSender(msg *types.Message) string {
+	if msg.Data != nil {
+		var taskData map[string]interface{}
+		if err := json.Unmarshal(msg.Data, &taskData); err == nil {
+			for _, key := range []string{"from", "user_address"} {
+				if sender, ok := taskData[key].(string); ok && sender != "" {
+					return strings.ToLower(sender)
+				}
+			}
+		}
+	}
+
+	if msg.From == "" || msg.From == "coordinator" {
+		return ""
+	}
+	return strings.ToLower(msg.From)
+}
+
 // processTask processes a task and sends a response
 func (p *ProtocolHandler) processTask(from, content, taskID, room string) error {
 	log.Printf("🔄 Processing task: %s", content)
@@ -337,9 +391,10 @@ func (p *ProtocolHandler) processTask(from, content, taskID, room string) error
 // SendCapabilities sends agent capabilities to the server
 func (p *ProtocolHandler) SendCapabilities() error {
 	// Send capabilities in the same format as x-agent (simple JSON, not wrapped in Message)
+	capabilities := p.GetCapabilities()
 	capMsg := map[string]interface{}{
 		"type":         "capabilities",
-		"capabilities": p.capabilities,
+		"capabilities": capabilities,
 		"room":         p.room,
 	}
 
@@ -348,7 +403,7 @@ func (p *ProtocolHandler) SendCapabilities() error {
 		return fmt.Errorf("failed to marshal capabilities: %w", err)
 	}
 
-	log.Printf("📋 Sending capabilities: %v", p.capabilities)
+	log.Printf("📋 Sending capabilities: %v", capabilities)
 
 	// Send directly via WebSocket using the new SendRawData method
 	return p.client.SendRawData(data)
@@ -369,7 +424,7 @@ func (p *ProtocolHandler) SendPing() error {
 // RegisterAgent registers the agent with the server
 func (p *ProtocolHandler) RegisterAgent() error {
 	registerData, err := json.Marshal(map[string]interface{}{
-		"capabilities": p.capabilities,
+		"capabilities": p.GetCapabilities(),
 		"description":  fmt.Sprintf("%s - Teneo network agent", p.agentName),
 	})
 	if err != nil {
@@ -391,6 +446,21 @@ func (p *ProtocolHandler) RegisterAgent() error {
 
 // SendTaskResponseToRoom sends a task response back to the coordinator using a specific room
 func (p *ProtocolHandler) SendTaskResponseToRoom(taskID, content string, contentType string, success bool, errorMsg, room string) error {
+	msg, err := p.newTaskResponse(taskID, content, contentType, success, errorMsg, room)
+	if err != nil {
+		return err
+	}
+
+	// Log for debugging
+	log.Printf("🐛 DEBUG: Sending task response with room context - Room: %s, TaskID: %s, Agent: %s",
+		room, taskID, p.agentName)
+
+	// Send via WebSocket with room context preserved
+	return p.client.SendMessage(msg)
+}
+
+// newTaskResponse builds the task response SendTaskResponseToRoom sends
+func (p *ProtocolHandler) newTaskResponse(taskID, content string, contentType string, success bool, errorMsg, room string) (*types.Message, error) {
 	// Create response data for the Data field
 	responseData := map[string]interface{}{
 		"task_id": taskID,
@@ -403,11 +473,11 @@ func (p *ProtocolHandler) SendTaskResponseToRoom(taskID, content string, content
 
 	data, err := json.Marshal(responseData)
 	if err != nil {
-		return fmt.Errorf("failed to marshal response data: %w", err)
+		return nil, fmt.Errorf("failed to marshal response data: %w", err)
 	}
 
 	// Create message with room context fields that client expects
-	msg := &types.Message{
+	return &types.Message{
 		Type:          "task_response",
 		From:          p.agentName, // Use agent name instead of wallet
 		Room:          room,        // SDK internal field
@@ -418,24 +488,67 @@ func (p *ProtocolHandler) SendTaskResponseToRoom(taskID, content string, content
 		TaskID:        taskID,
 		Data:          data,
 		Timestamp:     time.Now(),
+	}, nil
+}
+
+// SendTaskResultToRoom sends content as a task response whose data is the
+// whole result envelope, a superset of the task ID, success flag and error
+// that SendTaskResponseToRoom sends
+func (p *ProtocolHandler) SendTaskResultToRoom(result *types.TaskResult, content, room string) error {
+	msg, err := p.newTaskResult(result, content, room)
+	if err != nil {
+		return err
 	}
+	return p.client.SendMessage(msg)
+}
 
-	// Log for debugging
-	log.Printf("🐛 DEBUG: Sending task response with room context - Room: %s, TaskID: %s, Agent: %s",
-		room, taskID, p.agentName)
+// newTaskResult builds the task response SendTaskResultToRoom sends
+func (p *ProtocolHandler) newTaskResult(result *types.TaskResult, content, room string) (*types.Message, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal task result: %w", err)
+	}
 
-	// Send via WebSocket with room context preserved
-	return p.client.SendMessage(msg)
+	return &types.Message{
+		Type:          "task_response",
+		From:          p.agentName,
+		Room:          room,
+		DataRoom:      room,
+		MessageRoomId: room,
+		Content:       content,
+		ContentType:   types.StandardMessageTypeString,
+		TaskID:        result.TaskID,
+		Data:          data,
+		Timestamp:     time.Now(),
+	}, nil
 }
 
 // UpdateCapabilities updates the agent's capabilities
 func (p *ProtocolHandler) UpdateCapabilities(capabilities []string) {
-	p.capabilities = capabilities
+	p.capabilitiesMu.Lock()
+	defer p.capabilitiesMu.Unlock()
+	p.capabilities = append([]string(nil), capabilities...)
+}
+
+// PushCapabilities sends the current capabilities to the backend so that
+// discovery reflects them. Before authentication the push is deferred until
+// the agent has registered.
+func (p *ProtocolHandler) PushCapabilities() error {
+	if !p.client.IsAuthenticated() {
+		p.capabilitiesMu.Lock()
+		p.capabilitiesPending = true
+		p.capabilitiesMu.Unlock()
+		log.Printf("📋 Not authenticated yet, capabilities will be sent after registration")
+		return nil
+	}
+	return p.SendCapabilities()
 }
 
-// GetCapabilities returns the current capabilities
+// GetCapabilities returns a copy of the current capabilities
 func (p *ProtocolHandler) GetCapabilities() []string {
-	return p.capabilities
+	p.capabilitiesMu.RLock()
+	defer p.capabilitiesMu.RUnlock()
+	return append([]string(nil), p.capabilities...)
 }
 
 // GetWalletAddress returns the wallet address
@@ -0,0 +1,117 @@
+package network
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/types"
+	"github.com/gorilla/websocket"
+)
+
+// newRecordingServer starts a WebSocket server that forwards every message
+// it receives to the returned channel
+func newRecordingServer(t *testing.T) (*httptest.Server, chan map[string]interface{}) {
+	t.Helper()
+	received := make(chan map[string]interface{}, 10)
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var msg map[string]interface{}
+			if json.Unmarshal(data, &msg) == nil {
+				received <- msg
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, received
+}
+
+// nextCapabilities waits for a capabilities message, skipping others
+func nextCapabilities(t *testing.T, received chan map[string]interface{}) []string {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case msg := <-received:
+			if msg["type"] != "capabilities" {
+				continue
+			}
+			var capabilities []string
+			for _, c := range msg["capabilities"].([]interface{}) {
+				capabilities = append(capabilities, c.(string))
+			}
+			return capabilities
+		case <-timeout:
+			t.Fatal("expected a capabilities message")
+			return nil
+		}
+	}
+}
+
+func TestProtocolHandler_PushCapabilities(t *testing.T) {
+	srv, received := newRecordingServer(t)
+	config := DefaultNetworkConfig()
+	config.WebSocketURL = "ws" + strings.TrimPrefix(srv.URL, "http")
+	client := NewNetworkClient(config)
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { client.Disconnect() })
+	protocol := NewProtocolHandler(client, nil, "test-agent", []string{"general"}, "0xagent", "7", "room")
+
+	// Before authentication the push waits for registration
+	protocol.UpdateCapabilities([]string{"general", "translation"})
+	if err := protocol.PushCapabilities(); err != nil {
+		t.Fatalf("PushCapabilities() error = %v", err)
+	}
+	select {
+	case msg := <-received:
+		t.Fatalf("sent %v before authentication", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := protocol.HandleAuthSuccess(&types.Message{Type: "auth_success"}); err != nil {
+		t.Fatalf("HandleAuthSuccess() error = %v", err)
+	}
+	if got, want := nextCapabilities(t, received), []string{"general", "translation"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("deferred capabilities = %v, want %v", got, want)
+	}
+
+	// Once authenticated the push is immediate and sent once
+	protocol.UpdateCapabilities([]string{"summarize"})
+	if err := protocol.PushCapabilities(); err != nil {
+		t.Fatalf("PushCapabilities() error = %v", err)
+	}
+	if got, want := nextCapabilities(t, received), []string{"summarize"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("capabilities = %v, want %v", got, want)
+	}
+
+	if err := protocol.HandleAuthSuccess(&types.Message{Type: "auth_success"}); err != nil {
+		t.Fatalf("HandleAuthSuccess() error = %v", err)
+	}
+	timeout := time.After(100 * time.Millisecond)
+	for {
+		select {
+		case msg := <-received:
+			if msg["type"] == "capabilities" {
+				t.Fatal("capabilities sent again on re-registration without a change")
+			}
+		case <-timeout:
+			return
+		}
+	}
+}
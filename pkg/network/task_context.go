@@ -0,0 +1,136 @@
+package network
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultTaskTimeout is how long a task may run, not counting time spent
+// waiting for the user to answer wallet transaction requests
+const defaultTaskTimeout = 30 * time.Second
+
+// taskContext is the context a task runs with. Unlike a context from
+// context.WithTimeout, its deadline stops running while the task waits for
+// a wallet transaction, so RequestWalletTx can wait for its own timeout.
+type taskContext struct {
+	context.Context // the parent, for values
+
+	done       chan struct{}
+	stopParent func() bool
+
+	mu        sync.Mutex
+	deadline  time.Time
+	timer     *time.Timer
+	err       error
+	holds     int           // wallet transactions being waited for
+	remaining time.Duration // time the task had left when the first hold began
+}
+
+// newTaskContext returns a context that expires timeout from now or when
+// parent is done, and a function that cancels it
+func newTaskContext(parent context.Context, timeout time.Duration) (*taskContext, context.CancelFunc) {
+	c := &taskContext{
+		Context:  parent,
+		done:     make(chan struct{}),
+		deadline: time.Now().Add(timeout),
+	}
+
+	c.mu.Lock()
+	c.timer = time.AfterFunc(timeout, c.expire)
+	c.stopParent = context.AfterFunc(parent, func() { c.finish(parent.Err()) })
+	c.mu.Unlock()
+
+	return c, func() { c.finish(context.Canceled) }
+}
+
+// Deadline returns the task's current deadline, or the parent's if earlier
+func (c *taskContext) Deadline() (time.Time, bool) {
+	c.mu.Lock()
+	deadline := c.deadline
+	c.mu.Unlock()
+
+	if parent, ok := c.Context.Deadline(); ok && parent.Before(deadline) {
+		return parent, true
+	}
+	return deadline, true
+}
+
+func (c *taskContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *taskContext) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// holdDeadline stops the task's deadline from running for up to wait, while
+// the task waits for a wallet transaction. The returned function ends the
+// hold; once no holds remain, the task gets back the time it had left.
+func (c *taskContext) holdDeadline(wait time.Duration) func() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return func() {}
+	}
+
+	if c.holds == 0 {
+		c.remaining = time.Until(c.deadline)
+		if c.remaining < 0 {
+			c.remaining = 0
+		}
+	}
+	c.holds++
+	if deadline := time.Now().Add(wait + c.remaining); deadline.After(c.deadline) {
+		c.setDeadline(deadline)
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.holds--
+			if c.holds == 0 && c.err == nil {
+				c.setDeadline(time.Now().Add(c.remaining))
+			}
+		})
+	}
+}
+
+// setDeadline moves the deadline, c.mu must be held
+func (c *taskContext) setDeadline(deadline time.Time) {
+	c.deadline = deadline
+	c.timer.Reset(time.Until(deadline))
+}
+
+// expire ends the task unless its deadline was moved since the timer fired
+func (c *taskContext) expire() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Now().Before(c.deadline) {
+		return
+	}
+	c.finishLocked(context.DeadlineExceeded)
+}
+
+// finish ends the context with err unless it has already ended
+func (c *taskContext) finish(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.finishLocked(err)
+}
+
+// finishLocked is finish with c.mu held
+func (c *taskContext) finishLocked(err error) {
+	if c.err != nil {
+		return
+	}
+
+	c.err = err
+	c.timer.Stop()
+	c.stopParent()
+	close(c.done)
+}
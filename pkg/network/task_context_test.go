@@ -0,0 +1,69 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTaskContext_Expires(t *testing.T) {
+	ctx, cancel := newTaskContext(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected the task context to expire")
+	}
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		t.Errorf("Err() = %v, want context.DeadlineExceeded", ctx.Err())
+	}
+
+	// Contexts derived by handlers see the same error
+	child, cancelChild := context.WithCancel(ctx)
+	defer cancelChild()
+	if !errors.Is(child.Err(), context.DeadlineExceeded) {
+		t.Errorf("child Err() = %v, want context.DeadlineExceeded", child.Err())
+	}
+}
+
+func TestTaskContext_HoldDeadline(t *testing.T) {
+	ctx, cancel := newTaskContext(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	release := ctx.holdDeadline(time.Second)
+	time.Sleep(100 * time.Millisecond)
+	if ctx.Err() != nil {
+		t.Fatalf("Err() = %v during a hold, want nil", ctx.Err())
+	}
+
+	// The task gets back the time it had left, not the whole hold
+	release()
+	deadline, _ := ctx.Deadline()
+	if left := time.Until(deadline); left <= 0 || left > 50*time.Millisecond {
+		t.Errorf("time left after the hold = %v, want at most 50ms", left)
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected the task context to expire after the hold")
+	}
+}
+
+func TestTaskContext_ParentCanceled(t *testing.T) {
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx, cancel := newTaskContext(parent, time.Minute)
+	defer cancel()
+
+	ctx.holdDeadline(time.Minute)
+	cancelParent()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected the task context to end with its parent")
+	}
+	if !errors.Is(ctx.Err(), context.Canceled) {
+		t.Errorf("Err() = %v, want context.Canceled", ctx.Err())
+	}
+}
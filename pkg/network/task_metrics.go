@@ -0,0 +1,95 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/types"
+)
+
+// responseTimeWindow is the number of recent tasks the average response
+// time is computed over
+const responseTimeWindow = 100
+
+// taskMetrics counts finished tasks and their durations
+type taskMetrics struct {
+	mu        sync.Mutex
+	now       func() time.Time // replaced in tests
+	processed int64
+	succeeded int64
+	failed    int64
+
+	// durations holds the response times of the last responseTimeWindow
+	// tasks, oldest first once full
+	durations []time.Duration
+	next      int
+
+	// finished holds the completion times of tasks in the last hour
+	finished []time.Time
+}
+
+func newTaskMetrics() *taskMetrics {
+	return &taskMetrics{now: time.Now}
+}
+
+// record adds a finished task that took duration and failed with err, if any
+func (m *taskMetrics) record(duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.processed++
+	if err != nil {
+		m.failed++
+	} else {
+		m.succeeded++
+	}
+
+	if len(m.durations) < responseTimeWindow {
+		m.durations = append(m.durations, duration)
+	} else {
+		m.durations[m.next] = duration
+		m.next = (m.next + 1) % responseTimeWindow
+	}
+
+	now := m.now()
+	m.finished = append(m.pruneFinished(now), now)
+}
+
+// pruneFinished drops completion times older than an hour
+func (m *taskMetrics) pruneFinished(now time.Time) []time.Time {
+	cutoff := now.Add(-time.Hour)
+	i := 0
+	for i < len(m.finished) && !m.finished[i].After(cutoff) {
+		i++
+	}
+	return m.finished[i:]
+}
+
+// snapshot returns the metrics computed from the recorded tasks
+func (m *taskMetrics) snapshot() types.AgentMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.finished = m.pruneFinished(now)
+
+	metrics := types.AgentMetrics{
+		TasksProcessed:  m.processed,
+		TasksSuccessful: m.succeeded,
+		TasksFailed:     m.failed,
+		TasksPerHour:    float64(len(m.finished)),
+		LastUpdated:     now,
+	}
+	if m.processed > 0 {
+		metrics.SuccessRate = float64(m.succeeded) / float64(m.processed)
+		metrics.ErrorRate = float64(m.failed) / float64(m.processed)
+	}
+	if len(m.durations) > 0 {
+		var total time.Duration
+		for _, d := range m.durations {
+			total += d
+		}
+		metrics.AverageResponseTime = total / time.Duration(len(m.durations))
+	}
+	return metrics
+}
@@ -0,0 +1,99 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTaskMetrics(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	metrics := newTaskMetrics()
+	metrics.now = func() time.Time { return now }
+
+	if got := metrics.snapshot(); got.TasksProcessed != 0 || got.SuccessRate != 0 || got.AverageResponseTime != 0 {
+		t.Errorf("snapshot() with no tasks = %+v, want zero metrics", got)
+	}
+
+	// An hour and a half of tasks: three successes and one failure
+	metrics.record(100*time.Millisecond, nil)
+	now = now.Add(30 * time.Minute)
+	metrics.record(200*time.Millisecond, nil)
+	now = now.Add(30 * time.Minute)
+	metrics.record(300*time.Millisecond, errors.New("boom"))
+	now = now.Add(30 * time.Minute)
+	metrics.record(400*time.Millisecond, nil)
+
+	got := metrics.snapshot()
+	if got.TasksProcessed != 4 || got.TasksSuccessful != 3 || got.TasksFailed != 1 {
+		t.Errorf("counts = %d/%d/%d, want 4/3/1", got.TasksProcessed, got.TasksSuccessful, got.TasksFailed)
+	}
+	if got.SuccessRate != 0.75 || got.ErrorRate != 0.25 {
+		t.Errorf("rates = %v/%v, want 0.75/0.25", got.SuccessRate, got.ErrorRate)
+	}
+	if got.AverageResponseTime != 250*time.Millisecond {
+		t.Errorf("AverageResponseTime = %v, want 250ms", got.AverageResponseTime)
+	}
+	if got.TasksPerHour != 2 {
+		t.Errorf("TasksPerHour = %v, want 2 tasks in the last hour", got.TasksPerHour)
+	}
+	if !got.LastUpdated.Equal(now) {
+		t.Errorf("LastUpdated = %v, want %v", got.LastUpdated, now)
+	}
+}
+
+func TestTaskMetrics_RollingAverage(t *testing.T) {
+	metrics := newTaskMetrics()
+	for i := 0; i < responseTimeWindow; i++ {
+		metrics.record(time.Second, nil)
+	}
+	// Slow tasks push the fast ones out of the window
+	for i := 0; i < responseTimeWindow/2; i++ {
+		metrics.record(3*time.Second, nil)
+	}
+
+	if got := metrics.snapshot().AverageResponseTime; got != 2*time.Second {
+		t.Errorf("AverageResponseTime = %v, want 2s over the last %d tasks", got, responseTimeWindow)
+	}
+}
+
+// flakyHandler fails tasks named "fail" and takes delay for the rest
+type flakyHandler struct {
+	delay time.Duration
+}
+
+func (h flakyHandler) ProcessTask(ctx context.Context, task string) (string, error) {
+	if task == "fail" {
+		return "", errors.New("task failed")
+	}
+	time.Sleep(h.delay)
+	return "ok", nil
+}
+
+func TestTaskCoordinator_GetMetrics(t *testing.T) {
+	coordinator, _ := newTestCoordinator(flakyHandler{delay: 20 * time.Millisecond})
+
+	for i, task := range []string{"ok", "fail", "ok", "ok", "fail"} {
+		coordinator.ExecuteTask(fmt.Sprintf("task-%d", i), task, "room")
+	}
+
+	metrics := coordinator.GetMetrics()
+	if metrics.AgentID != "test-agent" {
+		t.Errorf("AgentID = %q, want test-agent", metrics.AgentID)
+	}
+	if metrics.TasksProcessed != 5 || metrics.TasksSuccessful != 3 || metrics.TasksFailed != 2 {
+		t.Errorf("counts = %d/%d/%d, want 5/3/2", metrics.TasksProcessed, metrics.TasksSuccessful, metrics.TasksFailed)
+	}
+	if metrics.SuccessRate != 0.6 || metrics.ErrorRate != 0.4 {
+		t.Errorf("rates = %v/%v, want 0.6/0.4", metrics.SuccessRate, metrics.ErrorRate)
+	}
+	if metrics.TasksPerHour != 5 {
+		t.Errorf("TasksPerHour = %v, want 5", metrics.TasksPerHour)
+	}
+	// Three 20ms successes and two instant failures
+	if metrics.AverageResponseTime < 12*time.Millisecond {
+		t.Errorf("AverageResponseTime = %v, want at least 12ms", metrics.AverageResponseTime)
+	}
+}
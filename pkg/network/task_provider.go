@@ -0,0 +1,111 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/types"
+)
+
+// DefaultTaskProviderInterval is how often a TaskProvider is polled when no
+// interval is configured
+const DefaultTaskProviderInterval = 30 * time.Second
+
+// StartTaskProvider polls the agent for tasks every interval until ctx is
+// done, if the agent implements types.TaskProvider, and reports whether it
+// does. Returned tasks run like tasks from the network, with their results
+// sent to the agent's room. Polling is skipped while paused, and tasks are
+// only taken while the rate limit and the concurrent task limit allow; the
+// rest are left for the provider to offer again.
+func (t *TaskCoordinator) StartTaskProvider(ctx context.Context, interval time.Duration) bool {
+	provider, ok := t.agentHandler.(types.TaskProvider)
+	if !ok {
+		return false
+	}
+	if interval <= 0 {
+		interval = DefaultTaskProviderInterval
+	}
+
+	log.Printf("⚙️ Polling task provider every %v", interval)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				t.pollTaskProvider(ctx, provider)
+			}
+		}
+	}()
+	return true
+}
+
+// pollTaskProvider fetches the provider's available tasks and dispatches as
+// many as the limits allow
+func (t *TaskCoordinator) pollTaskProvider(ctx context.Context, provider types.TaskProvider) {
+	if t.IsPaused() {
+		return
+	}
+
+	tasks, err := provider.GetAvailableTasks(ctx)
+	if err != nil {
+		log.Printf("⚠️ Failed to get tasks from task provider: %v", err)
+		return
+	}
+
+	for i, task := range tasks {
+		if task.ID == "" {
+			task.ID = fmt.Sprintf("provider-%d-%d", time.Now().UnixNano(), i)
+		}
+		if t.isProvidedTaskPending(task.ID) {
+			continue // Still queued or running from an earlier poll
+		}
+		if !t.hasFreeSlot() {
+			log.Printf("⏳ All task slots busy, deferring %d provider tasks", len(tasks)-i)
+			return
+		}
+		if code := t.checkRateLimit(task.Sender); code != "" {
+			log.Printf("⏳ Rate limit reached (%s), deferring %d provider tasks", code, len(tasks)-i)
+			return
+		}
+
+		if task.Type == "" {
+			task.Type = types.MessageTypeTask
+		}
+		if task.CreatedAt.IsZero() {
+			task.CreatedAt = time.Now()
+		}
+
+		t.providedMu.Lock()
+		t.provided[task.ID] = true
+		t.providedMu.Unlock()
+
+		log.Printf("📥 Dispatching provider task %s", task.ID)
+		if !t.submitTask(task, t.protocolHandler.room) {
+			// Rejected, so the next poll may offer it again
+			t.providedMu.Lock()
+			delete(t.provided, task.ID)
+			t.providedMu.Unlock()
+		}
+	}
+}
+
+// isProvidedTaskPending reports whether a provider task with this ID was
+// dispatched and has not finished yet
+func (t *TaskCoordinator) isProvidedTaskPending(taskID string) bool {
+	t.providedMu.Lock()
+	defer t.providedMu.Unlock()
+	return t.provided[taskID]
+}
+
+// hasFreeSlot reports whether a task could start without waiting in the queue
+func (t *TaskCoordinator) hasFreeSlot() bool {
+	t.queueMu.Lock()
+	defer t.queueMu.Unlock()
+	return t.maxConcurrent == 0 || t.inFlight < t.maxConcurrent
+}
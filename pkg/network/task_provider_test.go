@@ -0,0 +1,143 @@
+package network
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/types"
+)
+
+// providerHandler offers a fixed set of tasks and records those it processes
+type providerHandler struct {
+	mu        sync.Mutex
+	tasks     []types.Task
+	processed []string
+	release   chan struct{} // nil = tasks finish immediately
+}
+
+func (h *providerHandler) GetAvailableTasks(ctx context.Context) ([]types.Task, error) {
+	return h.tasks, nil
+}
+
+func (h *providerHandler) ProcessTask(ctx context.Context, task string) (string, error) {
+	if h.release != nil {
+		<-h.release
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.processed = append(h.processed, task)
+	return "done", nil
+}
+
+func (h *providerHandler) processedCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.processed)
+}
+
+func providerTasks(contents ...string) []types.Task {
+	tasks := make([]types.Task, len(contents))
+	for i, content := range contents {
+		tasks[i] = types.Task{ID: "scheduled-" + content, Content: content}
+	}
+	return tasks
+}
+
+func TestTaskCoordinator_StartTaskProvider(t *testing.T) {
+	handler := &providerHandler{tasks: providerTasks("daily-report")}
+	coordinator, sent := newTestCoordinator(handler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if !coordinator.StartTaskProvider(ctx, 10*time.Millisecond) {
+		t.Fatal("StartTaskProvider() = false for an agent implementing TaskProvider")
+	}
+	waitFor(t, func() bool { return handler.processedCount() >= 1 })
+
+	if got := handler.processed[0]; got != "daily-report" {
+		t.Errorf("ProcessTask() got %q, want daily-report", got)
+	}
+	msg := <-sent
+	if msg.TaskID != "scheduled-daily-report" || msg.Content != "done" || msg.Room != "room" {
+		t.Errorf("result = task %q %q in room %q, want the result in the agent's room", msg.TaskID, msg.Content, msg.Room)
+	}
+
+	other, _ := newTestCoordinator(failingHandler{})
+	if other.StartTaskProvider(ctx, time.Millisecond) {
+		t.Error("StartTaskProvider() = true for an agent without TaskProvider")
+	}
+}
+
+func TestTaskCoordinator_TaskProviderRespectsConcurrencyLimit(t *testing.T) {
+	handler := &providerHandler{tasks: providerTasks("a", "b", "c"), release: make(chan struct{})}
+	coordinator, _ := newTestCoordinator(handler)
+	coordinator.SetMaxConcurrentTasks(2)
+
+	coordinator.pollTaskProvider(context.Background(), handler)
+	waitFor(t, func() bool { return coordinator.GetActiveTaskCount() == 2 })
+
+	// Running tasks are not dispatched twice and no slot is free for "c"
+	coordinator.pollTaskProvider(context.Background(), handler)
+	if got := coordinator.GetActiveTaskCount() + coordinator.GetQueuedTaskCount(); got != 2 {
+		t.Errorf("%d tasks admitted while both slots were busy, want 2", got)
+	}
+
+	close(handler.release)
+	waitFor(t, func() bool { return coordinator.hasFreeSlot() })
+
+	// Finished tasks are no longer offered and "c" is taken
+	handler.tasks = providerTasks("c")
+	coordinator.pollTaskProvider(context.Background(), handler)
+	waitFor(t, func() bool { return handler.processedCount() == 3 })
+}
+
+func TestTaskCoordinator_TaskProviderRespectsRateLimit(t *testing.T) {
+	handler := &providerHandler{tasks: providerTasks("a", "b", "c")}
+	coordinator, _ := newTestCoordinator(handler)
+	coordinator.SetRateLimit(2)
+
+	coordinator.pollTaskProvider(context.Background(), handler)
+	waitFor(t, func() bool { return handler.processedCount() == 2 })
+
+	time.Sleep(50 * time.Millisecond)
+	if got := handler.processedCount(); got != 2 {
+		t.Errorf("processed %d tasks with a rate limit of 2, want 2", got)
+	}
+}
+
+func TestTaskCoordinator_TaskProviderPaused(t *testing.T) {
+	handler := &providerHandler{tasks: providerTasks("a")}
+	coordinator, _ := newTestCoordinator(handler)
+	coordinator.Pause()
+
+	coordinator.pollTaskProvider(context.Background(), handler)
+	time.Sleep(50 * time.Millisecond)
+	if got := handler.processedCount(); got != 0 {
+		t.Errorf("processed %d tasks while paused, want 0", got)
+	}
+}
+
+func TestTaskCoordinator_TaskProviderRejectedTaskIsOfferedAgain(t *testing.T) {
+	handler := &providerHandler{tasks: providerTasks("a")}
+	coordinator, sent := newTestCoordinator(handler)
+
+	// A task held from an earlier block policy makes the queue reject new ones
+	coordinator.queueMu.Lock()
+	coordinator.held = 1
+	coordinator.queueMu.Unlock()
+	coordinator.pollTaskProvider(context.Background(), handler)
+	if msg := <-sent; msg.TaskID != "scheduled-a" || msg.Data == nil {
+		t.Fatalf("message = %+v, want the agent busy rejection", msg)
+	}
+	if coordinator.isProvidedTaskPending("scheduled-a") {
+		t.Error("rejected provider task is still marked pending")
+	}
+
+	coordinator.queueMu.Lock()
+	coordinator.held = 0
+	coordinator.queueMu.Unlock()
+	coordinator.pollTaskProvider(context.Background(), handler)
+	waitFor(t, func() bool { return handler.processedCount() == 1 })
+}
@@ -0,0 +1,117 @@
+package network
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// updateCoalescer collapses rapid task updates so that at most one update is
+// sent per window. The first update in a window goes out immediately; later
+// ones replace each other and only the latest is sent when the window ends.
+type updateCoalescer struct {
+	window time.Duration
+	send   func(content string) error
+
+	// sendMu serializes delivery so a flush cannot overtake a timer-fired send
+	sendMu sync.Mutex
+
+	mu         sync.Mutex
+	pending    string
+	hasPending bool
+	lastSent   time.Time
+	timer      *time.Timer
+	dropped    int64
+}
+
+// newUpdateCoalescer creates a coalescer that delivers updates through send
+func newUpdateCoalescer(window time.Duration, send func(content string) error) *updateCoalescer {
+	return &updateCoalescer{
+		window: window,
+		send:   send,
+	}
+}
+
+// Submit queues an update, sending it right away if the window allows
+func (c *updateCoalescer) Submit(content string) error {
+	c.mu.Lock()
+
+	if c.timer == nil && time.Since(c.lastSent) >= c.window {
+		c.lastSent = time.Now()
+		c.mu.Unlock()
+
+		c.sendMu.Lock()
+		defer c.sendMu.Unlock()
+		return c.send(content)
+	}
+
+	if c.hasPending {
+		c.dropped++
+	}
+	c.pending = content
+	c.hasPending = true
+
+	if c.timer == nil {
+		wait := c.window - time.Since(c.lastSent)
+		c.timer = time.AfterFunc(wait, c.onTimer)
+	}
+
+	c.mu.Unlock()
+	return nil
+}
+
+// Flush sends the pending update, if any, without waiting for the window
+func (c *updateCoalescer) Flush() error {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	c.mu.Lock()
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	content, ok := c.takePendingLocked()
+	c.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	return c.send(content)
+}
+
+// Dropped returns how many updates were superseded before being sent
+func (c *updateCoalescer) Dropped() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dropped
+}
+
+// onTimer delivers the latest pending update at the end of a window
+func (c *updateCoalescer) onTimer() {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	c.mu.Lock()
+	c.timer = nil
+	content, ok := c.takePendingLocked()
+	c.mu.Unlock()
+
+	if !ok {
+		return
+	}
+	if err := c.send(content); err != nil {
+		log.Printf("⚠️ Failed to send coalesced task update: %v", err)
+	}
+}
+
+// takePendingLocked returns and clears the pending update. Caller must hold c.mu.
+func (c *updateCoalescer) takePendingLocked() (string, bool) {
+	if !c.hasPending {
+		return "", false
+	}
+	content := c.pending
+	c.pending = ""
+	c.hasPending = false
+	c.lastSent = time.Now()
+	return content, true
+}
@@ -0,0 +1,94 @@
+package network
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingSender struct {
+	mu   sync.Mutex
+	sent []string
+}
+
+func (r *recordingSender) send(content string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, content)
+	return nil
+}
+
+func (r *recordingSender) messages() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.sent...)
+}
+
+func TestUpdateCoalescer_CollapsesRapidUpdates(t *testing.T) {
+	rec := &recordingSender{}
+	c := newUpdateCoalescer(100*time.Millisecond, rec.send)
+
+	for i := 0; i < 10; i++ {
+		if err := c.Submit(fmt.Sprintf("update %d", i)); err != nil {
+			t.Fatalf("Submit failed: %v", err)
+		}
+	}
+
+	if got := rec.messages(); len(got) != 1 || got[0] != "update 0" {
+		t.Fatalf("expected only the first update sent immediately, got %v", got)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	got := rec.messages()
+	if len(got) != 2 {
+		t.Fatalf("expected rapid updates to collapse to one trailing send, got %v", got)
+	}
+	if got[1] != "update 9" {
+		t.Errorf("expected trailing send to carry the latest update, got %q", got[1])
+	}
+	if dropped := c.Dropped(); dropped != 8 {
+		t.Errorf("expected 8 dropped updates, got %d", dropped)
+	}
+}
+
+func TestUpdateCoalescer_FlushSendsPending(t *testing.T) {
+	rec := &recordingSender{}
+	c := newUpdateCoalescer(time.Hour, rec.send)
+
+	c.Submit("first")
+	c.Submit("second")
+	c.Submit("third")
+
+	if err := c.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	got := rec.messages()
+	if len(got) != 2 || got[1] != "third" {
+		t.Fatalf("expected flush to send the latest pending update, got %v", got)
+	}
+
+	// Nothing left to send
+	if err := c.Flush(); err != nil {
+		t.Fatalf("second Flush failed: %v", err)
+	}
+	if len(rec.messages()) != 2 {
+		t.Errorf("expected no extra send on empty flush")
+	}
+}
+
+func TestUpdateCoalescer_SpacedUpdatesAllSent(t *testing.T) {
+	rec := &recordingSender{}
+	c := newUpdateCoalescer(20*time.Millisecond, rec.send)
+
+	for i := 0; i < 3; i++ {
+		c.Submit(fmt.Sprintf("update %d", i))
+		time.Sleep(40 * time.Millisecond)
+	}
+
+	if got := rec.messages(); len(got) != 3 {
+		t.Errorf("expected every spaced update to be sent, got %v", got)
+	}
+}
@@ -0,0 +1,142 @@
+package network
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/types"
+)
+
+// DefaultWalletTxTimeout is how long RequestWalletTx waits for the user to
+// answer a wallet transaction request unless SetWalletTxTimeout is called
+const DefaultWalletTxTimeout = 2 * time.Minute
+
+// pendingWalletTx is a wallet transaction request awaiting the user's answer
+type pendingWalletTx struct {
+	taskID    string
+	requester string // lowercased wallet asked to sign, "" when the task's sender is unknown
+	created   time.Time
+	result    chan *types.TxResultData // buffered, receives at most one result
+}
+
+// answerableBy reports whether from may answer the request
+func (p *pendingWalletTx) answerableBy(from string) bool {
+	return p.requester == "" || p.requester == from
+}
+
+// trackWalletTx registers a wallet transaction request that requester is
+// asked to sign for taskID and returns its request ID and the channel its
+// result is delivered on. Request IDs are random so other peers cannot
+// guess them.
+func (p *ProtocolHandler) trackWalletTx(taskID, requester string) (string, chan *types.TxResultData, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", nil, fmt.Errorf("failed to generate request ID: %w", err)
+	}
+	requestID := "tx-" + hex.EncodeToString(id)
+	pending := &pendingWalletTx{
+		taskID:    taskID,
+		requester: requester,
+		created:   time.Now(),
+		result:    make(chan *types.TxResultData, 1),
+	}
+
+	p.pendingTxMu.Lock()
+	p.pendingTx[requestID] = pending
+	p.pendingTxMu.Unlock()
+	return requestID, pending.result, nil
+}
+
+// untrackWalletTx stops waiting for the answer to a request
+func (p *ProtocolHandler) untrackWalletTx(requestID string) {
+	p.pendingTxMu.Lock()
+	delete(p.pendingTx, requestID)
+	p.pendingTxMu.Unlock()
+}
+
+// HandleTxResult delivers the user's answer to a wallet transaction request
+// to the task waiting for it. Results are matched by request ID or, from
+// clients that do not echo it, to the oldest pending request of their task.
+// Only the user asked to sign can answer; results from anyone else are
+// ignored.
+func (p *ProtocolHandler) HandleTxResult(msg *types.Message) error {
+	var result types.TxResultData
+	if err := json.Unmarshal(msg.Data, &result); err != nil {
+		return fmt.Errorf("failed to unmarshal tx result: %w", err)
+	}
+	if result.TaskID == "" {
+		result.TaskID = msg.TaskID
+	}
+	from := p.taskSender(msg)
+
+	p.pendingTxMu.Lock()
+	requestID := result.RequestID
+	if pending, ok := p.pendingTx[requestID]; !ok || !pending.answerableBy(from) {
+		requestID = ""
+		if result.RequestID == "" {
+			var oldest time.Time
+			for id, pending := range p.pendingTx {
+				if pending.taskID == result.TaskID && pending.answerableBy(from) && (requestID == "" || pending.created.Before(oldest)) {
+					requestID, oldest = id, pending.created
+				}
+			}
+		}
+	}
+	pending, ok := p.pendingTx[requestID]
+	delete(p.pendingTx, requestID)
+	p.pendingTxMu.Unlock()
+
+	if !ok {
+		log.Printf("⚠️ No pending wallet transaction from %s for tx result of task %s (request %q)", from, result.TaskID, result.RequestID)
+		return nil
+	}
+
+	log.Printf("💳 Wallet transaction %s for task %s: %s", requestID, pending.taskID, result.Status)
+	result.RequestID = requestID
+	pending.result <- &result
+	return nil
+}
+
+// RequestWalletTx asks the user to sign a wallet transaction and waits for
+// their answer. The task's timeout is paused while it waits. It implements
+// types.WalletTxRequester.
+func (s *TaskMessageSender) RequestWalletTx(ctx context.Context, tx types.TxRequest, description string, optional bool) (*types.TxResultData, error) {
+	requestID, results, err := s.protocolHandler.trackWalletTx(s.taskID, s.requester)
+	if err != nil {
+		return nil, err
+	}
+	defer s.protocolHandler.untrackWalletTx(requestID)
+
+	if err := s.triggerWalletTx(tx, description, optional, requestID); err != nil {
+		return nil, err
+	}
+
+	timeout := s.walletTxTimeout
+	if timeout <= 0 {
+		timeout = DefaultWalletTxTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	// The user's time to answer does not count against the task timeout
+	if s.taskContext != nil {
+		defer s.taskContext.holdDeadline(timeout)()
+	}
+
+	select {
+	case result := <-results:
+		return result, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+		if optional {
+			return &types.TxResultData{TaskID: s.taskID, RequestID: requestID, Status: types.TxStatusSkipped}, nil
+		}
+		return nil, fmt.Errorf("%w: no answer to request %s after %v", types.ErrWalletTxTimeout, requestID, timeout)
+	}
+}
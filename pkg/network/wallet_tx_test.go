@@ -0,0 +1,258 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/types"
+)
+
+// walletTxOutcome is what RequestWalletTx returned to the agent
+type walletTxOutcome struct {
+	result *types.TxResultData
+	err    error
+}
+
+// walletTxHandler asks the user to sign a transaction for each task and
+// reports what RequestWalletTx returned
+type walletTxHandler struct {
+	optional bool
+	outcomes chan walletTxOutcome
+}
+
+func newWalletTxHandler(optional bool) *walletTxHandler {
+	return &walletTxHandler{optional: optional, outcomes: make(chan walletTxOutcome, 4)}
+}
+
+func (h *walletTxHandler) ProcessTask(ctx context.Context, task string) (string, error) {
+	return "", errors.New("ProcessTask must not be called when ProcessTaskWithStreaming is implemented")
+}
+
+func (h *walletTxHandler) ProcessTaskWithStreaming(ctx context.Context, task, room string, sender types.MessageSender) error {
+	requester, ok := sender.(types.WalletTxRequester)
+	if !ok {
+		return errors.New("sender cannot wait for wallet transactions")
+	}
+	result, err := requester.RequestWalletTx(ctx, types.TxRequest{To: "0xcontract", ChainId: 8453}, "Approve "+task, h.optional)
+	h.outcomes <- walletTxOutcome{result, err}
+	if err != nil {
+		return err
+	}
+	return sender.SendMessage("tx " + result.Status)
+}
+
+// nextWalletTx returns the next wallet transaction request sent to the user
+func nextWalletTx(t *testing.T, sent chan *types.Message) types.TriggerWalletTxData {
+	t.Helper()
+	select {
+	case msg := <-sent:
+		if msg.Type != types.MessageTypeTriggerWalletTx {
+			t.Fatalf("message type = %s, want %s", msg.Type, types.MessageTypeTriggerWalletTx)
+		}
+		var data types.TriggerWalletTxData
+		if err := json.Unmarshal(msg.Data, &data); err != nil {
+			t.Fatal(err)
+		}
+		return data
+	case <-time.After(time.Second):
+		t.Fatal("expected a wallet transaction request")
+		return types.TriggerWalletTxData{}
+	}
+}
+
+// txResultMessage is the user's answer to a wallet transaction request
+func txResultMessage(result types.TxResultData) *types.Message {
+	return txResultFrom("0xuser", result)
+}
+
+// txResultFrom is an answer to a wallet transaction request sent by from
+func txResultFrom(from string, result types.TxResultData) *types.Message {
+	data, _ := json.Marshal(result)
+	return &types.Message{Type: types.MessageTypeTxResult, From: from, Data: data, Room: "room"}
+}
+
+func nextOutcome(t *testing.T, h *walletTxHandler) walletTxOutcome {
+	t.Helper()
+	select {
+	case outcome := <-h.outcomes:
+		return outcome
+	case <-time.After(time.Second):
+		t.Fatal("expected RequestWalletTx to return")
+		return walletTxOutcome{}
+	}
+}
+
+func TestRequestWalletTx_DeliversCorrelatedResult(t *testing.T) {
+	handler := newWalletTxHandler(false)
+	coordinator, sent := newTestCoordinator(handler)
+
+	go coordinator.ExecuteTask("task-a", "swap", "room")
+	first := nextWalletTx(t, sent)
+	go coordinator.ExecuteTask("task-b", "stake", "room")
+	second := nextWalletTx(t, sent)
+
+	if first.TaskID != "task-a" || second.TaskID != "task-b" || first.RequestID == "" || first.RequestID == second.RequestID {
+		t.Fatalf("requests = %+v and %+v, want distinct request IDs per task", first, second)
+	}
+	if first.Tx.To != "0xcontract" || first.Description != "Approve swap" || first.Optional {
+		t.Errorf("request = %+v, want the agent's transaction", first)
+	}
+
+	// The user answers the second request first
+	protocol := coordinator.protocolHandler
+	protocol.HandleTxResult(txResultMessage(types.TxResultData{TaskID: "task-b", RequestID: second.RequestID, TxHash: "0xbbb", Status: "confirmed"}))
+	if got := nextOutcome(t, handler); got.err != nil || got.result.TxHash != "0xbbb" || got.result.RequestID != second.RequestID {
+		t.Fatalf("second request got %+v, %v, want tx 0xbbb", got.result, got.err)
+	}
+
+	// Clients that do not echo the request ID are matched by task
+	protocol.HandleTxResult(txResultMessage(types.TxResultData{TaskID: "task-a", Status: "rejected", Error: "user rejected"}))
+	if got := nextOutcome(t, handler); got.err != nil || got.result.Status != "rejected" || got.result.RequestID != first.RequestID {
+		t.Fatalf("first request got %+v, %v, want the rejection", got.result, got.err)
+	}
+
+	for _, want := range []string{"tx confirmed", "tx rejected"} {
+		select {
+		case msg := <-sent:
+			if msg.Content != want {
+				t.Errorf("content = %q, want %q", msg.Content, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected the agent to send %q", want)
+		}
+	}
+}
+
+func TestRequestWalletTx_IgnoresUnknownResults(t *testing.T) {
+	handler := newWalletTxHandler(false)
+	coordinator, sent := newTestCoordinator(handler)
+	coordinator.SetWalletTxTimeout(100 * time.Millisecond)
+
+	go coordinator.ExecuteTask("task-a", "swap", "room")
+	request := nextWalletTx(t, sent)
+
+	protocol := coordinator.protocolHandler
+	protocol.HandleTxResult(txResultMessage(types.TxResultData{TaskID: "task-a", RequestID: "tx-unknown", Status: "confirmed"}))
+	protocol.HandleTxResult(txResultMessage(types.TxResultData{TaskID: "task-other", Status: "confirmed"}))
+
+	if got := nextOutcome(t, handler); !errors.Is(got.err, types.ErrWalletTxTimeout) {
+		t.Errorf("RequestWalletTx() = %+v, %v, want ErrWalletTxTimeout for request %s", got.result, got.err, request.RequestID)
+	}
+}
+
+func TestRequestWalletTx_OnlyRequesterCanAnswer(t *testing.T) {
+	handler := newWalletTxHandler(false)
+	coordinator, sent := newTestCoordinator(handler)
+
+	// A task relayed by the coordinator on behalf of 0xAlice
+	coordinator.HandleIncomingTask(&types.Message{
+		Type:    types.MessageTypeTask,
+		From:    "coordinator",
+		Content: "swap",
+		Room:    "room",
+		Data:    json.RawMessage(`{"task_id":"task-a","from":"0xAlice"}`),
+	})
+	request := nextWalletTx(t, sent)
+
+	protocol := coordinator.protocolHandler
+	forged := types.TxResultData{TaskID: "task-a", RequestID: request.RequestID, TxHash: "0xforged", Status: "confirmed"}
+	protocol.HandleTxResult(txResultFrom("0xmallory", forged))
+	forged.RequestID = ""
+	protocol.HandleTxResult(txResultFrom("0xmallory", forged))
+
+	select {
+	case got := <-handler.outcomes:
+		t.Fatalf("RequestWalletTx() = %+v, %v, want results from other users ignored", got.result, got.err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	protocol.HandleTxResult(txResultFrom("0xALICE", types.TxResultData{TaskID: "task-a", RequestID: request.RequestID, TxHash: "0xaaa", Status: "confirmed"}))
+	if got := nextOutcome(t, handler); got.err != nil || got.result.TxHash != "0xaaa" {
+		t.Errorf("RequestWalletTx() = %+v, %v, want the requester's tx 0xaaa", got.result, got.err)
+	}
+}
+
+func TestTrackWalletTx_RandomRequestIDs(t *testing.T) {
+	coordinator, _ := newTestCoordinator(newWalletTxHandler(false))
+	protocol := coordinator.protocolHandler
+
+	first, _, err := protocol.trackWalletTx("task-a", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _, err := protocol.trackWalletTx("task-a", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second || len(first) != len("tx-")+32 || len(second) != len(first) {
+		t.Errorf("request IDs = %q and %q, want distinct random IDs", first, second)
+	}
+}
+
+func TestRequestWalletTx_Timeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		optional bool
+	}{
+		{"required", false},
+		{"optional", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newWalletTxHandler(tt.optional)
+			coordinator, sent := newTestCoordinator(handler)
+			coordinator.SetWalletTxTimeout(20 * time.Millisecond)
+
+			go coordinator.ExecuteTask("task-a", "swap", "room")
+			if request := nextWalletTx(t, sent); request.Optional != tt.optional {
+				t.Errorf("request optional = %v, want %v", request.Optional, tt.optional)
+			}
+
+			got := nextOutcome(t, handler)
+			if tt.optional {
+				if got.err != nil || got.result.Status != types.TxStatusSkipped || got.result.TaskID != "task-a" {
+					t.Errorf("RequestWalletTx() = %+v, %v, want a skipped result", got.result, got.err)
+				}
+			} else if !errors.Is(got.err, types.ErrWalletTxTimeout) {
+				t.Errorf("RequestWalletTx() error = %v, want ErrWalletTxTimeout", got.err)
+			}
+
+			protocol := coordinator.protocolHandler
+			protocol.pendingTxMu.Lock()
+			defer protocol.pendingTxMu.Unlock()
+			if len(protocol.pendingTx) != 0 {
+				t.Errorf("%d wallet transactions still pending after the timeout", len(protocol.pendingTx))
+			}
+		})
+	}
+}
+
+func TestRequestWalletTx_PausesTaskTimeout(t *testing.T) {
+	handler := newWalletTxHandler(false)
+	coordinator, sent := newTestCoordinator(handler)
+	coordinator.taskTimeout = 50 * time.Millisecond
+	coordinator.SetWalletTxTimeout(time.Second)
+
+	go coordinator.ExecuteTask("task-a", "swap", "room")
+	request := nextWalletTx(t, sent)
+
+	// The user answers well after the task timeout
+	time.Sleep(150 * time.Millisecond)
+	coordinator.protocolHandler.HandleTxResult(txResultMessage(types.TxResultData{TaskID: "task-a", RequestID: request.RequestID, TxHash: "0xaaa", Status: "confirmed"}))
+
+	if got := nextOutcome(t, handler); got.err != nil || got.result.TxHash != "0xaaa" {
+		t.Fatalf("RequestWalletTx() = %+v, %v, want tx 0xaaa", got.result, got.err)
+	}
+	select {
+	case msg := <-sent:
+		if msg.Type == types.MessageTypeAgentError || msg.Content != "tx confirmed" {
+			t.Errorf("message = %s %q, want the task's result", msg.Type, msg.Content)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the agent to send its result")
+	}
+}
@@ -13,7 +13,6 @@ import (
 	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/types"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
@@ -36,18 +35,11 @@ func NewBusinessCardManager(rpcURL, contractAddress, privateKey string) (*Busine
 	}
 
 	// Parse private key
-	if strings.HasPrefix(privateKey, "0x") {
-		privateKey = privateKey[2:]
-	}
-
-	key, err := crypto.HexToECDSA(privateKey)
+	key, fromAddress, err := auth.ParsePrivateKey(privateKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse private key: %w", err)
+		return nil, err
 	}
 
-	// Get address from private key
-	fromAddress := crypto.PubkeyToAddress(key.PublicKey)
-
 	// Parse contract address
 	contractAddr := common.HexToAddress(contractAddress)
 
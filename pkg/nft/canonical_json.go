@@ -0,0 +1,156 @@
+package nft
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// CanonicalJSON re-encodes a JSON document in the canonical form the backend
+// hashes, following RFC 8785 (JSON Canonicalization Scheme):
+//
+//   - no insignificant whitespace
+//   - object keys sorted by their UTF-16 code units
+//   - array order kept as is
+//   - numbers written as ECMAScript would print the float64 they parse to,
+//     so 1, 1.0 and 1e0 all become 1
+//   - strings escaped minimally: only '"', '\' and control characters, with
+//     <, > and & and non-ASCII characters left as they are
+//
+// Documents that differ only in formatting therefore hash the same.
+func CanonicalJSON(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid JSON: trailing data after the top-level value")
+	}
+
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeCanonical writes value, as decoded with UseNumber, in canonical form
+func writeCanonical(buf *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case json.Number:
+		number, err := canonicalNumber(v)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(number)
+	case string:
+		writeCanonicalString(buf, v)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool { return lessUTF16(keys[i], keys[j]) })
+
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, key)
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, v[key]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("unexpected JSON value of type %T", value)
+	}
+	return nil
+}
+
+// canonicalNumber formats a JSON number like ECMAScript's Number.prototype.toString:
+// the shortest digits that round-trip the float64, in fixed notation for
+// magnitudes from 1e-6 up to 1e21 and exponential notation otherwise
+func canonicalNumber(n json.Number) (string, error) {
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return "", fmt.Errorf("number %s cannot be represented as a float64", n)
+	}
+	if f == 0 {
+		return "0", nil // also for -0
+	}
+
+	if abs := math.Abs(f); abs >= 1e21 || abs < 1e-6 {
+		mantissa, exponent, _ := strings.Cut(strconv.FormatFloat(f, 'e', -1, 64), "e")
+		return mantissa + "e" + exponent[:1] + strings.TrimLeft(exponent[1:], "0"), nil
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64), nil
+}
+
+// writeCanonicalString writes s as a JSON string, escaping only what JSON
+// requires
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// lessUTF16 orders strings by their UTF-16 code units, as RFC 8785 sorts keys
+func lessUTF16(a, b string) bool {
+	ua, ub := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
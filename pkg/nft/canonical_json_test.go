@@ -0,0 +1,92 @@
+package nft
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"whitespace", "{ \"a\" : [ 1 , 2 ] ,\n\t\"b\" : null }", `{"a":[1,2],"b":null}`},
+		{"sorted keys", `{"b":1,"a":{"d":true,"c":false}}`, `{"a":{"c":false,"d":true},"b":1}`},
+		{"array order kept", `["b","a",3,1]`, `["b","a",3,1]`},
+		{"UTF-16 key order", `{"😀":1,"€":2,"\r":3,"1":4,"ö":5}`, `{"\r":3,"1":4,"ö":5,"€":2,"😀":1}`},
+		{"numbers", `[1.0,1e0,4.50,-0,0.0,2e-3,1E2,100]`, `[1,1,4.5,0,0,0.002,100,100]`},
+		{"large and small numbers", `[1e21,1e20,0.000001,1e-7,-1.5e-10,333333333.33333329]`, `[1e+21,100000000000000000000,0.000001,1e-7,-1.5e-10,333333333.3333333]`},
+		{"minimal escaping", `"<a href=\"x\">&amp;</a>é\u0001\n\/"`, `"<a href=\"x\">&amp;</a>é\u0001\n/"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CanonicalJSON([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("CanonicalJSON() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("CanonicalJSON() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalJSON_Invalid(t *testing.T) {
+	for _, input := range []string{`{"a":`, `{"a":1} {"b":2}`, `[1e400]`} {
+		if _, err := CanonicalJSON([]byte(input)); err == nil {
+			t.Errorf("CanonicalJSON(%s) succeeded, want an error", input)
+		}
+	}
+}
+
+func TestParsePayloadAndHash_IgnoresFormatting(t *testing.T) {
+	documents := []string{
+		`{"name":"Hash Agent","agent_id":"hash-agent","description":"Hashes metadata documents","agent_type":"command",` +
+			`"capabilities":[{"name":"hash","description":"Hashes things"}],"categories":["Utilities"],` +
+			`"metadata_version":"2.3.0","pricing":{"amount":1.50,"decimals":6}}`,
+		`{
+			"pricing": {"decimals": 6e0, "amount": 1.5},
+			"metadata_version": "2.3.0",
+			"categories": ["Utilities"],
+			"capabilities": [{"description": "Hashes things", "name": "hash"}],
+			"agent_type": "command",
+			"description": "Hashes metadata documents",
+			"agent_id": "hash-agent",
+			"name": "Hash Agent"
+		}`,
+	}
+
+	var hashes []string
+	for _, doc := range documents {
+		_, canonical, hash, err := (&NFTMinter{}).parsePayloadAndHash([]byte(doc))
+		if err != nil {
+			t.Fatalf("parsePayloadAndHash() error = %v", err)
+		}
+		if want := `"pricing":{"amount":1.5,"decimals":6}`; !strings.Contains(string(canonical), want) {
+			t.Errorf("canonical JSON = %s, want it to contain %s", canonical, want)
+		}
+		hashes = append(hashes, hash)
+	}
+	if hashes[0] != hashes[1] {
+		t.Errorf("hashes differ for the same metadata: %s and %s", hashes[0], hashes[1])
+	}
+
+	// Array order is significant
+	reordered := `{"name":"Hash Agent","agent_id":"hash-agent","description":"Hashes metadata documents","agent_type":"command",` +
+		`"capabilities":[{"name":"hash","description":"Hashes things"}],"categories":["Utilities","AI"],"metadata_version":"2.3.0"}`
+	original := `{"name":"Hash Agent","agent_id":"hash-agent","description":"Hashes metadata documents","agent_type":"command",` +
+		`"capabilities":[{"name":"hash","description":"Hashes things"}],"categories":["AI","Utilities"],"metadata_version":"2.3.0"}`
+	_, _, h1, err := (&NFTMinter{}).parsePayloadAndHash([]byte(reordered))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, h2, err := (&NFTMinter{}).parsePayloadAndHash([]byte(original))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h1 == h2 {
+		t.Error("reordering an array did not change the hash")
+	}
+}
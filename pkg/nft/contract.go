@@ -127,7 +127,8 @@ func ParseABI() (abi.ABI, error) {
 	return abi.JSON(strings.NewReader(abiJSON))
 }
 
-// DefaultMintPrice returns the default mint price (2 PEAQ)
+// DefaultMintPrice returns the fallback mint price (2 PEAQ), used when the
+// contract's mintPrice() cannot be read
 func DefaultMintPrice() *big.Int {
 	mintPrice, _ := new(big.Int).SetString("2000000000000000000", 10)
 	return mintPrice
@@ -15,11 +15,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/TeneoProtocolAI/teneo-agent-sdk/pkg/auth"
 	"github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
@@ -137,23 +136,39 @@ type NFTMinter struct {
 	privateKey      *ecdsa.PrivateKey
 	address         common.Address
 	httpClient      *http.Client
+	challengeScheme auth.ChallengeScheme
+}
+
+// NFTMinterOption configures an NFTMinter
+type NFTMinterOption func(*NFTMinter)
+
+// WithHTTPClient makes the minter send backend requests through httpClient.
+// A nil client keeps the default.
+func WithHTTPClient(httpClient *http.Client) NFTMinterOption {
+	return func(m *NFTMinter) {
+		if httpClient != nil {
+			m.httpClient = httpClient
+		}
+	}
+}
+
+// WithEIP712Signing signs SDK auth challenges as EIP-712 typed data instead
+// of personal_sign when enabled
+func WithEIP712Signing(enabled bool) NFTMinterOption {
+	return func(m *NFTMinter) {
+		if enabled {
+			m.challengeScheme = auth.ChallengeSchemeEIP712
+		}
+	}
 }
 
 // NewNFTMinter creates a new NFT minter instance
-func NewNFTMinter(backendURL, rpcEndpoint, privateKeyHex string) (*NFTMinter, error) {
+func NewNFTMinter(backendURL, rpcEndpoint, privateKeyHex string, opts ...NFTMinterOption) (*NFTMinter, error) {
 	// Parse private key
-	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(privateKeyHex, "0x"))
+	privateKey, address, err := auth.ParsePrivateKey(privateKeyHex)
 	if err != nil {
-		return nil, fmt.Errorf("invalid private key: %w", err)
-	}
-
-	// Get address from private key
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		return nil, fmt.Errorf("error casting public key to ECDSA")
+		return nil, err
 	}
-	address := crypto.PubkeyToAddress(*publicKeyECDSA)
 
 	// Create HTTP client with timeout
 	httpClient := &http.Client{
@@ -169,13 +184,18 @@ func NewNFTMinter(backendURL, rpcEndpoint, privateKeyHex string) (*NFTMinter, er
 		}
 	}
 
-	return &NFTMinter{
-		client:     ethClient,
-		backendURL: backendURL,
-		privateKey: privateKey,
-		address:    address,
-		httpClient: httpClient,
-	}, nil
+	minter := &NFTMinter{
+		client:          ethClient,
+		backendURL:      backendURL,
+		privateKey:      privateKey,
+		address:         address,
+		httpClient:      httpClient,
+		challengeScheme: auth.ChallengeSchemePersonalSign,
+	}
+	for _, opt := range opts {
+		opt(minter)
+	}
+	return minter, nil
 }
 
 // MintAgent mints a new agent NFT
@@ -369,11 +389,7 @@ func (m *NFTMinter) parsePayloadAndHash(rawJSON []byte) (*sdkAgentPayload, []byt
 		config.MetadataVersion = "2.3.0"
 	}
 
-	var canonical interface{}
-	if err := json.Unmarshal(rawJSON, &canonical); err != nil {
-		return nil, nil, "", fmt.Errorf("failed to parse canonical metadata json: %w", err)
-	}
-	canonicalJSON, err := json.Marshal(canonical)
+	canonicalJSON, err := CanonicalJSON(rawJSON)
 	if err != nil {
 		return nil, nil, "", fmt.Errorf("failed to canonicalize metadata json: %w", err)
 	}
@@ -398,6 +414,9 @@ func (m *NFTMinter) syncAgentState(agentID, configHash string) (*sdkSyncResponse
 		"challenge":   challenge,
 		"signature":   signature,
 	}
+	if m.challengeScheme == auth.ChallengeSchemeEIP712 {
+		req["signature_type"] = string(m.challengeScheme)
+	}
 	var resp sdkSyncResponse
 	if err := m.postJSON("/api/sdk/agent/sync", req, nil, &resp); err != nil {
 		return nil, err
@@ -420,6 +439,9 @@ func (m *NFTMinter) authenticateSDKSession() (string, error) {
 		"challenge":      challenge,
 		"signature":      signature,
 	}
+	if m.challengeScheme == auth.ChallengeSchemeEIP712 {
+		req["signature_type"] = string(m.challengeScheme)
+	}
 	var resp sdkVerifyResponse
 	if err := m.postJSON("/api/sdk/auth/verify", req, nil, &resp); err != nil {
 		return "", err
@@ -443,16 +465,11 @@ func (m *NFTMinter) requestSDKChallenge() (string, error) {
 }
 
 func (m *NFTMinter) signSDKChallenge(challenge string) (string, error) {
-	message := "Teneo SDK auth: " + challenge
-	hash := accounts.TextHash([]byte(message))
-	sig, err := crypto.Sign(hash, m.privateKey)
+	sig, err := auth.SignChallenge(m.privateKey, m.challengeScheme, m.address.Hex(), challenge)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign sdk challenge: %w", err)
 	}
-	if sig[64] < 27 {
-		sig[64] += 27
-	}
-	return hexutil.Encode(sig), nil
+	return sig, nil
 }
 
 func (m *NFTMinter) callSDKDeploy(sessionToken string, config *sdkAgentPayload, configHash string) (*sdkDeployResponse, error) {
@@ -890,6 +907,32 @@ func (m *NFTMinter) requestMintSignature(to, agentID string, tokenURI string, no
 	return sigResp.Signature, nil
 }
 
+// resolveMintPrice reads the contract's mintPrice(), falling back to
+// DefaultMintPrice if the call fails
+func (m *NFTMinter) resolveMintPrice(ctx context.Context) *big.Int {
+	contractABI, err := ParseABI()
+	if err != nil {
+		fmt.Printf("   ⚠️  Failed to parse ABI (%v), using default mint price\n", err)
+		return DefaultMintPrice()
+	}
+
+	data, err := contractABI.Pack(MethodMintPrice)
+	if err == nil {
+		var result []byte
+		result, err = m.client.CallContract(ctx, ethereum.CallMsg{To: &m.contractAddress, Data: data}, nil)
+		if err == nil {
+			var price *big.Int
+			if err = contractABI.UnpackIntoInterface(&price, MethodMintPrice, result); err == nil && price != nil {
+				fmt.Printf("   💰 Mint price: %s wei (contract)\n", price.String())
+				return price
+			}
+		}
+	}
+
+	fmt.Printf("   ⚠️  Failed to read mintPrice() (%v), using default %s wei\n", err, DefaultMintPrice().String())
+	return DefaultMintPrice()
+}
+
 // executeMint executes the mint transaction on the blockchain
 func (m *NFTMinter) executeMint(signature string) (uint64, error) {
 	tokenID, _, err := m.executeMintWithTxHash(signature)
@@ -938,8 +981,8 @@ func (m *NFTMinter) executeMintWithTxHash(signature string) (uint64, string, err
 	tx := types.NewTransaction(
 		nonce,
 		m.contractAddress,
-		DefaultMintPrice(), // 0.01 ETH mint price
-		uint64(300000),     // Gas limit
+		m.resolveMintPrice(context.Background()),
+		uint64(300000), // Gas limit
 		gasPrice,
 		data,
 	)
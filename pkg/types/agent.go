@@ -21,7 +21,11 @@ type TaskProvider interface {
 	GetAvailableTasks(ctx context.Context) ([]Task, error)
 }
 
-// TaskResultHandler is an optional interface for agents that need custom result handling
+// TaskResultHandler is an optional interface for agents that need custom result handling.
+// HandleTaskResult is called with the result of every successful task before it
+// is sent. Streaming handlers send their own messages, so for them it is called
+// once the task returns, with the last message sent successfully as the result.
+// Errors are logged and do not stop the result from being sent.
 type TaskResultHandler interface {
 	HandleTaskResult(ctx context.Context, taskID string, result string) error
 }
@@ -49,6 +53,34 @@ type MessageSender interface {
 	TriggerWalletTx(tx TxRequest, description string, optional bool) error
 }
 
+// WalletTxRequester is implemented by MessageSenders that can wait for the
+// user's answer to a wallet transaction request. RequestWalletTx sends the
+// request like TriggerWalletTx and blocks until the user's tx_result arrives,
+// ctx is done or the request times out. A required request that times out
+// fails with ErrWalletTxTimeout; an optional one returns a result with
+// status TxStatusSkipped.
+type WalletTxRequester interface {
+	RequestWalletTx(ctx context.Context, tx TxRequest, description string, optional bool) (*TxResultData, error)
+}
+
+// NLPFallbackHandler is an optional interface for agents that accept
+// conversational input. When NLP fallback is enabled and a task fails with
+// ErrUnrecognizedCommand, the task coordinator passes the original text to
+// ParseNaturalLanguage and runs the task once more with the returned command.
+type NLPFallbackHandler interface {
+	ParseNaturalLanguage(ctx context.Context, text string) (command string, err error)
+}
+
+// RichTaskHandler is an optional interface for agents that return structured
+// results. The task coordinator prefers it over ProcessTask and fills in the
+// task ID, duration, creation time and success flag of the returned result;
+// a result with Error set counts as a failed task. The result is sent as the
+// response content with the whole TaskResult as its data. A returned error is
+// sent as an agent_error message, as for ProcessTask.
+type RichTaskHandler interface {
+	ProcessRichTask(ctx context.Context, task string) (*TaskResult, error)
+}
+
 // StreamingTaskHandler is an optional interface for agents that need to send multiple messages during task execution
 type StreamingTaskHandler interface {
 	// ProcessTaskWithStreaming processes a task with the ability to send multiple messages
@@ -70,6 +102,7 @@ type Task struct {
 	ID          string            `json:"id"`
 	Type        string            `json:"type"`
 	Content     string            `json:"content"`
+	Sender      string            `json:"sender,omitempty"` // originating wallet/user, if known
 	Priority    int               `json:"priority"`
 	CreatedAt   time.Time         `json:"created_at"`
 	Deadline    *time.Time        `json:"deadline,omitempty"`
@@ -113,6 +146,9 @@ type AgentMetrics struct {
 	ErrorRate           float64       `json:"error_rate"`
 	UptimePercentage    float64       `json:"uptime_percentage"`
 	LastUpdated         time.Time     `json:"last_updated"`
+	TasksProcessed      int64         `json:"tasks_processed"`
+	TasksSuccessful     int64         `json:"tasks_successful"`
+	TasksFailed         int64         `json:"tasks_failed"`
 }
 
 // AgentCapability represents a capability that an agent can perform
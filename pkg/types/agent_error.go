@@ -0,0 +1,45 @@
+package types
+
+import "errors"
+
+// Error codes sent with agent_error messages when a task fails. They are
+// stable so clients can show localized messages keyed by code.
+const (
+	ErrorCodeValidation  = "VALIDATION_ERROR"
+	ErrorCodeRateLimited = "RATE_LIMITED"
+	ErrorCodeTimeout     = "TIMEOUT"
+	ErrorCodeInternal    = "INTERNAL_ERROR"
+)
+
+// ErrRateLimited is returned, usually wrapped, by task handlers that were
+// rate limited, e.g. by an upstream API, so the task fails with
+// ErrorCodeRateLimited
+var ErrRateLimited = errors.New("rate limited")
+
+// AgentError is an error a task handler returns to choose the error code,
+// message and details the user is sent instead of those derived from the
+// error's kind
+type AgentError struct {
+	Code    string                 // Error code, ErrorCodeInternal if empty
+	Message string                 // User-facing message, the wrapped error's text if empty
+	Details map[string]interface{} // Extra structured details for the client
+	Err     error                  // Underlying error, if any
+}
+
+// Error returns the user-facing message
+func (e *AgentError) Error() string {
+	switch {
+	case e.Message != "":
+		return e.Message
+	case e.Err != nil:
+		return e.Err.Error()
+	case e.Code != "":
+		return e.Code
+	}
+	return ErrorCodeInternal
+}
+
+// Unwrap returns the underlying error
+func (e *AgentError) Unwrap() error {
+	return e.Err
+}
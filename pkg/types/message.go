@@ -20,6 +20,19 @@ var (
 	ErrSignatureInvalid        = errors.New("invalid signature")
 	ErrNFTNotFound             = errors.New("NFT not found")
 	ErrAgentAlreadyRegistered  = errors.New("agent already registered")
+
+	// ErrUnrecognizedCommand is returned, usually wrapped, by task handlers
+	// whose strict parsing rejects a task, letting an NLPFallbackHandler
+	// retry it
+	ErrUnrecognizedCommand = errors.New("unrecognized command")
+
+	// ErrMessageTooLarge is returned when content that cannot be split, such
+	// as JSON, exceeds the configured maximum message size
+	ErrMessageTooLarge = errors.New("message too large")
+
+	// ErrWalletTxTimeout is returned when the user does not answer a
+	// required wallet transaction request in time
+	ErrWalletTxTimeout = errors.New("wallet transaction request timed out")
 )
 
 // Message represents a message in the Teneo network
@@ -237,6 +250,7 @@ type AgentErrorData struct {
 // TriggerWalletTxData is the payload for trigger_wallet_tx messages
 type TriggerWalletTxData struct {
 	TaskID      string    `json:"task_id"`
+	RequestID   string    `json:"request_id,omitempty"` // Echoed in the user's tx_result
 	Tx          TxRequest `json:"tx"`
 	Description string    `json:"description"`
 	Optional    bool      `json:"optional"`
@@ -244,8 +258,13 @@ type TriggerWalletTxData struct {
 
 // TxResultData is the payload received when user responds to trigger_mm_tx
 type TxResultData struct {
-	TaskID string `json:"task_id"`
-	TxHash string `json:"tx_hash,omitempty"`
-	Status string `json:"status"`
-	Error  string `json:"error,omitempty"`
+	TaskID    string `json:"task_id"`
+	RequestID string `json:"request_id,omitempty"`
+	TxHash    string `json:"tx_hash,omitempty"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
 }
+
+// TxStatusSkipped is the status of an optional wallet transaction request
+// the user did not answer in time
+const TxStatusSkipped = "skipped"
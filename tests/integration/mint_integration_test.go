@@ -3,6 +3,7 @@ package integration
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -144,13 +145,12 @@ func TestMint_NewAgent(t *testing.T) {
 
 	result, err := minter.MintWithContext(ctx, jsonPath)
 	if err != nil {
-		errStr := err.Error()
 		// Check for rate limit
-		if strings.Contains(errStr, "rate limit") {
+		if errors.Is(err, deploy.ErrRateLimited) {
 			t.Skip("Rate limit exceeded - try again later or run tests with delays")
 		}
 		// Check if this is the known "already taken" issue during deploy
-		if strings.Contains(errStr, "already taken") || strings.Contains(errStr, "conflict") {
+		if errors.Is(err, deploy.ErrAgentIDTaken) {
 			t.Logf("NOTE: Got 'already taken' error - this may be a backend deploy endpoint issue")
 			t.Logf("The sync->MINT_REQUIRED flow worked, but deploy failed")
 			t.Logf("Error details: %v", err)
@@ -232,7 +232,7 @@ func TestMint_SyncAlreadyOwned(t *testing.T) {
 	t.Logf("First mint for agent: %s", uniqueID)
 	result1, err := minter.MintWithContext(ctx, jsonPath)
 	if err != nil {
-		if strings.Contains(err.Error(), "rate limit") {
+		if errors.Is(err, deploy.ErrRateLimited) {
 			t.Skip("Rate limit exceeded - try again later")
 		}
 		t.Fatalf("First mint failed: %v", err)
@@ -307,7 +307,7 @@ func TestMint_ReloginSameJSON(t *testing.T) {
 	t.Logf("First mint for agent: %s", uniqueID)
 	result1, err := minter.MintWithContext(ctx, jsonPath)
 	if err != nil {
-		if strings.Contains(err.Error(), "rate limit") || strings.Contains(err.Error(), "MAX_RESERVATIONS") {
+		if errors.Is(err, deploy.ErrRateLimited) || errors.Is(err, deploy.ErrMaxReservations) {
 			t.Skip("Rate limit or max reservations - try again later")
 		}
 		t.Fatalf("First mint failed: %v", err)
@@ -369,7 +369,7 @@ func TestMint_ChangeAgentIdMintsNew(t *testing.T) {
 	t.Logf("Minting first agent: %s", agentID1)
 	result1, err := minter.MintWithContext(ctx, jsonPath1)
 	if err != nil {
-		if strings.Contains(err.Error(), "rate limit") || strings.Contains(err.Error(), "MAX_RESERVATIONS") {
+		if errors.Is(err, deploy.ErrRateLimited) || errors.Is(err, deploy.ErrMaxReservations) {
 			t.Skip("Rate limit or max reservations - try again later")
 		}
 		t.Fatalf("First mint failed: %v", err)
@@ -439,7 +439,7 @@ func TestUpdate_AutoUpdateOnNameChange(t *testing.T) {
 	t.Logf("First mint for agent: %s", uniqueID)
 	result1, err := minter.MintWithContext(ctx, jsonPath1)
 	if err != nil {
-		if strings.Contains(err.Error(), "rate limit") || strings.Contains(err.Error(), "MAX_RESERVATIONS") {
+		if errors.Is(err, deploy.ErrRateLimited) || errors.Is(err, deploy.ErrMaxReservations) {
 			t.Skip("Rate limit or max reservations - try again later")
 		}
 		t.Fatalf("First mint failed: %v", err)
@@ -884,7 +884,7 @@ func TestSecurity_ChallengeReplay(t *testing.T) {
 	// Step 1: Get a challenge and sign it
 	challenge, err := httpClient.GetChallenge(wallet)
 	if err != nil {
-		if strings.Contains(err.Error(), "rate limit") {
+		if errors.Is(err, deploy.ErrRateLimited) {
 			t.Skip("Rate limit exceeded")
 		}
 		t.Fatalf("GetChallenge failed: %v", err)
@@ -907,7 +907,7 @@ func TestSecurity_ChallengeReplay(t *testing.T) {
 		Signature:  signature,
 	})
 	if err != nil {
-		if strings.Contains(err.Error(), "rate limit") {
+		if errors.Is(err, deploy.ErrRateLimited) {
 			t.Skip("Rate limit exceeded")
 		}
 		t.Fatalf("First sync failed (unexpected): %v", err)
@@ -940,7 +940,7 @@ func TestSecurity_ChallengeReplay(t *testing.T) {
 	t.Log("Verifying challenge uniqueness...")
 	challenge2, err := httpClient.GetChallenge(wallet)
 	if err != nil {
-		if strings.Contains(err.Error(), "rate limit") {
+		if errors.Is(err, deploy.ErrRateLimited) {
 			t.Skip("Rate limit exceeded on second challenge")
 		}
 		t.Fatalf("Second GetChallenge failed: %v", err)
@@ -1300,7 +1300,7 @@ func TestEdge_AbandonAndRemint(t *testing.T) {
 	t.Logf("Step 1: Mint agent %s", uniqueID)
 	result1, err := minter.MintWithContext(ctx, jsonPath)
 	if err != nil {
-		if strings.Contains(err.Error(), "rate limit") || strings.Contains(err.Error(), "MAX_RESERVATIONS") {
+		if errors.Is(err, deploy.ErrRateLimited) || errors.Is(err, deploy.ErrMaxReservations) {
 			t.Skip("Rate limit or max reservations - try again later")
 		}
 		t.Fatalf("First mint failed: %v", err)
@@ -1344,7 +1344,7 @@ func TestEdge_AbandonAndRemint(t *testing.T) {
 	// Instead, let's test the full cycle: mint, then verify re-mint with same JSON works
 	result2, err := minter.MintWithContext(ctx, jsonPathReserve)
 	if err != nil {
-		if strings.Contains(err.Error(), "rate limit") || strings.Contains(err.Error(), "MAX_RESERVATIONS") {
+		if errors.Is(err, deploy.ErrRateLimited) || errors.Is(err, deploy.ErrMaxReservations) {
 			t.Skip("Rate limit or max reservations")
 		}
 		t.Fatalf("Reserve agent mint failed: %v", err)
@@ -1393,7 +1393,7 @@ func TestEdge_OnChainOwnershipVerification(t *testing.T) {
 	t.Logf("Minting agent: %s", uniqueID)
 	result, err := minter.MintWithContext(ctx, jsonPath)
 	if err != nil {
-		if strings.Contains(err.Error(), "rate limit") || strings.Contains(err.Error(), "MAX_RESERVATIONS") {
+		if errors.Is(err, deploy.ErrRateLimited) || errors.Is(err, deploy.ErrMaxReservations) {
 			t.Skip("Rate limit or max reservations")
 		}
 		t.Fatalf("Mint failed: %v", err)
@@ -1438,7 +1438,8 @@ func TestEdge_OnChainOwnershipVerification(t *testing.T) {
 }
 
 // Test 19: Config hash determinism - same config always produces same hash
-// v3 hash includes: agentId, name, description, image, agentType, capabilities, nlpFallback, categories, command triggers+prices
+// v3 hash includes: agentId, name, description, agentType, capabilities, nlpFallback, categories, command triggers+prices.
+// Image is excluded unless HashOptions.IncludeImage is set.
 func TestEdge_ConfigHashDeterminism(t *testing.T) {
 	config1 := &deploy.AgentConfig{
 		Name:        "Deterministic Test",
@@ -1545,7 +1546,7 @@ func TestEdge_ConfigHashDeterminism(t *testing.T) {
 		t.Error("Config hash SHOULD change when description changes (v3 security)")
 	}
 
-	// v3: Changing image SHOULD change the hash
+	// v3: Changing only the image should NOT change the backend hash (image is cosmetic)
 	config4b := &deploy.AgentConfig{
 		Name:        "Deterministic Test",
 		AgentID:     "hash-test-agent",
@@ -1567,8 +1568,14 @@ func TestEdge_ConfigHashDeterminism(t *testing.T) {
 	hash4b := deploy.GenerateConfigHash(config4b)
 	t.Logf("Hash 4b (different image): %s", hash4b)
 
-	if hash1 == hash4b {
-		t.Error("Config hash SHOULD change when image changes (v3 security)")
+	if hash1 != hash4b {
+		t.Error("Config hash should NOT change when only the image changes")
+	}
+
+	// ...but it SHOULD change the image-inclusive variant used for local change detection
+	withImage := deploy.HashOptions{IncludeImage: true}
+	if deploy.GenerateConfigHashWithOptions(config1, withImage) == deploy.GenerateConfigHashWithOptions(config4b, withImage) {
+		t.Error("IncludeImage config hash SHOULD change when image changes")
 	}
 
 	// Changing price SHOULD change the hash (billing security)
@@ -1605,7 +1612,7 @@ func TestEdge_ConfigHashDeterminism(t *testing.T) {
 		}
 	}
 
-	t.Log("Config hash determinism verified: v3 with description/image/prices, 100 iterations stable")
+	t.Log("Config hash determinism verified: v3 with description/prices, optional image, 100 iterations stable")
 }
 
 // Test 20: Rate limiting enforcement
@@ -1677,7 +1684,7 @@ func TestEdge_SignatureTampering(t *testing.T) {
 	// Get a valid challenge
 	challenge, err := httpClient.GetChallenge(wallet)
 	if err != nil {
-		if strings.Contains(err.Error(), "rate limit") {
+		if errors.Is(err, deploy.ErrRateLimited) {
 			t.Skip("Rate limit exceeded")
 		}
 		t.Fatalf("GetChallenge failed: %v", err)
@@ -1723,7 +1730,7 @@ func TestEdge_SignatureTampering(t *testing.T) {
 	t.Log("Testing with completely fabricated signature...")
 	challenge2, err := httpClient.GetChallenge(wallet)
 	if err != nil {
-		if strings.Contains(err.Error(), "rate limit") {
+		if errors.Is(err, deploy.ErrRateLimited) {
 			t.Skip("Rate limit exceeded")
 		}
 		t.Fatalf("Second GetChallenge failed: %v", err)
@@ -1749,7 +1756,7 @@ func TestEdge_SignatureTampering(t *testing.T) {
 	t.Log("Testing with mismatched wallet address...")
 	challenge3, err := httpClient.GetChallenge(wallet)
 	if err != nil {
-		if strings.Contains(err.Error(), "rate limit") {
+		if errors.Is(err, deploy.ErrRateLimited) {
 			t.Skip("Rate limit exceeded")
 		}
 		t.Fatalf("Third GetChallenge failed: %v", err)
@@ -1811,7 +1818,7 @@ func TestUpdate_AutoUpdateOnCapabilityChange(t *testing.T) {
 	t.Logf("Minting agent: %s", uniqueID)
 	result1, err := minter.MintWithContext(ctx, jsonPath1)
 	if err != nil {
-		if strings.Contains(err.Error(), "rate limit") || strings.Contains(err.Error(), "MAX_RESERVATIONS") {
+		if errors.Is(err, deploy.ErrRateLimited) || errors.Is(err, deploy.ErrMaxReservations) {
 			t.Skip("Rate limit or max reservations")
 		}
 		t.Fatalf("First mint failed: %v", err)
@@ -1888,7 +1895,7 @@ func TestUpdate_AutoUpdateOnPriceChange(t *testing.T) {
 	t.Logf("Minting agent: %s", uniqueID)
 	result1, err := minter.MintWithContext(ctx, jsonPath1)
 	if err != nil {
-		if strings.Contains(err.Error(), "rate limit") || strings.Contains(err.Error(), "MAX_RESERVATIONS") {
+		if errors.Is(err, deploy.ErrRateLimited) || errors.Is(err, deploy.ErrMaxReservations) {
 			t.Skip("Rate limit or max reservations")
 		}
 		t.Fatalf("First mint failed: %v", err)
@@ -1962,7 +1969,7 @@ func TestUpdate_VerifySyncedAfterUpdate(t *testing.T) {
 	t.Logf("Step 1: Mint agent %s", uniqueID)
 	result1, err := minter.MintWithContext(ctx, jsonPath1)
 	if err != nil {
-		if strings.Contains(err.Error(), "rate limit") || strings.Contains(err.Error(), "MAX_RESERVATIONS") {
+		if errors.Is(err, deploy.ErrRateLimited) || errors.Is(err, deploy.ErrMaxReservations) {
 			t.Skip("Rate limit or max reservations")
 		}
 		t.Fatalf("First mint failed: %v", err)
@@ -2040,7 +2047,7 @@ func TestUpdate_DescriptionChangeTriggersUpdate(t *testing.T) {
 	t.Logf("Minting agent: %s", uniqueID)
 	result1, err := minter.MintWithContext(ctx, jsonPath1)
 	if err != nil {
-		if strings.Contains(err.Error(), "rate limit") || strings.Contains(err.Error(), "MAX_RESERVATIONS") {
+		if errors.Is(err, deploy.ErrRateLimited) || errors.Is(err, deploy.ErrMaxReservations) {
 			t.Skip("Rate limit or max reservations")
 		}
 		t.Fatalf("First mint failed: %v", err)
@@ -2116,7 +2123,7 @@ func TestUpdate_NonexistentAgent(t *testing.T) {
 
 	sessionToken, _, err := auth.Authenticate()
 	if err != nil {
-		if strings.Contains(err.Error(), "rate limit") {
+		if errors.Is(err, deploy.ErrRateLimited) {
 			t.Skip("Rate limited")
 		}
 		t.Fatalf("Auth failed: %v", err)
@@ -2157,7 +2164,7 @@ func TestUpdate_UnauthorizedWalletUpdate(t *testing.T) {
 
 	sessionToken, _, err := auth.Authenticate()
 	if err != nil {
-		if strings.Contains(err.Error(), "rate limit") {
+		if errors.Is(err, deploy.ErrRateLimited) {
 			t.Skip("Rate limited")
 		}
 		t.Fatalf("Auth failed: %v", err)
@@ -2203,7 +2210,7 @@ func TestUpdate_XSSPrevention(t *testing.T) {
 
 	sessionToken, _, err := auth.Authenticate()
 	if err != nil {
-		if strings.Contains(err.Error(), "rate limit") {
+		if errors.Is(err, deploy.ErrRateLimited) {
 			t.Skip("Rate limited")
 		}
 		t.Fatalf("Auth failed: %v", err)
@@ -2271,7 +2278,7 @@ func TestReservation_MintFreesSlot(t *testing.T) {
 	jsonPath := createAgentJSON(t, config)
 	result, err := minter.MintWithContext(ctx, jsonPath)
 	if err != nil {
-		if strings.Contains(err.Error(), "rate limit") || strings.Contains(err.Error(), "MAX_RESERVATIONS") {
+		if errors.Is(err, deploy.ErrRateLimited) || errors.Is(err, deploy.ErrMaxReservations) {
 			t.Skip("Rate limit or max reservations")
 		}
 		t.Fatalf("Mint failed: %v", err)
@@ -2316,7 +2323,7 @@ func TestReservation_AbandonFreesSlot(t *testing.T) {
 
 	challenge, err := httpClient.GetChallenge(auth.GetAddress())
 	if err != nil {
-		if strings.Contains(err.Error(), "rate limit") {
+		if errors.Is(err, deploy.ErrRateLimited) {
 			t.Skip("Rate limited")
 		}
 		t.Fatalf("GetChallenge failed: %v", err)
@@ -2336,7 +2343,7 @@ func TestReservation_AbandonFreesSlot(t *testing.T) {
 		Signature:  signature,
 	})
 	if err != nil {
-		if strings.Contains(err.Error(), "rate limit") || strings.Contains(err.Error(), "MAX_RESERVATIONS") {
+		if errors.Is(err, deploy.ErrRateLimited) || errors.Is(err, deploy.ErrMaxReservations) {
 			t.Skip("Rate limit or max reservations")
 		}
 		t.Fatalf("Sync to create reservation failed: %v", err)